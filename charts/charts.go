@@ -0,0 +1,29 @@
+// Package charts embeds the CustomResourceDefinitions of the k6s Helm chart,
+// so that they can be installed without Helm.
+package charts
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed k6s/crds/*.yaml
+var crds embed.FS
+
+// CRDs returns the CustomResourceDefinition manifests of the chart, in file
+// name order
+func CRDs() ([][]byte, error) {
+	files, err := fs.Glob(crds, "k6s/crds/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	manifests := make([][]byte, 0, len(files))
+	for _, file := range files {
+		data, err := crds.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, data)
+	}
+	return manifests, nil
+}
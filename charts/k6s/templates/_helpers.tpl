@@ -67,3 +67,14 @@ Create the image name
 {{- define "k6s.image" -}}
 {{- printf "%s:%s" .Values.image.repository .Values.image.tag }}
 {{- end }}
+
+{{/*
+Name of the ConfigMap holding the configuration file, empty when none is used
+*/}}
+{{- define "k6s.configMapName" -}}
+{{- if .Values.configMap.name }}
+{{- .Values.configMap.name }}
+{{- else if .Values.configMap.content }}
+{{- include "k6s.fullname" . }}-config
+{{- end }}
+{{- end }}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/manifest"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	applyFiles          []string
+	applyRecursive      bool
+	applyNamespace      string
+	applyKubeconfig     string
+	applyForceConflicts bool
+	applyDryRun         bool
+)
+
+// applyTimeout bounds the server-side apply of one deployment
+const applyTimeout = 30 * time.Second
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply deployment manifests",
+	Long: `Create or update deployments from YAML or JSON manifests with a server-side
+apply, so complete manifests with environment variables, probes and volumes can
+be managed. Fields are owned by the "k6s" field manager; fields owned by other
+managers, such as kubectl, are only taken over with --force-conflicts.
+
+The applied manifest is recorded in the k6s.io/desired-manifest annotation, so
+that "k6s drift report" and k6s server can report fields changed since.
+
+Manifests may hold several documents separated by "---". Only apps/v1
+Deployment objects are supported. Deployments without a namespace are applied
+to --namespace.
+
+Examples:
+  k6s apply -f web.yaml
+  k6s apply -f manifests/ -R -n shop
+  cat web.yaml | k6s apply -f -
+  k6s apply -f web.yaml --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		manifests, err := manifest.Read(applyFiles, applyRecursive, os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading manifests: %v\n", err)
+			os.Exit(1)
+		}
+		if len(manifests) == 0 {
+			fmt.Fprintf(os.Stderr, "error: no deployments found in %v\n", applyFiles)
+			os.Exit(1)
+		}
+		if err := manifest.SetNamespace(manifests, applyNamespace, cmd.Flags().Changed("namespace")); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		client, err := kubernetes.NewClient(applyKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		opts := kubernetes.ApplyOptions{Force: applyForceConflicts, DryRun: applyDryRun}
+		suffix := ""
+		if applyDryRun {
+			suffix = " (server dry run)"
+		}
+		failed := false
+		for _, m := range manifests {
+			dep := m.Deployment
+			data, err := drift.RecordDesired(m.Data)
+			if err != nil {
+				failed = true
+				fmt.Fprintf(os.Stderr, "error recording manifest of deployment %s/%s from %s: %v\n", dep.Namespace, dep.Name, m.Source, err)
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), applyTimeout)
+			_, err = client.DeploymentApply(ctx, dep.Namespace, dep.Name, data, opts)
+			cancel()
+			if err != nil {
+				failed = true
+				fmt.Fprintf(os.Stderr, "error applying deployment %s/%s from %s: %v\n", dep.Namespace, dep.Name, m.Source, err)
+				if apierrors.IsConflict(err) {
+					fmt.Fprintln(os.Stderr, "fields are managed by another field manager; rerun with --force-conflicts to take them over")
+				}
+				continue
+			}
+			fmt.Printf("deployment.apps/%s serverside-applied%s\n", dep.Name, suffix)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringSliceVarP(&applyFiles, "filename", "f", nil, "Manifest file or directory to apply, - for stdin (repeatable)")
+	applyCmd.Flags().BoolVarP(&applyRecursive, "recursive", "R", false, "Read directories given with -f recursively")
+	applyCmd.Flags().StringVarP(&applyNamespace, "namespace", "n", "default", "Namespace of deployments without one")
+	applyCmd.Flags().StringVar(&applyKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	applyCmd.Flags().BoolVar(&applyForceConflicts, "force-conflicts", false, "Take over fields managed by other field managers")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Validate the apply on the server without persisting it")
+	_ = applyCmd.MarkFlagRequired("filename")
+}
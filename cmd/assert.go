@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/assert"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/remote"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+var (
+	assertFile       string
+	assertTimeout    time.Duration
+	assertOutput     string
+	assertKubeconfig string
+	assertServer     string
+	assertToken      string
+)
+
+// assertCmd represents the assert command
+var assertCmd = &cobra.Command{
+	Use:   "assert",
+	Short: "Check deployments against declared expectations",
+	Long: `Evaluate declarative deployment assertions, waiting up to the timeout for
+them to pass. The command exits non-zero if any assertion fails, for use in CI.
+
+Assertions are read live from the Kubernetes API. Clusters named in an
+assertion are resolved from the multi-cluster configuration; assertions
+without clusters use the current cluster. With --server, deployments are read
+from the informer cache of a k6s server instead.
+
+Example assertions.yaml:
+  timeout: 2m
+  assertions:
+    - name: web is rolled out
+      deployment: web
+      namespace: prod
+      clusters: [prod-us, prod-eu]
+      expect:
+        replicas: ">=3"
+        ready_replicas: ">=3"
+        image_prefix: "registry.example/web:"
+
+Examples:
+  k6s assert -f assertions.yaml
+  k6s assert -f assertions.yaml --timeout 5m --output junit > assert-report.xml
+  k6s assert -f assertions.yaml --server https://k6s.example`,
+	Args: cobra.NoArgs,
+	RunE: runAssert,
+}
+
+func init() {
+	rootCmd.AddCommand(assertCmd)
+
+	assertCmd.Flags().StringVarP(&assertFile, "file", "f", "", "assertions file (required)")
+	assertCmd.Flags().DurationVar(&assertTimeout, "timeout", 0, "how long to wait for assertions to pass (overrides the file)")
+	assertCmd.Flags().StringVarP(&assertOutput, "output", "o", assert.FormatText, "report format (text, json, junit)")
+	assertCmd.Flags().StringVar(&assertKubeconfig, "kubeconfig", "", "kubeconfig for assertions without clusters")
+	assertCmd.Flags().StringVar(&assertServer, "server", "", "read deployments from this k6s server instead of the Kubernetes API")
+	assertCmd.Flags().StringVar(&assertToken, "token", "", "API token for --server (default $K6S_TOKEN)")
+	_ = assertCmd.MarkFlagRequired("file")
+}
+
+func runAssert(cmd *cobra.Command, args []string) error {
+	file, err := assert.Load(assertFile)
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("timeout") {
+		file.Timeout = assertTimeout
+	}
+
+	source, err := newAssertSource()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	results := file.Run(ctx, source)
+	if err := assert.WriteReport(os.Stdout, assertOutput, results); err != nil {
+		return err
+	}
+
+	if !assert.Passed(results) {
+		return fmt.Errorf("assertions failed")
+	}
+	return nil
+}
+
+// newAssertSource returns the source assertions are evaluated against
+func newAssertSource() (assert.Source, error) {
+	if assertServer != "" {
+		token := assertToken
+		if token == "" {
+			token = viper.GetString("attach.token")
+		}
+		return assert.NewRemoteSource(func(clusterName string) (*remote.Client, error) {
+			return remote.NewClient(assertServer, token, clusterName)
+		}), nil
+	}
+
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	return assert.NewLiveSource(func(clusterName string) (k8s.Interface, error) {
+		if clusterName == "" {
+			client, err := kubernetes.NewClient(assertKubeconfig)
+			if err != nil {
+				return nil, err
+			}
+			return client.Clientset(), nil
+		}
+
+		for _, clusterConfig := range cfg.MultiCluster.Clusters {
+			if clusterConfig.Name == clusterName {
+				return cluster.NewClusterConfigFrom(clusterConfig).GetKubernetesClient()
+			}
+		}
+		return nil, fmt.Errorf("cluster %s is not configured", clusterName)
+	}), nil
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/remote"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	attachServer        string
+	attachToken         string
+	attachCluster       string
+	attachNamespace     string
+	attachAllNamespaces bool
+	attachSelector      string
+	attachReplicas      int32
+)
+
+// attachCmd represents the attach command group
+var attachCmd = &cobra.Command{
+	Use:   "attach",
+	Short: "Run commands against a remote k6s server",
+	Long: `Run deployment commands against the API of a running k6s server
+instead of a local kubeconfig. Watch streams events over a WebSocket.
+
+The token is sent as a bearer token and can also be set with K6S_TOKEN.
+--cluster makes the server reject requests unless it serves that cluster.
+
+Examples:
+  # List deployments in all namespaces
+  k6s attach --server https://k6s.example list -A
+
+  # Get a deployment
+  k6s attach --server https://k6s.example get web -n prod
+
+  # Watch deployment events for a label selector
+  k6s attach --server https://k6s.example watch -n prod -l app=web
+
+  # Scale a deployment (the server must have API tokens configured)
+  k6s attach --server https://k6s.example --token $TOKEN --cluster prod-eu scale web --replicas 3 -n prod`,
+}
+
+// attachListCmd represents the attach list command
+var attachListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deployments on the remote server",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newRemoteClient()
+		if err != nil {
+			return err
+		}
+
+		namespace := attachNamespace
+		if attachAllNamespaces {
+			namespace = ""
+		}
+
+		deployments, err := client.ListDeployments(cmd.Context(), namespace)
+		if err != nil {
+			return fmt.Errorf("failed to list deployments: %w", err)
+		}
+
+		printRemoteDeployments(deployments, attachAllNamespaces)
+		return nil
+	},
+}
+
+// attachGetCmd represents the attach get command
+var attachGetCmd = &cobra.Command{
+	Use:   "get NAME",
+	Short: "Get a deployment from the remote server",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newRemoteClient()
+		if err != nil {
+			return err
+		}
+
+		deployment, err := client.GetDeployment(cmd.Context(), attachNamespace, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+
+		printRemoteDeployments([]server.DeploymentResponse{*deployment}, false)
+		return nil
+	},
+}
+
+// attachWatchCmd represents the attach watch command
+var attachWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Stream deployment events from the remote server",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newRemoteClient()
+		if err != nil {
+			return err
+		}
+
+		namespace := attachNamespace
+		if attachAllNamespaces {
+			namespace = ""
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		return client.Watch(ctx, namespace, attachSelector, func(event server.DeploymentEvent) {
+			dep := event.Deployment
+			fmt.Printf("%s\t%s\t%s/%s\treplicas=%d ready=%d\n",
+				event.Timestamp.Format("15:04:05"), event.Type, dep.Namespace, dep.Name, dep.Replicas, dep.Ready)
+		})
+	},
+}
+
+// attachScaleCmd represents the attach scale command
+var attachScaleCmd = &cobra.Command{
+	Use:   "scale NAME",
+	Short: "Scale a deployment through the remote server",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("replicas") {
+			return fmt.Errorf("--replicas is required")
+		}
+
+		client, err := newRemoteClient()
+		if err != nil {
+			return err
+		}
+
+		result, err := client.ScaleDeployment(cmd.Context(), attachNamespace, args[0], attachReplicas)
+		if err != nil {
+			return fmt.Errorf("failed to scale deployment: %w", err)
+		}
+
+		fmt.Printf("deployment.apps/%s scaled to %d replicas\n", result.Name, result.Replicas)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+
+	attachCmd.AddCommand(attachListCmd)
+	attachCmd.AddCommand(attachGetCmd)
+	attachCmd.AddCommand(attachWatchCmd)
+	attachCmd.AddCommand(attachScaleCmd)
+
+	// Connection flags
+	attachCmd.PersistentFlags().StringVar(&attachServer, "server", "", "URL of the k6s server (required)")
+	attachCmd.PersistentFlags().StringVar(&attachToken, "token", "", "API token (default $K6S_TOKEN)")
+	attachCmd.PersistentFlags().StringVar(&attachCluster, "cluster", "", "cluster the server must serve")
+	attachCmd.PersistentFlags().StringVarP(&attachNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	_ = attachCmd.MarkPersistentFlagRequired("server")
+	_ = viper.BindEnv("attach.token", "K6S_TOKEN")
+
+	attachListCmd.Flags().BoolVarP(&attachAllNamespaces, "all-namespaces", "A", false, "List deployments across all namespaces")
+	attachWatchCmd.Flags().BoolVarP(&attachAllNamespaces, "all-namespaces", "A", false, "Watch deployments across all namespaces")
+	attachWatchCmd.Flags().StringVarP(&attachSelector, "selector", "l", "", "Label selector, e.g. app=web")
+	attachScaleCmd.Flags().Int32Var(&attachReplicas, "replicas", 0, "Number of replicas (required)")
+}
+
+// newRemoteClient creates a client for the server given by the attach flags
+func newRemoteClient() (*remote.Client, error) {
+	token := attachToken
+	if token == "" {
+		token = viper.GetString("attach.token")
+	}
+	return remote.NewClient(attachServer, token, attachCluster)
+}
+
+// printRemoteDeployments prints deployments in kubectl-like format
+func printRemoteDeployments(deployments []server.DeploymentResponse, showNamespace bool) {
+	if len(deployments) == 0 {
+		fmt.Println("No resources found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if showNamespace {
+		fmt.Fprintln(w, "NAMESPACE\tNAME\tREADY\tUP-TO-DATE\tAVAILABLE\tAGE")
+	} else {
+		fmt.Fprintln(w, "NAME\tREADY\tUP-TO-DATE\tAVAILABLE\tAGE")
+	}
+
+	for _, dep := range deployments {
+		ready := fmt.Sprintf("%d/%d", dep.Ready, dep.Replicas)
+		if showNamespace {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", dep.Namespace, dep.Name, ready, dep.Updated, dep.Available, dep.Age)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", dep.Name, ready, dep.Updated, dep.Available, dep.Age)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/compliance"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/remote"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+var (
+	auditNamespace     string
+	auditAllNamespaces bool
+	auditKubeconfig    string
+	auditSeverity      string
+	auditOutput        string
+	auditServer        string
+	auditToken         string
+)
+
+// auditCmd represents the audit command group
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit deployments against policy rules",
+	Long:  `Check deployments against the rules of the configuration.`,
+}
+
+// auditImagesCmd represents the audit images command
+var auditImagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Check deployment images against the registry policy rules",
+	Long: `Check the images of deployments against the rules of controller.compliance:
+disallowed or not allowed registries, latest tags and missing digests. Each
+finding has the severity configured for its rule; --severity hides findings
+below a severity. The command exits with status 1 when a finding is shown.
+
+Without --server the deployments of --namespace are listed directly, with the
+rules of the configuration file whether or not scanning is enabled; with it,
+the last scan of a k6s server is shown.
+
+Examples:
+  k6s audit images -n shop
+  k6s audit images -A --severity high
+  k6s audit images --server https://k6s.example -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if auditOutput != "table" && auditOutput != "json" {
+			fmt.Fprintf(os.Stderr, "invalid output format %q: must be table or json\n", auditOutput)
+			os.Exit(1)
+		}
+		severity, err := compliance.ParseSeverity(auditSeverity)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		namespace := auditNamespace
+		if auditAllNamespaces {
+			namespace = ""
+		}
+
+		report, err := auditImages(cmd.Context(), namespace, severity)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if auditOutput == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			_ = encoder.Encode(report)
+		} else {
+			printComplianceReport(report, auditAllNamespaces || auditServer != "")
+		}
+		if len(report.Findings) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditImagesCmd)
+
+	auditImagesCmd.Flags().StringVarP(&auditNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	auditImagesCmd.Flags().BoolVarP(&auditAllNamespaces, "all-namespaces", "A", false, "Audit deployments of all namespaces")
+	auditImagesCmd.Flags().StringVar(&auditKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	auditImagesCmd.Flags().StringVar(&auditSeverity, "severity", "low", "Only show findings of at least this severity (critical, high, medium, low)")
+	auditImagesCmd.Flags().StringVarP(&auditOutput, "output", "o", "table", "output format (table, json)")
+	auditImagesCmd.Flags().StringVar(&auditServer, "server", "", "show the last scan of this k6s server")
+	auditImagesCmd.Flags().StringVar(&auditToken, "token", "", "API token for --server (default $K6S_TOKEN)")
+}
+
+// auditImages returns the findings of the --server k6s server, or scans the
+// deployments of namespace with the rules of the configuration
+func auditImages(ctx context.Context, namespace, severity string) (compliance.Report, error) {
+	if auditServer != "" {
+		token := auditToken
+		if token == "" {
+			token = viper.GetString("attach.token")
+		}
+		client, err := remote.NewClient(auditServer, token, "")
+		if err != nil {
+			return compliance.Report{}, err
+		}
+		report, err := client.Compliance(ctx, namespace, severity)
+		if err != nil {
+			return compliance.Report{}, err
+		}
+		return *report, nil
+	}
+
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		return compliance.Report{}, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	client, err := kubernetes.NewClient(auditKubeconfig)
+	if err != nil {
+		return compliance.Report{}, fmt.Errorf("error creating kubernetes client: %w", err)
+	}
+	list, err := client.DeploymentList(namespace)
+	if err != nil {
+		return compliance.Report{}, fmt.Errorf("error listing deployments: %w", err)
+	}
+	deployments := make([]*appsv1.Deployment, 0, len(list.Items))
+	for i := range list.Items {
+		deployments = append(deployments, &list.Items[i])
+	}
+	return compliance.Filter(compliance.Scan(deployments, cfg.Controller.Compliance), "", severity), nil
+}
+
+// printComplianceReport prints the findings of a report, most severe first,
+// followed by the number of compliant deployments
+func printComplianceReport(report compliance.Report, showNamespace bool) {
+	if len(report.Findings) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if showNamespace {
+			fmt.Fprintln(w, "SEVERITY\tNAMESPACE\tDEPLOYMENT\tCONTAINER\tRULE\tMESSAGE")
+		} else {
+			fmt.Fprintln(w, "SEVERITY\tDEPLOYMENT\tCONTAINER\tRULE\tMESSAGE")
+		}
+		for _, f := range report.Findings {
+			if showNamespace {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Severity, f.Namespace, f.Deployment, f.Container, f.Rule, f.Message)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Severity, f.Deployment, f.Container, f.Rule, f.Message)
+			}
+		}
+		w.Flush()
+		fmt.Println()
+	}
+	fmt.Printf("%d of %d deployments compliant, %d findings\n", report.Compliant, report.Deployments, len(report.Findings))
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// addKubeClientFlags adds the Kubernetes API client tuning flags to a
+// long-running command
+func addKubeClientFlags(cmd *cobra.Command) {
+	cmd.Flags().Float32("kube-api-qps", 0, "sustained requests per second to the Kubernetes API server (default controller.client.qps)")
+	cmd.Flags().Int("kube-api-burst", 0, "requests allowed above --kube-api-qps in bursts (default controller.client.burst)")
+	cmd.Flags().String("kube-api-content-type", "", "encoding of built-in resources, protobuf or json (default controller.client.content_type)")
+}
+
+// applyKubeClientFlags overrides client with the tuning flags that were set
+func applyKubeClientFlags(cmd *cobra.Command, client *config.KubeClientConfig) {
+	if cmd.Flags().Changed("kube-api-qps") {
+		client.QPS, _ = cmd.Flags().GetFloat32("kube-api-qps")
+	}
+	if cmd.Flags().Changed("kube-api-burst") {
+		client.Burst, _ = cmd.Flags().GetInt("kube-api-burst")
+	}
+	if cmd.Flags().Changed("kube-api-content-type") {
+		client.ContentType, _ = cmd.Flags().GetString("kube-api-content-type")
+	}
+}
@@ -1,40 +1,25 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
-	"path/filepath"
-	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/remote"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/server"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
-	"k8s.io/client-go/tools/clientcmd"
+	"github.com/spf13/viper"
 )
 
-// validateClusterConfigPath validates that the file path is safe and doesn't contain directory traversal attempts
-func validateClusterConfigPath(path string) error {
-	// Clean the path to resolve any ".." components
-	cleaned := filepath.Clean(path)
-
-	// Check for directory traversal attempts
-	if strings.Contains(cleaned, "..") {
-		return fmt.Errorf("path contains directory traversal sequences")
-	}
-
-	// Ensure the path is absolute or relative to current directory
-	if !filepath.IsAbs(cleaned) {
-		// Allow relative paths but ensure they don't go above current directory
-		if strings.HasPrefix(cleaned, "../") {
-			return fmt.Errorf("path cannot go above current directory")
-		}
-	}
-
-	return nil
-}
-
 // clusterCmd represents the cluster command group
 var clusterCmd = &cobra.Command{
 	Use:   "cluster",
@@ -85,7 +70,15 @@ The output shows:
 - Enabled/Disabled status
 - Primary designation
 - Context information
-- Namespace settings`,
+- Namespace settings
+
+With --server, the health a running k6s server tracks for each cluster is
+added: its state (connected, syncing, degraded, unreachable or disabled),
+last heartbeat and last error.
+
+Examples:
+  k6s cluster list
+  k6s cluster list --server https://k6s.example`,
 	RunE: listClusters,
 }
 
@@ -139,14 +132,20 @@ var checkConnectivityCmd = &cobra.Command{
 	Use:     "check-connectivity [NAME]",
 	Aliases: []string{"check"},
 	Short:   "Check connectivity to clusters",
-	Long: `Check connectivity to one or all clusters.
+	Long: `Check connectivity to one or all clusters by requesting the API server version.
+Shows the server version, the request latency and the proxy used for each cluster.
+Clusters are checked concurrently, up to multi_cluster.max_concurrent_connections
+at a time.
 
 Examples:
   # Check connectivity to all clusters
   k6s cluster check-connectivity
 
-  # Check connectivity to a specific cluster
-  k6s cluster check-connectivity production`,
+  # Check connectivity to a specific cluster, allowing 10 seconds
+  k6s cluster check-connectivity production --timeout 10s
+
+  # Write a JSON report, e.g. for a CI pipeline
+  k6s cluster check-connectivity --output json > connectivity.json`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: checkConnectivity,
 }
@@ -159,9 +158,22 @@ var (
 	addPrimary      bool
 	addDisabled     bool
 	skipConnectivity bool
+	addInsecure     bool
+	addCAFile       string
+	addProxyURL     string
+
+	// Flags for check-connectivity command
+	connectivityTimeout time.Duration
+	connectivityOutput  string
+
+	// Flags for list command
+	listServer string
+	listToken  string
 )
 
 func init() {
+	rootCmd.AddCommand(clusterCmd)
+
 	// Add subcommands
 	clusterCmd.AddCommand(addClusterCmd)
 	clusterCmd.AddCommand(listClustersCmd)
@@ -178,36 +190,62 @@ func init() {
 	addClusterCmd.Flags().BoolVar(&addPrimary, "primary", false, "set this cluster as primary")
 	addClusterCmd.Flags().BoolVar(&addDisabled, "disabled", false, "add cluster in disabled state")
 	addClusterCmd.Flags().BoolVar(&skipConnectivity, "skip-connectivity", false, "skip connectivity test when adding cluster")
+	addClusterCmd.Flags().BoolVar(&addInsecure, "insecure-skip-tls-verify", false, "skip TLS certificate verification (not recommended, prefer --ca-file)")
+	addClusterCmd.Flags().StringVar(&addCAFile, "ca-file", "", "path to a CA bundle used to verify the API server certificate")
+	addClusterCmd.Flags().StringVar(&addProxyURL, "proxy-url", "", "proxy URL for API server connections (http, https or socks5); \"direct\" ignores HTTPS_PROXY")
+
+	// Flags for list command
+	listClustersCmd.Flags().StringVar(&listServer, "server", "", "show the cluster health tracked by this k6s server")
+	listClustersCmd.Flags().StringVar(&listToken, "token", "", "API token for --server (default $K6S_TOKEN)")
+
+	// Flags for check-connectivity command
+	checkConnectivityCmd.Flags().DurationVar(&connectivityTimeout, "timeout", defaultConnectivityTimeout, "how long to wait for each cluster's API server to answer")
+	checkConnectivityCmd.Flags().StringVarP(&connectivityOutput, "output", "o", "text", "output format (text, json)")
 }
 
 func addCluster(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
-	// Load or create configuration
-	cfg, err := loadMultiClusterConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+	// Determine kubeconfig path
+	kubeconfigPath := addKubeconfig
+	if kubeconfigPath == "" {
+		var err error
+		if kubeconfigPath, err = cluster.DefaultKubeconfigPath(); err != nil {
+			return err
+		}
 	}
 
-	// Check if cluster already exists
-	for _, cluster := range cfg.MultiCluster.Clusters {
-		if cluster.Name == name {
-			return fmt.Errorf("cluster '%s' already exists", name)
+	// Create new cluster config
+	clusterConfig := config.ClusterConfig{
+		Name:                  name,
+		KubeConfig:            kubeconfigPath,
+		Context:               addContext,
+		Namespace:             addNamespace,
+		Enabled:               !addDisabled,
+		Primary:               addPrimary,
+		InsecureSkipTLSVerify: addInsecure,
+		CAFile:                addCAFile,
+		ProxyURL:              addProxyURL,
+	}
+
+	if addInsecure && addCAFile != "" {
+		return fmt.Errorf("--insecure-skip-tls-verify and --ca-file cannot be used together")
+	}
+	if addProxyURL != "" {
+		if err := config.ValidateProxyURL(addProxyURL); err != nil {
+			return fmt.Errorf("invalid --proxy-url: %w", err)
 		}
 	}
 
-	// Determine kubeconfig path
-	kubeconfigPath := addKubeconfig
-	if kubeconfigPath == "" {
-		if env := os.Getenv("KUBECONFIG"); env != "" {
-			kubeconfigPath = env
-		} else {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				return fmt.Errorf("failed to get home directory: %w", err)
-			}
-			kubeconfigPath = filepath.Join(home, ".kube", "config")
-		}
+	store := newClusterStore()
+	if _, err := store.Get(name); err == nil {
+		return fmt.Errorf("cluster '%s' already exists", name)
+	}
+
+	if addInsecure {
+		logger.Warn("TLS certificate verification is disabled for cluster", map[string]interface{}{
+			"cluster": name,
+		})
 	}
 
 	// Test connectivity unless skipped
@@ -218,33 +256,23 @@ func addCluster(cmd *cobra.Command, args []string) error {
 			"context":    addContext,
 		})
 
-		if err := testClusterConnectivity(kubeconfigPath, addContext); err != nil {
-			return fmt.Errorf("connectivity test failed for cluster '%s': %w", name, err)
+		result, err := testClusterConnectivity(clusterConfig, defaultConnectivityTimeout)
+		if err != nil {
+			return fmt.Errorf("connectivity test failed for cluster '%s' (proxy: %s): %w", name, result.Proxy, err)
 		}
+		logger.Info("Cluster is reachable", map[string]interface{}{
+			"cluster": name,
+			"version": result.ServerVersion,
+			"latency": result.Latency.String(),
+			"proxy":   result.Proxy.String(),
+		})
 	}
 
-	// If setting as primary, unset other primary clusters
-	if addPrimary {
-		for i := range cfg.MultiCluster.Clusters {
-			cfg.MultiCluster.Clusters[i].Primary = false
+	// Add to configuration, taking over the primary designation if requested
+	if _, err := store.Add(clusterConfig); err != nil {
+		if errors.Is(err, cluster.ErrClusterExists) {
+			return fmt.Errorf("cluster '%s' already exists", name)
 		}
-	}
-
-	// Create new cluster config
-	clusterConfig := config.ClusterConfig{
-		Name:       name,
-		KubeConfig: kubeconfigPath,
-		Context:    addContext,
-		Namespace:  addNamespace,
-		Enabled:    !addDisabled,
-		Primary:    addPrimary,
-	}
-
-	// Add to configuration
-	cfg.MultiCluster.Clusters = append(cfg.MultiCluster.Clusters, clusterConfig)
-
-	// Save configuration
-	if err := saveMultiClusterConfig(cfg); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
@@ -255,89 +283,123 @@ func addCluster(cmd *cobra.Command, args []string) error {
 }
 
 func listClusters(cmd *cobra.Command, args []string) error {
-	cfg, err := loadMultiClusterConfig()
+	clusters, err := newClusterStore().List()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	if len(cfg.MultiCluster.Clusters) == 0 {
+	if len(clusters) == 0 {
 		fmt.Println("No clusters configured")
 		return nil
 	}
 
+	if listServer != "" {
+		health, err := fetchClusterHealth(cmd.Context(), clusters)
+		if err != nil {
+			return err
+		}
+		printClusterHealth(clusters, health)
+		return nil
+	}
+
 	// Print header
 	fmt.Printf("%-20s %-10s %-10s %-30s %-15s\n", "NAME", "STATUS", "PRIMARY", "CONTEXT", "NAMESPACE")
 	fmt.Printf("%-20s %-10s %-10s %-30s %-15s\n", "----", "------", "-------", "-------", "---------")
 
 	// Print clusters
-	for _, cluster := range cfg.MultiCluster.Clusters {
-		status := "Disabled"
-		if cluster.Enabled {
-			status = "Enabled"
-		}
+	for _, clusterConfig := range clusters {
+		status, primary, kubeContext, namespace := describeCluster(clusterConfig)
+		fmt.Printf("%-20s %-10s %-10s %-30s %-15s\n", clusterConfig.Name, status, primary, kubeContext, namespace)
+	}
 
-		primary := ""
-		if cluster.Primary {
-			primary = "Yes"
-		}
+	return nil
+}
 
-		context := cluster.Context
-		if context == "" {
-			context = "<current>"
-		}
+// describeCluster returns the status, primary, context and namespace columns of a cluster
+func describeCluster(clusterConfig config.ClusterConfig) (status, primary, kubeContext, namespace string) {
+	status = "Disabled"
+	if clusterConfig.Enabled {
+		status = "Enabled"
+	}
 
-		namespace := cluster.Namespace
-		if namespace == "" {
-			namespace = "default"
-		}
+	if clusterConfig.Primary {
+		primary = "Yes"
+	}
 
-		fmt.Printf("%-20s %-10s %-10s %-30s %-15s\n", cluster.Name, status, primary, context, namespace)
+	kubeContext = clusterConfig.Context
+	if kubeContext == "" {
+		kubeContext = "<current>"
 	}
 
-	return nil
+	namespace = clusterConfig.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	return status, primary, kubeContext, namespace
 }
 
-func deleteCluster(cmd *cobra.Command, args []string) error {
-	cfg, err := loadMultiClusterConfig()
+// fetchClusterHealth reads the health of each cluster from the --server k6s
+// server. Clusters the server does not know are left out.
+func fetchClusterHealth(ctx context.Context, clusters []config.ClusterConfig) (map[string]*server.ClusterStatusResponse, error) {
+	token := listToken
+	if token == "" {
+		token = viper.GetString("attach.token")
+	}
+	client, err := remote.NewClient(listServer, token, "")
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return nil, err
+	}
+
+	health := make(map[string]*server.ClusterStatusResponse, len(clusters))
+	for _, c := range clusters {
+		status, err := client.ClusterStatus(ctx, c.Name)
+		var apiErr *remote.APIError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to get status of cluster %s: %w", c.Name, err)
+		}
+		health[c.Name] = status
 	}
+	return health, nil
+}
 
-	var deleted []string
-	var notFound []string
-
-	for _, name := range args {
-		found := false
-		for i, cluster := range cfg.MultiCluster.Clusters {
-			if cluster.Name == name {
-				// Remove cluster from slice
-				cfg.MultiCluster.Clusters = append(cfg.MultiCluster.Clusters[:i], cfg.MultiCluster.Clusters[i+1:]...)
-				deleted = append(deleted, name)
-				found = true
-				break
+// printClusterHealth prints clusters with the health tracked by the server
+func printClusterHealth(clusters []config.ClusterConfig, health map[string]*server.ClusterStatusResponse) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tSTATUS\tPRIMARY\tSTATE\tCACHE\tLAST HEARTBEAT\tLAST ERROR")
+	for _, c := range clusters {
+		status, primary, _, _ := describeCluster(c)
+		state, cache, heartbeat, lastError := "<unknown>", "-", "-", ""
+		if h, ok := health[c.Name]; ok {
+			state = string(h.State)
+			if h.CacheSync != "" {
+				cache = string(h.CacheSync)
 			}
+			if !h.LastHeartbeat.IsZero() {
+				heartbeat = kubernetes.FormatAge(h.LastHeartbeat) + " ago"
+			}
+			lastError = h.LastError
 		}
-		if !found {
-			notFound = append(notFound, name)
-		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Name, status, primary, state, cache, heartbeat, lastError)
 	}
+}
 
-	if len(deleted) > 0 {
-		// Save configuration
-		if err := saveMultiClusterConfig(cfg); err != nil {
-			return fmt.Errorf("failed to save configuration: %w", err)
-		}
-
-		// Output confirmation
-		for _, name := range deleted {
-			fmt.Printf("cluster/%s deleted\n", name)
-		}
-	}
+func deleteCluster(cmd *cobra.Command, args []string) error {
+	store := newClusterStore()
 
-	if len(notFound) > 0 {
-		for _, name := range notFound {
-			fmt.Printf("cluster/%s not found\n", name)
+	for _, name := range args {
+		if err := store.Delete(name); err != nil {
+			if errors.Is(err, cluster.ErrClusterNotFound) {
+				fmt.Printf("cluster/%s not found\n", name)
+				continue
+			}
+			return fmt.Errorf("failed to save configuration: %w", err)
 		}
+		fmt.Printf("cluster/%s deleted\n", name)
 	}
 
 	return nil
@@ -352,26 +414,10 @@ func disableCluster(cmd *cobra.Command, args []string) error {
 }
 
 func updateClusterStatus(name string, enabled bool, action string) error {
-	cfg, err := loadMultiClusterConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
-	}
-
-	found := false
-	for i, cluster := range cfg.MultiCluster.Clusters {
-		if cluster.Name == name {
-			cfg.MultiCluster.Clusters[i].Enabled = enabled
-			found = true
-			break
+	if _, err := newClusterStore().SetEnabled(name, enabled); err != nil {
+		if errors.Is(err, cluster.ErrClusterNotFound) {
+			return fmt.Errorf("cluster '%s' not found", name)
 		}
-	}
-
-	if !found {
-		return fmt.Errorf("cluster '%s' not found", name)
-	}
-
-	// Save configuration
-	if err := saveMultiClusterConfig(cfg); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
@@ -381,31 +427,10 @@ func updateClusterStatus(name string, enabled bool, action string) error {
 
 func setPrimaryCluster(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	cfg, err := loadMultiClusterConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
-	}
-
-	found := false
-	for i, cluster := range cfg.MultiCluster.Clusters {
-		if cluster.Name == name {
-			// Unset all other primary flags
-			for j := range cfg.MultiCluster.Clusters {
-				cfg.MultiCluster.Clusters[j].Primary = false
-			}
-			// Set this cluster as primary
-			cfg.MultiCluster.Clusters[i].Primary = true
-			found = true
-			break
+	if _, err := newClusterStore().SetPrimary(name); err != nil {
+		if errors.Is(err, cluster.ErrClusterNotFound) {
+			return fmt.Errorf("cluster '%s' not found", name)
 		}
-	}
-
-	if !found {
-		return fmt.Errorf("cluster '%s' not found", name)
-	}
-
-	// Save configuration
-	if err := saveMultiClusterConfig(cfg); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
@@ -414,138 +439,97 @@ func setPrimaryCluster(cmd *cobra.Command, args []string) error {
 }
 
 func checkConnectivity(cmd *cobra.Command, args []string) error {
-	cfg, err := loadMultiClusterConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+	if connectivityTimeout <= 0 {
+		return fmt.Errorf("--timeout must be positive, got %v", connectivityTimeout)
+	}
+	if connectivityOutput != "text" && connectivityOutput != "json" {
+		return fmt.Errorf("invalid output format %q, must be text or json", connectivityOutput)
 	}
 
+	store := newClusterStore()
+
 	var clustersToCheck []config.ClusterConfig
 
 	if len(args) == 1 {
 		// Check specific cluster
 		name := args[0]
-		found := false
-		for _, cluster := range cfg.MultiCluster.Clusters {
-			if cluster.Name == name {
-				clustersToCheck = append(clustersToCheck, cluster)
-				found = true
-				break
-			}
-		}
-		if !found {
+		clusterConfig, err := store.Get(name)
+		if errors.Is(err, cluster.ErrClusterNotFound) {
 			return fmt.Errorf("cluster '%s' not found", name)
 		}
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		clustersToCheck = append(clustersToCheck, clusterConfig)
 	} else {
 		// Check all clusters
-		clustersToCheck = cfg.MultiCluster.Clusters
+		clusters, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		clustersToCheck = clusters
 	}
 
-	if len(clustersToCheck) == 0 {
+	if len(clustersToCheck) == 0 && connectivityOutput == "text" {
 		fmt.Println("No clusters to check")
 		return nil
 	}
 
-	fmt.Printf("%-20s %-15s %-50s\n", "NAME", "STATUS", "MESSAGE")
-	fmt.Printf("%-20s %-15s %-50s\n", "----", "------", "-------")
+	report := cluster.CheckAll(clustersToCheck, connectivityTimeout, connectivityConcurrency())
+	if connectivityOutput == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Printf("%-20s %-15s %-15s %-10s %-40s %-50s\n", "NAME", "STATUS", "VERSION", "LATENCY", "PROXY", "MESSAGE")
+	fmt.Printf("%-20s %-15s %-15s %-10s %-40s %-50s\n", "----", "------", "-------", "-------", "-----", "-------")
 
-	for _, cluster := range clustersToCheck {
+	for _, result := range report.Clusters {
 		status := "Reachable"
-		message := "Connection successful"
+		version := result.ServerVersion
+		latency := "-"
+		message := result.Message
 
-		err := testClusterConnectivity(cluster.KubeConfig, cluster.Context)
-		if err != nil {
+		if !result.Reachable {
 			status = "Unreachable"
-			message = err.Error()
+			version = "-"
 			if len(message) > 47 {
 				message = message[:47] + "..."
 			}
 		}
+		if result.LatencyMs > 0 {
+			latency = time.Duration(result.LatencyMs * float64(time.Millisecond)).Round(time.Millisecond).String()
+		}
 
-		fmt.Printf("%-20s %-15s %-50s\n", cluster.Name, status, message)
+		fmt.Printf("%-20s %-15s %-15s %-10s %-40s %-50s\n", result.Name, status, version, latency, result.Proxy, message)
 	}
 
 	return nil
 }
 
-func testClusterConnectivity(kubeconfigPath, contextName string) error {
-	// Build config from kubeconfig
-	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
-		&clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load kubeconfig: %w", err)
-	}
+// defaultConnectivityTimeout bounds a connectivity check unless --timeout is set
+const defaultConnectivityTimeout = 5 * time.Second
 
-	// Set a reasonable timeout
-	config.Timeout = 5 * time.Second
-
-	return nil
+// testClusterConnectivity asks the cluster's API server for its version within
+// timeout and reports the version, latency and proxy used to reach it
+func testClusterConnectivity(clusterConfig config.ClusterConfig, timeout time.Duration) (cluster.ConnectivityResult, error) {
+	return cluster.CheckConnectivity(clusterConfig, timeout)
 }
 
-func loadMultiClusterConfig() (*config.Config, error) {
-	// Default config path
-	home, err := os.UserHomeDir()
+// connectivityConcurrency returns how many clusters are checked at once:
+// multi_cluster.max_concurrent_connections, or its default when the
+// configuration can't be loaded
+func connectivityConcurrency() int {
+	cfg, err := config.LoadConfig(cfgFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return config.DefaultConfig().MultiCluster.MaxConcurrentConns
 	}
-
-	configPath := filepath.Join(home, ".k6s", "k6s.yaml")
-
-	// Check if config exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// Create default config
-		cfg := config.DefaultConfig()
-
-		// Ensure directory exists
-		if err := os.MkdirAll(filepath.Dir(configPath), 0750); err != nil {
-			return nil, fmt.Errorf("failed to create config directory: %w", err)
-		}
-
-		return cfg, nil
-	}
-
-	// Validate file path to prevent directory traversal attacks
-	if err := validateClusterConfigPath(configPath); err != nil {
-		return nil, fmt.Errorf("invalid config file path: %w", err)
-	}
-
-	// Load existing config
-	data, err := os.ReadFile(configPath) // #nosec G304 - path is validated above
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	var cfg config.Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
-
-	return &cfg, nil
+	return cfg.MultiCluster.MaxConcurrentConns
 }
 
-func saveMultiClusterConfig(cfg *config.Config) error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	configPath := filepath.Join(home, ".k6s", "k6s.yaml")
-
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(configPath), 0750); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	// Marshal config to YAML
-	data, err := yaml.Marshal(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
-
-	// Write file
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
-	}
-
-	return nil
+// newClusterStore returns the store editing the clusters of the configuration
+// file (--config, or ~/.k6s/k6s.yaml)
+func newClusterStore() *cluster.ConfigStore {
+	return cluster.NewConfigStore(cfgFile)
 }
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// completionTimeout bounds the requests listing the names completed
+const completionTimeout = 5 * time.Second
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate shell completion scripts",
+	Long: `Generate the completion script of k6s for a shell.
+
+Besides commands and flags, deployment names, namespaces, cluster names and
+kubeconfig contexts are completed live: deployments and namespaces from the
+cluster of --kubeconfig, or from the k6s server of --server for attach
+commands, and clusters from the configuration file.
+
+Examples:
+  # Bash, for the current shell or every new one
+  source <(k6s completion bash)
+  k6s completion bash > /etc/bash_completion.d/k6s
+
+  # Zsh
+  k6s completion zsh > "${fpath[1]}/_k6s"
+
+  # Fish
+  k6s completion fish > ~/.config/fish/completions/k6s.fish
+
+  # PowerShell
+  k6s completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		default:
+			return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+
+	for _, c := range []*cobra.Command{
+		deploymentDeleteCmd, deploymentDescribeCmd, deploymentDiffCmd, deploymentHistoryCmd,
+		setImageCmd, deploymentPatchCmd,
+		rolloutStatusCmd, rolloutRestartCmd, rolloutPauseCmd, rolloutResumeCmd, rolloutUndoCmd,
+		attachGetCmd, attachScaleCmd,
+	} {
+		c.ValidArgsFunction = completeDeploymentName
+	}
+	deploymentScaleCmd.ValidArgsFunction = completeDeploymentNames
+	podGetCmd.ValidArgsFunction = completePodName
+	podDeleteCmd.ValidArgsFunction = completePodName
+	logsCmd.ValidArgsFunction = completePodName
+
+	for _, c := range []*cobra.Command{enableClusterCmd, disableClusterCmd, setPrimaryCmd, checkConnectivityCmd} {
+		c.ValidArgsFunction = completeClusterName
+	}
+	deleteClusterCmd.ValidArgsFunction = completeClusterNames
+}
+
+// registerFlagCompletions completes the --namespace, --cluster and --context
+// flags of every command. It runs once all commands have added their flags.
+func registerFlagCompletions(cmd *cobra.Command) {
+	completions := map[string]cobra.CompletionFunc{
+		"namespace": completeNamespaces,
+		"cluster":   completeClusterFlag,
+		"context":   completeContexts,
+	}
+	register := func(flag *pflag.Flag) {
+		if fn, ok := completions[flag.Name]; ok {
+			// Persistent flags are seen again on subcommands; the first
+			// registration applies to them
+			_ = cmd.RegisterFlagCompletionFunc(flag.Name, fn)
+		}
+	}
+	cmd.PersistentFlags().VisitAll(register)
+	cmd.LocalNonPersistentFlags().VisitAll(register)
+
+	for _, sub := range cmd.Commands() {
+		registerFlagCompletions(sub)
+	}
+}
+
+// completeDeploymentName completes the argument of commands taking one
+// deployment name
+func completeDeploymentName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeDeploymentNames(cmd, args, toComplete)
+}
+
+// completeDeploymentNames completes the deployments of the namespace of
+// --namespace that aren't arguments yet
+func completeDeploymentNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	namespace, _ := cmd.Flags().GetString("namespace")
+	names, err := listDeploymentNames(cmd, namespace)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError | cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterCompletions(names, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePodName completes the argument of commands taking one pod name
+// with the pods of the namespace of --namespace
+func completePodName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	namespace, _ := cmd.Flags().GetString("namespace")
+	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+	client, err := kubernetes.NewClient(kubeconfig)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError | cobra.ShellCompDirectiveNoFileComp
+	}
+	pods, err := client.Clientset().CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError | cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	return filterCompletions(names, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNamespaces completes namespace names
+func completeNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	namespaces, err := listNamespaceNames(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError | cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterCompletions(namespaces, nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeClusterName completes the argument of commands taking one cluster
+// name
+func completeClusterName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeClusterNames(cmd, args, toComplete)
+}
+
+// completeClusterFlag completes --cluster with the names of the clusters of
+// the configuration file
+func completeClusterFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeClusterNames(cmd, nil, toComplete)
+}
+
+// completeClusterNames completes the names of the clusters of the
+// configuration file that aren't arguments yet
+func completeClusterNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	clusters, err := newClusterStore().List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError | cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(clusters))
+	for _, c := range clusters {
+		names = append(names, c.Name)
+	}
+	return filterCompletions(names, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeContexts completes the contexts of the kubeconfig of --kubeconfig
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+	return filterCompletions(kubernetes.Contexts(kubeconfig), nil, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// listDeploymentNames lists the deployments of namespace through the k6s
+// server of attach commands, or the cluster of --kubeconfig otherwise
+func listDeploymentNames(cmd *cobra.Command, namespace string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	if isAttachCommand(cmd) {
+		client, err := newRemoteClient()
+		if err != nil {
+			return nil, err
+		}
+		deployments, err := client.ListDeployments(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(deployments))
+		for _, dep := range deployments {
+			names = append(names, dep.Name)
+		}
+		return names, nil
+	}
+
+	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+	client, err := kubernetes.NewClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := client.Clientset().AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(deployments.Items))
+	for _, dep := range deployments.Items {
+		names = append(names, dep.Name)
+	}
+	return names, nil
+}
+
+// listNamespaceNames lists the namespaces of the deployments of the k6s
+// server of attach commands, or the namespaces of the cluster of
+// --kubeconfig otherwise
+func listNamespaceNames(cmd *cobra.Command) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	if isAttachCommand(cmd) {
+		client, err := newRemoteClient()
+		if err != nil {
+			return nil, err
+		}
+		deployments, err := client.ListDeployments(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		var namespaces []string
+		for _, dep := range deployments {
+			if !seen[dep.Namespace] {
+				seen[dep.Namespace] = true
+				namespaces = append(namespaces, dep.Namespace)
+			}
+		}
+		return namespaces, nil
+	}
+
+	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+	client, err := kubernetes.NewClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.Clientset().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	return namespaces, nil
+}
+
+// isAttachCommand reports whether cmd runs against a k6s server
+func isAttachCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == attachCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// filterCompletions returns the sorted names starting with toComplete that
+// aren't in args
+func filterCompletions(names, args []string, toComplete string) []string {
+	given := make(map[string]bool, len(args))
+	for _, arg := range args {
+		given[arg] = true
+	}
+	var completions []string
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) && !given[name] {
+			completions = append(completions, name)
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command group
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration file",
+	Long:  `Describe and check the k6s configuration file.`,
+}
+
+// configSchemaCmd represents the config schema command
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Long: `Print the JSON Schema of the configuration file, with the default of each
+setting. Editors with YAML language support use it to complete and check keys,
+e.g. with a "# yaml-language-server: $schema=k6s.schema.json" comment.
+
+Examples:
+  k6s config schema > k6s.schema.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(config.Schema()); err != nil {
+			fmt.Fprintf(os.Stderr, "error encoding schema: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [FILE]",
+	Short: "Check a configuration file",
+	Long: `Parse a configuration file strictly, rejecting unknown and duplicate keys
+such as a misspelt resync_period, and validate its settings. FILE defaults to
+--config or ~/.k6s/k6s.yaml.
+
+Examples:
+  k6s config validate
+  k6s config validate examples/config/production.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := cfgFile
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if path == "" {
+			path = config.GetDefaultConfigPath()
+		}
+		if _, err := os.Stat(path); err != nil {
+			fmt.Fprintf(os.Stderr, "error reading config file: %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg, err := config.LoadConfigStrict(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if err := config.NewConfigValidator(cfg).ValidateAll(); err != nil {
+			fmt.Fprintf(os.Stderr, "configuration validation failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s is valid\n", path)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
@@ -8,11 +8,17 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/controller"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/telemetry"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 // controllerCmd represents the controller command group
@@ -62,7 +68,14 @@ Examples:
   k6s controller start --mode multi --config ~/.k6s/clusters.yaml
 
   # Start with debug logging and custom metrics port
-  k6s controller start --log-level debug --metrics-port 9090`,
+  k6s controller start --log-level debug --metrics-port 9090
+
+  # Observe deployments without writing to them
+  k6s controller start --read-only
+
+  # Read the configuration from the k6s.yaml key of a ConfigMap in the
+  # pod's namespace, reconfiguring when it changes
+  k6s controller start --config-map k6s-config`,
 	RunE: runController,
 }
 
@@ -72,6 +85,7 @@ var (
 
 	// Single cluster configuration
 	namespace               string
+	excludeNamespaces       []string
 	metricsPort            int
 	healthPort             int
 	enableLeaderElection   bool
@@ -85,6 +99,7 @@ var (
 	// Common options
 	kubeconfig string
 	inCluster  bool
+	readOnly   bool
 )
 
 func init() {
@@ -99,12 +114,16 @@ func init() {
 	_ = viper.BindPFlag("controller.mode", startCmd.Flags().Lookup("mode"))
 
 	// Single cluster flags
-	startCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "namespace to watch (empty = all namespaces)")
+	startCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "comma-separated namespaces to watch (empty = all namespaces)")
+	startCmd.Flags().StringSliceVar(&excludeNamespaces, "exclude-namespaces", nil, "comma-separated namespaces not to watch")
 	startCmd.Flags().IntVar(&metricsPort, "metrics-port", 8080, "port for metrics endpoint")
 	startCmd.Flags().IntVar(&healthPort, "health-port", 8081, "port for health probes")
 	startCmd.Flags().BoolVar(&enableLeaderElection, "enable-leader-election", true, "enable leader election for controller manager")
 	startCmd.Flags().StringVar(&leaderElectionID, "leader-election-id", "k6s-controller", "leader election ID")
 	startCmd.Flags().StringVar(&leaderElectionNamespace, "leader-election-namespace", "default", "namespace for leader election")
+	startCmd.Flags().Duration("leader-election-lease-duration", 0, "how long other replicas wait before taking over an unrenewed lease (default controller.single.leader_election.lease_duration or 15s)")
+	startCmd.Flags().Duration("leader-election-renew-deadline", 0, "how long the leader retries renewing the lease before giving up leadership (default controller.single.leader_election.renew_deadline or 10s)")
+	startCmd.Flags().Duration("leader-election-retry-period", 0, "how often replicas try to acquire or renew the lease (default controller.single.leader_election.retry_period or 2s)")
 
 	// Multi-cluster flags
 	startCmd.Flags().StringVar(&configFile, "config-file", "", "path to multi-cluster configuration file")
@@ -113,6 +132,9 @@ func init() {
 	// Common flags
 	startCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "path to kubeconfig file (default: auto-detect)")
 	startCmd.Flags().BoolVar(&inCluster, "in-cluster", false, "use in-cluster configuration")
+	addKubeClientFlags(startCmd)
+	addConfigMapFlags(startCmd)
+	startCmd.Flags().BoolVar(&readOnly, "read-only", false, "only observe deployments, without writing status annotations or enforcing policies")
 
 	// Bind flags to viper
 	_ = viper.BindPFlag("controller.single.namespace", startCmd.Flags().Lookup("namespace"))
@@ -125,6 +147,7 @@ func init() {
 	_ = viper.BindPFlag("controller.resync_period", startCmd.Flags().Lookup("resync-period"))
 	_ = viper.BindPFlag("kubeconfig", startCmd.Flags().Lookup("kubeconfig"))
 	_ = viper.BindPFlag("in-cluster", startCmd.Flags().Lookup("in-cluster"))
+	_ = viper.BindPFlag("controller.read_only", startCmd.Flags().Lookup("read-only"))
 }
 
 func runController(cmd *cobra.Command, args []string) error {
@@ -137,6 +160,10 @@ func runController(cmd *cobra.Command, args []string) error {
 	}
 	logger.Init(loggerConfig)
 
+	// Route controller-runtime logs, such as the webhook server's, through
+	// the unified logger
+	ctrllog.SetLogger(logger.WithComponent("controller-runtime").GetLogr())
+
 	log := logger.WithComponent("controller-cmd")
 
 	// Load configuration
@@ -148,21 +175,24 @@ func runController(cmd *cobra.Command, args []string) error {
 		"config_path": configPath,
 	})
 	
-	cfg, err := config.LoadConfig(configPath)
+	// Load and validate configuration; the reloader keeps watching the file,
+	// or the ConfigMap of --config-map
+	reloader, err := newConfigReloader(cmd, configPath, kubeconfig)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Validate configuration
-	validator := config.NewConfigValidator(cfg)
-	if err := validator.ValidateAll(); err != nil {
-		return fmt.Errorf("configuration validation failed: %w", err)
-	}
+	// Flag overrides go into a copy so that reloads only compare file contents
+	fileConfig := *reloader.Current()
+	cfg := &fileConfig
 
 	// Override with command-line flags
 	if cmd.Flags().Changed("namespace") {
 		cfg.Controller.Single.Namespace = viper.GetString("controller.single.namespace")
 	}
+	if cmd.Flags().Changed("exclude-namespaces") {
+		cfg.Controller.Single.ExcludeNamespaces = excludeNamespaces
+	}
 	if cmd.Flags().Changed("metrics-port") {
 		cfg.Controller.Single.MetricsPort = viper.GetInt("controller.single.metrics_port")
 	}
@@ -172,6 +202,24 @@ func runController(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("enable-leader-election") {
 		cfg.Controller.Single.LeaderElection.Enabled = viper.GetBool("controller.single.leader_election.enabled")
 	}
+	if cmd.Flags().Changed("read-only") {
+		cfg.Controller.ReadOnly = viper.GetBool("controller.read_only")
+	}
+	if cmd.Flags().Changed("leader-election-lease-duration") {
+		cfg.Controller.Single.LeaderElection.LeaseDuration, _ = cmd.Flags().GetDuration("leader-election-lease-duration")
+	}
+	if cmd.Flags().Changed("leader-election-renew-deadline") {
+		cfg.Controller.Single.LeaderElection.RenewDeadline, _ = cmd.Flags().GetDuration("leader-election-renew-deadline")
+	}
+	if cmd.Flags().Changed("leader-election-retry-period") {
+		cfg.Controller.Single.LeaderElection.RetryPeriod, _ = cmd.Flags().GetDuration("leader-election-retry-period")
+	}
+	applyKubeClientFlags(cmd, &cfg.Controller.Client)
+	if err := config.NewConfigValidator(cfg).ValidateController(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	applyComponentLogLevels(cfg.Log.Levels)
+	applyLogSampling(cfg.Log.Sampling)
 
 	// Determine mode
 	mode := viper.GetString("controller.mode")
@@ -181,23 +229,91 @@ func runController(cmd *cobra.Command, args []string) error {
 
 	log.Info("Starting k6s controller", map[string]interface{}{
 		"mode":       mode,
-		"version":    Version,
-		"namespace":  cfg.Controller.Single.Namespace,
+		"version":    version.Version,
+		"namespaces": cfg.Controller.Single.NamespaceScope().String(),
 		"metrics":    fmt.Sprintf(":%d", cfg.Controller.Single.MetricsPort),
 		"health":     fmt.Sprintf(":%d", cfg.Controller.Single.HealthPort),
 		"clusters":   len(cfg.MultiCluster.Clusters),
 	})
 
+	// Export spans of reconciliations when enabled
+	if cfg.Telemetry.Enabled {
+		shutdownTelemetry, err := telemetry.Setup(context.Background(), cfg.Telemetry)
+		if err != nil {
+			return fmt.Errorf("failed to setup telemetry: %w", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTelemetry(ctx); err != nil {
+				log.Warn("Failed to flush telemetry", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}()
+	}
+
 	// Create controller manager
 	mgr, err := controller.NewManager(cfg, mode)
 	if err != nil {
 		return fmt.Errorf("failed to create controller manager: %w", err)
 	}
+	mgr.SetVersion(version.Version)
+	if reloader.Path() != "" {
+		// Failover promotions are written to the configuration file; a
+		// ConfigMap is only read
+		mgr.SetClusterStore(cluster.NewConfigStore(reloader.Path()))
+	} else if mode == "multi" && cfg.MultiCluster.Failover.Enabled {
+		log.Warn("Primary cluster failover disabled: it writes promotions to the configuration file, and a ConfigMap configuration is read-only", map[string]interface{}{
+			"source": reloader.Name(),
+		})
+	}
+	if err := metrics.RegisterBuildInfo(ctrlmetrics.Registry); err != nil {
+		log.Warn("Failed to register build info metric", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if err := metrics.RegisterClusterManagerMetrics(ctrlmetrics.Registry); err != nil {
+		log.Warn("Failed to register cluster manager metrics", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if err := metrics.RegisterLeaderElectionMetrics(ctrlmetrics.Registry); err != nil {
+		log.Warn("Failed to register leader election metrics", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if err := metrics.RegisterShardingMetrics(ctrlmetrics.Registry); err != nil {
+		log.Warn("Failed to register sharding metrics", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if err := metrics.RegisterClusterClientMetrics(ctrlmetrics.Registry); err != nil {
+		log.Warn("Failed to register cluster client metrics", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if m, err := metrics.NewWithRegisterer(ctrlmetrics.Registry); err != nil {
+		log.Warn("Failed to register reconcile metrics", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		mgr.SetMetrics(m)
+	}
 
 	// Setup signal handling
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// Apply configuration file changes at runtime
+	reloader.Subscribe(reloadLogLevel)
+	reloader.Subscribe(mgr.ReloadClusters)
+	reloader.Subscribe(mgr.ReloadGitOps)
+	reloader.Subscribe(mgr.ReloadWebhook)
+	reloader.Subscribe(mgr.ReloadNotifications)
+	startConfigReloader(ctx, reloader)
+	restoreLogLevelOnSIGHUP(ctx, reloader)
+
 	// Setup graceful shutdown
 	go func() {
 		<-ctx.Done()
@@ -5,27 +5,57 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/batch"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
 	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
-	deployAllNamespaces   bool
-	deployKubeconfig      string
-	deployCreateImage     string
-	deployCreateReplicas  int32
-	deployCreateNamespace string
-	deployDeleteNamespace string
-	deployWatch           bool
-	deployWatchResync     time.Duration
-	deployNamespace       string
-	deployCustomLogic     bool
+	deployAllNamespaces     bool
+	deployAllClusters       bool
+	deployKubeconfig        string
+	deployCreateImage       string
+	deployCreateReplicas    int32
+	deployCreateNamespace   string
+	deployDeleteNamespace   string
+	deployUndeleteNamespace string
+	deployUndeleteList      bool
+	deployUndeleteMismatch  bool
+	deployScaleNamespace    string
+	deployScaleAll          bool
+	deployScaleSelector     string
+	deployScaleReplicas     int32
+	deployScaleYes          bool
+	deployWatch             bool
+	deployWatchResync       time.Duration
+	deployNamespace         string
+	deployCustomLogic       bool
+	deployOutput            string
+	deploySortBy            string
+	deployListSelector      string
+	deployFieldSelector     string
+	deployShowLabels        bool
 )
 
+// deployOutputJSONStream prints deployment watch events as one JSON object
+// per line
+const deployOutputJSONStream = "json-stream"
+
+// deployOutputWide prints the containers, images, selector and strategy of
+// listed deployments
+const deployOutputWide = "wide"
+
 // deploymentCmd represents the deployment command group
 var deploymentCmd = &cobra.Command{
 	Use:     "deployment",
@@ -38,13 +68,40 @@ var deploymentCmd = &cobra.Command{
 var deploymentListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List Kubernetes deployments",
-	Long:  `List Kubernetes deployments in the specified namespace or all namespaces. Use --watch to monitor for changes.`,
+	Long: `List Kubernetes deployments in the specified namespace or all namespaces. Use --watch to monitor for changes.
+
+Like kubectl get, --selector and --field-selector (e.g. metadata.name=web)
+filter the deployments, --sort-by sorts them by a JSONPath field such as
+.metadata.creationTimestamp or .status.readyReplicas, --show-labels adds a
+LABELS column and -o wide adds the containers, images, selector and strategy.
+
+With --all-clusters, deployments of every enabled cluster of the configuration
+file are listed concurrently, with a CLUSTER column.
+
+With --watch --output json-stream, each event is printed on stdout as one JSON
+object per line with its type (ADDED, MODIFIED or DELETED), the deployment and
+its changes, and only errors are logged, for jq pipelines and automation.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		client, err := kubernetes.NewClient(deployKubeconfig)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+		switch deployOutput {
+		case "table", deployOutputWide:
+		case deployOutputJSONStream:
+			if !deployWatch {
+				fmt.Fprintln(os.Stderr, "--output json-stream requires --watch")
+				os.Exit(1)
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "unsupported output format %q (use table, wide or json-stream)\n", deployOutput)
 			os.Exit(1)
 		}
+		if deployWatch && (deployOutput == deployOutputWide || deploySortBy != "" || deployListSelector != "" || deployFieldSelector != "" || deployShowLabels) {
+			fmt.Fprintln(os.Stderr, "--sort-by, --selector, --field-selector, --show-labels and --output wide cannot be combined with --watch")
+			os.Exit(1)
+		}
+		printOptions := kubernetes.DeploymentPrintOptions{
+			ShowNamespace: deployAllNamespaces,
+			Wide:          deployOutput == deployOutputWide,
+			ShowLabels:    deployShowLabels,
+		}
 
 		// Determine namespace
 		namespace := deployNamespace
@@ -52,6 +109,24 @@ var deploymentListCmd = &cobra.Command{
 			namespace = ""
 		}
 
+		if deployAllClusters {
+			if deployWatch {
+				fmt.Fprintln(os.Stderr, "--all-clusters cannot be combined with --watch")
+				os.Exit(1)
+			}
+			if err := listAllClusterDeployments(namespace, printOptions); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		client, err := kubernetes.NewClient(deployKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
 		if deployWatch {
 			// Get configuration for informer
 			cfg, err := config.LoadConfig("")
@@ -70,6 +145,9 @@ var deploymentListCmd = &cobra.Command{
 
 			// Watch mode using informer with config
 			informer := kubernetes.NewDeploymentInformerWithConfig(client.Clientset(), cfg)
+			if deployOutput == deployOutputJSONStream {
+				informer.ReplaceEventHandlers(kubernetes.NewJSONStreamEventHandler(os.Stdout))
+			}
 
 			err = informer.Start()
 			if err != nil {
@@ -87,13 +165,17 @@ var deploymentListCmd = &cobra.Command{
 			informer.Stop()
 		} else {
 			// Regular list mode
-			deployments, err := client.DeploymentList(namespace)
+			deployments, err := client.DeploymentListFiltered(namespace, deployListSelector, deployFieldSelector)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "error listing deployments: %v\n", err)
 				os.Exit(1)
 			}
+			if err := kubernetes.SortDeployments(deployments.Items, deploySortBy); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
 
-			kubernetes.DeploymentPrint(deployments.Items, deployAllNamespaces)
+			kubernetes.DeploymentPrint(deployments.Items, printOptions)
 		}
 	},
 }
@@ -151,16 +233,333 @@ var deploymentDeleteCmd = &cobra.Command{
 			deployDeleteNamespace = "default"
 		}
 
-		err = client.DeploymentDelete(deployDeleteNamespace, name)
+		store, err := newHistoryStore()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error opening history store: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Snapshot the manifest first so the deletion can be undone
+		deployment, err := client.DeploymentGet(deployDeleteNamespace, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error getting deployment: %v\n", err)
+			os.Exit(1)
+		}
+		entry, err := store.SaveDeleted(deploymentCluster(deployKubeconfig), deployment)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error saving deployment snapshot: %v\n", err)
+			os.Exit(1)
+		}
+
+		err = client.DeploymentDelete(context.TODO(), deployDeleteNamespace, name, "")
 		if err != nil {
+			_ = store.Remove(entry)
 			fmt.Fprintf(os.Stderr, "error deleting deployment: %v\n", err)
 			os.Exit(1)
 		}
 
 		fmt.Printf("deployment.apps \"%s\" deleted\n", name)
+		fmt.Printf("undelete until %s with: k6s deployment undelete %s -n %s\n",
+			entry.ExpiresAt.Format(time.RFC3339), name, deployDeleteNamespace)
+	},
+}
+
+// deploymentUndeleteCmd represents the deployment undelete command
+var deploymentUndeleteCmd = &cobra.Command{
+	Use:   "undelete [NAME]",
+	Short: "Restore a deployment deleted via k6s",
+	Long: `Recreate a deployment from the snapshot taken when it was deleted with
+"k6s deployment delete". Snapshots are kept for the history retention window
+(history.retention, default 7 days). Use --list to show deleted deployments.
+
+A deployment is only restored to the cluster it was deleted from, the current
+kubeconfig context when it was deleted, unless --allow-cluster-mismatch is set.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if deployUndeleteList {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		store, err := newHistoryStore()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error opening history store: %v\n", err)
+			os.Exit(1)
+		}
+
+		if deployUndeleteList {
+			entries, err := store.Deleted()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error listing deleted deployments: %v\n", err)
+				os.Exit(1)
+			}
+			printDeletedDeployments(entries)
+			return
+		}
+
+		name := args[0]
+		entry, err := store.LatestDeleted(deployUndeleteNamespace, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if target := deploymentCluster(deployKubeconfig); entry.Cluster != target && !deployUndeleteMismatch {
+			source := entry.Cluster
+			if source == "" {
+				source = "an unrecorded cluster"
+			}
+			fmt.Fprintf(os.Stderr, "error: %s/%s was deleted from %s, not %s; select its context or pass --allow-cluster-mismatch to restore it here\n",
+				entry.Namespace, entry.Name, source, target)
+			os.Exit(1)
+		}
+		deployment, err := store.Load(entry)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		client, err := kubernetes.NewClient(deployKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		if _, err := client.DeploymentCreateFrom(context.TODO(), deployment); err != nil {
+			fmt.Fprintf(os.Stderr, "error restoring deployment: %v\n", err)
+			os.Exit(1)
+		}
+		if err := store.Remove(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+
+		fmt.Printf("deployment.apps \"%s\" restored (deleted %s ago)\n", name, kubernetes.FormatAge(entry.DeletedAt))
+	},
+}
+
+// deploymentScaleCmd represents the deployment scale command
+var deploymentScaleCmd = &cobra.Command{
+	Use:   "scale [NAME...]",
+	Short: "Scale one or more deployments",
+	Long: `Set the replica count of the named deployments, or of every deployment
+matching --selector. The changes are shown as a diff and applied once
+confirmed; --yes skips the prompt and is required when stdin is not a terminal.
+
+A deployment modified between the diff and the confirmation is not scaled.
+
+Examples:
+  k6s deployment scale web api --replicas 3 -n prod
+  k6s deployment scale -l tier=frontend -A --replicas 0 --yes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !cmd.Flags().Changed("replicas") || deployScaleReplicas < 0 {
+			fmt.Fprintf(os.Stderr, "error: --replicas must be set to a non-negative number\n")
+			os.Exit(1)
+		}
+		if (len(args) == 0) == (deployScaleSelector == "") {
+			fmt.Fprintf(os.Stderr, "error: specify either deployment names or --selector\n")
+			os.Exit(1)
+		}
+
+		client, err := kubernetes.NewClient(deployKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		deployments, err := selectDeployments(client, args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		plan := batch.Scale(kubernetes.CurrentContext(deployKubeconfig), deployments, deployScaleReplicas)
+		if plan.Empty() {
+			plan.Render(os.Stdout)
+			return
+		}
+
+		if deployScaleYes {
+			plan.Render(os.Stdout)
+			fmt.Println()
+		} else {
+			if !stdinIsTerminal() {
+				fmt.Fprintf(os.Stderr, "error: stdin is not a terminal, pass --yes to apply without confirmation\n")
+				os.Exit(1)
+			}
+			approved, err := batch.Confirm(plan, os.Stdin, os.Stdout)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			if !approved {
+				fmt.Println("Aborted, no changes applied")
+				return
+			}
+		}
+
+		failed := 0
+		for _, change := range plan.Changes {
+			// Only apply the change to the version the diff was shown for
+			_, err := client.DeploymentScale(cmd.Context(), change.Namespace, change.Name, deployScaleReplicas, change.ResourceVersion)
+			if apierrors.IsConflict(err) {
+				fmt.Fprintf(os.Stderr, "error: deployment %s/%s was modified since the diff was shown, not scaled; run the command again to review it\n", change.Namespace, change.Name)
+				failed++
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error scaling deployment %s/%s: %v\n", change.Namespace, change.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("deployment.apps/%s scaled\n", change.Name)
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
 	},
 }
 
+// selectDeployments returns the named deployments, or those matching --selector
+func selectDeployments(client *kubernetes.Client, names []string) ([]*appsv1.Deployment, error) {
+	namespace := deployScaleNamespace
+	if len(names) == 0 {
+		if deployScaleAll {
+			namespace = ""
+		}
+		list, err := client.DeploymentListSelected(namespace, deployScaleSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments: %w", err)
+		}
+		deployments := make([]*appsv1.Deployment, 0, len(list.Items))
+		for i := range list.Items {
+			deployments = append(deployments, &list.Items[i])
+		}
+		return deployments, nil
+	}
+
+	deployments := make([]*appsv1.Deployment, 0, len(names))
+	for _, name := range names {
+		deployment, err := client.DeploymentGet(namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment %s/%s: %w", namespace, name, err)
+		}
+		deployments = append(deployments, deployment)
+	}
+	return deployments, nil
+}
+
+// listAllClusterDeployments prints the deployments of every enabled cluster
+// of the configuration file, sorted within each cluster. Clusters that cannot
+// be listed are reported on stderr, and make the command fail after the
+// others are printed.
+func listAllClusterDeployments(namespace string, opts kubernetes.DeploymentPrintOptions) error {
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var clusters []config.ClusterConfig
+	for _, c := range cfg.MultiCluster.Clusters {
+		if c.Enabled {
+			clusters = append(clusters, c)
+		}
+	}
+	if len(clusters) == 0 {
+		return fmt.Errorf("no enabled clusters configured; add one with 'k6s cluster add'")
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: deployListSelector, FieldSelector: deployFieldSelector}
+	results := cluster.ListDeployments(clusters, namespace, listOptions, cfg.MultiCluster.ConnectionTimeout, cfg.MultiCluster.MaxConcurrentConns)
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "error listing deployments of cluster %s: %v\n", result.Cluster, result.Err)
+			failed++
+			continue
+		}
+		if err := kubernetes.SortDeployments(result.Deployments, deploySortBy); err != nil {
+			return err
+		}
+	}
+	printClusterDeployments(results, opts)
+
+	if failed > 0 {
+		return fmt.Errorf("failed to list deployments of %d of %d clusters", failed, len(results))
+	}
+	return nil
+}
+
+// printClusterDeployments prints deployments like DeploymentPrint, with the
+// cluster of each one
+func printClusterDeployments(results []cluster.ClusterDeployments, opts kubernetes.DeploymentPrintOptions) {
+	count := 0
+	for _, result := range results {
+		count += len(result.Deployments)
+	}
+	if count == 0 {
+		fmt.Println("No resources found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "CLUSTER\t%s\n", strings.Join(kubernetes.DeploymentColumns(opts), "\t"))
+	for _, result := range results {
+		for i := range result.Deployments {
+			row := kubernetes.DeploymentRow(&result.Deployments[i], opts)
+			fmt.Fprintf(w, "%s\t%s\n", result.Cluster, strings.Join(row, "\t"))
+		}
+	}
+}
+
+// stdinIsTerminal reports whether stdin is interactive, so that prompts can be answered
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// newHistoryStore opens the history store configured in the k6s configuration
+func newHistoryStore() (*history.Store, error) {
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	return history.NewStore(cfg.History.Dir, cfg.History.Retention), nil
+}
+
+// deploymentCluster identifies the cluster deployment commands reach with
+// kubeconfig: its current context, or the API server of the in-cluster config
+func deploymentCluster(kubeconfig string) string {
+	if kubeContext := kubernetes.CurrentContext(kubeconfig); kubeContext != "" {
+		return kubeContext
+	}
+	restConfig, err := kubernetes.RestConfig(kubeconfig)
+	if err != nil {
+		return ""
+	}
+	return restConfig.Host
+}
+
+// printDeletedDeployments prints the recycle bin in kubectl-like format
+func printDeletedDeployments(entries []history.Entry) {
+	if len(entries) == 0 {
+		fmt.Println("No deleted deployments found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tCLUSTER\tDELETED\tEXPIRES")
+	for _, entry := range entries {
+		cluster := entry.Cluster
+		if cluster == "" {
+			cluster = "<unknown>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\n",
+			entry.Namespace, entry.Name, cluster, kubernetes.FormatAge(entry.DeletedAt), entry.ExpiresAt.Format(time.RFC3339))
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(deploymentCmd)
 
@@ -168,6 +567,8 @@ func init() {
 	deploymentCmd.AddCommand(deploymentListCmd)
 	deploymentCmd.AddCommand(deploymentCreateCmd)
 	deploymentCmd.AddCommand(deploymentDeleteCmd)
+	deploymentCmd.AddCommand(deploymentUndeleteCmd)
+	deploymentCmd.AddCommand(deploymentScaleCmd)
 
 	// List command flags
 	deploymentListCmd.Flags().BoolVarP(&deployAllNamespaces, "all-namespaces", "A", false, "List deployments across all namespaces")
@@ -176,6 +577,12 @@ func init() {
 	deploymentListCmd.Flags().BoolVar(&deployCustomLogic, "custom-logic", false, "Use custom logic for analyzing deployment events (only used with --watch)")
 	deploymentListCmd.Flags().DurationVar(&deployWatchResync, "resync-period", 30*time.Second, "Resync period for the informer (only used with --watch)")
 	deploymentListCmd.Flags().StringVar(&deployKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	deploymentListCmd.Flags().BoolVar(&deployAllClusters, "all-clusters", false, "List deployments of every enabled cluster in the configuration file")
+	deploymentListCmd.Flags().StringVarP(&deployOutput, "output", "o", "table", "output format (table, wide, json-stream with --watch)")
+	deploymentListCmd.Flags().StringVarP(&deployListSelector, "selector", "l", "", "List deployments matching this label selector, e.g. tier=frontend")
+	deploymentListCmd.Flags().StringVar(&deployFieldSelector, "field-selector", "", "List deployments matching this field selector, e.g. metadata.name=web")
+	deploymentListCmd.Flags().StringVar(&deploySortBy, "sort-by", "", "Sort deployments by a JSONPath field, e.g. .metadata.creationTimestamp")
+	deploymentListCmd.Flags().BoolVar(&deployShowLabels, "show-labels", false, "Show the labels of deployments as the last column")
 
 	// Create command flags
 	deploymentCreateCmd.Flags().StringVar(&deployCreateImage, "image", "", "Container image (required)")
@@ -189,4 +596,18 @@ func init() {
 	// Delete command flags
 	deploymentDeleteCmd.Flags().StringVarP(&deployDeleteNamespace, "namespace", "n", "default", "Kubernetes namespace")
 	deploymentDeleteCmd.Flags().StringVar(&deployKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+
+	// Undelete command flags
+	deploymentUndeleteCmd.Flags().StringVarP(&deployUndeleteNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	deploymentUndeleteCmd.Flags().BoolVar(&deployUndeleteList, "list", false, "List deleted deployments that can be restored")
+	deploymentUndeleteCmd.Flags().StringVar(&deployKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	deploymentUndeleteCmd.Flags().BoolVar(&deployUndeleteMismatch, "allow-cluster-mismatch", false, "Restore to the current cluster even if the deployment was deleted from another one")
+
+	// Scale command flags
+	deploymentScaleCmd.Flags().Int32Var(&deployScaleReplicas, "replicas", 0, "Number of replicas (required)")
+	deploymentScaleCmd.Flags().StringVarP(&deployScaleSelector, "selector", "l", "", "Scale deployments matching this label selector, e.g. tier=frontend")
+	deploymentScaleCmd.Flags().StringVarP(&deployScaleNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	deploymentScaleCmd.Flags().BoolVarP(&deployScaleAll, "all-namespaces", "A", false, "Select deployments across all namespaces (with --selector)")
+	deploymentScaleCmd.Flags().BoolVarP(&deployScaleYes, "yes", "y", false, "Apply without asking for confirmation")
+	deploymentScaleCmd.Flags().StringVar(&deployKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
 }
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	deployDescribeNamespace string
+	deployDescribeOutput    string
+)
+
+// deploymentDescribeCmd represents the deployment describe command
+var deploymentDescribeCmd = &cobra.Command{
+	Use:   "describe NAME",
+	Short: "Show details of a deployment",
+	Long: `Show the spec and status of a deployment together with its revisions, pods
+and recent events, like kubectl describe. Events of the deployment's
+ReplicaSets and pods are included, so scheduling and image pull failures show
+up next to the deployment.
+
+Examples:
+  k6s deployment describe web -n shop
+  k6s deployment describe web -n shop -o yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if deployDescribeOutput != "text" && deployDescribeOutput != "json" && deployDescribeOutput != "yaml" {
+			fmt.Fprintf(os.Stderr, "unsupported output format %q (use text, json or yaml)\n", deployDescribeOutput)
+			os.Exit(1)
+		}
+
+		client, err := kubernetes.NewClient(deployKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		description, err := client.DeploymentDescribe(ctx, deployDescribeNamespace, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error describing deployment: %v\n", err)
+			os.Exit(1)
+		}
+
+		switch deployDescribeOutput {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(description)
+		case "yaml":
+			var out []byte
+			if out, err = yaml.Marshal(description); err == nil {
+				_, err = os.Stdout.Write(out)
+			}
+		default:
+			printDeploymentDescription(os.Stdout, description)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error encoding deployment: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	deploymentCmd.AddCommand(deploymentDescribeCmd)
+
+	deploymentDescribeCmd.Flags().StringVarP(&deployDescribeNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	deploymentDescribeCmd.Flags().StringVarP(&deployDescribeOutput, "output", "o", "text", "output format (text, json, yaml)")
+	deploymentDescribeCmd.Flags().StringVar(&deployKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+}
+
+// printDeploymentDescription prints a deployment in kubectl describe format
+func printDeploymentDescription(out io.Writer, d kubernetes.DeploymentDescription) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "Name:\t%s\n", d.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", d.Namespace)
+	fmt.Fprintf(w, "CreationTimestamp:\t%s\n", d.CreationTimestamp.Format(time.RFC1123Z))
+	fmt.Fprintf(w, "Labels:\t%s\n", formatLabels(d.Labels))
+	fmt.Fprintf(w, "Annotations:\t%s\n", formatLabels(d.Annotations))
+	fmt.Fprintf(w, "Selector:\t%s\n", d.Selector)
+	fmt.Fprintf(w, "Replicas:\t%d desired | %d updated | %d total | %d available | %d unavailable\n",
+		d.Replicas.Desired, d.Replicas.Updated, d.Replicas.Total, d.Replicas.Available, d.Replicas.Unavailable)
+	fmt.Fprintf(w, "StrategyType:\t%s\n", d.Strategy)
+	fmt.Fprintf(w, "MinReadySeconds:\t%d\n", d.MinReadySeconds)
+	if d.MaxUnavailable != "" || d.MaxSurge != "" {
+		fmt.Fprintf(w, "RollingUpdateStrategy:\t%s max unavailable, %s max surge\n", d.MaxUnavailable, d.MaxSurge)
+	}
+
+	fmt.Fprintln(w, "Containers:")
+	for _, c := range d.Containers {
+		fmt.Fprintf(w, "  %s:\n", c.Name)
+		fmt.Fprintf(w, "    Image:\t%s\n", c.Image)
+		if len(c.Ports) > 0 {
+			fmt.Fprintf(w, "    Ports:\t%s\n", strings.Join(c.Ports, ", "))
+		}
+		if len(c.Requests) > 0 {
+			fmt.Fprintf(w, "    Requests:\t%s\n", formatLabels(c.Requests))
+		}
+		if len(c.Limits) > 0 {
+			fmt.Fprintf(w, "    Limits:\t%s\n", formatLabels(c.Limits))
+		}
+	}
+
+	if len(d.Conditions) > 0 {
+		fmt.Fprintln(w, "Conditions:")
+		fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON")
+		for _, c := range d.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", c.Type, c.Status, c.Reason)
+		}
+	}
+
+	fmt.Fprintln(w, "Revisions:")
+	if len(d.Revisions) == 0 {
+		fmt.Fprintln(w, "  <none>")
+	} else {
+		fmt.Fprintln(w, "  REVISION\tREPLICASET\tIMAGES\tREPLICAS\tREADY\tAGE")
+		for _, r := range d.Revisions {
+			number := fmt.Sprintf("%d", r.Revision)
+			if r.Current {
+				number += "*"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%d\t%s\n",
+				number, r.ReplicaSet, strings.Join(r.Images, ","), r.Replicas, r.Ready, kubernetes.FormatAge(r.Created))
+		}
+	}
+
+	fmt.Fprintln(w, "Pods:")
+	if len(d.Pods) == 0 {
+		fmt.Fprintln(w, "  <none>")
+	} else {
+		fmt.Fprintln(w, "  NAME\tREADY\tSTATUS\tRESTARTS\tNODE\tAGE")
+		for _, p := range d.Pods {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%s\t%s\n",
+				p.Name, p.Ready, p.Phase, p.Restarts, p.Node, kubernetes.FormatAge(p.Created))
+		}
+	}
+
+	fmt.Fprintln(w, "Events:")
+	if len(d.Events) == 0 {
+		fmt.Fprintln(w, "  <none>")
+	} else {
+		fmt.Fprintln(w, "  TYPE\tREASON\tAGE\tOBJECT\tMESSAGE")
+		for _, e := range d.Events {
+			age := kubernetes.FormatAge(e.LastSeen)
+			if e.Count > 1 {
+				age = fmt.Sprintf("%s (x%d)", age, e.Count)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", e.Type, e.Reason, age, e.Object, e.Message)
+		}
+	}
+}
+
+// formatLabels formats a map as sorted key=value pairs, or "<none>" when empty
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "<none>"
+	}
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	deployDiffClusters  []string
+	deployDiffNamespace string
+)
+
+// deploymentDiffCmd represents the deployment diff command
+var deploymentDiffCmd = &cobra.Command{
+	Use:   "diff NAME",
+	Short: "Compare a deployment across clusters",
+	Long: `Fetch a deployment from several clusters of the configuration file and show
+how its replicas, images, resources and labels differ from the first cluster.
+Without --clusters, every enabled cluster is compared. The command exits with
+status 1 when the deployment differs, is missing or cannot be fetched.
+
+Examples:
+  k6s deployment diff web --clusters prod,staging -n shop
+  k6s deployment diff api -n payments`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig(cfgFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+		clusters, err := diffClusters(cfg, deployDiffClusters)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		results := cluster.GetDeployment(clusters, deployDiffNamespace, args[0], cfg.MultiCluster.ConnectionTimeout, cfg.MultiCluster.MaxConcurrentConns)
+		if !printDeploymentDiff(deployDiffNamespace+"/"+args[0], results) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	deploymentCmd.AddCommand(deploymentDiffCmd)
+
+	deploymentDiffCmd.Flags().StringSliceVar(&deployDiffClusters, "clusters", nil, "Clusters to compare, the first one being the base (default: all enabled clusters)")
+	deploymentDiffCmd.Flags().StringVarP(&deployDiffNamespace, "namespace", "n", "default", "Kubernetes namespace")
+}
+
+// diffClusters returns the named clusters in order, or the enabled clusters
+// when none are named. At least two are needed to compare.
+func diffClusters(cfg *config.Config, names []string) ([]config.ClusterConfig, error) {
+	var clusters []config.ClusterConfig
+	if len(names) == 0 {
+		for _, c := range cfg.MultiCluster.Clusters {
+			if c.Enabled {
+				clusters = append(clusters, c)
+			}
+		}
+	}
+	for _, name := range names {
+		index := slices.IndexFunc(cfg.MultiCluster.Clusters, func(c config.ClusterConfig) bool { return c.Name == name })
+		if index < 0 {
+			return nil, fmt.Errorf("cluster %s is not configured", name)
+		}
+		clusters = append(clusters, cfg.MultiCluster.Clusters[index])
+	}
+
+	if len(clusters) < 2 {
+		return nil, fmt.Errorf("at least two clusters are needed to compare, got %d", len(clusters))
+	}
+	return clusters, nil
+}
+
+// printDeploymentDiff prints how the deployment of each cluster differs from
+// the first one, and reports whether they are all identical
+func printDeploymentDiff(name string, results []cluster.ClusterDeployment) bool {
+	base := results[0]
+	if base.Err != nil {
+		fmt.Fprintf(os.Stderr, "error fetching deployment %s from base cluster %s: %s\n", name, base.Cluster, diffError(base.Err))
+		return false
+	}
+
+	fmt.Printf("Comparing deployment %s with cluster %s\n", name, base.Cluster)
+	identical := true
+	for _, result := range results[1:] {
+		fmt.Println()
+		if result.Err != nil {
+			fmt.Printf("%s: %s\n", result.Cluster, diffError(result.Err))
+			identical = false
+			continue
+		}
+
+		changes := kubernetes.CompareDeployments(base.Deployment, result.Deployment)
+		if len(changes) == 0 {
+			fmt.Printf("%s: identical\n", result.Cluster)
+			continue
+		}
+		identical = false
+		fmt.Printf("%s:\n", result.Cluster)
+		for _, change := range changes {
+			fmt.Printf("  %s: %s -> %s\n", change.Field, formatDiffValue(change.OldValue), formatDiffValue(change.NewValue))
+		}
+	}
+	return identical
+}
+
+// diffError describes why a deployment could not be compared
+func diffError(err error) string {
+	if apierrors.IsNotFound(err) {
+		return "deployment not found"
+	}
+	return err.Error()
+}
+
+// formatDiffValue formats a compared value on one line
+func formatDiffValue(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]string:
+		return formatPairs(v)
+	case corev1.ResourceRequirements:
+		var parts []string
+		if len(v.Requests) > 0 {
+			parts = append(parts, "requests "+formatResources(v.Requests))
+		}
+		if len(v.Limits) > 0 {
+			parts = append(parts, "limits "+formatResources(v.Limits))
+		}
+		if len(parts) == 0 {
+			return "<none>"
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// formatResources formats a resource list as sorted name=quantity pairs
+func formatResources(resources corev1.ResourceList) string {
+	pairs := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		pairs[string(name)] = quantity.String()
+	}
+	return formatPairs(pairs)
+}
+
+// formatPairs formats a map as sorted key=value pairs
+func formatPairs(pairs map[string]string) string {
+	if len(pairs) == 0 {
+		return "<none>"
+	}
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		keys[i] = key + "=" + pairs[key]
+	}
+	return strings.Join(keys, ",")
+}
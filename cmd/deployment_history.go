@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var deployHistoryNamespace string
+
+// deploymentHistoryCmd represents the deployment history command
+var deploymentHistoryCmd = &cobra.Command{
+	Use:   "history NAME",
+	Short: "Show the rollout history of a deployment",
+	Long: `List the rollout revisions of a deployment from the ReplicaSets it controls,
+with the images and replica counts of each revision. The current revision is
+marked with *.
+
+Examples:
+  k6s deployment history web -n shop`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := kubernetes.NewClient(deployKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		revisions, err := client.DeploymentRevisions(ctx, deployHistoryNamespace, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error getting deployment history: %v\n", err)
+			os.Exit(1)
+		}
+		printRevisions(revisions)
+	},
+}
+
+func init() {
+	deploymentCmd.AddCommand(deploymentHistoryCmd)
+
+	deploymentHistoryCmd.Flags().StringVarP(&deployHistoryNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	deploymentHistoryCmd.Flags().StringVar(&deployKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+}
+
+// printRevisions prints deployment revisions in kubectl-like format
+func printRevisions(revisions []kubernetes.Revision) {
+	if len(revisions) == 0 {
+		fmt.Println("No revisions found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "REVISION\tREPLICASET\tIMAGES\tREPLICAS\tREADY\tAGE\tCHANGE-CAUSE")
+	for _, revision := range revisions {
+		number := fmt.Sprintf("%d", revision.Revision)
+		if revision.Current {
+			number += "*"
+		}
+		changeCause := revision.ChangeCause
+		if changeCause == "" {
+			changeCause = "<none>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			number, revision.ReplicaSet, strings.Join(revision.Images, ","), revision.Replicas, revision.Ready,
+			kubernetes.FormatAge(revision.Created), changeCause)
+	}
+}
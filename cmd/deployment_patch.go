@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	patchNamespace string
+	patchType      string
+	patchInline    string
+	patchFile      string
+)
+
+// deploymentSetCmd represents the deployment set command
+var deploymentSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set fields of a deployment",
+}
+
+// setImageCmd represents the deployment set image command
+var setImageCmd = &cobra.Command{
+	Use:   "image NAME CONTAINER=IMAGE...",
+	Short: "Update the images of containers of a deployment",
+	Long: `Update the images of containers and init containers of a deployment, like
+kubectl set image. The container "*" selects every container. Other container
+fields are left unchanged.
+
+Examples:
+  k6s deployment set image web web=registry.example/web:1.4 -n shop
+  k6s deployment set image web web=web:1.4 proxy=envoy:1.31 -n shop
+  k6s deployment set image web '*=web:1.4' -n shop`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		images, err := kubernetes.ParseContainerImages(args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		runDeploymentAction(args[0], "image updated", func(ctx context.Context, client *kubernetes.Client) error {
+			_, err := client.DeploymentSetImage(ctx, patchNamespace, args[0], images)
+			return err
+		})
+	},
+}
+
+// deploymentPatchCmd represents the deployment patch command
+var deploymentPatchCmd = &cobra.Command{
+	Use:   "patch NAME",
+	Short: "Patch a deployment",
+	Long: `Update fields of a live deployment with a strategic merge, JSON merge or JSON
+patch, like kubectl patch. The patch is given inline with --patch or read from
+--patch-file ("-" for stdin), as JSON or YAML.
+
+Examples:
+  k6s deployment patch web -n shop -p '{"spec":{"replicas":3}}'
+  k6s deployment patch web -n shop --patch-file resources.yaml
+  k6s deployment patch web -n shop --type json --patch-file ops.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pt, err := kubernetes.ParsePatchType(patchType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		patch, err := readPatch()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading patch: %v\n", err)
+			os.Exit(1)
+		}
+		runDeploymentAction(args[0], "patched", func(ctx context.Context, client *kubernetes.Client) error {
+			_, err := client.DeploymentPatch(ctx, patchNamespace, args[0], pt, patch)
+			return err
+		})
+	},
+}
+
+func init() {
+	deploymentCmd.AddCommand(deploymentSetCmd)
+	deploymentSetCmd.AddCommand(setImageCmd)
+	deploymentCmd.AddCommand(deploymentPatchCmd)
+
+	for _, c := range []*cobra.Command{deploymentSetCmd, deploymentPatchCmd} {
+		c.PersistentFlags().StringVarP(&patchNamespace, "namespace", "n", "default", "Kubernetes namespace")
+		c.PersistentFlags().StringVar(&deployKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	}
+
+	deploymentPatchCmd.Flags().StringVar(&patchType, "type", "strategic", "Patch type (strategic, merge, json)")
+	deploymentPatchCmd.Flags().StringVarP(&patchInline, "patch", "p", "", "Patch to apply, as JSON or YAML")
+	deploymentPatchCmd.Flags().StringVar(&patchFile, "patch-file", "", "File with the patch to apply, - for stdin")
+	deploymentPatchCmd.MarkFlagsOneRequired("patch", "patch-file")
+	deploymentPatchCmd.MarkFlagsMutuallyExclusive("patch", "patch-file")
+}
+
+// readPatch returns the patch of --patch or --patch-file as JSON
+func readPatch() ([]byte, error) {
+	data := []byte(patchInline)
+	if patchFile != "" {
+		var err error
+		if patchFile == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(patchFile) // #nosec G304 - path is provided by the user running the command
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return yaml.YAMLToJSON(data)
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rolloutNamespace  string
+	rolloutWatch      bool
+	rolloutTimeout    time.Duration
+	rolloutToRevision int64
+)
+
+// rolloutTimeoutDefault bounds the API calls of deployment actions that don't wait
+const rolloutTimeoutDefault = 30 * time.Second
+
+// deploymentRolloutCmd represents the deployment rollout command
+var deploymentRolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Manage the rollout of a deployment",
+	Long: `Follow, restart, pause, resume or undo the rollout of a deployment, like
+kubectl rollout. Use "k6s deployment history" to list the revisions.`,
+}
+
+// rolloutStatusCmd represents the deployment rollout status command
+var rolloutStatusCmd = &cobra.Command{
+	Use:   "status NAME",
+	Short: "Show the status of a rollout",
+	Long: `Show the status of the rollout of a deployment and, by default, follow it
+until it completes. Progress is reported from watch updates of the deployment.
+The command fails when the rollout exceeds its progress deadline or --timeout.
+
+Examples:
+  k6s deployment rollout status web -n shop
+  k6s deployment rollout status web -n shop --timeout 5m`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := newDeploymentClient()
+
+		if !rolloutWatch {
+			dep, err := client.DeploymentGet(rolloutNamespace, args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error getting deployment: %v\n", err)
+				os.Exit(1)
+			}
+			message, _, err := kubernetes.RolloutStatus(dep)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(message)
+			return
+		}
+
+		ctx := context.Background()
+		if rolloutTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, rolloutTimeout)
+			defer cancel()
+		}
+		err := client.WatchRolloutStatus(ctx, rolloutNamespace, args[0], func(message string) {
+			fmt.Println(message)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// rolloutRestartCmd represents the deployment rollout restart command
+var rolloutRestartCmd = &cobra.Command{
+	Use:   "restart NAME",
+	Short: "Restart the pods of a deployment",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDeploymentAction(args[0], "restarted", func(ctx context.Context, client *kubernetes.Client) error {
+			return client.RolloutRestart(ctx, rolloutNamespace, args[0])
+		})
+	},
+}
+
+// rolloutPauseCmd represents the deployment rollout pause command
+var rolloutPauseCmd = &cobra.Command{
+	Use:   "pause NAME",
+	Short: "Pause the rollout of a deployment",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDeploymentAction(args[0], "paused", func(ctx context.Context, client *kubernetes.Client) error {
+			return client.RolloutPause(ctx, rolloutNamespace, args[0])
+		})
+	},
+}
+
+// rolloutResumeCmd represents the deployment rollout resume command
+var rolloutResumeCmd = &cobra.Command{
+	Use:   "resume NAME",
+	Short: "Resume the paused rollout of a deployment",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDeploymentAction(args[0], "resumed", func(ctx context.Context, client *kubernetes.Client) error {
+			return client.RolloutResume(ctx, rolloutNamespace, args[0])
+		})
+	},
+}
+
+// rolloutUndoCmd represents the deployment rollout undo command
+var rolloutUndoCmd = &cobra.Command{
+	Use:   "undo NAME",
+	Short: "Roll a deployment back to a previous revision",
+	Long: `Roll a deployment back to the pod template of the previous revision, or of
+--to-revision.
+
+Examples:
+  k6s deployment rollout undo web -n shop
+  k6s deployment rollout undo web -n shop --to-revision 3`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if rolloutToRevision < 0 {
+			fmt.Fprintf(os.Stderr, "error: --to-revision must not be negative\n")
+			os.Exit(1)
+		}
+		runDeploymentAction(args[0], "rolled back", func(ctx context.Context, client *kubernetes.Client) error {
+			_, err := client.RolloutUndo(ctx, rolloutNamespace, args[0], rolloutToRevision)
+			return err
+		})
+	},
+}
+
+func init() {
+	deploymentCmd.AddCommand(deploymentRolloutCmd)
+	deploymentRolloutCmd.AddCommand(rolloutStatusCmd)
+	deploymentRolloutCmd.AddCommand(rolloutRestartCmd)
+	deploymentRolloutCmd.AddCommand(rolloutPauseCmd)
+	deploymentRolloutCmd.AddCommand(rolloutResumeCmd)
+	deploymentRolloutCmd.AddCommand(rolloutUndoCmd)
+
+	deploymentRolloutCmd.PersistentFlags().StringVarP(&rolloutNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	deploymentRolloutCmd.PersistentFlags().StringVar(&deployKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+
+	rolloutStatusCmd.Flags().BoolVarP(&rolloutWatch, "watch", "w", true, "Follow the rollout until it completes")
+	rolloutStatusCmd.Flags().DurationVar(&rolloutTimeout, "timeout", 0, "How long to follow the rollout (0 waits until it completes or fails)")
+	rolloutUndoCmd.Flags().Int64Var(&rolloutToRevision, "to-revision", 0, "Revision to roll back to (default: the previous revision)")
+}
+
+// newDeploymentClient creates the Kubernetes client of deployment commands, exiting on failure
+func newDeploymentClient() *kubernetes.Client {
+	client, err := kubernetes.NewClient(deployKubeconfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+		os.Exit(1)
+	}
+	return client
+}
+
+// runDeploymentAction runs an action on a deployment and prints the
+// kubectl-style result, exiting on failure
+func runDeploymentAction(name, done string, action func(context.Context, *kubernetes.Client) error) {
+	client := newDeploymentClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), rolloutTimeoutDefault)
+	defer cancel()
+	if err := action(ctx, client); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("deployment.apps/%s %s\n", name, done)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/manifest"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+var (
+	driftReportFiles         []string
+	driftReportRecursive     bool
+	driftReportNamespace     string
+	driftReportAllNamespaces bool
+	driftReportKubeconfig    string
+)
+
+// driftCmd represents the drift command group
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Detect drift from declared manifests",
+	Long:  `Compare deployments with the manifests declared for them.`,
+}
+
+// driftReportCmd represents the drift report command
+var driftReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report deployments that drifted from their manifests",
+	Long: `Compare live deployments with their declared manifests and show how their
+replicas, images, resources and labels changed since. Without --filename, the
+manifests recorded by "k6s apply" on the deployments of --namespace are used;
+with it, the deployments of the given files or directories, such as a Git
+checkout. The command exits with status 1 when a deployment drifted or is
+missing.
+
+Examples:
+  k6s drift report -n shop
+  k6s drift report -A
+  k6s drift report -f deploy/ -R`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := kubernetes.NewClient(driftReportKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		var desired []drift.Desired
+		namespaces := []string{driftReportNamespace}
+		if driftReportAllNamespaces {
+			namespaces = []string{""}
+		}
+		if len(driftReportFiles) > 0 {
+			desired, err = declaredManifests(driftReportFiles, driftReportRecursive, driftReportNamespace, cmd.Flags().Changed("namespace"))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error reading manifests: %v\n", err)
+				os.Exit(1)
+			}
+			namespaces = manifestNamespaces(desired)
+		}
+
+		live := make(map[string]*appsv1.Deployment)
+		for _, namespace := range namespaces {
+			deployments, err := client.DeploymentList(namespace)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error listing deployments: %v\n", err)
+				os.Exit(1)
+			}
+			for i := range deployments.Items {
+				dep := &deployments.Items[i]
+				live[dep.Namespace+"/"+dep.Name] = dep
+				if len(driftReportFiles) > 0 {
+					continue
+				}
+				applied, ok, err := drift.Applied(dep)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+				} else if ok {
+					desired = append(desired, applied)
+				}
+			}
+		}
+
+		if !printDriftReport(drift.CompareManifests(desired, live)) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(driftCmd)
+	driftCmd.AddCommand(driftReportCmd)
+
+	driftReportCmd.Flags().StringSliceVarP(&driftReportFiles, "filename", "f", nil, "Manifest file or directory declaring deployments, - for stdin (repeatable)")
+	driftReportCmd.Flags().BoolVarP(&driftReportRecursive, "recursive", "R", false, "Read directories given with -f recursively")
+	driftReportCmd.Flags().StringVarP(&driftReportNamespace, "namespace", "n", "default", "Kubernetes namespace, or namespace of manifests without one")
+	driftReportCmd.Flags().BoolVarP(&driftReportAllNamespaces, "all-namespaces", "A", false, "Report deployments of all namespaces")
+	driftReportCmd.Flags().StringVar(&driftReportKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+}
+
+// declaredManifests reads the deployments declared in paths, like "k6s apply"
+func declaredManifests(paths []string, recursive bool, namespace string, explicit bool) ([]drift.Desired, error) {
+	manifests, err := manifest.Read(paths, recursive, os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.SetNamespace(manifests, namespace, explicit); err != nil {
+		return nil, err
+	}
+	desired := make([]drift.Desired, 0, len(manifests))
+	for _, m := range manifests {
+		desired = append(desired, drift.Desired{Deployment: m.Deployment, Source: m.Source})
+	}
+	return desired, nil
+}
+
+// manifestNamespaces returns the distinct namespaces of declared deployments, sorted
+func manifestNamespaces(desired []drift.Desired) []string {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, d := range desired {
+		if !seen[d.Deployment.Namespace] {
+			seen[d.Deployment.Namespace] = true
+			namespaces = append(namespaces, d.Deployment.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// printDriftReport prints how each deployment differs from its manifest, and
+// reports whether they all match
+func printDriftReport(drifts []drift.DeploymentDrift) bool {
+	if len(drifts) == 0 {
+		fmt.Println("No declared manifests found.")
+		return true
+	}
+
+	inSync := true
+	for _, d := range drifts {
+		name := fmt.Sprintf("%s/%s (%s)", d.Namespace, d.Name, d.Source)
+		switch d.Status {
+		case drift.StatusMissing:
+			inSync = false
+			fmt.Printf("%s: missing\n", name)
+		case drift.StatusDrifted:
+			inSync = false
+			fmt.Printf("%s: drifted\n", name)
+			for _, change := range d.Fields {
+				fmt.Printf("  %s: %s -> %s\n", change.Field, formatDiffValue(change.OldValue), formatDiffValue(change.NewValue))
+			}
+		default:
+			fmt.Printf("%s: in sync\n", name)
+		}
+	}
+	return inSync
+}
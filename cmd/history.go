@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/remote"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	historyNamespace string
+	historyName      string
+	historySince     string
+	historyLimit     int
+	historyOutput    string
+	historyServer    string
+	historyToken     string
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show recorded deployment events",
+	Long: `Show the deployment events recorded by a k6s server with event history
+enabled (history.events.enabled), most recent first, with the replicas,
+images, resources and labels each update changed.
+
+Without --server the event database of the configuration is read directly,
+which only works while no k6s server holds it open.
+
+Examples:
+  k6s history -n shop --name web --since 2h
+  k6s history --since 2025-07-07T09:00:00Z -o json
+  k6s history --server https://k6s.example -n shop`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVarP(&historyNamespace, "namespace", "n", "", "only show events of this namespace")
+	historyCmd.Flags().StringVar(&historyName, "name", "", "only show events of deployments with this name")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only show events since an RFC 3339 time or a duration ago (e.g., 2h)")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 0, "maximum number of events to show (0 = all)")
+	historyCmd.Flags().StringVarP(&historyOutput, "output", "o", "table", "output format (table, json)")
+	historyCmd.Flags().StringVar(&historyServer, "server", "", "query the event history of this k6s server")
+	historyCmd.Flags().StringVar(&historyToken, "token", "", "API token for --server (default $K6S_TOKEN)")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if historyOutput != "table" && historyOutput != "json" {
+		return fmt.Errorf("invalid output format %q: must be table or json", historyOutput)
+	}
+	if historyLimit < 0 {
+		return fmt.Errorf("invalid limit %d: must not be negative", historyLimit)
+	}
+
+	query := history.EventQuery{Namespace: historyNamespace, Name: historyName, Limit: historyLimit}
+	if historySince != "" {
+		since, err := history.ParseSince(historySince, time.Now())
+		if err != nil {
+			return err
+		}
+		query.Since = since
+	}
+
+	events, err := queryEventHistory(cmd.Context(), query)
+	if err != nil {
+		return err
+	}
+
+	if historyOutput == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(events)
+	}
+	printHistory(events)
+	return nil
+}
+
+// queryEventHistory queries the --server k6s server, or the local event
+// database
+func queryEventHistory(ctx context.Context, query history.EventQuery) ([]history.Event, error) {
+	if historyServer != "" {
+		token := historyToken
+		if token == "" {
+			token = viper.GetString("attach.token")
+		}
+		client, err := remote.NewClient(historyServer, token, "")
+		if err != nil {
+			return nil, err
+		}
+		return client.EventHistory(ctx, query)
+	}
+
+	cfg, err := config.LoadConfig(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	path := cfg.History.Events.Path
+	if path == "" {
+		path = history.DefaultEventsPath(cfg.History.Dir)
+	}
+	store, err := history.OpenEventStoreReadOnly(path)
+	if errors.Is(err, history.ErrLocked) {
+		return nil, fmt.Errorf("%w; query the server with --server instead", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return store.Query(query)
+}
+
+// printHistory prints events as a table, most recent first
+func printHistory(events []history.Event) {
+	if len(events) == 0 {
+		fmt.Println("No events found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "TIME\tEVENT\tCLUSTER\tNAMESPACE\tNAME\tCHANGES")
+	for _, event := range events {
+		changes := make([]string, 0, len(event.Changes))
+		for _, change := range event.Changes {
+			changes = append(changes, change.Description)
+		}
+		cluster := event.Cluster
+		if cluster == "" {
+			cluster = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			event.Time.Local().Format(time.RFC3339), event.Type, cluster, event.Namespace, event.Name, strings.Join(changes, "; "))
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/charts"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	installCRDsKubeconfig string
+	installCRDsDryRun     bool
+)
+
+// installCmd represents the install command group
+var installCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install k6s resources into the cluster",
+	Long:  `Install the resources k6s needs into the cluster without Helm.`,
+}
+
+// installCRDsCmd represents the install crds command
+var installCRDsCmd = &cobra.Command{
+	Use:   "crds",
+	Short: "Install or update the k6s CustomResourceDefinitions",
+	Long: `Install or update the CustomResourceDefinitions of the Helm chart, such as
+K6sController and K6sDeploymentPolicy, with a server-side apply. Running it
+again updates them to the definitions of this version.
+
+Examples:
+  k6s install crds
+  k6s install crds --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		manifests, err := charts.CRDs()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading CustomResourceDefinitions: %v\n", err)
+			os.Exit(1)
+		}
+		restConfig, err := kubernetes.RestConfig(installCRDsKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+		client, err := dynamic.NewForConfig(restConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		suffix := ""
+		if installCRDsDryRun {
+			suffix = " (server dry run)"
+		}
+		for _, manifest := range manifests {
+			name, err := kubernetes.ApplyCRD(ctx, client, manifest, installCRDsDryRun)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("customresourcedefinition/%s installed%s\n", name, suffix)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(installCmd)
+	installCmd.AddCommand(installCRDsCmd)
+
+	installCRDsCmd.Flags().StringVar(&installCRDsKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	installCRDsCmd.Flags().BoolVar(&installCRDsDryRun, "dry-run", false, "Validate the definitions on the server without persisting them")
+}
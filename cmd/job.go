@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jobNamespace     string
+	jobAllNamespaces bool
+	jobWatch         bool
+	jobKubeconfig    string
+)
+
+// jobCmd represents the job command group
+var jobCmd = &cobra.Command{
+	Use:     "job",
+	Aliases: []string{"jobs"},
+	Short:   "Inspect Kubernetes jobs",
+	Long:    `Inspect Kubernetes jobs and follow their completion.`,
+}
+
+// jobListCmd represents the job list command
+var jobListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List Kubernetes jobs",
+	Long: `List Kubernetes jobs in the specified namespace or all namespaces, with their
+status, completions and duration.
+
+With --watch, jobs are listed as they are added, updated and deleted, and a
+"finished" line is printed when a job completes or fails, until interrupted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		namespace := jobNamespace
+		if jobAllNamespaces {
+			namespace = ""
+		}
+
+		client, err := kubernetes.NewClient(jobKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !jobWatch {
+			jobs, err := client.JobList(namespace)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error listing jobs: %v\n", err)
+				os.Exit(1)
+			}
+			kubernetes.JobPrint(jobs.Items, jobAllNamespaces)
+			return
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		informer := kubernetes.NewJobInformer(client.Clientset(), namespace, 30*time.Second)
+		fmt.Printf("%-9s %-40s %-10s %-12s %-9s %s\n", "EVENT", "JOB", "STATUS", "COMPLETIONS", "DURATION", "AGE")
+		informer.AddHandler(kubernetes.JobEventHandlerFunc(func(event kubernetes.JobEvent) {
+			job := event.Job
+			name := job.Name
+			if jobAllNamespaces {
+				name = job.Namespace + "/" + job.Name
+			}
+			fmt.Printf("%-9s %-40s %-10s %-12s %-9s %s\n", event.Type, name, job.Status, job.Completions, job.Duration, job.Age)
+		}))
+		if err := informer.Start(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error starting informer: %v\n", err)
+			os.Exit(1)
+		}
+		defer informer.Stop()
+
+		<-ctx.Done()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(jobCmd)
+	jobCmd.AddCommand(jobListCmd)
+
+	jobListCmd.Flags().StringVarP(&jobNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	jobListCmd.Flags().BoolVarP(&jobAllNamespaces, "all-namespaces", "A", false, "List jobs across all namespaces")
+	jobListCmd.Flags().BoolVarP(&jobWatch, "watch", "w", false, "Watch jobs and their completion")
+	jobListCmd.Flags().StringVar(&jobKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+}
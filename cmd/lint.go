@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/lint"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var (
+	lintNamespace            string
+	lintAllNamespaces        bool
+	lintKubeconfig           string
+	lintSelector             string
+	lintOutput               string
+	lintFailOn               string
+	lintDisable              []string
+	lintProductionNamespaces []string
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check deployments for missing requests, limits, probes and disruption budgets",
+	Long: `Check the deployments of informer caches against these rules:
+
+  missing-requests         warning  a container has no cpu or memory request
+  missing-limits           warning  a container has no cpu or memory limit
+  limit-below-request      error    a container limit is below its request
+  missing-readiness-probe  warning  a container has no readiness probe
+  missing-liveness-probe   warning  a container has no liveness probe
+  single-replica           error    a deployment of a production namespace runs one replica
+  missing-pdb              warning  no PodDisruptionBudget selects the pods of a deployment
+                                    running more than one replica, or of a production namespace
+
+Production namespaces and disabled rules are read from the lint section of
+the configuration; --production-namespaces and --disable replace them. The
+rules listed in a deployment's k6s.io/lint-ignore annotation, comma-separated,
+are not checked on it.
+
+The command exits with status 1 when a finding of at least --fail-on is
+reported, for CI gates.
+
+Examples:
+  k6s lint -n shop
+  k6s lint -A --fail-on error
+  k6s lint -A --disable missing-limits -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if lintOutput != "table" && lintOutput != "json" {
+			fmt.Fprintf(os.Stderr, "invalid output format %q: must be table or json\n", lintOutput)
+			os.Exit(1)
+		}
+		if lintFailOn != lint.SeverityError && lintFailOn != lint.SeverityWarning && lintFailOn != "none" {
+			fmt.Fprintf(os.Stderr, "invalid --fail-on %q: must be error, warning or none\n", lintFailOn)
+			os.Exit(1)
+		}
+		selector, err := labels.Parse(lintSelector)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid selector: %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg, err := config.LoadConfig(cfgFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+			os.Exit(1)
+		}
+		opts := lint.Options{
+			ProductionNamespaces: cfg.Lint.ProductionNamespaces,
+			DisabledRules:        cfg.Lint.DisabledRules,
+		}
+		if cmd.Flags().Changed("production-namespaces") {
+			opts.ProductionNamespaces = lintProductionNamespaces
+		}
+		if cmd.Flags().Changed("disable") {
+			opts.DisabledRules = lintDisable
+		}
+		if err := lint.ValidateRules(opts.DisabledRules); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		report, err := lintDeployments(selector, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if lintOutput == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			_ = encoder.Encode(report)
+		} else {
+			printLintReport(report, lintAllNamespaces)
+		}
+		if (lintFailOn == lint.SeverityError && report.Errors > 0) ||
+			(lintFailOn == lint.SeverityWarning && report.Errors+report.Warnings > 0) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&lintNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	lintCmd.Flags().BoolVarP(&lintAllNamespaces, "all-namespaces", "A", false, "Lint deployments of all namespaces")
+	lintCmd.Flags().StringVar(&lintKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	lintCmd.Flags().StringVarP(&lintSelector, "selector", "l", "", "Only lint deployments matching this label selector")
+	lintCmd.Flags().StringVarP(&lintOutput, "output", "o", "table", "output format (table, json)")
+	lintCmd.Flags().StringVar(&lintFailOn, "fail-on", lint.SeverityWarning, "Exit with status 1 on findings of at least this severity (error, warning, none)")
+	lintCmd.Flags().StringSliceVar(&lintDisable, "disable", nil, "Rules not checked (default lint.disabled_rules)")
+	lintCmd.Flags().StringSliceVar(&lintProductionNamespaces, "production-namespaces", nil, "Namespaces or patterns of production namespaces (default lint.production_namespaces)")
+}
+
+// lintDeployments lints the deployments of --namespace matching selector,
+// read from informer caches with the PodDisruptionBudgets protecting them
+func lintDeployments(selector labels.Selector, opts lint.Options) (lint.Report, error) {
+	client, err := kubernetes.NewClient(lintKubeconfig)
+	if err != nil {
+		return lint.Report{}, fmt.Errorf("error creating kubernetes client: %w", err)
+	}
+	namespace := lintNamespace
+	if lintAllNamespaces {
+		namespace = ""
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	set := kubernetes.NewInformerSet(client.Clientset(), namespace, 0)
+	deployments := kubernetes.NewDeploymentInformerFor(set, nil)
+	// Events aren't logged, the cache is only read
+	deployments.ReplaceEventHandlers()
+	if err := deployments.Start(); err != nil {
+		return lint.Report{}, fmt.Errorf("failed to start deployment informer: %w", err)
+	}
+	defer deployments.Stop()
+	pdbs := kubernetes.NewPDBInformerFor(set)
+	if err := pdbs.Start(ctx); err != nil {
+		return lint.Report{}, err
+	}
+	defer pdbs.Stop()
+
+	cached, err := deployments.ListDeployments()
+	if err != nil {
+		return lint.Report{}, err
+	}
+	selected := make([]*appsv1.Deployment, 0, len(cached))
+	for _, dep := range cached {
+		if selector.Matches(labels.Set(dep.Labels)) {
+			selected = append(selected, dep)
+		}
+	}
+	return lint.Lint(selected, pdbs.ListAllPDBs(), opts), nil
+}
+
+// printLintReport prints the findings of a report by deployment, followed by
+// their counts
+func printLintReport(report lint.Report, showNamespace bool) {
+	if len(report.Findings) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if showNamespace {
+			fmt.Fprintln(w, "NAMESPACE\tDEPLOYMENT\tCONTAINER\tSEVERITY\tRULE\tMESSAGE")
+		} else {
+			fmt.Fprintln(w, "DEPLOYMENT\tCONTAINER\tSEVERITY\tRULE\tMESSAGE")
+		}
+		for _, f := range report.Findings {
+			container := f.Container
+			if container == "" {
+				container = "-"
+			}
+			if showNamespace {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Namespace, f.Deployment, container, f.Severity, f.Rule, f.Message)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Deployment, container, f.Severity, f.Rule, f.Message)
+			}
+		}
+		w.Flush()
+		fmt.Println()
+	}
+	fmt.Printf("%d of %d deployments passed, %d errors, %d warnings\n", report.Passed, report.Deployments, report.Errors, report.Warnings)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsNamespace      string
+	logsKubeconfig     string
+	logsOptions        kubernetes.LogOptions
+	logsMaxLogRequests int
+)
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs [POD | pod/NAME | deploy/NAME]",
+	Short: "Print the logs of a pod or deployment",
+	Long: `Print the logs of a pod, or of every started pod of a deployment with
+deploy/NAME. Without --container, the default container of each pod is
+streamed (the kubectl.kubernetes.io/default-container annotation, or the
+first container); --all-containers streams all of them.
+
+Lines of several pods or containers are prefixed with
+[pod/NAME/CONTAINER]. With --follow, new lines are streamed until
+interrupted; pods started later are not followed.`,
+	Example: `  k6s logs web-7d9c6b5f4-x2k8p -n prod
+  k6s logs deploy/web -n prod -f --since 10m --container web`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := kubernetes.NewClient(logsKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		pods, err := client.LogPods(ctx, logsNamespace, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error getting pods: %v\n", err)
+			os.Exit(1)
+		}
+		sources, err := kubernetes.LogSources(pods, logsOptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if logsOptions.Follow && len(sources) > logsMaxLogRequests {
+			fmt.Fprintf(os.Stderr, "following %d containers exceeds --max-log-requests %d; raise it or select a container with --container\n",
+				len(sources), logsMaxLogRequests)
+			os.Exit(1)
+		}
+
+		if err := kubernetes.StreamLogs(ctx, client.Clientset(), sources, logsOptions, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().StringVarP(&logsNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	logsCmd.Flags().StringVar(&logsKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	logsCmd.Flags().StringVarP(&logsOptions.Container, "container", "c", "", "Container to print the logs of")
+	logsCmd.Flags().BoolVar(&logsOptions.AllContainers, "all-containers", false, "Print the logs of every container of the pods")
+	logsCmd.Flags().BoolVarP(&logsOptions.Follow, "follow", "f", false, "Stream new lines until interrupted")
+	logsCmd.Flags().DurationVar(&logsOptions.Since, "since", 0, "Only print lines newer than a duration, e.g. 10m (0 = all)")
+	logsCmd.Flags().Int64Var(&logsOptions.TailLines, "tail", -1, "Number of recent lines to print of each container (-1 = all)")
+	logsCmd.Flags().BoolVar(&logsOptions.Timestamps, "timestamps", false, "Start each line with its timestamp")
+	logsCmd.Flags().BoolVar(&logsOptions.Prefix, "prefix", false, "Prefix each line with its pod and container, even for one container")
+	logsCmd.Flags().IntVar(&logsMaxLogRequests, "max-log-requests", 10, "Maximum number of containers followed at once")
+	logsCmd.MarkFlagsMutuallyExclusive("container", "all-containers")
+}
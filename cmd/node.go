@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var nodeKubeconfig string
+
+// nodeCmd represents the node command group
+var nodeCmd = &cobra.Command{
+	Use:     "node",
+	Aliases: []string{"nodes", "no"},
+	Short:   "Inspect Kubernetes nodes",
+	Long:    `Inspect the nodes of a Kubernetes cluster.`,
+}
+
+// nodeListCmd represents the node list command
+var nodeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List Kubernetes nodes",
+	Long: `List the nodes of the cluster with their status, roles, allocatable cpu,
+memory and pods, and number of taints.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := kubernetes.NewClient(nodeKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		nodes, err := client.NodeList()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error listing nodes: %v\n", err)
+			os.Exit(1)
+		}
+		kubernetes.NodePrint(nodes.Items)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nodeCmd)
+	nodeCmd.AddCommand(nodeListCmd)
+
+	nodeListCmd.Flags().StringVar(&nodeKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	podNamespace     string
+	podAllNamespaces bool
+	podKubeconfig    string
+	podWatch         bool
+	podOutput        string
+	podSortBy        string
+	podSelector      string
+	podFieldSelector string
+	podShowLabels    bool
+	podGracePeriod   int64
+)
+
+// podWatchWidths are the widths of the columns of pod watches, which print
+// each event as it happens and so can't align columns over all rows
+var podWatchWidths = map[string]int{
+	"EVENT":     9,
+	"NAMESPACE": 20,
+	"NAME":      50,
+	"READY":     6,
+	"STATUS":    18,
+	"RESTARTS":  9,
+	"AGE":       8,
+	"IP":        16,
+	"NODE":      30,
+}
+
+// podCmd represents the pod command group
+var podCmd = &cobra.Command{
+	Use:     "pod",
+	Aliases: []string{"pods", "po"},
+	Short:   "Manage Kubernetes pods",
+	Long:    `Manage Kubernetes pods with list, get, and delete operations.`,
+}
+
+// podListCmd represents the pod list command
+var podListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List Kubernetes pods",
+	Long: `List Kubernetes pods in the specified namespace or all namespaces, with their
+readiness, status, restarts and age.
+
+Like "k6s deployment list", --selector and --field-selector (e.g.
+spec.nodeName=node-1) filter the pods, --sort-by sorts them by a JSONPath
+field, --show-labels adds a LABELS column and -o wide adds the IP and node.
+
+With --watch, pods are printed as they are added, modified and deleted until
+interrupted; --output json-stream prints each event as one JSON object per
+line instead.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		validatePodOutput()
+		namespace := podNamespace
+		if podAllNamespaces {
+			namespace = ""
+		}
+
+		client, err := kubernetes.NewClient(podKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		if podWatch {
+			if err := watchPods(client, namespace, "", false, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		pods, err := client.PodList(namespace, podSelector, podFieldSelector)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error listing pods: %v\n", err)
+			os.Exit(1)
+		}
+		if err := kubernetes.SortPods(pods.Items, podSortBy); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		kubernetes.PodPrint(pods.Items, podPrintOptions())
+	},
+}
+
+// podGetCmd represents the pod get command
+var podGetCmd = &cobra.Command{
+	Use:   "get [NAME]",
+	Short: "Show a pod",
+	Long: `Show a Kubernetes pod by name. With --watch, its changes are printed until
+interrupted.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		validatePodOutput()
+		name := args[0]
+
+		client, err := kubernetes.NewClient(podKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		if podWatch {
+			if err := watchPods(client, podNamespace, name, false, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		pod, err := client.PodGet(podNamespace, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error getting pod: %v\n", err)
+			os.Exit(1)
+		}
+		kubernetes.PodPrint([]corev1.Pod{*pod}, podPrintOptions())
+	},
+}
+
+// podDeleteCmd represents the pod delete command
+var podDeleteCmd = &cobra.Command{
+	Use:   "delete [NAME]",
+	Short: "Delete a pod",
+	Long: `Delete a Kubernetes pod by name. A pod owned by a deployment is replaced by
+a new one.
+
+With --watch, the changes of the pod are printed while it terminates, and the
+command returns once it is deleted.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		validatePodOutput()
+		name := args[0]
+
+		client, err := kubernetes.NewClient(podKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		deletePod := func() error {
+			if err := client.PodDelete(context.TODO(), podNamespace, name, podGracePeriod); err != nil {
+				return fmt.Errorf("error deleting pod: %w", err)
+			}
+			if podOutput != deployOutputJSONStream {
+				fmt.Printf("pod \"%s\" deleted\n", name)
+			}
+			return nil
+		}
+
+		if podWatch {
+			err = watchPods(client, podNamespace, name, true, deletePod)
+		} else {
+			err = deletePod()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// validatePodOutput exits on an unsupported output format, or list options
+// that don't apply to watches
+func validatePodOutput() {
+	switch podOutput {
+	case "table", deployOutputWide:
+	case deployOutputJSONStream:
+		if !podWatch {
+			fmt.Fprintln(os.Stderr, "--output json-stream requires --watch")
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported output format %q (use table, wide or json-stream)\n", podOutput)
+		os.Exit(1)
+	}
+	if podWatch && (podSortBy != "" || podSelector != "" || podFieldSelector != "") {
+		fmt.Fprintln(os.Stderr, "--sort-by, --selector and --field-selector cannot be combined with --watch")
+		os.Exit(1)
+	}
+}
+
+// podPrintOptions returns the columns selected by the pod command flags
+func podPrintOptions() kubernetes.PodPrintOptions {
+	return kubernetes.PodPrintOptions{
+		ShowNamespace: podAllNamespaces,
+		Wide:          podOutput == deployOutputWide,
+		ShowLabels:    podShowLabels,
+	}
+}
+
+// watchPods prints the events of the pods of namespace, or only of the pod
+// name when it is set, until interrupted. With untilDeleted it returns once
+// that pod is deleted. afterSync, if set, runs once the pod cache synced, so
+// the changes it makes are printed.
+func watchPods(client *kubernetes.Client, namespace, name string, untilDeleted bool, afterSync func() error) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	printEvent := podEventPrinter(podPrintOptions())
+	informer := kubernetes.NewPodInformer(client.Clientset(), namespace, 30*time.Second)
+	informer.AddHandler(kubernetes.PodEventHandlerFunc(func(event kubernetes.PodEvent) {
+		if name != "" && event.Object.Name != name {
+			return
+		}
+		printEvent(event)
+		if untilDeleted && event.Type == kubernetes.WatchEventDeleted {
+			cancel()
+		}
+	}))
+	if err := informer.Start(ctx); err != nil {
+		return fmt.Errorf("error starting informer: %w", err)
+	}
+	defer informer.Stop()
+
+	if afterSync != nil {
+		if err := afterSync(); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// podEventPrinter prints the header of a pod watch and returns the function
+// printing its events, as rows or as JSON objects with --output json-stream
+func podEventPrinter(opts kubernetes.PodPrintOptions) func(kubernetes.PodEvent) {
+	if podOutput == deployOutputJSONStream {
+		encoder := json.NewEncoder(os.Stdout)
+		return func(event kubernetes.PodEvent) {
+			// Events are delivered one at a time, so writes don't interleave
+			_ = encoder.Encode(event)
+		}
+	}
+
+	columns := append([]string{"EVENT"}, kubernetes.PodColumns(opts)...)
+	fmt.Println(podWatchLine(columns, columns))
+	return func(event kubernetes.PodEvent) {
+		row := append([]string{event.Type}, kubernetes.PodRow(event.Object, opts)...)
+		fmt.Println(podWatchLine(columns, row))
+	}
+}
+
+// podWatchLine pads the values of a pod watch row to the widths of their
+// columns; the last value isn't padded
+func podWatchLine(columns, values []string) string {
+	var line strings.Builder
+	for i, value := range values {
+		if i == len(values)-1 {
+			line.WriteString(value)
+			break
+		}
+		fmt.Fprintf(&line, "%-*s ", podWatchWidths[columns[i]], value)
+	}
+	return line.String()
+}
+
+func init() {
+	rootCmd.AddCommand(podCmd)
+	podCmd.AddCommand(podListCmd)
+	podCmd.AddCommand(podGetCmd)
+	podCmd.AddCommand(podDeleteCmd)
+
+	for _, cmd := range []*cobra.Command{podListCmd, podGetCmd, podDeleteCmd} {
+		cmd.Flags().StringVarP(&podNamespace, "namespace", "n", "default", "Kubernetes namespace")
+		cmd.Flags().StringVar(&podKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+		cmd.Flags().BoolVarP(&podWatch, "watch", "w", false, "Watch for changes")
+		cmd.Flags().StringVarP(&podOutput, "output", "o", "table", "output format (table, wide, json-stream with --watch)")
+		cmd.Flags().BoolVar(&podShowLabels, "show-labels", false, "Show the labels of pods as the last column")
+	}
+
+	// List command flags
+	podListCmd.Flags().BoolVarP(&podAllNamespaces, "all-namespaces", "A", false, "List pods across all namespaces")
+	podListCmd.Flags().StringVarP(&podSelector, "selector", "l", "", "List pods matching this label selector, e.g. app=web")
+	podListCmd.Flags().StringVar(&podFieldSelector, "field-selector", "", "List pods matching this field selector, e.g. spec.nodeName=node-1")
+	podListCmd.Flags().StringVar(&podSortBy, "sort-by", "", "Sort pods by a JSONPath field, e.g. .metadata.creationTimestamp")
+
+	// Delete command flags
+	podDeleteCmd.Flags().Int64Var(&podGracePeriod, "grace-period", -1, "Seconds given to the pod to terminate (-1 = the pod's own, 0 = immediately)")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pvcNamespace     string
+	pvcAllNamespaces bool
+	pvcKubeconfig    string
+)
+
+// pvcCmd represents the pvc command group
+var pvcCmd = &cobra.Command{
+	Use:     "pvc",
+	Aliases: []string{"pvcs", "persistentvolumeclaim", "persistentvolumeclaims"},
+	Short:   "Inspect PersistentVolumeClaims",
+	Long:    `Inspect Kubernetes PersistentVolumeClaims and the pods mounting them.`,
+}
+
+// pvcListCmd represents the pvc list command
+var pvcListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List PersistentVolumeClaims",
+	Long: `List PersistentVolumeClaims in the specified namespace or all namespaces, with
+their phase, volume, capacity, access modes, storage class and the pods
+mounting them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		namespace := pvcNamespace
+		if pvcAllNamespaces {
+			namespace = ""
+		}
+
+		client, err := kubernetes.NewClient(pvcKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		pvcs, err := client.PVCList(namespace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error listing PersistentVolumeClaims: %v\n", err)
+			os.Exit(1)
+		}
+		mounts, err := client.PVCMounts(namespace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error listing pods: %v\n", err)
+			os.Exit(1)
+		}
+		kubernetes.PVCPrint(pvcs.Items, mounts, pvcAllNamespaces)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pvcCmd)
+	pvcCmd.AddCommand(pvcListCmd)
+
+	pvcListCmd.Flags().StringVarP(&pvcNamespace, "namespace", "n", "default", "Kubernetes namespace")
+	pvcListCmd.Flags().BoolVarP(&pvcAllNamespaces, "all-namespaces", "A", false, "List PersistentVolumeClaims across all namespaces")
+	pvcListCmd.Flags().StringVar(&pvcKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+}
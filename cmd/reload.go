@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/anomaly"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/compliance"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/notify"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/propagation"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/rollout"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// addConfigMapFlags adds the flags reading the configuration from a
+// ConfigMap to a long-running command
+func addConfigMapFlags(cmd *cobra.Command) {
+	cmd.Flags().String("config-map", "", "read the configuration from this ConfigMap, NAMESPACE/NAME or NAME in the pod's namespace, instead of --config (or K6S_CONFIG_MAP)")
+	cmd.Flags().String("config-map-key", kubernetes.DefaultConfigMapKey, "key of the configuration file in --config-map (or K6S_CONFIG_MAP_KEY)")
+}
+
+// configMapFlag returns the value of a ConfigMap flag, or of its environment
+// variable when the flag isn't set
+func configMapFlag(cmd *cobra.Command, name string) string {
+	if cmd.Flags().Changed(name) {
+		value, _ := cmd.Flags().GetString(name)
+		return value
+	}
+	if value := viper.GetString(name); value != "" {
+		return value
+	}
+	value, _ := cmd.Flags().GetString(name)
+	return value
+}
+
+// newConfigReloader loads the configuration from the ConfigMap of
+// --config-map when set, or from the configuration file at path otherwise,
+// and returns a reloader for it. The ConfigMap is read with the client of
+// kubeconfig ("" = in-cluster or default).
+func newConfigReloader(cmd *cobra.Command, path, kubeconfig string) (*config.ConfigReloader, error) {
+	ref := configMapFlag(cmd, "config-map")
+	if ref == "" {
+		return config.NewConfigReloader(path)
+	}
+
+	namespace, name, err := kubernetes.ParseConfigMapRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewClient(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	source := kubernetes.NewConfigMapSource(client.Clientset(), namespace, name, configMapFlag(cmd, "config-map-key"))
+	return config.NewSourceReloader(source)
+}
+
+// startConfigReloader watches the configuration file, or ConfigMap, until ctx
+// is cancelled. Failing to watch is not fatal: the process keeps running with
+// the configuration it started with.
+func startConfigReloader(ctx context.Context, reloader *config.ConfigReloader) {
+	if err := reloader.Start(ctx); err != nil {
+		logger.Warn("Configuration hot reload disabled", map[string]interface{}{
+			"source": reloader.Name(),
+			"error":  err.Error(),
+		})
+		return
+	}
+	logger.Info("Watching configuration for changes", map[string]interface{}{
+		"source": reloader.Name(),
+	})
+}
+
+// reloadLogLevel applies a changed log_level, log.levels and log.sampling
+func reloadLogLevel(oldConfig, newConfig *config.Config) {
+	if oldConfig.LogLevel != newConfig.LogLevel {
+		logger.SetLevel(newConfig.LogLevel)
+		logger.Info("Log level changed", map[string]interface{}{
+			"old": oldConfig.LogLevel,
+			"new": newConfig.LogLevel,
+		})
+	}
+	if !reflect.DeepEqual(oldConfig.Log.Levels, newConfig.Log.Levels) {
+		applyComponentLogLevels(newConfig.Log.Levels)
+		logger.Info("Component log levels changed", map[string]interface{}{
+			"levels": newConfig.Log.Levels,
+		})
+	}
+	if oldConfig.Log.Sampling != newConfig.Log.Sampling {
+		applyLogSampling(newConfig.Log.Sampling)
+		logger.Info("Informer log sampling changed", map[string]interface{}{
+			"burst":      newConfig.Log.Sampling.Burst,
+			"period":     newConfig.Log.Sampling.Period.String(),
+			"thereafter": newConfig.Log.Sampling.Thereafter,
+		})
+	}
+}
+
+// applyLogSampling samples the entries of the informers as configured
+func applyLogSampling(sampling config.LogSamplingConfig) {
+	logger.SetComponentSampling(logger.ComponentInformer, sampling.Burst, sampling.Period, sampling.Thereafter)
+}
+
+// applyComponentLogLevels sets the configured log levels of components
+func applyComponentLogLevels(levels map[string]string) {
+	if err := logger.SetComponentLevels(levels); err != nil {
+		logger.Warn("Failed to apply component log levels", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// restoreLogLevelOnSIGHUP reloads the configuration file (when reloader is
+// not nil) on SIGHUP and restores the configured log levels, removing levels
+// changed through the API, until ctx is cancelled
+func restoreLogLevelOnSIGHUP(ctx context.Context, reloader *config.ConfigReloader) {
+	var mu sync.Mutex
+	level, components := logger.Level(), logger.ComponentLevels()
+	if reloader != nil {
+		reloader.Subscribe(func(oldConfig, newConfig *config.Config) {
+			mu.Lock()
+			defer mu.Unlock()
+			if oldConfig.LogLevel != newConfig.LogLevel {
+				level = newConfig.LogLevel
+			}
+			if !reflect.DeepEqual(oldConfig.Log.Levels, newConfig.Log.Levels) {
+				components = newConfig.Log.Levels
+			}
+		})
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+			}
+
+			if reloader != nil {
+				if err := reloader.Reload(); err != nil {
+					logger.Warn("Failed to reload configuration on SIGHUP", map[string]interface{}{
+						"source": reloader.Name(),
+						"error":  err.Error(),
+					})
+				}
+			}
+			mu.Lock()
+			configured, configuredComponents := level, components
+			mu.Unlock()
+			logger.SetLevel(configured)
+			applyComponentLogLevels(configuredComponents)
+			logger.Info("Log levels restored on SIGHUP", map[string]interface{}{
+				"level":      logger.Level(),
+				"components": logger.ComponentLevels(),
+			})
+		}
+	}()
+}
+
+// reloadInformerResync returns a subscriber applying a changed resync period to informer
+func reloadInformerResync(informer *kubernetes.DeploymentInformer) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if oldConfig.Controller.ResyncPeriod == newConfig.Controller.ResyncPeriod {
+			return
+		}
+		if err := informer.SetResyncPeriod(newConfig.Controller.ResyncPeriod); err != nil {
+			logger.Error("Failed to apply resync period", err, map[string]interface{}{
+				"resync_period": newConfig.Controller.ResyncPeriod.String(),
+			})
+		}
+	}
+}
+
+// reloadClientPool returns a subscriber applying a changed connection limit
+// to pool
+func reloadClientPool(pool *cluster.ClientPool) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if oldConfig.MultiCluster.MaxConcurrentConns != newConfig.MultiCluster.MaxConcurrentConns {
+			pool.SetMaxClients(newConfig.MultiCluster.MaxConcurrentConns)
+		}
+	}
+}
+
+// reloadClusterRegistry returns a subscriber applying cluster changes, such as
+// those made through the cluster API, to registry
+func reloadClusterRegistry(registry cluster.ClusterRegistry) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		newClusters := make(map[string]config.ClusterConfig, len(newConfig.MultiCluster.Clusters))
+		for _, clusterConfig := range newConfig.MultiCluster.Clusters {
+			newClusters[clusterConfig.Name] = clusterConfig
+		}
+
+		for _, oldCluster := range oldConfig.MultiCluster.Clusters {
+			newCluster, exists := newClusters[oldCluster.Name]
+			switch {
+			case !exists:
+				_ = registry.RemoveCluster(oldCluster.Name)
+			case newCluster == oldCluster:
+				// Unchanged clusters keep their cached clients
+				delete(newClusters, oldCluster.Name)
+			}
+		}
+		// Added and changed clusters replace any previous registration
+		for name, newCluster := range newClusters {
+			if err := registry.AddCluster(name, cluster.NewClusterConfigFrom(newCluster)); err != nil {
+				logger.Warn("Failed to register cluster", map[string]interface{}{
+					"cluster": name,
+					"error":   err.Error(),
+				})
+			}
+		}
+	}
+}
+
+// reloadDriftDetector returns a subscriber applying changed drift groups and
+// interval to detector
+func reloadDriftDetector(detector *drift.Detector) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if reflect.DeepEqual(oldConfig.MultiCluster.Drift, newConfig.MultiCluster.Drift) {
+			return
+		}
+		detector.SetConfig(newConfig.MultiCluster.Drift)
+		logger.Info("Drift detection groups changed", map[string]interface{}{
+			"groups":   len(newConfig.MultiCluster.Drift.Groups),
+			"interval": newConfig.MultiCluster.Drift.Interval.String(),
+		})
+	}
+}
+
+// reloadManifestDriftDetector returns a subscriber applying changed manifest
+// drift settings to detector
+func reloadManifestDriftDetector(detector *drift.ManifestDetector) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if reflect.DeepEqual(oldConfig.Controller.Drift, newConfig.Controller.Drift) {
+			return
+		}
+		detector.SetConfig(newConfig.Controller.Drift)
+		logger.Info("Manifest drift detection changed", map[string]interface{}{
+			"enabled":  newConfig.Controller.Drift.Enabled,
+			"path":     newConfig.Controller.Drift.Path,
+			"interval": newConfig.Controller.Drift.Interval.String(),
+		})
+	}
+}
+
+// reloadComplianceScanner returns a subscriber applying changed image
+// compliance rules to scanner
+func reloadComplianceScanner(scanner *compliance.Scanner) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if reflect.DeepEqual(oldConfig.Controller.Compliance, newConfig.Controller.Compliance) {
+			return
+		}
+		scanner.SetConfig(newConfig.Controller.Compliance)
+		logger.Info("Image compliance scanning changed", map[string]interface{}{
+			"enabled":  newConfig.Controller.Compliance.Enabled,
+			"interval": newConfig.Controller.Compliance.Interval.String(),
+		})
+	}
+}
+
+// reloadRolloutTracker returns a subscriber applying changed rollout health
+// settings to tracker
+func reloadRolloutTracker(tracker *rollout.Tracker) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if reflect.DeepEqual(oldConfig.Controller.Rollouts, newConfig.Controller.Rollouts) {
+			return
+		}
+		tracker.SetConfig(newConfig.Controller.Rollouts)
+		logger.Info("Rollout health tracking changed", map[string]interface{}{
+			"enabled":  newConfig.Controller.Rollouts.Enabled,
+			"interval": newConfig.Controller.Rollouts.Interval.String(),
+		})
+	}
+}
+
+// reloadAnomalyDetector returns a subscriber applying changed anomaly
+// detection settings to detector
+func reloadAnomalyDetector(detector *anomaly.Detector) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if reflect.DeepEqual(oldConfig.Controller.Anomalies, newConfig.Controller.Anomalies) {
+			return
+		}
+		detector.SetConfig(newConfig.Controller.Anomalies)
+		logger.Info("Anomaly detection changed", map[string]interface{}{
+			"enabled":  newConfig.Controller.Anomalies.Enabled,
+			"interval": newConfig.Controller.Anomalies.Interval.String(),
+			"window":   newConfig.Controller.Anomalies.Window.String(),
+		})
+	}
+}
+
+// reloadNotifier returns a subscriber applying changed notification sinks
+// and rules to notifier
+func reloadNotifier(notifier *notify.Notifier) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if reflect.DeepEqual(oldConfig.Notifications, newConfig.Notifications) {
+			return
+		}
+		notifier.SetConfig(newConfig.Notifications)
+		logger.Info("Notifications changed", map[string]interface{}{
+			"enabled": newConfig.Notifications.Enabled,
+			"sinks":   len(newConfig.Notifications.Sinks),
+			"rules":   len(newConfig.Notifications.Rules),
+		})
+	}
+}
+
+// reloadPropagator returns a subscriber applying changed propagation
+// settings to propagator
+func reloadPropagator(propagator *propagation.Propagator) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if reflect.DeepEqual(oldConfig.MultiCluster.Propagation, newConfig.MultiCluster.Propagation) {
+			return
+		}
+		propagator.SetConfig(newConfig.MultiCluster.Propagation)
+		logger.Info("Deployment propagation settings changed", map[string]interface{}{
+			"enabled":  newConfig.MultiCluster.Propagation.Enabled,
+			"interval": newConfig.MultiCluster.Propagation.Interval.String(),
+		})
+	}
+}
+
+// reloadSecretWatcher returns a subscriber updating the clusters whose
+// kubeconfig Secrets watcher checks
+func reloadSecretWatcher(watcher *cluster.SecretWatcher) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		watcher.SetClusters(newConfig.MultiCluster.Clusters)
+	}
+}
+
+// reloadHealthTracker returns a subscriber applying a changed health check
+// interval to tracker
+func reloadHealthTracker(tracker *cluster.HealthTracker) config.Subscriber {
+	return func(oldConfig, newConfig *config.Config) {
+		if oldConfig.MultiCluster.HealthCheckInterval == newConfig.MultiCluster.HealthCheckInterval {
+			return
+		}
+		tracker.SetInterval(newConfig.MultiCluster.HealthCheckInterval)
+		logger.Info("Cluster health check interval changed", map[string]interface{}{
+			"interval": newConfig.MultiCluster.HealthCheckInterval.String(),
+		})
+	}
+}
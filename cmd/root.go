@@ -5,13 +5,14 @@ import (
 	"os"
 	"strings"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	Version  = "v0.10.0"
 	cfgFile  string
 	logLevel string
 )
@@ -50,23 +51,34 @@ Examples:
 
   # Start HTTP server for API access
   k6s server --port 8080`,
-	Version: Version,
+	Version: version.Version,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		config.SetStrict(viper.GetBool("strict-config"))
+
 		// Skip logging setup for certain commands that need clean output
-		if cmd.Use == "version" || cmd.Use == "completion" {
+		switch cmd.Name() {
+		case "version", "completion", "schema", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
 			return
 		}
 
-		// Initialize logger with the specified log level
+		// Initialize logger with the specified log level; JSON streams keep
+		// stderr for errors only, and the terminal UI for fatal errors
+		level := logLevel
+		switch {
+		case cmd == deploymentListCmd && deployOutput == deployOutputJSONStream:
+			level = "error"
+		case cmd == uiCmd:
+			level = "fatal"
+		}
 		logger.Init(logger.Config{
-			Level:       logLevel,
+			Level:       level,
 			Format:      "text",
 			Development: false,
 			Caller:      false,
 		})
 		
 		logger.Info("k6s controller starting", map[string]interface{}{
-			"version": Version,
+			"version": version.Version,
 			"command": cmd.Use,
 		})
 	},
@@ -79,6 +91,7 @@ Examples:
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
+	registerFlagCompletions(rootCmd)
 	return rootCmd.Execute()
 }
 
@@ -90,8 +103,11 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", 
 		fmt.Sprintf("log level (%s)", getValidLogLevels()))
 
+	rootCmd.PersistentFlags().Bool("strict-config", false, "reject unknown keys in the config file (or K6S_STRICT_CONFIG)")
+
 	// Bind flags to viper
 	_ = viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
+	_ = viper.BindPFlag("strict-config", rootCmd.PersistentFlags().Lookup("strict-config"))
 
 	// Version flags - using SetVersionTemplate for proper Cobra integration
 	rootCmd.SetVersionTemplate("k6s version {{.Version}}\n")
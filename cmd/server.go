@@ -1,24 +1,48 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/anomaly"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/compliance"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	grpcapi "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/grpc"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/lifecycle"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/notify"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/propagation"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/rollout"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/server"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/telemetry"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	k8s "k8s.io/client-go/kubernetes"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var (
-	serverPort         int
-	enableInformer     bool
-	informerNamespace  string
-	informerResyncTime string
+	serverPort          int
+	enableInformer      bool
+	informerNamespace   string
+	excludedNamespaces  []string
+	informerResyncTime  string
+	suppressNoopUpdates bool
+	grpcPort            int
+	tlsCertFile         string
+	tlsKeyFile          string
+	shutdownTimeout     time.Duration
 )
 
 // serverCmd represents the server command
@@ -29,45 +53,340 @@ var serverCmd = &cobra.Command{
 	
 The server provides REST API endpoints for health checks, version info,
 and deployment resources. When informer is enabled, it provides real-time
-deployment data via /api/v1/deployments endpoints.
+deployment data via /api/v1/deployments endpoints and streams live
+deployment events as Server-Sent Events from /api/v1/deployments/watch
+or over a WebSocket at /api/v1/deployments/ws. The same deployment and
+cluster data is available over gRPC when --grpc-port or server.grpc is set.
+The clusters of the configuration file can be managed at /api/v1/clusters.
+With multi_cluster.propagation.enabled, deployments labeled k6s.io/propagate=true
+on the primary cluster are copied to the other clusters, as reported at
+/api/v1/propagation.
+Deployments of every cluster are listed at /api/v1/deployments?cluster=*
+and /api/v1/clusters/{name}/deployments, and the health of each cluster is
+tracked at /api/v1/clusters/{name}/status.
+The HTTP API is described at /openapi.json and browsable at /docs.
 
 Examples:
   k6s server                                    # start server on default port 8080
   k6s server --port 9090                       # start server on port 9090
   k6s server --enable-informer                 # start server with deployment informer
   k6s server --enable-informer --namespace=prod # start server with informer for specific namespace
+  k6s server --enable-informer --grpc-port 9090  # also serve the gRPC API on port 9090
+  k6s server --tls-cert-file tls.crt --tls-key-file tls.key  # serve HTTPS
   K6S_SERVER_PORT=8081 k6s server              # start server using env var`,
 	Run: func(cmd *cobra.Command, args []string) {
+		// Load configuration, from the ConfigMap of --config-map when set
+		reloader, reloadErr := newConfigReloader(cmd, cfgFile, "")
+		if reloadErr != nil && configMapFlag(cmd, "config-map") != "" {
+			logger.Fatal("Failed to load configuration", reloadErr, nil)
+		}
+		cfg := loadServerConfig(reloader)
+
 		// Get port from viper (supports env vars, config files, and flags)
 		port := viper.GetInt("server.port")
 		if port == 0 {
 			port = serverPort // fallback to flag value
 		}
+		cfg.Server.Port = port
+		if tlsCertFile != "" {
+			cfg.Server.TLS.CertFile = tlsCertFile
+			cfg.Server.TLS.KeyFile = tlsKeyFile
+		}
+		applyKubeClientFlags(cmd, &cfg.Controller.Client)
+		if informerNamespace != "" {
+			cfg.Controller.Single.Namespace = informerNamespace
+		}
+		if len(excludedNamespaces) > 0 {
+			cfg.Controller.Single.ExcludeNamespaces = excludedNamespaces
+		}
+		if err := config.NewConfigValidator(cfg).ValidateController(); err != nil {
+			logger.Fatal("Invalid configuration", err, nil)
+		}
+		applyComponentLogLevels(cfg.Log.Levels)
+		applyLogSampling(cfg.Log.Sampling)
 		
 		logger.Info("Starting k6s server", map[string]interface{}{
 			"component":      "server",
 			"port":           port,
-			"version":        Version,
+			"version":        version.Version,
 			"enable_informer": enableInformer,
 		})
 
+		// Components are stopped on SIGTERM in the reverse order they
+		// are added, so the API stops before the informers it reads
+		if cmd.Flags().Changed("shutdown-timeout") {
+			cfg.Server.ShutdownTimeout = shutdownTimeout
+		}
+		lc := lifecycle.NewCoordinator(cfg.Server.ShutdownTimeout)
+
+		// Export spans of API requests and informer events when enabled
+		if cfg.Telemetry.Enabled {
+			shutdownTelemetry, err := telemetry.Setup(context.Background(), cfg.Telemetry)
+			if err != nil {
+				logger.Fatal("Failed to setup telemetry", err, nil)
+			}
+			lc.OnShutdown("telemetry", shutdownTelemetry)
+		}
+
 		// Create server
-		srv := server.New(port)
+		srv := server.NewWithConfig(cfg.Server)
+		if reloader == nil || reloader.Path() != "" {
+			// Clusters added through the API are written to the
+			// configuration file; a ConfigMap is only read
+			srv.SetClusterStore(cluster.NewConfigStore(cfgFile))
+		}
+		// Serve the API metrics alongside the controller-runtime metrics
+		if err := metrics.RegisterBuildInfo(ctrlmetrics.Registry); err != nil {
+			logger.Warn("Failed to register build info metric", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		if err := metrics.RegisterAPIMetrics(ctrlmetrics.Registry); err != nil {
+			logger.Warn("Failed to register API metrics", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		if err := metrics.RegisterClusterClientMetrics(ctrlmetrics.Registry); err != nil {
+			logger.Warn("Failed to register cluster client metrics", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		srv.SetMetricsGatherer(ctrlmetrics.Registry)
+		if cfg.Server.Auth.TokenReview.Enabled {
+			if err := setupTokenReview(srv, cfg); err != nil {
+				logger.Fatal("Failed to setup token review", err, nil)
+			}
+		}
+		
+		// Deliver informer events matching notification rules when enabled
+		notifier := notify.New(cfg.Notifications)
 		
 		// Setup informer if enabled
+		var informer *kubernetes.DeploymentInformer
+		var eventHistory *history.EventStore
 		if enableInformer {
-			if err := setupDeploymentInformer(srv); err != nil {
+			// Record informer events for the history API when enabled
+			eventHistory = openEventHistory(cfg, lc)
+			
+			var err error
+			informer, err = setupDeploymentInformer(srv, cfg, notifier, eventHistory, lc)
+			if err != nil {
 				logger.Fatal("Failed to setup deployment informer", err, nil)
 			}
+		} else {
+			if cfg.Notifications.Enabled {
+				logger.Warn("Notifications disabled", map[string]interface{}{
+					"reason": "requires --enable-informer",
+				})
+			}
+			if cfg.History.Events.Enabled {
+				logger.Warn("Event history disabled", map[string]interface{}{
+					"reason": "requires --enable-informer",
+				})
+			}
 		}
 
-		// Setup graceful shutdown
 		// Start server in goroutine
-		serverError := make(chan error, 1)
+		serverError := make(chan error, 2)
+		serverStopped := make(chan struct{})
 		go func() {
 			serverError <- srv.Start()
+			close(serverStopped)
 		}()
 
+		// Start gRPC API if enabled
+		if cmd.Flags().Changed("grpc-port") {
+			cfg.Server.GRPC.Enabled = true
+			cfg.Server.GRPC.Port = grpcPort
+		}
+		registry := newClusterRegistry(cfg)
+		var grpcSrv *grpcapi.Server
+		if cfg.Server.GRPC.Enabled {
+			// Callers authenticate like those of the HTTP API when it requires it
+			var grpcOpts []grpclib.ServerOption
+			if cfg.Server.Auth.Enabled() {
+				grpcOpts = append(grpcOpts, grpcapi.WithAuth(srv)...)
+			}
+
+			// Serve the HTTP API's certificate, reloaded until gRPC stops
+			grpcTLSCtx, stopGRPCTLS := context.WithCancel(context.Background())
+			if cfg.Server.TLS.Enabled() {
+				tlsConfig, err := srv.TLSConfig(grpcTLSCtx)
+				if err != nil {
+					logger.Fatal("Failed to load gRPC TLS configuration", err, nil)
+				}
+				grpcOpts = append(grpcOpts, grpclib.Creds(credentials.NewTLS(tlsConfig)))
+			}
+
+			grpcSrv = grpcapi.NewServer(cfg.Server.GRPC.Port, informer, registry, grpcOpts...)
+			go func() {
+				defer stopGRPCTLS()
+				serverError <- grpcSrv.Start()
+			}()
+		}
+
+		// Record drift and propagation findings as events on deployments
+		eventRecorders := cluster.NewEventRecorders(registry)
+		lc.OnShutdown("event recorders", lifecycle.Stop(eventRecorders.Stop))
+
+		// Compare deployments across drift groups in the background
+		reloadCtx, stopReload := context.WithCancel(context.Background())
+		detector := drift.NewDetector(registry, cfg.MultiCluster.Drift)
+		detector.SetEventRecorders(eventRecorders)
+		srv.SetDriftDetector(detector)
+		go detector.Start(reloadCtx)
+
+		// Compare cached deployments with their declared manifests when enabled
+		var manifestDetector *drift.ManifestDetector
+		if informer != nil {
+			manifestDetector = drift.NewManifestDetector(informer, cfg.Controller.Drift)
+			recorder, stopRecorder := kubernetes.NewEventRecorder(informer.Clientset())
+			manifestDetector.SetEventRecorder(recorder)
+			lc.OnShutdown("manifest drift events", lifecycle.Stop(stopRecorder))
+			srv.SetManifestDriftDetector(manifestDetector)
+			go manifestDetector.Start(reloadCtx)
+		} else if cfg.Controller.Drift.Enabled {
+			logger.Warn("Manifest drift detection disabled", map[string]interface{}{
+				"reason": "requires --enable-informer",
+			})
+		}
+
+		// Scan the images of cached deployments against registry rules when enabled
+		var complianceScanner *compliance.Scanner
+		if informer != nil {
+			complianceScanner = compliance.NewScanner(informer, cfg.Controller.Compliance)
+			srv.SetComplianceScanner(complianceScanner)
+			go complianceScanner.Start(reloadCtx)
+		} else if cfg.Controller.Compliance.Enabled {
+			logger.Warn("Image compliance scanning disabled", map[string]interface{}{
+				"reason": "requires --enable-informer",
+			})
+		}
+
+		// Score rollout health and warn of stuck rollouts when enabled
+		var rolloutTracker *rollout.Tracker
+		if informer != nil {
+			rolloutTracker = rollout.NewTracker(informer, cfg.Controller.Rollouts)
+			recorder, stopRecorder := kubernetes.NewEventRecorder(informer.Clientset())
+			rolloutTracker.SetEventRecorder(recorder)
+			lc.OnShutdown("rollout events", lifecycle.Stop(stopRecorder))
+			clusterName := cfg.Server.ClusterName
+			if clusterName == "" {
+				clusterName = "default"
+			}
+			rolloutTracker.SetNotifier(notifier, clusterName)
+			srv.SetRolloutTracker(rolloutTracker)
+			go rolloutTracker.Start(reloadCtx)
+		} else if cfg.Controller.Rollouts.Enabled {
+			logger.Warn("Rollout health tracking disabled", map[string]interface{}{
+				"reason": "requires --enable-informer",
+			})
+		}
+
+		// Sample replica and restart trends into the event history and alert
+		// on anomalies when enabled
+		var anomalyDetector *anomaly.Detector
+		if informer != nil && eventHistory != nil {
+			anomalyDetector = anomaly.NewDetector(informer, eventHistory, cfg.Controller.Anomalies)
+			clusterName := cfg.Server.ClusterName
+			if clusterName == "" {
+				clusterName = "default"
+			}
+			anomalyDetector.SetNotifier(notifier, clusterName)
+			srv.SetAnomalyDetector(anomalyDetector)
+			go anomalyDetector.Start(reloadCtx)
+		} else if cfg.Controller.Anomalies.Enabled {
+			logger.Warn("Anomaly detection disabled", map[string]interface{}{
+				"reason": "requires --enable-informer and history.events.enabled",
+			})
+		}
+
+		// Copy labeled deployments from the primary cluster when enabled
+		propagator := propagation.NewPropagator(registry, cfg.MultiCluster.Propagation)
+		propagator.SetEventRecorders(eventRecorders)
+		srv.SetPropagator(propagator)
+		go propagator.Start(reloadCtx)
+		go notifier.Start(reloadCtx)
+		if eventHistory != nil {
+			go eventHistory.Start(reloadCtx)
+		}
+
+		// Cache deployments of every cluster for the aggregated views
+		clusterInformers := server.NewClusterInformers(registry, func(clientset k8s.Interface) *kubernetes.DeploymentInformer {
+			return kubernetes.NewDeploymentInformerWithConfig(clientset, cfg)
+		})
+		srv.SetClusterInformers(clusterInformers)
+		clusterInformers.Sync()
+		lc.OnShutdown("cluster informers", lifecycle.Stop(clusterInformers.Stop))
+
+		// Track the health of every enabled cluster
+		healthTracker := cluster.NewHealthTracker(registry, cfg.MultiCluster.HealthCheckInterval)
+		healthTracker.SetCacheSource(clusterInformers)
+		srv.SetHealthTracker(healthTracker)
+		go healthTracker.Start(reloadCtx)
+
+		// Rebuild clients of clusters whose kubeconfig Secret changed
+		secretWatcher := cluster.NewSecretWatcher(cfg.MultiCluster.SecretRefreshInterval, func(clusterConfig config.ClusterConfig) {
+			if err := registry.AddCluster(clusterConfig.Name, cluster.NewClusterConfigFrom(clusterConfig)); err != nil {
+				logger.Warn("Failed to register cluster", map[string]interface{}{
+					"cluster": clusterConfig.Name,
+					"error":   err.Error(),
+				})
+			}
+		})
+		secretWatcher.SetClusters(cfg.MultiCluster.Clusters)
+		go secretWatcher.Start(reloadCtx)
+
+		// Apply configuration changes at runtime
+		if reloadErr != nil {
+			logger.Warn("Configuration hot reload disabled", map[string]interface{}{
+				"error": reloadErr.Error(),
+			})
+		} else {
+			reloader.Subscribe(reloadLogLevel)
+			reloader.Subscribe(reloadClusterRegistry(registry))
+			reloader.Subscribe(reloadClientPool(registry.Pool()))
+			reloader.Subscribe(reloadDriftDetector(detector))
+			if manifestDetector != nil {
+				reloader.Subscribe(reloadManifestDriftDetector(manifestDetector))
+			}
+			if complianceScanner != nil {
+				reloader.Subscribe(reloadComplianceScanner(complianceScanner))
+			}
+			if rolloutTracker != nil {
+				reloader.Subscribe(reloadRolloutTracker(rolloutTracker))
+			}
+			if anomalyDetector != nil {
+				reloader.Subscribe(reloadAnomalyDetector(anomalyDetector))
+			}
+			reloader.Subscribe(reloadPropagator(propagator))
+			reloader.Subscribe(reloadNotifier(notifier))
+			reloader.Subscribe(reloadSecretWatcher(secretWatcher))
+			if informer != nil {
+				reloader.Subscribe(reloadInformerResync(informer))
+			}
+			startConfigReloader(reloadCtx, reloader)
+		}
+		restoreLogLevelOnSIGHUP(reloadCtx, reloader)
+		lc.OnShutdown("background workers", lifecycle.Stop(stopReload))
+
+		// Stop the gRPC API, then stop accepting HTTP requests and drain
+		// those in flight
+		if grpcSrv != nil {
+			lc.OnShutdown("grpc server", lifecycle.Stop(grpcSrv.Stop))
+		}
+		lc.OnShutdown("http server", func(ctx context.Context) error {
+			if err := srv.Shutdown(ctx); err != nil {
+				return err
+			}
+			select {
+			case <-serverStopped:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+
 		// Wait for interrupt signal
 		interrupt := make(chan os.Signal, 1)
 		signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
@@ -80,7 +399,14 @@ Examples:
 				})
 			}
 		case <-interrupt:
-			logger.Info("Received interrupt signal, shutting down server", nil)
+			logger.Info("Received interrupt signal, shutting down server", map[string]interface{}{
+				"timeout": cfg.Server.ShutdownTimeout.String(),
+			})
+		}
+		if err := lc.Shutdown(); err != nil {
+			logger.Warn("Server did not shut down cleanly", map[string]interface{}{
+				"error": err.Error(),
+			})
 		}
 	},
 }
@@ -91,8 +417,17 @@ func init() {
 	// Add server-specific flags
 	serverCmd.Flags().IntVarP(&serverPort, "port", "p", 8080, "server port")
 	serverCmd.Flags().BoolVar(&enableInformer, "enable-informer", false, "enable deployment informer for API endpoints")
-	serverCmd.Flags().StringVar(&informerNamespace, "namespace", "", "kubernetes namespace to watch (empty = all namespaces)")
+	serverCmd.Flags().StringVar(&informerNamespace, "namespace", "", "comma-separated kubernetes namespaces to watch (empty = all namespaces)")
+	serverCmd.Flags().StringSliceVar(&excludedNamespaces, "exclude-namespaces", nil, "comma-separated kubernetes namespaces not to watch")
 	serverCmd.Flags().StringVar(&informerResyncTime, "resync-period", "", "informer cache resync period (e.g., 5m, 30s)")
+	serverCmd.Flags().BoolVar(&suppressNoopUpdates, "suppress-noop-updates", false, "skip informer updates that change neither spec, status, labels nor annotations, such as resyncs")
+	serverCmd.Flags().IntVar(&grpcPort, "grpc-port", 9090, "serve the gRPC API on this port (enables gRPC)")
+	serverCmd.Flags().StringVar(&tlsCertFile, "tls-cert-file", "", "serve HTTPS with this PEM certificate chain (requires --tls-key-file)")
+	serverCmd.Flags().StringVar(&tlsKeyFile, "tls-key-file", "", "PEM private key of --tls-cert-file")
+	serverCmd.MarkFlagsRequiredTogether("tls-cert-file", "tls-key-file")
+	addKubeClientFlags(serverCmd)
+	addConfigMapFlags(serverCmd)
+	serverCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "how long shutdown waits for in-flight requests and informers to stop")
 	
 	// Bind flags to viper for environment variable support
 	if err := viper.BindPFlag("server.port", serverCmd.Flags().Lookup("port")); err != nil {
@@ -105,22 +440,57 @@ func init() {
 	}
 }
 
-// setupDeploymentInformer creates and starts deployment informer for server
-func setupDeploymentInformer(srv *server.Server) error {
-	// Load configuration
-	cfg, err := config.LoadConfig(configFile)
+// loadServerConfig returns the configuration for the server command: a copy
+// of the configuration of reloader, so that flag overrides aren't seen as
+// changes on reload, or the configuration file when reloader is nil, falling
+// back to defaults
+func loadServerConfig(reloader *config.ConfigReloader) *config.Config {
+	if reloader != nil {
+		cfg := *reloader.Current()
+		return &cfg
+	}
+
+	cfg, err := config.LoadConfig(cfgFile)
 	if err != nil {
 		logger.Warn("Failed to load config, using defaults", map[string]interface{}{
-			"config_file": configFile,
+			"config_file": cfgFile,
 			"error":       err.Error(),
 		})
 		cfg = config.DefaultConfig()
 	}
+	return cfg
+}
 
-	// Override with command line flags
-	if informerNamespace != "" {
-		cfg.Controller.Single.Namespace = informerNamespace
+// newClusterRegistry builds a cluster registry from the configured clusters
+func newClusterRegistry(cfg *config.Config) *cluster.InMemoryClusterRegistry {
+	registry := cluster.NewInMemoryClusterRegistry()
+	registry.Pool().SetMaxClients(cfg.MultiCluster.MaxConcurrentConns)
+	for _, clusterConfig := range cfg.MultiCluster.Clusters {
+		if err := registry.AddCluster(clusterConfig.Name, cluster.NewClusterConfigFrom(clusterConfig)); err != nil {
+			logger.Warn("Failed to register cluster", map[string]interface{}{
+				"cluster": clusterConfig.Name,
+				"error":   err.Error(),
+			})
+		}
+	}
+	return registry
+}
+
+// setupTokenReview validates bearer tokens unknown to the server with the
+// Kubernetes TokenReview API
+func setupTokenReview(srv *server.Server, cfg *config.Config) error {
+	client, err := kubernetes.NewClientWithConfig("", cfg.Controller.Client)
+	if err != nil {
+		return err
 	}
+	review := cfg.Server.Auth.TokenReview
+	srv.SetTokenReviewer(server.NewTokenReviewer(client.Clientset().AuthenticationV1().TokenReviews(), review.Audiences, review.CacheTTL, review.CacheSize))
+	return nil
+}
+
+// setupDeploymentInformer creates and starts deployment informer for server
+func setupDeploymentInformer(srv *server.Server, cfg *config.Config, notifier *notify.Notifier, eventHistory *history.EventStore, lc *lifecycle.Coordinator) (*kubernetes.DeploymentInformer, error) {
+	// Override with command line flags
 	if informerResyncTime != "" {
 		if duration, parseErr := time.ParseDuration(informerResyncTime); parseErr == nil {
 			cfg.Controller.ResyncPeriod = duration
@@ -133,23 +503,344 @@ func setupDeploymentInformer(srv *server.Server) error {
 		}
 	}
 
+	if suppressNoopUpdates {
+		cfg.Controller.Informer.SuppressNoopUpdates = true
+	}
+
 	// Create Kubernetes client
-	client, err := kubernetes.NewClient("")
+	client, err := kubernetes.NewClientWithConfig("", cfg.Controller.Client)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Informers of the cluster share one watch and cache per resource type
+	resyncPeriod := cfg.Controller.ResyncPeriod
+	if resyncPeriod == 0 {
+		resyncPeriod = 30 * time.Second
 	}
+	informerSet := kubernetes.NewInformerSetForScope(client.Clientset(), cfg.Controller.Single.NamespaceScope(), resyncPeriod)
 
 	// Create informer with config
-	informer := kubernetes.NewDeploymentInformerWithConfig(client.Clientset(), cfg)
+	informer := kubernetes.NewDeploymentInformerFor(informerSet, cfg)
+	clusterName := cfg.Server.ClusterName
+	if clusterName == "" {
+		clusterName = "default"
+	}
+	if m, err := metrics.NewWithRegisterer(ctrlmetrics.Registry); err != nil {
+		logger.Warn("Failed to register informer metrics", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		informer.SetMetrics(m, clusterName)
+	}
+	informer.AddEventHandler(notify.InformerHandler(notifier, clusterName))
+	if eventHistory != nil {
+		informer.AddEventHandler(history.InformerHandler(eventHistory, clusterName))
+		srv.SetEventHistory(eventHistory)
+	}
+
+	setupResourceInformers(srv, informerSet, informer, cfg, lc)
 
-	// Set informer in server
+	// Set informer and client in server
 	srv.SetDeploymentInformer(informer)
+	srv.SetDeploymentClient(client)
+	srv.SetDeletedHistory(history.NewStore(cfg.History.Dir, cfg.History.Retention), deploymentCluster(""))
 
 	// Start informer
 	logger.Info("Starting deployment informer", map[string]interface{}{
-		"namespace":     cfg.Controller.Single.Namespace,
+		"namespaces":    cfg.Controller.Single.NamespaceScope().String(),
 		"resync_period": cfg.Controller.ResyncPeriod,
 	})
 
-	return informer.Start()
+	lc.OnShutdown("deployment informer", lifecycle.Stop(informer.Stop))
+	if cfg.Controller.Informer.Snapshot.Path != "" && setupDeploymentSnapshot(informer, cfg.Controller.Informer.Snapshot, lc) {
+		// The snapshot is served while the cache syncs in the background
+		go func() {
+			if err := informer.Start(); err != nil {
+				logger.Error("Failed to start deployment informer", err, nil)
+			}
+		}()
+		return informer, nil
+	}
+	return informer, informer.Start()
+}
+
+// setupResourceInformers starts the informers of the resources served
+// besides deployments. With server.disable_enrichment, the informers read
+// only by the disabled endpoints and namespace label propagation aren't
+// started, so their resources are neither listed nor cached.
+func setupResourceInformers(srv *server.Server, set *kubernetes.InformerSet, deployments *kubernetes.DeploymentInformer, cfg *config.Config, lc *lifecycle.Coordinator) {
+	if cfg.Server.DisableEnrichment {
+		logger.Info("Enrichment informers not started", map[string]interface{}{
+			"informers": "namespaces, pods, replicasets, events, configmaps, secrets, ingresses, services",
+		})
+	} else {
+		// Cache namespaces and pods for the namespace endpoints, and propagate
+		// namespace labels onto deployment views
+		setupNamespaceInformers(srv, set, cfg, lc)
+		setupReplicaSetInformer(srv, set, cfg, lc)
+		setupEventInformer(srv, set, cfg, lc)
+		setupConfigInformer(srv, set, deployments, cfg, lc)
+		setupIngressInformer(srv, set, cfg, lc)
+	}
+	setupNodeInformer(srv, set, cfg, lc)
+	setupJobInformer(srv, set, cfg, lc)
+	setupPVCInformer(srv, set, cfg, lc)
+}
+
+// setupDeploymentSnapshot saves the deployment cache to the snapshot file
+// periodically and on shutdown, and loads the last snapshot. It returns
+// whether a snapshot was loaded, to be served until the cache has synced.
+func setupDeploymentSnapshot(informer *kubernetes.DeploymentInformer, snapshot config.SnapshotConfig, lc *lifecycle.Coordinator) bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		informer.RunSnapshots(ctx, snapshot.Path, snapshot.Interval)
+	}()
+	// Saved once more before the informer stops
+	lc.OnShutdown("deployment snapshot", func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	})
+
+	loaded, err := informer.LoadSnapshot(snapshot.Path, snapshot.MaxAge)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Deployment snapshot not loaded", map[string]interface{}{
+				"path":  snapshot.Path,
+				"error": err.Error(),
+			})
+		}
+		return false
+	}
+	logger.Info("Serving deployment snapshot until the cache syncs", map[string]interface{}{
+		"path":        snapshot.Path,
+		"deployments": loaded,
+	})
+	return true
+}
+
+// openEventHistory opens the store recording the deployment informer's
+// events when event history is enabled. The store is closed on shutdown,
+// after the informer stops. Without a store the server runs without the
+// history API.
+func openEventHistory(cfg *config.Config, lc *lifecycle.Coordinator) *history.EventStore {
+	events := cfg.History.Events
+	if !events.Enabled {
+		return nil
+	}
+	path := events.Path
+	if path == "" {
+		path = history.DefaultEventsPath(cfg.History.Dir)
+	}
+	store, err := history.OpenEventStore(path, events.Retention)
+	if err != nil {
+		logger.Warn("Event history disabled", map[string]interface{}{
+			"path":  path,
+			"error": err.Error(),
+		})
+		return nil
+	}
+	lc.OnShutdown("event history", func(ctx context.Context) error {
+		return store.Close()
+	})
+	logger.Info("Recording deployment events", map[string]interface{}{
+		"path":      path,
+		"retention": events.Retention.String(),
+	})
+	return store
+}
+
+// setupNamespaceInformers starts the namespace and pod informers served at
+// /api/v1/namespaces. Namespace label propagation reads the same namespace
+// cache. Without permission to list namespaces or pods the server runs
+// without the corresponding endpoints.
+func setupNamespaceInformers(srv *server.Server, set *kubernetes.InformerSet, cfg *config.Config, lc *lifecycle.Coordinator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	namespaces := kubernetes.NewNamespaceInformerFor(set)
+	labels := cfg.Server.LabelPropagation.Labels
+	if err := namespaces.Start(ctx); err != nil {
+		fields := map[string]interface{}{"error": err.Error()}
+		if len(labels) > 0 {
+			fields["labels"] = labels
+		}
+		logger.Warn("Namespace informer disabled", fields)
+	} else {
+		srv.SetNamespaceInformer(namespaces)
+		lc.OnShutdown("namespace informer", lifecycle.Stop(namespaces.Stop))
+		if len(labels) > 0 {
+			srv.SetLabelPropagator(kubernetes.NewNamespaceLabelPropagatorFor(namespaces, labels))
+			logger.Info("Propagating namespace labels", map[string]interface{}{
+				"labels": labels,
+			})
+		}
+	}
+
+	pods := kubernetes.NewPodInformerFor(set)
+	if err := pods.Start(ctx); err != nil {
+		logger.Warn("Pod informer disabled", map[string]interface{}{
+			"namespaces": cfg.Controller.Single.NamespaceScope().String(),
+			"error":     err.Error(),
+		})
+		return
+	}
+	srv.SetPodInformer(pods)
+	lc.OnShutdown("pod informer", lifecycle.Stop(pods.Stop))
+}
+
+// setupReplicaSetInformer starts the replica set informer deployment revisions
+// are read from. Without permission to list replica sets the server runs
+// without the revisions endpoint.
+func setupReplicaSetInformer(srv *server.Server, set *kubernetes.InformerSet, cfg *config.Config, lc *lifecycle.Coordinator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	replicaSets := kubernetes.NewReplicaSetInformerFor(set)
+	if err := replicaSets.Start(ctx); err != nil {
+		logger.Warn("ReplicaSet informer disabled", map[string]interface{}{
+			"namespaces": cfg.Controller.Single.NamespaceScope().String(),
+			"error":     err.Error(),
+		})
+		return
+	}
+	srv.SetReplicaSetInformer(replicaSets)
+	lc.OnShutdown("replica set informer", lifecycle.Stop(replicaSets.Stop))
+}
+
+// setupEventInformer starts the event informer served with the events of
+// deployments. Without permission to list events the server runs without the
+// deployment events endpoint.
+func setupEventInformer(srv *server.Server, set *kubernetes.InformerSet, cfg *config.Config, lc *lifecycle.Coordinator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	events := kubernetes.NewEventInformerFor(set)
+	if err := events.Start(ctx); err != nil {
+		logger.Warn("Event informer disabled", map[string]interface{}{
+			"namespaces": cfg.Controller.Single.NamespaceScope().String(),
+			"error":     err.Error(),
+		})
+		return
+	}
+	srv.SetEventInformer(events)
+	lc.OnShutdown("event informer", lifecycle.Stop(events.Stop))
+}
+
+// setupConfigInformer starts the ConfigMap and Secret informer linking
+// deployments to their config sources. Without permission to list ConfigMaps
+// or Secrets the server runs without the deployment config endpoint and
+// config_changed events. Deployments annotated with
+// k6s.io/restart-on-config-change=true are restarted when their config changes.
+func setupConfigInformer(srv *server.Server, set *kubernetes.InformerSet, deployments *kubernetes.DeploymentInformer, cfg *config.Config, lc *lifecycle.Coordinator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	configs := kubernetes.NewConfigInformerFor(set)
+	if err := configs.Start(ctx); err != nil {
+		logger.Warn("ConfigMap and Secret informer disabled", map[string]interface{}{
+			"namespaces": cfg.Controller.Single.NamespaceScope().String(),
+			"error":     err.Error(),
+		})
+		return
+	}
+	srv.SetConfigInformer(configs)
+	configs.AddHandler(kubernetes.NewConfigRestarter(set.Clientset(), deployments))
+	lc.OnShutdown("config informer", lifecycle.Stop(configs.Stop))
+}
+
+// setupNodeInformer starts the node informer served at /api/v1/nodes.
+// Without permission to list nodes the server runs without the node
+// endpoints.
+func setupNodeInformer(srv *server.Server, set *kubernetes.InformerSet, cfg *config.Config, lc *lifecycle.Coordinator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	nodes := kubernetes.NewNodeInformerFor(set)
+	if err := nodes.Start(ctx); err != nil {
+		logger.Warn("Node informer disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	srv.SetNodeInformer(nodes)
+	lc.OnShutdown("node informer", lifecycle.Stop(nodes.Stop))
+}
+
+// setupJobInformer starts the Job and CronJob informer served at
+// /api/v1/jobs and /api/v1/cronjobs, logging jobs as they complete or fail.
+// Without permission to list Jobs or CronJobs the server runs without these
+// endpoints.
+func setupJobInformer(srv *server.Server, set *kubernetes.InformerSet, cfg *config.Config, lc *lifecycle.Coordinator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	jobs := kubernetes.NewJobInformerFor(set)
+	jobs.AddHandler(kubernetes.JobEventHandlerFunc(func(event kubernetes.JobEvent) {
+		if event.Type != kubernetes.JobEventFinished {
+			return
+		}
+		logger.Info("Job finished", map[string]interface{}{
+			"namespace":   event.Job.Namespace,
+			"name":        event.Job.Name,
+			"status":      event.Job.Status,
+			"completions": event.Job.Completions,
+			"failed":      event.Job.Failed,
+			"duration":    event.Job.Duration,
+		})
+	}))
+	if err := jobs.Start(ctx); err != nil {
+		logger.Warn("Job informer disabled", map[string]interface{}{
+			"namespaces": cfg.Controller.Single.NamespaceScope().String(),
+			"error":     err.Error(),
+		})
+		return
+	}
+	srv.SetJobInformer(jobs)
+	lc.OnShutdown("job informer", lifecycle.Stop(jobs.Stop))
+}
+
+// setupIngressInformer starts the Ingress and Service informer served at
+// /api/v1/routes. Without permission to list Ingresses or Services the server
+// runs without the routes endpoint.
+func setupIngressInformer(srv *server.Server, set *kubernetes.InformerSet, cfg *config.Config, lc *lifecycle.Coordinator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ingresses := kubernetes.NewIngressInformerFor(set)
+	if err := ingresses.Start(ctx); err != nil {
+		logger.Warn("Ingress informer disabled", map[string]interface{}{
+			"namespaces": cfg.Controller.Single.NamespaceScope().String(),
+			"error":     err.Error(),
+		})
+		return
+	}
+	srv.SetIngressInformer(ingresses)
+	lc.OnShutdown("ingress informer", lifecycle.Stop(ingresses.Stop))
+}
+
+// setupPVCInformer starts the PersistentVolumeClaim informer served at
+// /api/v1/pvcs. Without permission to list claims the server runs without
+// the PVC endpoints.
+func setupPVCInformer(srv *server.Server, set *kubernetes.InformerSet, cfg *config.Config, lc *lifecycle.Coordinator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pvcs := kubernetes.NewPVCInformerFor(set)
+	if err := pvcs.Start(ctx); err != nil {
+		logger.Warn("PersistentVolumeClaim informer disabled", map[string]interface{}{
+			"namespaces": cfg.Controller.Single.NamespaceScope().String(),
+			"error":     err.Error(),
+		})
+		return
+	}
+	srv.SetPVCInformer(pvcs)
+	lc.OnShutdown("pvc informer", lifecycle.Stop(pvcs.Stop))
 }
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/lifecycle"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/server"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// listedResources returns the resources listed through clientset
+func listedResources(clientset *fake.Clientset) map[string]bool {
+	listed := make(map[string]bool)
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "list" {
+			listed[action.GetResource().Resource] = true
+		}
+	}
+	return listed
+}
+
+func TestSetupResourceInformersDisableEnrichment(t *testing.T) {
+	enrichment := []string{"namespaces", "pods", "replicasets", "events", "configmaps", "secrets", "ingresses", "services"}
+
+	for _, disabled := range []bool{false, true} {
+		cfg := config.DefaultConfig()
+		cfg.Server.DisableEnrichment = disabled
+		clientset := fake.NewSimpleClientset()
+		set := kubernetes.NewInformerSetForScope(clientset, cfg.Controller.Single.NamespaceScope(), time.Minute)
+		lc := lifecycle.NewCoordinator(0)
+
+		setupResourceInformers(server.NewWithConfig(cfg.Server), set, kubernetes.NewDeploymentInformerFor(set, cfg), cfg, lc)
+		listed := listedResources(clientset)
+		if err := lc.Shutdown(); err != nil {
+			t.Errorf("Shutdown() error = %v", err)
+		}
+
+		for _, resource := range enrichment {
+			if listed[resource] == disabled {
+				t.Errorf("disable_enrichment=%v: expected %s listed to be %v", disabled, resource, !disabled)
+			}
+		}
+		// The informers of the other endpoints start either way
+		for _, resource := range []string{"nodes", "jobs", "persistentvolumeclaims"} {
+			if !listed[resource] {
+				t.Errorf("disable_enrichment=%v: expected %s to be listed", disabled, resource)
+			}
+		}
+	}
+}
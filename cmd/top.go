@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var (
+	topNamespace     string
+	topAllNamespaces bool
+	topKubeconfig    string
+	topSelector      string
+	topSortBy        string
+	topWatch         bool
+	topInterval      time.Duration
+)
+
+// topCmd represents the top command group
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show the resource usage of pods, nodes and deployments",
+	Long: `Show the cpu and memory used by pods, nodes and deployments, as reported by
+metrics-server through the metrics.k8s.io API, which must be installed in the
+cluster. Usage is joined with the pods, nodes and deployments of informer
+caches: nodes show their usage as a percentage of their allocatable
+resources, and deployments the sum of their pods.
+
+With --watch, the usage is printed again every --interval until interrupted.`,
+}
+
+// topPodsCmd represents the top pods command
+var topPodsCmd = &cobra.Command{
+	Use:     "pods",
+	Aliases: []string{"pod", "po"},
+	Short:   "Show the resource usage of pods",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTop(func(ctx context.Context, client *kubernetes.Client, set *kubernetes.InformerSet) (func(context.Context) error, error) {
+			selector, err := labels.Parse(topSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector: %w", err)
+			}
+			pods := kubernetes.NewPodInformerFor(set)
+			if err := pods.Start(ctx); err != nil {
+				return nil, err
+			}
+			return func(ctx context.Context) error {
+				metrics, err := client.PodMetrics(ctx, topNamespaceArg())
+				if err != nil {
+					return err
+				}
+				usage := kubernetes.TopPods(metrics, pods.ListAllPods(), selector)
+				if err := kubernetes.SortTopUsage(usage, topSortBy); err != nil {
+					return err
+				}
+				kubernetes.TopPodsPrint(usage, topAllNamespaces)
+				return nil
+			}, nil
+		})
+	},
+}
+
+// topNodesCmd represents the top nodes command
+var topNodesCmd = &cobra.Command{
+	Use:     "nodes",
+	Aliases: []string{"node", "no"},
+	Short:   "Show the resource usage of nodes",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTop(func(ctx context.Context, client *kubernetes.Client, set *kubernetes.InformerSet) (func(context.Context) error, error) {
+			nodes := kubernetes.NewNodeInformerFor(set)
+			if err := nodes.Start(ctx); err != nil {
+				return nil, err
+			}
+			return func(ctx context.Context) error {
+				metrics, err := client.NodeMetrics(ctx)
+				if err != nil {
+					return err
+				}
+				usage := kubernetes.TopNodes(metrics, nodes.ListNodes())
+				if err := kubernetes.SortTopUsage(usage, topSortBy); err != nil {
+					return err
+				}
+				kubernetes.TopNodesPrint(usage)
+				return nil
+			}, nil
+		})
+	},
+}
+
+// topDeploymentsCmd represents the top deployments command
+var topDeploymentsCmd = &cobra.Command{
+	Use:     "deployments",
+	Aliases: []string{"deployment", "deploy"},
+	Short:   "Show the resource usage of deployments, summed over their pods",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTop(func(ctx context.Context, client *kubernetes.Client, set *kubernetes.InformerSet) (func(context.Context) error, error) {
+			selector, err := labels.Parse(topSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector: %w", err)
+			}
+			deployments := kubernetes.NewDeploymentInformerFor(set, nil)
+			// Events aren't logged, the cache is only read
+			deployments.ReplaceEventHandlers()
+			if err := deployments.Start(); err != nil {
+				return nil, fmt.Errorf("failed to start deployment informer: %w", err)
+			}
+			pods := kubernetes.NewPodInformerFor(set)
+			if err := pods.Start(ctx); err != nil {
+				return nil, err
+			}
+			return func(ctx context.Context) error {
+				metrics, err := client.PodMetrics(ctx, topNamespaceArg())
+				if err != nil {
+					return err
+				}
+				cached, err := deployments.ListDeployments()
+				if err != nil {
+					return err
+				}
+				usage, err := kubernetes.TopDeployments(metrics, cached, pods.ListAllPods(), selector)
+				if err != nil {
+					return err
+				}
+				if err := kubernetes.SortTopUsage(usage, topSortBy); err != nil {
+					return err
+				}
+				kubernetes.TopDeploymentsPrint(usage, topAllNamespaces)
+				return nil
+			}, nil
+		})
+	},
+}
+
+// topStartFunc starts the informers of a top command and returns the
+// function printing the usage once
+type topStartFunc func(ctx context.Context, client *kubernetes.Client, set *kubernetes.InformerSet) (func(context.Context) error, error)
+
+// runTop prints the usage of a top command once, or every --interval with
+// --watch until interrupted
+func runTop(start topStartFunc) {
+	client, err := kubernetes.NewClient(topKubeconfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	set := kubernetes.NewInformerSet(client.Clientset(), topNamespaceArg(), 0)
+	show, err := start(ctx, client, set)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error starting informers: %v\n", err)
+		os.Exit(1)
+	}
+	if !topWatch {
+		if err := show(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if topInterval <= 0 {
+		fmt.Fprintln(os.Stderr, "--interval must be positive")
+		os.Exit(1)
+	}
+	ticker := time.NewTicker(topInterval)
+	defer ticker.Stop()
+	for {
+		if stdoutIsTerminal() {
+			// Clear the screen so each refresh replaces the last one
+			fmt.Print("\033[H\033[2J")
+		}
+		fmt.Printf("Every %s: %s\n\n", topInterval, time.Now().Format(time.RFC3339))
+		if err := show(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !stdoutIsTerminal() {
+				fmt.Println()
+			}
+		}
+	}
+}
+
+// topNamespaceArg returns the namespace of pods and deployments shown, ""
+// for all namespaces
+func topNamespaceArg() string {
+	if topAllNamespaces {
+		return ""
+	}
+	return topNamespace
+}
+
+// stdoutIsTerminal reports whether stdout is a terminal, which refreshed
+// output can be redrawn on
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+	topCmd.AddCommand(topPodsCmd)
+	topCmd.AddCommand(topNodesCmd)
+	topCmd.AddCommand(topDeploymentsCmd)
+
+	topCmd.PersistentFlags().StringVar(&topKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+	topCmd.PersistentFlags().StringVar(&topSortBy, "sort-by", "", "Sort by cpu or memory, most used first")
+	topCmd.PersistentFlags().BoolVarP(&topWatch, "watch", "w", false, "Print the usage again every --interval")
+	topCmd.PersistentFlags().DurationVar(&topInterval, "interval", 5*time.Second, "Refresh interval with --watch")
+
+	for _, cmd := range []*cobra.Command{topPodsCmd, topDeploymentsCmd} {
+		cmd.Flags().StringVarP(&topNamespace, "namespace", "n", "default", "Kubernetes namespace")
+		cmd.Flags().BoolVarP(&topAllNamespaces, "all-namespaces", "A", false, "Show usage across all namespaces")
+		cmd.Flags().StringVarP(&topSelector, "selector", "l", "", "Only show pods or deployments matching this label selector")
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	uiNamespace  string
+	uiKubeconfig string
+)
+
+// uiCmd represents the ui command
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse deployments and pods in a terminal UI",
+	Long: `Browse the deployments and pods of the cluster in an interactive terminal UI,
+refreshed live from informer caches.
+
+Keys: ↑/↓ (or j/k) select a row, tab switches between deployments and pods,
+n/N switch to the next or previous namespace and a back to all namespaces,
+enter toggles the details pane with the changes the change analyzer found in
+the selected deployment, and q quits.
+
+With --namespace only that namespace is watched; by default all namespaces
+are, and the UI switches between them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := kubernetes.NewClient(uiKubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creating kubernetes client: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		set := kubernetes.NewInformerSet(client.Clientset(), uiNamespace, 30*time.Second)
+		deployments := kubernetes.NewDeploymentInformerFor(set, nil)
+		pods := kubernetes.NewPodInformerFor(set)
+		source := ui.NewInformerSource(deployments, pods)
+
+		if err := deployments.Start(); err != nil {
+			fmt.Fprintf(os.Stderr, "error starting informer: %v\n", err)
+			os.Exit(1)
+		}
+		defer deployments.Stop()
+
+		// Pods are shown once listed; the UI runs without them meanwhile
+		go func() { _ = pods.Start(ctx) }()
+		defer pods.Stop()
+
+		if err := ui.Run(ctx, source, uiNamespace, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+
+	uiCmd.Flags().StringVarP(&uiNamespace, "namespace", "n", "", "Kubernetes namespace to watch (default all namespaces)")
+	uiCmd.Flags().StringVar(&uiKubeconfig, "kubeconfig", "", "Path to kubeconfig file")
+}
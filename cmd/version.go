@@ -1,17 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"runtime"
+	"os"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
 	"github.com/spf13/cobra"
 )
 
-// Build information (set by ldflags)
-var (
-	BuildTime = "unknown"
-	GoVersion = runtime.Version()
-)
+var versionOutput string
 
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
@@ -19,13 +17,29 @@ var versionCmd = &cobra.Command{
 	Short: "show version information",
 	Long:  `Display version information including build details and Go version.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("k6s version %s\n", Version)
-		fmt.Printf("go version: %s\n", GoVersion)
-		fmt.Printf("runtime: %s/%s\n", runtime.GOOS, runtime.GOARCH)
-		fmt.Printf("build time: %s\n", BuildTime)
+		info := version.Get()
+		switch versionOutput {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(info); err != nil {
+				fmt.Fprintf(os.Stderr, "error encoding version: %v\n", err)
+				os.Exit(1)
+			}
+		case "", "text":
+			fmt.Printf("k6s version %s\n", info.Version)
+			fmt.Printf("commit: %s\n", info.Commit)
+			fmt.Printf("build date: %s\n", info.BuildDate)
+			fmt.Printf("go version: %s\n", info.GoVersion)
+			fmt.Printf("platform: %s\n", info.Platform)
+		default:
+			fmt.Fprintf(os.Stderr, "unsupported output format %q (use text or json)\n", versionOutput)
+			os.Exit(1)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().StringVarP(&versionOutput, "output", "o", "text", "output format (text, json)")
 }
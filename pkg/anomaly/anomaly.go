@@ -0,0 +1,98 @@
+// Package anomaly samples the replicas and pod restarts of deployments into
+// the event history and flags restart spikes and unexpected scale-to-zero
+// from their trends.
+package anomaly
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+)
+
+// Anomaly types
+const (
+	// The pods of a deployment restarted far more than usual
+	TypeRestartSpike = "restart-spike"
+
+	// A deployment running replicas within the window was scaled to zero
+	TypeScaleToZero = "scale-to-zero"
+)
+
+// AllowScaleToZeroAnnotation marks deployments expected to scale to zero,
+// such as those scaled by an event-driven autoscaler, when set to "true"
+const AllowScaleToZeroAnnotation = "k6s.io/allow-scale-to-zero"
+
+// Anomaly is an anomaly of a deployment, flagged since Since
+type Anomaly struct {
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+
+	// Restarts in the last interval for a restart spike, replicas before
+	// the deployment was scaled to zero
+	Value int32 `json:"value"`
+
+	// Average restarts per interval over the window of a restart spike
+	Baseline float64 `json:"baseline,omitempty"`
+
+	Since time.Time `json:"since"`
+}
+
+// ParseType returns the anomaly type named by s; empty for an empty s
+func ParseType(s string) (string, error) {
+	switch s {
+	case "", TypeRestartSpike, TypeScaleToZero:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid type %q: must be %s or %s", s, TypeRestartSpike, TypeScaleToZero)
+}
+
+// Detect returns the anomalies of a deployment from its samples within the
+// window, oldest first, the last one being the current sample. Since is not
+// set. Scale-to-zero is not flagged when allowScaleToZero is true.
+func Detect(samples []history.Sample, allowScaleToZero bool, cfg config.AnomalyConfig) []Anomaly {
+	if len(samples) == 0 {
+		return nil
+	}
+	current, previous := samples[len(samples)-1], samples[:len(samples)-1]
+
+	var anomalies []Anomaly
+	if current.Restarts >= int32(cfg.RestartSpikeMin) {
+		baseline := 0.0
+		if len(previous) > 0 {
+			for _, sample := range previous {
+				baseline += float64(sample.Restarts)
+			}
+			baseline /= float64(len(previous))
+		}
+		if float64(current.Restarts) > cfg.RestartSpikeFactor*baseline {
+			anomalies = append(anomalies, Anomaly{
+				Type:      TypeRestartSpike,
+				Namespace: current.Namespace,
+				Name:      current.Name,
+				Message:   fmt.Sprintf("%d container restarts in the last interval, against %.1f on average over %s", current.Restarts, baseline, cfg.Window),
+				Value:     current.Restarts,
+				Baseline:  baseline,
+			})
+		}
+	}
+
+	if current.Replicas == 0 && !allowScaleToZero {
+		for i := len(previous) - 1; i >= 0; i-- {
+			if replicas := previous[i].Replicas; replicas > 0 {
+				anomalies = append(anomalies, Anomaly{
+					Type:      TypeScaleToZero,
+					Namespace: current.Namespace,
+					Name:      current.Name,
+					Message:   fmt.Sprintf("scaled from %d replicas to zero", replicas),
+					Value:     replicas,
+				})
+				break
+			}
+		}
+	}
+	return anomalies
+}
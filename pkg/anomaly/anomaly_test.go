@@ -0,0 +1,85 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+)
+
+// series returns the samples of shop/web with replicas and restarts, a
+// minute apart
+func series(points ...[2]int32) []history.Sample {
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	samples := make([]history.Sample, 0, len(points))
+	for i, point := range points {
+		samples = append(samples, history.Sample{
+			Time:      start.Add(time.Duration(i) * time.Minute),
+			Namespace: "shop",
+			Name:      "web",
+			Replicas:  point[0],
+			Restarts:  point[1],
+		})
+	}
+	return samples
+}
+
+func TestDetect(t *testing.T) {
+	cfg := config.DefaultConfig().Controller.Anomalies
+	tests := []struct {
+		name             string
+		samples          []history.Sample
+		allowScaleToZero bool
+		types            []string
+	}{
+		{"steady", series([2]int32{3, 0}, [2]int32{3, 1}, [2]int32{3, 0}), false, nil},
+		{"restart spike", series([2]int32{3, 1}, [2]int32{3, 0}, [2]int32{3, 6}), false, []string{TypeRestartSpike}},
+		{"spike of a new deployment", series([2]int32{3, 5}), false, []string{TypeRestartSpike}},
+		{"below the minimum", series([2]int32{3, 0}, [2]int32{3, 4}), false, nil},
+		{"within the usual rate", series([2]int32{3, 4}, [2]int32{3, 2}, [2]int32{3, 9}), false, nil},
+		{"scaled to zero", series([2]int32{3, 0}, [2]int32{0, 0}, [2]int32{0, 0}), false, []string{TypeScaleToZero}},
+		{"scale to zero allowed", series([2]int32{3, 0}, [2]int32{0, 0}), true, nil},
+		{"zero over the whole window", series([2]int32{0, 0}, [2]int32{0, 0}), false, nil},
+		{"both", series([2]int32{3, 0}, [2]int32{0, 7}), false, []string{TypeRestartSpike, TypeScaleToZero}},
+	}
+	for _, tt := range tests {
+		anomalies := Detect(tt.samples, tt.allowScaleToZero, cfg)
+		var types []string
+		for _, anomaly := range anomalies {
+			types = append(types, anomaly.Type)
+		}
+		if len(types) != len(tt.types) {
+			t.Errorf("%s: Detect() = %v, want %v", tt.name, types, tt.types)
+			continue
+		}
+		for i := range types {
+			if types[i] != tt.types[i] {
+				t.Errorf("%s: Detect() = %v, want %v", tt.name, types, tt.types)
+				break
+			}
+		}
+	}
+
+	anomalies := Detect(series([2]int32{3, 1}, [2]int32{3, 0}, [2]int32{3, 6}), false, cfg)
+	if anomalies[0].Value != 6 || anomalies[0].Baseline != 0.5 {
+		t.Errorf("Expected 6 restarts against a baseline of 0.5, got %+v", anomalies[0])
+	}
+}
+
+func TestParseTypeAndFilter(t *testing.T) {
+	if _, err := ParseType("outage"); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+	report := Report{Anomalies: []Anomaly{
+		{Type: TypeRestartSpike, Namespace: "shop", Name: "web"},
+		{Type: TypeScaleToZero, Namespace: "shop", Name: "api"},
+		{Type: TypeRestartSpike, Namespace: "ops", Name: "cron"},
+	}}
+	if filtered := Filter(report, "shop", TypeRestartSpike); len(filtered.Anomalies) != 1 || filtered.Anomalies[0].Name != "web" {
+		t.Errorf("Expected the restart spike of shop/web, got %+v", filtered.Anomalies)
+	}
+	if all := Filter(report, "", ""); len(all.Anomalies) != 3 {
+		t.Errorf("Expected every anomaly without filters, got %+v", all.Anomalies)
+	}
+}
@@ -0,0 +1,385 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/notify"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// defaultInterval is used when no interval is configured
+const defaultInterval = time.Minute
+
+// Report lists the anomalies flagged by the last check, sorted by namespace,
+// name and type
+type Report struct {
+	CheckedAt   time.Time `json:"checkedAt"`
+	Deployments int       `json:"deployments"`
+	Anomalies   []Anomaly `json:"anomalies"`
+	Errors      []string  `json:"errors,omitempty"`
+}
+
+// Filter returns the anomalies of a report in namespace of type anomalyType;
+// empty values match every namespace and type
+func Filter(report Report, namespace, anomalyType string) Report {
+	anomalies := []Anomaly{}
+	for _, anomaly := range report.Anomalies {
+		if (namespace == "" || anomaly.Namespace == namespace) && (anomalyType == "" || anomaly.Type == anomalyType) {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	report.Anomalies = anomalies
+	return report
+}
+
+// Detector periodically samples the replicas and pod restarts of the cached
+// deployments into the event history, and alerts on anomalies of their
+// trends: it logs a warning and delivers a restart-spike or scale-to-zero
+// notification
+type Detector struct {
+	deployments *kubernetes.DeploymentInformer
+	store       *history.EventStore
+	log         *logger.Logger
+
+	mu      sync.RWMutex
+	cfg     config.AnomalyConfig
+	report  Report
+	checked bool
+
+	// Pods of deployments are looked up in these caches; nil when pods
+	// can't be cached
+	pods        *kubernetes.PodInformer
+	replicaSets *kubernetes.ReplicaSetInformer
+
+	// Container restarts of each pod at the last check; restarts before the
+	// first check are not counted
+	restarts map[types.UID]int32
+	primed   bool
+
+	// When anomalies were first flagged, keyed by type and namespace/name
+	alarms map[string]time.Time
+
+	// Delivers anomaly notifications, nil to only log
+	notifier *notify.Notifier
+	cluster  string
+}
+
+// NewDetector creates a detector of the deployments of informer, keeping
+// their samples in store
+func NewDetector(deployments *kubernetes.DeploymentInformer, store *history.EventStore, cfg config.AnomalyConfig) *Detector {
+	return &Detector{
+		deployments: deployments,
+		store:       store,
+		cfg:         cfg,
+		restarts:    make(map[types.UID]int32),
+		alarms:      make(map[string]time.Time),
+		log:         logger.WithComponent("anomaly"),
+	}
+}
+
+// SetConfig replaces the interval, window and thresholds, taking effect at
+// the next check
+func (d *Detector) SetConfig(cfg config.AnomalyConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cfg = cfg
+	if !cfg.Enabled {
+		d.checked = false
+		// Restarts while disabled are not counted once enabled again
+		d.primed = false
+	}
+}
+
+// SetPodInformers sets the started caches the pods of deployments are
+// looked up in. It must be called before Start, which otherwise shares the
+// caches of the deployment informer's set.
+func (d *Detector) SetPodInformers(pods *kubernetes.PodInformer, replicaSets *kubernetes.ReplicaSetInformer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pods, d.replicaSets = pods, replicaSets
+}
+
+// SetNotifier delivers a notification, reported for cluster, for each
+// anomaly flagged. It must be called before Start.
+func (d *Detector) SetNotifier(notifier *notify.Notifier, cluster string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.notifier, d.cluster = notifier, cluster
+}
+
+// Enabled reports whether anomaly detection is enabled
+func (d *Detector) Enabled() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.cfg.Enabled
+}
+
+// Report returns the result of the last check, and false before the first
+// one or while detection is disabled
+func (d *Detector) Report() (Report, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.report, d.checked
+}
+
+// Start samples the deployments every interval while detection is enabled,
+// until ctx is cancelled
+func (d *Detector) Start(ctx context.Context) {
+	d.startPodInformers(ctx)
+
+	for {
+		d.mu.RLock()
+		enabled, interval := d.cfg.Enabled, d.cfg.Interval
+		d.mu.RUnlock()
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+
+		if enabled && d.deployments.HasSynced() {
+			d.Check()
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// startPodInformers starts pod and replica set caches sharing the watches of
+// the deployment informer's set, unless they were set, and stops them when
+// ctx is done. Without permission to list them, restarts are not sampled.
+func (d *Detector) startPodInformers(ctx context.Context) {
+	d.mu.RLock()
+	started := d.pods != nil
+	d.mu.RUnlock()
+	if started {
+		return
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	set := d.deployments.InformerSet()
+	pods := kubernetes.NewPodInformerFor(set)
+	if err := pods.Start(syncCtx); err != nil {
+		d.log.Warn("Pod restarts not sampled", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	replicaSets := kubernetes.NewReplicaSetInformerFor(set)
+	if err := replicaSets.Start(syncCtx); err != nil {
+		pods.Stop()
+		d.log.Warn("Pod restarts not sampled", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		replicaSets.Stop()
+		pods.Stop()
+	}()
+	d.SetPodInformers(pods, replicaSets)
+}
+
+// Check samples the cached deployments once, records the samples, flags
+// the anomalies of their trends over the window, alerts on new ones and
+// stores the report
+func (d *Detector) Check() Report {
+	now := time.Now().UTC()
+	report := Report{CheckedAt: now, Anomalies: []Anomaly{}}
+	deployments, err := d.deployments.ListDeployments()
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("listing deployments: %v", err))
+	}
+	report.Deployments = len(deployments)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	samples, errs := d.sample(deployments, now)
+	report.Errors = append(report.Errors, errs...)
+	if err := d.store.RecordSamples(samples); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("recording samples: %v", err))
+	}
+
+	// The current samples end each series, even when they couldn't be
+	// recorded
+	recorded, err := d.store.Samples(history.SampleQuery{Since: now.Add(-d.cfg.Window)})
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("reading samples: %v", err))
+	}
+	series := make(map[string][]history.Sample)
+	for _, sample := range recorded {
+		if sample.Time.Before(now) {
+			key := sample.Namespace + "/" + sample.Name
+			series[key] = append(series[key], sample)
+		}
+	}
+
+	live := make(map[string]*appsv1.Deployment, len(deployments))
+	for i, dep := range deployments {
+		key := dep.Namespace + "/" + dep.Name
+		live[key] = dep
+		allowed := dep.Annotations[AllowScaleToZeroAnnotation] == "true"
+		report.Anomalies = append(report.Anomalies, Detect(append(series[key], samples[i]), allowed, d.cfg)...)
+	}
+	sort.SliceStable(report.Anomalies, func(i, j int) bool {
+		a, b := report.Anomalies[i], report.Anomalies[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Type < b.Type
+	})
+
+	d.raiseAlarms(report.Anomalies, live, now)
+	d.report = report
+	d.checked = true
+	return report
+}
+
+// sample returns the current sample of each deployment, in order, with the
+// container restarts of its pods since the previous check. The caller must
+// hold d.mu.
+func (d *Detector) sample(deployments []*appsv1.Deployment, now time.Time) ([]history.Sample, []string) {
+	var errs []string
+	podsByNamespace := make(map[string][]*corev1.Pod)
+	restarts := make(map[types.UID]int32)
+	samples := make([]history.Sample, 0, len(deployments))
+	for _, dep := range deployments {
+		sample := history.Sample{
+			Time:      now,
+			Namespace: dep.Namespace,
+			Name:      dep.Name,
+			Replicas:  1,
+			Available: dep.Status.AvailableReplicas,
+		}
+		if dep.Spec.Replicas != nil {
+			sample.Replicas = *dep.Spec.Replicas
+		}
+
+		pods, err := d.deploymentPods(dep, podsByNamespace)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("deployment %s/%s: %v", dep.Namespace, dep.Name, err))
+		}
+		for _, pod := range pods {
+			count := restartCount(pod)
+			restarts[pod.UID] = count
+			previous, seen := d.restarts[pod.UID]
+			switch {
+			case !d.primed:
+			case !seen || count < previous:
+				sample.Restarts += count
+			default:
+				sample.Restarts += count - previous
+			}
+		}
+		samples = append(samples, sample)
+	}
+	d.restarts = restarts
+	d.primed = d.pods != nil
+	return samples, errs
+}
+
+// deploymentPods returns the cached pods of the replica sets of a
+// deployment, nil when pods are not cached. Pods are listed once per
+// namespace into podsByNamespace. The caller must hold d.mu.
+func (d *Detector) deploymentPods(dep *appsv1.Deployment, podsByNamespace map[string][]*corev1.Pod) ([]*corev1.Pod, error) {
+	if d.pods == nil || d.replicaSets == nil {
+		return nil, nil
+	}
+	replicaSets, err := d.replicaSets.ReplicaSets(dep)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[string]bool, len(replicaSets))
+	for _, rs := range replicaSets {
+		owned[rs.Name] = true
+	}
+
+	pods, listed := podsByNamespace[dep.Namespace]
+	if !listed {
+		if pods, err = d.pods.ListPods(dep.Namespace); err != nil {
+			return nil, err
+		}
+		podsByNamespace[dep.Namespace] = pods
+	}
+	var selected []*corev1.Pod
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "ReplicaSet" && owned[owner.Name] {
+			selected = append(selected, pod)
+		}
+	}
+	return selected, nil
+}
+
+// raiseAlarms warns of anomalies flagged since the previous check and logs
+// those cleared, setting the time anomalies were first flagged. The caller
+// must hold d.mu.
+func (d *Detector) raiseAlarms(anomalies []Anomaly, live map[string]*appsv1.Deployment, now time.Time) {
+	current := make(map[string]time.Time, len(anomalies))
+	for i := range anomalies {
+		anomaly := &anomalies[i]
+		key := anomaly.Type + "/" + anomaly.Namespace + "/" + anomaly.Name
+		if since, flagged := d.alarms[key]; flagged {
+			anomaly.Since = since
+			current[key] = since
+			continue
+		}
+		anomaly.Since = now
+		current[key] = now
+
+		d.log.Warn("Deployment anomaly detected", map[string]interface{}{
+			"namespace":  anomaly.Namespace,
+			"deployment": anomaly.Name,
+			"type":       anomaly.Type,
+			"message":    anomaly.Message,
+		})
+		if d.notifier != nil {
+			d.notifier.Notify(notify.Event{
+				Type:      anomaly.Type,
+				Source:    notify.SourceAnomaly,
+				Cluster:   d.cluster,
+				Namespace: anomaly.Namespace,
+				Name:      anomaly.Name,
+				Labels:    live[anomaly.Namespace+"/"+anomaly.Name].Labels,
+				Message:   anomaly.Message,
+			})
+		}
+	}
+
+	for key := range d.alarms {
+		if _, flagged := current[key]; !flagged {
+			d.log.Info("Deployment anomaly cleared", map[string]interface{}{
+				"anomaly": key,
+			})
+		}
+	}
+	d.alarms = current
+}
+
+// restartCount returns the container restarts of a pod
+func restartCount(pod *corev1.Pod) int32 {
+	var count int32
+	for _, status := range pod.Status.InitContainerStatuses {
+		count += status.RestartCount
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		count += status.RestartCount
+	}
+	return count
+}
@@ -0,0 +1,115 @@
+package anomaly
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// waitFor polls condition until it holds, failing the test after a few
+// seconds
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !condition(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestDetectorCheck(t *testing.T) {
+	replicas := int32(2)
+	controller := true
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", UID: types.UID("web-uid")},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "shop",
+		Name:            "web-1",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: web.UID, Controller: &controller}},
+	}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "shop",
+			Name:            "web-1-a",
+			UID:             types.UID("pod-uid"),
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-1", Controller: &controller}},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: 3}}},
+	}
+	clientset := fake.NewSimpleClientset(web, rs, pod)
+
+	store, err := history.OpenEventStore(filepath.Join(t.TempDir(), "events.db"), time.Hour)
+	if err != nil {
+		t.Fatalf("OpenEventStore() error = %v", err)
+	}
+	defer store.Close()
+	deployments := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	cfg := config.DefaultConfig().Controller.Anomalies
+	cfg.Enabled = true
+	detector := NewDetector(deployments, store, cfg)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	detector.startPodInformers(ctx)
+
+	// Restarts before the first check are not counted
+	if report := detector.Check(); len(report.Anomalies) != 0 || report.Deployments != 1 {
+		t.Fatalf("Expected no anomalies at the first check, got %+v", report)
+	}
+
+	pod.Status.ContainerStatuses[0].RestartCount = 9
+	if _, err := clientset.CoreV1().Pods("shop").UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the pod restarts", func() bool {
+		pods, _ := detector.pods.ListPods("shop")
+		return len(pods) == 1 && restartCount(pods[0]) == 9
+	})
+	report := detector.Check()
+	if len(report.Anomalies) != 1 || report.Anomalies[0].Type != TypeRestartSpike || report.Anomalies[0].Value != 6 {
+		t.Fatalf("Expected a spike of 6 restarts, got %+v", report.Anomalies)
+	}
+	since := report.Anomalies[0].Since
+
+	zero := int32(0)
+	web.Spec.Replicas = &zero
+	if _, err := clientset.AppsV1().Deployments("shop").Update(ctx, web, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the deployment to scale", func() bool {
+		dep, err := deployments.GetDeployment("shop", "web")
+		return err == nil && *dep.Spec.Replicas == 0
+	})
+	report = detector.Check()
+	if len(report.Anomalies) != 1 || report.Anomalies[0].Type != TypeScaleToZero || report.Anomalies[0].Value != 2 {
+		t.Fatalf("Expected web scaled from 2 replicas to zero, with the spike cleared, got %+v", report.Anomalies)
+	}
+	if !report.Anomalies[0].Since.After(since) {
+		t.Errorf("Expected the scale-to-zero to be flagged at this check, got %v", report.Anomalies[0].Since)
+	}
+
+	samples, err := store.Samples(history.SampleQuery{Namespace: "shop", Name: "web"})
+	if err != nil || len(samples) != 3 || samples[1].Restarts != 6 || samples[2].Replicas != 0 {
+		t.Errorf("Expected the 3 samples of web to be recorded, got %+v, %v", samples, err)
+	}
+
+	detector.SetConfig(config.AnomalyConfig{})
+	if _, checked := detector.Report(); checked {
+		t.Error("Expected no report once detection is disabled")
+	}
+}
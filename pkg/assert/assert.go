@@ -0,0 +1,287 @@
+// Package assert evaluates declarative deployment assertions, used by
+// "k6s assert" to check cluster state in CI pipelines.
+package assert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultInterval is the time between evaluation rounds while waiting for assertions to pass
+const DefaultInterval = 5 * time.Second
+
+// File is a set of assertions loaded from YAML
+type File struct {
+	// How long to wait for all assertions to pass (0 = evaluate once)
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Time between evaluation rounds
+	Interval time.Duration `yaml:"interval"`
+
+	Assertions []Assertion `yaml:"assertions"`
+}
+
+// Assertion declares the expected state of a deployment in one or more clusters
+type Assertion struct {
+	Name       string `yaml:"name"`
+	Deployment string `yaml:"deployment"`
+	Namespace  string `yaml:"namespace"`
+
+	// Clusters to check (empty = the current cluster)
+	Clusters []string `yaml:"clusters"`
+
+	Expect Expectation `yaml:"expect"`
+}
+
+// Expectation lists the conditions a deployment must meet. Replica counts
+// are comparisons such as "3", ">=3", "<5" or "!=0".
+type Expectation struct {
+	// Whether the deployment exists (default true)
+	Exists *bool `yaml:"exists"`
+
+	Replicas          string `yaml:"replicas"`
+	ReadyReplicas     string `yaml:"ready_replicas"`
+	AvailableReplicas string `yaml:"available_replicas"`
+
+	// Every container image starts with this prefix
+	ImagePrefix string `yaml:"image_prefix"`
+
+	// Labels the deployment must have
+	Labels map[string]string `yaml:"labels"`
+}
+
+// State is the observed state of a deployment
+type State struct {
+	Exists    bool
+	Replicas  int32
+	Ready     int32
+	Available int32
+	Images    []string
+	Labels    map[string]string
+}
+
+// Source returns the state of a deployment in a cluster ("" = the current cluster)
+type Source interface {
+	Deployment(ctx context.Context, cluster, namespace, name string) (State, error)
+}
+
+// Result is the outcome of an assertion in a single cluster
+type Result struct {
+	Assertion  string   `json:"assertion"`
+	Cluster    string   `json:"cluster,omitempty"`
+	Namespace  string   `json:"namespace"`
+	Deployment string   `json:"deployment"`
+	Passed     bool     `json:"passed"`
+	Failures   []string `json:"failures,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Load reads and validates an assertions file
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is provided by the user running the command
+	if err != nil {
+		return nil, fmt.Errorf("failed to read assertions file %s: %w", path, err)
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates assertions. Unknown keys are rejected so that a
+// misspelt expectation can't silently pass.
+func Parse(data []byte) (*File, error) {
+	var file File
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse assertions: %w", err)
+	}
+
+	if len(file.Assertions) == 0 {
+		return nil, fmt.Errorf("no assertions defined")
+	}
+	if file.Interval <= 0 {
+		file.Interval = DefaultInterval
+	}
+
+	for i := range file.Assertions {
+		a := &file.Assertions[i]
+		if a.Deployment == "" {
+			return nil, fmt.Errorf("assertion %d: deployment is required", i)
+		}
+		if a.Namespace == "" {
+			a.Namespace = "default"
+		}
+		if a.Name == "" {
+			a.Name = a.Namespace + "/" + a.Deployment
+		}
+		for field, expr := range map[string]string{
+			"replicas":           a.Expect.Replicas,
+			"ready_replicas":     a.Expect.ReadyReplicas,
+			"available_replicas": a.Expect.AvailableReplicas,
+		} {
+			if expr == "" {
+				continue
+			}
+			if _, _, err := parseComparison(expr); err != nil {
+				return nil, fmt.Errorf("assertion %s: invalid %s: %w", a.Name, field, err)
+			}
+		}
+	}
+
+	return &file, nil
+}
+
+// Run evaluates the assertions until they all pass or the timeout expires,
+// returning the results of the last round
+func (f *File) Run(ctx context.Context, source Source) []Result {
+	deadline := time.Now().Add(f.Timeout)
+
+	for {
+		results := f.Evaluate(ctx, source)
+		if Passed(results) || !time.Now().Add(f.Interval).Before(deadline) {
+			return results
+		}
+
+		select {
+		case <-ctx.Done():
+			return results
+		case <-time.After(f.Interval):
+		}
+	}
+}
+
+// Evaluate evaluates every assertion once
+func (f *File) Evaluate(ctx context.Context, source Source) []Result {
+	var results []Result
+	for _, a := range f.Assertions {
+		clusters := a.Clusters
+		if len(clusters) == 0 {
+			clusters = []string{""}
+		}
+
+		for _, cluster := range clusters {
+			result := Result{
+				Assertion:  a.Name,
+				Cluster:    cluster,
+				Namespace:  a.Namespace,
+				Deployment: a.Deployment,
+			}
+
+			state, err := source.Deployment(ctx, cluster, a.Namespace, a.Deployment)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Failures = a.Expect.Check(state)
+				result.Passed = len(result.Failures) == 0
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// Passed reports whether every result passed
+func Passed(results []Result) bool {
+	for _, r := range results {
+		if !r.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns a description of every unmet expectation
+func (e Expectation) Check(state State) []string {
+	wantExists := e.Exists == nil || *e.Exists
+	if !state.Exists {
+		if wantExists {
+			return []string{"deployment does not exist"}
+		}
+		return nil
+	}
+	if !wantExists {
+		return []string{"deployment exists"}
+	}
+
+	var failures []string
+	for _, c := range []struct {
+		field  string
+		expr   string
+		actual int32
+	}{
+		{"replicas", e.Replicas, state.Replicas},
+		{"ready_replicas", e.ReadyReplicas, state.Ready},
+		{"available_replicas", e.AvailableReplicas, state.Available},
+	} {
+		if c.expr == "" {
+			continue
+		}
+		if !compare(c.expr, int64(c.actual)) {
+			failures = append(failures, fmt.Sprintf("%s is %d, expected %s", c.field, c.actual, c.expr))
+		}
+	}
+
+	if e.ImagePrefix != "" {
+		for _, image := range state.Images {
+			if !strings.HasPrefix(image, e.ImagePrefix) {
+				failures = append(failures, fmt.Sprintf("image %s does not start with %s", image, e.ImagePrefix))
+			}
+		}
+	}
+
+	for key, want := range e.Labels {
+		if got, ok := state.Labels[key]; !ok || got != want {
+			failures = append(failures, fmt.Sprintf("label %s is %q, expected %q", key, got, want))
+		}
+	}
+
+	return failures
+}
+
+// comparisonOps are checked longest first so ">=" isn't read as ">"
+var comparisonOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// parseComparison parses expressions like ">=3"; a bare number means "=="
+func parseComparison(expr string) (string, int64, error) {
+	expr = strings.TrimSpace(expr)
+	op := "=="
+	for _, candidate := range comparisonOps {
+		if strings.HasPrefix(expr, candidate) {
+			op = candidate
+			expr = strings.TrimSpace(strings.TrimPrefix(expr, candidate))
+			break
+		}
+	}
+
+	value, err := strconv.ParseInt(expr, 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("expected a comparison like \">=3\", got %q", expr)
+	}
+	return op, value, nil
+}
+
+// compare evaluates a validated comparison against a value
+func compare(expr string, actual int64) bool {
+	op, want, err := parseComparison(expr)
+	if err != nil {
+		return false
+	}
+
+	switch op {
+	case ">=":
+		return actual >= want
+	case "<=":
+		return actual <= want
+	case ">":
+		return actual > want
+	case "<":
+		return actual < want
+	case "!=":
+		return actual != want
+	default:
+		return actual == want
+	}
+}
@@ -0,0 +1,147 @@
+package assert
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func newDeployment(name string, replicas, ready int32, image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod", Labels: map[string]string{"app": name}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(replicas),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: image}}},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: ready, AvailableReplicas: ready},
+	}
+}
+
+const testAssertions = `
+assertions:
+  - name: web is scaled
+    deployment: web
+    namespace: prod
+    clusters: [prod-us, prod-eu]
+    expect:
+      replicas: ">=3"
+      ready_replicas: ">=3"
+      image_prefix: "registry.example/web:"
+      labels:
+        app: web
+  - deployment: legacy
+    namespace: prod
+    expect:
+      exists: false
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse([]byte(testAssertions))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if file.Interval != DefaultInterval {
+		t.Errorf("Expected default interval, got %v", file.Interval)
+	}
+	if file.Assertions[1].Name != "prod/legacy" {
+		t.Errorf("Expected default name prod/legacy, got %s", file.Assertions[1].Name)
+	}
+
+	invalid := []string{
+		"assertions: []",
+		"assertions:\n  - namespace: prod",
+		"assertions:\n  - deployment: web\n    expect:\n      replicas: lots",
+		"assertions:\n  - deployment: web\n    expect:\n      replica: 3",
+	}
+	for _, data := range invalid {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Expected error for %q", data)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		expect   Expectation
+		state    State
+		failures int
+	}{
+		{"bare number", Expectation{Replicas: "3"}, State{Exists: true, Replicas: 3}, 0},
+		{"at least", Expectation{Replicas: ">=3"}, State{Exists: true, Replicas: 2}, 1},
+		{"not equal", Expectation{ReadyReplicas: "!=0"}, State{Exists: true}, 1},
+		{"image prefix", Expectation{ImagePrefix: "registry/"}, State{Exists: true, Images: []string{"registry/a", "docker.io/b"}}, 1},
+		{"missing label", Expectation{Labels: map[string]string{"tier": "web"}}, State{Exists: true}, 1},
+		{"missing deployment", Expectation{}, State{}, 1},
+		{"expected absent", Expectation{Exists: new(bool)}, State{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if failures := tt.expect.Check(tt.state); len(failures) != tt.failures {
+				t.Errorf("Check() = %v, want %d failures", failures, tt.failures)
+			}
+		})
+	}
+}
+
+func TestRunAndReport(t *testing.T) {
+	clusters := map[string]kubernetes.Interface{
+		"prod-us": fake.NewSimpleClientset(newDeployment("web", 3, 3, "registry.example/web:1.2")),
+		"prod-eu": fake.NewSimpleClientset(newDeployment("web", 3, 1, "registry.example/web:1.2")),
+		"":        fake.NewSimpleClientset(),
+	}
+	source := NewLiveSource(func(cluster string) (kubernetes.Interface, error) {
+		return clusters[cluster], nil
+	})
+
+	file, err := Parse([]byte(testAssertions))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	file.Timeout = 50 * time.Millisecond
+	file.Interval = 10 * time.Millisecond
+
+	results := file.Run(context.Background(), source)
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if Passed(results) {
+		t.Fatal("Expected prod-eu to fail")
+	}
+	if !results[0].Passed || results[1].Passed || !results[2].Passed {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	var text bytes.Buffer
+	if err := WriteReport(&text, FormatText, results); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	if !strings.Contains(text.String(), "FAIL  web is scaled [prod-eu]: ready_replicas is 1, expected >=3") {
+		t.Errorf("Unexpected text report:\n%s", text.String())
+	}
+
+	var junit bytes.Buffer
+	if err := WriteReport(&junit, FormatJUnit, results); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	var suite junitTestSuite
+	if err := xml.Unmarshal(junit.Bytes(), &suite); err != nil {
+		t.Fatalf("Invalid JUnit XML: %v", err)
+	}
+	if suite.Tests != 3 || suite.Failures != 1 {
+		t.Errorf("Expected 3 tests with 1 failure, got %d/%d", suite.Tests, suite.Failures)
+	}
+}
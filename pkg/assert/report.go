@@ -0,0 +1,134 @@
+package assert
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report formats
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// WriteReport writes results in the given format
+func WriteReport(w io.Writer, format string, results []Result) error {
+	switch format {
+	case "", FormatText:
+		return writeText(w, results)
+	case FormatJSON:
+		return writeJSON(w, results)
+	case FormatJUnit:
+		return writeJUnit(w, results)
+	default:
+		return fmt.Errorf("unknown report format %q (valid formats: text, json, junit)", format)
+	}
+}
+
+// writeText writes one line per result and a summary
+func writeText(w io.Writer, results []Result) error {
+	failed := 0
+	for _, r := range results {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+			failed++
+		}
+
+		line := fmt.Sprintf("%s  %s", status, r.Assertion)
+		if r.Cluster != "" {
+			line += fmt.Sprintf(" [%s]", r.Cluster)
+		}
+		if msg := r.message(); msg != "" {
+			line += ": " + msg
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d passed, %d failed\n", len(results)-failed, failed)
+	return err
+}
+
+// writeJSON writes the results with a summary as a JSON document
+func writeJSON(w io.Writer, results []Result) error {
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{
+		"passed":  len(results) - failed,
+		"failed":  failed,
+		"results": results,
+	})
+}
+
+// junitTestSuite is the JUnit XML document understood by CI systems
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnit writes the results as a JUnit test suite, one case per assertion and cluster
+func writeJUnit(w io.Writer, results []Result) error {
+	suite := junitTestSuite{Name: "k6s assert", Tests: len(results)}
+	for _, r := range results {
+		tc := junitTestCase{Name: r.Assertion, ClassName: "k6s"}
+		if r.Cluster != "" {
+			tc.ClassName = "k6s." + r.Cluster
+		}
+		switch {
+		case r.Error != "":
+			suite.Errors++
+			tc.Error = &junitMessage{Message: r.Error}
+		case !r.Passed:
+			suite.Failures++
+			tc.Failure = &junitMessage{Message: r.message()}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// message describes why a result failed
+func (r Result) message() string {
+	if r.Error != "" {
+		return "error: " + r.Error
+	}
+	return strings.Join(r.Failures, "; ")
+}
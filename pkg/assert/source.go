@@ -0,0 +1,128 @@
+package assert
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/remote"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LiveSource reads deployments from the Kubernetes API
+type LiveSource struct {
+	connect func(cluster string) (kubernetes.Interface, error)
+
+	mu      sync.Mutex
+	clients map[string]kubernetes.Interface
+}
+
+// NewLiveSource creates a source that connects to clusters on first use
+func NewLiveSource(connect func(cluster string) (kubernetes.Interface, error)) *LiveSource {
+	return &LiveSource{
+		connect: connect,
+		clients: make(map[string]kubernetes.Interface),
+	}
+}
+
+// Deployment implements Source
+func (s *LiveSource) Deployment(ctx context.Context, cluster, namespace, name string) (State, error) {
+	client, err := s.client(cluster)
+	if err != nil {
+		return State{}, err
+	}
+
+	dep, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	return stateFromDeployment(dep), nil
+}
+
+// client returns the cached client for a cluster
+func (s *LiveSource) client(cluster string) (kubernetes.Interface, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client, ok := s.clients[cluster]; ok {
+		return client, nil
+	}
+	client, err := s.connect(cluster)
+	if err != nil {
+		return nil, err
+	}
+	s.clients[cluster] = client
+	return client, nil
+}
+
+// stateFromDeployment converts a deployment into its observed state
+func stateFromDeployment(dep *appsv1.Deployment) State {
+	state := State{
+		Exists:    true,
+		Replicas:  1,
+		Ready:     dep.Status.ReadyReplicas,
+		Available: dep.Status.AvailableReplicas,
+		Labels:    dep.Labels,
+	}
+	if dep.Spec.Replicas != nil {
+		state.Replicas = *dep.Spec.Replicas
+	}
+	for _, c := range dep.Spec.Template.Spec.Containers {
+		state.Images = append(state.Images, c.Image)
+	}
+	return state
+}
+
+// RemoteSource reads deployments from the informer cache of a k6s server
+type RemoteSource struct {
+	connect func(cluster string) (*remote.Client, error)
+}
+
+// NewRemoteSource creates a source that queries a k6s server per cluster
+func NewRemoteSource(connect func(cluster string) (*remote.Client, error)) *RemoteSource {
+	return &RemoteSource{connect: connect}
+}
+
+// Deployment implements Source. The server only reports the first container
+// image, so image_prefix checks that image.
+func (s *RemoteSource) Deployment(ctx context.Context, cluster, namespace, name string) (State, error) {
+	client, err := s.connect(cluster)
+	if err != nil {
+		return State{}, err
+	}
+
+	dep, err := client.GetDeployment(ctx, namespace, name)
+	var apiErr *remote.APIError
+	if errors.As(err, &apiErr) && isDeploymentNotFound(apiErr) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+
+	state := State{
+		Exists:    true,
+		Replicas:  dep.Replicas,
+		Ready:     dep.Ready,
+		Available: dep.Available,
+		Labels:    dep.Labels,
+	}
+	if dep.Image != "" {
+		state.Images = []string{dep.Image}
+	}
+	return state, nil
+}
+
+// isDeploymentNotFound tells a missing deployment apart from a cluster the
+// server doesn't serve, which also returns 404
+func isDeploymentNotFound(err *remote.APIError) bool {
+	return err.StatusCode == http.StatusNotFound && strings.HasPrefix(err.Message, "Deployment ")
+}
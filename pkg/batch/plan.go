@@ -0,0 +1,134 @@
+// Package batch previews mutating operations over many deployments, so that
+// they can be reviewed and approved before they are applied.
+package batch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// Change describes how an operation changes one field of a deployment.
+// ResourceVersion is the version of the deployment the change was planned
+// against, so that applying it can fail when the deployment changed since.
+type Change struct {
+	Cluster         string `json:"cluster,omitempty"`
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	Field           string `json:"field"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// Plan previews a batch operation: the changes it makes and how many selected
+// deployments are already in the desired state
+type Plan struct {
+	Operation string   `json:"operation"`
+	Changes   []Change `json:"changes"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Scale plans setting the replica count of deployments in cluster
+func Scale(cluster string, deployments []*appsv1.Deployment, replicas int32) Plan {
+	plan := Plan{Operation: "scale", Changes: []Change{}}
+	to := strconv.Itoa(int(replicas))
+	for _, dep := range deployments {
+		// An unset replica count defaults to 1
+		from := "1"
+		if dep.Spec.Replicas != nil {
+			from = strconv.Itoa(int(*dep.Spec.Replicas))
+		}
+		if from == to {
+			plan.Unchanged++
+			continue
+		}
+		plan.Changes = append(plan.Changes, Change{
+			Cluster:         cluster,
+			Namespace:       dep.Namespace,
+			Name:            dep.Name,
+			Field:           "spec.replicas",
+			From:            from,
+			To:              to,
+			ResourceVersion: dep.ResourceVersion,
+		})
+	}
+	sortChanges(plan.Changes)
+	return plan
+}
+
+// Empty reports whether applying the plan would change nothing
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Clusters returns the clusters the plan changes, sorted
+func (p Plan) Clusters() []string {
+	seen := make(map[string]bool)
+	var clusters []string
+	for _, change := range p.Changes {
+		if change.Cluster != "" && !seen[change.Cluster] {
+			seen[change.Cluster] = true
+			clusters = append(clusters, change.Cluster)
+		}
+	}
+	sort.Strings(clusters)
+	return clusters
+}
+
+// Render writes the plan as a unified diff per deployment, preceded by a summary
+func (p Plan) Render(w io.Writer) {
+	summary := fmt.Sprintf("Plan: %s %d deployment(s)", p.Operation, len(p.Changes))
+	if clusters := p.Clusters(); len(clusters) > 0 {
+		summary += fmt.Sprintf(" in cluster(s) %s", strings.Join(clusters, ", "))
+	}
+	if p.Unchanged > 0 {
+		summary += fmt.Sprintf(", %d already up to date", p.Unchanged)
+	}
+	fmt.Fprintln(w, summary)
+
+	for _, change := range p.Changes {
+		object := change.Namespace + "/" + change.Name
+		if change.Cluster != "" {
+			object = change.Cluster + "/" + object
+		}
+		fmt.Fprintf(w, "\n--- %s\n+++ %s\n@@ %s @@\n-%s\n+%s\n", object, object, change.Field, change.From, change.To)
+	}
+}
+
+// Confirm renders the plan to out and asks for approval on in. Only "y" or
+// "yes" approve; anything else, including end of input, declines.
+func Confirm(p Plan, in io.Reader, out io.Writer) (bool, error) {
+	p.Render(out)
+	fmt.Fprint(out, "\nApply these changes? [y/N]: ")
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// sortChanges orders changes by cluster, namespace and name
+func sortChanges(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
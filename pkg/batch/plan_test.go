@@ -0,0 +1,85 @@
+package batch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func deployment(namespace, name string, replicas *int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       appsv1.DeploymentSpec{Replicas: replicas},
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestScale(t *testing.T) {
+	worker := deployment("default", "worker", nil)
+	worker.ResourceVersion = "42"
+	plan := Scale("prod-eu", []*appsv1.Deployment{
+		deployment("prod", "web", int32Ptr(2)),
+		deployment("prod", "api", int32Ptr(3)),
+		worker,
+	}, 3)
+
+	if plan.Unchanged != 1 || len(plan.Changes) != 2 {
+		t.Fatalf("Expected 2 changes and 1 unchanged, got %+v", plan)
+	}
+	// Changes are sorted, and an unset replica count is shown as its default
+	if first := plan.Changes[0]; first.Name != "worker" || first.From != "1" || first.To != "3" || first.ResourceVersion != "42" {
+		t.Errorf("Unexpected first change %+v", first)
+	}
+	if clusters := plan.Clusters(); len(clusters) != 1 || clusters[0] != "prod-eu" {
+		t.Errorf("Expected cluster prod-eu, got %v", clusters)
+	}
+
+	var out bytes.Buffer
+	plan.Render(&out)
+	for _, want := range []string{
+		"Plan: scale 2 deployment(s) in cluster(s) prod-eu, 1 already up to date",
+		"--- prod-eu/prod/web\n+++ prod-eu/prod/web\n@@ spec.replicas @@\n-2\n+3\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected rendered plan to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	if !Scale("", nil, 1).Empty() {
+		t.Error("Expected a plan without deployments to be empty")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	plan := Scale("", []*appsv1.Deployment{deployment("prod", "web", int32Ptr(2))}, 3)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		got, err := Confirm(plan, strings.NewReader(tt.input), &out)
+		if err != nil {
+			t.Fatalf("Confirm(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("Confirm(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if !strings.Contains(out.String(), "Apply these changes? [y/N]") {
+			t.Errorf("Expected a prompt, got %q", out.String())
+		}
+	}
+}
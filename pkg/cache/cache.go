@@ -6,10 +6,10 @@ import (
 	"time"
 )
 
-// Cache represents a generic cache interface
-type Cache interface {
-	Get(key string) (interface{}, bool)
-	Set(key string, value interface{}, ttl time.Duration)
+// Cache represents a cache of values of type T
+type Cache[T any] interface {
+	Get(key string) (T, bool)
+	Set(key string, value T, ttl time.Duration)
 	Delete(key string)
 	Clear()
 	Size() int
@@ -17,20 +17,20 @@ type Cache interface {
 }
 
 // MemoryCache implements an in-memory cache with TTL support
-type MemoryCache struct {
+type MemoryCache[T any] struct {
 	mu    sync.RWMutex
-	items map[string]*cacheItem
+	items map[string]*cacheItem[T]
 }
 
-type cacheItem struct {
-	value      interface{}
+type cacheItem[T any] struct {
+	value      T
 	expiration int64
 }
 
 // NewMemoryCache creates a new in-memory cache
-func NewMemoryCache() *MemoryCache {
-	cache := &MemoryCache{
-		items: make(map[string]*cacheItem),
+func NewMemoryCache[T any]() *MemoryCache[T] {
+	cache := &MemoryCache[T]{
+		items: make(map[string]*cacheItem[T]),
 	}
 	
 	// Start cleanup goroutine
@@ -40,25 +40,26 @@ func NewMemoryCache() *MemoryCache {
 }
 
 // Get retrieves a value from the cache
-func (c *MemoryCache) Get(key string) (interface{}, bool) {
+func (c *MemoryCache[T]) Get(key string) (T, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	
+	var zero T
 	item, exists := c.items[key]
 	if !exists {
-		return nil, false
+		return zero, false
 	}
 	
 	// Check if item has expired
 	if item.expiration > 0 && time.Now().UnixNano() > item.expiration {
-		return nil, false
+		return zero, false
 	}
 	
 	return item.value, true
 }
 
 // Set stores a value in the cache with TTL
-func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+func (c *MemoryCache[T]) Set(key string, value T, ttl time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
@@ -67,14 +68,14 @@ func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
 		expiration = time.Now().Add(ttl).UnixNano()
 	}
 	
-	c.items[key] = &cacheItem{
+	c.items[key] = &cacheItem[T]{
 		value:      value,
 		expiration: expiration,
 	}
 }
 
 // Delete removes a value from the cache
-func (c *MemoryCache) Delete(key string) {
+func (c *MemoryCache[T]) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
@@ -82,15 +83,15 @@ func (c *MemoryCache) Delete(key string) {
 }
 
 // Clear removes all items from the cache
-func (c *MemoryCache) Clear() {
+func (c *MemoryCache[T]) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	c.items = make(map[string]*cacheItem)
+	c.items = make(map[string]*cacheItem[T])
 }
 
 // Size returns the number of items in the cache
-func (c *MemoryCache) Size() int {
+func (c *MemoryCache[T]) Size() int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	
@@ -98,7 +99,7 @@ func (c *MemoryCache) Size() int {
 }
 
 // Keys returns all keys in the cache
-func (c *MemoryCache) Keys() []string {
+func (c *MemoryCache[T]) Keys() []string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	
@@ -111,7 +112,7 @@ func (c *MemoryCache) Keys() []string {
 }
 
 // cleanup removes expired items from the cache
-func (c *MemoryCache) cleanup() {
+func (c *MemoryCache[T]) cleanup() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 	
@@ -121,7 +122,7 @@ func (c *MemoryCache) cleanup() {
 }
 
 // removeExpired removes expired items from the cache
-func (c *MemoryCache) removeExpired() {
+func (c *MemoryCache[T]) removeExpired() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
@@ -134,31 +135,31 @@ func (c *MemoryCache) removeExpired() {
 }
 
 // LRUCache implements a Least Recently Used cache
-type LRUCache struct {
+type LRUCache[T any] struct {
 	mu       sync.RWMutex
 	capacity int
-	items    map[string]*lruItem
-	head     *lruItem
-	tail     *lruItem
+	items    map[string]*lruItem[T]
+	head     *lruItem[T]
+	tail     *lruItem[T]
 }
 
-type lruItem struct {
+type lruItem[T any] struct {
 	key   string
-	value interface{}
-	prev  *lruItem
-	next  *lruItem
+	value T
+	prev  *lruItem[T]
+	next  *lruItem[T]
 }
 
 // NewLRUCache creates a new LRU cache with specified capacity
-func NewLRUCache(capacity int) *LRUCache {
-	cache := &LRUCache{
+func NewLRUCache[T any](capacity int) *LRUCache[T] {
+	cache := &LRUCache[T]{
 		capacity: capacity,
-		items:    make(map[string]*lruItem),
+		items:    make(map[string]*lruItem[T]),
 	}
 	
 	// Initialize dummy head and tail
-	cache.head = &lruItem{}
-	cache.tail = &lruItem{}
+	cache.head = &lruItem[T]{}
+	cache.tail = &lruItem[T]{}
 	cache.head.next = cache.tail
 	cache.tail.prev = cache.head
 	
@@ -166,13 +167,14 @@ func NewLRUCache(capacity int) *LRUCache {
 }
 
 // Get retrieves a value from the LRU cache
-func (c *LRUCache) Get(key string) (interface{}, bool) {
+func (c *LRUCache[T]) Get(key string) (T, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
 	item, exists := c.items[key]
 	if !exists {
-		return nil, false
+		var zero T
+		return zero, false
 	}
 	
 	// Move to front
@@ -182,7 +184,7 @@ func (c *LRUCache) Get(key string) (interface{}, bool) {
 }
 
 // Set stores a value in the LRU cache
-func (c *LRUCache) Set(key string, value interface{}, ttl time.Duration) {
+func (c *LRUCache[T]) Set(key string, value T, ttl time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
@@ -195,7 +197,7 @@ func (c *LRUCache) Set(key string, value interface{}, ttl time.Duration) {
 	}
 	
 	// Create new item
-	newItem := &lruItem{
+	newItem := &lruItem[T]{
 		key:   key,
 		value: value,
 	}
@@ -210,7 +212,7 @@ func (c *LRUCache) Set(key string, value interface{}, ttl time.Duration) {
 }
 
 // Delete removes a value from the LRU cache
-func (c *LRUCache) Delete(key string) {
+func (c *LRUCache[T]) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
@@ -224,17 +226,17 @@ func (c *LRUCache) Delete(key string) {
 }
 
 // Clear removes all items from the LRU cache
-func (c *LRUCache) Clear() {
+func (c *LRUCache[T]) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	c.items = make(map[string]*lruItem)
+	c.items = make(map[string]*lruItem[T])
 	c.head.next = c.tail
 	c.tail.prev = c.head
 }
 
 // Size returns the number of items in the LRU cache
-func (c *LRUCache) Size() int {
+func (c *LRUCache[T]) Size() int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	
@@ -242,7 +244,7 @@ func (c *LRUCache) Size() int {
 }
 
 // Keys returns all keys in the LRU cache
-func (c *LRUCache) Keys() []string {
+func (c *LRUCache[T]) Keys() []string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	
@@ -255,13 +257,13 @@ func (c *LRUCache) Keys() []string {
 }
 
 // moveToFront moves an item to the front of the list
-func (c *LRUCache) moveToFront(item *lruItem) {
+func (c *LRUCache[T]) moveToFront(item *lruItem[T]) {
 	c.removeItem(item)
 	c.addToFront(item)
 }
 
 // addToFront adds an item to the front of the list
-func (c *LRUCache) addToFront(item *lruItem) {
+func (c *LRUCache[T]) addToFront(item *lruItem[T]) {
 	item.prev = c.head
 	item.next = c.head.next
 	c.head.next.prev = item
@@ -269,13 +271,13 @@ func (c *LRUCache) addToFront(item *lruItem) {
 }
 
 // removeItem removes an item from the list
-func (c *LRUCache) removeItem(item *lruItem) {
+func (c *LRUCache[T]) removeItem(item *lruItem[T]) {
 	item.prev.next = item.next
 	item.next.prev = item.prev
 }
 
 // removeLast removes the last item from the cache
-func (c *LRUCache) removeLast() {
+func (c *LRUCache[T]) removeLast() {
 	lastItem := c.tail.prev
 	c.removeItem(lastItem)
 	delete(c.items, lastItem.key)
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// defaultShards is the number of shards of a ShardedCache by default
+const defaultShards = 32
+
+var (
+	_ Cache[any] = (*MemoryCache[any])(nil)
+	_ Cache[any] = (*LRUCache[any])(nil)
+	_ Cache[any] = (*ShardedCache[any])(nil)
+)
+
+// ShardedCache is a cache of values of type T spread over shards locked
+// separately, so that concurrent requests for different keys rarely wait for
+// each other. Expired values are dropped when they are read, and from a
+// shard that is full when a value is added to it; a full shard without
+// expired values drops an arbitrary one.
+type ShardedCache[T any] struct {
+	shards []*shard[T]
+
+	// Values each shard holds at most, 0 for no limit
+	shardCapacity int
+}
+
+// shard is one lock's worth of a ShardedCache
+type shard[T any] struct {
+	mu    sync.RWMutex
+	items map[string]cacheItem[T]
+}
+
+// NewShardedCache creates a cache of shards shards (0 = 32) holding about
+// capacity values at most (0 = no limit)
+func NewShardedCache[T any](shards, capacity int) *ShardedCache[T] {
+	if shards <= 0 {
+		shards = defaultShards
+	}
+	c := &ShardedCache[T]{shards: make([]*shard[T], shards)}
+	if capacity > 0 {
+		c.shardCapacity = (capacity + shards - 1) / shards
+	}
+	for i := range c.shards {
+		c.shards[i] = &shard[T]{items: make(map[string]cacheItem[T])}
+	}
+	return c
+}
+
+// shardOf returns the shard holding key
+func (c *ShardedCache[T]) shardOf(key string) *shard[T] {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return c.shards[h.Sum32()%uint32(len(c.shards))]
+}
+
+// Get returns the value of key unless it expired
+func (c *ShardedCache[T]) Get(key string) (T, bool) {
+	s := c.shardOf(key)
+	s.mu.RLock()
+	item, ok := s.items[key]
+	s.mu.RUnlock()
+
+	var zero T
+	if !ok {
+		return zero, false
+	}
+	if item.expired(time.Now().UnixNano()) {
+		s.mu.Lock()
+		// The value may have been replaced since it was read
+		if current, ok := s.items[key]; ok && current.expiration == item.expiration {
+			delete(s.items, key)
+		}
+		s.mu.Unlock()
+		return zero, false
+	}
+	return item.value, true
+}
+
+// Set stores the value of key for ttl, or until it is deleted when ttl is 0
+func (c *ShardedCache[T]) Set(key string, value T, ttl time.Duration) {
+	now := time.Now().UnixNano()
+	var expiration int64
+	if ttl > 0 {
+		expiration = now + int64(ttl)
+	}
+
+	s := c.shardOf(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.items[key]; !exists && c.shardCapacity > 0 && len(s.items) >= c.shardCapacity {
+		s.makeRoom(c.shardCapacity, now)
+	}
+	s.items[key] = cacheItem[T]{value: value, expiration: expiration}
+}
+
+// makeRoom drops the expired values of the shard, then arbitrary ones until
+// it holds fewer than capacity; the caller holds the lock
+func (s *shard[T]) makeRoom(capacity int, now int64) {
+	for key, item := range s.items {
+		if item.expired(now) {
+			delete(s.items, key)
+		}
+	}
+	for key := range s.items {
+		if len(s.items) < capacity {
+			return
+		}
+		delete(s.items, key)
+	}
+}
+
+// Delete removes the value of key
+func (c *ShardedCache[T]) Delete(key string) {
+	s := c.shardOf(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+}
+
+// DeleteFunc removes the values for which drop returns true and returns how
+// many it removed
+func (c *ShardedCache[T]) DeleteFunc(drop func(key string, value T) bool) int {
+	removed := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key, item := range s.items {
+			if drop(key, item.value) {
+				delete(s.items, key)
+				removed++
+			}
+		}
+		s.mu.Unlock()
+	}
+	return removed
+}
+
+// Range calls fn with the values that haven't expired until it returns
+// false. fn must not modify the cache.
+func (c *ShardedCache[T]) Range(fn func(key string, value T) bool) {
+	now := time.Now().UnixNano()
+	for _, s := range c.shards {
+		s.mu.RLock()
+		for key, item := range s.items {
+			if !item.expired(now) && !fn(key, item.value) {
+				s.mu.RUnlock()
+				return
+			}
+		}
+		s.mu.RUnlock()
+	}
+}
+
+// Clear removes all values
+func (c *ShardedCache[T]) Clear() {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.items = make(map[string]cacheItem[T])
+		s.mu.Unlock()
+	}
+}
+
+// Size returns the number of values, including expired ones not dropped yet
+func (c *ShardedCache[T]) Size() int {
+	size := 0
+	for _, s := range c.shards {
+		s.mu.RLock()
+		size += len(s.items)
+		s.mu.RUnlock()
+	}
+	return size
+}
+
+// Keys returns the keys of the values that haven't expired
+func (c *ShardedCache[T]) Keys() []string {
+	now := time.Now().UnixNano()
+	var keys []string
+	for _, s := range c.shards {
+		s.mu.RLock()
+		for key, item := range s.items {
+			if !item.expired(now) {
+				keys = append(keys, key)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return keys
+}
+
+// expired reports whether the item expired at now
+func (i cacheItem[T]) expired(now int64) bool {
+	return i.expiration > 0 && now > i.expiration
+}
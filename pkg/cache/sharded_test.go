@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedCache(t *testing.T) {
+	c := NewShardedCache[int](4, 0)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, time.Millisecond)
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %d, %v, want 1, true", v, ok)
+	}
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Expected a miss for a missing key")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.Get("c"); ok {
+		t.Error("Expected c to expire")
+	}
+	keys := c.Keys()
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("Keys() = %v, want [a b]", keys)
+	}
+
+	if removed := c.DeleteFunc(func(_ string, v int) bool { return v%2 == 0 }); removed != 1 {
+		t.Errorf("DeleteFunc() removed %d values, want 1", removed)
+	}
+	sum := 0
+	c.Range(func(_ string, v int) bool {
+		sum += v
+		return true
+	})
+	if sum != 1 {
+		t.Errorf("Expected only a left, got a sum of %d", sum)
+	}
+
+	c.Clear()
+	if c.Size() != 0 {
+		t.Errorf("Size() = %d after Clear()", c.Size())
+	}
+}
+
+func TestShardedCacheCapacity(t *testing.T) {
+	c := NewShardedCache[string](2, 10)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		c.Set(key, key, 0)
+	}
+	if size := c.Size(); size > 10 {
+		t.Errorf("Size() = %d, want at most 10", size)
+	}
+
+	// Replacing a value of a full shard keeps the others
+	c.Set("key-99", "new", 0)
+	if v, ok := c.Get("key-99"); !ok || v != "new" {
+		t.Errorf("Get(key-99) = %q, %v, want new, true", v, ok)
+	}
+}
+
+func TestShardedCacheConcurrency(t *testing.T) {
+	c := NewShardedCache[int](0, 100)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("%d-%d", w, i%50)
+				c.Set(key, i, time.Second)
+				c.Get(key)
+				if i%100 == 0 {
+					c.DeleteFunc(func(_ string, v int) bool { return v < 10 })
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if size := c.Size(); size > 128 {
+		t.Errorf("Size() = %d, want about 100 at most", size)
+	}
+}
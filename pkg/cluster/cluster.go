@@ -3,7 +3,11 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cache"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/kubernetes"
@@ -31,14 +35,24 @@ type ClusterClient interface {
 type ClusterConfig struct {
 	Name       string `yaml:"name" json:"name"`
 	KubeConfig string `yaml:"kubeconfig" json:"kubeconfig"`
+	KubeconfigSecret config.SecretKeyRef `yaml:"kubeconfig_secret,omitempty" json:"kubeconfig_secret,omitzero"`
 	Context    string `yaml:"context" json:"context"`
 	Namespace  string `yaml:"namespace" json:"namespace"`
 	Enabled    bool   `yaml:"enabled" json:"enabled"`
 	Primary    bool   `yaml:"primary" json:"primary"`
+	Priority   int    `yaml:"priority,omitempty" json:"priority,omitempty"`
+	
+	// Connection overrides
+	InsecureSkipTLSVerify bool   `yaml:"insecure_skip_tls_verify,omitempty" json:"insecure_skip_tls_verify,omitempty"`
+	CAFile                string `yaml:"ca_file,omitempty" json:"ca_file,omitempty"`
+	ProxyURL              string `yaml:"proxy_url,omitempty" json:"proxy_url,omitempty"`
 	
 	// Internal fields
 	restConfig *rest.Config
 	kubeClient kubernetes.Interface
+	
+	// Builds and caches the clients of registered clusters, nil otherwise
+	pool *ClientPool
 }
 
 // NewClusterConfig creates a new cluster configuration
@@ -49,18 +63,64 @@ func NewClusterConfig(name string) *ClusterConfig {
 	}
 }
 
+// NewClusterConfigFrom creates a cluster configuration from its file configuration
+func NewClusterConfigFrom(cfg config.ClusterConfig) *ClusterConfig {
+	return &ClusterConfig{
+		Name:                  cfg.Name,
+		KubeConfig:            cfg.KubeConfig,
+		KubeconfigSecret:      cfg.KubeconfigSecret,
+		Context:               cfg.Context,
+		Namespace:             cfg.Namespace,
+		Enabled:               cfg.Enabled,
+		Primary:               cfg.Primary,
+		Priority:              cfg.Priority,
+		InsecureSkipTLSVerify: cfg.InsecureSkipTLSVerify,
+		CAFile:                cfg.CAFile,
+		ProxyURL:              cfg.ProxyURL,
+	}
+}
+
 // GetName returns the cluster name
 func (c *ClusterConfig) GetName() string {
 	return c.Name
 }
 
-// GetRestConfig returns the REST configuration for this cluster
+// GetRestConfig returns the REST configuration for this cluster. A
+// registered cluster shares it through the registry's client pool.
 func (c *ClusterConfig) GetRestConfig() (*rest.Config, error) {
+	if c.pool != nil {
+		client, err := c.pool.get(c)
+		if err != nil {
+			return nil, err
+		}
+		return client.restConfig, nil
+	}
 	if c.restConfig != nil {
 		return c.restConfig, nil
 	}
 	
-	if c.KubeConfig != "" {
+	config, err := c.buildRestConfig()
+	if err != nil {
+		return nil, err
+	}
+	c.restConfig = config
+	return c.restConfig, nil
+}
+
+// buildRestConfig builds the REST configuration from the kubeconfig of the
+// cluster and its connection overrides
+func (c *ClusterConfig) buildRestConfig() (*rest.Config, error) {
+	if c.KubeconfigSecret.IsSet() {
+		// Use the kubeconfig stored in a Secret
+		config, err := c.secretRestConfig()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.applyConnectionOverrides(config); err != nil {
+			return nil, err
+		}
+		return config, nil
+	} else if c.KubeConfig != "" {
 		// Use specific kubeconfig file
 		config, err := clientcmd.BuildConfigFromFlags("", c.KubeConfig)
 		if err != nil {
@@ -79,7 +139,10 @@ func (c *ClusterConfig) GetRestConfig() (*rest.Config, error) {
 			}
 		}
 		
-		c.restConfig = config
+		if err := c.applyConnectionOverrides(config); err != nil {
+			return nil, err
+		}
+		return config, nil
 	} else {
 		// Use default kubeconfig
 		config, err := rest.InClusterConfig()
@@ -90,14 +153,43 @@ func (c *ClusterConfig) GetRestConfig() (*rest.Config, error) {
 				return nil, fmt.Errorf("failed to build config from default kubeconfig: %w", err)
 			}
 		}
-		c.restConfig = config
+		if err := c.applyConnectionOverrides(config); err != nil {
+			return nil, err
+		}
+		return config, nil
+	}
+}
+
+// applyConnectionOverrides applies the cluster's TLS and proxy overrides to a REST config
+func (c *ClusterConfig) applyConnectionOverrides(config *rest.Config) error {
+	if c.CAFile != "" {
+		if _, err := os.Stat(c.CAFile); err != nil {
+			return fmt.Errorf("failed to read CA bundle for cluster %s: %w", c.Name, err)
+		}
+		config.TLSClientConfig.CAFile = c.CAFile
+		config.TLSClientConfig.CAData = nil
 	}
 	
-	return c.restConfig, nil
+	if c.InsecureSkipTLSVerify {
+		// client-go rejects root certificates combined with the insecure flag
+		config.TLSClientConfig.Insecure = true
+		config.TLSClientConfig.CAFile = ""
+		config.TLSClientConfig.CAData = nil
+	}
+	
+	return c.applyProxy(config)
 }
 
-// GetKubernetesClient returns a Kubernetes client for this cluster
+// GetKubernetesClient returns a Kubernetes client for this cluster. A
+// registered cluster shares it through the registry's client pool.
 func (c *ClusterConfig) GetKubernetesClient() (kubernetes.Interface, error) {
+	if c.pool != nil {
+		client, err := c.pool.get(c)
+		if err != nil {
+			return nil, err
+		}
+		return client.clientset, nil
+	}
 	if c.kubeClient != nil {
 		return c.kubeClient, nil
 	}
@@ -121,8 +213,23 @@ func (c *ClusterConfig) IsEnabled() bool {
 	return c.Enabled
 }
 
-// TestConnection tests connectivity to the cluster
+// IsPrimary returns whether the cluster is the primary cluster
+func (c *ClusterConfig) IsPrimary() bool {
+	return c.Primary
+}
+
+// GetPriority returns the startup priority of the cluster
+func (c *ClusterConfig) GetPriority() int {
+	return c.Priority
+}
+
+// TestConnection tests connectivity to the cluster. The pooled client of a
+// registered cluster that fails the test is rebuilt on next use.
 func (c *ClusterConfig) TestConnection(ctx context.Context) error {
+	if c.pool != nil {
+		return c.pool.check(ctx, c)
+	}
+	
 	client, err := c.GetKubernetesClient()
 	if err != nil {
 		return fmt.Errorf("failed to get Kubernetes client: %w", err)
@@ -137,33 +244,92 @@ func (c *ClusterConfig) TestConnection(ctx context.Context) error {
 	return nil
 }
 
+// ConnectivityResult reports how a cluster's API server answered a connectivity check
+type ConnectivityResult struct {
+	Proxy         ProxyInfo
+	ServerVersion string
+	Latency       time.Duration
+}
+
+// CheckConnectivity asks a configured cluster's API server for its version,
+// giving up after timeout, and reports the version, the round trip latency and
+// the proxy used to reach it
+func CheckConnectivity(cfg config.ClusterConfig, timeout time.Duration) (ConnectivityResult, error) {
+	c := NewClusterConfigFrom(cfg)
+	result := ConnectivityResult{Proxy: ProxyInfo{Source: ProxySourceNone}}
+	
+	// Build config from kubeconfig, applying TLS and proxy overrides
+	restConfig, err := c.GetRestConfig()
+	if err != nil {
+		return result, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	
+	result.Proxy, err = c.ProxyInfo()
+	if err != nil {
+		return result, err
+	}
+	
+	// The client is built from the cached REST config, so it uses the timeout
+	restConfig.Timeout = timeout
+	client, err := c.GetKubernetesClient()
+	if err != nil {
+		return result, fmt.Errorf("failed to get Kubernetes client: %w", err)
+	}
+	
+	start := time.Now()
+	version, err := client.Discovery().ServerVersion()
+	result.Latency = time.Since(start)
+	if err != nil {
+		return result, fmt.Errorf("failed to connect to cluster %s: %w", c.Name, err)
+	}
+	result.ServerVersion = version.GitVersion
+	return result, nil
+}
+
 // InMemoryClusterRegistry is a simple in-memory implementation of ClusterRegistry
 type InMemoryClusterRegistry struct {
-	clusters map[string]*ClusterConfig
+	clusters *cache.ShardedCache[*ClusterConfig]
+	
+	// Clients of the registered clusters
+	pool *ClientPool
 }
 
+// registryShards is the number of shards of the clusters of a registry, which
+// are few and mostly read
+const registryShards = 4
+
 // NewInMemoryClusterRegistry creates a new in-memory cluster registry
 func NewInMemoryClusterRegistry() *InMemoryClusterRegistry {
 	return &InMemoryClusterRegistry{
-		clusters: make(map[string]*ClusterConfig),
+		clusters: cache.NewShardedCache[*ClusterConfig](registryShards, 0),
+		pool:     NewClientPool(0),
 	}
 }
 
+// Pool returns the pool of the clients of the registered clusters
+func (r *InMemoryClusterRegistry) Pool() *ClientPool {
+	return r.pool
+}
+
 // GetEnabledClusters returns all enabled clusters
 func (r *InMemoryClusterRegistry) GetEnabledClusters() map[string]ClusterClient {
 	enabled := make(map[string]ClusterClient)
-	for name, config := range r.clusters {
+	r.clusters.Range(func(name string, config *ClusterConfig) bool {
 		if config.Enabled {
 			enabled[name] = config
 		}
-	}
+		return true
+	})
 	return enabled
 }
 
 // GetCluster returns a specific cluster by name
 func (r *InMemoryClusterRegistry) GetCluster(name string) (ClusterClient, bool) {
-	config, exists := r.clusters[name]
-	return config, exists
+	config, exists := r.clusters.Get(name)
+	if !exists {
+		return nil, false
+	}
+	return config, true
 }
 
 // AddCluster adds a new cluster to the registry
@@ -176,21 +342,26 @@ func (r *InMemoryClusterRegistry) AddCluster(name string, config *ClusterConfig)
 		config.Name = name
 	}
 	
-	r.clusters[name] = config
+	// Clients are reused across registrations with the same connection
+	// settings, except those read from a Secret: it is registered again
+	// when its kubeconfig changes
+	config.pool = r.pool
+	if config.KubeconfigSecret.IsSet() {
+		r.pool.Remove(name)
+	}
+	
+	r.clusters.Set(name, config, 0)
 	return nil
 }
 
-// RemoveCluster removes a cluster from the registry
+// RemoveCluster removes a cluster from the registry and closes its client
 func (r *InMemoryClusterRegistry) RemoveCluster(name string) error {
-	delete(r.clusters, name)
+	r.clusters.Delete(name)
+	r.pool.Remove(name)
 	return nil
 }
 
 // ListClusters returns a list of all cluster names
 func (r *InMemoryClusterRegistry) ListClusters() []string {
-	names := make([]string, 0, len(r.clusters))
-	for name := range r.clusters {
-		names = append(names, name)
-	}
-	return names
+	return r.clusters.Keys()
 }
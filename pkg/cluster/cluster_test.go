@@ -0,0 +1,115 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"k8s.io/client-go/rest"
+)
+
+func TestApplyConnectionOverrides(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(caFile, []byte("ca"), 0600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	c := &ClusterConfig{Name: "prod", CAFile: caFile, ProxyURL: "socks5://proxy.internal:1080"}
+	config := &rest.Config{TLSClientConfig: rest.TLSClientConfig{CAData: []byte("kubeconfig-ca")}}
+	if err := c.applyConnectionOverrides(config); err != nil {
+		t.Fatalf("applyConnectionOverrides() error = %v", err)
+	}
+	if config.TLSClientConfig.CAFile != caFile || config.TLSClientConfig.CAData != nil {
+		t.Errorf("Expected CA bundle override, got file=%q data=%q", config.TLSClientConfig.CAFile, config.TLSClientConfig.CAData)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.prod:6443", nil)
+	proxy, err := config.Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "proxy.internal:1080" {
+		t.Errorf("Expected proxy proxy.internal:1080, got %v (err %v)", proxy, err)
+	}
+
+	insecure := &ClusterConfig{Name: "lab", InsecureSkipTLSVerify: true}
+	config = &rest.Config{TLSClientConfig: rest.TLSClientConfig{CAData: []byte("kubeconfig-ca")}}
+	if err := insecure.applyConnectionOverrides(config); err != nil {
+		t.Fatalf("applyConnectionOverrides() error = %v", err)
+	}
+	if !config.TLSClientConfig.Insecure || config.TLSClientConfig.CAData != nil {
+		t.Error("Expected insecure config without root certificates")
+	}
+
+	missing := &ClusterConfig{Name: "broken", CAFile: filepath.Join(t.TempDir(), "missing.crt")}
+	if err := missing.applyConnectionOverrides(&rest.Config{}); err == nil {
+		t.Error("Expected error for missing CA bundle")
+	}
+}
+
+func TestCheckConnectivity(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"major":"1","minor":"30","gitVersion":"v1.30.2"}`)
+	}))
+	defer apiServer.Close()
+
+	kubeconfig := writeKubeconfig(t, apiServer.URL)
+	result, err := CheckConnectivity(config.ClusterConfig{Name: "prod", KubeConfig: kubeconfig, ProxyURL: "direct"}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("CheckConnectivity() error = %v", err)
+	}
+	if result.ServerVersion != "v1.30.2" || result.Latency <= 0 {
+		t.Errorf("Expected server version v1.30.2 and a latency, got %+v", result)
+	}
+
+	slow := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-slow
+	}))
+	defer hanging.Close()
+	defer close(slow)
+
+	start := time.Now()
+	_, err = CheckConnectivity(config.ClusterConfig{Name: "stuck", KubeConfig: writeKubeconfig(t, hanging.URL), ProxyURL: "direct"}, 200*time.Millisecond)
+	if err == nil {
+		t.Fatal("Expected error for an API server that does not answer")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the check to give up after the timeout, took %v", elapsed)
+	}
+}
+
+// writeKubeconfig writes a kubeconfig for an API server at server
+func writeKubeconfig(t *testing.T, server string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	data := kubeconfigYAML(server)
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+// kubeconfigYAML returns a kubeconfig connecting to server with a token
+func kubeconfigYAML(server string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test
+`, server)
+}
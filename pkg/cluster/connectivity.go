@@ -0,0 +1,88 @@
+package cluster
+
+import (
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+)
+
+// ClusterConnectivity is the connectivity check result of one cluster
+type ClusterConnectivity struct {
+	Name          string  `json:"name"`
+	Reachable     bool    `json:"reachable"`
+	ServerVersion string  `json:"serverVersion,omitempty"`
+	LatencyMs     float64 `json:"latencyMs"`
+	Proxy         string  `json:"proxy"`
+	Message       string  `json:"message"`
+}
+
+// ConnectivityReport aggregates the connectivity checks of several clusters
+type ConnectivityReport struct {
+	Clusters    []ClusterConnectivity `json:"clusters"`
+	Reachable   int                   `json:"reachable"`
+	Unreachable int                   `json:"unreachable"`
+}
+
+// CheckAll checks the connectivity of clusters concurrently, at most
+// concurrency at a time, each within timeout. Results keep the order of clusters.
+func CheckAll(clusters []config.ClusterConfig, timeout time.Duration, concurrency int) ConnectivityReport {
+	return checkAll(clusters, concurrency, func(c config.ClusterConfig) (ConnectivityResult, error) {
+		return CheckConnectivity(c, timeout)
+	})
+}
+
+// checkAll runs check for each cluster on at most concurrency goroutines
+func checkAll(clusters []config.ClusterConfig, concurrency int, check func(config.ClusterConfig) (ConnectivityResult, error)) ConnectivityReport {
+	report := ConnectivityReport{Clusters: make([]ClusterConnectivity, len(clusters))}
+	forEachCluster(clusters, concurrency, func(i int, c config.ClusterConfig) {
+		result, err := check(c)
+		report.Clusters[i] = DescribeConnectivity(c.Name, result, err)
+	})
+
+	for _, result := range report.Clusters {
+		if result.Reachable {
+			report.Reachable++
+		} else {
+			report.Unreachable++
+		}
+	}
+	return report
+}
+
+// DescribeConnectivity describes the connectivity check result of a cluster
+func DescribeConnectivity(name string, result ConnectivityResult, err error) ClusterConnectivity {
+	described := ClusterConnectivity{
+		Name:          name,
+		Reachable:     err == nil,
+		ServerVersion: result.ServerVersion,
+		LatencyMs:     float64(result.Latency.Microseconds()) / 1000,
+		Proxy:         result.Proxy.String(),
+		Message:       "Connection successful",
+	}
+	if err != nil {
+		described.Message = err.Error()
+	}
+	return described
+}
+
+// forEachCluster calls fn with each cluster and its index on at most
+// concurrency goroutines, and returns once every call returned
+func forEachCluster(clusters []config.ClusterConfig, concurrency int, fn func(int, config.ClusterConfig)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, c := range clusters {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, c config.ClusterConfig) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(i, c)
+		}(i, c)
+	}
+	wg.Wait()
+}
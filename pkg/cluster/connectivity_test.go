@@ -0,0 +1,50 @@
+package cluster
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+)
+
+func TestCheckAll(t *testing.T) {
+	clusters := []config.ClusterConfig{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+
+	var running, peak int32
+	report := checkAll(clusters, 2, func(c config.ClusterConfig) (ConnectivityResult, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		if c.Name == "c" {
+			return ConnectivityResult{Proxy: ProxyInfo{Source: ProxySourceNone}}, errors.New("connection refused")
+		}
+		return ConnectivityResult{ServerVersion: "v1.30.2", Latency: 12 * time.Millisecond}, nil
+	})
+
+	if peak != 2 {
+		t.Errorf("Expected at most and up to 2 concurrent checks, got %d", peak)
+	}
+	for i, result := range report.Clusters {
+		if result.Name != clusters[i].Name {
+			t.Errorf("Expected results in cluster order, got %s at %d", result.Name, i)
+		}
+	}
+	if report.Reachable != 4 || report.Unreachable != 1 {
+		t.Errorf("Expected 4 reachable and 1 unreachable clusters, got %d and %d", report.Reachable, report.Unreachable)
+	}
+	if c := report.Clusters[2]; c.Reachable || c.Message != "connection refused" {
+		t.Errorf("Expected cluster c to be unreachable, got %+v", c)
+	}
+	if a := report.Clusters[0]; a.ServerVersion != "v1.30.2" || a.LatencyMs != 12 {
+		t.Errorf("Expected version and latency for cluster a, got %+v", a)
+	}
+}
@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterDeployments are the deployments listed from one cluster, or the
+// error listing them
+type ClusterDeployments struct {
+	Cluster     string
+	Deployments []appsv1.Deployment
+	Err         error
+}
+
+// ListDeployments lists the deployments in namespace ("" = all namespaces)
+// matching opts of each cluster concurrently, at most concurrency at a time,
+// each within timeout. Results keep the order of clusters.
+func ListDeployments(clusters []config.ClusterConfig, namespace string, opts metav1.ListOptions, timeout time.Duration, concurrency int) []ClusterDeployments {
+	results := make([]ClusterDeployments, len(clusters))
+	forEachCluster(clusters, concurrency, func(i int, c config.ClusterConfig) {
+		results[i] = ClusterDeployments{Cluster: c.Name}
+		results[i].Deployments, results[i].Err = listClusterDeployments(c, namespace, opts, timeout)
+	})
+	return results
+}
+
+// listClusterDeployments lists the deployments of one cluster
+func listClusterDeployments(c config.ClusterConfig, namespace string, opts metav1.ListOptions, timeout time.Duration) ([]appsv1.Deployment, error) {
+	client, err := NewClusterConfigFrom(c).GetKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	list, err := client.AppsV1().Deployments(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	return list.Items, nil
+}
+
+// ClusterDeployment is a deployment fetched from one cluster, or the error
+// fetching it
+type ClusterDeployment struct {
+	Cluster    string
+	Deployment *appsv1.Deployment
+	Err        error
+}
+
+// GetDeployment fetches the deployment namespace/name from each cluster
+// concurrently, like ListDeployments. Results keep the order of clusters.
+func GetDeployment(clusters []config.ClusterConfig, namespace, name string, timeout time.Duration, concurrency int) []ClusterDeployment {
+	results := make([]ClusterDeployment, len(clusters))
+	forEachCluster(clusters, concurrency, func(i int, c config.ClusterConfig) {
+		results[i] = ClusterDeployment{Cluster: c.Name}
+		results[i].Deployment, results[i].Err = getClusterDeployment(c, namespace, name, timeout)
+	})
+	return results
+}
+
+// getClusterDeployment fetches a deployment from one cluster. A missing
+// deployment is reported as a NotFound API error.
+func getClusterDeployment(c config.ClusterConfig, namespace, name string, timeout time.Duration) (*appsv1.Deployment, error) {
+	client, err := NewClusterConfigFrom(c).GetKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+}
@@ -0,0 +1,72 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListDeployments(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/apps/v1/namespaces/shop/deployments" || r.URL.Query().Get("labelSelector") != "app=web" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"DeploymentList","apiVersion":"apps/v1","items":[{"metadata":{"name":"web","namespace":"shop"}}]}`)
+	}))
+	defer apiServer.Close()
+
+	results := ListDeployments([]config.ClusterConfig{
+		{Name: "prod", KubeConfig: writeKubeconfig(t, apiServer.URL), ProxyURL: "direct"},
+		{Name: "broken", KubeConfig: writeKubeconfig(t, "http://127.0.0.1:1"), ProxyURL: "direct"},
+	}, "shop", metav1.ListOptions{LabelSelector: "app=web"}, 5*time.Second, 2)
+
+	if len(results) != 2 || results[0].Cluster != "prod" || results[1].Cluster != "broken" {
+		t.Fatalf("Expected results in cluster order, got %+v", results)
+	}
+	if results[0].Err != nil || len(results[0].Deployments) != 1 || results[0].Deployments[0].Name != "web" {
+		t.Errorf("Expected web in prod, got %+v", results[0])
+	}
+	if results[1].Err == nil {
+		t.Error("Expected an error for an unreachable cluster")
+	}
+}
+
+func TestGetDeployment(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/apis/apps/v1/namespaces/shop/deployments/web" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			return
+		}
+		fmt.Fprint(w, `{"kind":"Deployment","apiVersion":"apps/v1","metadata":{"name":"web","namespace":"shop"}}`)
+	}))
+	defer apiServer.Close()
+
+	clusters := []config.ClusterConfig{
+		{Name: "prod", KubeConfig: writeKubeconfig(t, apiServer.URL), ProxyURL: "direct"},
+		{Name: "staging", KubeConfig: writeKubeconfig(t, apiServer.URL), ProxyURL: "direct"},
+	}
+	results := GetDeployment(clusters, "shop", "web", 5*time.Second, 2)
+	if len(results) != 2 || results[1].Cluster != "staging" {
+		t.Fatalf("Expected results in cluster order, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Err != nil || result.Deployment.Name != "web" {
+			t.Errorf("Expected web in %s, got %+v", result.Cluster, result)
+		}
+	}
+
+	results = GetDeployment(clusters[:1], "shop", "api", 5*time.Second, 1)
+	if !apierrors.IsNotFound(results[0].Err) {
+		t.Errorf("Expected a NotFound error, got %v", results[0].Err)
+	}
+}
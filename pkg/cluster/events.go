@@ -0,0 +1,70 @@
+package cluster
+
+import (
+	"sync"
+
+	k6skube "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+)
+
+// EventRecorders records events in the clusters of a registry, creating the
+// recorder of a cluster on first use. A cluster whose client is replaced,
+// e.g. after a configuration reload, gets a new recorder.
+type EventRecorders struct {
+	registry ClusterRegistry
+
+	mu        sync.Mutex
+	recorders map[string]*clusterRecorder
+}
+
+// clusterRecorder is the recorder of a cluster and the clientset it writes
+// through
+type clusterRecorder struct {
+	clientset kubernetes.Interface
+	recorder  record.EventRecorder
+	stop      func()
+}
+
+// NewEventRecorders creates recorders for the clusters of registry
+func NewEventRecorders(registry ClusterRegistry) *EventRecorders {
+	return &EventRecorders{
+		registry:  registry,
+		recorders: make(map[string]*clusterRecorder),
+	}
+}
+
+// Recorder returns the event recorder of a cluster, nil when the cluster is
+// not configured or has no client
+func (r *EventRecorders) Recorder(name string) record.EventRecorder {
+	client, exists := r.registry.GetCluster(name)
+	if !exists {
+		return nil
+	}
+	clientset, err := client.GetKubernetesClient()
+	if err != nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.recorders[name]; ok {
+		if existing.clientset == clientset {
+			return existing.recorder
+		}
+		existing.stop()
+	}
+	recorder, stop := k6skube.NewEventRecorder(clientset)
+	r.recorders[name] = &clusterRecorder{clientset: clientset, recorder: recorder, stop: stop}
+	return recorder
+}
+
+// Stop stops every recorder once its queued events are written
+func (r *EventRecorders) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, existing := range r.recorders {
+		existing.stop()
+		delete(r.recorders, name)
+	}
+}
@@ -0,0 +1,233 @@
+package cluster
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+)
+
+// failoverCheckTimeout bounds one health check of a cluster
+const failoverCheckTimeout = 10 * time.Second
+
+// defaultFailoverInterval is used when no interval is configured
+const defaultFailoverInterval = 30 * time.Second
+
+// FailoverMonitor checks the primary cluster periodically and, once it has
+// failed FailureThreshold consecutive checks, promotes a reachable enabled
+// cluster chosen by the configured policy. The promotion is written to the
+// configuration file, so the configuration reload applies it everywhere.
+type FailoverMonitor struct {
+	store *ConfigStore
+	log   *logger.Logger
+
+	// Called after each promotion, nil when unset
+	onFailover func(from, to string)
+
+	// check reports whether a cluster is healthy
+	check func(config.ClusterConfig) error
+
+	mu       sync.Mutex
+	cfg      config.FailoverConfig
+	clusters []config.ClusterConfig
+	primary  string
+
+	// Consecutive failed checks of the primary cluster
+	failures int
+}
+
+// NewFailoverMonitor creates a monitor promoting clusters through store
+func NewFailoverMonitor(store *ConfigStore, cfg config.FailoverConfig) *FailoverMonitor {
+	return &FailoverMonitor{
+		store: store,
+		cfg:   cfg,
+		log:   logger.WithComponent("failover"),
+		check: func(c config.ClusterConfig) error {
+			_, err := CheckConnectivity(c, failoverCheckTimeout)
+			return err
+		},
+	}
+}
+
+// OnFailover sets the function called after the primary cluster failed over
+// from one cluster to another, such as to deliver a notification
+func (m *FailoverMonitor) OnFailover(fn func(from, to string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onFailover = fn
+}
+
+// SetConfig replaces the failover settings, taking effect at the next check
+func (m *FailoverMonitor) SetConfig(cfg config.FailoverConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg
+	if !cfg.Enabled {
+		m.failures = 0
+	}
+}
+
+// SetClusters replaces the configured clusters. A different primary cluster
+// starts over with no failed checks.
+func (m *FailoverMonitor) SetClusters(clusters []config.ClusterConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clusters = slices.Clone(clusters)
+
+	primary := ""
+	if i := slices.IndexFunc(clusters, func(c config.ClusterConfig) bool { return c.Primary && c.Enabled }); i >= 0 {
+		primary = clusters[i].Name
+	}
+	if primary != m.primary {
+		m.primary = primary
+		m.failures = 0
+	}
+}
+
+// Start checks the primary cluster every interval while failover is enabled,
+// until ctx is cancelled
+func (m *FailoverMonitor) Start(ctx context.Context) {
+	for {
+		m.mu.Lock()
+		enabled, interval := m.cfg.Enabled, m.cfg.Interval
+		m.mu.Unlock()
+		if interval <= 0 {
+			interval = defaultFailoverInterval
+		}
+
+		if enabled {
+			m.Check()
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Check checks the primary cluster once and fails over when it has reached
+// the failure threshold. It returns the name of the promoted cluster, or ""
+// when the primary cluster is kept.
+func (m *FailoverMonitor) Check() string {
+	m.mu.Lock()
+	cfg, clusters, primary := m.cfg, m.clusters, m.primary
+	m.mu.Unlock()
+
+	i := slices.IndexFunc(clusters, func(c config.ClusterConfig) bool { return c.Name == primary })
+	if i < 0 {
+		return ""
+	}
+	err := m.check(clusters[i])
+
+	m.mu.Lock()
+	if m.primary != primary {
+		// The clusters were reloaded during the check
+		m.mu.Unlock()
+		return ""
+	}
+	if err == nil {
+		m.failures = 0
+		m.mu.Unlock()
+		return ""
+	}
+	m.failures++
+	failures := m.failures
+	m.mu.Unlock()
+
+	threshold := max(cfg.FailureThreshold, 1)
+	m.log.Warn("Primary cluster health check failed", map[string]interface{}{
+		"cluster":   primary,
+		"failures":  failures,
+		"threshold": threshold,
+		"error":     err.Error(),
+	})
+	if failures < threshold {
+		return ""
+	}
+
+	for _, candidate := range failoverCandidates(cfg, clusters, primary) {
+		if err := m.check(candidate); err != nil {
+			m.log.Warn("Failover candidate is unreachable", map[string]interface{}{
+				"cluster": candidate.Name,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		if _, err := m.store.SetPrimary(candidate.Name); err != nil {
+			m.log.Error("Failed to promote cluster to primary", err, map[string]interface{}{
+				"cluster": candidate.Name,
+			})
+			return ""
+		}
+
+		m.mu.Lock()
+		if m.primary == primary {
+			for j := range m.clusters {
+				m.clusters[j].Primary = m.clusters[j].Name == candidate.Name
+			}
+			m.primary = candidate.Name
+			m.failures = 0
+		}
+		onFailover := m.onFailover
+		m.mu.Unlock()
+
+		m.log.Warn("Primary cluster failed over", map[string]interface{}{
+			"from":     primary,
+			"to":       candidate.Name,
+			"failures": failures,
+			"policy":   cfg.Policy,
+		})
+		if onFailover != nil {
+			onFailover(primary, candidate.Name)
+		}
+		return candidate.Name
+	}
+
+	m.log.Warn("No reachable cluster to fail over to", map[string]interface{}{
+		"cluster": primary,
+	})
+	return ""
+}
+
+// failoverCandidates returns the enabled clusters eligible to replace primary
+// in order of preference: by descending priority and name, or in the order of
+// the candidates list with the candidates policy
+func failoverCandidates(cfg config.FailoverConfig, clusters []config.ClusterConfig, primary string) []config.ClusterConfig {
+	eligible := make(map[string]config.ClusterConfig)
+	for _, c := range clusters {
+		if c.Enabled && c.Name != primary {
+			eligible[c.Name] = c
+		}
+	}
+
+	var candidates []config.ClusterConfig
+	if cfg.Policy == config.FailoverPolicyCandidates {
+		for _, name := range cfg.Candidates {
+			if c, ok := eligible[name]; ok {
+				candidates = append(candidates, c)
+				delete(eligible, name)
+			}
+		}
+		return candidates
+	}
+
+	for _, c := range eligible {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.Name < b.Name
+	})
+	return candidates
+}
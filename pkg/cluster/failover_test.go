@@ -0,0 +1,123 @@
+package cluster
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+)
+
+func TestFailoverMonitor(t *testing.T) {
+	store := NewConfigStore(filepath.Join(t.TempDir(), "k6s.yaml"))
+	clusters := []config.ClusterConfig{
+		{Name: "prod", KubeConfig: "/etc/k6s/prod.yaml", Enabled: true, Primary: true},
+		{Name: "eu", KubeConfig: "/etc/k6s/eu.yaml", Enabled: true, Priority: 10},
+		{Name: "us", KubeConfig: "/etc/k6s/us.yaml", Enabled: true, Priority: 20},
+		{Name: "ap", KubeConfig: "/etc/k6s/ap.yaml", Priority: 30},
+	}
+	for _, c := range clusters {
+		if _, err := store.Add(c); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	down := map[string]bool{"prod": true, "us": true}
+	monitor := NewFailoverMonitor(store, config.FailoverConfig{Enabled: true, FailureThreshold: 2, Policy: config.FailoverPolicyPriority})
+	monitor.check = func(c config.ClusterConfig) error {
+		if down[c.Name] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	monitor.SetClusters(clusters)
+	var failovers []string
+	monitor.OnFailover(func(from, to string) {
+		failovers = append(failovers, from+"->"+to)
+	})
+
+	if promoted := monitor.Check(); promoted != "" {
+		t.Fatalf("Expected no failover below the threshold, got %s", promoted)
+	}
+	// us has the highest priority of the enabled clusters but is unreachable
+	if promoted := monitor.Check(); promoted != "eu" {
+		t.Fatalf("Expected eu to be promoted, got %q", promoted)
+	}
+	if len(failovers) != 1 || failovers[0] != "prod->eu" {
+		t.Errorf("Expected the failover from prod to eu to be reported, got %v", failovers)
+	}
+
+	stored, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	for _, c := range stored {
+		if c.Primary != (c.Name == "eu") {
+			t.Errorf("Expected only eu to be primary, got %+v", c)
+		}
+	}
+
+	// The new primary is checked from now on
+	if promoted := monitor.Check(); promoted != "" {
+		t.Errorf("Expected eu to stay primary, got %s", promoted)
+	}
+}
+
+func TestFailoverMonitorRecovery(t *testing.T) {
+	store := NewConfigStore(filepath.Join(t.TempDir(), "k6s.yaml"))
+	monitor := NewFailoverMonitor(store, config.FailoverConfig{Enabled: true, FailureThreshold: 2})
+	healthy := false
+	monitor.check = func(c config.ClusterConfig) error {
+		if c.Name == "prod" && !healthy {
+			return errors.New("timeout")
+		}
+		return nil
+	}
+	monitor.SetClusters([]config.ClusterConfig{
+		{Name: "prod", Enabled: true, Primary: true},
+		{Name: "eu", Enabled: true},
+	})
+
+	monitor.Check()
+	healthy = true
+	monitor.Check()
+	healthy = false
+	if promoted := monitor.Check(); promoted != "" {
+		t.Errorf("Expected a successful check to reset the failures, got %s", promoted)
+	}
+}
+
+func TestFailoverCandidates(t *testing.T) {
+	clusters := []config.ClusterConfig{
+		{Name: "prod", Enabled: true, Primary: true},
+		{Name: "eu", Enabled: true, Priority: 10},
+		{Name: "us", Enabled: true, Priority: 10},
+		{Name: "ap", Enabled: true, Priority: 20},
+		{Name: "dev", Priority: 50},
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.FailoverConfig
+		want []string
+	}{
+		{"priority", config.FailoverConfig{Policy: config.FailoverPolicyPriority}, []string{"ap", "eu", "us"}},
+		{"candidates", config.FailoverConfig{Policy: config.FailoverPolicyCandidates, Candidates: []string{"us", "dev", "prod", "eu", "gone"}}, []string{"us", "eu"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range failoverCandidates(tt.cfg, clusters, "prod") {
+				got = append(got, c.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+)
+
+// HealthState is the health of a cluster as seen by a HealthTracker
+type HealthState string
+
+const (
+	// HealthSyncing: the API server answers but the deployment cache has not
+	// synced yet; also the state of a cluster before its first check
+	HealthSyncing HealthState = "syncing"
+	// HealthConnected: the API server answers and the cache is synced
+	HealthConnected HealthState = "connected"
+	// HealthDegraded: a check failed, fewer than the unreachable threshold in
+	// a row, or the API server answers but the cache failed to start
+	HealthDegraded HealthState = "degraded"
+	// HealthUnreachable: unreachableThreshold consecutive checks failed
+	HealthUnreachable HealthState = "unreachable"
+	// HealthDisabled: the cluster is configured but disabled, so not checked
+	HealthDisabled HealthState = "disabled"
+)
+
+// CacheSyncState is the state of the deployment cache of a cluster
+type CacheSyncState string
+
+const (
+	CacheSyncPending CacheSyncState = "pending"
+	CacheSyncSynced  CacheSyncState = "synced"
+	CacheSyncFailed  CacheSyncState = "failed"
+)
+
+// CacheSyncSource reports the deployment cache state of clusters. An empty
+// state means the cluster has no cache.
+type CacheSyncSource interface {
+	CacheSyncState(name string) (CacheSyncState, error)
+}
+
+const (
+	// healthCheckTimeout bounds one health check of a cluster
+	healthCheckTimeout = 10 * time.Second
+
+	// defaultHealthCheckInterval is used when no interval is configured
+	defaultHealthCheckInterval = 30 * time.Second
+
+	// unreachableThreshold is the number of consecutive failed checks after
+	// which a cluster is unreachable rather than degraded
+	unreachableThreshold = 3
+)
+
+// ClusterHealth is the tracked health of a cluster
+type ClusterHealth struct {
+	Name                string         `json:"name"`
+	State               HealthState    `json:"state"`
+	CacheSync           CacheSyncState `json:"cacheSync,omitempty"`
+	LastError           string         `json:"lastError,omitempty"`
+	LastHeartbeat       time.Time      `json:"lastHeartbeat,omitzero"`
+	LastTransition      time.Time      `json:"lastTransition,omitzero"`
+	ConsecutiveFailures int            `json:"consecutiveFailures"`
+	LatencyMs           float64        `json:"latencyMs"`
+}
+
+// HealthTracker periodically checks the enabled clusters of a registry and
+// moves each through the syncing, connected, degraded and unreachable states
+type HealthTracker struct {
+	registry ClusterRegistry
+	log      *logger.Logger
+
+	mu       sync.RWMutex
+	interval time.Duration
+	cache    CacheSyncSource
+	health   map[string]*ClusterHealth
+}
+
+// NewHealthTracker creates a tracker checking the clusters of registry every interval
+func NewHealthTracker(registry ClusterRegistry, interval time.Duration) *HealthTracker {
+	return &HealthTracker{
+		registry: registry,
+		interval: interval,
+		health:   make(map[string]*ClusterHealth),
+		log:      logger.WithComponent("cluster-health"),
+	}
+}
+
+// SetInterval changes the check interval, taking effect after the next check
+func (t *HealthTracker) SetInterval(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.interval = interval
+}
+
+// SetCacheSource sets where the deployment cache state of clusters is read from
+func (t *HealthTracker) SetCacheSource(source CacheSyncSource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cache = source
+}
+
+// Start checks the clusters every interval until ctx is cancelled
+func (t *HealthTracker) Start(ctx context.Context) {
+	for {
+		t.Check(ctx)
+
+		t.mu.RLock()
+		interval := t.interval
+		t.mu.RUnlock()
+		if interval <= 0 {
+			interval = defaultHealthCheckInterval
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Check checks every enabled cluster once, concurrently, and forgets
+// clusters that are no longer enabled
+func (t *HealthTracker) Check(ctx context.Context) {
+	clusters := t.registry.GetEnabledClusters()
+
+	var wg sync.WaitGroup
+	for name, client := range clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			start := time.Now()
+			err := client.TestConnection(checkCtx)
+			t.record(name, time.Since(start), err)
+		}()
+	}
+	wg.Wait()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for name := range t.health {
+		if _, enabled := clusters[name]; !enabled {
+			delete(t.health, name)
+		}
+	}
+}
+
+// record applies the result of a check to the state of a cluster
+func (t *HealthTracker) record(name string, latency time.Duration, err error) {
+	t.mu.RLock()
+	source := t.cache
+	t.mu.RUnlock()
+
+	var cacheState CacheSyncState
+	var cacheErr error
+	if source != nil {
+		cacheState, cacheErr = source.CacheSyncState(name)
+	}
+
+	t.mu.Lock()
+	health, exists := t.health[name]
+	if !exists {
+		health = &ClusterHealth{Name: name, State: HealthSyncing}
+		t.health[name] = health
+	}
+	previous := health.State
+	health.CacheSync = cacheState
+	health.LatencyMs = float64(latency.Microseconds()) / 1000
+
+	now := time.Now().UTC()
+	switch {
+	case err != nil:
+		health.ConsecutiveFailures++
+		health.LastError = err.Error()
+		health.State = HealthDegraded
+		if health.ConsecutiveFailures >= unreachableThreshold {
+			health.State = HealthUnreachable
+		}
+	case cacheState == CacheSyncFailed:
+		health.ConsecutiveFailures = 0
+		health.LastHeartbeat = now
+		health.State = HealthDegraded
+		if cacheErr != nil {
+			health.LastError = cacheErr.Error()
+		}
+	case cacheState == CacheSyncPending:
+		health.ConsecutiveFailures = 0
+		health.LastHeartbeat = now
+		health.State = HealthSyncing
+	default:
+		health.ConsecutiveFailures = 0
+		health.LastHeartbeat = now
+		health.State = HealthConnected
+	}
+	if health.State != previous || !exists {
+		health.LastTransition = now
+	}
+	current := *health
+	t.mu.Unlock()
+
+	if current.State == previous {
+		return
+	}
+	fields := map[string]interface{}{
+		"cluster": name,
+		"from":    string(previous),
+		"to":      string(current.State),
+	}
+	if current.State == HealthDegraded || current.State == HealthUnreachable {
+		fields["error"] = current.LastError
+		t.log.Warn("Cluster health changed", fields)
+	} else {
+		t.log.Info("Cluster health changed", fields)
+	}
+}
+
+// Health returns the health of a cluster, and false when it is not enabled.
+// An enabled cluster that was not checked yet is syncing.
+func (t *HealthTracker) Health(name string) (ClusterHealth, bool) {
+	t.mu.RLock()
+	health, exists := t.health[name]
+	t.mu.RUnlock()
+	if exists {
+		return *health, true
+	}
+
+	if _, enabled := t.registry.GetEnabledClusters()[name]; enabled {
+		return ClusterHealth{Name: name, State: HealthSyncing}, true
+	}
+	return ClusterHealth{}, false
+}
+
+// List returns the health of every checked cluster, sorted by name
+func (t *HealthTracker) List() []ClusterHealth {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	list := make([]ClusterHealth, 0, len(t.health))
+	for _, health := range t.health {
+		list = append(list, *health)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
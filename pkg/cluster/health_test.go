@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// probedCluster is a cluster client whose connectivity test returns err
+type probedCluster struct {
+	name string
+	err  *error
+}
+
+func (c probedCluster) GetName() string                                    { return c.name }
+func (c probedCluster) GetRestConfig() (*rest.Config, error)               { return &rest.Config{}, nil }
+func (c probedCluster) GetKubernetesClient() (kubernetes.Interface, error) { return nil, nil }
+func (c probedCluster) IsEnabled() bool                                    { return true }
+func (c probedCluster) TestConnection(ctx context.Context) error           { return *c.err }
+
+// probedRegistry serves probed clusters by name
+type probedRegistry map[string]ClusterClient
+
+func (r probedRegistry) GetEnabledClusters() map[string]ClusterClient { return r }
+func (r probedRegistry) GetCluster(name string) (ClusterClient, bool) {
+	c, ok := r[name]
+	return c, ok
+}
+func (r probedRegistry) AddCluster(name string, c *ClusterConfig) error { return nil }
+func (r probedRegistry) RemoveCluster(name string) error                { return nil }
+func (r probedRegistry) ListClusters() []string                         { return nil }
+
+// cacheStates is a cache sync source serving fixed states
+type cacheStates map[string]CacheSyncState
+
+func (s cacheStates) CacheSyncState(name string) (CacheSyncState, error) {
+	if s[name] == CacheSyncFailed {
+		return CacheSyncFailed, errors.New("forbidden")
+	}
+	return s[name], nil
+}
+
+func TestHealthTracker(t *testing.T) {
+	var prodErr, euErr error
+	registry := probedRegistry{
+		"prod": probedCluster{name: "prod", err: &prodErr},
+		"eu":   probedCluster{name: "eu", err: &euErr},
+	}
+	cache := cacheStates{"prod": CacheSyncPending, "eu": CacheSyncFailed}
+	tracker := NewHealthTracker(registry, 0)
+	tracker.SetCacheSource(cache)
+
+	if health, ok := tracker.Health("prod"); !ok || health.State != HealthSyncing {
+		t.Errorf("Expected prod to be syncing before the first check, got %+v", health)
+	}
+	if _, ok := tracker.Health("us"); ok {
+		t.Error("Expected no health for a cluster that is not enabled")
+	}
+
+	ctx := context.Background()
+	tracker.Check(ctx)
+	if health, _ := tracker.Health("prod"); health.State != HealthSyncing || health.LastHeartbeat.IsZero() {
+		t.Errorf("Expected prod to be syncing with a heartbeat, got %+v", health)
+	}
+	if health, _ := tracker.Health("eu"); health.State != HealthDegraded || health.LastError != "forbidden" {
+		t.Errorf("Expected eu to be degraded by its cache, got %+v", health)
+	}
+
+	cache["prod"] = CacheSyncSynced
+	tracker.Check(ctx)
+	prod, _ := tracker.Health("prod")
+	if prod.State != HealthConnected || prod.CacheSync != CacheSyncSynced {
+		t.Fatalf("Expected prod to be connected, got %+v", prod)
+	}
+	heartbeat := prod.LastHeartbeat
+
+	prodErr = errors.New("connection refused")
+	for i := 1; i <= unreachableThreshold; i++ {
+		tracker.Check(ctx)
+		prod, _ = tracker.Health("prod")
+		want := HealthDegraded
+		if i == unreachableThreshold {
+			want = HealthUnreachable
+		}
+		if prod.State != want || prod.ConsecutiveFailures != i {
+			t.Fatalf("Expected prod to be %s after %d failures, got %+v", want, i, prod)
+		}
+	}
+	if prod.LastError != "connection refused" || !prod.LastHeartbeat.Equal(heartbeat) {
+		t.Errorf("Expected the error and the last successful heartbeat, got %+v", prod)
+	}
+
+	prodErr = nil
+	tracker.Check(ctx)
+	if prod, _ = tracker.Health("prod"); prod.State != HealthConnected || prod.ConsecutiveFailures != 0 {
+		t.Errorf("Expected prod to recover, got %+v", prod)
+	}
+
+	// Clusters that are no longer enabled are forgotten
+	delete(registry, "eu")
+	tracker.Check(ctx)
+	if list := tracker.List(); len(list) != 1 || list[0].Name != "prod" {
+		t.Errorf("Expected only prod to be tracked, got %+v", list)
+	}
+}
@@ -0,0 +1,295 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Reasons pooled clients are closed, as reported in metrics
+const (
+	poolEvictCapacity  = "capacity"
+	poolEvictUnhealthy = "unhealthy"
+	poolEvictRemoved   = "removed"
+	poolEvictChanged   = "changed"
+)
+
+// ClientPool builds the REST config and clientset of each registered cluster
+// once and shares them between the controllers, health checks and API
+// handlers using the cluster. The pool holds at most maxClients clients,
+// closing the least recently used one beyond that, and closes the client of
+// a cluster that fails a health check so the next use connects again. Every
+// request through a pooled client is timed in the cluster API metrics.
+type ClientPool struct {
+	log *logger.Logger
+
+	mu         sync.Mutex
+	maxClients int
+	clients    map[string]*pooledClient
+}
+
+// pooledClient is the client of a cluster and the settings it was built with
+type pooledClient struct {
+	settings   connectionSettings
+	restConfig *rest.Config
+	httpClient *http.Client
+	clientset  kubernetes.Interface
+	lastUsed   time.Time
+}
+
+// connectionSettings are the fields of a cluster configuration its client
+// depends on; a client built with other settings is rebuilt
+type connectionSettings struct {
+	kubeConfig            string
+	kubeconfigSecret      config.SecretKeyRef
+	context               string
+	insecureSkipTLSVerify bool
+	caFile                string
+	proxyURL              string
+}
+
+// NewClientPool creates a pool of at most maxClients clients (0 = unbounded)
+func NewClientPool(maxClients int) *ClientPool {
+	return &ClientPool{
+		maxClients: maxClients,
+		clients:    make(map[string]*pooledClient),
+		log:        logger.WithComponent("cluster-clients"),
+	}
+}
+
+// SetMaxClients changes the number of clients held, closing the least
+// recently used ones beyond it
+func (p *ClientPool) SetMaxClients(maxClients int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.maxClients = maxClients
+	p.evictLocked("")
+}
+
+// Len returns the number of clients held
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// Remove closes the client of a cluster, if any
+func (p *ClientPool) Remove(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeLocked(name, poolEvictRemoved)
+}
+
+// Start checks the pooled clients every interval until ctx is done. Where a
+// HealthTracker checks the clusters, it checks their pooled clients already.
+func (p *ClientPool) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		p.Check(ctx)
+	}
+}
+
+// Check checks every pooled client once, concurrently
+func (p *ClientPool) Check(ctx context.Context) {
+	p.mu.Lock()
+	clients := make(map[string]*pooledClient, len(p.clients))
+	for name, client := range p.clients {
+		clients[name] = client
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for name, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+			if err := p.checkClient(checkCtx, name, client); err != nil {
+				p.log.Warn("Cluster client failed health check", map[string]interface{}{
+					"cluster": name,
+					"error":   err.Error(),
+				})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// get returns the client of a cluster, building it when the pool holds none
+// or one built with other connection settings
+func (p *ClientPool) get(c *ClusterConfig) (*pooledClient, error) {
+	settings := settingsOf(c)
+
+	p.mu.Lock()
+	if client, exists := p.clients[c.Name]; exists && client.settings == settings {
+		client.lastUsed = time.Now()
+		p.mu.Unlock()
+		return client, nil
+	}
+	p.mu.Unlock()
+
+	// Reading a kubeconfig Secret may take a while, so the client is built
+	// without holding the lock
+	client, err := p.build(c, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, exists := p.clients[c.Name]; exists {
+		if existing.settings == settings {
+			// Built concurrently by another caller
+			client.httpClient.CloseIdleConnections()
+			existing.lastUsed = time.Now()
+			return existing, nil
+		}
+		p.closeLocked(c.Name, poolEvictChanged)
+	}
+	p.clients[c.Name] = client
+	p.evictLocked(c.Name)
+	metrics.ClusterClients.Set(float64(len(p.clients)))
+	return client, nil
+}
+
+// check asks the API server of a cluster for its version with its pooled
+// client, closing the client when it doesn't answer
+func (p *ClientPool) check(ctx context.Context, c *ClusterConfig) error {
+	client, err := p.get(c)
+	if err != nil {
+		return fmt.Errorf("failed to get Kubernetes client: %w", err)
+	}
+	return p.checkClient(ctx, c.Name, client)
+}
+
+// checkClient asks the API server for its version with the client of a
+// cluster, closing the client when it doesn't answer
+func (p *ClientPool) checkClient(ctx context.Context, name string, client *pooledClient) error {
+	result := client.clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx)
+	if err := result.Error(); err != nil {
+		p.mu.Lock()
+		if p.clients[name] == client {
+			p.closeLocked(name, poolEvictUnhealthy)
+		}
+		p.mu.Unlock()
+		return fmt.Errorf("failed to connect to cluster %s: %w", name, err)
+	}
+	return nil
+}
+
+// build creates the REST config and clientset of a cluster, timing the
+// requests made through them
+func (p *ClientPool) build(c *ClusterConfig, settings connectionSettings) (*pooledClient, error) {
+	restConfig, err := c.buildRestConfig()
+	if err != nil {
+		return nil, err
+	}
+	restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &timedRoundTripper{cluster: c.Name, next: rt}
+	})
+
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for cluster %s: %w", c.Name, err)
+	}
+	clientset, err := kubernetes.NewForConfigAndClient(restConfig, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return &pooledClient{
+		settings:   settings,
+		restConfig: restConfig,
+		httpClient: httpClient,
+		clientset:  clientset,
+		lastUsed:   time.Now(),
+	}, nil
+}
+
+// evictLocked closes the least recently used clients beyond maxClients,
+// keeping the client of keep; callers hold the mutex
+func (p *ClientPool) evictLocked(keep string) {
+	for p.maxClients > 0 && len(p.clients) > p.maxClients {
+		oldest := ""
+		for name, client := range p.clients {
+			if name != keep && (oldest == "" || client.lastUsed.Before(p.clients[oldest].lastUsed)) {
+				oldest = name
+			}
+		}
+		if oldest == "" {
+			return
+		}
+		p.closeLocked(oldest, poolEvictCapacity)
+	}
+}
+
+// closeLocked drops the client of a cluster and closes its idle connections;
+// callers hold the mutex. Callers still holding the client can use it.
+func (p *ClientPool) closeLocked(name, reason string) {
+	client, exists := p.clients[name]
+	if !exists {
+		return
+	}
+	delete(p.clients, name)
+	client.httpClient.CloseIdleConnections()
+	metrics.ClusterClients.Set(float64(len(p.clients)))
+	metrics.ClusterClientEvictions.WithLabelValues(reason).Inc()
+	p.log.Debug("Closed cluster client", map[string]interface{}{
+		"cluster": name,
+		"reason":  reason,
+	})
+}
+
+// settingsOf returns the connection settings of a cluster configuration
+func settingsOf(c *ClusterConfig) connectionSettings {
+	return connectionSettings{
+		kubeConfig:            c.KubeConfig,
+		kubeconfigSecret:      c.KubeconfigSecret,
+		context:               c.Context,
+		insecureSkipTLSVerify: c.InsecureSkipTLSVerify,
+		caFile:                c.CAFile,
+		proxyURL:              c.ProxyURL,
+	}
+}
+
+// timedRoundTripper observes the duration of the requests to the API server
+// of a cluster. Watches last until they are closed, so they are not timed.
+type timedRoundTripper struct {
+	cluster string
+	next    http.RoundTripper
+}
+
+// RoundTrip times a request
+func (t *timedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Query().Get("watch") == "true" {
+		return t.next.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.ClusterAPIRequestDuration.WithLabelValues(t.cluster, req.Method, code).Observe(time.Since(start).Seconds())
+	return resp, err
+}
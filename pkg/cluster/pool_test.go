@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+)
+
+func TestClientPool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.1"}`))
+	}))
+	kubeconfig := writeKubeconfig(t, server.URL)
+
+	registry := NewInMemoryClusterRegistry()
+	register := func(name, context string) *ClusterConfig {
+		t.Helper()
+		// Connecting directly keeps the proxy environment from being read
+		// and cached before the proxy tests set it
+		c := NewClusterConfigFrom(config.ClusterConfig{Name: name, KubeConfig: kubeconfig, Context: context, ProxyURL: ProxyDirect, Enabled: true})
+		if err := registry.AddCluster(name, c); err != nil {
+			t.Fatalf("AddCluster() error = %v", err)
+		}
+		return c
+	}
+	clientOf := func(c *ClusterConfig) interface{} {
+		t.Helper()
+		client, err := c.GetKubernetesClient()
+		if err != nil {
+			t.Fatalf("GetKubernetesClient() error = %v", err)
+		}
+		return client
+	}
+
+	// Registering the cluster again with the same settings keeps its client,
+	// other settings replace it
+	prod := clientOf(register("prod", ""))
+	if again := clientOf(register("prod", "")); again != prod {
+		t.Error("Expected the client to be reused for the same connection settings")
+	}
+	if changed := clientOf(register("prod", "test")); changed == prod {
+		t.Error("Expected a new client for other connection settings")
+	}
+
+	// Requests through pooled clients are timed per cluster
+	edge := register("edge", "")
+	if err := edge.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() error = %v", err)
+	}
+	if got := testutil.CollectAndCount(metrics.ClusterAPIRequestDuration, "k6s_cluster_api_request_duration_seconds"); got == 0 {
+		t.Error("Expected the request to edge to be timed")
+	}
+
+	// The least recently used client is closed beyond the limit
+	if registry.Pool().Len() != 2 {
+		t.Fatalf("Expected 2 pooled clients, got %d", registry.Pool().Len())
+	}
+	registry.Pool().SetMaxClients(1)
+	if registry.Pool().Len() != 1 {
+		t.Errorf("Expected 1 pooled client after lowering the limit, got %d", registry.Pool().Len())
+	}
+
+	// A client that fails its health check is closed and rebuilt on next use
+	server.Close()
+	if err := edge.TestConnection(context.Background()); err == nil {
+		t.Fatal("Expected TestConnection() to fail once the API server is gone")
+	}
+	if registry.Pool().Len() != 0 {
+		t.Errorf("Expected the unhealthy client to be closed, got %d pooled clients", registry.Pool().Len())
+	}
+
+	clientOf(edge)
+	if err := registry.RemoveCluster("edge"); err != nil {
+		t.Fatal(err)
+	}
+	if registry.Pool().Len() != 0 {
+		t.Errorf("Expected the client of a removed cluster to be closed, got %d pooled clients", registry.Pool().Len())
+	}
+}
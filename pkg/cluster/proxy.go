@@ -0,0 +1,139 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ProxyDirect as a cluster proxy URL bypasses any proxy from the environment
+const ProxyDirect = "direct"
+
+// Proxy sources reported by ProxyInfo
+const (
+	ProxySourceCluster     = "cluster"
+	ProxySourceEnvironment = "environment"
+	ProxySourceNone        = "none"
+)
+
+// proxyEnvVars are the variables exec credential plugins read proxy settings from
+var proxyEnvVars = []string{"HTTPS_PROXY", "HTTP_PROXY", "https_proxy", "http_proxy"}
+
+// ProxyInfo describes the proxy used for a cluster's API server connections
+type ProxyInfo struct {
+	// URL is the proxy URL with credentials redacted (empty = direct connection)
+	URL string `json:"url,omitempty"`
+
+	// Source is where the proxy setting comes from: cluster, environment or none
+	Source string `json:"source"`
+}
+
+// String returns a short description for command output
+func (p ProxyInfo) String() string {
+	if p.URL == "" {
+		if p.Source == ProxySourceCluster {
+			return "direct (cluster)"
+		}
+		return "none"
+	}
+	return fmt.Sprintf("%s (%s)", p.URL, p.Source)
+}
+
+// applyProxy applies the cluster proxy to a REST config. Without a cluster
+// proxy, client-go falls back to HTTPS_PROXY/HTTP_PROXY/NO_PROXY.
+func (c *ClusterConfig) applyProxy(config *rest.Config) error {
+	switch c.ProxyURL {
+	case "":
+		return nil
+	case ProxyDirect:
+		config.Proxy = func(*http.Request) (*url.URL, error) {
+			return nil, nil
+		}
+	default:
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL for cluster %s: %w", c.Name, err)
+		}
+		config.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	c.applyExecProxy(config)
+	return nil
+}
+
+// applyExecProxy passes the cluster proxy to exec credential plugins, which run
+// as separate processes and only see environment variables. Variables already
+// set for the plugin in the kubeconfig take precedence.
+func (c *ClusterConfig) applyExecProxy(config *rest.Config) {
+	if config.ExecProvider == nil {
+		return
+	}
+
+	values := map[string]string{}
+	for _, name := range proxyEnvVars {
+		values[name] = c.ProxyURL
+	}
+	if c.ProxyURL == ProxyDirect {
+		for _, name := range proxyEnvVars {
+			values[name] = ""
+		}
+		values["NO_PROXY"] = "*"
+		values["no_proxy"] = "*"
+	}
+
+	exec := *config.ExecProvider
+	exec.Env = append([]clientcmdapi.ExecEnvVar{}, config.ExecProvider.Env...)
+	for _, existing := range exec.Env {
+		delete(values, existing.Name)
+	}
+	for _, name := range append(proxyEnvVars, "NO_PROXY", "no_proxy") {
+		if value, ok := values[name]; ok {
+			exec.Env = append(exec.Env, clientcmdapi.ExecEnvVar{Name: name, Value: value})
+		}
+	}
+	config.ExecProvider = &exec
+}
+
+// ProxyInfo returns the proxy used to reach the cluster's API server
+func (c *ClusterConfig) ProxyInfo() (ProxyInfo, error) {
+	switch c.ProxyURL {
+	case ProxyDirect:
+		return ProxyInfo{Source: ProxySourceCluster}, nil
+	case "":
+	default:
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return ProxyInfo{}, fmt.Errorf("invalid proxy URL for cluster %s: %w", c.Name, err)
+		}
+		return ProxyInfo{URL: proxyURL.Redacted(), Source: ProxySourceCluster}, nil
+	}
+
+	config, err := c.GetRestConfig()
+	if err != nil {
+		return ProxyInfo{}, err
+	}
+	return environmentProxy(config.Host)
+}
+
+// environmentProxy resolves the proxy from the environment for an API server host
+func environmentProxy(host string) (ProxyInfo, error) {
+	target, err := url.Parse(host)
+	if err != nil {
+		return ProxyInfo{}, fmt.Errorf("invalid API server host %s: %w", host, err)
+	}
+	if target.Scheme == "" {
+		target.Scheme = "https"
+	}
+
+	proxyURL, err := http.ProxyFromEnvironment(&http.Request{URL: target})
+	if err != nil {
+		return ProxyInfo{}, fmt.Errorf("invalid proxy in environment: %w", err)
+	}
+	if proxyURL == nil {
+		return ProxyInfo{Source: ProxySourceNone}, nil
+	}
+	return ProxyInfo{URL: proxyURL.Redacted(), Source: ProxySourceEnvironment}, nil
+}
@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	k6skube "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// secretReadTimeout bounds reading a kubeconfig Secret
+const secretReadTimeout = 10 * time.Second
+
+// defaultSecretRefreshInterval is used when no refresh interval is configured
+const defaultSecretRefreshInterval = time.Minute
+
+// secretClient returns the client kubeconfig Secrets are read with: the
+// cluster k6s runs in, or the default kubeconfig outside a pod
+var secretClient = sync.OnceValues(func() (kubernetes.Interface, error) {
+	restConfig, err := k6skube.RestConfig("")
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(restConfig)
+})
+
+// readKubeconfigSecret returns the kubeconfig stored in a Secret
+func readKubeconfigSecret(ctx context.Context, ref config.SecretKeyRef) ([]byte, error) {
+	client, err := secretClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for kubeconfig secret %s: %w", ref, err)
+	}
+
+	secret, err := client.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig secret %s: %w", ref, err)
+	}
+	data := secret.Data[ref.DataKey()]
+	if len(data) == 0 {
+		return nil, fmt.Errorf("kubeconfig secret %s is empty or has no such key", ref)
+	}
+	return data, nil
+}
+
+// secretRestConfig builds the REST config from the kubeconfig stored in the
+// cluster's Secret, using the configured context or the current one
+func (c *ClusterConfig) secretRestConfig() (*rest.Config, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretReadTimeout)
+	defer cancel()
+	data, err := readKubeconfigSecret(ctx, c.KubeconfigSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig secret %s: %w", c.KubeconfigSecret, err)
+	}
+	config, err := clientcmd.NewNonInteractiveClientConfig(*raw, c.Context, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load context %q from kubeconfig secret %s: %w", c.Context, c.KubeconfigSecret, err)
+	}
+	return config, nil
+}
+
+// SecretWatcher periodically reads the Secrets holding cluster kubeconfigs
+// and reports clusters whose kubeconfig changed, so their clients can be
+// rebuilt with the new credentials
+type SecretWatcher struct {
+	interval time.Duration
+	onChange func(config.ClusterConfig)
+	log      *logger.Logger
+
+	mu       sync.Mutex
+	clusters []config.ClusterConfig
+
+	// Hash of the last kubeconfig read, by cluster name
+	hashes map[string]string
+}
+
+// NewSecretWatcher creates a watcher checking Secrets every interval and
+// calling onChange with the configuration of each cluster whose kubeconfig
+// changed
+func NewSecretWatcher(interval time.Duration, onChange func(config.ClusterConfig)) *SecretWatcher {
+	if interval <= 0 {
+		interval = defaultSecretRefreshInterval
+	}
+	return &SecretWatcher{
+		interval: interval,
+		onChange: onChange,
+		hashes:   make(map[string]string),
+		log:      logger.WithComponent("kubeconfig-secrets"),
+	}
+}
+
+// SetClusters replaces the watched clusters. Clusters without a kubeconfig
+// Secret are ignored; a cluster whose reference changed starts over, since
+// its client is rebuilt by the configuration reload anyway.
+func (w *SecretWatcher) SetClusters(clusters []config.ClusterConfig) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	previous := make(map[string]config.SecretKeyRef, len(w.clusters))
+	for _, c := range w.clusters {
+		previous[c.Name] = c.KubeconfigSecret
+	}
+
+	w.clusters = nil
+	watched := make(map[string]bool)
+	for _, c := range clusters {
+		if !c.KubeconfigSecret.IsSet() {
+			continue
+		}
+		w.clusters = append(w.clusters, c)
+		if ref, exists := previous[c.Name]; exists && ref == c.KubeconfigSecret {
+			watched[c.Name] = true
+		}
+	}
+	for name := range w.hashes {
+		if !watched[name] {
+			delete(w.hashes, name)
+		}
+	}
+}
+
+// Start checks the Secrets every interval until ctx is cancelled
+func (w *SecretWatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check reads every watched Secret once and calls onChange for clusters whose
+// kubeconfig differs from the previous read. The first read of a cluster only
+// records its kubeconfig.
+func (w *SecretWatcher) Check(ctx context.Context) {
+	w.mu.Lock()
+	clusters := w.clusters
+	w.mu.Unlock()
+
+	var changed []config.ClusterConfig
+	for _, c := range clusters {
+		readCtx, cancel := context.WithTimeout(ctx, secretReadTimeout)
+		data, err := readKubeconfigSecret(readCtx, c.KubeconfigSecret)
+		cancel()
+		if err != nil {
+			// Clients built from the previous kubeconfig keep working
+			w.log.Warn("Failed to refresh kubeconfig secret", map[string]interface{}{
+				"cluster": c.Name,
+				"secret":  c.KubeconfigSecret.String(),
+				"error":   err.Error(),
+			})
+			continue
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+
+		w.mu.Lock()
+		previous, seen := w.hashes[c.Name]
+		w.hashes[c.Name] = hash
+		w.mu.Unlock()
+		if seen && previous != hash {
+			changed = append(changed, c)
+		}
+	}
+
+	for _, c := range changed {
+		w.log.Info("Kubeconfig secret changed, rebuilding cluster client", map[string]interface{}{
+			"cluster": c.Name,
+			"secret":  c.KubeconfigSecret.String(),
+		})
+		w.onChange(c)
+	}
+}
@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// useSecretClient makes kubeconfig Secrets be read from client during a test
+func useSecretClient(t *testing.T, client kubernetes.Interface) {
+	t.Helper()
+	previous := secretClient
+	secretClient = func() (kubernetes.Interface, error) { return client, nil }
+	t.Cleanup(func() { secretClient = previous })
+}
+
+func newKubeconfigSecret(key, server string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "k6s", Name: "prod-kubeconfig"},
+		Data:       map[string][]byte{key: []byte(kubeconfigYAML(server))},
+	}
+}
+
+func TestGetRestConfigFromSecret(t *testing.T) {
+	useSecretClient(t, fake.NewSimpleClientset(newKubeconfigSecret("config", "https://prod.example.com")))
+
+	c := NewClusterConfigFrom(config.ClusterConfig{
+		Name:             "prod",
+		KubeconfigSecret: config.SecretKeyRef{Namespace: "k6s", Name: "prod-kubeconfig", Key: "config"},
+	})
+	restConfig, err := c.GetRestConfig()
+	if err != nil {
+		t.Fatalf("GetRestConfig() error = %v", err)
+	}
+	if restConfig.Host != "https://prod.example.com" {
+		t.Errorf("Expected host from the secret, got %s", restConfig.Host)
+	}
+	if restConfig.BearerToken != "test" {
+		t.Errorf("Expected token from the secret, got %q", restConfig.BearerToken)
+	}
+
+	// The default key doesn't exist in this Secret
+	c = NewClusterConfigFrom(config.ClusterConfig{
+		Name:             "prod",
+		KubeconfigSecret: config.SecretKeyRef{Namespace: "k6s", Name: "prod-kubeconfig"},
+	})
+	if _, err := c.GetRestConfig(); err == nil {
+		t.Error("Expected an error for a missing key")
+	}
+}
+
+func TestSecretWatcher(t *testing.T) {
+	client := fake.NewSimpleClientset(newKubeconfigSecret(config.DefaultKubeconfigSecretKey, "https://old.example.com"))
+	useSecretClient(t, client)
+
+	var changed []string
+	watcher := NewSecretWatcher(0, func(c config.ClusterConfig) {
+		changed = append(changed, c.Name)
+	})
+	watcher.SetClusters([]config.ClusterConfig{
+		{Name: "prod", KubeconfigSecret: config.SecretKeyRef{Namespace: "k6s", Name: "prod-kubeconfig"}},
+		{Name: "local", KubeConfig: "/tmp/kubeconfig"},
+	})
+
+	ctx := context.Background()
+	watcher.Check(ctx)
+	watcher.Check(ctx)
+	if len(changed) != 0 {
+		t.Fatalf("Expected no change before the secret is updated, got %v", changed)
+	}
+
+	secret := newKubeconfigSecret(config.DefaultKubeconfigSecretKey, "https://new.example.com")
+	if _, err := client.CoreV1().Secrets("k6s").Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	watcher.Check(ctx)
+	if len(changed) != 1 || changed[0] != "prod" {
+		t.Fatalf("Expected prod to change, got %v", changed)
+	}
+
+	watcher.Check(ctx)
+	if len(changed) != 1 {
+		t.Errorf("Expected a single change notification, got %v", changed)
+	}
+
+	// A cluster whose reference changes starts over without a notification
+	watcher.SetClusters([]config.ClusterConfig{
+		{Name: "prod", KubeconfigSecret: config.SecretKeyRef{Namespace: "k6s", Name: "prod-kubeconfig", Key: "other"}},
+	})
+	if _, exists := watcher.hashes["prod"]; exists {
+		t.Error("Expected the hash of a cluster with a changed reference to be dropped")
+	}
+}
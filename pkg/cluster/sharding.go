@@ -0,0 +1,298 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	k6skube "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coordinationclient "k8s.io/client-go/kubernetes/typed/coordination/v1"
+)
+
+// ShardGroupLabel labels the Leases of the replicas sharing the clusters
+const ShardGroupLabel = "k6s.io/shard-group"
+
+// shardReleaseTimeout bounds deleting the Lease of a replica that stops
+const shardReleaseTimeout = 5 * time.Second
+
+// Sharder splits the clusters between the replicas of a shard group. Every
+// replica renews a Lease of its own, labeled with the group; the replicas
+// whose Lease has not expired are the members, and each cluster belongs to
+// the member with the highest rendezvous hash for it. A replica joining or
+// leaving only moves the clusters it gains or held.
+type Sharder struct {
+	leases        coordinationclient.LeasesGetter
+	namespace     string
+	group         string
+	identity      string
+	interval      time.Duration
+	leaseDuration time.Duration
+	log           *logger.Logger
+
+	// now returns the current time, replaced in tests
+	now func() time.Time
+
+	mu        sync.RWMutex
+	members   []string
+	lastRenew time.Time
+	onChange  func()
+}
+
+// NewSharder creates a sharder renewing the Lease of identity in the
+// namespace of cfg, or of the pod when cfg has none
+func NewSharder(leases coordinationclient.LeasesGetter, cfg config.ShardingConfig, identity string) *Sharder {
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = k6skube.InClusterNamespace()
+	}
+	return &Sharder{
+		leases:        leases,
+		namespace:     namespace,
+		group:         cfg.Group,
+		identity:      identity,
+		interval:      cfg.Interval,
+		leaseDuration: cfg.LeaseDuration,
+		log:           logger.WithComponent("sharding"),
+		now:           time.Now,
+	}
+}
+
+// OnChange sets the function called after the members change, which moves
+// clusters between the replicas
+func (s *Sharder) OnChange(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// Join renews the Lease of this replica and reads the members, so that the
+// clusters can be assigned before Start
+func (s *Sharder) Join(ctx context.Context) error {
+	if err := s.renew(ctx); err != nil {
+		return fmt.Errorf("failed to renew shard lease: %w", err)
+	}
+	members, err := s.listMembers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list shard members: %w", err)
+	}
+	s.setMembers(members)
+	return nil
+}
+
+// Start renews the Lease and reads the members every interval until ctx is
+// done, then deletes the Lease so that the other replicas take over the
+// clusters of this one right away
+func (s *Sharder) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	defer s.release()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.refresh(ctx)
+	}
+}
+
+// Owns reports whether a cluster belongs to this replica
+func (s *Sharder) Owns(cluster string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return ShardOwner(s.members, cluster) == s.identity
+}
+
+// Members returns the identities of the replicas sharing the clusters
+func (s *Sharder) Members() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.members)
+}
+
+// Identity returns the identity of this replica
+func (s *Sharder) Identity() string {
+	return s.identity
+}
+
+// ShardOwner returns the member a cluster belongs to, the one with the
+// highest hash of member and cluster, or "" without members
+func ShardOwner(members []string, cluster string) string {
+	owner := ""
+	var highest uint64
+	for _, member := range members {
+		h := fnv.New64a()
+		h.Write([]byte(member))
+		h.Write([]byte{'/'})
+		h.Write([]byte(cluster))
+		if weight := mix64(h.Sum64()); owner == "" || weight > highest || (weight == highest && member < owner) {
+			owner, highest = member, weight
+		}
+	}
+	return owner
+}
+
+// mix64 spreads the bits of an FNV hash, whose high bits barely change with
+// the last bytes hashed, so that every member wins a fair share of clusters
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// refresh renews the Lease and reads the members. A replica that could not
+// renew its Lease for the lease duration has been dropped by the others, so
+// it gives up its clusters until it renews again.
+func (s *Sharder) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+
+	if err := s.renew(ctx); err != nil {
+		s.log.Error("Failed to renew shard lease", err, map[string]interface{}{"group": s.group})
+		s.mu.RLock()
+		expired := s.now().Sub(s.lastRenew) >= s.leaseDuration
+		s.mu.RUnlock()
+		if expired {
+			s.setMembers(nil)
+		}
+		return
+	}
+
+	members, err := s.listMembers(ctx)
+	if err != nil {
+		s.log.Error("Failed to list shard members", err, map[string]interface{}{"group": s.group})
+		return
+	}
+	s.setMembers(members)
+}
+
+// setMembers replaces the members, calling the change function when they
+// changed
+func (s *Sharder) setMembers(members []string) {
+	s.mu.Lock()
+	changed := !slices.Equal(s.members, members)
+	previous := s.members
+	s.members = members
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	metrics.ShardMembers.Set(float64(len(members)))
+	if !changed {
+		return
+	}
+	s.log.Info("Shard members changed", map[string]interface{}{
+		"group":    s.group,
+		"members":  strings.Join(members, ","),
+		"previous": strings.Join(previous, ","),
+	})
+	if onChange != nil {
+		onChange()
+	}
+}
+
+// renew creates or renews the Lease of this replica
+func (s *Sharder) renew(ctx context.Context) error {
+	now := metav1.NewMicroTime(s.now())
+	seconds := int32(s.leaseDuration / time.Second)
+	leases := s.leases.Leases(s.namespace)
+
+	lease, err := leases.Get(ctx, s.leaseName(), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.leaseName(),
+				Namespace: s.namespace,
+				Labels:    map[string]string{ShardGroupLabel: s.group},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &s.identity,
+				LeaseDurationSeconds: &seconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+	case err == nil:
+		lease.Spec.HolderIdentity = &s.identity
+		lease.Spec.LeaseDurationSeconds = &seconds
+		lease.Spec.RenewTime = &now
+		_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.lastRenew = now.Time
+	s.mu.Unlock()
+	return nil
+}
+
+// listMembers returns the sorted identities holding an unexpired Lease of
+// the group
+func (s *Sharder) listMembers(ctx context.Context) ([]string, error) {
+	selector := labels.SelectorFromSet(labels.Set{ShardGroupLabel: s.group}).String()
+	list, err := s.leases.Leases(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	members := make([]string, 0, len(list.Items))
+	for _, lease := range list.Items {
+		spec := lease.Spec
+		if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || spec.RenewTime == nil {
+			continue
+		}
+		duration := s.leaseDuration
+		if spec.LeaseDurationSeconds != nil {
+			duration = time.Duration(*spec.LeaseDurationSeconds) * time.Second
+		}
+		if spec.RenewTime.Add(duration).After(now) && !slices.Contains(members, *spec.HolderIdentity) {
+			members = append(members, *spec.HolderIdentity)
+		}
+	}
+	slices.Sort(members)
+	return members, nil
+}
+
+// release deletes the Lease of this replica
+func (s *Sharder) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), shardReleaseTimeout)
+	defer cancel()
+	err := s.leases.Leases(s.namespace).Delete(ctx, s.leaseName(), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		s.log.Error("Failed to release shard lease", err, map[string]interface{}{"group": s.group})
+	}
+}
+
+// leaseName returns the name of the Lease of this replica
+func (s *Sharder) leaseName() string {
+	name := strings.ToLower(s.group + "-" + s.identity)
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return strings.Trim(name, "-.")
+}
@@ -0,0 +1,112 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSharder(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+	cfg := config.ShardingConfig{Enabled: true, Group: "k6s-controller", Namespace: "k6s", Interval: 10 * time.Second, LeaseDuration: 40 * time.Second}
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	newReplica := func(identity string) *Sharder {
+		s := NewSharder(clientset.CoordinationV1(), cfg, identity)
+		s.now = func() time.Time { return now }
+		if err := s.Join(ctx); err != nil {
+			t.Fatalf("Join(%s) error = %v", identity, err)
+		}
+		return s
+	}
+	a := newReplica("k6s-a")
+	b := newReplica("k6s-b")
+	changes := 0
+	a.OnChange(func() { changes++ })
+	a.refresh(ctx)
+	if !slices.Equal(a.Members(), []string{"k6s-a", "k6s-b"}) || changes != 1 {
+		t.Fatalf("Expected both replicas as members after one change, got %v and %d changes", a.Members(), changes)
+	}
+
+	clusters := make([]string, 20)
+	for i := range clusters {
+		clusters[i] = fmt.Sprintf("cluster-%02d", i)
+	}
+	ownedBy := func(replicas ...*Sharder) map[string]string {
+		owners := make(map[string]string)
+		for _, cluster := range clusters {
+			for _, r := range replicas {
+				if r.Owns(cluster) {
+					if owner, exists := owners[cluster]; exists {
+						t.Errorf("Cluster %s owned by %s and %s", cluster, owner, r.identity)
+					}
+					owners[cluster] = r.identity
+				}
+			}
+			if _, exists := owners[cluster]; !exists {
+				t.Errorf("Cluster %s owned by no replica", cluster)
+			}
+		}
+		return owners
+	}
+	before := ownedBy(a, b)
+
+	// A third replica only takes clusters, never moving them between the others
+	c := newReplica("k6s-c")
+	a.refresh(ctx)
+	b.refresh(ctx)
+	after := ownedBy(a, b, c)
+	for cluster, owner := range after {
+		if owner != before[cluster] && owner != "k6s-c" {
+			t.Errorf("Cluster %s moved from %s to %s", cluster, before[cluster], owner)
+		}
+	}
+
+	// A replica that stops renewing its Lease is dropped once it expires, and
+	// one that stops releases its Lease right away
+	now = now.Add(cfg.LeaseDuration)
+	a.refresh(ctx)
+	b.refresh(ctx)
+	ctxC, cancel := context.WithCancel(ctx)
+	cancel()
+	c.Start(ctxC)
+	a.refresh(ctx)
+	if !slices.Equal(a.Members(), []string{"k6s-a", "k6s-b"}) {
+		t.Errorf("Expected the stopped replica to leave, got %v", a.Members())
+	}
+	if got := ownedBy(a, b); len(got) != len(clusters) {
+		t.Errorf("Expected every cluster owned, got %v", got)
+	}
+}
+
+func TestShardOwner(t *testing.T) {
+	if owner := ShardOwner(nil, "prod"); owner != "" {
+		t.Errorf("Expected no owner without members, got %q", owner)
+	}
+	members := []string{"k6s-a", "k6s-b", "k6s-c"}
+	owner := ShardOwner(members, "prod")
+	if !slices.Contains(members, owner) {
+		t.Fatalf("Unexpected owner %q", owner)
+	}
+	// The owner doesn't depend on the order of the members
+	if reversed := ShardOwner([]string{"k6s-c", "k6s-b", "k6s-a"}, "prod"); reversed != owner {
+		t.Errorf("Expected %q regardless of order, got %q", owner, reversed)
+	}
+
+	// Every member owns a fair share of the clusters
+	counts := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		counts[ShardOwner(members, fmt.Sprintf("cluster-%d", i))]++
+	}
+	for _, member := range members {
+		if counts[member] < 75 {
+			t.Errorf("Expected about 100 of 300 clusters owned by %s, got %d", member, counts[member])
+		}
+	}
+}
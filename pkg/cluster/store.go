@@ -0,0 +1,263 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ErrClusterNotFound is returned when a cluster is not in the configuration
+	ErrClusterNotFound = errors.New("cluster not found")
+
+	// ErrClusterExists is returned when adding a cluster whose name is taken
+	ErrClusterExists = errors.New("cluster already exists")
+)
+
+// ConfigStore edits the clusters of a configuration file, leaving the rest of
+// the file untouched. It is shared by the cluster CLI and the server API.
+type ConfigStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewConfigStore creates a store for the configuration file at path
+// (the default configuration path when empty)
+func NewConfigStore(path string) *ConfigStore {
+	if path == "" {
+		path = config.GetDefaultConfigPath()
+	}
+	return &ConfigStore{path: path}
+}
+
+// Path returns the configuration file edited by the store
+func (s *ConfigStore) Path() string {
+	return s.path
+}
+
+// List returns the configured clusters
+func (s *ConfigStore) List() ([]config.ClusterConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, clusters, err := s.load()
+	return clusters, err
+}
+
+// Get returns the configuration of a cluster
+func (s *ConfigStore) Get(name string) (config.ClusterConfig, error) {
+	clusters, err := s.List()
+	if err != nil {
+		return config.ClusterConfig{}, err
+	}
+	if i := indexOf(clusters, name); i >= 0 {
+		return clusters[i], nil
+	}
+	return config.ClusterConfig{}, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+}
+
+// Add adds a cluster. An empty kubeconfig resolves to DefaultKubeconfigPath
+// unless the kubeconfig is read from a Secret, and a primary cluster takes
+// over the primary designation.
+func (s *ConfigStore) Add(c config.ClusterConfig) (config.ClusterConfig, error) {
+	if c.KubeConfig == "" && !c.KubeconfigSecret.IsSet() {
+		path, err := DefaultKubeconfigPath()
+		if err != nil {
+			return config.ClusterConfig{}, err
+		}
+		c.KubeConfig = path
+	}
+	if err := config.ValidateCluster(c); err != nil {
+		return config.ClusterConfig{}, err
+	}
+
+	err := s.update(func(clusters []config.ClusterConfig) ([]config.ClusterConfig, error) {
+		if indexOf(clusters, c.Name) >= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrClusterExists, c.Name)
+		}
+		if c.Primary {
+			for i := range clusters {
+				clusters[i].Primary = false
+			}
+		}
+		return append(clusters, c), nil
+	})
+	if err != nil {
+		return config.ClusterConfig{}, err
+	}
+	return c, nil
+}
+
+// Delete removes a cluster
+func (s *ConfigStore) Delete(name string) error {
+	return s.update(func(clusters []config.ClusterConfig) ([]config.ClusterConfig, error) {
+		i := indexOf(clusters, name)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+		}
+		return append(clusters[:i], clusters[i+1:]...), nil
+	})
+}
+
+// SetEnabled enables or disables a cluster
+func (s *ConfigStore) SetEnabled(name string, enabled bool) (config.ClusterConfig, error) {
+	var updated config.ClusterConfig
+	err := s.update(func(clusters []config.ClusterConfig) ([]config.ClusterConfig, error) {
+		i := indexOf(clusters, name)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+		}
+		clusters[i].Enabled = enabled
+		updated = clusters[i]
+		return clusters, nil
+	})
+	return updated, err
+}
+
+// SetPrimary makes a cluster the only primary cluster
+func (s *ConfigStore) SetPrimary(name string) (config.ClusterConfig, error) {
+	var updated config.ClusterConfig
+	err := s.update(func(clusters []config.ClusterConfig) ([]config.ClusterConfig, error) {
+		i := indexOf(clusters, name)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, name)
+		}
+		for j := range clusters {
+			clusters[j].Primary = j == i
+		}
+		updated = clusters[i]
+		return clusters, nil
+	})
+	return updated, err
+}
+
+// update applies fn to the configured clusters and writes them back unless fn fails
+func (s *ConfigStore) update(fn func(clusters []config.ClusterConfig) ([]config.ClusterConfig, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, clusters, err := s.load()
+	if err != nil {
+		return err
+	}
+	clusters, err = fn(clusters)
+	if err != nil {
+		return err
+	}
+	return s.save(setClusters(doc, clusters))
+}
+
+// load reads the configuration file as a generic document, so that saving it
+// keeps the other settings exactly as written instead of persisting zero or
+// default values. A missing file yields an empty document.
+func (s *ConfigStore) load() (yaml.MapSlice, []config.ClusterConfig, error) {
+	data, err := os.ReadFile(s.path) // #nosec G304 - path is chosen by the operator
+	if os.IsNotExist(err) {
+		return yaml.MapSlice{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	var cfg config.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return doc, cfg.MultiCluster.Clusters, nil
+}
+
+// save writes the configuration file by renaming a temporary file over it,
+// so that a watching config reloader never reads a partial file
+func (s *ConfigStore) save(doc yaml.MapSlice) error {
+	if err := config.EnsureConfigDir(s.path); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// setClusters replaces multi_cluster.clusters in a configuration document
+func setClusters(doc yaml.MapSlice, clusters []config.ClusterConfig) yaml.MapSlice {
+	multiCluster := yaml.MapSlice{}
+	i := keyIndex(doc, "multi_cluster")
+	if i >= 0 {
+		if section, ok := doc[i].Value.(yaml.MapSlice); ok {
+			multiCluster = section
+		}
+	}
+
+	if j := keyIndex(multiCluster, "clusters"); j >= 0 {
+		multiCluster[j].Value = clusters
+	} else {
+		multiCluster = append(multiCluster, yaml.MapItem{Key: "clusters", Value: clusters})
+	}
+
+	if i >= 0 {
+		doc[i].Value = multiCluster
+	} else {
+		doc = append(doc, yaml.MapItem{Key: "multi_cluster", Value: multiCluster})
+	}
+	return doc
+}
+
+// keyIndex returns the index of a key in a YAML mapping, or -1
+func keyIndex(m yaml.MapSlice, key string) int {
+	for i, item := range m {
+		if k, ok := item.Key.(string); ok && k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// DefaultKubeconfigPath returns $KUBECONFIG, or ~/.kube/config when unset
+func DefaultKubeconfigPath() (string, error) {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return env, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// indexOf returns the index of the named cluster, or -1
+func indexOf(clusters []config.ClusterConfig, name string) int {
+	for i, c := range clusters {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
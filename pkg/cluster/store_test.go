@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+)
+
+func TestConfigStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k6s.yaml")
+	if err := os.WriteFile(path, []byte("log_level: debug\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store := NewConfigStore(path)
+
+	if _, err := store.Add(config.ClusterConfig{Name: "prod", KubeConfig: "/etc/k6s/prod.yaml", Enabled: true, Primary: true}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := store.Add(config.ClusterConfig{Name: "dev", KubeConfig: "/etc/k6s/dev.yaml", Primary: true}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := store.Add(config.ClusterConfig{Name: "dev", KubeConfig: "/etc/k6s/dev.yaml"}); !errors.Is(err, ErrClusterExists) {
+		t.Errorf("Expected ErrClusterExists, got %v", err)
+	}
+	if _, err := store.Add(config.ClusterConfig{Name: "edge", KubeConfig: "/etc/k6s/edge.yaml", ProxyURL: "ftp://proxy"}); err == nil {
+		t.Error("Expected an invalid proxy URL to be rejected")
+	}
+
+	// Adding a primary cluster takes over the designation
+	prod, err := store.Get("prod")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if prod.Primary {
+		t.Error("Expected prod to lose the primary designation")
+	}
+
+	if _, err := store.SetEnabled("dev", true); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	if _, err := store.SetPrimary("prod"); err != nil {
+		t.Fatalf("SetPrimary() error = %v", err)
+	}
+	if err := store.Delete("dev"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete("dev"); !errors.Is(err, ErrClusterNotFound) {
+		t.Errorf("Expected ErrClusterNotFound, got %v", err)
+	}
+
+	clusters, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].Name != "prod" || !clusters[0].Primary {
+		t.Errorf("Expected only prod as primary, got %+v", clusters)
+	}
+
+	// Settings outside the clusters are kept as written, and the file still loads
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "log_level: debug") || strings.Contains(string(data), "connection_timeout") {
+		t.Errorf("Expected only the clusters to change, got:\n%s", data)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected saved config to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+// Package compliance scans the images of deployments against registry policy
+// rules: disallowed registries, latest tags and missing digests.
+package compliance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/policy"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Severities of findings, from the most to the least severe. A rule with
+// severity none is disabled.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityNone     = "none"
+)
+
+// Rules images are checked against
+const (
+	RuleRegistry      = "registry"
+	RuleLatestTag     = "latest-tag"
+	RuleMissingDigest = "missing-digest"
+)
+
+// Finding is an image of a deployment container breaking a rule
+type Finding struct {
+	Namespace  string `json:"namespace"`
+	Deployment string `json:"deployment"`
+	Container  string `json:"container"`
+	Image      string `json:"image"`
+	Rule       string `json:"rule"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+}
+
+// Report is the result of scanning deployments. Findings are sorted from
+// the most severe, then by deployment and container.
+type Report struct {
+	CheckedAt   time.Time      `json:"checkedAt"`
+	Deployments int            `json:"deployments"`
+	Compliant   int            `json:"compliant"`
+	Findings    []Finding      `json:"findings"`
+	Severities  map[string]int `json:"severities"`
+	Errors      []string       `json:"errors,omitempty"`
+}
+
+// Rank orders severities, critical being the highest; an unknown severity
+// ranks 0, like none
+func Rank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 4
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	}
+	return 0
+}
+
+// ParseSeverity validates a severity given as a minimum, such as a query
+// parameter or flag; empty is returned as low, keeping every finding
+func ParseSeverity(severity string) (string, error) {
+	severity = strings.ToLower(severity)
+	if severity == "" {
+		return SeverityLow, nil
+	}
+	if Rank(severity) == 0 {
+		return "", fmt.Errorf("invalid severity %q (use critical, high, medium or low)", severity)
+	}
+	return severity, nil
+}
+
+// Evaluate returns the findings of the containers and init containers of a
+// deployment
+func Evaluate(dep *appsv1.Deployment, cfg config.ComplianceConfig) []Finding {
+	var findings []Finding
+	containers := append(append([]corev1.Container{}, dep.Spec.Template.Spec.InitContainers...), dep.Spec.Template.Spec.Containers...)
+	for _, container := range containers {
+		for _, violation := range evaluateImage(container.Image, cfg) {
+			violation.Namespace = dep.Namespace
+			violation.Deployment = dep.Name
+			violation.Container = container.Name
+			violation.Image = container.Image
+			findings = append(findings, violation)
+		}
+	}
+	return findings
+}
+
+// evaluateImage returns the rules an image breaks, with their severity and
+// message
+func evaluateImage(image string, cfg config.ComplianceConfig) []Finding {
+	var findings []Finding
+	add := func(rule, severity, message string) {
+		if Rank(severity) > 0 {
+			findings = append(findings, Finding{Rule: rule, Severity: severity, Message: message})
+		}
+	}
+
+	switch {
+	case policy.Allowed(image, cfg.DisallowedRegistries):
+		add(RuleRegistry, cfg.RegistrySeverity, fmt.Sprintf("%s is pulled from a disallowed registry", policy.Reference(image)))
+	case len(cfg.AllowedRegistries) > 0 && !policy.Allowed(image, cfg.AllowedRegistries):
+		add(RuleRegistry, cfg.RegistrySeverity, fmt.Sprintf("%s is not pulled from an allowed registry", policy.Reference(image)))
+	}
+
+	tag, digest := imageTag(image)
+	switch {
+	case digest != "":
+		// A digest pins the image whatever its tag
+	case tag == "latest":
+		add(RuleLatestTag, cfg.LatestTagSeverity, "image is tagged latest")
+	case tag == "":
+		add(RuleLatestTag, cfg.LatestTagSeverity, "image has no tag and defaults to latest")
+	}
+	if digest == "" {
+		add(RuleMissingDigest, cfg.MissingDigestSeverity, "image is not pinned by digest")
+	}
+	return findings
+}
+
+// imageTag returns the tag and digest of an image, empty when it has none
+func imageTag(image string) (tag, digest string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image, digest = image[:i], image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		tag = image[i+1:]
+	}
+	return tag, digest
+}
+
+// Scan evaluates deployments and returns the report of their findings
+func Scan(deployments []*appsv1.Deployment, cfg config.ComplianceConfig) Report {
+	report := Report{CheckedAt: time.Now().UTC(), Findings: []Finding{}}
+	for _, dep := range deployments {
+		findings := Evaluate(dep, cfg)
+		if len(findings) == 0 {
+			report.Compliant++
+		}
+		report.Deployments++
+		report.Findings = append(report.Findings, findings...)
+	}
+	sortFindings(report.Findings)
+	report.Severities = countSeverities(report.Findings)
+	return report
+}
+
+// Filter returns a copy of report keeping the findings of namespace, when
+// set, of at least severity minimum
+func Filter(report Report, namespace, minimum string) Report {
+	findings := []Finding{}
+	for _, finding := range report.Findings {
+		if (namespace == "" || finding.Namespace == namespace) && Rank(finding.Severity) >= Rank(minimum) {
+			findings = append(findings, finding)
+		}
+	}
+	report.Findings = findings
+	report.Severities = countSeverities(findings)
+	return report
+}
+
+// sortFindings sorts findings from the most severe, then by namespace,
+// deployment, container and rule
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if Rank(a.Severity) != Rank(b.Severity) {
+			return Rank(a.Severity) > Rank(b.Severity)
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Deployment != b.Deployment {
+			return a.Deployment < b.Deployment
+		}
+		if a.Container != b.Container {
+			return a.Container < b.Container
+		}
+		return a.Rule < b.Rule
+	})
+}
+
+// countSeverities returns the number of findings of each severity
+func countSeverities(findings []Finding) map[string]int {
+	counts := map[string]int{}
+	for _, finding := range findings {
+		counts[finding.Severity]++
+	}
+	return counts
+}
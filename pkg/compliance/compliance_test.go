@@ -0,0 +1,140 @@
+package compliance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testConfig returns the default rules with docker.io disallowed
+func testConfig() config.ComplianceConfig {
+	cfg := config.DefaultConfig().Controller.Compliance
+	cfg.Enabled = true
+	cfg.DisallowedRegistries = []string{"docker.io"}
+	return cfg
+}
+
+// newDeployment returns a deployment running one container per image
+func newDeployment(namespace, name string, images ...string) *appsv1.Deployment {
+	replicas := int32(1)
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	for i, image := range images {
+		dep.Spec.Template.Spec.Containers = append(dep.Spec.Template.Spec.Containers, corev1.Container{
+			Name:  []string{"app", "sidecar", "proxy"}[i],
+			Image: image,
+		})
+	}
+	return dep
+}
+
+func TestEvaluateImage(t *testing.T) {
+	digest := "@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		image string
+		rules []string
+	}{
+		{"ghcr.io/acme/web:1.2.3" + digest, nil},
+		{"ghcr.io/acme/web:1.2.3", []string{RuleMissingDigest}},
+		{"ghcr.io/acme/web:latest", []string{RuleLatestTag, RuleMissingDigest}},
+		{"ghcr.io/acme/web:latest" + digest, nil},
+		{"localhost:5000/web", []string{RuleLatestTag, RuleMissingDigest}},
+		{"nginx:1.27", []string{RuleRegistry, RuleMissingDigest}},
+		{"nginx", []string{RuleRegistry, RuleLatestTag, RuleMissingDigest}},
+	}
+	for _, tt := range tests {
+		var rules []string
+		for _, finding := range evaluateImage(tt.image, testConfig()) {
+			rules = append(rules, finding.Rule)
+		}
+		if len(rules) != len(tt.rules) {
+			t.Errorf("evaluateImage(%q) = %v, want %v", tt.image, rules, tt.rules)
+			continue
+		}
+		for i := range rules {
+			if rules[i] != tt.rules[i] {
+				t.Errorf("evaluateImage(%q) = %v, want %v", tt.image, rules, tt.rules)
+				break
+			}
+		}
+	}
+
+	cfg := testConfig()
+	cfg.DisallowedRegistries = nil
+	cfg.AllowedRegistries = []string{"ghcr.io/acme"}
+	cfg.MissingDigestSeverity = SeverityNone
+	if findings := evaluateImage("ghcr.io/other/web:1.0", cfg); len(findings) != 1 || findings[0].Rule != RuleRegistry || findings[0].Severity != SeverityCritical {
+		t.Errorf("Expected a critical registry finding outside the allowed registries, got %+v", findings)
+	}
+	if findings := evaluateImage("ghcr.io/acme/web:1.0", cfg); len(findings) != 0 {
+		t.Errorf("Expected no findings with the digest rule disabled, got %+v", findings)
+	}
+}
+
+func TestScanAndFilter(t *testing.T) {
+	deployments := []*appsv1.Deployment{
+		newDeployment("shop", "web", "ghcr.io/acme/web:1.0", "nginx:latest"),
+		newDeployment("shop", "api", "ghcr.io/acme/api@sha256:abc"),
+		newDeployment("ops", "agent", "ghcr.io/acme/agent:2.0"),
+	}
+	report := Scan(deployments, testConfig())
+	if report.Deployments != 3 || report.Compliant != 1 {
+		t.Errorf("Expected 1 of 3 deployments compliant, got %d of %d", report.Compliant, report.Deployments)
+	}
+	if len(report.Findings) != 5 {
+		t.Fatalf("Expected 5 findings, got %+v", report.Findings)
+	}
+	first := report.Findings[0]
+	if first.Severity != SeverityCritical || first.Deployment != "web" || first.Container != "sidecar" || first.Image != "nginx:latest" {
+		t.Errorf("Expected the critical registry finding first, got %+v", first)
+	}
+	if report.Severities[SeverityCritical] != 1 || report.Severities[SeverityHigh] != 1 || report.Severities[SeverityLow] != 3 {
+		t.Errorf("Unexpected severity counts %v", report.Severities)
+	}
+
+	filtered := Filter(report, "shop", SeverityHigh)
+	if len(filtered.Findings) != 2 || filtered.Severities[SeverityLow] != 0 {
+		t.Errorf("Expected the critical and high findings of shop, got %+v", filtered.Findings)
+	}
+	if len(report.Findings) != 5 {
+		t.Errorf("Filter modified the report")
+	}
+
+	if _, err := ParseSeverity("urgent"); err == nil {
+		t.Error("Expected an error for an unknown severity")
+	}
+	if severity, err := ParseSeverity("HIGH"); err != nil || severity != SeverityHigh {
+		t.Errorf("ParseSeverity(HIGH) = %q, %v", severity, err)
+	}
+}
+
+func TestScannerCheck(t *testing.T) {
+	deployments := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(newDeployment("shop", "web", "nginx")), "", time.Minute)
+	scanner := NewScanner(deployments, testConfig())
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+
+	if _, checked := scanner.Report(); checked {
+		t.Error("Expected no report before the first scan")
+	}
+	scanner.Check()
+	report, checked := scanner.Report()
+	if !checked || len(report.Findings) != 3 {
+		t.Errorf("Expected 3 findings after a scan, got %+v", report)
+	}
+
+	scanner.SetConfig(config.ComplianceConfig{})
+	if _, checked := scanner.Report(); checked || scanner.Enabled() {
+		t.Error("Expected no report once scanning is disabled")
+	}
+}
@@ -0,0 +1,148 @@
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+)
+
+// defaultInterval is used when no interval is configured
+const defaultInterval = 5 * time.Minute
+
+// Scanner periodically scans the images of the cached deployments and logs
+// a warning when a deployment starts to break a rule
+type Scanner struct {
+	deployments *kubernetes.DeploymentInformer
+	log         *logger.Logger
+
+	mu      sync.RWMutex
+	cfg     config.ComplianceConfig
+	report  Report
+	checked bool
+
+	// Deployments alarmed on, keyed by namespace/name
+	alarms map[string]bool
+}
+
+// NewScanner creates a scanner of the deployments of informer
+func NewScanner(deployments *kubernetes.DeploymentInformer, cfg config.ComplianceConfig) *Scanner {
+	return &Scanner{
+		deployments: deployments,
+		cfg:         cfg,
+		alarms:      make(map[string]bool),
+		log:         logger.WithComponent("compliance"),
+	}
+}
+
+// SetConfig replaces the rules and interval, taking effect at the next scan
+func (s *Scanner) SetConfig(cfg config.ComplianceConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+	if !cfg.Enabled {
+		s.checked = false
+	}
+}
+
+// Enabled reports whether image compliance scanning is enabled
+func (s *Scanner) Enabled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.Enabled
+}
+
+// Report returns the result of the last scan, and false before the first
+// one or while scanning is disabled
+func (s *Scanner) Report() (Report, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.report, s.checked
+}
+
+// Start scans the deployments every interval while scanning is enabled,
+// until ctx is cancelled
+func (s *Scanner) Start(ctx context.Context) {
+	for {
+		s.mu.RLock()
+		enabled, interval := s.cfg.Enabled, s.cfg.Interval
+		s.mu.RUnlock()
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+
+		if enabled && s.deployments.HasSynced() {
+			s.Check()
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Check scans the cached deployments once, raises alarms for new findings
+// and stores the report
+func (s *Scanner) Check() Report {
+	s.mu.RLock()
+	cfg := s.cfg
+	s.mu.RUnlock()
+
+	deployments, err := s.deployments.ListDeployments()
+	report := Scan(deployments, cfg)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("listing deployments: %v", err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.raiseAlarms(report)
+	s.report = report
+	s.checked = true
+	return report
+}
+
+// raiseAlarms logs deployments that started or stopped breaking rules since
+// the previous scan. The caller must hold s.mu.
+func (s *Scanner) raiseAlarms(report Report) {
+	rules := make(map[string][]string)
+	severities := make(map[string]string)
+	for _, finding := range report.Findings {
+		key := finding.Namespace + "/" + finding.Deployment
+		rules[key] = append(rules[key], finding.Container+": "+finding.Rule)
+		// Findings are sorted from the most severe
+		if _, exists := severities[key]; !exists {
+			severities[key] = finding.Severity
+		}
+	}
+
+	current := make(map[string]bool, len(rules))
+	for key, broken := range rules {
+		current[key] = true
+		if s.alarms[key] {
+			continue
+		}
+		s.log.Warn("Deployment images break compliance rules", map[string]interface{}{
+			"deployment": key,
+			"severity":   severities[key],
+			"rules":      broken,
+		})
+	}
+
+	for key := range s.alarms {
+		if !current[key] {
+			s.log.Info("Deployment images are compliant again", map[string]interface{}{
+				"deployment": key,
+			})
+		}
+	}
+	s.alarms = current
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -37,12 +39,34 @@ type Config struct {
 	// General configuration
 	LogLevel string `yaml:"log_level" json:"log_level"`
 
+	// Log levels of components
+	Log LogConfig `yaml:"log" json:"log"`
+
+	// Large-cluster mode applies the bounded memory preset (see ApplyPresets)
+	LargeClusterMode bool `yaml:"large_cluster_mode" json:"large_cluster_mode"`
+
 	// Controller configuration
 	Controller ControllerConfig `yaml:"controller" json:"controller"`
 
 	// Multi-cluster configuration
 	MultiCluster MultiClusterConfig `yaml:"multi_cluster" json:"multi_cluster"`
 
+	// HTTP API server configuration
+	Server ServerConfig `yaml:"server" json:"server"`
+
+	// History store for snapshots of deleted deployments
+	History HistoryConfig `yaml:"history" json:"history"`
+
+	// Delivery of deployment events to Slack, HTTP endpoints and email
+	Notifications NotificationsConfig `yaml:"notifications" json:"notifications"`
+
+	// OpenTelemetry spans of API requests, reconciliations and informer
+	// event handlers
+	Telemetry TelemetryConfig `yaml:"telemetry" json:"telemetry"`
+
+	// Rules of k6s lint
+	Lint LintConfig `yaml:"lint" json:"lint"`
+
 	// Legacy fields for backward compatibility
 	Informer *LegacyInformerConfig `yaml:"informer,omitempty" json:"informer,omitempty"`
 	Watch    *LegacyWatchConfig    `yaml:"watch,omitempty" json:"watch,omitempty"`
@@ -54,6 +78,33 @@ type Config struct {
 	Clusters                   []ClusterConfig `yaml:"clusters,omitempty" json:"clusters,omitempty"`
 }
 
+// LogConfig represents the log levels of components
+type LogConfig struct {
+	// Levels overriding log_level for the loggers of components, such as
+	// informer, server or reconciler, keyed by component
+	Levels map[string]string `yaml:"levels,omitempty" json:"levels,omitempty"`
+
+	// Sampling of the trace, debug and info entries of the informers
+	Sampling LogSamplingConfig `yaml:"sampling" json:"sampling"`
+
+	// Repeated informer entries of a deployment with the same kind of
+	// change within this window are logged once, followed by a "repeated N
+	// times" summary (0 = disabled)
+	DedupWindow time.Duration `yaml:"dedup_window" json:"dedup_window"`
+}
+
+// LogSamplingConfig represents the sampling of log entries in bursts
+type LogSamplingConfig struct {
+	// Entries logged per period before sampling starts (0 = no sampling)
+	Burst uint32 `yaml:"burst" json:"burst"`
+
+	// Period of the burst (0 = 1s)
+	Period time.Duration `yaml:"period" json:"period"`
+
+	// Beyond the burst, every Nth entry is logged (0 = none)
+	Thereafter uint32 `yaml:"thereafter" json:"thereafter"`
+}
+
 // LegacyInformerConfig represents legacy informer configuration for backward compatibility
 type LegacyInformerConfig struct {
 	Namespace             string        `yaml:"namespace" json:"namespace"`
@@ -85,13 +136,336 @@ type ControllerConfig struct {
 
 	// Resync period for informers
 	ResyncPeriod time.Duration `yaml:"resync_period" json:"resync_period"`
+
+	// Informer cache tuning
+	Informer InformerConfig `yaml:"informer" json:"informer"`
+
+	// Kubernetes API client tuning
+	Client KubeClientConfig `yaml:"client" json:"client"`
+
+	// Controller status reporting
+	Status StatusReportConfig `yaml:"status" json:"status"`
+
+	// Drift detection between deployments and their declared manifests
+	Drift ManifestDriftConfig `yaml:"drift" json:"drift"`
+
+	// Scanning of deployment images against registry policy rules
+	Compliance ComplianceConfig `yaml:"compliance" json:"compliance"`
+
+	// Rollout health scoring and stuck rollout detection
+	Rollouts RolloutHealthConfig `yaml:"rollouts" json:"rollouts"`
+
+	// Detection of restart spikes and unexpected scale-to-zero from the
+	// replica and restart trends of deployments
+	Anomalies AnomalyConfig `yaml:"anomalies" json:"anomalies"`
+
+	// Reconciling deployments from the manifests of a Git repository
+	GitOps GitOpsConfig `yaml:"gitops" json:"gitops"`
+
+	// Enforcement of K6sDeploymentPolicy objects
+	Policies DeploymentPolicyConfig `yaml:"policies" json:"policies"`
+
+	// Mutating admission webhook injecting defaults into deployments
+	Webhook WebhookConfig `yaml:"webhook" json:"webhook"`
+
+	// Cleanup hooks run before deployments are deleted
+	Finalizer FinalizerConfig `yaml:"finalizer" json:"finalizer"`
+
+	// Only observe deployments: status annotations are not written and
+	// deployment policies are reported without being enforced
+	ReadOnly bool `yaml:"read_only" json:"read_only"`
+}
+
+// FinalizerConfig represents the k6s.io/finalizer finalizer: the deletion of
+// a deployment carrying it waits until the registered cleanup hooks succeed
+type FinalizerConfig struct {
+	// Run cleanup hooks for deployments carrying the finalizer (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Labels of deployments the finalizer is added to (empty = only
+	// deployments given the finalizer in their manifest)
+	Selector map[string]string `yaml:"selector" json:"selector"`
+
+	// URL notified with a POST of each deleted deployment
+	NotifyURL string `yaml:"notify_url" json:"notify_url"`
+
+	// Delete the copies of propagated deployments of the primary cluster
+	// from the member clusters, in multi-cluster mode
+	RemovePropagatedCopies bool `yaml:"remove_propagated_copies" json:"remove_propagated_copies"`
+}
+
+// WebhookConfig represents the mutating admission webhook that injects
+// default resources, labels and topology spread constraints into the
+// deployments of selected namespaces. Values a deployment sets are kept.
+type WebhookConfig struct {
+	// Serve the webhook (opt-in); a MutatingWebhookConfiguration must point
+	// the API server to it
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Port of the webhook's HTTPS server
+	Port int `yaml:"port" json:"port"`
+
+	// Directory holding tls.crt and tls.key (empty = controller-runtime's
+	// default, /tmp/k8s-webhook-server/serving-certs)
+	CertDir string `yaml:"cert_dir" json:"cert_dir"`
+
+	// Namespaces whose deployments are mutated (empty = all namespaces)
+	Namespaces []string `yaml:"namespaces" json:"namespaces"`
+
+	// Default requests and limits of containers, by resource name
+	Resources DefaultResourcesConfig `yaml:"resources" json:"resources"`
+
+	// Labels added to deployments and their pod templates
+	Labels map[string]string `yaml:"labels" json:"labels"`
+
+	// Topology spread constraint added to pod templates without one
+	TopologySpread TopologySpreadConfig `yaml:"topology_spread" json:"topology_spread"`
+}
+
+// DefaultResourcesConfig represents default container requests and limits,
+// such as cpu: 100m or memory: 128Mi
+type DefaultResourcesConfig struct {
+	Requests map[string]string `yaml:"requests" json:"requests"`
+	Limits   map[string]string `yaml:"limits" json:"limits"`
+}
+
+// TopologySpreadConfig represents the topology spread constraint injected
+// into pod templates, spreading the pods of a deployment across topologies
+type TopologySpreadConfig struct {
+	// Inject the constraint
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Node label defining topologies, such as topology.kubernetes.io/zone
+	TopologyKey string `yaml:"topology_key" json:"topology_key"`
+
+	// Maximum difference of pod counts between topologies
+	MaxSkew int32 `yaml:"max_skew" json:"max_skew"`
+
+	// DoNotSchedule or ScheduleAnyway
+	WhenUnsatisfiable string `yaml:"when_unsatisfiable" json:"when_unsatisfiable"`
+}
+
+// DeploymentPolicyConfig represents enforcing K6sDeploymentPolicy objects:
+// deployments are checked against the policies of their namespace, and the
+// violations are reported in the policy status
+type DeploymentPolicyConfig struct {
+	// Enable deployment policies (opt-in); the K6sDeploymentPolicy CRD must
+	// be installed
+	Enabled bool `yaml:"enabled" json:"enabled"`
+}
+
+// GitOpsConfig represents reconciling deployments from a Git repository of
+// manifests: the repository is polled and its deployments are applied to the
+// primary cluster, and changes made to them in the cluster are reverted
+type GitOpsConfig struct {
+	// Enable GitOps source mode (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// URL or path of the repository, as given to git clone
+	Repository string `yaml:"repository" json:"repository"`
+
+	// Branch to follow (empty = the remote's default branch)
+	Branch string `yaml:"branch" json:"branch"`
+
+	// Directory of deployment manifests within the repository, read
+	// recursively (empty = the whole repository)
+	Path string `yaml:"path" json:"path"`
+
+	// How often the repository is polled and applied
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// Namespace of manifests without one
+	Namespace string `yaml:"namespace" json:"namespace"`
+
+	// Keep polling and reporting the sync status without applying changes
+	Suspend bool `yaml:"suspend" json:"suspend"`
+
+	// Take over fields managed by other field managers
+	ForceConflicts bool `yaml:"force_conflicts" json:"force_conflicts"`
+
+	// Directory the repository is cloned to (empty = k6s-gitops in the
+	// temporary directory)
+	CheckoutDir string `yaml:"checkout_dir" json:"checkout_dir"`
+}
+
+// ManifestDriftConfig represents periodic comparison of the cached
+// deployments with the manifests declared for them: those recorded by
+// "k6s apply" and those of a directory, such as a Git checkout
+type ManifestDriftConfig struct {
+	// Enable manifest drift detection (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// How often deployments are compared with their manifests
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// Directory of deployment manifests, read recursively
+	// (empty = only manifests recorded by k6s apply)
+	Path string `yaml:"path" json:"path"`
+
+	// Namespace of manifests without one
+	Namespace string `yaml:"namespace" json:"namespace"`
+}
+
+// ComplianceConfig represents periodic scanning of the images of the cached
+// deployments against registry policy rules. Each rule reports its findings
+// with a severity: critical, high, medium, low, or none to disable it.
+type ComplianceConfig struct {
+	// Enable image compliance scanning (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// How often the cached deployments are scanned
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// Registries, or registry/repository prefixes such as ghcr.io/acme,
+	// images must not be pulled from
+	DisallowedRegistries []string `yaml:"disallowed_registries" json:"disallowed_registries"`
+
+	// Registries, or registry/repository prefixes, images must be pulled
+	// from (empty = any registry not disallowed)
+	AllowedRegistries []string `yaml:"allowed_registries" json:"allowed_registries"`
+
+	// Severity of images from a disallowed registry, or not from an allowed one
+	RegistrySeverity string `yaml:"registry_severity" json:"registry_severity"`
+
+	// Severity of images tagged latest, or without a tag or digest
+	LatestTagSeverity string `yaml:"latest_tag_severity" json:"latest_tag_severity"`
+
+	// Severity of images not pinned by digest
+	MissingDigestSeverity string `yaml:"missing_digest_severity" json:"missing_digest_severity"`
+}
+
+// RolloutHealthConfig represents periodic scoring of the rollout health of
+// the cached deployments, warning when a rollout gets stuck
+type RolloutHealthConfig struct {
+	// Enable rollout health tracking (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// How often the rollouts of deployments are checked
+	Interval time.Duration `yaml:"interval" json:"interval"`
+}
+
+// AnomalyConfig represents sampling the replicas and pod restarts of the
+// cached deployments into the event history, alerting on restart spikes and
+// deployments unexpectedly scaled to zero
+type AnomalyConfig struct {
+	// Enable anomaly detection (opt-in); requires history.events.enabled
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// How often deployments are sampled
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// How far back samples form the baseline of a deployment
+	Window time.Duration `yaml:"window" json:"window"`
+
+	// Fewest restarts within an interval reported as a spike
+	RestartSpikeMin int `yaml:"restart_spike_min" json:"restart_spike_min"`
+
+	// How many times the average restarts per interval over the window a
+	// spike must exceed
+	RestartSpikeFactor float64 `yaml:"restart_spike_factor" json:"restart_spike_factor"`
+}
+
+// StatusReportConfig represents K6sController status object reporting
+type StatusReportConfig struct {
+	// Maintain a cluster-scoped K6sController object when running in-cluster
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Name of the K6sController object
+	Name string `yaml:"name" json:"name"`
+
+	// How often the status is refreshed
+	Interval time.Duration `yaml:"interval" json:"interval"`
+}
+
+// InformerConfig represents informer cache tuning
+type InformerConfig struct {
+	// Transform applied to objects before they are cached:
+	// "" or "none" keeps full objects, "strip" drops managed fields and
+	// last-applied annotations, "metadata" additionally reduces the pod
+	// template to container names and images
+	Transform string `yaml:"transform" json:"transform"`
+
+	// Skip updates that change neither the spec, status, labels nor
+	// annotations of a deployment, such as resyncs, instead of passing
+	// them to handlers
+	SuppressNoopUpdates bool `yaml:"suppress_noop_updates" json:"suppress_noop_updates"`
+
+	// Deployments per page of the initial list (0 = one response served
+	// from the API server's watch cache)
+	ListChunkSize int64 `yaml:"list_chunk_size" json:"list_chunk_size"`
+
+	// Additional indexes of the deployment cache, queryable with
+	// /api/v1/deployments?index=<name>&value=<value>
+	Indexes []IndexConfig `yaml:"indexes" json:"indexes"`
+
+	// Snapshot of the deployment cache served on start until it has synced
+	Snapshot SnapshotConfig `yaml:"snapshot" json:"snapshot"`
+}
+
+// SnapshotConfig represents the snapshot of the deployment cache the server
+// saves to disk, so that after a restart the API serves deployments at once,
+// marked stale until the cache has synced
+type SnapshotConfig struct {
+	// Snapshot file (empty = no snapshot)
+	Path string `yaml:"path" json:"path"`
+
+	// How often the snapshot is saved; it is also saved on shutdown
+	// (0 = 1m)
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// Oldest snapshot served on start (0 = any age)
+	MaxAge time.Duration `yaml:"max_age" json:"max_age"`
+}
+
+// IndexConfig represents an index of the deployment cache on a field
+type IndexConfig struct {
+	// Name of the index, used to query it
+	Name string `yaml:"name" json:"name"`
+
+	// JSONPath of the indexed field, e.g. metadata.annotations['team'];
+	// keys in quoted brackets may contain dots
+	Field string `yaml:"field" json:"field"`
+}
+
+// quotedKey matches a quoted key in brackets of a JSONPath
+var quotedKey = regexp.MustCompile(`\['([^']*)'\]`)
+
+// JSONPath returns the field as a client-go JSONPath template, escaping the
+// dots of quoted keys, which the template syntax would otherwise read as
+// field separators
+func (c IndexConfig) JSONPath() string {
+	field := strings.TrimSpace(c.Field)
+	if strings.HasPrefix(field, "{") {
+		return field
+	}
+	field = quotedKey.ReplaceAllStringFunc(field, func(key string) string {
+		return strings.ReplaceAll(key, ".", `\.`)
+	})
+	return "{." + strings.TrimPrefix(field, ".") + "}"
+}
+
+// KubeClientConfig tunes the Kubernetes API clients of the server and the
+// controller
+type KubeClientConfig struct {
+	// Sustained requests per second to the API server (0 = client default)
+	QPS float32 `yaml:"qps" json:"qps"`
+
+	// Requests allowed above QPS in bursts (0 = client default)
+	Burst int `yaml:"burst" json:"burst"`
+
+	// Encoding of built-in resources: "protobuf" or "json". Custom
+	// resources are always encoded as JSON.
+	ContentType string `yaml:"content_type" json:"content_type"`
 }
 
 // SingleClusterConfig represents single cluster mode configuration
 type SingleClusterConfig struct {
-	// Namespace to watch (empty = all namespaces)
+	// Namespaces to watch, comma-separated (empty = all namespaces)
 	Namespace string `yaml:"namespace" json:"namespace"`
 
+	// Namespaces not to watch
+	ExcludeNamespaces []string `yaml:"exclude_namespaces" json:"exclude_namespaces"`
+
 	// Metrics configuration
 	MetricsPort int `yaml:"metrics_port" json:"metrics_port"`
 
@@ -102,6 +476,85 @@ type SingleClusterConfig struct {
 	LeaderElection LeaderElectionConfig `yaml:"leader_election" json:"leader_election"`
 }
 
+// NamespaceScope returns the namespaces watched
+func (c SingleClusterConfig) NamespaceScope() NamespaceScope {
+	return NewNamespaceScope(c.Namespace, c.ExcludeNamespaces)
+}
+
+// NamespaceScope selects the namespaces watched: the Include list, or every
+// namespace but the Exclude list when Include is empty
+type NamespaceScope struct {
+	Include []string
+	Exclude []string
+}
+
+// NewNamespaceScope returns the scope watching a comma-separated list of
+// namespaces ("" = all namespaces) except exclude
+func NewNamespaceScope(namespaces string, exclude []string) NamespaceScope {
+	excluded := make(map[string]bool, len(exclude))
+	var scope NamespaceScope
+	for _, namespace := range exclude {
+		namespace = strings.TrimSpace(namespace)
+		if namespace != "" && !excluded[namespace] {
+			excluded[namespace] = true
+			scope.Exclude = append(scope.Exclude, namespace)
+		}
+	}
+
+	included := make(map[string]bool)
+	for _, namespace := range strings.Split(namespaces, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace != "" && !included[namespace] {
+			included[namespace] = true
+			if !excluded[namespace] {
+				scope.Include = append(scope.Include, namespace)
+			}
+		}
+	}
+	// Exclusions only narrow down all namespaces; a list naming nothing
+	// but excluded namespaces is rejected by validation
+	if len(included) > 0 && len(scope.Include) > 0 {
+		scope.Exclude = nil
+	}
+	return scope
+}
+
+// All reports whether every namespace but the excluded ones is watched
+func (s NamespaceScope) All() bool {
+	return len(s.Include) == 0
+}
+
+// Contains reports whether namespace is watched
+func (s NamespaceScope) Contains(namespace string) bool {
+	if s.All() {
+		for _, excluded := range s.Exclude {
+			if namespace == excluded {
+				return false
+			}
+		}
+		return true
+	}
+	for _, included := range s.Include {
+		if namespace == included {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the scope for logs, e.g. "shop,payments" or
+// "all except kube-system"
+func (s NamespaceScope) String() string {
+	switch {
+	case !s.All():
+		return strings.Join(s.Include, ",")
+	case len(s.Exclude) > 0:
+		return "all except " + strings.Join(s.Exclude, ",")
+	default:
+		return "all"
+	}
+}
+
 // LeaderElectionConfig represents leader election configuration
 type LeaderElectionConfig struct {
 	// Enable leader election
@@ -112,6 +565,51 @@ type LeaderElectionConfig struct {
 
 	// Leader election namespace
 	Namespace string `yaml:"namespace" json:"namespace"`
+
+	// How long other replicas wait before taking over a lease the leader
+	// stopped renewing (0 = 15s)
+	LeaseDuration time.Duration `yaml:"lease_duration" json:"lease_duration"`
+
+	// How long the leader keeps retrying to renew the lease before giving up
+	// leadership; shorter than lease_duration (0 = 10s)
+	RenewDeadline time.Duration `yaml:"renew_deadline" json:"renew_deadline"`
+
+	// How often replicas try to acquire or renew the lease (0 = 2s)
+	RetryPeriod time.Duration `yaml:"retry_period" json:"retry_period"`
+
+	// Resource holding the lock; only "leases" is supported, the configmaps
+	// and endpoints locks having been removed from Kubernetes ("" = leases)
+	ResourceLock string `yaml:"resource_lock" json:"resource_lock"`
+
+	// Release the lease on shutdown, so that another replica takes over
+	// without waiting for lease_duration
+	ReleaseOnCancel bool `yaml:"release_on_cancel" json:"release_on_cancel"`
+}
+
+// Defaults of the leader election timings, those of controller-runtime
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// LeasesResourceLock is the only supported leader election resource lock
+const LeasesResourceLock = "leases"
+
+// Timings returns the lease duration, renew deadline and retry period, with
+// defaults for those not set
+func (l LeaderElectionConfig) Timings() (leaseDuration, renewDeadline, retryPeriod time.Duration) {
+	leaseDuration, renewDeadline, retryPeriod = l.LeaseDuration, l.RenewDeadline, l.RetryPeriod
+	if leaseDuration == 0 {
+		leaseDuration = DefaultLeaseDuration
+	}
+	if renewDeadline == 0 {
+		renewDeadline = DefaultRenewDeadline
+	}
+	if retryPeriod == 0 {
+		retryPeriod = DefaultRetryPeriod
+	}
+	return leaseDuration, renewDeadline, retryPeriod
 }
 
 // MultiClusterConfig represents multi-cluster configuration
@@ -124,18 +622,596 @@ type MultiClusterConfig struct {
 	ConnectionTimeout      time.Duration `yaml:"connection_timeout" json:"connection_timeout"`
 	MaxConcurrentConns     int           `yaml:"max_concurrent_connections" json:"max_concurrent_connections"`
 
+	// Secondary clusters that must be synced, in addition to the primary,
+	// before the controller reports ready
+	ReadyQuorum int `yaml:"ready_quorum" json:"ready_quorum"`
+
+	// Drift detection between clusters meant to run identical deployments
+	Drift DriftConfig `yaml:"drift" json:"drift"`
+
+	// Propagation of labeled deployments from the primary cluster to the
+	// other enabled clusters
+	Propagation PropagationConfig `yaml:"propagation" json:"propagation"`
+
+	// Promotion of another cluster when the primary cluster stays unreachable
+	Failover FailoverConfig `yaml:"failover" json:"failover"`
+
+	// How often Secrets referenced by kubeconfig_secret are checked for changes
+	SecretRefreshInterval time.Duration `yaml:"secret_refresh_interval" json:"secret_refresh_interval"`
+
+	// How often k6s server checks the health of enabled clusters
+	HealthCheckInterval time.Duration `yaml:"health_check_interval" json:"health_check_interval"`
+
+	// Election of the replica reconciling each cluster, with a lease held in
+	// that cluster, so that several controller replicas can share the
+	// clusters (disabled by default)
+	LeaderElection LeaderElectionConfig `yaml:"leader_election" json:"leader_election"`
+
+	// Sharding of the clusters across controller replicas, each replica
+	// reconciling its own subset (disabled by default)
+	Sharding ShardingConfig `yaml:"sharding" json:"sharding"`
+
 	// Clusters configuration
 	Clusters []ClusterConfig `yaml:"clusters" json:"clusters"`
 }
 
+// DriftConfig represents continuous comparison of deployment specs across
+// groups of clusters, such as the regions of one environment
+type DriftConfig struct {
+	// How often deployment specs are compared
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// Groups of clusters whose deployments must match (none = disabled)
+	Groups []DriftGroupConfig `yaml:"groups" json:"groups"`
+}
+
+// PropagationConfig represents copying deployments labeled
+// k6s.io/propagate=true from the primary cluster to every other enabled
+// cluster, creating or updating them there
+type PropagationConfig struct {
+	// Enable propagation (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// How often deployments are propagated
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// Namespaces propagated (empty = all namespaces)
+	Namespaces []string `yaml:"namespaces" json:"namespaces"`
+}
+
+// Failover policies choosing the cluster promoted to primary
+const (
+	FailoverPolicyPriority   = "priority"   // highest priority, then name
+	FailoverPolicyCandidates = "candidates" // first reachable of the candidates list
+)
+
+// FailoverConfig represents promoting another enabled cluster to primary
+// when the primary cluster fails consecutive health checks. The promotion is
+// written to the configuration file.
+type FailoverConfig struct {
+	// Enable automatic failover (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// How often the primary cluster is checked
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// Consecutive failed checks before failing over
+	FailureThreshold int `yaml:"failure_threshold" json:"failure_threshold"`
+
+	// How the new primary is chosen: priority or candidates
+	Policy string `yaml:"policy" json:"policy"`
+
+	// Clusters eligible for promotion in order of preference, with the
+	// candidates policy
+	Candidates []string `yaml:"candidates" json:"candidates"`
+}
+
+// ShardingConfig represents splitting the clusters between the replicas of
+// the controller. Every replica renews a Lease labeled with the group, and
+// each cluster is reconciled by the replica that rendezvous hashing assigns it
+// among the live replicas, so clusters move when replicas join or leave.
+type ShardingConfig struct {
+	// Enable sharding (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Replicas sharing the clusters, labeling their Leases
+	Group string `yaml:"group" json:"group"`
+
+	// Namespace of the Leases ("" = namespace of the pod)
+	Namespace string `yaml:"namespace" json:"namespace"`
+
+	// How often a replica renews its Lease and rebalances the clusters
+	Interval time.Duration `yaml:"interval" json:"interval"`
+
+	// How long a replica that stopped renewing its Lease keeps its clusters
+	LeaseDuration time.Duration `yaml:"lease_duration" json:"lease_duration"`
+}
+
+// DriftGroupConfig represents clusters whose deployments must match
+type DriftGroupConfig struct {
+	// Name reported in drift alarms
+	Name string `yaml:"name" json:"name"`
+
+	// Names of the compared clusters
+	Clusters []string `yaml:"clusters" json:"clusters"`
+
+	// Namespaces compared (empty = all namespaces)
+	Namespaces []string `yaml:"namespaces" json:"namespaces"`
+}
+
 // ClusterConfig represents a single cluster configuration
 type ClusterConfig struct {
 	Name       string `yaml:"name" json:"name"`
 	KubeConfig string `yaml:"kubeconfig" json:"kubeconfig"`
+
+	// Secret holding the kubeconfig, read from the cluster k6s runs in;
+	// replaces the kubeconfig file
+	KubeconfigSecret SecretKeyRef `yaml:"kubeconfig_secret,omitempty" json:"kubeconfig_secret,omitzero"`
+
 	Context    string `yaml:"context" json:"context"`
 	Namespace  string `yaml:"namespace" json:"namespace"`
 	Enabled    bool   `yaml:"enabled" json:"enabled"`
 	Primary    bool   `yaml:"primary" json:"primary"`
+
+	// Startup priority among secondary clusters, higher starts first; the
+	// primary cluster always starts first
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Skip TLS certificate verification (discouraged, prefer ca_file)
+	InsecureSkipTLSVerify bool `yaml:"insecure_skip_tls_verify,omitempty" json:"insecure_skip_tls_verify,omitempty"`
+
+	// CA bundle used to verify the API server certificate
+	CAFile string `yaml:"ca_file,omitempty" json:"ca_file,omitempty"`
+
+	// Proxy URL for API server connections (http, https or socks5), also passed
+	// to exec credential plugins; "direct" bypasses HTTPS_PROXY/HTTP_PROXY
+	ProxyURL string `yaml:"proxy_url,omitempty" json:"proxy_url,omitempty"`
+}
+
+// DefaultKubeconfigSecretKey is the Secret key holding a kubeconfig when a
+// reference sets none
+const DefaultKubeconfigSecretKey = "kubeconfig"
+
+// SecretKeyRef references a key of a Kubernetes Secret
+type SecretKeyRef struct {
+	Namespace string `yaml:"namespace" json:"namespace"`
+	Name      string `yaml:"name" json:"name"`
+
+	// Key holding the data (empty = kubeconfig)
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+}
+
+// IsSet reports whether a Secret is referenced
+func (r SecretKeyRef) IsSet() bool {
+	return r.Name != ""
+}
+
+// DataKey returns the referenced key, defaulting to DefaultKubeconfigSecretKey
+func (r SecretKeyRef) DataKey() string {
+	if r.Key == "" {
+		return DefaultKubeconfigSecretKey
+	}
+	return r.Key
+}
+
+// String returns the reference as namespace/name:key
+func (r SecretKeyRef) String() string {
+	return r.Namespace + "/" + r.Name + ":" + r.DataKey()
+}
+
+// HistoryConfig represents the history store that keeps a snapshot of each
+// deployment deleted through k6s, so it can be undeleted
+type HistoryConfig struct {
+	// Directory holding snapshots (empty = ~/.k6s/history)
+	Dir string `yaml:"dir" json:"dir"`
+
+	// How long deleted deployments can be undeleted
+	Retention time.Duration `yaml:"retention" json:"retention"`
+
+	// Store of the deployment events seen by the server's informer
+	Events EventHistoryConfig `yaml:"events" json:"events"`
+}
+
+// EventHistoryConfig represents the embedded store of deployment events kept
+// for post-incident review
+type EventHistoryConfig struct {
+	// Record the events of the server's deployment informer (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Database file (empty = events.db in the history directory)
+	Path string `yaml:"path" json:"path"`
+
+	// How long events are kept (0 = forever)
+	Retention time.Duration `yaml:"retention" json:"retention"`
+}
+
+// NotificationsConfig represents delivering the deployment events that match
+// a rule to the rule's sinks, with retries and a rate limit per sink
+type NotificationsConfig struct {
+	// Deliver notifications (opt-in)
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Destinations of notifications, referenced by name from rules
+	Sinks []NotificationSinkConfig `yaml:"sinks" json:"sinks"`
+
+	// Events to deliver; an event matching several rules is delivered once
+	// to each sink
+	Rules []NotificationRuleConfig `yaml:"rules" json:"rules"`
+
+	// Delivery attempts per notification and sink, including the first
+	MaxAttempts int `yaml:"max_attempts" json:"max_attempts"`
+
+	// Delay before the first retry, doubled for each following one
+	RetryBackoff time.Duration `yaml:"retry_backoff" json:"retry_backoff"`
+
+	// Notifications delivered per sink and minute; more are dropped
+	// (0 = unlimited)
+	RateLimit int `yaml:"rate_limit" json:"rate_limit"`
+}
+
+// NotificationSinkConfig represents a destination of notifications
+type NotificationSinkConfig struct {
+	// Name referenced by rules
+	Name string `yaml:"name" json:"name"`
+
+	// slack, webhook or email
+	Type string `yaml:"type" json:"type"`
+
+	// Slack incoming webhook URL, or the endpoint webhook sinks POST the
+	// event to as JSON
+	URL string `yaml:"url" json:"url"`
+
+	// Headers sent by webhook sinks, such as Authorization
+	Headers map[string]string `yaml:"headers" json:"-"`
+
+	// SMTP server and recipients of email sinks
+	SMTP SMTPConfig `yaml:"smtp" json:"smtp"`
+}
+
+// SMTPConfig represents the SMTP server email notifications are sent through
+type SMTPConfig struct {
+	Host string `yaml:"host" json:"host"`
+	Port int    `yaml:"port" json:"port"`
+
+	// Credentials for PLAIN authentication (empty = no authentication)
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"-"`
+
+	From string   `yaml:"from" json:"from"`
+	To   []string `yaml:"to" json:"to"`
+}
+
+// NotificationRuleConfig represents the deployment events delivered to a set
+// of sinks. Empty conditions match every event.
+type NotificationRuleConfig struct {
+	// Name reported in notifications
+	Name string `yaml:"name" json:"name"`
+
+	// Event types: added, updated, deleted, rollout-stuck, restart-spike,
+	// scale-to-zero or failover
+	Events []string `yaml:"events" json:"events"`
+
+	// Where events come from: informer (k6s server), controller, rollout
+	// (the rollout tracker of k6s server), anomaly (its anomaly detector) or
+	// failover (primary cluster failover of the controller)
+	Sources []string `yaml:"sources" json:"sources"`
+
+	// Namespaces of the deployments
+	Namespaces []string `yaml:"namespaces" json:"namespaces"`
+
+	// Labels of the deployments
+	Selector map[string]string `yaml:"selector" json:"selector"`
+
+	// Fields an update must change: replicas, image, resources or labels.
+	// Only informer events report changed fields.
+	Changes []string `yaml:"changes" json:"changes"`
+
+	// Names of the sinks events are delivered to
+	Sinks []string `yaml:"sinks" json:"sinks"`
+}
+
+// ServerConfig represents HTTP API server configuration
+type ServerConfig struct {
+	// Port to listen on
+	Port int `yaml:"port" json:"port"`
+
+	// Access log configuration
+	AccessLog AccessLogConfig `yaml:"access_log" json:"access_log"`
+
+	// Disable endpoints that enrich cached objects with analysis or related
+	// resources, and the informers caching those resources
+	DisableEnrichment bool `yaml:"disable_enrichment" json:"disable_enrichment"`
+
+	// Fields returned for deployments when a request doesn't ask for specific ones
+	// (empty = all fields)
+	FieldMask []string `yaml:"field_mask" json:"field_mask"`
+
+	// gRPC API configuration
+	GRPC GRPCConfig `yaml:"grpc" json:"grpc"`
+
+	// Name of the cluster served, matched against the "cluster" parameter
+	// sent by remote clients (k6s attach --cluster)
+	ClusterName string `yaml:"cluster_name" json:"cluster_name"`
+
+	// HTTPS serving
+	TLS ServerTLSConfig `yaml:"tls" json:"tls"`
+
+	// API token authentication
+	Auth AuthConfig `yaml:"auth" json:"auth"`
+
+	// Labels deployments inherit from their namespace in API views
+	LabelPropagation LabelPropagationConfig `yaml:"label_propagation" json:"label_propagation"`
+
+	// Path prefix the API is also served under, e.g. /k6s behind an ingress
+	// that doesn't strip it
+	PathPrefix string `yaml:"path_prefix" json:"path_prefix"`
+
+	// Cross-origin requests from browser applications
+	CORS CORSConfig `yaml:"cors" json:"cors"`
+
+	// Proxies whose X-Forwarded-For and X-Forwarded-Prefix headers are
+	// trusted, as IP addresses or CIDR ranges
+	TrustedProxies []string `yaml:"trusted_proxies" json:"trusted_proxies"`
+
+	// Limits protecting the API from misbehaving clients
+	Limits LimitsConfig `yaml:"limits" json:"limits"`
+
+	// Caching of deployment responses between deployment changes
+	ResponseCache ResponseCacheConfig `yaml:"response_cache" json:"response_cache"`
+
+	// Deprecated: OpenTelemetry spans of API requests, moved to telemetry
+	// when the configuration is loaded
+	Tracing TracingConfig `yaml:"tracing,omitempty" json:"tracing,omitempty"`
+
+	// Port of a dedicated listener serving only /metrics (0 = /metrics is
+	// served on the API port)
+	MetricsPort int `yaml:"metrics_port" json:"metrics_port"`
+
+	// Liveness and readiness probes served at /livez and /readyz
+	Probes ProbesConfig `yaml:"probes" json:"probes"`
+
+	// How long shutdown waits for in-flight requests to drain and for the
+	// informers and background workers to stop
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" json:"shutdown_timeout"`
+}
+
+// ProbesConfig represents the checks of the /livez and /readyz probes
+type ProbesConfig struct {
+	// Checks both probes skip, e.g. cluster/staging to stay ready while a
+	// remote cluster is unreachable
+	Exclude []string `yaml:"exclude" json:"exclude"`
+
+	// How long after startup /livez fails while the server has never been
+	// ready, so that a server stuck syncing its caches is restarted
+	// (0 = never)
+	SyncTimeout time.Duration `yaml:"sync_timeout" json:"sync_timeout"`
+}
+
+// TracingConfig represents OpenTelemetry tracing of API requests, exported
+// with OTLP over HTTP. Deprecated: use TelemetryConfig.
+type TracingConfig struct {
+	// Create a span for every API request
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// OTLP HTTP endpoint, e.g. http://otel-collector:4318 (empty =
+	// OTEL_EXPORTER_OTLP_ENDPOINT, or http://localhost:4318)
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+
+	// Fraction of requests traced, unless the caller's trace is sampled
+	// (0 = all requests)
+	SampleRatio float64 `yaml:"sample_ratio" json:"sample_ratio"`
+}
+
+// TelemetryConfig represents OpenTelemetry tracing of API requests,
+// reconciliations and informer event handlers, exported with OTLP over HTTP
+type TelemetryConfig struct {
+	// Create spans for API requests, reconciliations and informer events
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// OTLP HTTP endpoint, e.g. http://otel-collector:4318 (empty =
+	// OTEL_EXPORTER_OTLP_ENDPOINT, or http://localhost:4318)
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+
+	// Fraction of traces sampled, unless the caller's trace is sampled
+	// (0 = all traces)
+	SampleRatio float64 `yaml:"sample_ratio" json:"sample_ratio"`
+
+	// Service name spans are reported under (empty = k6s)
+	ServiceName string `yaml:"service_name" json:"service_name"`
+}
+
+// LintConfig represents the rules k6s lint checks deployments against
+type LintConfig struct {
+	// Namespaces, or patterns such as prod-*, whose deployments must run more
+	// than one replica
+	ProductionNamespaces []string `yaml:"production_namespaces" json:"production_namespaces"`
+
+	// Rules that are not checked, such as missing-limits
+	DisabledRules []string `yaml:"disabled_rules" json:"disabled_rules"`
+}
+
+// LimitsConfig represents limits on the requests clients make to the API
+type LimitsConfig struct {
+	// Per-client rate limit of /api/ requests
+	RateLimit RateLimitConfig `yaml:"rate_limit" json:"rate_limit"`
+
+	// Largest request body accepted, in bytes (0 = 4 MiB)
+	MaxBodySize int `yaml:"max_body_size" json:"max_body_size"`
+
+	// How long a request may take before it fails with 503; watch streams
+	// are not limited (0 = no limit)
+	RequestTimeout time.Duration `yaml:"request_timeout" json:"request_timeout"`
+
+	// How long the deployments of every cluster listed for cluster=* are
+	// reused by later requests; concurrent requests always share one listing
+	// (0 = 1s)
+	ClusterListTTL time.Duration `yaml:"cluster_list_ttl" json:"cluster_list_ttl"`
+}
+
+// ResponseCacheConfig represents the cache of responses of the deployment
+// endpoints, keyed by the resourceVersion of the deployments they show and
+// invalidated when deployments change
+type ResponseCacheConfig struct {
+	// Cache GET responses of the deployment list, get, aggregate and index
+	// endpoints
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// How long a response is served at most, bounding how stale labels
+	// propagated from namespaces can be (0 = 10s)
+	TTL time.Duration `yaml:"ttl" json:"ttl"`
+
+	// Most responses kept (0 = 1000)
+	MaxEntries int `yaml:"max_entries" json:"max_entries"`
+}
+
+// RateLimitConfig represents a token bucket rate limit per client
+type RateLimitConfig struct {
+	// Requests per second each client may make on average (0 = no limit)
+	RequestsPerSecond float64 `yaml:"requests_per_second" json:"requests_per_second"`
+
+	// Requests a client may make at once after being idle (0 = one second's worth)
+	Burst int `yaml:"burst" json:"burst"`
+
+	// What identifies a client: "ip", or "token" for the principal its
+	// bearer token authenticates as, falling back to the IP for requests
+	// without a valid token or when auth is off (empty = ip)
+	Key string `yaml:"key" json:"key"`
+}
+
+// Enabled reports whether requests are rate limited
+func (r RateLimitConfig) Enabled() bool {
+	return r.RequestsPerSecond > 0
+}
+
+// CORSConfig represents cross-origin resource sharing for browser clients
+type CORSConfig struct {
+	// Origins allowed to call the API, e.g. https://portal.example.com;
+	// "*" allows any origin (empty = CORS disabled)
+	AllowedOrigins []string `yaml:"allowed_origins" json:"allowed_origins"`
+
+	// Request headers browsers may send (empty = Authorization, Content-Type)
+	AllowedHeaders []string `yaml:"allowed_headers" json:"allowed_headers"`
+
+	// How long browsers may cache preflight responses (0 = not cached)
+	MaxAge time.Duration `yaml:"max_age" json:"max_age"`
+}
+
+// LabelPropagationConfig represents labels propagated from namespaces onto
+// deployments, so that cost and usage reports group workloads that don't set
+// them. Labels set on a deployment take precedence.
+type LabelPropagationConfig struct {
+	// Namespace label keys to propagate, e.g. team or cost-center
+	Labels []string `yaml:"labels" json:"labels"`
+}
+
+// ServerTLSConfig represents the certificate the HTTP server serves HTTPS with
+type ServerTLSConfig struct {
+	// PEM certificate chain and private key files (empty = plain HTTP)
+	CertFile string `yaml:"cert_file" json:"cert_file"`
+	KeyFile  string `yaml:"key_file" json:"key_file"`
+
+	// How often the files are checked for a rotated certificate
+	// (0 = loaded once at startup)
+	ReloadInterval time.Duration `yaml:"reload_interval" json:"reload_interval"`
+}
+
+// Enabled reports whether the server serves HTTPS
+func (t ServerTLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// AuthConfig represents API authentication. When any method is configured,
+// every /api/ request must authenticate with one of them; write endpoints
+// require authentication to be configured.
+type AuthConfig struct {
+	// Bearer tokens accepted by the API
+	Tokens []APIToken `yaml:"tokens" json:"tokens"`
+
+	// Bearer tokens that match no configured token, such as service account
+	// tokens, validated with the Kubernetes TokenReview API
+	TokenReview TokenReviewConfig `yaml:"token_review" json:"token_review"`
+
+	// PEM file of the CAs that sign client certificates. Requests over TLS
+	// presenting a certificate they verify are authenticated as the
+	// certificate's common name.
+	ClientCAFile string `yaml:"client_ca_file" json:"client_ca_file"`
+
+	// Roles of users and groups authenticated by token review or client
+	// certificate; the highest bound role applies
+	RoleBindings []RoleBinding `yaml:"role_bindings" json:"role_bindings"`
+
+	// Role of callers without a bound role (empty = viewer)
+	DefaultRole string `yaml:"default_role" json:"default_role"`
+}
+
+// RoleBinding grants a role (viewer, operator or admin) to users and groups.
+// Certificates are authenticated with their common name as user and their
+// organizations as groups.
+type RoleBinding struct {
+	Role   string   `yaml:"role" json:"role"`
+	Users  []string `yaml:"users" json:"users"`
+	Groups []string `yaml:"groups" json:"groups"`
+}
+
+// Enabled reports whether any authentication method is configured
+func (a AuthConfig) Enabled() bool {
+	return len(a.Tokens) > 0 || a.TokenReview.Enabled || a.ClientCAFile != ""
+}
+
+// TokenReviewConfig represents bearer token validation by the Kubernetes API
+type TokenReviewConfig struct {
+	// Validate unknown bearer tokens with TokenReview
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Audiences the tokens must be issued for (empty = the API server's)
+	Audiences []string `yaml:"audiences" json:"audiences"`
+
+	// How long review results are cached (0 = 1m)
+	CacheTTL time.Duration `yaml:"cache_ttl" json:"cache_ttl"`
+
+	// Most accepted tokens cached at once (0 = 1000)
+	CacheSize int `yaml:"cache_size" json:"cache_size"`
+}
+
+// APIToken represents a named bearer token
+type APIToken struct {
+	// Name recorded as the principal in access logs
+	Name string `yaml:"name" json:"name"`
+
+	// Token value sent as "Authorization: Bearer <token>"
+	Token string `yaml:"token" json:"-"`
+
+	// Role of the token: viewer, operator or admin (empty = the role bound
+	// to its name in role_bindings, or else default_role)
+	Role string `yaml:"role" json:"role"`
+}
+
+// GRPCConfig represents gRPC API configuration
+type GRPCConfig struct {
+	// Serve the gRPC API alongside the HTTP API
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Port to listen on
+	Port int `yaml:"port" json:"port"`
+}
+
+// AccessLogConfig represents API access log configuration
+type AccessLogConfig struct {
+	// Enable access logging
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// File to append access entries to (empty = structured logs)
+	File string `yaml:"file" json:"file"`
+
+	// Replace principals with an HMAC-SHA256 of them keyed with HashKey
+	HashPrincipals bool `yaml:"hash_principals" json:"hash_principals"`
+
+	// Secret key of the principal hashes, at least 16 bytes (empty = a random
+	// key per process, so hashes don't match across restarts)
+	HashKey string `yaml:"hash_key" json:"hash_key"`
+
+	// Skip health, version and metrics endpoints
+	ExcludeHealth bool `yaml:"exclude_health" json:"exclude_health"`
 }
 
 // DefaultConfig returns the default configuration
@@ -156,19 +1232,131 @@ func DefaultConfig() *Config {
 			},
 			ConfigFile:   "",
 			ResyncPeriod: 30 * time.Second,
+			Client: KubeClientConfig{
+				ContentType: "protobuf",
+			},
+			Status: StatusReportConfig{
+				Enabled:  true,
+				Name:     "k6s",
+				Interval: 30 * time.Second,
+			},
+			Drift: ManifestDriftConfig{
+				Interval:  time.Minute,
+				Namespace: "default",
+			},
+			Compliance: ComplianceConfig{
+				Interval:              5 * time.Minute,
+				RegistrySeverity:      "critical",
+				LatestTagSeverity:     "high",
+				MissingDigestSeverity: "low",
+			},
+			Rollouts: RolloutHealthConfig{
+				Interval: 30 * time.Second,
+			},
+			Anomalies: AnomalyConfig{
+				Interval:           time.Minute,
+				Window:             time.Hour,
+				RestartSpikeMin:    5,
+				RestartSpikeFactor: 3,
+			},
+			GitOps: GitOpsConfig{
+				Interval:  time.Minute,
+				Namespace: "default",
+			},
+			Webhook: WebhookConfig{
+				Port: 9443,
+				TopologySpread: TopologySpreadConfig{
+					TopologyKey:       "topology.kubernetes.io/zone",
+					MaxSkew:           1,
+					WhenUnsatisfiable: "ScheduleAnyway",
+				},
+			},
 		},
 		MultiCluster: MultiClusterConfig{
 			TestConnectivity:       false,
 			DefaultNamespace:       "default",
 			ConnectionTimeout:      30 * time.Second,
 			MaxConcurrentConns:     10,
+			Drift: DriftConfig{
+				Interval: 5 * time.Minute,
+			},
+			Propagation: PropagationConfig{
+				Interval: time.Minute,
+			},
+			Failover: FailoverConfig{
+				Interval:         30 * time.Second,
+				FailureThreshold: 3,
+				Policy:           FailoverPolicyPriority,
+			},
+			SecretRefreshInterval: time.Minute,
+			HealthCheckInterval:   30 * time.Second,
+			LeaderElection: LeaderElectionConfig{
+				Enabled:   false,
+				ID:        "k6s-controller",
+				Namespace: "default",
+			},
+			Sharding: ShardingConfig{
+				Enabled:       false,
+				Group:         "k6s-controller",
+				Interval:      10 * time.Second,
+				LeaseDuration: 40 * time.Second,
+			},
 			Clusters:               []ClusterConfig{},
 		},
+		Server: ServerConfig{
+			Port: 8080,
+			AccessLog: AccessLogConfig{
+				Enabled:        false,
+				File:           "",
+				HashPrincipals: false,
+				ExcludeHealth:  true,
+			},
+			GRPC: GRPCConfig{
+				Enabled: false,
+				Port:    9090,
+			},
+			ShutdownTimeout: 30 * time.Second,
+		},
+		History: HistoryConfig{
+			Retention: 7 * 24 * time.Hour,
+			Events: EventHistoryConfig{
+				Retention: 30 * 24 * time.Hour,
+			},
+		},
+		Notifications: NotificationsConfig{
+			MaxAttempts:  3,
+			RetryBackoff: 2 * time.Second,
+			RateLimit:    30,
+		},
+		Lint: LintConfig{
+			ProductionNamespaces: []string{"prod", "production", "prod-*", "*-prod"},
+		},
 	}
 }
 
-// LoadConfig loads configuration from file
+// strictParsing makes LoadConfig reject unknown keys
+var strictParsing atomic.Bool
+
+// SetStrict makes LoadConfig reject configuration files with unknown or
+// duplicate keys, such as a misspelt resync_period, instead of ignoring them
+func SetStrict(strict bool) {
+	strictParsing.Store(strict)
+}
+
+// LoadConfig loads configuration from file, rejecting unknown keys when
+// strict parsing is on (see SetStrict)
 func LoadConfig(configFile string) (*Config, error) {
+	return loadConfig(configFile, strictParsing.Load())
+}
+
+// LoadConfigStrict loads configuration from file, rejecting unknown and
+// duplicate keys
+func LoadConfigStrict(configFile string) (*Config, error) {
+	return loadConfig(configFile, true)
+}
+
+// loadConfig loads configuration from file, optionally rejecting unknown keys
+func loadConfig(configFile string, strict bool) (*Config, error) {
 	// Start with default config
 	config := DefaultConfig()
 
@@ -197,9 +1385,21 @@ func LoadConfig(configFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file %s: %v", configFile, err)
 	}
 
+	return parseConfig(data, configFile, strict)
+}
+
+// parseConfig parses the contents of the configuration file named name over
+// the default configuration, optionally rejecting unknown keys
+func parseConfig(data []byte, name string, strict bool) (*Config, error) {
+	config := DefaultConfig()
+
 	// Parse YAML
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file %s: %v", configFile, err)
+	unmarshal := yaml.Unmarshal
+	if strict {
+		unmarshal = yaml.UnmarshalStrict
+	}
+	if err := unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", name, err)
 	}
 
 	// Migrate legacy configuration if needed
@@ -207,9 +1407,49 @@ func LoadConfig(configFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to migrate legacy config: %v", err)
 	}
 
+	config.ApplyPresets()
+
 	return config, nil
 }
 
+// Large-cluster mode defaults
+const (
+	LargeClusterResyncPeriod  = 10 * time.Minute
+	LargeClusterListChunkSize = 500
+)
+
+// LargeClusterFieldMask is the default deployment field mask in large-cluster mode
+var LargeClusterFieldMask = []string{"name", "namespace", "replicas", "ready", "available"}
+
+// ApplyPresets expands preset switches into their individual settings.
+// Settings that were explicitly changed from their defaults are kept.
+func (c *Config) ApplyPresets() {
+	if !c.LargeClusterMode {
+		return
+	}
+
+	// Cache only what the API serves
+	if c.Controller.Informer.Transform == "" {
+		c.Controller.Informer.Transform = "metadata"
+	}
+
+	// List deployments in pages instead of one large response
+	if c.Controller.Informer.ListChunkSize == 0 {
+		c.Controller.Informer.ListChunkSize = LargeClusterListChunkSize
+	}
+
+	// Avoid periodic full-cache resync storms
+	if c.Controller.ResyncPeriod == DefaultConfig().Controller.ResyncPeriod {
+		c.Controller.ResyncPeriod = LargeClusterResyncPeriod
+	}
+
+	// Keep responses small and skip per-object enrichment
+	c.Server.DisableEnrichment = true
+	if len(c.Server.FieldMask) == 0 {
+		c.Server.FieldMask = append([]string(nil), LargeClusterFieldMask...)
+	}
+}
+
 // migrateLegacyConfig migrates old configuration format to new format
 func migrateLegacyConfig(config *Config) error {
 	// Migrate direct cluster fields to MultiCluster
@@ -249,6 +1489,16 @@ func migrateLegacyConfig(config *Config) error {
 		config.Informer = nil
 	}
 
+	// Migrate API request tracing to telemetry, unless it is configured
+	if tracing := config.Server.Tracing; tracing.Enabled && !config.Telemetry.Enabled {
+		config.Telemetry = TelemetryConfig{
+			Enabled:     true,
+			Endpoint:    tracing.Endpoint,
+			SampleRatio: tracing.SampleRatio,
+		}
+	}
+	config.Server.Tracing = TracingConfig{}
+
 	// Migrate legacy watch config (store in controller config for future use)
 	if config.Watch != nil {
 		// For now, we'll just clear it since we don't have equivalent fields
@@ -285,6 +1535,9 @@ func ResolveConfig(config *Config, flagValues *FlagValues) *Config {
 		if flagValues.Namespace != "" {
 			resolved.Controller.Single.Namespace = flagValues.Namespace
 		}
+		if len(flagValues.ExcludeNamespaces) > 0 {
+			resolved.Controller.Single.ExcludeNamespaces = flagValues.ExcludeNamespaces
+		}
 		if flagValues.MetricsPort != 0 {
 			resolved.Controller.Single.MetricsPort = flagValues.MetricsPort
 		}
@@ -323,6 +1576,9 @@ func ResolveConfig(config *Config, flagValues *FlagValues) *Config {
 	if envValue := os.Getenv("K6S_CONTROLLER_NAMESPACE"); envValue != "" {
 		resolved.Controller.Single.Namespace = envValue
 	}
+	if envValue := os.Getenv("K6S_CONTROLLER_EXCLUDE_NAMESPACES"); envValue != "" {
+		resolved.Controller.Single.ExcludeNamespaces = strings.Split(envValue, ",")
+	}
 	if envValue := os.Getenv("K6S_CONTROLLER_METRICS_PORT"); envValue != "" {
 		if port, err := strconv.Atoi(envValue); err == nil {
 			resolved.Controller.Single.MetricsPort = port
@@ -360,6 +1616,7 @@ type FlagValues struct {
 	ConfigFile                 string
 	ResyncPeriod               time.Duration
 	Namespace                  string
+	ExcludeNamespaces          []string
 	MetricsPort                int
 	HealthPort                 int
 	LeaderElectionEnabled      *bool
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k6s.yaml")
+	if err := os.WriteFile(path, []byte("controller:\n  resnyc_period: 1m\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(path); err != nil {
+		t.Errorf("Expected unknown keys to be ignored by default, got %v", err)
+	}
+	if _, err := LoadConfigStrict(path); err == nil || !strings.Contains(err.Error(), "resnyc_period") {
+		t.Errorf("Expected the misspelt key to be reported, got %v", err)
+	}
+
+	SetStrict(true)
+	defer SetStrict(false)
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("Expected LoadConfig to reject unknown keys in strict mode")
+	}
+
+	// The examples use known keys only
+	dir, err := filepath.Abs(filepath.Join("..", "..", "examples", "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	examples, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil || len(examples) == 0 {
+		t.Fatalf("Expected example configurations, got %v, %v", examples, err)
+	}
+	for _, example := range examples {
+		if _, err := LoadConfig(example); err != nil {
+			t.Errorf("Example %s: %v", example, err)
+		}
+	}
+}
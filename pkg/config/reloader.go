@@ -0,0 +1,280 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+)
+
+// DefaultReloadDebounce is how long the reloader waits for a burst of file
+// events to settle before reading the file
+const DefaultReloadDebounce = 250 * time.Millisecond
+
+// sourceReadTimeout bounds reading the configuration file from a Source
+const sourceReadTimeout = 30 * time.Second
+
+// Subscriber is notified with the previous and the new configuration after a
+// successful reload. Subscribers should only act on the settings that changed,
+// so that command-line overrides survive unrelated edits to the file.
+type Subscriber func(oldConfig, newConfig *Config)
+
+// Source provides the configuration file from outside the filesystem, such
+// as from a ConfigMap
+type Source interface {
+	// String describes the source in logs
+	String() string
+
+	// Read returns the contents of the configuration file
+	Read(ctx context.Context) ([]byte, error)
+
+	// Watch calls changed whenever the contents may have changed, until ctx
+	// is done
+	Watch(ctx context.Context, changed func()) error
+}
+
+// ConfigReloader watches the configuration file and notifies subscribers when
+// it changes
+type ConfigReloader struct {
+	path     string
+	source   Source
+	debounce time.Duration
+
+	mu          sync.RWMutex
+	current     *Config
+	subscribers []Subscriber
+}
+
+// NewConfigReloader loads and validates the configuration file at path (the
+// default location when empty) and returns a reloader for it
+func NewConfigReloader(path string) (*ConfigReloader, error) {
+	if path == "" {
+		path = GetDefaultConfigPath()
+	}
+
+	cfg, err := loadValidConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigReloader{
+		path:     path,
+		debounce: DefaultReloadDebounce,
+		current:  cfg,
+	}, nil
+}
+
+// NewSourceReloader loads and validates the configuration file of source and
+// returns a reloader for it
+func NewSourceReloader(source Source) (*ConfigReloader, error) {
+	r := &ConfigReloader{
+		source:   source,
+		debounce: DefaultReloadDebounce,
+	}
+
+	cfg, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.current = cfg
+	return r, nil
+}
+
+// Path returns the watched configuration file, or "" when the configuration
+// is read from a Source
+func (r *ConfigReloader) Path() string {
+	return r.path
+}
+
+// Name describes where the configuration is read from: the path of the file
+// or the Source
+func (r *ConfigReloader) Name() string {
+	if r.source != nil {
+		return r.source.String()
+	}
+	return r.path
+}
+
+// Current returns the last successfully loaded configuration
+func (r *ConfigReloader) Current() *Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// Subscribe registers fn to be called after each successful reload
+func (r *ConfigReloader) Subscribe(fn Subscriber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Reload reads the configuration file and notifies subscribers. An invalid
+// file is rejected and the previous configuration stays in effect.
+func (r *ConfigReloader) Reload() error {
+	cfg, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	oldConfig := r.current
+	r.current = cfg
+	subscribers := append([]Subscriber(nil), r.subscribers...)
+	r.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(oldConfig, cfg)
+	}
+	return nil
+}
+
+// Start watches the configuration file until ctx is cancelled. The parent
+// directory is watched rather than the file itself, because editors and
+// config management tools usually replace the file instead of writing to it.
+func (r *ConfigReloader) Start(ctx context.Context) error {
+	if r.source != nil {
+		changes := make(chan struct{}, 1)
+		changed := func() {
+			select {
+			case changes <- struct{}{}:
+			default:
+				// A reload is already pending
+			}
+		}
+		if err := r.source.Watch(ctx, changed); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", r.source, err)
+		}
+		go r.watchSource(ctx, changes)
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	dir := filepath.Dir(r.path)
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch config directory %s: %w", dir, err)
+	}
+
+	go r.watch(ctx, watcher)
+	return nil
+}
+
+// watch reloads the configuration after file events settle
+func (r *ConfigReloader) watch(ctx context.Context, watcher *fsnotify.Watcher) {
+	defer func() { _ = watcher.Close() }()
+
+	log := logger.WithComponent("config-reloader")
+	target := filepath.Clean(r.path)
+
+	timer := time.NewTimer(r.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != target || event.Op == fsnotify.Chmod {
+				continue
+			}
+			timer.Reset(r.debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warn("Config watcher error", map[string]interface{}{
+				"error": err.Error(),
+			})
+		case <-timer.C:
+			if _, err := os.Stat(r.path); os.IsNotExist(err) {
+				// Removed, or mid-replace; wait for the file to come back
+				continue
+			}
+			r.reloadChanged(log)
+		}
+	}
+}
+
+// watchSource reloads the configuration after changes of the source settle
+func (r *ConfigReloader) watchSource(ctx context.Context, changes <-chan struct{}) {
+	log := logger.WithComponent("config-reloader")
+
+	timer := time.NewTimer(r.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+			timer.Reset(r.debounce)
+		case <-timer.C:
+			r.reloadChanged(log)
+		}
+	}
+}
+
+// reloadChanged reloads the configuration after a change, logging whether it
+// was applied
+func (r *ConfigReloader) reloadChanged(log *logger.Logger) {
+	if err := r.Reload(); err != nil {
+		log.Error("Rejected configuration change, keeping previous configuration", err, map[string]interface{}{
+			"source": r.Name(),
+		})
+		return
+	}
+	log.Info("Configuration reloaded", map[string]interface{}{
+		"source": r.Name(),
+	})
+}
+
+// load reads and validates the configuration from the source, or the file
+func (r *ConfigReloader) load() (*Config, error) {
+	if r.source == nil {
+		return loadValidConfig(r.path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sourceReadTimeout)
+	defer cancel()
+	data, err := r.source.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config from %s: %w", r.source, err)
+	}
+	cfg, err := parseConfig(data, r.source.String(), strictParsing.Load())
+	if err != nil {
+		return nil, err
+	}
+	return validConfig(cfg)
+}
+
+// loadValidConfig loads the configuration file and validates it
+func loadValidConfig(path string) (*Config, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return validConfig(cfg)
+}
+
+// validConfig returns cfg if it is valid
+func validConfig(cfg *Config) (*Config, error) {
+	if err := NewConfigValidator(cfg).ValidateAll(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return cfg, nil
+}
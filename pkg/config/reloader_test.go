@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigReloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k6s.yaml")
+	write := func(data string) {
+		// Replace the file the way editors do, to exercise the directory watch
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("log_level: info\n")
+
+	reloader, err := NewConfigReloader(path)
+	if err != nil {
+		t.Fatalf("NewConfigReloader() error = %v", err)
+	}
+	reloader.debounce = 10 * time.Millisecond
+
+	changes := make(chan [2]string, 4)
+	reloader.Subscribe(func(oldConfig, newConfig *Config) {
+		changes <- [2]string{oldConfig.LogLevel, newConfig.LogLevel}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := reloader.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	write("log_level: debug\n")
+	select {
+	case change := <-changes:
+		if change != [2]string{"info", "debug"} {
+			t.Errorf("Unexpected change %v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reload")
+	}
+
+	// An invalid file is rejected and the previous configuration kept
+	write("log_level: loud\n")
+	select {
+	case change := <-changes:
+		t.Errorf("Expected invalid config to be rejected, got %v", change)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if reloader.Current().LogLevel != "debug" {
+		t.Errorf("Expected previous config to stay in effect, got %q", reloader.Current().LogLevel)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Error("Expected Reload() to fail for an invalid file")
+	}
+}
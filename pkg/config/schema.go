@@ -0,0 +1,119 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaURI is the JSON Schema dialect of Schema
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the durations time.ParseDuration accepts, such as
+// 30s or 1h30m
+const durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Schema returns the JSON Schema of the configuration file, derived from the
+// yaml keys of Config with the values of DefaultConfig as defaults. Objects
+// don't allow other keys, as in strict parsing.
+func Schema() map[string]interface{} {
+	schema := schemaFor(reflect.TypeOf(Config{}), reflect.ValueOf(*DefaultConfig()))
+	schema["$schema"] = SchemaURI
+	schema["title"] = "k6s configuration"
+	return schema
+}
+
+// schemaFor returns the schema of values of type t, with the default value
+// def when it is valid and not zero
+func schemaFor(t reflect.Type, def reflect.Value) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if def.IsValid() && !def.IsNil() {
+			def = def.Elem()
+		} else {
+			def = reflect.Value{}
+		}
+	}
+
+	var schema map[string]interface{}
+	switch {
+	case t == durationType:
+		schema = map[string]interface{}{
+			"type":    []string{"string", "integer"},
+			"pattern": durationPattern,
+		}
+		if def.IsValid() && !def.IsZero() {
+			schema["default"] = time.Duration(def.Int()).String()
+		}
+		return schema
+	case t.Kind() == reflect.Struct:
+		return structSchema(t, def)
+	case t.Kind() == reflect.Map:
+		schema = map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem(), reflect.Value{}),
+		}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		schema = map[string]interface{}{
+			"type":  "array",
+			"items": schemaFor(t.Elem(), reflect.Value{}),
+		}
+	case t.Kind() == reflect.String:
+		schema = map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		schema = map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		schema = map[string]interface{}{"type": "integer"}
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		schema = map[string]interface{}{"type": "integer", "minimum": 0}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema = map[string]interface{}{"type": "number"}
+	default:
+		schema = map[string]interface{}{}
+	}
+
+	if def.IsValid() && !def.IsZero() {
+		schema["default"] = def.Interface()
+	}
+	return schema
+}
+
+// structSchema returns the schema of an object with the yaml keys of the
+// fields of t
+func structSchema(t reflect.Type, def reflect.Value) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+
+		var fieldDef reflect.Value
+		if def.IsValid() {
+			fieldDef = def.Field(i)
+		}
+		if options == "inline" {
+			inlined := structSchema(field.Type, fieldDef)
+			for key, property := range inlined["properties"].(map[string]interface{}) {
+				properties[key] = property
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		properties[name] = schemaFor(field.Type, fieldDef)
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	if schema["$schema"] != SchemaURI || schema["additionalProperties"] != false {
+		t.Errorf("Expected a closed top-level object, got %v", schema)
+	}
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("Expected the schema to encode as JSON: %v", err)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	controller := properties["controller"].(map[string]interface{})["properties"].(map[string]interface{})
+	resync, ok := controller["resync_period"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected controller.resync_period, got %v", controller)
+	}
+	if resync["pattern"] != durationPattern || resync["default"] != "30s" {
+		t.Errorf("Expected a duration defaulting to 30s, got %v", resync)
+	}
+	if levels := properties["log"].(map[string]interface{})["properties"].(map[string]interface{})["levels"].(map[string]interface{}); levels["type"] != "object" {
+		t.Errorf("Expected log.levels to be a map, got %v", levels)
+	}
+	if _, ok := properties["informer"]; !ok {
+		t.Error("Expected legacy keys, which are still accepted, to be described")
+	}
+}
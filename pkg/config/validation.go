@@ -3,10 +3,19 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/errors"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/security"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 // ConfigValidator validates configuration
@@ -47,6 +56,60 @@ func (v *ConfigValidator) ValidateGeneral() error {
 	if !v.isValidLogLevel(v.config.LogLevel) {
 		return errors.NewValidationError(fmt.Sprintf("invalid log level '%s', must be one of: %v", v.config.LogLevel, validLogLevels))
 	}
+	for component, level := range v.config.Log.Levels {
+		if component == "" || !v.isValidLogLevel(level) {
+			return errors.NewValidationError(fmt.Sprintf("invalid log level '%s' of component '%s', must be one of: %v", level, component, validLogLevels))
+		}
+	}
+	if v.config.Log.Sampling.Period < 0 {
+		return errors.NewValidationError(fmt.Sprintf("invalid log sampling period %v, must not be negative", v.config.Log.Sampling.Period))
+	}
+	if v.config.Log.DedupWindow < 0 {
+		return errors.NewValidationError(fmt.Sprintf("invalid log dedup window %v, must not be negative", v.config.Log.DedupWindow))
+	}
+	
+	if v.config.History.Retention <= 0 {
+		return errors.NewValidationError(fmt.Sprintf("invalid history retention %v, must be positive", v.config.History.Retention))
+	}
+	
+	if v.config.History.Dir != "" {
+		if err := validateFilePath(v.config.History.Dir); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("invalid history directory '%s': %v", v.config.History.Dir, err))
+		}
+	}
+	
+	if events := v.config.History.Events; events.Enabled {
+		if events.Retention < 0 {
+			return errors.NewValidationError(fmt.Sprintf("invalid event history retention %v, must not be negative", events.Retention))
+		}
+		if events.Path != "" {
+			if err := validateFilePath(events.Path); err != nil {
+				return errors.NewValidationError(fmt.Sprintf("invalid event history path '%s': %v", events.Path, err))
+			}
+		}
+	}
+	
+	if notifications := v.config.Notifications; notifications.Enabled {
+		if err := validateNotifications(notifications); err != nil {
+			return err
+		}
+	}
+	
+	telemetry := v.config.Telemetry
+	if telemetry.SampleRatio < 0 || telemetry.SampleRatio > 1 {
+		return errors.NewValidationError("telemetry sample ratio must be between 0 and 1")
+	}
+	if telemetry.Endpoint != "" {
+		if u, err := url.Parse(telemetry.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.NewValidationError(fmt.Sprintf("invalid telemetry endpoint '%s', must be an http or https URL", telemetry.Endpoint))
+		}
+	}
+	
+	for _, pattern := range v.config.Lint.ProductionNamespaces {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return errors.NewValidationError(fmt.Sprintf("invalid lint production namespace pattern '%s'", pattern))
+		}
+	}
 	
 	return nil
 }
@@ -58,6 +121,11 @@ func (v *ConfigValidator) ValidateController() error {
 		return errors.NewValidationError(fmt.Sprintf("invalid controller mode '%s', must be 'single' or 'multi'", v.config.Controller.Mode))
 	}
 	
+	// Validate watched namespaces
+	if err := v.validateNamespaceScope(); err != nil {
+		return err
+	}
+	
 	// Validate single cluster configuration
 	if v.config.Controller.Mode == "single" || v.config.Controller.Mode == "" {
 		if err := v.validateSingleCluster(); err != nil {
@@ -70,6 +138,112 @@ func (v *ConfigValidator) ValidateController() error {
 		return errors.NewValidationError(fmt.Sprintf("resync period must be at least 1 second, got %v", v.config.Controller.ResyncPeriod))
 	}
 	
+	// Validate informer transform
+	switch v.config.Controller.Informer.Transform {
+	case "", "none", "strip", "metadata":
+	default:
+		return errors.NewValidationError(fmt.Sprintf("invalid informer transform '%s', must be 'none', 'strip' or 'metadata'", v.config.Controller.Informer.Transform))
+	}
+	if v.config.Controller.Informer.ListChunkSize < 0 {
+		return errors.NewValidationError(fmt.Sprintf("informer list chunk size must not be negative, got %d", v.config.Controller.Informer.ListChunkSize))
+	}
+	if err := validateIndexes(v.config.Controller.Informer.Indexes); err != nil {
+		return err
+	}
+	if snapshot := v.config.Controller.Informer.Snapshot; snapshot.Interval < 0 || snapshot.MaxAge < 0 {
+		return errors.NewValidationError(fmt.Sprintf("informer snapshot interval and max age must not be negative, got %v and %v", snapshot.Interval, snapshot.MaxAge))
+	}
+	
+	// Validate API client tuning
+	if client := v.config.Controller.Client; client.QPS < 0 || client.Burst < 0 {
+		return errors.NewValidationError(fmt.Sprintf("client qps and burst must not be negative, got %v and %d", client.QPS, client.Burst))
+	}
+	switch v.config.Controller.Client.ContentType {
+	case "", "protobuf", "json":
+	default:
+		return errors.NewValidationError(fmt.Sprintf("invalid client content type '%s', must be 'protobuf' or 'json'", v.config.Controller.Client.ContentType))
+	}
+	
+	// Validate status reporting
+	if v.config.Controller.Status.Enabled {
+		if v.config.Controller.Status.Name == "" {
+			return errors.NewValidationError("status object name cannot be empty when status reporting is enabled")
+		}
+		if v.config.Controller.Status.Interval < time.Second {
+			return errors.NewValidationError(fmt.Sprintf("status interval must be at least 1 second, got %v", v.config.Controller.Status.Interval))
+		}
+	}
+	
+	// Validate manifest drift detection
+	if drift := v.config.Controller.Drift; drift.Enabled {
+		if drift.Interval < time.Second {
+			return errors.NewValidationError(fmt.Sprintf("manifest drift interval must be at least 1 second, got %v", drift.Interval))
+		}
+		if drift.Path != "" {
+			if err := validateFilePath(drift.Path); err != nil {
+				return errors.NewValidationError(fmt.Sprintf("invalid manifest drift path '%s': %v", drift.Path, err))
+			}
+		}
+	}
+	
+	// Validate image compliance scanning
+	if compliance := v.config.Controller.Compliance; compliance.Enabled {
+		if err := validateCompliance(compliance); err != nil {
+			return err
+		}
+	}
+	
+	// Validate rollout health tracking
+	if rollouts := v.config.Controller.Rollouts; rollouts.Enabled && rollouts.Interval < time.Second {
+		return errors.NewValidationError(fmt.Sprintf("rollout health interval must be at least 1 second, got %v", rollouts.Interval))
+	}
+	
+	// Validate anomaly detection
+	if anomalies := v.config.Controller.Anomalies; anomalies.Enabled {
+		if err := validateAnomalies(anomalies); err != nil {
+			return err
+		}
+	}
+	
+	// Validate the mutating webhook
+	if webhook := v.config.Controller.Webhook; webhook.Enabled {
+		if err := validateWebhook(webhook); err != nil {
+			return err
+		}
+	}
+	
+	// Validate the cleanup finalizer
+	if finalizer := v.config.Controller.Finalizer; finalizer.Enabled {
+		if err := validateFinalizer(finalizer); err != nil {
+			return err
+		}
+	}
+	
+	// Read-only mode excludes the features that write deployments
+	if v.config.Controller.ReadOnly {
+		if v.config.Controller.GitOps.Enabled {
+			return errors.NewValidationError("gitops cannot be enabled in read-only mode")
+		}
+		if v.config.Controller.Finalizer.Enabled {
+			return errors.NewValidationError("the deployment finalizer cannot be enabled in read-only mode")
+		}
+	}
+	
+	// Validate GitOps source mode
+	if gitops := v.config.Controller.GitOps; gitops.Enabled {
+		if gitops.Repository == "" {
+			return errors.NewValidationError("gitops repository cannot be empty when gitops is enabled")
+		}
+		if gitops.Interval < time.Second {
+			return errors.NewValidationError(fmt.Sprintf("gitops interval must be at least 1 second, got %v", gitops.Interval))
+		}
+		if gitops.Path != "" {
+			if err := validateFilePath(gitops.Path); err != nil || filepath.IsAbs(gitops.Path) {
+				return errors.NewValidationError(fmt.Sprintf("invalid gitops path '%s': must be a directory within the repository", gitops.Path))
+			}
+		}
+	}
+	
 	return nil
 }
 
@@ -85,6 +259,48 @@ func (v *ConfigValidator) ValidateMultiCluster() error {
 		return errors.NewValidationError(fmt.Sprintf("max concurrent connections must be between 1 and 1000, got %d", v.config.MultiCluster.MaxConcurrentConns))
 	}
 	
+	if v.config.MultiCluster.ReadyQuorum < 0 {
+		return errors.NewValidationError(fmt.Sprintf("ready quorum must not be negative, got %d", v.config.MultiCluster.ReadyQuorum))
+	}
+	
+	if err := v.validateDrift(); err != nil {
+		return err
+	}
+	
+	if propagation := v.config.MultiCluster.Propagation; propagation.Enabled && propagation.Interval < time.Second {
+		return errors.NewValidationError(fmt.Sprintf("propagation interval must be at least 1 second, got %v", propagation.Interval))
+	}
+	
+	if err := v.validateFailover(); err != nil {
+		return err
+	}
+	
+	if err := v.validateSharding(); err != nil {
+		return err
+	}
+	
+	// The lease is held in each member cluster, where the namespace of the
+	// controller's pod may not exist
+	if election := v.config.MultiCluster.LeaderElection; election.Enabled {
+		if !v.isValidKubernetesName(election.ID) {
+			return errors.NewValidationError(fmt.Sprintf("invalid multi-cluster leader election ID '%s'", election.ID))
+		}
+		if !v.isValidKubernetesName(election.Namespace) {
+			return errors.NewValidationError(fmt.Sprintf("invalid multi-cluster leader election namespace '%s'", election.Namespace))
+		}
+		if err := validateLeaderElectionTuning(election); err != nil {
+			return err
+		}
+	}
+	
+	if v.config.MultiCluster.SecretRefreshInterval < time.Second && hasKubeconfigSecret(v.config.MultiCluster.Clusters) {
+		return errors.NewValidationError(fmt.Sprintf("secret refresh interval must be at least 1 second, got %v", v.config.MultiCluster.SecretRefreshInterval))
+	}
+	
+	if v.config.MultiCluster.HealthCheckInterval < time.Second {
+		return errors.NewValidationError(fmt.Sprintf("health check interval must be at least 1 second, got %v", v.config.MultiCluster.HealthCheckInterval))
+	}
+	
 	// Validate clusters
 	if len(v.config.MultiCluster.Clusters) == 0 && v.config.Controller.Mode == "multi" {
 		return errors.NewValidationError("multi-cluster mode requires at least one cluster configuration")
@@ -111,6 +327,121 @@ func (v *ConfigValidator) ValidateMultiCluster() error {
 	return nil
 }
 
+// validateKubeconfigSecret validates a cluster's kubeconfig Secret reference
+func (v *ConfigValidator) validateKubeconfigSecret(cluster ClusterConfig) error {
+	ref := cluster.KubeconfigSecret
+	if ref == (SecretKeyRef{}) {
+		return nil
+	}
+	
+	if cluster.KubeConfig != "" {
+		return errors.NewValidationError(fmt.Sprintf("cluster '%s' cannot set both kubeconfig and kubeconfig_secret", cluster.Name))
+	}
+	if ref.Namespace == "" || ref.Name == "" {
+		return errors.NewValidationError(fmt.Sprintf("kubeconfig_secret for cluster '%s' requires namespace and name", cluster.Name))
+	}
+	if !v.isValidKubernetesName(ref.Namespace) {
+		return errors.NewValidationError(fmt.Sprintf("invalid kubeconfig_secret namespace '%s' for cluster '%s'", ref.Namespace, cluster.Name))
+	}
+	return nil
+}
+
+// hasKubeconfigSecret reports whether a cluster reads its kubeconfig from a Secret
+func hasKubeconfigSecret(clusters []ClusterConfig) bool {
+	for _, cluster := range clusters {
+		if cluster.KubeconfigSecret.IsSet() {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDrift validates drift detection groups. Clusters are not required to
+// be configured, so that removing a cluster doesn't invalidate the file; the
+// drift report shows them as unavailable instead.
+func (v *ConfigValidator) validateDrift() error {
+	drift := v.config.MultiCluster.Drift
+	if len(drift.Groups) == 0 {
+		return nil
+	}
+	
+	if drift.Interval < time.Second {
+		return errors.NewValidationError(fmt.Sprintf("drift interval must be at least 1 second, got %v", drift.Interval))
+	}
+	
+	names := make(map[string]bool)
+	for i, group := range drift.Groups {
+		if group.Name == "" {
+			return errors.NewValidationError(fmt.Sprintf("drift group at index %d: name cannot be empty", i))
+		}
+		if names[group.Name] {
+			return errors.NewValidationError(fmt.Sprintf("duplicate drift group name '%s'", group.Name))
+		}
+		names[group.Name] = true
+		
+		clusters := make(map[string]bool)
+		for _, name := range group.Clusters {
+			clusters[name] = true
+		}
+		if len(clusters) < 2 {
+			return errors.NewValidationError(fmt.Sprintf("drift group '%s' must compare at least 2 clusters", group.Name))
+		}
+	}
+	
+	return nil
+}
+
+// validateFailover validates primary cluster failover when it is enabled
+func (v *ConfigValidator) validateFailover() error {
+	failover := v.config.MultiCluster.Failover
+	if !failover.Enabled {
+		return nil
+	}
+	
+	if failover.Interval < time.Second {
+		return errors.NewValidationError(fmt.Sprintf("failover interval must be at least 1 second, got %v", failover.Interval))
+	}
+	if failover.FailureThreshold < 1 {
+		return errors.NewValidationError(fmt.Sprintf("failover failure threshold must be at least 1, got %d", failover.FailureThreshold))
+	}
+	
+	switch failover.Policy {
+	case FailoverPolicyPriority:
+	case FailoverPolicyCandidates:
+		if len(failover.Candidates) == 0 {
+			return errors.NewValidationError("failover policy 'candidates' requires at least one candidate cluster")
+		}
+	default:
+		return errors.NewValidationError(fmt.Sprintf("invalid failover policy '%s', must be '%s' or '%s'", failover.Policy, FailoverPolicyPriority, FailoverPolicyCandidates))
+	}
+	
+	return nil
+}
+
+// validateSharding validates sharding of the clusters across replicas
+func (v *ConfigValidator) validateSharding() error {
+	sharding := v.config.MultiCluster.Sharding
+	if !sharding.Enabled {
+		return nil
+	}
+	
+	if !v.isValidKubernetesName(sharding.Group) {
+		return errors.NewValidationError(fmt.Sprintf("invalid sharding group '%s'", sharding.Group))
+	}
+	if sharding.Namespace != "" && !v.isValidKubernetesName(sharding.Namespace) {
+		return errors.NewValidationError(fmt.Sprintf("invalid sharding namespace '%s'", sharding.Namespace))
+	}
+	if sharding.Interval < time.Second {
+		return errors.NewValidationError(fmt.Sprintf("sharding interval must be at least 1 second, got %v", sharding.Interval))
+	}
+	// A replica must renew its Lease before the others consider it gone
+	if sharding.LeaseDuration <= sharding.Interval {
+		return errors.NewValidationError(fmt.Sprintf("sharding lease duration (%v) must be greater than the interval (%v)", sharding.LeaseDuration, sharding.Interval))
+	}
+	
+	return nil
+}
+
 // ValidateNetwork validates network-related configuration
 func (v *ConfigValidator) ValidateNetwork() error {
 	// Validate ports
@@ -127,18 +458,223 @@ func (v *ConfigValidator) ValidateNetwork() error {
 		return errors.NewValidationError("metrics port and health port cannot be the same")
 	}
 	
+	if err := v.validateServer(); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
-// validateSingleCluster validates single cluster configuration
-func (v *ConfigValidator) validateSingleCluster() error {
-	// Validate namespace (if specified)
-	if v.config.Controller.Single.Namespace != "" {
-		if !v.isValidKubernetesName(v.config.Controller.Single.Namespace) {
-			return errors.NewValidationError(fmt.Sprintf("invalid namespace name '%s'", v.config.Controller.Single.Namespace))
+// validateServer validates HTTP API server configuration
+func (v *ConfigValidator) validateServer() error {
+	if err := v.validatePort("server port", v.config.Server.Port); err != nil {
+		return err
+	}
+
+	for _, field := range v.config.Server.FieldMask {
+		if !IsDeploymentField(field) {
+			return errors.NewValidationError(fmt.Sprintf("invalid field '%s' in server field mask", field))
+		}
+	}
+
+	if key := v.config.Server.AccessLog.HashKey; key != "" && len(key) < 16 {
+		return errors.NewValidationError("access log hash key must be at least 16 bytes")
+	}
+	if v.config.Server.AccessLog.File != "" {
+		if err := validateFilePath(v.config.Server.AccessLog.File); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("invalid access log file '%s': %v", v.config.Server.AccessLog.File, err))
+		}
+	}
+
+	if v.config.Server.GRPC.Enabled {
+		if err := v.validatePort("gRPC port", v.config.Server.GRPC.Port); err != nil {
+			return err
 		}
+		if v.config.Server.GRPC.Port == v.config.Server.Port {
+			return errors.NewValidationError(fmt.Sprintf("gRPC port %d conflicts with server port", v.config.Server.GRPC.Port))
+		}
+	}
+
+	if port := v.config.Server.MetricsPort; port != 0 {
+		if err := v.validatePort("metrics port", port); err != nil {
+			return err
+		}
+		if port == v.config.Server.Port || (v.config.Server.GRPC.Enabled && port == v.config.Server.GRPC.Port) {
+			return errors.NewValidationError(fmt.Sprintf("metrics port %d conflicts with another server port", port))
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, token := range v.config.Server.Auth.Tokens {
+		if token.Name == "" || token.Token == "" {
+			return errors.NewValidationError(fmt.Sprintf("API token %d requires a name and a token", i))
+		}
+		if names[token.Name] {
+			return errors.NewValidationError(fmt.Sprintf("duplicate API token name '%s'", token.Name))
+		}
+		names[token.Name] = true
+		if token.Role != "" {
+			if _, err := security.ParseRole(token.Role); err != nil {
+				return errors.NewValidationError(fmt.Sprintf("API token '%s': %v", token.Name, err))
+			}
+		}
+	}
+	for i, binding := range v.config.Server.Auth.RoleBindings {
+		if _, err := security.ParseRole(binding.Role); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("role binding %d: %v", i, err))
+		}
+		if len(binding.Users) == 0 && len(binding.Groups) == 0 {
+			return errors.NewValidationError(fmt.Sprintf("role binding %d requires users or groups", i))
+		}
+	}
+	if role := v.config.Server.Auth.DefaultRole; role != "" {
+		if _, err := security.ParseRole(role); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("default role: %v", err))
+		}
+	}
+
+	tlsConfig := v.config.Server.TLS
+	if (tlsConfig.CertFile == "") != (tlsConfig.KeyFile == "") {
+		return errors.NewValidationError("server TLS requires both a certificate file and a key file")
+	}
+	for _, file := range []string{tlsConfig.CertFile, tlsConfig.KeyFile} {
+		if file == "" {
+			continue
+		}
+		if err := validateFilePath(file); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("invalid server TLS file '%s': %v", file, err))
+		}
+	}
+	if tlsConfig.ReloadInterval < 0 {
+		return errors.NewValidationError("server TLS reload interval cannot be negative")
+	}
+
+	if v.config.Server.Auth.TokenReview.CacheTTL < 0 {
+		return errors.NewValidationError("token review cache TTL cannot be negative")
+	}
+	if v.config.Server.Auth.TokenReview.CacheSize < 0 {
+		return errors.NewValidationError("token review cache size cannot be negative")
+	}
+	if file := v.config.Server.Auth.ClientCAFile; file != "" {
+		if err := validateFilePath(file); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("invalid client CA file '%s': %v", file, err))
+		}
+	}
+
+	limits := v.config.Server.Limits
+	if limits.RateLimit.RequestsPerSecond < 0 || limits.RateLimit.Burst < 0 {
+		return errors.NewValidationError("server rate limit and burst cannot be negative")
+	}
+	if key := limits.RateLimit.Key; key != "" && key != "ip" && key != "token" {
+		return errors.NewValidationError(fmt.Sprintf("invalid rate limit key '%s', must be ip or token", key))
+	}
+	if limits.MaxBodySize < 0 {
+		return errors.NewValidationError("server max body size cannot be negative")
+	}
+	if limits.RequestTimeout < 0 {
+		return errors.NewValidationError("server request timeout cannot be negative")
+	}
+	if limits.ClusterListTTL < 0 {
+		return errors.NewValidationError("server cluster list TTL cannot be negative")
+	}
+	if cache := v.config.Server.ResponseCache; cache.TTL < 0 || cache.MaxEntries < 0 {
+		return errors.NewValidationError("server response cache ttl and max entries cannot be negative")
+	}
+
+	if v.config.Server.ShutdownTimeout < 0 {
+		return errors.NewValidationError("server shutdown timeout cannot be negative")
+	}
+	if v.config.Server.Probes.SyncTimeout < 0 {
+		return errors.NewValidationError("probe sync timeout cannot be negative")
+	}
+
+	for _, key := range v.config.Server.LabelPropagation.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return errors.NewValidationError(fmt.Sprintf("invalid propagated label key '%s': %s", key, errs[0]))
+		}
+	}
+
+	if prefix := v.config.Server.PathPrefix; prefix != "" {
+		if !strings.HasPrefix(prefix, "/") || strings.HasSuffix(prefix, "/") || strings.ContainsAny(prefix, "?#") {
+			return errors.NewValidationError(fmt.Sprintf("invalid path prefix '%s', must start with '/' and not end with '/'", prefix))
+		}
+	}
+
+	for _, origin := range v.config.Server.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.Path != "" {
+			return errors.NewValidationError(fmt.Sprintf("invalid CORS origin '%s', must be '*' or scheme://host[:port]", origin))
+		}
+	}
+
+	if v.config.Server.CORS.MaxAge < 0 {
+		return errors.NewValidationError(fmt.Sprintf("CORS max age must not be negative, got %v", v.config.Server.CORS.MaxAge))
+	}
+
+	for _, proxy := range v.config.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return errors.NewValidationError(fmt.Sprintf("invalid trusted proxy '%s', must be an IP address or CIDR range", proxy))
+			}
+		}
+	}
+
+	return nil
+}
+
+// builtinIndexes are the indexes every deployment cache has
+var builtinIndexes = []string{"app", "image", "namespace", "owner", "configSource"}
+
+// validateIndexes validates the additional indexes of the deployment cache
+func validateIndexes(indexes []IndexConfig) error {
+	names := make(map[string]bool, len(indexes))
+	for _, name := range builtinIndexes {
+		names[name] = true
+	}
+	for i, index := range indexes {
+		if index.Name == "" {
+			return errors.NewValidationError(fmt.Sprintf("informer index %d: name is required", i))
+		}
+		if names[index.Name] {
+			return errors.NewValidationError(fmt.Sprintf("informer index '%s' is already defined", index.Name))
+		}
+		names[index.Name] = true
+		if strings.TrimSpace(index.Field) == "" {
+			return errors.NewValidationError(fmt.Sprintf("informer index '%s': field is required", index.Name))
+		}
+		if err := jsonpath.New(index.Name).Parse(index.JSONPath()); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("informer index '%s': invalid field '%s': %v", index.Name, index.Field, err))
+		}
+	}
+	return nil
+}
+
+// validateNamespaceScope validates the namespaces watched and excluded
+func (v *ConfigValidator) validateNamespaceScope() error {
+	single := v.config.Controller.Single
+	for _, namespace := range strings.Split(single.Namespace, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace != "" && !v.isValidKubernetesName(namespace) {
+			return errors.NewValidationError(fmt.Sprintf("invalid namespace name '%s'", namespace))
+		}
+	}
+	for _, namespace := range single.ExcludeNamespaces {
+		if !v.isValidKubernetesName(strings.TrimSpace(namespace)) {
+			return errors.NewValidationError(fmt.Sprintf("invalid excluded namespace name '%s'", namespace))
+		}
+	}
+	if strings.TrimSpace(strings.ReplaceAll(single.Namespace, ",", "")) != "" && single.NamespaceScope().All() {
+		return errors.NewValidationError(fmt.Sprintf("every namespace of '%s' is excluded", single.Namespace))
 	}
 	
+	return nil
+}
+
+// validateSingleCluster validates single cluster configuration
+func (v *ConfigValidator) validateSingleCluster() error {
 	// Validate leader election
 	if v.config.Controller.Single.LeaderElection.Enabled {
 		if v.config.Controller.Single.LeaderElection.ID == "" {
@@ -154,11 +690,36 @@ func (v *ConfigValidator) validateSingleCluster() error {
 				return errors.NewValidationError(fmt.Sprintf("invalid leader election namespace '%s'", v.config.Controller.Single.LeaderElection.Namespace))
 			}
 		}
+		
+		if err := validateLeaderElectionTuning(v.config.Controller.Single.LeaderElection); err != nil {
+			return err
+		}
 	}
 	
 	return nil
 }
 
+// validateLeaderElectionTuning validates the resource lock and timings of
+// leader election, which client-go checks only once the manager starts
+func validateLeaderElectionTuning(election LeaderElectionConfig) error {
+	if lock := election.ResourceLock; lock != "" && lock != LeasesResourceLock {
+		return errors.NewValidationError(fmt.Sprintf("unsupported leader election resource lock '%s', must be %s", lock, LeasesResourceLock))
+	}
+	if election.LeaseDuration < 0 || election.RenewDeadline < 0 || election.RetryPeriod < 0 {
+		return errors.NewValidationError("leader election lease_duration, renew_deadline and retry_period must not be negative")
+	}
+	
+	leaseDuration, renewDeadline, retryPeriod := election.Timings()
+	if leaseDuration <= renewDeadline {
+		return errors.NewValidationError(fmt.Sprintf("leader election lease_duration %s must be longer than renew_deadline %s", leaseDuration, renewDeadline))
+	}
+	// client-go retries with up to 20% jitter
+	if renewDeadline <= time.Duration(1.2*float64(retryPeriod)) {
+		return errors.NewValidationError(fmt.Sprintf("leader election renew_deadline %s must be longer than 1.2 times retry_period %s", renewDeadline, retryPeriod))
+	}
+	return nil
+}
+
 // validateCluster validates a single cluster configuration
 func (v *ConfigValidator) validateCluster(index int, cluster ClusterConfig) error {
 	if cluster.Name == "" {
@@ -173,6 +734,52 @@ func (v *ConfigValidator) validateCluster(index int, cluster ClusterConfig) erro
 		return errors.NewValidationError(fmt.Sprintf("invalid namespace '%s' for cluster '%s'", cluster.Namespace, cluster.Name))
 	}
 	
+	if err := v.validateKubeconfigSecret(cluster); err != nil {
+		return err
+	}
+	
+	if cluster.InsecureSkipTLSVerify && cluster.CAFile != "" {
+		return errors.NewValidationError(fmt.Sprintf("cluster '%s' cannot set both insecure_skip_tls_verify and ca_file", cluster.Name))
+	}
+	
+	if cluster.CAFile != "" {
+		if err := validateFilePath(cluster.CAFile); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("invalid ca_file for cluster '%s': %v", cluster.Name, err))
+		}
+	}
+	
+	if cluster.ProxyURL != "" {
+		if err := ValidateProxyURL(cluster.ProxyURL); err != nil {
+			return errors.NewValidationError(fmt.Sprintf("invalid proxy_url for cluster '%s': %v", cluster.Name, err))
+		}
+	}
+	
+	return nil
+}
+
+// ValidateCluster validates a single cluster configuration, e.g. one about to be added
+func ValidateCluster(cluster ClusterConfig) error {
+	return NewConfigValidator(&Config{}).validateCluster(0, cluster)
+}
+
+// ValidateProxyURL checks that a proxy URL is absolute and uses a supported scheme.
+// "direct" is accepted and bypasses proxies from the environment.
+func ValidateProxyURL(proxyURL string) error {
+	if proxyURL == "direct" {
+		return nil
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return err
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("unsupported scheme '%s', must be http, https or socks5", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("missing host")
+	}
 	return nil
 }
 
@@ -189,6 +796,19 @@ func (v *ConfigValidator) validatePort(name string, port int) error {
 	return nil
 }
 
+// DeploymentFields lists the deployment fields the API can return
+var DeploymentFields = []string{"name", "namespace", "replicas", "ready", "updated", "available", "age", "image", "labels", "specHash"}
+
+// IsDeploymentField checks if a field name can be used in a field mask
+func IsDeploymentField(field string) bool {
+	for _, valid := range DeploymentFields {
+		if field == valid {
+			return true
+		}
+	}
+	return false
+}
+
 // isValidLogLevel checks if log level is valid
 func (v *ConfigValidator) isValidLogLevel(level string) bool {
 	validLevels := []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}
@@ -244,6 +864,41 @@ func (v *ConfigValidator) addWarnings(report *ValidationReport) {
 		report.Warnings = append(report.Warnings, "leader election is disabled, this may cause issues in high-availability setups")
 	}
 	
+	// Note when large-cluster mode trims cached objects
+	if v.config.LargeClusterMode {
+		report.Warnings = append(report.Warnings, "large-cluster mode is enabled: cached deployments omit resources, env and probes, and the revisions, events, config and routes endpoints are disabled")
+	}
+	
+	// Warn about access logs that record raw principals
+	if v.config.Server.AccessLog.Enabled && !v.config.Server.AccessLog.HashPrincipals {
+		report.Warnings = append(report.Warnings, "access log records principals in clear text, consider enabling hash_principals")
+	}
+	if v.config.Server.AccessLog.Enabled && v.config.Server.AccessLog.HashPrincipals && v.config.Server.AccessLog.HashKey == "" {
+		report.Warnings = append(report.Warnings, "access log hash_key is not set, principal hashes change on every restart")
+	}
+	
+	// Warn about short API tokens
+	for _, token := range v.config.Server.Auth.Tokens {
+		if len(token.Token) < 16 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("API token '%s' is shorter than 16 characters", token.Name))
+		}
+		if token.Role == "" {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("API token '%s' has no role, it gets the role bound to its name or the default role", token.Name))
+		}
+	}
+	
+	// Client certificates are only presented over TLS
+	if v.config.Server.Auth.ClientCAFile != "" && !v.config.Server.TLS.Enabled() {
+		report.Warnings = append(report.Warnings, "client_ca_file only authenticates requests served over TLS, but server.tls is not configured")
+	}
+	
+	// Warn about clusters that skip TLS verification
+	for _, cluster := range v.config.MultiCluster.Clusters {
+		if cluster.InsecureSkipTLSVerify {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("cluster '%s' skips TLS certificate verification, consider setting ca_file instead", cluster.Name))
+		}
+	}
+	
 	// Warn about too many concurrent connections
 	if v.config.MultiCluster.MaxConcurrentConns > 100 {
 		report.Warnings = append(report.Warnings, fmt.Sprintf("max concurrent connections (%d) is very high and may cause resource exhaustion", v.config.MultiCluster.MaxConcurrentConns))
@@ -261,3 +916,204 @@ type ValidationReport struct {
 	Warnings []string `json:"warnings,omitempty"`
 	Errors   []string `json:"errors,omitempty"`
 }
+
+// validateWebhook validates the defaults injected by the mutating webhook
+func validateWebhook(webhook WebhookConfig) error {
+	if webhook.Port < 1 || webhook.Port > 65535 {
+		return errors.NewValidationError(fmt.Sprintf("invalid webhook port %d, must be between 1 and 65535", webhook.Port))
+	}
+	for _, namespace := range webhook.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return errors.NewValidationError(fmt.Sprintf("invalid webhook namespace '%s': %s", namespace, errs[0]))
+		}
+	}
+	
+	requests, limits := webhook.Resources.Requests, webhook.Resources.Limits
+	for _, quantities := range []map[string]string{requests, limits} {
+		for name, value := range quantities {
+			if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+				return errors.NewValidationError(fmt.Sprintf("invalid webhook resource name '%s': %s", name, errs[0]))
+			}
+			if _, err := resource.ParseQuantity(value); err != nil {
+				return errors.NewValidationError(fmt.Sprintf("invalid webhook quantity '%s' for %s: %v", value, name, err))
+			}
+		}
+	}
+	for name, request := range requests {
+		limit, ok := limits[name]
+		if !ok {
+			continue
+		}
+		if requested := resource.MustParse(request); requested.Cmp(resource.MustParse(limit)) > 0 {
+			return errors.NewValidationError(fmt.Sprintf("webhook %s request %s exceeds its limit %s", name, request, limit))
+		}
+	}
+	
+	for key, value := range webhook.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return errors.NewValidationError(fmt.Sprintf("invalid webhook label key '%s': %s", key, errs[0]))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return errors.NewValidationError(fmt.Sprintf("invalid webhook label value '%s': %s", value, errs[0]))
+		}
+	}
+	
+	if spread := webhook.TopologySpread; spread.Enabled {
+		if errs := validation.IsQualifiedName(spread.TopologyKey); len(errs) > 0 {
+			return errors.NewValidationError(fmt.Sprintf("invalid topology spread key '%s': %s", spread.TopologyKey, errs[0]))
+		}
+		if spread.MaxSkew < 1 {
+			return errors.NewValidationError(fmt.Sprintf("topology spread max_skew must be at least 1, got %d", spread.MaxSkew))
+		}
+		if spread.WhenUnsatisfiable != "DoNotSchedule" && spread.WhenUnsatisfiable != "ScheduleAnyway" {
+			return errors.NewValidationError(fmt.Sprintf("invalid topology spread when_unsatisfiable '%s', must be DoNotSchedule or ScheduleAnyway", spread.WhenUnsatisfiable))
+		}
+	}
+	return nil
+}
+
+// validateCompliance validates the interval, registries and rule severities
+// of image compliance scanning
+func validateCompliance(compliance ComplianceConfig) error {
+	if compliance.Interval < time.Second {
+		return errors.NewValidationError(fmt.Sprintf("compliance interval must be at least 1 second, got %v", compliance.Interval))
+	}
+	for _, registry := range append(append([]string{}, compliance.DisallowedRegistries...), compliance.AllowedRegistries...) {
+		if strings.TrimSpace(registry) == "" || strings.ContainsAny(registry, " @") {
+			return errors.NewValidationError(fmt.Sprintf("invalid compliance registry '%s'", registry))
+		}
+	}
+	for _, rule := range []struct{ key, severity string }{
+		{"registry_severity", compliance.RegistrySeverity},
+		{"latest_tag_severity", compliance.LatestTagSeverity},
+		{"missing_digest_severity", compliance.MissingDigestSeverity},
+	} {
+		switch rule.severity {
+		case "critical", "high", "medium", "low", "none":
+		default:
+			return errors.NewValidationError(fmt.Sprintf("invalid compliance %s '%s', must be critical, high, medium, low or none", rule.key, rule.severity))
+		}
+	}
+	return nil
+}
+
+// validateFinalizer validates the selector and notification URL of the
+// cleanup finalizer
+func validateFinalizer(finalizer FinalizerConfig) error {
+	for key, value := range finalizer.Selector {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return errors.NewValidationError(fmt.Sprintf("invalid finalizer selector key '%s': %s", key, errs[0]))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return errors.NewValidationError(fmt.Sprintf("invalid finalizer selector value '%s': %s", value, errs[0]))
+		}
+	}
+	if finalizer.NotifyURL != "" {
+		if u, err := url.Parse(finalizer.NotifyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.NewValidationError(fmt.Sprintf("invalid finalizer notify_url '%s', must be an http or https URL", finalizer.NotifyURL))
+		}
+	}
+	return nil
+}
+
+// validateAnomalies validates the trend window and restart spike thresholds
+// of anomaly detection
+func validateAnomalies(anomalies AnomalyConfig) error {
+	if anomalies.Interval < time.Second {
+		return errors.NewValidationError(fmt.Sprintf("anomaly detection interval must be at least 1 second, got %v", anomalies.Interval))
+	}
+	if anomalies.Window < 2*anomalies.Interval {
+		return errors.NewValidationError(fmt.Sprintf("anomaly detection window must be at least twice the interval %v, got %v", anomalies.Interval, anomalies.Window))
+	}
+	if anomalies.RestartSpikeMin < 1 {
+		return errors.NewValidationError(fmt.Sprintf("anomaly detection restart_spike_min must be at least 1, got %d", anomalies.RestartSpikeMin))
+	}
+	if anomalies.RestartSpikeFactor < 1 {
+		return errors.NewValidationError(fmt.Sprintf("anomaly detection restart_spike_factor must be at least 1, got %v", anomalies.RestartSpikeFactor))
+	}
+	return nil
+}
+
+// validateNotifications validates the sinks and rules of notifications
+func validateNotifications(notifications NotificationsConfig) error {
+	if notifications.MaxAttempts < 1 {
+		return errors.NewValidationError(fmt.Sprintf("notifications max_attempts must be at least 1, got %d", notifications.MaxAttempts))
+	}
+	if notifications.RetryBackoff < 0 {
+		return errors.NewValidationError(fmt.Sprintf("invalid notifications retry_backoff %v, must not be negative", notifications.RetryBackoff))
+	}
+	if notifications.RateLimit < 0 {
+		return errors.NewValidationError(fmt.Sprintf("invalid notifications rate_limit %d, must not be negative", notifications.RateLimit))
+	}
+	
+	sinks := make(map[string]bool, len(notifications.Sinks))
+	for _, sink := range notifications.Sinks {
+		if sink.Name == "" {
+			return errors.NewValidationError("notification sink name cannot be empty")
+		}
+		if sinks[sink.Name] {
+			return errors.NewValidationError(fmt.Sprintf("duplicate notification sink '%s'", sink.Name))
+		}
+		sinks[sink.Name] = true
+		
+		switch sink.Type {
+		case "slack", "webhook":
+			if u, err := url.Parse(sink.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return errors.NewValidationError(fmt.Sprintf("invalid url '%s' for notification sink '%s', must be an http or https URL", sink.URL, sink.Name))
+			}
+		case "email":
+			smtp := sink.SMTP
+			if smtp.Host == "" || smtp.From == "" || len(smtp.To) == 0 {
+				return errors.NewValidationError(fmt.Sprintf("email notification sink '%s' requires smtp host, from and to", sink.Name))
+			}
+			if smtp.Port < 1 || smtp.Port > 65535 {
+				return errors.NewValidationError(fmt.Sprintf("invalid smtp port %d for notification sink '%s'", smtp.Port, sink.Name))
+			}
+		default:
+			return errors.NewValidationError(fmt.Sprintf("invalid type '%s' for notification sink '%s', must be slack, webhook or email", sink.Type, sink.Name))
+		}
+	}
+	
+	for i, rule := range notifications.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if len(rule.Sinks) == 0 {
+			return errors.NewValidationError(fmt.Sprintf("notification rule '%s' has no sinks", name))
+		}
+		for _, sink := range rule.Sinks {
+			if !sinks[sink] {
+				return errors.NewValidationError(fmt.Sprintf("notification rule '%s' references unknown sink '%s'", name, sink))
+			}
+		}
+		for _, event := range rule.Events {
+			switch event {
+			case "added", "updated", "deleted", "rollout-stuck", "restart-spike", "scale-to-zero", "failover":
+			default:
+				return errors.NewValidationError(fmt.Sprintf("invalid event '%s' in notification rule '%s', must be added, updated, deleted, rollout-stuck, restart-spike, scale-to-zero or failover", event, name))
+			}
+		}
+		for _, source := range rule.Sources {
+			switch source {
+			case "informer", "controller", "rollout", "anomaly", "failover":
+			default:
+				return errors.NewValidationError(fmt.Sprintf("invalid source '%s' in notification rule '%s', must be informer, controller, rollout, anomaly or failover", source, name))
+			}
+		}
+		for _, change := range rule.Changes {
+			if change != "replicas" && change != "image" && change != "resources" && change != "labels" {
+				return errors.NewValidationError(fmt.Sprintf("invalid change '%s' in notification rule '%s', must be replicas, image, resources or labels", change, name))
+			}
+		}
+		for key, value := range rule.Selector {
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				return errors.NewValidationError(fmt.Sprintf("invalid selector key '%s' in notification rule '%s': %s", key, name, errs[0]))
+			}
+			if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+				return errors.NewValidationError(fmt.Sprintf("invalid selector value '%s' in notification rule '%s': %s", value, name, errs[0]))
+			}
+		}
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -61,7 +65,10 @@ func TestDeploymentReconciler_Reconcile(t *testing.T) {
 		t.Errorf("unexpected error on add: %v", err)
 	}
 
-	// Test case 2: Update deployment (update event)
+	// Test case 2: Update deployment (update event); the reconciler annotated it
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(deploy), deploy); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
 	deploy.Generation = 2 // Increment generation = update event
 	deploy.Spec.Replicas = int32Ptr(3)
 	deploy.Spec.Template.Spec.Containers[0].Image = "nginx:1.16"
@@ -141,3 +148,88 @@ func TestDeploymentReconciler_EventTypes(t *testing.T) {
 }
 
 func int32Ptr(i int32) *int32 { return &i }
+
+func TestDeploymentReconcilerMetrics(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	registry := prometheus.NewRegistry()
+	m, err := metrics.NewWithRegisterer(registry)
+	if err != nil {
+		t.Fatalf("NewWithRegisterer() error = %v", err)
+	}
+	// Metrics registered again are shared
+	if again, err := metrics.NewWithRegisterer(registry); err != nil || again.DeploymentEvents != m.DeploymentEvents {
+		t.Fatalf("Expected registered metrics to be reused, got %v", err)
+	}
+
+	reconciler := &DeploymentReconciler{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Log:     logr.Discard(),
+		Scheme:  scheme,
+		cluster: "prod",
+	}
+	reconciler.SetMetrics(m)
+
+	_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{
+		NamespacedName: client.ObjectKey{Namespace: "default", Name: "gone"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := testutil.ToFloat64(m.DeploymentEvents.WithLabelValues("prod", "default", "delete")); got != 1 {
+		t.Errorf("Expected one delete event, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.ReconciliationDuration, "k6s_reconciliation_duration_seconds"); got != 1 {
+		t.Errorf("Expected a reconcile duration series, got %d", got)
+	}
+}
+
+// recordedState records the deployments a reconciler restores
+type recordedState struct {
+	calls []string
+	err   error
+}
+
+func (s *recordedState) ReconcileDeployment(ctx context.Context, namespace, name string, live *appsv1.Deployment) error {
+	call := namespace + "/" + name
+	if live == nil {
+		call += " (deleted)"
+	}
+	s.calls = append(s.calls, call)
+	return s.err
+}
+
+func TestDeploymentReconcilerDesiredState(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}
+	reconciler := &DeploymentReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(deploy).Build(),
+		Log:    logr.Discard(),
+		Scheme: scheme,
+	}
+	desired := &recordedState{}
+	reconciler.SetDesiredState(desired)
+
+	for _, name := range []string{"web", "gone"} {
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: client.ObjectKey{Namespace: "shop", Name: name},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(desired.calls) != 2 || desired.calls[0] != "shop/web" || desired.calls[1] != "shop/gone (deleted)" {
+		t.Errorf("Expected shop/web and the deleted shop/gone to be restored, got %v", desired.calls)
+	}
+
+	// Failures are retried
+	desired.err = errors.New("apply failed")
+	_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+		NamespacedName: client.ObjectKey{Namespace: "shop", Name: "web"},
+	})
+	if err == nil {
+		t.Error("Expected the desired state error to be returned")
+	}
+}
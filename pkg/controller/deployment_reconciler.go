@@ -3,11 +3,18 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/notify"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/telemetry"
 	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -17,6 +24,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
+// reconcilerName is the controller label of the reconciler's metrics, and
+// names it in spans and logs
+const reconcilerName = "deployment"
+
 // DeploymentReconciler reconciles a Deployment object
 type DeploymentReconciler struct {
 	client.Client
@@ -25,22 +36,141 @@ type DeploymentReconciler struct {
 	
 	// Configuration
 	cluster     string
-	namespace   string
+	namespaces  config.NamespaceScope
 	concurrency int
+	
+	// Reconcile statistics
+	stats *ReconcileStats
+	
+	// Prometheus metrics, nil when not recorded
+	metrics *metrics.Metrics
+
+	// Desired state deployments are reconciled to, nil to only log events
+	desired DesiredState
+	
+	// Enforces deployment policies, nil when they are disabled
+	policies *PolicyEnforcer
+	
+	// Whether cleanup hooks run through the finalizer, the labels of
+	// deployments it is added to (nil = none) and the hooks
+	finalize          bool
+	finalizerSelector labels.Selector
+	hooks             []CleanupHook
+	
+	// Whether status annotations are left unwritten
+	readOnly bool
+	
+	// Delivers deployment events matching notification rules, nil when
+	// notifications are disabled
+	notifier *notify.Notifier
+}
+
+// DesiredState declares the state of deployments and restores it. live is
+// nil when the deployment was deleted.
+type DesiredState interface {
+	ReconcileDeployment(ctx context.Context, namespace, name string, live *appsv1.Deployment) error
+}
+
+// ReconcileSummary summarizes reconcile activity
+type ReconcileSummary struct {
+	Total          int64  `json:"total"`
+	Errors         int64  `json:"errors"`
+	LastTime       string `json:"lastTime,omitempty"`
+	LastDurationMs int64  `json:"lastDurationMs"`
+	LastError      string `json:"lastError,omitempty"`
+}
+
+// ReconcileStats tracks reconcile activity for status reporting
+type ReconcileStats struct {
+	mu       sync.Mutex
+	summary  ReconcileSummary
+	lastTime time.Time
+}
+
+// Record records the outcome of a single reconcile
+func (s *ReconcileStats) Record(start time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.summary.Total++
+	s.summary.LastDurationMs = time.Since(start).Milliseconds()
+	s.lastTime = start
+	s.summary.LastTime = start.UTC().Format(time.RFC3339)
+	if err != nil {
+		s.summary.Errors++
+		s.summary.LastError = err.Error()
+	}
+}
+
+// Summary returns a snapshot of the recorded statistics
+func (s *ReconcileStats) Summary() ReconcileSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.summary
+}
+
+// mergeReconcileStats combines statistics from several reconcilers,
+// keeping the most recent reconcile's timing and error
+func mergeReconcileStats(stats ...*ReconcileStats) ReconcileSummary {
+	var merged ReconcileSummary
+	var latest time.Time
+	for _, s := range stats {
+		if s == nil {
+			continue
+		}
+		s.mu.Lock()
+		merged.Total += s.summary.Total
+		merged.Errors += s.summary.Errors
+		if s.lastTime.After(latest) {
+			latest = s.lastTime
+			merged.LastTime = s.summary.LastTime
+			merged.LastDurationMs = s.summary.LastDurationMs
+		}
+		if s.summary.LastError != "" && merged.LastError == "" {
+			merged.LastError = s.summary.LastError
+		}
+		s.mu.Unlock()
+	}
+	return merged
 }
 
 // NewDeploymentReconciler creates a new DeploymentReconciler
-func NewDeploymentReconciler(mgr manager.Manager, cluster, namespace string, concurrency int) *DeploymentReconciler {
+func NewDeploymentReconciler(mgr manager.Manager, cluster string, namespaces config.NamespaceScope, concurrency int) *DeploymentReconciler {
 	return &DeploymentReconciler{
 		Client:      mgr.GetClient(),
-		Log:         logger.WithComponent("deployment-controller").WithCluster(cluster).GetLogr(),
+		Log:         logger.WithComponent(logger.ComponentReconciler).WithField("reconciler", reconcilerName).WithCluster(cluster).GetLogr(),
 		Scheme:      mgr.GetScheme(),
 		cluster:     cluster,
-		namespace:   namespace,
+		namespaces:  namespaces,
 		concurrency: concurrency,
+		stats:       &ReconcileStats{},
 	}
 }
 
+// SetMetrics records reconcile durations, errors and deployment events in m.
+// It must be called before the manager starts.
+func (r *DeploymentReconciler) SetMetrics(m *metrics.Metrics) {
+	r.metrics = m
+}
+
+// SetDesiredState makes the reconciler restore the deployments desired
+// declares. It must be called before the manager starts.
+func (r *DeploymentReconciler) SetDesiredState(desired DesiredState) {
+	r.desired = desired
+}
+
+// SetPolicyEnforcer makes the reconciler enforce the K6sDeploymentPolicy
+// objects of each deployment's namespace. It must be called before the
+// manager starts.
+func (r *DeploymentReconciler) SetPolicyEnforcer(policies *PolicyEnforcer) {
+	r.policies = policies
+}
+
+// Stats returns the reconciler's statistics
+func (r *DeploymentReconciler) Stats() *ReconcileStats {
+	return r.stats
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *DeploymentReconciler) SetupWithManager(mgr manager.Manager) error {
 	// Build the controller with predicates
@@ -62,50 +192,95 @@ func (r *DeploymentReconciler) createEventFilter() predicate.Predicate {
 	)
 }
 
-// createNamespaceFilter creates a namespace filter if specified
+// createNamespaceFilter creates a filter of the watched namespaces
 func (r *DeploymentReconciler) createNamespaceFilter() predicate.Predicate {
-	if r.namespace == "" {
-		return predicate.NewPredicateFuncs(func(object client.Object) bool {
-			return true
-		})
-	}
-	
 	return predicate.NewPredicateFuncs(func(object client.Object) bool {
-		return object.GetNamespace() == r.namespace
+		return r.namespaces.Contains(object.GetNamespace())
 	})
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop
-func (r *DeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *DeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	log := r.Log.WithValues("deployment", req.NamespacedName)
+	ctx, span := telemetry.StartReconcile(ctx, reconcilerName, r.cluster, req.Namespace, req.Name)
+	if id := telemetry.TraceID(span); id != "" {
+		log = log.WithValues("trace_id", id)
+	}
 	
 	// Start timing
 	start := time.Now()
 	defer func() {
+		telemetry.End(span, err)
+		if r.stats != nil {
+			r.stats.Record(start, err)
+		}
+		r.metrics.RecordReconciliationDuration(r.cluster, reconcilerName, time.Since(start).Seconds())
+		if err != nil {
+			r.metrics.RecordReconciliationError(r.cluster, reconcilerName, errorType(err))
+		}
 		log.V(1).Info("Reconciliation completed", "duration", time.Since(start))
 	}()
 
 	// Fetch the Deployment instance
 	deployment := &appsv1.Deployment{}
-	err := r.Get(ctx, req.NamespacedName, deployment)
+	err = r.Get(ctx, req.NamespacedName, deployment)
 	if err != nil {
 		if client.IgnoreNotFound(err) == nil {
 			// Object not found, log deletion event
 			r.logDeploymentEvent(log, "delete", req.NamespacedName, nil)
-			return ctrl.Result{}, nil
+			if err = r.restoreDesiredState(ctx, log, req.NamespacedName, nil); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, r.enforcePolicies(ctx, log, req.Namespace, nil)
 		}
 		log.Error(err, "Failed to get deployment")
 		return ctrl.Result{}, err
 	}
 
+	// A deployment being deleted is only cleaned up
+	if !deployment.DeletionTimestamp.IsZero() {
+		log.V(1).Info("Deployment being deleted", "finalizers", deployment.Finalizers)
+		return ctrl.Result{}, r.runCleanupHooks(ctx, log, deployment)
+	}
+	
 	// Determine event type and log accordingly
 	eventType := r.determineEventType(deployment)
 	r.logDeploymentEvent(log, eventType, req.NamespacedName, deployment)
 
-	// Additional reconciliation logic can be added here
-	// For now, this controller focuses on logging events
-	
-	return ctrl.Result{}, nil
+	if err = r.ensureFinalizer(ctx, log, deployment); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err = r.restoreDesiredState(ctx, log, req.NamespacedName, deployment); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err = r.enforcePolicies(ctx, log, req.Namespace, deployment); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.writeStatus(ctx, log, deployment)
+}
+
+// restoreDesiredState reconciles a deployment to its desired state, if any
+func (r *DeploymentReconciler) restoreDesiredState(ctx context.Context, log logr.Logger, namespacedName types.NamespacedName, deployment *appsv1.Deployment) error {
+	if r.desired == nil {
+		return nil
+	}
+	if err := r.desired.ReconcileDeployment(ctx, namespacedName.Namespace, namespacedName.Name, deployment); err != nil {
+		log.Error(err, "Failed to restore desired state")
+		return err
+	}
+	return nil
+}
+
+// enforcePolicies enforces the deployment policies of a namespace, if enabled
+func (r *DeploymentReconciler) enforcePolicies(ctx context.Context, log logr.Logger, namespace string, deployment *appsv1.Deployment) error {
+	if r.policies == nil {
+		return nil
+	}
+	if err := r.policies.ReconcileDeployment(ctx, namespace, deployment); err != nil {
+		log.Error(err, "Failed to enforce deployment policies")
+		return err
+	}
+	return nil
 }
 
 // determineEventType determines the event type based on deployment metadata
@@ -141,6 +316,9 @@ func (r *DeploymentReconciler) logDeploymentEvent(log logr.Logger, eventType str
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
 
+	r.metrics.RecordDeploymentEvent(r.cluster, namespacedName.Namespace, eventType)
+	r.notify(eventType, namespacedName, deployment)
+
 	if deployment == nil {
 		// Deletion event
 		log.Info("Deployment deleted", convertMapToKeyValues(baseFields)...)
@@ -263,6 +441,15 @@ func AddToManager(mgr manager.Manager, cluster, namespace string, concurrency in
 		concurrency = 1
 	}
 	
-	reconciler := NewDeploymentReconciler(mgr, cluster, namespace, concurrency)
+	reconciler := NewDeploymentReconciler(mgr, cluster, config.NewNamespaceScope(namespace, nil), concurrency)
 	return reconciler.SetupWithManager(mgr)
 }
+
+// errorType classifies a reconcile error for metrics by its Kubernetes API
+// reason, such as NotFound or Timeout
+func errorType(err error) string {
+	if reason := apierrors.ReasonForError(err); reason != "" {
+		return string(reason)
+	}
+	return "Unknown"
+}
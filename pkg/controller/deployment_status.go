@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Status annotations the reconciler writes to watched deployments unless it
+// is read-only
+const (
+	// LastAnalyzedAnnotation records when the deployment was last reconciled
+	LastAnalyzedAnnotation = "k6s.io/last-analyzed"
+
+	// PolicyCompliantAnnotation is "true" when the deployment breaks none of
+	// the deployment policies selecting it, and "false" otherwise. It is
+	// removed when no policy selects the deployment.
+	PolicyCompliantAnnotation = "k6s.io/policy-compliant"
+)
+
+// SetReadOnly stops the reconciler from writing status annotations to
+// deployments, keeping it observe-only. It must be called before the
+// manager starts.
+func (r *DeploymentReconciler) SetReadOnly(readOnly bool) {
+	r.readOnly = readOnly
+}
+
+// writeStatus records the status annotations of a deployment. Only the
+// annotations are patched, so a deployment changed in the meantime is not
+// overwritten; annotation changes don't change the generation and don't
+// trigger another reconcile.
+func (r *DeploymentReconciler) writeStatus(ctx context.Context, log logr.Logger, deployment *appsv1.Deployment) error {
+	if r.readOnly {
+		return nil
+	}
+
+	annotated := deployment.DeepCopy()
+	if annotated.Annotations == nil {
+		annotated.Annotations = make(map[string]string)
+	}
+	annotated.Annotations[LastAnalyzedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+	delete(annotated.Annotations, PolicyCompliantAnnotation)
+	if r.policies != nil {
+		violations, selected, err := r.policies.Compliance(ctx, deployment)
+		if err != nil {
+			log.Error(err, "Failed to evaluate deployment policies")
+			return err
+		}
+		if selected {
+			annotated.Annotations[PolicyCompliantAnnotation] = strconv.FormatBool(len(violations) == 0)
+		}
+	}
+
+	if err := r.Patch(ctx, annotated, client.MergeFrom(deployment)); err != nil {
+		// A deployment deleted in the meantime has no status to record
+		if client.IgnoreNotFound(err) == nil {
+			return nil
+		}
+		log.Error(err, "Failed to write status annotations")
+		return err
+	}
+	return nil
+}
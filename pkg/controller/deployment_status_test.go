@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestDeploymentReconcilerStatusAnnotations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+
+	labeled := newTestPolicy("labeled", map[string]interface{}{
+		"requiredLabels": []interface{}{"team"},
+	})
+	web := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", Labels: map[string]string{"team": "shop"}}}
+	api := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api"}}
+	cart := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "store",
+		Name:        "cart",
+		Annotations: map[string]string{PolicyCompliantAnnotation: "false"},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(labeled, web, api, cart).
+		WithStatusSubresource(labeled).
+		Build()
+	reconciler := &DeploymentReconciler{Client: c, Log: logr.Discard(), Scheme: scheme}
+	reconciler.SetPolicyEnforcer(&PolicyEnforcer{Client: c, Log: logr.Discard()})
+
+	ctx := context.TODO()
+	for _, dep := range []*appsv1.Deployment{web, api, cart} {
+		key := client.ObjectKeyFromObject(dep)
+		if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile(%s) error = %v", key, err)
+		}
+	}
+
+	tests := []struct {
+		namespace, name string
+		compliant       string
+	}{
+		{"shop", "web", "true"},
+		{"shop", "api", "false"},
+		// No policy selects cart, so its stale annotation is removed
+		{"store", "cart", ""},
+	}
+	for _, tt := range tests {
+		dep := &appsv1.Deployment{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: tt.namespace, Name: tt.name}, dep); err != nil {
+			t.Fatal(err)
+		}
+		if dep.Annotations[LastAnalyzedAnnotation] == "" {
+			t.Errorf("Expected %s to be annotated with %s", tt.name, LastAnalyzedAnnotation)
+		}
+		if got := dep.Annotations[PolicyCompliantAnnotation]; got != tt.compliant {
+			t.Errorf("Expected %s %s=%q, got %q", tt.name, PolicyCompliantAnnotation, tt.compliant, got)
+		}
+	}
+}
+
+func TestDeploymentReconcilerReadOnly(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	web := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(web).Build()
+	reconciler := &DeploymentReconciler{Client: c, Log: logr.Discard(), Scheme: scheme}
+	reconciler.SetReadOnly(true)
+
+	ctx := context.TODO()
+	if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(web)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	dep := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(web), dep); err != nil {
+		t.Fatal(err)
+	}
+	if len(dep.Annotations) != 0 {
+		t.Errorf("Expected a read-only reconciler to leave web unchanged, got annotations %v", dep.Annotations)
+	}
+}
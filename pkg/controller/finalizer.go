@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/propagation"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Finalizer holds the deletion of a deployment until its cleanup hooks
+// succeed
+const Finalizer = "k6s.io/finalizer"
+
+// notifyTimeout bounds the request notifying a deleted deployment
+const notifyTimeout = 10 * time.Second
+
+// CleanupHook is a cleanup action run before a deployment carrying the
+// finalizer is deleted. A hook that fails is retried, together with the
+// hooks after it, so hooks must be idempotent.
+type CleanupHook interface {
+	// Name identifies the hook in logs and errors
+	Name() string
+
+	// Cleanup cleans up after a deployment of a cluster being deleted
+	Cleanup(ctx context.Context, cluster string, deployment *appsv1.Deployment) error
+}
+
+// cleanupFunc is a CleanupHook calling a function
+type cleanupFunc struct {
+	name string
+	fn   func(ctx context.Context, cluster string, deployment *appsv1.Deployment) error
+}
+
+func (h cleanupFunc) Name() string { return h.name }
+
+func (h cleanupFunc) Cleanup(ctx context.Context, cluster string, deployment *appsv1.Deployment) error {
+	return h.fn(ctx, cluster, deployment)
+}
+
+// NewCleanupHook returns a cleanup hook calling fn
+func NewCleanupHook(name string, fn func(ctx context.Context, cluster string, deployment *appsv1.Deployment) error) CleanupHook {
+	return cleanupFunc{name: name, fn: fn}
+}
+
+// EnableFinalizer makes the reconciler run the cleanup hooks of deployments
+// carrying the finalizer before they are deleted, and add the finalizer to
+// deployments matching selector. An empty selector adds it to none. It must
+// be called before the manager starts.
+func (r *DeploymentReconciler) EnableFinalizer(selector map[string]string) {
+	r.finalize = true
+	r.finalizerSelector = nil
+	if len(selector) > 0 {
+		r.finalizerSelector = labels.SelectorFromSet(selector)
+	}
+}
+
+// RegisterCleanupHook adds a hook run, in registration order, before
+// deployments carrying the finalizer are deleted. Hooks only run once the
+// finalizer is enabled. It must be called before the manager starts.
+func (r *DeploymentReconciler) RegisterCleanupHook(hook CleanupHook) {
+	r.hooks = append(r.hooks, hook)
+}
+
+// ensureFinalizer adds the finalizer to a deployment matching the selector
+func (r *DeploymentReconciler) ensureFinalizer(ctx context.Context, log logr.Logger, deployment *appsv1.Deployment) error {
+	if !r.finalize || r.finalizerSelector == nil || !r.finalizerSelector.Matches(labels.Set(deployment.Labels)) {
+		return nil
+	}
+	if !controllerutil.AddFinalizer(deployment, Finalizer) {
+		return nil
+	}
+	if err := r.Update(ctx, deployment); err != nil {
+		log.Error(err, "Failed to add finalizer")
+		return err
+	}
+	log.V(1).Info("Added finalizer", "finalizer", Finalizer)
+	return nil
+}
+
+// runCleanupHooks runs the cleanup hooks of a deployment being deleted and then
+// removes the finalizer, letting the deletion complete. The finalizer stays
+// when a hook fails, and the deployment is reconciled again. With the
+// finalizer disabled, it is removed without running the hooks, so deployments
+// given it earlier aren't left in deletion.
+func (r *DeploymentReconciler) runCleanupHooks(ctx context.Context, log logr.Logger, deployment *appsv1.Deployment) error {
+	if r.readOnly || !controllerutil.ContainsFinalizer(deployment, Finalizer) {
+		return nil
+	}
+
+	hooks := r.hooks
+	if !r.finalize {
+		hooks = nil
+	}
+	for _, hook := range hooks {
+		if err := hook.Cleanup(ctx, r.cluster, deployment); err != nil {
+			log.Error(err, "Cleanup hook failed", "hook", hook.Name())
+			return fmt.Errorf("cleanup hook %s failed: %w", hook.Name(), err)
+		}
+		log.V(1).Info("Cleanup hook completed", "hook", hook.Name())
+	}
+
+	controllerutil.RemoveFinalizer(deployment, Finalizer)
+	if err := r.Update(ctx, deployment); err != nil {
+		log.Error(err, "Failed to remove finalizer")
+		return err
+	}
+	log.Info("Deployment finalized", "hooks", len(hooks))
+	return nil
+}
+
+// NewNotifyHook returns a cleanup hook posting each deleted deployment to
+// url as JSON. Any status other than 2xx fails the hook.
+func NewNotifyHook(url string) CleanupHook {
+	httpClient := &http.Client{Timeout: notifyTimeout}
+	return NewCleanupHook("notify", func(ctx context.Context, cluster string, deployment *appsv1.Deployment) error {
+		body, err := json.Marshal(map[string]interface{}{
+			"event":     "delete",
+			"cluster":   cluster,
+			"namespace": deployment.Namespace,
+			"name":      deployment.Name,
+			"uid":       deployment.UID,
+			"labels":    deployment.Labels,
+		})
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("notification returned status %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// NewPropagatedCopiesHook returns a cleanup hook deleting the copies of a
+// propagated deployment of the primary cluster from the other clusters of
+// registry. Deployments of other clusters, or without the propagate label,
+// are skipped.
+func NewPropagatedCopiesHook(registry cluster.ClusterRegistry) CleanupHook {
+	return NewCleanupHook("remove-propagated-copies", func(ctx context.Context, clusterName string, deployment *appsv1.Deployment) error {
+		if deployment.Labels[propagation.PropagateLabel] != "true" {
+			return nil
+		}
+		client, ok := registry.GetCluster(clusterName)
+		if primary, isPrimary := client.(interface{ IsPrimary() bool }); !ok || !isPrimary || !primary.IsPrimary() {
+			return nil
+		}
+		return propagation.RemoveCopies(ctx, registry, clusterName, deployment.Namespace, deployment.Name)
+	})
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestDeploymentReconcilerFinalizer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	web := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", Labels: map[string]string{"team": "shop"}}}
+	api := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(web, api).Build()
+
+	reconciler := &DeploymentReconciler{Client: c, Log: logr.Discard(), Scheme: scheme, cluster: "prod"}
+	reconciler.EnableFinalizer(map[string]string{"team": "shop"})
+	var cleaned []string
+	hookErr := errors.New("external system unavailable")
+	reconciler.RegisterCleanupHook(NewCleanupHook("record", func(ctx context.Context, cluster string, deployment *appsv1.Deployment) error {
+		cleaned = append(cleaned, cluster+"/"+deployment.Namespace+"/"+deployment.Name)
+		return hookErr
+	}))
+
+	ctx := context.TODO()
+	reconcileDeployment := func(name string) error {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "shop", Name: name}})
+		return err
+	}
+	for _, name := range []string{"web", "api"} {
+		if err := reconcileDeployment(name); err != nil {
+			t.Fatalf("Reconcile(%s) error = %v", name, err)
+		}
+	}
+
+	// Only deployments matching the selector are given the finalizer
+	for name, want := range map[string]bool{"web": true, "api": false} {
+		dep := &appsv1.Deployment{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: "shop", Name: name}, dep); err != nil {
+			t.Fatal(err)
+		}
+		if got := controllerutil.ContainsFinalizer(dep, Finalizer); got != want {
+			t.Errorf("Expected %s to have the finalizer: %v, got %v", name, want, got)
+		}
+	}
+
+	// The deletion waits until the hooks succeed
+	if err := c.Delete(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileDeployment("web"); !errors.Is(err, hookErr) {
+		t.Fatalf("Expected the hook error, got %v", err)
+	}
+	deleting := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "shop", Name: "web"}, deleting); err != nil {
+		t.Fatalf("Expected web to wait for its cleanup, got %v", err)
+	}
+
+	hookErr = nil
+	if err := reconcileDeployment("web"); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "shop", Name: "web"}, deleting); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected web to be deleted once cleaned up, got %v", err)
+	}
+	if len(cleaned) != 2 || cleaned[1] != "prod/shop/web" {
+		t.Errorf("Expected the hook to run twice for prod/shop/web, got %v", cleaned)
+	}
+}
+
+func TestDeploymentReconcilerFinalizerDisabled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+	web := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", Finalizers: []string{Finalizer, "example.com/keep"}}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(web).Build()
+
+	// The finalizer was added while enabled and has since been turned off
+	reconciler := &DeploymentReconciler{Client: c, Log: logr.Discard(), Scheme: scheme, cluster: "prod"}
+	hookRan := false
+	reconciler.RegisterCleanupHook(NewCleanupHook("record", func(ctx context.Context, cluster string, deployment *appsv1.Deployment) error {
+		hookRan = true
+		return nil
+	}))
+
+	ctx := context.TODO()
+	if err := c.Delete(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "shop", Name: "web"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	deleting := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "shop", Name: "web"}, deleting); err != nil {
+		t.Fatalf("Expected web to wait for its other finalizer, got %v", err)
+	}
+	if controllerutil.ContainsFinalizer(deleting, Finalizer) {
+		t.Error("Expected the finalizer to be removed while disabled")
+	}
+	if !controllerutil.ContainsFinalizer(deleting, "example.com/keep") {
+		t.Error("Expected other finalizers to be kept")
+	}
+	if hookRan {
+		t.Error("Expected the hooks not to run while the finalizer is disabled")
+	}
+}
+
+func TestNotifyHook(t *testing.T) {
+	var notified map[string]interface{}
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected a POST, got %s", r.Method)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&notified)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	hook := NewNotifyHook(srv.URL)
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"}}
+	if err := hook.Cleanup(context.TODO(), "prod", dep); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if notified["cluster"] != "prod" || notified["namespace"] != "shop" || notified["name"] != "web" {
+		t.Errorf("Unexpected notification %v", notified)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := hook.Cleanup(context.TODO(), "prod", dep); err == nil {
+		t.Error("Expected a failed notification to fail the hook")
+	}
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+)
+
+// LeaderStatus reports the holder of the leader election lease
+type LeaderStatus struct {
+	Lease       string `json:"lease"`
+	Identity    string `json:"identity,omitempty"`
+	IsLeader    bool   `json:"isLeader"`
+	AcquireTime string `json:"acquireTime,omitempty"`
+	RenewTime   string `json:"renewTime,omitempty"`
+	Transitions int32  `json:"transitions"`
+
+	// Why the lease couldn't be read, if it couldn't
+	Error string `json:"error,omitempty"`
+}
+
+// LeaderTracker follows the leader election lease, serving its holder at
+// /leader and in the leader election metrics
+type LeaderTracker struct {
+	leases    coordinationv1.LeasesGetter
+	namespace string
+	name      string
+	interval  time.Duration
+	elected   <-chan struct{}
+	log       *logger.Logger
+
+	mu       sync.RWMutex
+	status   LeaderStatus
+	identity string
+}
+
+// NewLeaderTracker creates a tracker reading the lease namespace/name every
+// interval. This replica is the leader once elected is closed.
+func NewLeaderTracker(leases coordinationv1.LeasesGetter, namespace, name string, interval time.Duration, elected <-chan struct{}) *LeaderTracker {
+	return &LeaderTracker{
+		leases:    leases,
+		namespace: namespace,
+		name:      name,
+		interval:  interval,
+		elected:   elected,
+		log:       logger.WithComponent("leader-election"),
+		status:    LeaderStatus{Lease: namespace + "/" + name},
+	}
+}
+
+// Start reads the lease until ctx is done
+func (t *LeaderTracker) Start(ctx context.Context) error {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		t.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection is false: every replica reports the leader
+func (t *LeaderTracker) NeedLeaderElection() bool {
+	return false
+}
+
+// Status returns the last read holder of the lease
+func (t *LeaderTracker) Status() LeaderStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.status
+}
+
+// ServeHTTP serves the status of the lease as JSON
+func (t *LeaderTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(t.Status())
+}
+
+// refresh reads the lease and updates the status and metrics
+func (t *LeaderTracker) refresh(ctx context.Context) {
+	status := LeaderStatus{Lease: t.namespace + "/" + t.name, IsLeader: t.isLeader()}
+
+	ctx, cancel := context.WithTimeout(ctx, t.interval)
+	defer cancel()
+	lease, err := t.leases.Leases(t.namespace).Get(ctx, t.name, metav1.GetOptions{})
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		if lease.Spec.HolderIdentity != nil {
+			status.Identity = *lease.Spec.HolderIdentity
+		}
+		if lease.Spec.AcquireTime != nil {
+			status.AcquireTime = lease.Spec.AcquireTime.UTC().Format(time.RFC3339)
+		}
+		if lease.Spec.RenewTime != nil {
+			status.RenewTime = lease.Spec.RenewTime.UTC().Format(time.RFC3339)
+		}
+		if lease.Spec.LeaseTransitions != nil {
+			status.Transitions = *lease.Spec.LeaseTransitions
+		}
+	}
+
+	t.mu.Lock()
+	previous := t.identity
+	t.status = status
+	if err == nil {
+		t.identity = status.Identity
+	}
+	t.mu.Unlock()
+
+	if status.IsLeader {
+		metrics.IsLeader.Set(1)
+	} else {
+		metrics.IsLeader.Set(0)
+	}
+	if err != nil || status.Identity == previous {
+		return
+	}
+	metrics.LeaderInfo.DeletePartialMatch(map[string]string{"lease": status.Lease})
+	if status.Identity != "" {
+		metrics.LeaderInfo.WithLabelValues(status.Lease, status.Identity).Set(1)
+	}
+	t.log.Info("Leader changed", map[string]interface{}{
+		"lease":    status.Lease,
+		"identity": status.Identity,
+		"previous": previous,
+	})
+}
+
+// isLeader reports whether this replica has been elected
+func (t *LeaderTracker) isLeader() bool {
+	if t.elected == nil {
+		return false
+	}
+	select {
+	case <-t.elected:
+		return true
+	default:
+		return false
+	}
+}
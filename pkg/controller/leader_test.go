@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLeaderTracker(t *testing.T) {
+	holder := "k6s-7d9f_1"
+	transitions := int32(2)
+	renewed := metav1.NewMicroTime(time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC))
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "k6s", Name: "k6s-controller"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:   &holder,
+			RenewTime:        &renewed,
+			LeaseTransitions: &transitions,
+		},
+	}
+	clientset := fake.NewSimpleClientset(lease)
+	elected := make(chan struct{})
+	tracker := NewLeaderTracker(clientset.CoordinationV1(), "k6s", "k6s-controller", time.Second, elected)
+
+	tracker.refresh(context.Background())
+	status := tracker.Status()
+	if status.Identity != holder || status.IsLeader || status.Transitions != 2 || status.RenewTime != "2026-03-04T05:06:07Z" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	if got := testutil.ToFloat64(metrics.LeaderInfo.WithLabelValues("k6s/k6s-controller", holder)); got != 1 {
+		t.Errorf("Expected the leader info of %s, got %v", holder, got)
+	}
+
+	// Another replica takes over, then this one is elected
+	other := "k6s-7d9f_2"
+	lease.Spec.HolderIdentity = &other
+	if _, err := clientset.CoordinationV1().Leases("k6s").Update(context.Background(), lease, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	close(elected)
+	tracker.refresh(context.Background())
+	if got := testutil.CollectAndCount(metrics.LeaderInfo); got != 1 {
+		t.Errorf("Expected only the current leader in the leader info, got %d series", got)
+	}
+	if got := testutil.ToFloat64(metrics.IsLeader); got != 1 {
+		t.Errorf("Expected this replica to be the leader, got %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	tracker.ServeHTTP(rec, httptest.NewRequest("GET", "/leader", nil))
+	var served LeaderStatus
+	if err := json.NewDecoder(rec.Body).Decode(&served); err != nil {
+		t.Fatalf("Failed to decode /leader: %v", err)
+	}
+	if served.Lease != "k6s/k6s-controller" || served.Identity != other || !served.IsLeader {
+		t.Errorf("Unexpected /leader response %+v", served)
+	}
+
+	// A lease that can't be read is reported as an error
+	missing := NewLeaderTracker(clientset.CoordinationV1(), "k6s", "missing", time.Second, nil)
+	missing.refresh(context.Background())
+	if status := missing.Status(); status.Error == "" || status.Identity != "" {
+		t.Errorf("Expected an error for a missing lease, got %+v", status)
+	}
+}
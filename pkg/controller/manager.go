@@ -2,20 +2,35 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/defaulting"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/gitops"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/notify"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	k8s "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -25,9 +40,48 @@ type Manager struct {
 	mgr         manager.Manager
 	registry    cluster.ClusterRegistry
 	multiMgr    *MultiClusterManager
+	
+	// Clients of the registered clusters
+	pool *cluster.ClientPool
 	log         logr.Logger
 	config      *config.Config
 	mode        string // "single" or "multi"
+	version     string
+	
+	// Single cluster reconciler, used for status reporting
+	reconciler *DeploymentReconciler
+	
+	// Status reporter, started by Start in multi-cluster mode
+	statusReporter *StatusReporter
+	
+	// Rebuilds clusters whose kubeconfig Secret changed, in multi-cluster mode
+	secretWatcher *cluster.SecretWatcher
+	
+	// When Start was called, reported as the start time in single-cluster mode
+	startTime time.Time
+	
+	// Promotes another cluster when the primary is unreachable, in
+	// multi-cluster mode once a cluster store is set
+	failover *cluster.FailoverMonitor
+	
+	// Applies the deployments of a Git repository, nil unless GitOps
+	// source mode is enabled
+	gitops *gitops.Syncer
+	
+	// Mutating webhook injecting deployment defaults, nil unless enabled
+	defaulter *defaulting.Defaulter
+	
+	// Serves the mutating webhook in multi-cluster mode, where there is no
+	// controller-runtime manager to serve it
+	webhookServer webhook.Server
+	
+	// Delivers deployment events matching notification rules, nil unless
+	// notifications are enabled
+	notifier *notify.Notifier
+	
+	// Follows the leader election lease, nil unless leader election is
+	// enabled in single-cluster mode
+	leader *LeaderTracker
 }
 
 // NewManager creates a new controller manager
@@ -36,6 +90,7 @@ func NewManager(cfg *config.Config, mode string) (*Manager, error) {
 	
 	// Create cluster registry
 	clusterRegistry := cluster.NewInMemoryClusterRegistry()
+	clusterRegistry.Pool().SetMaxClients(cfg.MultiCluster.MaxConcurrentConns)
 	
 	// Add default cluster if none configured
 	if len(cfg.MultiCluster.Clusters) == 0 {
@@ -46,14 +101,7 @@ func NewManager(cfg *config.Config, mode string) (*Manager, error) {
 	} else {
 		// Add configured clusters
 		for _, clusterConfig := range cfg.MultiCluster.Clusters {
-			clusterClient := &cluster.ClusterConfig{
-				Name:       clusterConfig.Name,
-				KubeConfig: clusterConfig.KubeConfig,
-				Context:    clusterConfig.Context,
-				Namespace:  clusterConfig.Namespace,
-				Enabled:    clusterConfig.Enabled,
-				Primary:    clusterConfig.Primary,
-			}
+			clusterClient := cluster.NewClusterConfigFrom(clusterConfig)
 			if err := clusterRegistry.AddCluster(clusterConfig.Name, clusterClient); err != nil {
 				return nil, fmt.Errorf("failed to add cluster %s: %w", clusterConfig.Name, err)
 			}
@@ -74,121 +122,599 @@ func NewManager(cfg *config.Config, mode string) (*Manager, error) {
 	
 	var mgr manager.Manager
 	var multiMgr *MultiClusterManager
+	var reconciler *DeploymentReconciler
+	var leader *LeaderTracker
 	
 	if mode == "multi" {
 		// Multi-cluster mode - create multi-cluster manager
-		multiMgr = NewMultiClusterManager(clusterRegistry, cfg.Controller.Single.Namespace, 1)
+		multiMgr = NewMultiClusterManager(clusterRegistry, cfg.Controller.Single.NamespaceScope(), 1)
+		multiMgr.SetClientConfig(cfg.Controller.Client)
+		multiMgr.SetReadyQuorum(cfg.MultiCluster.ReadyQuorum)
+		multiMgr.SetLeaderElection(cfg.MultiCluster.LeaderElection)
+		if cfg.Controller.Policies.Enabled {
+			multiMgr.EnablePolicies()
+		}
+		multiMgr.SetReadOnly(cfg.Controller.ReadOnly)
+		if cfg.MultiCluster.Sharding.Enabled {
+			sharder, err := newSharder(cfg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set up sharding: %w", err)
+			}
+			multiMgr.SetSharder(sharder)
+		}
 		log.Info("Multi-cluster manager created", nil)
 	} else {
 		// Single cluster mode - create standard manager
 		var err error
-		mgr, err = createSingleClusterManager(cfg, log)
+		mgr, reconciler, leader, err = createSingleClusterManager(cfg, log)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create single cluster manager: %w", err)
 		}
 		log.Info("Single cluster manager created", nil)
 	}
 	
-	return &Manager{
-		mgr:      mgr,
-		registry: clusterRegistry,
-		multiMgr: multiMgr,
-		log:      log.GetLogr(),
-		config:   cfg,
-		mode:     mode,
-	}, nil
+	m := &Manager{
+		mgr:        mgr,
+		registry:   clusterRegistry,
+		pool:       clusterRegistry.Pool(),
+		multiMgr:   multiMgr,
+		log:        log.GetLogr(),
+		config:     cfg,
+		mode:       mode,
+		version:    "dev",
+		reconciler: reconciler,
+		leader:     leader,
+	}
+	
+	if err := m.setupStatusReporter(log); err != nil {
+		return nil, fmt.Errorf("failed to setup status reporting: %w", err)
+	}
+	
+	if err := m.setupGitOps(log); err != nil {
+		return nil, fmt.Errorf("failed to setup GitOps source mode: %w", err)
+	}
+	
+	m.setupWebhook(log)
+	m.setupFinalizer(log)
+	m.setupNotifications(log)
+	
+	if mode == "multi" {
+		m.secretWatcher = cluster.NewSecretWatcher(cfg.MultiCluster.SecretRefreshInterval, m.refreshCluster)
+		m.secretWatcher.SetClusters(cfg.MultiCluster.Clusters)
+	}
+	
+	return m, nil
 }
 
-// createSingleClusterManager creates a manager for single cluster mode
-func createSingleClusterManager(cfg *config.Config, log *logger.Logger) (manager.Manager, error) {
-	log.Info("Creating single cluster manager", nil)
+// SetClusterStore enables primary cluster failover in multi-cluster mode,
+// persisting promotions to store. Call it before Start.
+func (m *Manager) SetClusterStore(store *cluster.ConfigStore) {
+	if m.mode != "multi" {
+		return
+	}
+	m.failover = cluster.NewFailoverMonitor(store, m.config.MultiCluster.Failover)
+	m.failover.SetClusters(m.config.MultiCluster.Clusters)
+	if m.notifier != nil {
+		m.failover.OnFailover(m.notifyFailover)
+	}
+}
+
+// notifyFailover delivers a failover notification for the cluster promoted
+// to primary
+func (m *Manager) notifyFailover(from, to string) {
+	m.notifier.Notify(notify.Event{
+		Type:    notify.EventFailover,
+		Source:  notify.SourceFailover,
+		Cluster: to,
+		Message: fmt.Sprintf("promoted to primary, replacing unreachable cluster %s", from),
+	})
+}
+
+// setupStatusReporter registers the K6sController status reporter when running in-cluster
+func (m *Manager) setupStatusReporter(log *logger.Logger) error {
+	if !m.config.Controller.Status.Enabled {
+		return nil
+	}
 	
-	// Get REST config - for now use default
 	restConfig, err := rest.InClusterConfig()
 	if err != nil {
-		log.Info("In-cluster config failed, trying out-of-cluster config", map[string]interface{}{"error": err.Error()})
-		// Try out-of-cluster config
-		restConfig, err = ctrl.GetConfig()
+		log.Debug("Not running in-cluster, skipping status reporting", nil)
+		return nil
+	}
+	
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	
+	reporter := NewStatusReporter(client, m.config.Controller.Status.Name, m.config.Controller.Status.Interval, m.Status)
+	
+	if m.mode == "multi" {
+		m.statusReporter = reporter
+		return nil
+	}
+	
+	// In single cluster mode the manager runs the reporter on the elected leader only
+	return m.mgr.Add(reporter)
+}
+
+// setupGitOps creates the syncer applying the deployments of the configured
+// repository when GitOps source mode is enabled, and makes the reconciler of
+// the primary cluster restore them
+func (m *Manager) setupGitOps(log *logger.Logger) error {
+	cfg := m.config.Controller.GitOps
+	if !cfg.Enabled {
+		return nil
+	}
+	
+	if m.mode != "multi" {
+		client, err := kubernetes.NewClientForConfig(m.mgr.GetConfig())
 		if err != nil {
-			return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
+			return err
 		}
+		m.gitops = gitops.NewSyncer(client, cfg)
+		m.reconciler.SetDesiredState(m.gitops)
+		// The manager runs the syncer on the elected leader only
+		return m.mgr.Add(m.gitops)
+	}
+	
+	// In multi-cluster mode the repository is applied to the primary cluster
+	for name, clusterClient := range m.registry.GetEnabledClusters() {
+		if primary, ok := clusterClient.(interface{ IsPrimary() bool }); !ok || !primary.IsPrimary() {
+			continue
+		}
+		restConfig, err := clusterClient.GetRestConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get REST config for cluster %s: %w", name, err)
+		}
+		client, err := kubernetes.NewClientForConfig(restConfig)
+		if err != nil {
+			return err
+		}
+		m.gitops = gitops.NewSyncer(client, cfg)
+		m.multiMgr.SetDesiredState(name, m.gitops)
+		log.Info("GitOps source mode applies to the primary cluster", map[string]interface{}{
+			"cluster": name,
+		})
+		return nil
+	}
+	return fmt.Errorf("no enabled primary cluster to apply the repository to")
+}
+
+// setupWebhook registers the mutating webhook injecting deployment defaults
+// when it is enabled. It is served by every replica, not only the leader.
+func (m *Manager) setupWebhook(log *logger.Logger) {
+	cfg := m.config.Controller.Webhook
+	if !cfg.Enabled {
+		return
+	}
+	
+	m.defaulter = defaulting.New(cfg)
+	var server webhook.Server
+	if m.mode == "multi" {
+		m.webhookServer = webhook.NewServer(webhook.Options{Port: cfg.Port, CertDir: cfg.CertDir})
+		server = m.webhookServer
+	} else {
+		// The manager starts its webhook server once a handler is registered
+		server = m.mgr.GetWebhookServer()
+	}
+	server.Register(defaulting.Path, &webhook.Admission{Handler: m.defaulter})
+	log.Info("Mutating webhook registered", map[string]interface{}{
+		"path":       defaulting.Path,
+		"port":       cfg.Port,
+		"namespaces": cfg.Namespaces,
+	})
+}
+
+// setupFinalizer enables the finalizer running cleanup hooks before
+// deployments are deleted, with the built-in hooks the configuration enables
+func (m *Manager) setupFinalizer(log *logger.Logger) {
+	cfg := m.config.Controller.Finalizer
+	if !cfg.Enabled {
+		return
+	}
+	
+	if m.mode == "multi" {
+		m.multiMgr.EnableFinalizer(cfg.Selector)
+	} else {
+		m.reconciler.EnableFinalizer(cfg.Selector)
+	}
+	if cfg.NotifyURL != "" {
+		m.RegisterCleanupHook(NewNotifyHook(cfg.NotifyURL))
+	}
+	// Only the multi-cluster manager watches the primary and member clusters
+	if cfg.RemovePropagatedCopies && m.mode == "multi" {
+		m.RegisterCleanupHook(NewPropagatedCopiesHook(m.registry))
+	}
+	log.Info("Deployment finalizer enabled", map[string]interface{}{
+		"finalizer": Finalizer,
+		"selector":  cfg.Selector,
+	})
+}
+
+// RegisterCleanupHook adds a hook run before deployments carrying the
+// finalizer are deleted, in every watched cluster. Hooks only run when the
+// finalizer is enabled. Call it before Start.
+func (m *Manager) RegisterCleanupHook(hook CleanupHook) {
+	if m.mode == "multi" {
+		m.multiMgr.RegisterCleanupHook(hook)
+		return
+	}
+	m.reconciler.RegisterCleanupHook(hook)
+}
+
+// setupNotifications makes the reconcilers notify deployment events when
+// notifications are enabled
+func (m *Manager) setupNotifications(log *logger.Logger) {
+	cfg := m.config.Notifications
+	if !cfg.Enabled {
+		return
+	}
+	
+	m.notifier = notify.New(cfg)
+	if m.mode == "multi" {
+		m.multiMgr.SetNotifier(m.notifier)
+	} else {
+		m.reconciler.SetNotifier(m.notifier)
+	}
+	log.Info("Deployment notifications enabled", map[string]interface{}{
+		"sinks": len(cfg.Sinks),
+		"rules": len(cfg.Rules),
+	})
+}
+
+// ReloadNotifications applies changed notification sinks and rules to the
+// running notifier. Enabling or disabling notifications requires a restart.
+func (m *Manager) ReloadNotifications(oldConfig, newConfig *config.Config) {
+	if m.notifier == nil || reflect.DeepEqual(oldConfig.Notifications, newConfig.Notifications) {
+		return
+	}
+	if !newConfig.Notifications.Enabled {
+		m.log.Info("Disabling notifications requires a restart")
+		return
+	}
+	m.notifier.SetConfig(newConfig.Notifications)
+	m.log.Info("Notification configuration reloaded",
+		"sinks", len(newConfig.Notifications.Sinks),
+		"rules", len(newConfig.Notifications.Rules))
+}
+
+// ReloadWebhook applies changed webhook namespaces and defaults to the
+// running webhook. Enabling or disabling it, and its port and certificate
+// directory, require a restart.
+func (m *Manager) ReloadWebhook(oldConfig, newConfig *config.Config) {
+	if m.defaulter == nil || reflect.DeepEqual(oldConfig.Controller.Webhook, newConfig.Controller.Webhook) {
+		return
+	}
+	m.defaulter.SetConfig(newConfig.Controller.Webhook)
+	m.log.Info("Webhook defaults reloaded", "namespaces", newConfig.Controller.Webhook.Namespaces)
+}
+
+// ReloadGitOps applies a changed GitOps configuration to the running syncer.
+// Enabling or disabling GitOps source mode requires a restart.
+func (m *Manager) ReloadGitOps(oldConfig, newConfig *config.Config) {
+	if m.gitops == nil || reflect.DeepEqual(oldConfig.Controller.GitOps, newConfig.Controller.GitOps) {
+		return
+	}
+	if !newConfig.Controller.GitOps.Enabled {
+		m.log.Info("Disabling GitOps source mode requires a restart")
+		return
+	}
+	m.gitops.SetConfig(newConfig.Controller.GitOps)
+	m.log.Info("GitOps configuration reloaded",
+		"repository", newConfig.Controller.GitOps.Repository,
+		"suspend", newConfig.Controller.GitOps.Suspend)
+}
+
+// SetVersion sets the version reported in the controller status
+func (m *Manager) SetVersion(version string) {
+	m.version = version
+}
+
+// Status returns the current controller status
+func (m *Manager) Status() K6sControllerStatus {
+	clusters, healthy := clusterHealth(m.GetClusterStatus())
+	
+	var summary ReconcileSummary
+	if m.mode == "multi" && m.multiMgr != nil {
+		summary = m.multiMgr.ReconcileSummary()
+	} else if m.reconciler != nil {
+		summary = m.reconciler.Stats().Summary()
+	}
+	
+	status := K6sControllerStatus{
+		Version:       m.version,
+		Mode:          m.mode,
+		Features:      enabledFeatures(m.config, m.mode),
+		Healthy:       healthy,
+		Clusters:      clusters,
+		LastReconcile: summary,
+	}
+	if m.gitops != nil {
+		gitopsStatus := m.gitops.Status()
+		status.GitOps = &gitopsStatus
+	}
+	if m.leader != nil {
+		leaderStatus := m.leader.Status()
+		status.Leader = &leaderStatus
+	}
+	return status
+}
+
+// newSharder creates the sharder of this replica, identified by its hostname
+// (the pod name in-cluster), with the Leases in the cluster k6s runs in
+func newSharder(cfg *config.Config) (*cluster.Sharder, error) {
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shard identity: %w", err)
+	}
+	restConfig, err := kubernetes.RestConfig("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
+	}
+	kubernetes.ApplyClientConfig(restConfig, cfg.Controller.Client)
+	clientset, err := k8s.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sharding client: %w", err)
+	}
+	return cluster.NewSharder(clientset.CoordinationV1(), cfg.MultiCluster.Sharding, identity), nil
+}
+
+// enabledFeatures lists the optional features enabled by the configuration
+func enabledFeatures(cfg *config.Config, mode string) []string {
+	features := []string{}
+	if mode == "multi" {
+		features = append(features, "multi-cluster")
+	}
+	if mode != "multi" && cfg.Controller.Single.LeaderElection.Enabled {
+		features = append(features, "leader-election")
+	}
+	if mode == "multi" && cfg.MultiCluster.LeaderElection.Enabled {
+		features = append(features, "cluster-leader-election")
+	}
+	if mode == "multi" && cfg.MultiCluster.Sharding.Enabled {
+		features = append(features, "sharding")
+	}
+	if cfg.LargeClusterMode {
+		features = append(features, "large-cluster-mode")
+	}
+	if transform := cfg.Controller.Informer.Transform; transform != "" && transform != "none" {
+		features = append(features, "informer-transform-"+transform)
 	}
+	if cfg.Server.AccessLog.Enabled {
+		features = append(features, "access-log")
+	}
+	if cfg.Controller.GitOps.Enabled {
+		features = append(features, "gitops")
+	}
+	if cfg.Controller.Policies.Enabled {
+		features = append(features, "deployment-policies")
+	}
+	if cfg.Controller.Webhook.Enabled {
+		features = append(features, "mutating-webhook")
+	}
+	if cfg.Controller.Finalizer.Enabled {
+		features = append(features, "finalizer")
+	}
+	if cfg.Controller.ReadOnly {
+		features = append(features, "read-only")
+	}
+	if cfg.Notifications.Enabled {
+		features = append(features, "notifications")
+	}
+	return features
+}
+
+// createSingleClusterManager creates a manager for single cluster mode, and
+// the tracker of its leader election lease when leader election is enabled
+func createSingleClusterManager(cfg *config.Config, log *logger.Logger) (manager.Manager, *DeploymentReconciler, *LeaderTracker, error) {
+	log.Info("Creating single cluster manager", nil)
+	
+	// Resolve the REST config the same way as the server and deployment commands
+	restConfig, err := kubernetes.RestConfig("")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get kubernetes config: %w", err)
+	}
+	kubernetes.ApplyClientConfig(restConfig, cfg.Controller.Client)
 	
 	log.Info("Kubernetes config obtained", map[string]interface{}{"host": restConfig.Host})
 	
 	// Create manager options
+	election := cfg.Controller.Single.LeaderElection
+	leaseDuration, renewDeadline, retryPeriod := election.Timings()
 	opts := ctrl.Options{
 		Scheme: runtime.NewScheme(),
 		Metrics: server.Options{
 			BindAddress: fmt.Sprintf(":%d", cfg.Controller.Single.MetricsPort),
 		},
 		WebhookServer: webhook.NewServer(webhook.Options{
-			Port: 9443, // Default webhook port
+			Port:    cfg.Controller.Webhook.Port,
+			CertDir: cfg.Controller.Webhook.CertDir,
 		}),
 		HealthProbeBindAddress: fmt.Sprintf(":%d", cfg.Controller.Single.HealthPort),
-		LeaderElection:         cfg.Controller.Single.LeaderElection.Enabled,
-		LeaderElectionID:       cfg.Controller.Single.LeaderElection.ID,
-		LeaderElectionNamespace: cfg.Controller.Single.LeaderElection.Namespace,
+		LeaderElection:         election.Enabled,
+		LeaderElectionID:       election.ID,
+		LeaderElectionNamespace: election.Namespace,
+		LeaderElectionResourceLock: election.ResourceLock,
+		LeaderElectionReleaseOnCancel: election.ReleaseOnCancel,
+		LeaseDuration:          &leaseDuration,
+		RenewDeadline:          &renewDeadline,
+		RetryPeriod:            &retryPeriod,
 		Logger:                 log.GetLogr(),
 	}
 	
+	// The leader is served at /leader on the metrics port
+	var leader *LeaderTracker
+	if election.Enabled {
+		clientset, err := k8s.NewForConfig(restConfig)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to create leader election client: %w", err)
+		}
+		namespace := election.Namespace
+		if namespace == "" {
+			namespace = kubernetes.InClusterNamespace()
+		}
+		leader = NewLeaderTracker(clientset.CoordinationV1(), namespace, election.ID, retryPeriod, nil)
+		opts.Metrics.ExtraHandlers = map[string]http.Handler{"/leader": leader}
+	}
+	
 	log.Info("Manager options configured", map[string]interface{}{
 		"metrics_port": cfg.Controller.Single.MetricsPort,
 		"health_port": cfg.Controller.Single.HealthPort,
 		"leader_election": cfg.Controller.Single.LeaderElection.Enabled,
 		"leader_election_namespace": cfg.Controller.Single.LeaderElection.Namespace,
-		"namespace": cfg.Controller.Single.Namespace,
+		"lease_duration": leaseDuration.String(),
+		"renew_deadline": renewDeadline.String(),
+		"retry_period": retryPeriod.String(),
+		"namespaces": cfg.Controller.Single.NamespaceScope().String(),
 	})
 	
+	// Policies are read from the cache like deployments
+	if cfg.Controller.Policies.Enabled {
+		opts.Client.Cache = &client.CacheOptions{Unstructured: true}
+	}
+	
 	// Add namespace filter if specified
-	if cfg.Controller.Single.Namespace != "" {
-		opts.Cache.DefaultNamespaces = map[string]cache.Config{
-			cfg.Controller.Single.Namespace: {},
-		}
-		log.Info("Added namespace filter", map[string]interface{}{"namespace": cfg.Controller.Single.Namespace})
+	namespaces := cfg.Controller.Single.NamespaceScope()
+	opts.Cache.DefaultNamespaces = cacheNamespaces(namespaces)
+	if opts.Cache.DefaultNamespaces != nil {
+		log.Info("Added namespace filter", map[string]interface{}{"namespaces": namespaces.String()})
 	}
 	
 	// Add schemes
 	log.Info("Adding schemes to manager", nil)
 	if err := appsv1.AddToScheme(opts.Scheme); err != nil {
-		return nil, fmt.Errorf("failed to add apps/v1 scheme: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to add apps/v1 scheme: %w", err)
 	}
 	
 	// Create manager
 	log.Info("Creating controller-runtime manager", nil)
 	mgr, err := ctrl.NewManager(restConfig, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create manager: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to create manager: %w", err)
 	}
 	log.Info("Controller-runtime manager created successfully", nil)
 	
+	if leader != nil {
+		leader.elected = mgr.Elected()
+		if err := mgr.Add(leader); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to add leader tracker: %w", err)
+		}
+	}
+	
 	// Add deployment reconciler
 	log.Info("Adding deployment reconciler to manager", nil)
-	if err := AddToManager(mgr, "default", cfg.Controller.Single.Namespace, 1); err != nil {
-		return nil, fmt.Errorf("failed to add deployment controller: %w", err)
+	reconciler := NewDeploymentReconciler(mgr, "default", namespaces, 1)
+	reconciler.SetReadOnly(cfg.Controller.ReadOnly)
+	if err := reconciler.SetupWithManager(mgr); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to add deployment controller: %w", err)
 	}
 	log.Info("Deployment reconciler added successfully", nil)
 	
+	if cfg.Controller.Policies.Enabled {
+		enforcer := NewPolicyEnforcer(mgr, "default")
+		enforcer.SetReadOnly(cfg.Controller.ReadOnly)
+		if err := enforcer.SetupWithManager(mgr); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to setup deployment policy controller: %w", err)
+		}
+		reconciler.SetPolicyEnforcer(enforcer)
+		log.Info("Deployment policy enforcement enabled", nil)
+	}
+	
 	// Add health checks
 	log.Info("Adding health checks", nil)
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		return nil, fmt.Errorf("failed to add health check: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to add health check: %w", err)
 	}
 	
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		return nil, fmt.Errorf("failed to add ready check: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to add ready check: %w", err)
+	}
+	if err := mgr.AddReadyzCheck("informers", cacheSyncCheck(mgr.GetCache())); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to add cache sync check: %w", err)
 	}
 	log.Info("Health checks added successfully", nil)
 	
-	return mgr, nil
+	return mgr, reconciler, leader, nil
+}
+
+// cacheNamespaces returns the cache namespaces of a manager watching
+// namespaces, nil for all namespaces. Excluded namespaces are left out of
+// the watch of all namespaces with a field selector.
+func cacheNamespaces(namespaces config.NamespaceScope) map[string]cache.Config {
+	if !namespaces.All() {
+		defaults := make(map[string]cache.Config, len(namespaces.Include))
+		for _, namespace := range namespaces.Include {
+			defaults[namespace] = cache.Config{}
+		}
+		return defaults
+	}
+	if len(namespaces.Exclude) == 0 {
+		return nil
+	}
+	
+	selectors := make([]fields.Selector, 0, len(namespaces.Exclude))
+	for _, namespace := range namespaces.Exclude {
+		selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+	}
+	return map[string]cache.Config{
+		cache.AllNamespaces: {FieldSelector: fields.AndSelectors(selectors...)},
+	}
+}
+
+// cacheSyncCheck fails readiness until the informer caches have synced, so
+// that the controller only reports ready once it reconciles from a full cache
+func cacheSyncCheck(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("informer caches not synced")
+		}
+		return nil
+	}
+}
+
+// SetMetrics records reconciles and deployment events of the reconcilers in
+// m, labeled with their cluster. It must be called before Start.
+func (m *Manager) SetMetrics(metrics *metrics.Metrics) {
+	if m.reconciler != nil {
+		m.reconciler.SetMetrics(metrics)
+	}
+	if m.multiMgr != nil {
+		m.multiMgr.SetMetrics(metrics)
+	}
 }
 
 // Start starts the controller manager
 func (m *Manager) Start(ctx context.Context) error {
 	m.log.Info("Starting controller manager", "mode", m.mode)
+	m.startTime = time.Now()
+	if m.notifier != nil {
+		go m.notifier.Start(ctx)
+	}
 	
 	if m.mode == "multi" {
 		// Multi-cluster mode
+		if m.statusReporter != nil {
+			go func() {
+				_ = m.statusReporter.Start(ctx)
+			}()
+		}
+		go m.serveProbes(ctx, fmt.Sprintf(":%d", m.config.Controller.Single.HealthPort))
+		go m.serveMetrics(ctx, fmt.Sprintf(":%d", m.config.Controller.Single.MetricsPort))
+		go m.secretWatcher.Start(ctx)
+		go m.pool.Start(ctx, m.config.MultiCluster.HealthCheckInterval)
+		if m.failover != nil {
+			go m.runFailover(ctx)
+		}
+		if m.webhookServer != nil {
+			go func() {
+				if err := m.webhookServer.Start(ctx); err != nil {
+					m.log.Error(err, "Webhook server failed")
+				}
+			}()
+		}
 		return m.multiMgr.Start(ctx)
 	} else {
 		// Single cluster mode
@@ -196,6 +722,123 @@ func (m *Manager) Start(ctx context.Context) error {
 	}
 }
 
+// runFailover checks the primary cluster until ctx is done. When several
+// replicas share the clusters, through leader election or sharding, only the
+// replica holding the failover lease checks it, standing for election again
+// after losing the lease.
+func (m *Manager) runFailover(ctx context.Context) {
+	if !m.config.MultiCluster.LeaderElection.Enabled && !m.config.MultiCluster.Sharding.Enabled {
+		m.failover.Start(ctx)
+		return
+	}
+	
+	_, _, retryPeriod := m.config.MultiCluster.LeaderElection.Timings()
+	for {
+		if err := m.electFailover(ctx); err != nil {
+			m.log.Error(err, "Failover leader election failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryPeriod):
+		}
+	}
+}
+
+// electFailover runs the failover monitor as a leader election runnable of a
+// manager in the cluster k6s runs in, until ctx is done or the lease is lost
+func (m *Manager) electFailover(ctx context.Context) error {
+	restConfig, err := kubernetes.RestConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to get kubernetes config: %w", err)
+	}
+	kubernetes.ApplyClientConfig(restConfig, m.config.Controller.Client)
+	
+	mgr, err := ctrl.NewManager(restConfig, failoverElectionOptions(m.config, m.log))
+	if err != nil {
+		return fmt.Errorf("failed to create failover manager: %w", err)
+	}
+	err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		m.failover.Start(ctx)
+		return nil
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to add failover monitor: %w", err)
+	}
+	return mgr.Start(ctx)
+}
+
+// failoverElectionOptions returns the options of the manager electing the
+// replica that runs failover. The lease is named after the multi-cluster
+// leader election, or after the shard group when only sharding is enabled.
+func failoverElectionOptions(cfg *config.Config, log logr.Logger) ctrl.Options {
+	election := cfg.MultiCluster.LeaderElection
+	id, namespace := election.ID, election.Namespace
+	if !election.Enabled {
+		id, namespace = cfg.MultiCluster.Sharding.Group, cfg.MultiCluster.Sharding.Namespace
+	}
+	if namespace == "" {
+		namespace = kubernetes.InClusterNamespace()
+	}
+	leaseDuration, renewDeadline, retryPeriod := election.Timings()
+	return ctrl.Options{
+		Metrics: server.Options{
+			BindAddress: "0", // Metrics are served by serveMetrics
+		},
+		HealthProbeBindAddress:        "0", // Probes are served by serveProbes
+		LeaderElection:                true,
+		LeaderElectionID:              id + "-failover",
+		LeaderElectionNamespace:       namespace,
+		LeaderElectionResourceLock:    election.ResourceLock,
+		LeaderElectionReleaseOnCancel: election.ReleaseOnCancel,
+		LeaseDuration:                 &leaseDuration,
+		RenewDeadline:                 &renewDeadline,
+		RetryPeriod:                   &retryPeriod,
+		Logger:                        log.WithName("failover"),
+	}
+}
+
+// serveProbes serves /healthz and /readyz in multi-cluster mode, where the
+// per-cluster managers run without probes, until ctx is done. /readyz
+// reports the startup progress of the clusters.
+func (m *Manager) serveProbes(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.Handle("/readyz", m.multiMgr.ReadyzHandler())
+	
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	
+	m.log.Info("Serving health probes", "address", addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		m.log.Error(err, "Health probe server failed", "address", addr)
+	}
+}
+
+// serveMetrics serves the controller-runtime metrics registry at /metrics in
+// multi-cluster mode, where the per-cluster managers serve no metrics, until
+// ctx is done
+func (m *Manager) serveMetrics(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(ctrlmetrics.Registry, promhttp.HandlerOpts{}))
+	
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	
+	m.log.Info("Serving metrics", "address", addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		m.log.Error(err, "Metrics server failed", "address", addr)
+	}
+}
+
 // Stop stops the controller manager
 func (m *Manager) Stop() error {
 	m.log.Info("Stopping controller manager")
@@ -214,7 +857,7 @@ func (m *Manager) Stop() error {
 func (m *Manager) GetMetrics() map[string]interface{} {
 	metrics := map[string]interface{}{
 		"mode":           m.mode,
-		"namespace":      m.config.Controller.Single.Namespace,
+		"namespace":      m.config.Controller.Single.NamespaceScope().String(),
 		"concurrency":    1, // Default concurrency
 		"leader_election": m.config.Controller.Single.LeaderElection.Enabled,
 	}
@@ -253,7 +896,14 @@ func (m *Manager) AddCluster(clusterName string, clusterConfig *cluster.ClusterC
 	}
 	
 	// Add to registry
-	return m.registry.AddCluster(clusterName, clusterConfig)
+	if err := m.registry.AddCluster(clusterName, clusterConfig); err != nil {
+		return err
+	}
+	
+	if !clusterConfig.Enabled {
+		return nil
+	}
+	return m.multiMgr.AddCluster(clusterName, clusterConfig)
 }
 
 // RemoveCluster removes a cluster from the manager (multi-cluster mode only)
@@ -267,7 +917,81 @@ func (m *Manager) RemoveCluster(clusterName string) error {
 	}
 	
 	// Remove from registry
-	return m.registry.RemoveCluster(clusterName)
+	if err := m.registry.RemoveCluster(clusterName); err != nil {
+		return err
+	}
+	return m.multiMgr.RemoveCluster(clusterName)
+}
+
+// ReloadClusters applies changes to the configured cluster list. It is
+// subscribed to the configuration reloader; clusters whose settings changed
+// are removed and added again. Single-cluster mode ignores the cluster list.
+func (m *Manager) ReloadClusters(oldConfig, newConfig *config.Config) {
+	if m.mode != "multi" {
+		return
+	}
+	
+	if oldConfig.MultiCluster.ReadyQuorum != newConfig.MultiCluster.ReadyQuorum {
+		m.multiMgr.SetReadyQuorum(newConfig.MultiCluster.ReadyQuorum)
+	}
+	if oldConfig.MultiCluster.MaxConcurrentConns != newConfig.MultiCluster.MaxConcurrentConns {
+		m.pool.SetMaxClients(newConfig.MultiCluster.MaxConcurrentConns)
+	}
+	if m.secretWatcher != nil {
+		m.secretWatcher.SetClusters(newConfig.MultiCluster.Clusters)
+	}
+	if m.failover != nil {
+		m.failover.SetConfig(newConfig.MultiCluster.Failover)
+		m.failover.SetClusters(newConfig.MultiCluster.Clusters)
+	}
+	
+	oldClusters := clustersByName(oldConfig)
+	newClusters := clustersByName(newConfig)
+	
+	for name, oldCluster := range oldClusters {
+		if newCluster, exists := newClusters[name]; exists && newCluster == oldCluster {
+			continue
+		}
+		if err := m.RemoveCluster(name); err != nil {
+			m.log.Error(err, "Failed to remove cluster after config reload", "cluster", name)
+			continue
+		}
+		m.log.Info("Removed cluster after config reload", "cluster", name)
+	}
+	
+	for name, newCluster := range newClusters {
+		if oldCluster, exists := oldClusters[name]; exists && oldCluster == newCluster {
+			continue
+		}
+		if err := m.AddCluster(name, cluster.NewClusterConfigFrom(newCluster)); err != nil {
+			m.log.Error(err, "Failed to add cluster after config reload", "cluster", name)
+			continue
+		}
+		m.log.Info("Added cluster after config reload", "cluster", name, "enabled", newCluster.Enabled)
+	}
+}
+
+// refreshCluster rebuilds a cluster whose kubeconfig Secret changed, so its
+// manager reconnects with the new credentials
+func (m *Manager) refreshCluster(clusterConfig config.ClusterConfig) {
+	if err := m.RemoveCluster(clusterConfig.Name); err != nil {
+		m.log.Error(err, "Failed to remove cluster after kubeconfig secret change", "cluster", clusterConfig.Name)
+		return
+	}
+	if err := m.AddCluster(clusterConfig.Name, cluster.NewClusterConfigFrom(clusterConfig)); err != nil {
+		m.log.Error(err, "Failed to add cluster after kubeconfig secret change", "cluster", clusterConfig.Name)
+		return
+	}
+	m.log.Info("Rebuilt cluster after kubeconfig secret change", "cluster", clusterConfig.Name)
+}
+
+// clustersByName indexes the configured clusters by name
+func clustersByName(cfg *config.Config) map[string]config.ClusterConfig {
+	clusters := make(map[string]config.ClusterConfig, len(cfg.MultiCluster.Clusters))
+	for _, clusterConfig := range cfg.MultiCluster.Clusters {
+		clusters[clusterConfig.Name] = clusterConfig
+	}
+	return clusters
 }
 
 // GetClusterStatus returns the status of all clusters
@@ -284,7 +1008,7 @@ func (m *Manager) GetClusterStatus() map[string]ClusterStatus {
 		status[name] = ClusterStatus{
 			Name:      name,
 			Ready:     true, // Assume ready in single cluster mode
-			StartTime: time.Now(),
+			StartTime: m.startTime,
 		}
 	}
 	
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+)
+
+func TestManagerReloadClusters(t *testing.T) {
+	registry := cluster.NewInMemoryClusterRegistry()
+	m := &Manager{
+		registry: registry,
+		multiMgr: NewMultiClusterManager(registry, config.NamespaceScope{}, 1),
+		log:      logr.Discard(),
+		mode:     "multi",
+	}
+
+	withClusters := func(clusters ...config.ClusterConfig) *config.Config {
+		cfg := config.DefaultConfig()
+		cfg.MultiCluster.Clusters = clusters
+		return cfg
+	}
+
+	oldConfig := withClusters(
+		config.ClusterConfig{Name: "staging", Context: "staging"},
+		config.ClusterConfig{Name: "legacy", Context: "legacy"},
+	)
+	for _, c := range oldConfig.MultiCluster.Clusters {
+		if err := registry.AddCluster(c.Name, cluster.NewClusterConfigFrom(c)); err != nil {
+			t.Fatalf("AddCluster() error = %v", err)
+		}
+	}
+
+	newConfig := withClusters(
+		config.ClusterConfig{Name: "staging", Context: "staging-v2"},
+		config.ClusterConfig{Name: "dev", Context: "dev"},
+	)
+	m.ReloadClusters(oldConfig, newConfig)
+
+	names := registry.ListClusters()
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "dev" || names[1] != "staging" {
+		t.Fatalf("Expected clusters [dev staging], got %v", names)
+	}
+	staging, _ := registry.GetCluster("staging")
+	if staging.(*cluster.ClusterConfig).Context != "staging-v2" {
+		t.Errorf("Expected staging to be replaced with the new context")
+	}
+
+	// Single-cluster mode ignores the cluster list
+	m.mode = "single"
+	m.ReloadClusters(newConfig, oldConfig)
+	if len(registry.ListClusters()) != 2 {
+		t.Errorf("Expected single-cluster mode to leave the registry alone")
+	}
+}
+
+func TestCacheNamespaces(t *testing.T) {
+	if got := cacheNamespaces(config.NewNamespaceScope("", nil)); got != nil {
+		t.Errorf("Expected no namespace filter for all namespaces, got %v", got)
+	}
+
+	got := cacheNamespaces(config.NewNamespaceScope("shop,payments,kube-system", []string{"kube-system"}))
+	if len(got) != 2 {
+		t.Fatalf("Expected shop and payments to be cached, got %v", got)
+	}
+	if _, ok := got["shop"]; !ok {
+		t.Errorf("Expected shop to be cached, got %v", got)
+	}
+
+	got = cacheNamespaces(config.NewNamespaceScope("", []string{"kube-system"}))
+	all, ok := got[""]
+	if len(got) != 1 || !ok {
+		t.Fatalf("Expected a filtered watch of all namespaces, got %v", got)
+	}
+	if selector := all.FieldSelector.String(); selector != "metadata.namespace!=kube-system" {
+		t.Errorf("Unexpected field selector %q", selector)
+	}
+}
+
+func TestFailoverElectionOptions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MultiCluster.LeaderElection = config.LeaderElectionConfig{Enabled: true, ID: "k6s-controller", Namespace: "k6s"}
+
+	opts := failoverElectionOptions(cfg, logr.Discard())
+	if !opts.LeaderElection || opts.LeaderElectionID != "k6s-controller-failover" || opts.LeaderElectionNamespace != "k6s" {
+		t.Errorf("Expected the failover lease k6s/k6s-controller-failover, got %s/%s (election %v)", opts.LeaderElectionNamespace, opts.LeaderElectionID, opts.LeaderElection)
+	}
+	if opts.Metrics.BindAddress != "0" || opts.HealthProbeBindAddress != "0" {
+		t.Errorf("Expected the failover manager to serve no metrics or probes")
+	}
+
+	// With sharding only, the lease is named after the shard group
+	cfg.MultiCluster.LeaderElection.Enabled = false
+	cfg.MultiCluster.Sharding = config.ShardingConfig{Enabled: true, Group: "edge", Namespace: "shards"}
+	opts = failoverElectionOptions(cfg, logr.Discard())
+	if !opts.LeaderElection || opts.LeaderElectionID != "edge-failover" || opts.LeaderElectionNamespace != "shards" {
+		t.Errorf("Expected the failover lease shards/edge-failover, got %s/%s (election %v)", opts.LeaderElectionNamespace, opts.LeaderElectionID, opts.LeaderElection)
+	}
+}
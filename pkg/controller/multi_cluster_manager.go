@@ -8,27 +8,84 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/notify"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
+// clusterShutdownTimeout bounds how long RemoveCluster waits for a cluster
+// manager to stop; controller-runtime's own graceful shutdown takes up to 30s
+const clusterShutdownTimeout = 45 * time.Second
+
+// Bounds of the doubling delay between startups of a failed cluster manager
+const (
+	clusterRetryInitialBackoff = 5 * time.Second
+	clusterRetryMaxBackoff     = 5 * time.Minute
+)
+
 // MultiClusterManager manages controllers across multiple clusters
 type MultiClusterManager struct {
 	registry    cluster.ClusterRegistry
-	managers    map[string]manager.Manager
-	reconcilers map[string]*DeploymentReconciler
+	managers    map[string]*clusterManager
 	log         logr.Logger
 	
 	// Configuration
-	namespace   string
+	namespaces  config.NamespaceScope
 	concurrency int
+	readyQuorum int
+	
+	// Clusters in startup order, including those not started yet
+	startup []startupEntry
+	
+	// Restarts and failures of each cluster's managers, kept across restarts
+	history map[string]*managerHistory
+	
+	// Clusters whose manager failed to start or exited with an error, retried
+	// with backoff until they sync
+	degraded        map[string]*degradedCluster
+	retryBackoff    time.Duration
+	retryMaxBackoff time.Duration
+	
+	// Prometheus metrics given to each cluster's reconciler
+	metrics *metrics.Metrics
+	
+	// Desired state restored by the reconciler of a cluster, keyed by cluster
+	desired map[string]DesiredState
+	
+	// Whether every cluster enforces its deployment policies
+	policies bool
+	
+	// Tuning of every cluster's API clients
+	client config.KubeClientConfig
+	
+	// Whether every cluster runs cleanup hooks through the finalizer, the
+	// labels of deployments it is added to and the hooks
+	finalize          bool
+	finalizerSelector map[string]string
+	hooks             []CleanupHook
+	
+	// Whether reconcilers and policy enforcers only observe deployments
+	readOnly bool
+	
+	// Notifies the deployment events of every cluster, nil when disabled
+	notifier *notify.Notifier
+	
+	// Election of the replica reconciling each cluster, with a lease in
+	// that cluster
+	leaderElection config.LeaderElectionConfig
+	
+	// Assigns this replica its share of the clusters, nil without sharding
+	sharder *cluster.Sharder
 	
 	// Lifecycle
 	ctx    context.Context
@@ -38,24 +95,117 @@ type MultiClusterManager struct {
 }
 
 // NewMultiClusterManager creates a new multi-cluster manager
-func NewMultiClusterManager(registry cluster.ClusterRegistry, namespace string, concurrency int) *MultiClusterManager {
+func NewMultiClusterManager(registry cluster.ClusterRegistry, namespaces config.NamespaceScope, concurrency int) *MultiClusterManager {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	return &MultiClusterManager{
-		registry:    registry,
-		managers:    make(map[string]manager.Manager),
-		reconcilers: make(map[string]*DeploymentReconciler),
-		log:         logger.WithComponent("multi-cluster-manager").GetLogr(),
-		namespace:   namespace,
-		concurrency: concurrency,
-		ctx:         ctx,
-		cancel:      cancel,
+		registry:        registry,
+		managers:        make(map[string]*clusterManager),
+		history:         make(map[string]*managerHistory),
+		degraded:        make(map[string]*degradedCluster),
+		retryBackoff:    clusterRetryInitialBackoff,
+		retryMaxBackoff: clusterRetryMaxBackoff,
+		desired:         make(map[string]DesiredState),
+		log:             logger.WithComponent("multi-cluster-manager").GetLogr(),
+		namespaces:      namespaces,
+		concurrency:     concurrency,
+		ctx:             ctx,
+		cancel:          cancel,
 	}
 }
 
+// SetMetrics records the reconciles and deployment events of every cluster's
+// reconciler in metrics. It must be called before Start.
+func (m *MultiClusterManager) SetMetrics(metrics *metrics.Metrics) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.metrics = metrics
+}
+
+// SetClientConfig tunes the API clients of every cluster's manager. It must
+// be called before Start.
+func (m *MultiClusterManager) SetClientConfig(client config.KubeClientConfig) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.client = client
+}
+
+// EnablePolicies makes every cluster enforce its K6sDeploymentPolicy
+// objects. It must be called before Start.
+func (m *MultiClusterManager) EnablePolicies() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.policies = true
+}
+
+// SetReadOnly keeps every cluster's reconciler from writing status
+// annotations and its policy enforcer from scaling deployments. It must be
+// called before Start.
+func (m *MultiClusterManager) SetReadOnly(readOnly bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.readOnly = readOnly
+}
+
+// SetNotifier makes every cluster's reconciler notify the deployments it sees
+// added, updated and deleted. It must be called before Start.
+func (m *MultiClusterManager) SetNotifier(notifier *notify.Notifier) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.notifier = notifier
+}
+
+// EnableFinalizer makes every cluster run the cleanup hooks of deployments
+// carrying the finalizer before they are deleted, and add the finalizer to
+// deployments matching selector. It must be called before Start.
+func (m *MultiClusterManager) EnableFinalizer(selector map[string]string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.finalize = true
+	m.finalizerSelector = selector
+}
+
+// RegisterCleanupHook adds a hook run by every cluster before deployments
+// carrying the finalizer are deleted. Hooks only run once the finalizer is
+// enabled. It must be called before Start.
+func (m *MultiClusterManager) RegisterCleanupHook(hook CleanupHook) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// SetLeaderElection makes the managers of every cluster reconcile only while
+// this replica holds the lease of election in that cluster, so that several
+// replicas can share the clusters. It must be called before Start.
+func (m *MultiClusterManager) SetLeaderElection(election config.LeaderElectionConfig) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.leaderElection = election
+}
+
+// SetSharder makes this replica run only the clusters sharder assigns it,
+// starting and stopping clusters as replicas join and leave. It must be
+// called before Start.
+func (m *MultiClusterManager) SetSharder(sharder *cluster.Sharder) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sharder = sharder
+}
+
+// SetDesiredState makes the reconciler of a cluster restore the deployments
+// desired declares, including after the cluster's manager restarts. A
+// desired state that is also a manager.Runnable is run by the cluster's
+// manager, so only by the replica reconciling the cluster. It must be called
+// before the cluster starts.
+func (m *MultiClusterManager) SetDesiredState(clusterName string, desired DesiredState) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.desired[clusterName] = desired
+}
+
 // Start starts the multi-cluster manager
 func (m *MultiClusterManager) Start(ctx context.Context) error {
-	m.log.Info("Starting multi-cluster manager", "namespace", m.namespace, "concurrency", m.concurrency)
+	m.log.Info("Starting multi-cluster manager", "namespaces", m.namespaces.String(), "concurrency", m.concurrency)
 	
 	// Get enabled clusters
 	clusters := m.registry.GetEnabledClusters()
@@ -63,18 +213,59 @@ func (m *MultiClusterManager) Start(ctx context.Context) error {
 		return fmt.Errorf("no enabled clusters found")
 	}
 	
-	// Start managers for each cluster
-	for clusterName, clusterConfig := range clusters {
-		if err := m.startClusterManager(clusterName, clusterConfig); err != nil {
-			m.log.Error(err, "Failed to start cluster manager", "cluster", clusterName)
-			return fmt.Errorf("failed to start cluster manager %s: %w", clusterName, err)
+	// With sharding, only the clusters assigned to this replica start;
+	// owning none of them is fine while there are more replicas than clusters
+	rebalance := make(chan struct{}, 1)
+	if m.sharder != nil {
+		if err := m.sharder.Join(ctx); err != nil {
+			return fmt.Errorf("failed to join shard group: %w", err)
+		}
+		m.sharder.OnChange(func() {
+			select {
+			case rebalance <- struct{}{}:
+			default:
+			}
+		})
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.sharder.Start(m.ctx)
+		}()
+		clusters = m.ownedClusters(clusters)
+		m.log.Info("Joined shard group", "identity", m.sharder.Identity(), "members", len(m.sharder.Members()), "clusters", len(clusters))
+	}
+	
+	// Start managers in priority order. Secondaries wait for the primary to
+	// sync, so that it gets the API and memory headroom first.
+	order := startupOrder(clusters)
+	m.mutex.Lock()
+	m.startup = order
+	m.mutex.Unlock()
+	
+	// A cluster that fails to start is retried in the background, so that
+	// one unreachable cluster doesn't keep the healthy ones from starting
+	for i, entry := range order {
+		if err := m.startWithRetry(entry.name, clusters[entry.name]); err != nil {
+			m.log.Error(err, "Failed to start cluster manager, retrying in the background", "cluster", entry.name)
+			continue
+		}
+		if i == 0 && len(order) > 1 && !m.waitForSync(ctx, entry.name, primarySyncTimeout) {
+			m.log.Info("Primary cluster not synced, starting secondary clusters anyway", "cluster", entry.name)
 		}
 	}
 	
 	m.log.Info("Multi-cluster manager started", "clusters", len(clusters))
 	
-	// Wait for context cancellation
-	<-ctx.Done()
+	// Move clusters as replicas join and leave until ctx is done; changes
+	// seen during startup are handled right away
+	for done := false; !done; {
+		select {
+		case <-rebalance:
+			m.rebalance()
+		case <-ctx.Done():
+			done = true
+		}
+	}
 	
 	// Stop all managers
 	m.log.Info("Stopping multi-cluster manager")
@@ -84,13 +275,44 @@ func (m *MultiClusterManager) Start(ctx context.Context) error {
 	return nil
 }
 
+// clusterManager is the controller-runtime manager of one cluster, running
+// under its own context so it can be stopped without affecting other clusters
+type clusterManager struct {
+	mgr        manager.Manager
+	reconciler *DeploymentReconciler
+	cancel     context.CancelFunc
+	done       chan struct{}
+	syncedCh   chan struct{}
+	startTime  time.Time
+	
+	// Whether the manager reconciles only while elected
+	election bool
+}
+
+// managerHistory records the managers started for a cluster
+type managerHistory struct {
+	restarts        int
+	lastFailure     string
+	lastFailureTime time.Time
+}
+
+// running reports whether the manager has not exited yet
+func (c *clusterManager) running() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
 // startClusterManager starts a manager for a specific cluster
 func (m *MultiClusterManager) startClusterManager(clusterName string, clusterConfig cluster.ClusterClient) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	
-	// Check if manager already exists
-	if _, exists := m.managers[clusterName]; exists {
+	// Check if manager already exists; one that exited (e.g. failed to start) can be replaced
+	if existing, exists := m.managers[clusterName]; exists && existing.running() {
 		return fmt.Errorf("manager for cluster %s already exists", clusterName)
 	}
 	
@@ -99,6 +321,9 @@ func (m *MultiClusterManager) startClusterManager(clusterName string, clusterCon
 	if err != nil {
 		return fmt.Errorf("failed to get REST config for cluster %s: %w", clusterName, err)
 	}
+	// The cluster's REST config is shared, so the tuning is applied to a copy
+	restConfig = rest.CopyConfig(restConfig)
+	kubernetes.ApplyClientConfig(restConfig, m.client)
 	
 	// Create manager options
 	opts := ctrl.Options{
@@ -107,18 +332,31 @@ func (m *MultiClusterManager) startClusterManager(clusterName string, clusterCon
 			BindAddress: "0", // Disable metrics for individual cluster managers
 		},
 		HealthProbeBindAddress: "0", // Disable health probes for individual cluster managers
-		LeaderElection:         false, // Leader election is handled at the multi-cluster level
-		LeaderElectionID:       "",
 		Logger:                 logger.WithCluster(clusterName).GetLogr(),
 	}
 	
-	// Add namespace filter if specified
-	if m.namespace != "" {
-		opts.Cache.DefaultNamespaces = map[string]cache.Config{
-			m.namespace: {},
-		}
+	// The lease is held in the cluster itself, so each cluster elects its
+	// own leader among the replicas
+	if election := m.leaderElection; election.Enabled {
+		leaseDuration, renewDeadline, retryPeriod := election.Timings()
+		opts.LeaderElection = true
+		opts.LeaderElectionID = election.ID
+		opts.LeaderElectionNamespace = election.Namespace
+		opts.LeaderElectionResourceLock = election.ResourceLock
+		opts.LeaderElectionReleaseOnCancel = election.ReleaseOnCancel
+		opts.LeaseDuration = &leaseDuration
+		opts.RenewDeadline = &renewDeadline
+		opts.RetryPeriod = &retryPeriod
 	}
 	
+	// Policies are read from the cache like deployments
+	if m.policies {
+		opts.Client.Cache = &client.CacheOptions{Unstructured: true}
+	}
+	
+	// Only cache the watched namespaces
+	opts.Cache.DefaultNamespaces = cacheNamespaces(m.namespaces)
+	
 	// Add schemes
 	if err := appsv1.AddToScheme(opts.Scheme); err != nil {
 		return fmt.Errorf("failed to add apps/v1 scheme: %w", err)
@@ -131,35 +369,199 @@ func (m *MultiClusterManager) startClusterManager(clusterName string, clusterCon
 	}
 	
 	// Create and add deployment reconciler
-	reconciler := NewDeploymentReconciler(mgr, clusterName, m.namespace, m.concurrency)
+	reconciler := NewDeploymentReconciler(mgr, clusterName, m.namespaces, m.concurrency)
+	reconciler.SetMetrics(m.metrics)
+	reconciler.SetReadOnly(m.readOnly)
+	reconciler.SetNotifier(m.notifier)
+	if desired := m.desired[clusterName]; desired != nil {
+		reconciler.SetDesiredState(desired)
+		// A desired state that runs itself, like the GitOps syncer, runs
+		// with the reconciler: on the elected leader of the cluster only
+		if runnable, ok := desired.(manager.Runnable); ok {
+			if err := mgr.Add(runnable); err != nil {
+				return fmt.Errorf("failed to add desired state for cluster %s: %w", clusterName, err)
+			}
+		}
+	}
+	if m.finalize {
+		reconciler.EnableFinalizer(m.finalizerSelector)
+	}
+	for _, hook := range m.hooks {
+		reconciler.RegisterCleanupHook(hook)
+	}
 	if err := reconciler.SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("failed to setup deployment reconciler for cluster %s: %w", clusterName, err)
 	}
+	if m.policies {
+		enforcer := NewPolicyEnforcer(mgr, clusterName)
+		enforcer.SetReadOnly(m.readOnly)
+		if err := enforcer.SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("failed to setup deployment policy controller for cluster %s: %w", clusterName, err)
+		}
+		reconciler.SetPolicyEnforcer(enforcer)
+	}
 	
 	// Store manager and reconciler
-	m.managers[clusterName] = mgr
-	m.reconcilers[clusterName] = reconciler
+	ctx, cancel := context.WithCancel(m.ctx)
+	cm := &clusterManager{
+		mgr:        mgr,
+		reconciler: reconciler,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		syncedCh:   make(chan struct{}),
+		startTime:  time.Now(),
+		election:   m.leaderElection.Enabled,
+	}
+	m.managers[clusterName] = cm
+	m.recordStart(clusterName, cm.startTime)
+	if !m.inStartup(clusterName) {
+		// Clusters added at runtime start right away
+		m.startup = append(m.startup, newStartupEntry(clusterName, clusterConfig))
+	}
+	go cm.trackSync(ctx)
+	if cm.election {
+		go cm.trackLeadership(ctx, clusterName)
+	}
 	
 	// Start manager in a goroutine
 	m.wg.Add(1)
-	go func(clusterName string, mgr manager.Manager) {
+	go func(clusterName string, cm *clusterManager) {
 		defer m.wg.Done()
+		defer close(cm.done)
 		
 		m.log.Info("Starting cluster manager", "cluster", clusterName)
-		if err := mgr.Start(m.ctx); err != nil {
+		if err := cm.mgr.Start(ctx); err != nil {
 			m.log.Error(err, "Cluster manager failed", "cluster", clusterName)
+			m.recordFailure(clusterName, err)
+			switch {
+			case ctx.Err() != nil:
+			case cm.election:
+				// Losing the lease stops the manager; stand for election
+				// again rather than leave the cluster to the other replicas
+				m.wg.Add(1)
+				go m.rejoinElection(clusterName, cm)
+			default:
+				m.scheduleRetry(clusterName, err)
+			}
 		}
 		m.log.Info("Cluster manager stopped", "cluster", clusterName)
-	}(clusterName, mgr)
+	}(clusterName, cm)
 	
 	return nil
 }
 
-// AddCluster adds a new cluster to the multi-cluster manager
+// trackLeadership reports whether this replica leads a cluster, from when
+// its manager is elected until it stops
+func (c *clusterManager) trackLeadership(ctx context.Context, clusterName string) {
+	defer metrics.ClusterLeader.WithLabelValues(clusterName).Set(0)
+	metrics.ClusterLeader.WithLabelValues(clusterName).Set(0)
+	select {
+	case <-c.mgr.Elected():
+		metrics.ClusterLeader.WithLabelValues(clusterName).Set(1)
+	case <-ctx.Done():
+		return
+	case <-c.done:
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-c.done:
+	}
+}
+
+// leader reports whether the manager has been elected and is still running
+func (c *clusterManager) leader() bool {
+	select {
+	case <-c.mgr.Elected():
+		return c.running()
+	default:
+		return false
+	}
+}
+
+// rejoinElection starts a new manager for a cluster once the manager cm,
+// stopped by a lost lease, has exited, unless the cluster was removed or
+// restarted meanwhile
+func (m *MultiClusterManager) rejoinElection(clusterName string, cm *clusterManager) {
+	defer m.wg.Done()
+	
+	_, _, retryPeriod := m.leaderElection.Timings()
+	select {
+	case <-cm.done:
+	case <-m.ctx.Done():
+		return
+	}
+	select {
+	case <-time.After(retryPeriod):
+	case <-m.ctx.Done():
+		return
+	}
+	
+	m.mutex.RLock()
+	current := m.managers[clusterName]
+	m.mutex.RUnlock()
+	if current != cm {
+		return
+	}
+	clusterClient, exists := m.registry.GetCluster(clusterName)
+	if !exists {
+		return
+	}
+	m.log.Info("Rejoining leader election", "cluster", clusterName)
+	if err := m.startClusterManager(clusterName, clusterClient); err != nil {
+		m.log.Error(err, "Failed to rejoin leader election", "cluster", clusterName)
+	}
+}
+
+// recordStart counts a manager started for a cluster, after the first one as
+// a restart; callers hold the mutex
+func (m *MultiClusterManager) recordStart(clusterName string, startTime time.Time) {
+	history, exists := m.history[clusterName]
+	if !exists {
+		history = &managerHistory{}
+		m.history[clusterName] = history
+	} else {
+		history.restarts++
+		metrics.ClusterManagerRestarts.WithLabelValues(clusterName).Inc()
+	}
+	metrics.ClusterManagerStartTime.WithLabelValues(clusterName).Set(float64(startTime.Unix()))
+}
+
+// recordFailure records a cluster manager that exited with an error
+func (m *MultiClusterManager) recordFailure(clusterName string, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if history, exists := m.history[clusterName]; exists {
+		history.lastFailure = err.Error()
+		history.lastFailureTime = time.Now()
+	}
+	metrics.ClusterManagerFailures.WithLabelValues(clusterName).Inc()
+}
+
+// inStartup reports whether a cluster is in the startup order; callers hold the mutex
+func (m *MultiClusterManager) inStartup(clusterName string) bool {
+	for _, entry := range m.startup {
+		if entry.name == clusterName {
+			return true
+		}
+	}
+	return false
+}
+
+// AddCluster adds a new cluster to the multi-cluster manager. With sharding,
+// a cluster assigned to another replica is left to it.
 func (m *MultiClusterManager) AddCluster(clusterName string, clusterConfig cluster.ClusterClient) error {
+	if m.sharder != nil {
+		defer m.recordOwnedClusters()
+		if !m.sharder.Owns(clusterName) {
+			m.log.Info("Cluster assigned to another replica", "cluster", clusterName)
+			return nil
+		}
+	}
+	
 	m.log.Info("Adding cluster", "cluster", clusterName)
 	
-	if err := m.startClusterManager(clusterName, clusterConfig); err != nil {
+	if err := m.startWithRetry(clusterName, clusterConfig); err != nil {
 		return fmt.Errorf("failed to add cluster %s: %w", clusterName, err)
 	}
 	
@@ -167,41 +569,147 @@ func (m *MultiClusterManager) AddCluster(clusterName string, clusterConfig clust
 	return nil
 }
 
-// RemoveCluster removes a cluster from the multi-cluster manager
+// RemoveCluster stops the manager of a cluster and waits for it to shut down.
+// Removing a cluster without a manager is a no-op.
 func (m *MultiClusterManager) RemoveCluster(clusterName string) error {
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	cm, exists := m.managers[clusterName]
+	delete(m.managers, clusterName)
+	m.clearDegraded(clusterName)
+	for i, entry := range m.startup {
+		if entry.name == clusterName {
+			m.startup = append(m.startup[:i], m.startup[i+1:]...)
+			break
+		}
+	}
+	m.mutex.Unlock()
 	
-	m.log.Info("Removing cluster", "cluster", clusterName)
+	if m.sharder != nil {
+		m.recordOwnedClusters()
+	}
+	if !exists {
+		return nil
+	}
 	
-	// Remove from maps
-	delete(m.managers, clusterName)
-	delete(m.reconcilers, clusterName)
+	m.log.Info("Removing cluster", "cluster", clusterName)
 	
-	// Note: We don't actively stop the manager here as it's complex to do safely
-	// The manager will stop when the main context is cancelled
+	// Cancel outside the lock: controller-runtime drains in-flight reconciles
+	cm.cancel()
+	select {
+	case <-cm.done:
+	case <-time.After(clusterShutdownTimeout):
+		return fmt.Errorf("timed out waiting for cluster %s manager to stop", clusterName)
+	}
 	
 	m.log.Info("Cluster removed", "cluster", clusterName)
 	return nil
 }
 
+// ownedClusters returns the clusters assigned to this replica
+func (m *MultiClusterManager) ownedClusters(clusters map[string]cluster.ClusterClient) map[string]cluster.ClusterClient {
+	owned := make(map[string]cluster.ClusterClient)
+	for name, client := range clusters {
+		if m.sharder.Owns(name) {
+			owned[name] = client
+		}
+	}
+	metrics.ShardClusters.Set(float64(len(owned)))
+	return owned
+}
+
+// recordOwnedClusters updates the number of enabled clusters assigned to
+// this replica
+func (m *MultiClusterManager) recordOwnedClusters() {
+	m.ownedClusters(m.registry.GetEnabledClusters())
+}
+
+// rebalance stops the clusters now assigned to another replica and starts
+// the enabled clusters newly assigned to this one
+func (m *MultiClusterManager) rebalance() {
+	m.mutex.RLock()
+	running := make([]string, 0, len(m.managers))
+	for name := range m.managers {
+		running = append(running, name)
+	}
+	m.mutex.RUnlock()
+	
+	for _, name := range running {
+		if m.sharder.Owns(name) {
+			continue
+		}
+		m.log.Info("Releasing cluster to another replica", "cluster", name)
+		if err := m.RemoveCluster(name); err != nil {
+			m.log.Error(err, "Failed to release cluster", "cluster", name)
+		}
+	}
+	
+	for name, client := range m.ownedClusters(m.registry.GetEnabledClusters()) {
+		m.mutex.RLock()
+		_, exists := m.managers[name]
+		m.mutex.RUnlock()
+		if exists {
+			continue
+		}
+		m.log.Info("Claiming cluster", "cluster", name)
+		if err := m.startWithRetry(name, client); err != nil {
+			m.log.Error(err, "Failed to claim cluster", "cluster", name)
+		}
+	}
+}
+
 // GetClusterStatus returns the status of all clusters
 func (m *MultiClusterManager) GetClusterStatus() map[string]ClusterStatus {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 	
 	status := make(map[string]ClusterStatus)
-	for clusterName, mgr := range m.managers {
-		status[clusterName] = ClusterStatus{
+	for clusterName, cm := range m.managers {
+		clusterStatus := ClusterStatus{
 			Name:      clusterName,
-			Ready:     m.isManagerReady(mgr),
-			StartTime: time.Now(), // TODO: Track actual start time
+			Ready:     cm.running() && m.isManagerReady(cm.mgr),
+			StartTime: cm.startTime,
+		}
+		if cm.election {
+			leader := cm.leader()
+			clusterStatus.Leader = &leader
+		}
+		if history, exists := m.history[clusterName]; exists {
+			clusterStatus.Restarts = history.restarts
+			clusterStatus.LastFailure = history.lastFailure
+			clusterStatus.LastFailureTime = history.lastFailureTime
+		}
+		status[clusterName] = clusterStatus
+	}
+	
+	// Clusters that failed to start have no manager yet
+	for clusterName, d := range m.degraded {
+		clusterStatus := status[clusterName]
+		clusterStatus.Name = clusterName
+		clusterStatus.Degraded = true
+		clusterStatus.StartAttempts = d.attempts
+		clusterStatus.NextRetry = d.nextRetry
+		if d.lastFailureTime.After(clusterStatus.LastFailureTime) {
+			clusterStatus.LastFailure = d.lastFailure
+			clusterStatus.LastFailureTime = d.lastFailureTime
 		}
+		status[clusterName] = clusterStatus
 	}
 	
 	return status
 }
 
+// ReconcileSummary returns reconcile statistics aggregated across clusters
+func (m *MultiClusterManager) ReconcileSummary() ReconcileSummary {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	
+	stats := make([]*ReconcileStats, 0, len(m.managers))
+	for _, cm := range m.managers {
+		stats = append(stats, cm.reconciler.Stats())
+	}
+	return mergeReconcileStats(stats...)
+}
+
 // isManagerReady checks if a manager is ready
 func (m *MultiClusterManager) isManagerReady(mgr manager.Manager) bool {
 	// Try to get client to check if manager is ready
@@ -213,11 +721,26 @@ func (m *MultiClusterManager) isManagerReady(mgr manager.Manager) bool {
 	return true
 }
 
-// ClusterStatus represents the status of a cluster
+// ClusterStatus represents the status of a cluster. StartTime is when its
+// current manager started; Restarts counts the managers started after the
+// first, e.g. after a configuration change or a failure.
 type ClusterStatus struct {
-	Name      string    `json:"name"`
-	Ready     bool      `json:"ready"`
-	StartTime time.Time `json:"start_time"`
+	Name            string    `json:"name"`
+	Ready           bool      `json:"ready"`
+	StartTime       time.Time `json:"start_time"`
+	Restarts        int       `json:"restarts"`
+	LastFailure     string    `json:"last_failure,omitempty"`
+	LastFailureTime time.Time `json:"last_failure_time,omitzero"`
+	
+	// Whether this replica holds the lease of the cluster, nil without
+	// leader election
+	Leader *bool `json:"leader,omitempty"`
+	
+	// Whether the manager failed and is retried with backoff, the retried
+	// startups so far and when the next one is due
+	Degraded      bool      `json:"degraded,omitempty"`
+	StartAttempts int       `json:"start_attempts,omitempty"`
+	NextRetry     time.Time `json:"next_retry,omitzero"`
 }
 
 // EnhancedMultiClusterReconciler is a single reconciler that handles multiple clusters
@@ -292,13 +815,17 @@ func (m *MultiClusterManager) GetMetrics() map[string]interface{} {
 		"total_clusters":   len(m.managers),
 		"active_clusters":  0,
 		"cluster_status":   m.GetClusterStatus(),
-		"namespace_filter": m.namespace,
+		"namespace_filter": m.namespaces.String(),
 		"concurrency":      m.concurrency,
 	}
 	
+	if m.sharder != nil {
+		metrics["shard_members"] = m.sharder.Members()
+	}
+	
 	// Count active clusters
-	for _, mgr := range m.managers {
-		if m.isManagerReady(mgr) {
+	for _, cm := range m.managers {
+		if cm.running() && m.isManagerReady(cm.mgr) {
 			metrics["active_clusters"] = metrics["active_clusters"].(int) + 1
 		}
 	}
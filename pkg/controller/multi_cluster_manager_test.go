@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+// unreachableCluster is a cluster whose API server never answers, so its
+// manager runs until it is stopped
+type unreachableCluster struct {
+	name string
+}
+
+func (c unreachableCluster) GetName() string { return c.name }
+func (c unreachableCluster) IsEnabled() bool { return true }
+
+// writeUnreachableKubeconfig writes a kubeconfig for an API server that never
+// answers to path
+func writeUnreachableKubeconfig(t *testing.T, path string) {
+	t.Helper()
+	data := "apiVersion: v1\nkind: Config\nclusters:\n- name: edge\n  cluster:\n    server: http://127.0.0.1:1\ncontexts:\n- name: edge\n  context:\n    cluster: edge\ncurrent-context: edge\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+}
+
+func (c unreachableCluster) GetRestConfig() (*rest.Config, error) {
+	return &rest.Config{Host: "http://127.0.0.1:1", Timeout: time.Second}, nil
+}
+
+func (c unreachableCluster) GetKubernetesClient() (kubernetes.Interface, error) {
+	return kubernetes.NewForConfig(&rest.Config{Host: "http://127.0.0.1:1"})
+}
+
+func (c unreachableCluster) TestConnection(ctx context.Context) error {
+	return context.DeadlineExceeded
+}
+
+func TestMultiClusterManagerAddRemoveCluster(t *testing.T) {
+	m := NewMultiClusterManager(cluster.NewInMemoryClusterRegistry(), config.NamespaceScope{}, 1)
+	defer m.cancel()
+
+	if err := m.AddCluster("edge", unreachableCluster{name: "edge"}); err != nil {
+		t.Fatalf("AddCluster() error = %v", err)
+	}
+	if err := m.AddCluster("edge", unreachableCluster{name: "edge"}); err == nil {
+		t.Error("Expected adding a running cluster twice to fail")
+	}
+
+	m.mutex.RLock()
+	cm := m.managers["edge"]
+	m.mutex.RUnlock()
+	if _, exists := m.GetClusterStatus()["edge"]; !exists || !cm.running() {
+		t.Fatal("Expected edge cluster manager to be running")
+	}
+
+	// Removing the cluster stops its manager without touching the others
+	if err := m.RemoveCluster("edge"); err != nil {
+		t.Fatalf("RemoveCluster() error = %v", err)
+	}
+	if cm.running() {
+		t.Error("Expected edge cluster manager to be stopped")
+	}
+	if _, exists := m.GetClusterStatus()["edge"]; exists {
+		t.Error("Expected edge cluster to be removed from status")
+	}
+	if m.ctx.Err() != nil {
+		t.Error("Expected the multi-cluster manager to keep running")
+	}
+
+	// Removing an unknown cluster is a no-op, and a removed cluster can be added back
+	if err := m.RemoveCluster("edge"); err != nil {
+		t.Errorf("RemoveCluster() of an unknown cluster error = %v", err)
+	}
+	if err := m.AddCluster("edge", unreachableCluster{name: "edge"}); err != nil {
+		t.Errorf("AddCluster() after removal error = %v", err)
+	}
+	status := m.GetClusterStatus()["edge"]
+	if status.Restarts != 1 || !status.StartTime.After(cm.startTime) {
+		t.Errorf("Expected the second manager to count as a restart with its own start time, got %+v", status)
+	}
+	if err := m.RemoveCluster("edge"); err != nil {
+		t.Errorf("RemoveCluster() error = %v", err)
+	}
+}
+
+func TestMultiClusterManagerLeaderElection(t *testing.T) {
+	m := NewMultiClusterManager(cluster.NewInMemoryClusterRegistry(), config.NamespaceScope{}, 1)
+	defer m.cancel()
+	m.SetLeaderElection(config.LeaderElectionConfig{Enabled: true, ID: "k6s-controller", Namespace: "default"})
+
+	if err := m.AddCluster("edge", unreachableCluster{name: "edge"}); err != nil {
+		t.Fatalf("AddCluster() error = %v", err)
+	}
+
+	// The lease of an unreachable cluster is never acquired
+	status := m.GetClusterStatus()["edge"]
+	if status.Leader == nil || *status.Leader {
+		t.Errorf("Expected edge to be reported as not led by this replica, got %v", status.Leader)
+	}
+	if err := m.RemoveCluster("edge"); err != nil {
+		t.Fatalf("RemoveCluster() error = %v", err)
+	}
+
+	// Without leader election, leadership isn't reported
+	m.SetLeaderElection(config.LeaderElectionConfig{})
+	if err := m.AddCluster("edge", unreachableCluster{name: "edge"}); err != nil {
+		t.Fatalf("AddCluster() error = %v", err)
+	}
+	if status := m.GetClusterStatus()["edge"]; status.Leader != nil {
+		t.Errorf("Expected no leadership without leader election, got %v", *status.Leader)
+	}
+}
+
+func TestMultiClusterManagerSharding(t *testing.T) {
+	// Another replica holds a live Lease of the group
+	other, renewed, seconds := "k6s-b", metav1.NewMicroTime(time.Now()), int32(40)
+	clientset := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "k6s", Name: "k6s-controller-k6s-b", Labels: map[string]string{cluster.ShardGroupLabel: "k6s-controller"}},
+		Spec:       coordinationv1.LeaseSpec{HolderIdentity: &other, RenewTime: &renewed, LeaseDurationSeconds: &seconds},
+	})
+	sharder := cluster.NewSharder(clientset.CoordinationV1(), config.ShardingConfig{Group: "k6s-controller", Namespace: "k6s", Interval: 10 * time.Second, LeaseDuration: 40 * time.Second}, "k6s-a")
+	if err := sharder.Join(context.Background()); err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+
+	// Pick a cluster owned by each replica
+	owned, foreign := "", ""
+	for i := 0; owned == "" || foreign == ""; i++ {
+		name := fmt.Sprintf("edge-%d", i)
+		if cluster.ShardOwner(sharder.Members(), name) == "k6s-a" {
+			owned = name
+		} else {
+			foreign = name
+		}
+	}
+
+	registry := cluster.NewInMemoryClusterRegistry()
+	m := NewMultiClusterManager(registry, config.NamespaceScope{}, 1)
+	defer m.cancel()
+	m.SetSharder(sharder)
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	writeUnreachableKubeconfig(t, kubeconfig)
+	for _, name := range []string{owned, foreign} {
+		if err := registry.AddCluster(name, cluster.NewClusterConfigFrom(config.ClusterConfig{Name: name, KubeConfig: kubeconfig, Enabled: true})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := m.AddCluster(foreign, unreachableCluster{name: foreign}); err != nil {
+		t.Fatalf("AddCluster() error = %v", err)
+	}
+	if _, exists := m.GetClusterStatus()[foreign]; exists {
+		t.Errorf("Expected %s to be left to the other replica", foreign)
+	}
+
+	m.rebalance()
+	status := m.GetClusterStatus()
+	if _, exists := status[owned]; !exists || len(status) != 1 {
+		t.Errorf("Expected only %s to run after rebalancing, got %v", owned, status)
+	}
+}
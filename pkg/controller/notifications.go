@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/notify"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// notifyEventTypes maps the reconciler's event types to notification event
+// types; pending updates and status syncs are not notified
+var notifyEventTypes = map[string]string{
+	"add":    notify.EventAdded,
+	"update": notify.EventUpdated,
+	"delete": notify.EventDeleted,
+}
+
+// SetNotifier makes the reconciler notify the deployments it sees added,
+// updated and deleted. It must be called before the manager starts.
+func (r *DeploymentReconciler) SetNotifier(notifier *notify.Notifier) {
+	r.notifier = notifier
+}
+
+// notify delivers a deployment event to the notifier, if any. deployment is
+// nil when it was deleted.
+func (r *DeploymentReconciler) notify(eventType string, namespacedName types.NamespacedName, deployment *appsv1.Deployment) {
+	notifyType, ok := notifyEventTypes[eventType]
+	if r.notifier == nil || !ok {
+		return
+	}
+	event := notify.Event{
+		Type:      notifyType,
+		Source:    notify.SourceController,
+		Cluster:   r.cluster,
+		Namespace: namespacedName.Namespace,
+		Name:      namespacedName.Name,
+	}
+	if deployment != nil {
+		event.Labels = deployment.Labels
+	}
+	r.notifier.Notify(event)
+}
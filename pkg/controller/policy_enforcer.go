@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/policy"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/telemetry"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// policyReconcilerName names the policy reconciler in spans and logs
+const policyReconcilerName = "deploymentpolicy"
+
+// PolicyEnforcer enforces K6sDeploymentPolicy objects: deployments are scaled
+// into the replica bounds of enforcing policies, and the violations of each
+// policy are reported in its status. It reconciles policies when they
+// change, and the deployment reconciler calls it for deployment events.
+type PolicyEnforcer struct {
+	client.Client
+	Log logr.Logger
+
+	// Records violations and scaling as events on deployments, nil to only
+	// log them
+	Recorder record.EventRecorder
+
+	// Report violations without scaling deployments
+	readOnly bool
+
+	// Cluster reported in reconciliation spans
+	cluster string
+}
+
+// NewPolicyEnforcer creates a new PolicyEnforcer
+func NewPolicyEnforcer(mgr manager.Manager, cluster string) *PolicyEnforcer {
+	return &PolicyEnforcer{
+		Client:   mgr.GetClient(),
+		Log:      logger.WithComponent(logger.ComponentReconciler).WithField("reconciler", policyReconcilerName).WithCluster(cluster).GetLogr(),
+		Recorder: mgr.GetEventRecorderFor(kubernetes.EventComponent),
+		cluster:  cluster,
+	}
+}
+
+// SetReadOnly makes the enforcer report violations without scaling
+// deployments. It must be called before the manager starts.
+func (e *PolicyEnforcer) SetReadOnly(readOnly bool) {
+	e.readOnly = readOnly
+}
+
+// newPolicyObject returns an empty K6sDeploymentPolicy object
+func newPolicyObject() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(policy.GVR.GroupVersion().WithKind(policy.Kind))
+	return obj
+}
+
+// SetupWithManager sets up the policy controller with the Manager. Status
+// updates don't change the generation and are filtered out.
+func (e *PolicyEnforcer) SetupWithManager(mgr manager.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(policyReconcilerName).
+		For(newPolicyObject(), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(e)
+}
+
+// Reconcile enforces a policy on the deployments of its namespace and
+// reports their violations in its status
+func (e *PolicyEnforcer) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := telemetry.StartReconcile(ctx, policyReconcilerName, e.cluster, req.Namespace, req.Name)
+	defer func() { telemetry.End(span, err) }()
+
+	obj := newPolicyObject()
+	if err := e.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	p, err := policy.FromUnstructured(obj)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	deployments, err := e.listDeployments(ctx, req.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, dep := range deployments {
+		if err := e.enforce(ctx, dep, []*policy.Policy{p}); err != nil {
+			return ctrl.Result{}, err
+		}
+		e.recordViolations(dep, []*policy.Policy{p})
+	}
+	return ctrl.Result{}, e.updateStatus(ctx, obj, p, deployments)
+}
+
+// ReconcileDeployment scales a deployment into the bounds of the enforcing
+// policies that apply to it and refreshes the status of the policies of its
+// namespace. dep is nil for a deleted deployment, and is updated to the
+// replicas it was scaled to.
+func (e *PolicyEnforcer) ReconcileDeployment(ctx context.Context, namespace string, dep *appsv1.Deployment) error {
+	objects, policies, err := e.listPolicies(ctx, namespace)
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+	if dep != nil {
+		if err := e.enforce(ctx, dep, policies); err != nil {
+			return err
+		}
+		e.recordViolations(dep, policies)
+	}
+
+	deployments, err := e.listDeployments(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	for i := range policies {
+		if err := e.updateStatus(ctx, objects[i], policies[i], deployments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compliance returns the violations of a deployment against the policies of
+// its namespace that apply to it, and whether any policy applies to it
+func (e *PolicyEnforcer) Compliance(ctx context.Context, dep *appsv1.Deployment) ([]policy.Violation, bool, error) {
+	_, policies, err := e.listPolicies(ctx, dep.Namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	var violations []policy.Violation
+	selected := false
+	for _, p := range policies {
+		if !p.Matches(dep) {
+			continue
+		}
+		selected = true
+		violations = append(violations, p.Evaluate(dep)...)
+	}
+	return violations, selected, nil
+}
+
+// enforce scales a deployment into the replica bounds of the enforcing
+// policies that apply to it, in name order, and updates dep to the result
+func (e *PolicyEnforcer) enforce(ctx context.Context, dep *appsv1.Deployment, policies []*policy.Policy) error {
+	if e.readOnly {
+		return nil
+	}
+	scaled := dep.DeepCopy()
+	var enforcedBy string
+	for _, p := range policies {
+		if !p.Matches(scaled) {
+			continue
+		}
+		if replicas, ok := p.Replicas(scaled); ok {
+			scaled.Spec.Replicas = &replicas
+			enforcedBy = p.Name
+		}
+	}
+	if enforcedBy == "" || reflect.DeepEqual(scaled.Spec.Replicas, dep.Spec.Replicas) {
+		return nil
+	}
+
+	if err := e.Patch(ctx, scaled, client.MergeFrom(dep)); err != nil {
+		return fmt.Errorf("failed to scale deployment %s/%s: %w", dep.Namespace, dep.Name, err)
+	}
+	e.Log.Info("Scaled deployment into policy bounds",
+		"namespace", dep.Namespace,
+		"deployment", dep.Name,
+		"from", getReplicasValue(dep.Spec.Replicas),
+		"to", *scaled.Spec.Replicas,
+		"policy", enforcedBy)
+	if e.Recorder != nil {
+		e.Recorder.Eventf(scaled, corev1.EventTypeNormal, kubernetes.ReasonPolicyEnforced,
+			"Scaled from %d to %d replicas by policy %s", getReplicasValue(dep.Spec.Replicas), *scaled.Spec.Replicas, enforcedBy)
+	}
+	*dep = *scaled
+	return nil
+}
+
+// recordViolations records a warning event on a deployment for each rule it
+// breaks of the policies that apply to it
+func (e *PolicyEnforcer) recordViolations(dep *appsv1.Deployment, policies []*policy.Policy) {
+	if e.Recorder == nil {
+		return
+	}
+	for _, p := range policies {
+		if !p.Matches(dep) {
+			continue
+		}
+		for _, v := range p.Evaluate(dep) {
+			e.Recorder.Eventf(dep, corev1.EventTypeWarning, kubernetes.ReasonPolicyViolation,
+				"Violates %s of policy %s: %s", v.Rule, p.Name, v.Message)
+		}
+	}
+}
+
+// updateStatus reports the violations of a policy in its status, when they
+// changed since the last evaluation
+func (e *PolicyEnforcer) updateStatus(ctx context.Context, obj *unstructured.Unstructured, p *policy.Policy, deployments []*appsv1.Deployment) error {
+	status := policy.NewStatus(p, deployments)
+	previous := p.Status
+	previous.LastEvaluated = ""
+	if previous.Violations == nil {
+		previous.Violations = []policy.Violation{}
+	}
+	if reflect.DeepEqual(status, previous) {
+		return nil
+	}
+
+	status.LastEvaluated = time.Now().UTC().Format(time.RFC3339)
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return fmt.Errorf("failed to convert policy status: %w", err)
+	}
+	obj.Object["status"] = content
+	if err := e.Status().Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to update status of policy %s/%s: %w", p.Namespace, p.Name, err)
+	}
+	p.Status = status
+
+	if status.ViolationCount > 0 {
+		e.Log.Info("Deployment policy violated",
+			"namespace", p.Namespace,
+			"policy", p.Name,
+			"violations", status.ViolationCount)
+	}
+	return nil
+}
+
+// listPolicies returns the policies of a namespace, sorted by name, with
+// their objects
+func (e *PolicyEnforcer) listPolicies(ctx context.Context, namespace string) ([]*unstructured.Unstructured, []*policy.Policy, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(policy.GVR.GroupVersion().WithKind(policy.Kind + "List"))
+	if err := e.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, nil, fmt.Errorf("failed to list deployment policies: %w", err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+
+	objects := make([]*unstructured.Unstructured, 0, len(list.Items))
+	policies := make([]*policy.Policy, 0, len(list.Items))
+	for i := range list.Items {
+		p, err := policy.FromUnstructured(&list.Items[i])
+		if err != nil {
+			e.Log.Error(err, "Skipping invalid deployment policy")
+			continue
+		}
+		objects = append(objects, &list.Items[i])
+		policies = append(policies, p)
+	}
+	return objects, policies, nil
+}
+
+// listDeployments returns the deployments of a namespace
+func (e *PolicyEnforcer) listDeployments(ctx context.Context, namespace string) ([]*appsv1.Deployment, error) {
+	list := &appsv1.DeploymentList{}
+	if err := e.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	deployments := make([]*appsv1.Deployment, 0, len(list.Items))
+	for i := range list.Items {
+		deployments = append(deployments, &list.Items[i])
+	}
+	return deployments, nil
+}
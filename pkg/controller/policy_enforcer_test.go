@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/policy"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestPolicy(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := newPolicyObject()
+	obj.SetNamespace("shop")
+	obj.SetName(name)
+	obj.Object["spec"] = spec
+	return obj
+}
+
+func TestPolicyEnforcer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+
+	restricted := newTestPolicy("restricted", map[string]interface{}{
+		"maxReplicas":    int64(3),
+		"requiredLabels": []interface{}{"team"},
+		"enforce":        true,
+	})
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", Labels: map[string]string{"team": "shop"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(10)},
+	}
+	api := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(restricted, web, api).
+		WithStatusSubresource(restricted).
+		Build()
+	enforcer := &PolicyEnforcer{Client: c, Log: logr.Discard()}
+	reconciler := &DeploymentReconciler{Client: c, Log: logr.Discard(), Scheme: scheme}
+	reconciler.SetPolicyEnforcer(enforcer)
+
+	ctx := context.TODO()
+	if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "shop", Name: "web"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	scaled := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "shop", Name: "web"}, scaled); err != nil {
+		t.Fatal(err)
+	}
+	if *scaled.Spec.Replicas != 3 {
+		t.Errorf("Expected web to be scaled to the maximum of 3, got %d", *scaled.Spec.Replicas)
+	}
+
+	// A changed policy is evaluated against the deployments of its namespace
+	if _, err := enforcer.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "shop", Name: "restricted"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	obj := newPolicyObject()
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "shop", Name: "restricted"}, obj); err != nil {
+		t.Fatal(err)
+	}
+	p, err := policy.FromUnstructured(obj)
+	if err != nil {
+		t.Fatalf("FromUnstructured() error = %v", err)
+	}
+	if p.Status.MatchedDeployments != 2 || p.Status.ViolationCount != 1 || p.Status.LastEvaluated == "" {
+		t.Fatalf("Expected 2 matched deployments and 1 violation, got %+v", p.Status)
+	}
+	if v := p.Status.Violations[0]; v.Deployment != "api" || v.Rule != policy.RuleRequiredLabels {
+		t.Errorf("Expected api to miss the team label, got %+v", v)
+	}
+
+	// Deleted deployments no longer count
+	if err := c.Delete(ctx, api); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "shop", Name: "api"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "shop", Name: "restricted"}, obj); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := policy.FromUnstructured(obj); p.Status.MatchedDeployments != 1 || p.Status.ViolationCount != 0 {
+		t.Errorf("Expected the violation of the deleted api to be cleared, got %+v", p.Status)
+	}
+}
+
+func TestPolicyEnforcerEvents(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+
+	restricted := newTestPolicy("restricted", map[string]interface{}{
+		"maxReplicas":    int64(3),
+		"requiredLabels": []interface{}{"team"},
+		"enforce":        true,
+	})
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(10)},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(restricted, web).
+		WithStatusSubresource(restricted).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	enforcer := &PolicyEnforcer{Client: c, Log: logr.Discard(), Recorder: recorder}
+
+	dep := &appsv1.Deployment{}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(web), dep); err != nil {
+		t.Fatal(err)
+	}
+	if err := enforcer.ReconcileDeployment(context.TODO(), "shop", dep); err != nil {
+		t.Fatalf("ReconcileDeployment() error = %v", err)
+	}
+	close(recorder.Events)
+	var events []string
+	for event := range recorder.Events {
+		events = append(events, event)
+	}
+
+	// The violation is reported for the scaled deployment
+	want := []string{
+		"Normal PolicyEnforced Scaled from 10 to 3 replicas by policy restricted",
+		"Warning PolicyViolation Violates requiredLabels of policy restricted: missing required label team",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Expected events %v, got %v", want, events)
+	}
+}
@@ -0,0 +1,385 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// primarySyncTimeout bounds how long startup waits for the primary cluster to
+// sync before starting the secondary clusters anyway
+const primarySyncTimeout = 2 * time.Minute
+
+// Startup states of a cluster
+const (
+	StartupPending  = "pending"  // waiting for higher priority clusters
+	StartupStarting = "starting" // manager running, deployment cache not synced yet
+	StartupSynced   = "synced"
+	StartupStopped  = "stopped"  // manager exited, e.g. after a startup failure
+	StartupDegraded = "degraded" // manager failed, retried with backoff
+)
+
+// Startup phases of the multi-cluster manager
+const (
+	PhaseStarting = "starting" // readiness gate not met yet
+	PhasePartial  = "partial"  // ready, but some clusters are not synced
+	PhaseFull     = "full"     // every cluster is synced
+)
+
+// prioritizedCluster is implemented by cluster clients that carry a primary
+// designation and startup priority, such as *cluster.ClusterConfig
+type prioritizedCluster interface {
+	IsPrimary() bool
+	GetPriority() int
+}
+
+// startupEntry is a cluster in the startup order
+type startupEntry struct {
+	name     string
+	primary  bool
+	priority int
+}
+
+// newStartupEntry describes a cluster for the startup order
+func newStartupEntry(name string, client cluster.ClusterClient) startupEntry {
+	entry := startupEntry{name: name}
+	if p, ok := client.(prioritizedCluster); ok {
+		entry.primary = p.IsPrimary()
+		entry.priority = p.GetPriority()
+	}
+	return entry
+}
+
+// startupOrder orders clusters primary first, then by descending priority and
+// by name. Without an enabled primary, the first cluster acts as primary.
+func startupOrder(clusters map[string]cluster.ClusterClient) []startupEntry {
+	order := make([]startupEntry, 0, len(clusters))
+	for name, client := range clusters {
+		order = append(order, newStartupEntry(name, client))
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.primary != b.primary {
+			return a.primary
+		}
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		return a.name < b.name
+	})
+
+	for i := range order {
+		order[i].primary = i == 0
+	}
+	return order
+}
+
+// ClusterStartup reports the startup state of a cluster
+type ClusterStartup struct {
+	Name     string `json:"name"`
+	Primary  bool   `json:"primary"`
+	Priority int    `json:"priority"`
+	State    string `json:"state"`
+}
+
+// StartupProgress reports the priority-ordered startup of the cluster
+// managers. The manager is ready once the primary cluster and Quorum
+// secondary clusters are synced.
+type StartupProgress struct {
+	Ready             bool             `json:"ready"`
+	Phase             string           `json:"phase"`
+	Quorum            int              `json:"quorum"`
+	SyncedSecondaries int              `json:"synced_secondaries"`
+	Clusters          []ClusterStartup `json:"clusters"`
+}
+
+// newStartupProgress evaluates the readiness gate over clusters in startup
+// order. The quorum is capped at the number of secondary clusters.
+func newStartupProgress(clusters []ClusterStartup, quorum int) StartupProgress {
+	progress := StartupProgress{
+		Phase:    PhaseStarting,
+		Quorum:   quorum,
+		Clusters: clusters,
+	}
+
+	primarySynced := false
+	synced := 0
+	for _, c := range clusters {
+		if c.State != StartupSynced {
+			continue
+		}
+		synced++
+		if c.Primary {
+			primarySynced = true
+		} else {
+			progress.SyncedSecondaries++
+		}
+	}
+
+	if secondaries := len(clusters) - 1; progress.Quorum > secondaries && secondaries >= 0 {
+		progress.Quorum = secondaries
+	}
+	progress.Ready = primarySynced && progress.SyncedSecondaries >= progress.Quorum
+	switch {
+	case progress.Ready && synced == len(clusters):
+		progress.Phase = PhaseFull
+	case progress.Ready:
+		progress.Phase = PhasePartial
+	}
+	return progress
+}
+
+// SetReadyQuorum sets the number of secondary clusters that must be synced,
+// in addition to the primary, for the manager to be ready
+func (m *MultiClusterManager) SetReadyQuorum(quorum int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.readyQuorum = quorum
+}
+
+// StartupProgress returns the startup state of every cluster, in startup order
+func (m *MultiClusterManager) StartupProgress() StartupProgress {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	clusters := make([]ClusterStartup, 0, len(m.startup))
+	for _, entry := range m.startup {
+		state := StartupPending
+		if cm, exists := m.managers[entry.name]; exists {
+			state = cm.state()
+		}
+		if _, degraded := m.degraded[entry.name]; degraded && state != StartupSynced {
+			state = StartupDegraded
+		}
+		clusters = append(clusters, ClusterStartup{
+			Name:     entry.name,
+			Primary:  entry.primary,
+			Priority: entry.priority,
+			State:    state,
+		})
+	}
+	progress := newStartupProgress(clusters, m.readyQuorum)
+	// A replica assigned no clusters by sharding has nothing to wait for
+	if m.sharder != nil && len(clusters) == 0 {
+		progress.Ready = true
+		progress.Phase = PhaseFull
+	}
+	return progress
+}
+
+// ReadyzHandler serves the startup progress as JSON, with status 200 once the
+// readiness gate is met and 503 before. The phase tells partial from full
+// readiness.
+func (m *MultiClusterManager) ReadyzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		progress := m.StartupProgress()
+		w.Header().Set("Content-Type", "application/json")
+		if progress.Ready {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(progress)
+	})
+}
+
+// waitForSync waits until a cluster's deployment cache is synced, its manager
+// exits, ctx is done or timeout passes, and reports whether it synced
+func (m *MultiClusterManager) waitForSync(ctx context.Context, clusterName string, timeout time.Duration) bool {
+	m.mutex.RLock()
+	cm, exists := m.managers[clusterName]
+	m.mutex.RUnlock()
+	if !exists {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-cm.syncedCh:
+		return true
+	case <-cm.done:
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	return false
+}
+
+// trackSync marks a cluster manager synced once its deployment cache syncs.
+// Registering the informer needs API discovery, so it is retried until the
+// cluster answers or the manager stops.
+func (c *clusterManager) trackSync(ctx context.Context) {
+	err := wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
+		_, err := c.mgr.GetCache().GetInformer(ctx, &appsv1.Deployment{})
+		return err == nil, nil
+	})
+	if err != nil {
+		return
+	}
+	if c.mgr.GetCache().WaitForCacheSync(ctx) {
+		close(c.syncedCh)
+	}
+}
+
+// state returns the startup state of a cluster manager
+func (c *clusterManager) state() string {
+	switch {
+	case !c.running():
+		return StartupStopped
+	case c.synced():
+		return StartupSynced
+	default:
+		return StartupStarting
+	}
+}
+
+// synced reports whether the deployment cache of the cluster has synced
+func (c *clusterManager) synced() bool {
+	select {
+	case <-c.syncedCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// degradedCluster is a cluster whose manager failed, retried with backoff
+type degradedCluster struct {
+	attempts        int
+	lastFailure     string
+	lastFailureTime time.Time
+	nextRetry       time.Time
+}
+
+// startWithRetry starts the manager of a cluster. When that fails, the
+// cluster is marked degraded and retried in the background until its manager
+// syncs, and the error is returned.
+func (m *MultiClusterManager) startWithRetry(clusterName string, client cluster.ClusterClient) error {
+	err := m.startClusterManager(clusterName, client)
+	if err == nil {
+		return nil
+	}
+
+	m.mutex.RLock()
+	existing, exists := m.managers[clusterName]
+	m.mutex.RUnlock()
+	if !exists || !existing.running() {
+		m.scheduleRetry(clusterName, err)
+	}
+	return err
+}
+
+// scheduleRetry marks a cluster degraded after its manager failed with err
+// and retries it in the background, unless it is already being retried
+func (m *MultiClusterManager) scheduleRetry(clusterName string, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.ctx.Err() != nil {
+		return
+	}
+	d, retrying := m.degraded[clusterName]
+	if !retrying {
+		d = &degradedCluster{}
+		m.degraded[clusterName] = d
+	}
+	d.lastFailure = err.Error()
+	d.lastFailureTime = time.Now()
+	metrics.ClusterDegraded.WithLabelValues(clusterName).Set(1)
+	if retrying {
+		return
+	}
+
+	m.wg.Add(1)
+	go m.retryStart(clusterName, d)
+}
+
+// retryStart starts the manager of a degraded cluster again after a delay
+// doubling up to retryMaxBackoff, until it syncs, the cluster is removed or
+// disabled, or the multi-cluster manager stops
+func (m *MultiClusterManager) retryStart(clusterName string, d *degradedCluster) {
+	defer m.wg.Done()
+
+	backoff := m.retryBackoff
+	for {
+		m.mutex.Lock()
+		d.nextRetry = time.Now().Add(backoff)
+		m.mutex.Unlock()
+		select {
+		case <-time.After(backoff):
+		case <-m.ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, m.retryMaxBackoff)
+
+		m.mutex.Lock()
+		if m.degraded[clusterName] != d {
+			// Removed, or replaced by a newer configuration of the cluster
+			m.mutex.Unlock()
+			return
+		}
+		d.attempts++
+		cm, exists := m.managers[clusterName]
+		m.mutex.Unlock()
+
+		client, enabled := m.registry.GetEnabledClusters()[clusterName]
+		if !enabled || (m.sharder != nil && !m.sharder.Owns(clusterName)) {
+			m.clearDegradedCluster(clusterName, d)
+			return
+		}
+
+		if !exists || !cm.running() {
+			m.log.Info("Retrying cluster manager startup", "cluster", clusterName, "attempt", d.attempts)
+			metrics.ClusterStartRetries.WithLabelValues(clusterName).Inc()
+			if err := m.startClusterManager(clusterName, client); err != nil {
+				m.log.Error(err, "Failed to start cluster manager", "cluster", clusterName, "retryIn", backoff)
+				m.mutex.Lock()
+				d.lastFailure = err.Error()
+				d.lastFailureTime = time.Now()
+				m.mutex.Unlock()
+				continue
+			}
+			m.mutex.RLock()
+			cm = m.managers[clusterName]
+			m.mutex.RUnlock()
+		}
+
+		// The cluster recovers once its deployment cache syncs; a manager
+		// that exits first is retried after the next delay
+		select {
+		case <-cm.syncedCh:
+			m.clearDegradedCluster(clusterName, d)
+			m.log.Info("Cluster recovered", "cluster", clusterName, "attempts", d.attempts)
+			return
+		case <-cm.done:
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// clearDegradedCluster clears d, if it is still the degraded state of the
+// cluster
+func (m *MultiClusterManager) clearDegradedCluster(clusterName string, d *degradedCluster) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.degraded[clusterName] == d {
+		m.clearDegraded(clusterName)
+	}
+}
+
+// clearDegraded stops retrying a cluster; callers hold the mutex
+func (m *MultiClusterManager) clearDegraded(clusterName string) {
+	if _, exists := m.degraded[clusterName]; !exists {
+		return
+	}
+	delete(m.degraded, clusterName)
+	metrics.ClusterDegraded.WithLabelValues(clusterName).Set(0)
+}
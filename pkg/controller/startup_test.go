@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+)
+
+func TestStartupOrder(t *testing.T) {
+	clusterWith := func(name string, primary bool, priority int) *cluster.ClusterConfig {
+		c := cluster.NewClusterConfig(name)
+		c.Primary = primary
+		c.Priority = priority
+		return c
+	}
+
+	order := startupOrder(map[string]cluster.ClusterClient{
+		"edge":    clusterWith("edge", false, 0),
+		"prod-eu": clusterWith("prod-eu", false, 10),
+		"prod-us": clusterWith("prod-us", true, 0),
+		"dev":     clusterWith("dev", false, 0),
+	})
+	var names []string
+	for _, entry := range order {
+		names = append(names, entry.name)
+	}
+	want := []string{"prod-us", "prod-eu", "dev", "edge"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Expected startup order %v, got %v", want, names)
+		}
+	}
+
+	// Without a primary, the first cluster in priority order acts as primary
+	order = startupOrder(map[string]cluster.ClusterClient{
+		"edge":    unreachableCluster{name: "edge"},
+		"prod-eu": clusterWith("prod-eu", false, 10),
+	})
+	if order[0].name != "prod-eu" || !order[0].primary || order[1].primary {
+		t.Errorf("Expected prod-eu to act as primary, got %+v", order)
+	}
+}
+
+func TestStartupProgress(t *testing.T) {
+	clusters := func(states ...string) []ClusterStartup {
+		result := make([]ClusterStartup, len(states))
+		for i, state := range states {
+			result[i] = ClusterStartup{Primary: i == 0, State: state}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		clusters []ClusterStartup
+		quorum   int
+		ready    bool
+		phase    string
+	}{
+		{"primary starting", clusters(StartupStarting, StartupSynced), 0, false, PhaseStarting},
+		{"primary only", clusters(StartupSynced, StartupStarting, StartupPending), 0, true, PhasePartial},
+		{"quorum not met", clusters(StartupSynced, StartupSynced, StartupStopped), 2, false, PhaseStarting},
+		{"quorum met", clusters(StartupSynced, StartupSynced, StartupStarting), 1, true, PhasePartial},
+		{"all synced", clusters(StartupSynced, StartupSynced), 1, true, PhaseFull},
+		{"quorum capped", clusters(StartupSynced, StartupSynced), 5, true, PhaseFull},
+		{"no clusters", nil, 0, false, PhaseStarting},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := newStartupProgress(tt.clusters, tt.quorum)
+			if progress.Ready != tt.ready || progress.Phase != tt.phase {
+				t.Errorf("Expected ready=%v phase=%s, got ready=%v phase=%s", tt.ready, tt.phase, progress.Ready, progress.Phase)
+			}
+		})
+	}
+}
+
+func TestReadyzHandler(t *testing.T) {
+	m := NewMultiClusterManager(cluster.NewInMemoryClusterRegistry(), config.NamespaceScope{}, 1)
+	defer m.cancel()
+	m.startup = []startupEntry{{name: "prod", primary: true}, {name: "edge"}}
+
+	if err := m.AddCluster("prod", unreachableCluster{name: "prod"}); err != nil {
+		t.Fatalf("AddCluster() error = %v", err)
+	}
+	defer func() { _ = m.RemoveCluster("prod") }()
+
+	rec := httptest.NewRecorder()
+	m.ReadyzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 before the primary syncs, got %d", rec.Code)
+	}
+
+	var progress StartupProgress
+	if err := json.Unmarshal(rec.Body.Bytes(), &progress); err != nil {
+		t.Fatalf("Invalid readyz body: %v", err)
+	}
+	if len(progress.Clusters) != 2 || progress.Clusters[0].State != StartupStarting || progress.Clusters[1].State != StartupPending {
+		t.Errorf("Expected prod starting and edge pending, got %+v", progress.Clusters)
+	}
+}
+
+func TestStartRetry(t *testing.T) {
+	dir := t.TempDir()
+	broken, edge := filepath.Join(dir, "broken"), filepath.Join(dir, "edge")
+	writeUnreachableKubeconfig(t, edge)
+
+	registry := cluster.NewInMemoryClusterRegistry()
+	for name, kubeconfig := range map[string]string{"a-broken": broken, "b-edge": edge} {
+		if err := registry.AddCluster(name, cluster.NewClusterConfigFrom(config.ClusterConfig{Name: name, KubeConfig: kubeconfig, Enabled: true})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := NewMultiClusterManager(registry, config.NamespaceScope{}, 1)
+	m.retryBackoff, m.retryMaxBackoff = 10*time.Millisecond, 40*time.Millisecond
+
+	// The cluster without a kubeconfig doesn't keep the other from starting
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Start() error = %v", err)
+		}
+	}()
+
+	waitFor := func(what string, cond func(map[string]ClusterStatus) bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !cond(m.GetClusterStatus()) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %s, got %+v", what, m.GetClusterStatus())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitFor("a-broken to be retried", func(status map[string]ClusterStatus) bool {
+		return status["a-broken"].Degraded && status["a-broken"].StartAttempts >= 2 && !status["b-edge"].StartTime.IsZero()
+	})
+	if status := m.GetClusterStatus()["a-broken"]; status.LastFailure == "" || status.NextRetry.IsZero() {
+		t.Errorf("Expected the failure and next retry of a-broken, got %+v", status)
+	}
+	if progress := m.StartupProgress(); progress.Clusters[0].State != StartupDegraded || progress.Clusters[1].State != StartupStarting {
+		t.Errorf("Expected a-broken degraded and b-edge starting, got %+v", progress.Clusters)
+	}
+	if got := testutil.ToFloat64(metrics.ClusterDegraded.WithLabelValues("a-broken")); got != 1 {
+		t.Errorf("Expected a-broken reported degraded, got %v", got)
+	}
+
+	// Once its kubeconfig appears the manager starts, and removing the
+	// cluster stops the retries
+	writeUnreachableKubeconfig(t, broken)
+	waitFor("a-broken to start", func(status map[string]ClusterStatus) bool {
+		return !status["a-broken"].StartTime.IsZero()
+	})
+	if err := m.RemoveCluster("a-broken"); err != nil {
+		t.Fatalf("RemoveCluster() error = %v", err)
+	}
+	if _, exists := m.GetClusterStatus()["a-broken"]; exists {
+		t.Error("Expected a-broken to be gone after removal")
+	}
+	if got := testutil.ToFloat64(metrics.ClusterDegraded.WithLabelValues("a-broken")); got != 0 {
+		t.Errorf("Expected a-broken no longer degraded, got %v", got)
+	}
+}
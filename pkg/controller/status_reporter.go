@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/gitops"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// K6sControllerGVR identifies the cluster-scoped K6sController resource
+var K6sControllerGVR = schema.GroupVersionResource{
+	Group:    "k6s.io",
+	Version:  "v1alpha1",
+	Resource: "k6scontrollers",
+}
+
+// K6sControllerStatus is the status reported on the K6sController object
+type K6sControllerStatus struct {
+	Version       string           `json:"version"`
+	Mode          string           `json:"mode"`
+	Features      []string         `json:"features"`
+	Healthy       bool             `json:"healthy"`
+	Clusters      []ClusterHealth  `json:"clusters"`
+	LastReconcile ReconcileSummary `json:"lastReconcile"`
+	ObservedTime  string           `json:"observedTime"`
+
+	// Sync of the GitOps repository, when GitOps source mode is enabled
+	GitOps *gitops.Status `json:"gitops,omitempty"`
+
+	// Holder of the leader election lease, when leader election is enabled
+	Leader *LeaderStatus `json:"leader,omitempty"`
+}
+
+// ClusterHealth reports the health of a single watched cluster and the
+// restarts of its manager
+type ClusterHealth struct {
+	Name        string `json:"name"`
+	Ready       bool   `json:"ready"`
+	StartTime   string `json:"startTime,omitempty"`
+	Restarts    int    `json:"restarts"`
+	LastFailure string `json:"lastFailure,omitempty"`
+
+	// Whether the reporting replica leads the cluster, with per-cluster
+	// leader election
+	Leader *bool `json:"leader,omitempty"`
+
+	// Whether the manager of the cluster failed and is being retried
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+// StatusReporter maintains the K6sController status object
+type StatusReporter struct {
+	client   dynamic.Interface
+	name     string
+	interval time.Duration
+	collect  func() K6sControllerStatus
+	log      *logger.Logger
+}
+
+// NewStatusReporter creates a new status reporter writing the object with the given name
+func NewStatusReporter(client dynamic.Interface, name string, interval time.Duration, collect func() K6sControllerStatus) *StatusReporter {
+	return &StatusReporter{
+		client:   client,
+		name:     name,
+		interval: interval,
+		collect:  collect,
+		log:      logger.WithComponent("status-reporter"),
+	}
+}
+
+// Start reports status periodically until the context is cancelled.
+// It implements manager.Runnable, so it only runs on the elected leader.
+func (r *StatusReporter) Start(ctx context.Context) error {
+	r.log.Info("Starting controller status reporting", map[string]interface{}{
+		"name":     r.name,
+		"interval": r.interval.String(),
+	})
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Report(ctx); err != nil {
+			if apierrors.IsNotFound(err) {
+				r.log.Warn("K6sController resource is not installed, status reporting disabled", map[string]interface{}{
+					"resource": K6sControllerGVR.String(),
+				})
+				return nil
+			}
+			r.log.Error("Failed to report controller status", err, map[string]interface{}{
+				"name": r.name,
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report writes the current status, creating the object if it doesn't exist
+func (r *StatusReporter) Report(ctx context.Context) error {
+	resource := r.client.Resource(K6sControllerGVR)
+
+	obj, err := resource.Get(ctx, r.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		obj, err = resource.Create(ctx, r.newObject(), metav1.CreateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	status := r.collect()
+	status.ObservedTime = time.Now().UTC().Format(time.RFC3339)
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return fmt.Errorf("failed to convert status: %w", err)
+	}
+	obj.Object["status"] = content
+
+	if _, err := resource.UpdateStatus(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update status of %s: %w", r.name, err)
+	}
+	return nil
+}
+
+// newObject returns an empty K6sController object
+func (r *StatusReporter) newObject() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(K6sControllerGVR.GroupVersion().String())
+	obj.SetKind("K6sController")
+	obj.SetName(r.name)
+	obj.Object["spec"] = map[string]interface{}{}
+	return obj
+}
+
+// clusterHealth converts cluster status into a sorted health list,
+// reporting whether every cluster is ready
+func clusterHealth(status map[string]ClusterStatus) ([]ClusterHealth, bool) {
+	healthy := len(status) > 0
+	clusters := make([]ClusterHealth, 0, len(status))
+	for name, s := range status {
+		health := ClusterHealth{Name: name, Ready: s.Ready, Restarts: s.Restarts, LastFailure: s.LastFailure, Leader: s.Leader, Degraded: s.Degraded}
+		if !s.StartTime.IsZero() {
+			health.StartTime = s.StartTime.UTC().Format(time.RFC3339)
+		}
+		clusters = append(clusters, health)
+		if !s.Ready {
+			healthy = false
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Name < clusters[j].Name
+	})
+	return clusters, healthy
+}
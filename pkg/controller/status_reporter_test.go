@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestStatusReporterReport(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		K6sControllerGVR: "K6sControllerList",
+	})
+
+	reporter := NewStatusReporter(client, "k6s", time.Minute, func() K6sControllerStatus {
+		return K6sControllerStatus{
+			Version:  "v1.2.3",
+			Mode:     "single",
+			Features: []string{"leader-election"},
+			Healthy:  true,
+			Clusters: []ClusterHealth{{Name: "default", Ready: true}},
+			LastReconcile: ReconcileSummary{
+				Total:  3,
+				Errors: 1,
+			},
+		}
+	})
+
+	// First report creates the object, second one updates it
+	for i := 0; i < 2; i++ {
+		if err := reporter.Report(context.TODO()); err != nil {
+			t.Fatalf("Report() error = %v", err)
+		}
+	}
+
+	obj, err := client.Resource(K6sControllerGVR).Get(context.TODO(), "k6s", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get K6sController: %v", err)
+	}
+
+	version, _, _ := unstructured.NestedString(obj.Object, "status", "version")
+	if version != "v1.2.3" {
+		t.Errorf("Expected version v1.2.3, got %q", version)
+	}
+	healthy, _, _ := unstructured.NestedBool(obj.Object, "status", "healthy")
+	if !healthy {
+		t.Error("Expected healthy status")
+	}
+	total, _, _ := unstructured.NestedInt64(obj.Object, "status", "lastReconcile", "total")
+	if total != 3 {
+		t.Errorf("Expected 3 reconciles, got %d", total)
+	}
+	if observed, _, _ := unstructured.NestedString(obj.Object, "status", "observedTime"); observed == "" {
+		t.Error("Expected observedTime to be set")
+	}
+}
+
+func TestMergeReconcileStats(t *testing.T) {
+	older := &ReconcileStats{}
+	newer := &ReconcileStats{}
+
+	older.Record(time.Now().Add(-time.Minute), errors.New("boom"))
+	newer.Record(time.Now(), nil)
+	newer.Record(time.Now(), nil)
+
+	merged := mergeReconcileStats(older, newer, nil)
+	if merged.Total != 3 {
+		t.Errorf("Expected 3 reconciles, got %d", merged.Total)
+	}
+	if merged.Errors != 1 || merged.LastError != "boom" {
+		t.Errorf("Expected 1 error 'boom', got %d %q", merged.Errors, merged.LastError)
+	}
+	if merged.LastTime != newer.Summary().LastTime {
+		t.Errorf("Expected last time from newest reconciler, got %s", merged.LastTime)
+	}
+}
+
+func TestClusterHealth(t *testing.T) {
+	started := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	clusters, healthy := clusterHealth(map[string]ClusterStatus{
+		"b": {Name: "b", Ready: true, StartTime: started},
+		"a": {Name: "a", Ready: false, Restarts: 2, LastFailure: "unauthorized"},
+	})
+	if healthy {
+		t.Error("Expected unhealthy when a cluster is not ready")
+	}
+	if len(clusters) != 2 || clusters[0].Name != "a" {
+		t.Errorf("Expected clusters sorted by name, got %+v", clusters)
+	}
+	if clusters[0].Restarts != 2 || clusters[0].LastFailure != "unauthorized" || clusters[0].StartTime != "" {
+		t.Errorf("Expected the restarts and last failure of a, got %+v", clusters[0])
+	}
+	if clusters[1].StartTime != "2025-03-01T12:00:00Z" {
+		t.Errorf("Expected the start time of b, got %q", clusters[1].StartTime)
+	}
+
+	if _, healthy := clusterHealth(nil); healthy {
+		t.Error("Expected unhealthy with no clusters")
+	}
+}
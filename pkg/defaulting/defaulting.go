@@ -0,0 +1,207 @@
+// Package defaulting implements the mutating admission webhook that injects
+// default resources, labels and topology spread constraints into
+// deployments.
+package defaulting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Path is the path the webhook is served at
+const Path = "/mutate-apps-v1-deployment"
+
+// SkipAnnotation opts a deployment out of defaulting when set to "true"
+const SkipAnnotation = "k6s.io/skip-defaults"
+
+// Defaulter injects the configured defaults into deployments. It implements
+// admission.Handler.
+type Defaulter struct {
+	log *logger.Logger
+
+	mu  sync.RWMutex
+	cfg config.WebhookConfig
+}
+
+// New creates a defaulter injecting the defaults of cfg
+func New(cfg config.WebhookConfig) *Defaulter {
+	return &Defaulter{
+		cfg: cfg,
+		log: logger.WithComponent("webhook"),
+	}
+}
+
+// SetConfig replaces the namespaces and defaults, taking effect at the next
+// admission request
+func (d *Defaulter) SetConfig(cfg config.WebhookConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cfg = cfg
+}
+
+// Selects reports whether the deployments of a namespace are mutated
+func (d *Defaulter) Selects(namespace string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if len(d.cfg.Namespaces) == 0 {
+		return true
+	}
+	for _, selected := range d.cfg.Namespaces {
+		if selected == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle patches an admitted deployment with the defaults it lacks. Other
+// objects, deployments of namespaces that are not selected and deployments
+// annotated with SkipAnnotation are admitted unchanged.
+func (d *Defaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Kind.Group != "apps" || req.Kind.Kind != "Deployment" {
+		return admission.Allowed("not a deployment")
+	}
+	if !d.Selects(req.Namespace) {
+		return admission.Allowed("namespace not selected")
+	}
+
+	dep := &appsv1.Deployment{}
+	if err := json.Unmarshal(req.Object.Raw, dep); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if dep.Annotations[SkipAnnotation] == "true" {
+		return admission.Allowed("defaults skipped by annotation")
+	}
+
+	defaults := d.Default(dep)
+	if len(defaults) == 0 {
+		return admission.Allowed("no defaults to inject")
+	}
+	mutated, err := json.Marshal(dep)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	name := dep.Name
+	if name == "" {
+		name = dep.GenerateName
+	}
+	d.log.Debug("Injected deployment defaults", map[string]interface{}{
+		"namespace":  req.Namespace,
+		"deployment": name,
+		"operation":  string(req.Operation),
+		"defaults":   defaults,
+	})
+	return admission.PatchResponseFromRaw(req.Object.Raw, mutated)
+}
+
+// Default injects the defaults a deployment lacks and returns what was
+// injected, such as "label team" or "web memory limit"
+func (d *Defaulter) Default(dep *appsv1.Deployment) []string {
+	d.mu.RLock()
+	cfg := d.cfg
+	d.mu.RUnlock()
+
+	var defaults []string
+	for _, key := range sortedKeys(cfg.Labels) {
+		if _, ok := dep.Labels[key]; !ok {
+			if dep.Labels == nil {
+				dep.Labels = make(map[string]string)
+			}
+			dep.Labels[key] = cfg.Labels[key]
+			defaults = append(defaults, "label "+key)
+		}
+		if _, ok := dep.Spec.Template.Labels[key]; !ok {
+			if dep.Spec.Template.Labels == nil {
+				dep.Spec.Template.Labels = make(map[string]string)
+			}
+			dep.Spec.Template.Labels[key] = cfg.Labels[key]
+			defaults = append(defaults, "pod label "+key)
+		}
+	}
+
+	podSpec := &dep.Spec.Template.Spec
+	for i := range podSpec.InitContainers {
+		defaults = append(defaults, defaultResources(&podSpec.InitContainers[i], cfg.Resources)...)
+	}
+	for i := range podSpec.Containers {
+		defaults = append(defaults, defaultResources(&podSpec.Containers[i], cfg.Resources)...)
+	}
+
+	if spread := cfg.TopologySpread; spread.Enabled && len(podSpec.TopologySpreadConstraints) == 0 && dep.Spec.Selector != nil {
+		podSpec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+			MaxSkew:           spread.MaxSkew,
+			TopologyKey:       spread.TopologyKey,
+			WhenUnsatisfiable: corev1.UnsatisfiableConstraintAction(spread.WhenUnsatisfiable),
+			LabelSelector:     dep.Spec.Selector.DeepCopy(),
+		}}
+		defaults = append(defaults, "topology spread "+spread.TopologyKey)
+	}
+	return defaults
+}
+
+// defaultResources sets the requests and limits a container lacks. A default
+// request above the container's limit is lowered to the limit, and a
+// default limit below the container's request is not set.
+func defaultResources(c *corev1.Container, resources config.DefaultResourcesConfig) []string {
+	var defaults []string
+	for _, name := range sortedKeys(resources.Requests) {
+		resourceName := corev1.ResourceName(name)
+		if _, ok := c.Resources.Requests[resourceName]; ok {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(resources.Requests[name])
+		if err != nil {
+			continue
+		}
+		if limit, ok := c.Resources.Limits[resourceName]; ok && quantity.Cmp(limit) > 0 {
+			quantity = limit
+		}
+		if c.Resources.Requests == nil {
+			c.Resources.Requests = make(corev1.ResourceList)
+		}
+		c.Resources.Requests[resourceName] = quantity
+		defaults = append(defaults, fmt.Sprintf("%s %s request", c.Name, name))
+	}
+
+	for _, name := range sortedKeys(resources.Limits) {
+		resourceName := corev1.ResourceName(name)
+		if _, ok := c.Resources.Limits[resourceName]; ok {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(resources.Limits[name])
+		if err != nil {
+			continue
+		}
+		if request, ok := c.Resources.Requests[resourceName]; ok && request.Cmp(quantity) > 0 {
+			continue
+		}
+		if c.Resources.Limits == nil {
+			c.Resources.Limits = make(corev1.ResourceList)
+		}
+		c.Resources.Limits[resourceName] = quantity
+		defaults = append(defaults, fmt.Sprintf("%s %s limit", c.Name, name))
+	}
+	return defaults
+}
+
+// sortedKeys returns the keys of a map in order, for stable patches
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,119 @@
+package defaulting
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func testConfig() config.WebhookConfig {
+	return config.WebhookConfig{
+		Enabled:    true,
+		Namespaces: []string{"shop"},
+		Resources: config.DefaultResourcesConfig{
+			Requests: map[string]string{"cpu": "100m", "memory": "128Mi"},
+			Limits:   map[string]string{"memory": "256Mi"},
+		},
+		Labels: map[string]string{"team": "platform"},
+		TopologySpread: config.TopologySpreadConfig{
+			Enabled:           true,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			MaxSkew:           1,
+			WhenUnsatisfiable: "ScheduleAnyway",
+		},
+	}
+}
+
+func newDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", Labels: map[string]string{"team": "shop"}},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:  "web",
+					Image: "nginx",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+					},
+				}}},
+			},
+		},
+	}
+}
+
+func TestDefault(t *testing.T) {
+	dep := newDeployment()
+	defaults := New(testConfig()).Default(dep)
+	if len(defaults) != 5 {
+		t.Errorf("Expected 5 defaults, got %v", defaults)
+	}
+
+	if dep.Labels["team"] != "shop" || dep.Spec.Template.Labels["team"] != "platform" {
+		t.Errorf("Expected the team label to be kept and added to the pod template, got %v and %v", dep.Labels, dep.Spec.Template.Labels)
+	}
+	resources := dep.Spec.Template.Spec.Containers[0].Resources
+	if cpu := resources.Requests[corev1.ResourceCPU]; cpu.String() != "50m" {
+		t.Errorf("Expected the cpu request to be lowered to the 50m limit, got %s", cpu.String())
+	}
+	if memory := resources.Limits[corev1.ResourceMemory]; memory.String() != "256Mi" {
+		t.Errorf("Expected a 256Mi memory limit, got %s", memory.String())
+	}
+	constraints := dep.Spec.Template.Spec.TopologySpreadConstraints
+	if len(constraints) != 1 || constraints[0].LabelSelector.MatchLabels["app"] != "web" {
+		t.Errorf("Expected a zone spread constraint selecting the deployment's pods, got %+v", constraints)
+	}
+
+	if again := New(testConfig()).Default(dep); len(again) != 0 {
+		t.Errorf("Expected defaulting to be idempotent, got %v", again)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	defaulter := New(testConfig())
+	request := func(dep *appsv1.Deployment) admission.Request {
+		raw, _ := json.Marshal(dep)
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Namespace: dep.Namespace,
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+
+	resp := defaulter.Handle(context.Background(), request(newDeployment()))
+	if !resp.Allowed || len(resp.Patches) == 0 {
+		t.Fatalf("Expected an allowed response with patches, got %+v", resp)
+	}
+	var paths []string
+	for _, patch := range resp.Patches {
+		paths = append(paths, patch.Path)
+	}
+	if joined := strings.Join(paths, " "); !strings.Contains(joined, "/spec/template/spec/topologySpreadConstraints") {
+		t.Errorf("Expected a topology spread patch, got %v", paths)
+	}
+
+	other := newDeployment()
+	other.Namespace = "kube-system"
+	if resp := defaulter.Handle(context.Background(), request(other)); !resp.Allowed || len(resp.Patches) != 0 {
+		t.Errorf("Expected deployments of other namespaces to be admitted unchanged, got %+v", resp)
+	}
+
+	skipped := newDeployment()
+	skipped.Annotations = map[string]string{SkipAnnotation: "true"}
+	if resp := defaulter.Handle(context.Background(), request(skipped)); !resp.Allowed || len(resp.Patches) != 0 {
+		t.Errorf("Expected annotated deployments to be admitted unchanged, got %+v", resp)
+	}
+}
@@ -0,0 +1,337 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// listTimeout bounds listing the deployments of one cluster
+const listTimeout = 30 * time.Second
+
+// DeploymentHashes reports the spec hash of a deployment in each cluster of a
+// group that has it
+type DeploymentHashes struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Hashes    map[string]string `json:"hashes"`
+	Drifted   bool              `json:"drifted"`
+
+	// UIDs of the deployment in each cluster, for recording events
+	uids map[string]types.UID
+}
+
+// GroupReport compares the deployments of a group of clusters. Clusters that
+// could not be listed are reported in Errors and left out of the comparison.
+type GroupReport struct {
+	Name        string             `json:"name"`
+	Clusters    []string           `json:"clusters"`
+	Deployments []DeploymentHashes `json:"deployments"`
+	Drifted     int                `json:"drifted"`
+	Errors      map[string]string  `json:"errors,omitempty"`
+}
+
+// Report is the result of comparing every drift group
+type Report struct {
+	CheckedAt time.Time     `json:"checkedAt"`
+	Groups    []GroupReport `json:"groups"`
+	Drifted   int           `json:"drifted"`
+}
+
+// Detector periodically compares deployment specs across the clusters of each
+// drift group and raises an alarm, logged as a warning, when a deployment
+// starts to differ or is missing from a cluster of its group
+type Detector struct {
+	registry cluster.ClusterRegistry
+	log      *logger.Logger
+
+	mu      sync.RWMutex
+	cfg     config.DriftConfig
+	report  Report
+	checked bool
+
+	// Deployments alarmed on, keyed by group/namespace/name
+	alarms map[string]bool
+
+	// Records an event on each copy of a deployment that starts to drift,
+	// nil to only log
+	recorders kubernetes.ClusterRecorders
+}
+
+// NewDetector creates a detector comparing clusters of registry
+func NewDetector(registry cluster.ClusterRegistry, cfg config.DriftConfig) *Detector {
+	return &Detector{
+		registry: registry,
+		cfg:      cfg,
+		alarms:   make(map[string]bool),
+		log:      logger.WithComponent("drift-detector"),
+	}
+}
+
+// SetConfig replaces the drift groups and interval, taking effect at the next check
+func (d *Detector) SetConfig(cfg config.DriftConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cfg = cfg
+}
+
+// SetEventRecorders records a warning event on each copy of a deployment that
+// starts to drift, in its cluster. It must be called before Start.
+func (d *Detector) SetEventRecorders(recorders kubernetes.ClusterRecorders) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.recorders = recorders
+}
+
+// Report returns the result of the last check, and false before the first one
+func (d *Detector) Report() (Report, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.report, d.checked
+}
+
+// Start checks for drift every interval until ctx is cancelled
+func (d *Detector) Start(ctx context.Context) {
+	d.mu.RLock()
+	d.log.Info("Starting drift detection", map[string]interface{}{
+		"groups":   len(d.cfg.Groups),
+		"interval": d.cfg.Interval.String(),
+	})
+	d.mu.RUnlock()
+
+	for {
+		d.Check(ctx)
+
+		d.mu.RLock()
+		timer := time.NewTimer(d.cfg.Interval)
+		d.mu.RUnlock()
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Check compares every drift group once, raises alarms for new drift and
+// stores the report
+func (d *Detector) Check(ctx context.Context) Report {
+	d.mu.RLock()
+	groups := d.cfg.Groups
+	d.mu.RUnlock()
+
+	report := Report{CheckedAt: time.Now().UTC(), Groups: make([]GroupReport, 0, len(groups))}
+	for _, group := range groups {
+		groupReport := d.compare(ctx, group)
+		report.Drifted += groupReport.Drifted
+		report.Groups = append(report.Groups, groupReport)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.raiseAlarms(report)
+	d.report = report
+	d.checked = true
+	return report
+}
+
+// compare lists the deployments of each cluster of a group and compares their hashes
+func (d *Detector) compare(ctx context.Context, group config.DriftGroupConfig) GroupReport {
+	report := GroupReport{
+		Name:        group.Name,
+		Clusters:    uniqueSorted(group.Clusters),
+		Deployments: []DeploymentHashes{},
+	}
+
+	// Hashes by namespace/name, then by cluster
+	deployments := make(map[string]*DeploymentHashes)
+	listed := 0
+	for _, clusterName := range report.Clusters {
+		hashes, err := d.listHashes(ctx, clusterName, group.Namespaces)
+		if err != nil {
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[clusterName] = err.Error()
+			d.log.Warn("Failed to list deployments for drift detection", map[string]interface{}{
+				"group":   group.Name,
+				"cluster": clusterName,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		listed++
+
+		for key, listed := range hashes {
+			dep, exists := deployments[key]
+			if !exists {
+				namespace, name, _ := strings.Cut(key, "/")
+				dep = &DeploymentHashes{Namespace: namespace, Name: name, Hashes: make(map[string]string), uids: make(map[string]types.UID)}
+				deployments[key] = dep
+			}
+			dep.Hashes[clusterName] = listed.hash
+			dep.uids[clusterName] = listed.uid
+		}
+	}
+
+	for _, dep := range deployments {
+		dep.Drifted = drifted(dep.Hashes, listed)
+		if dep.Drifted {
+			report.Drifted++
+		}
+		report.Deployments = append(report.Deployments, *dep)
+	}
+	sort.Slice(report.Deployments, func(i, j int) bool {
+		a, b := report.Deployments[i], report.Deployments[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return report
+}
+
+// listedDeployment is the spec hash and UID of a listed deployment
+type listedDeployment struct {
+	hash string
+	uid  types.UID
+}
+
+// listHashes returns the spec hashes of the deployments of a cluster, keyed by namespace/name
+func (d *Detector) listHashes(ctx context.Context, clusterName string, namespaces []string) (map[string]listedDeployment, error) {
+	client, exists := d.registry.GetCluster(clusterName)
+	if !exists {
+		return nil, fmt.Errorf("cluster %s is not configured", clusterName)
+	}
+	clientset, err := client.GetKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	hashes := make(map[string]listedDeployment)
+	for _, namespace := range namespaces {
+		list, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments: %w", err)
+		}
+		for i := range list.Items {
+			dep := &list.Items[i]
+			hashes[dep.Namespace+"/"+dep.Name] = listedDeployment{hash: SpecHash(dep), uid: dep.UID}
+		}
+	}
+	return hashes, nil
+}
+
+// raiseAlarms logs deployments that started or stopped drifting since the
+// previous check. The caller must hold d.mu.
+func (d *Detector) raiseAlarms(report Report) {
+	current := make(map[string]bool)
+	for _, group := range report.Groups {
+		for _, dep := range group.Deployments {
+			key := group.Name + "/" + dep.Namespace + "/" + dep.Name
+			if !dep.Drifted {
+				continue
+			}
+			current[key] = true
+			if !d.alarms[key] {
+				d.log.Warn("Configuration drift detected", map[string]interface{}{
+					"group":      group.Name,
+					"namespace":  dep.Namespace,
+					"deployment": dep.Name,
+					"hashes":     dep.Hashes,
+				})
+				d.recordDrift(group, dep)
+			}
+		}
+	}
+
+	for key := range d.alarms {
+		if !current[key] {
+			d.log.Info("Configuration drift resolved", map[string]interface{}{
+				"deployment": key,
+			})
+		}
+	}
+	d.alarms = current
+}
+
+// recordDrift records a warning event on each copy of a deployment that
+// started to drift
+func (d *Detector) recordDrift(group GroupReport, dep DeploymentHashes) {
+	if d.recorders == nil {
+		return
+	}
+	var missing []string
+	for _, clusterName := range group.Clusters {
+		if _, listed := dep.Hashes[clusterName]; !listed && group.Errors[clusterName] == "" {
+			missing = append(missing, clusterName)
+		}
+	}
+	message := fmt.Sprintf("Spec differs across the clusters of drift group %s", group.Name)
+	if len(missing) > 0 {
+		message = fmt.Sprintf("Missing from clusters %s of drift group %s", strings.Join(missing, ", "), group.Name)
+	}
+
+	for clusterName, uid := range dep.uids {
+		recorder := d.recorders.Recorder(clusterName)
+		if recorder == nil {
+			continue
+		}
+		ref := &corev1.ObjectReference{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+			Namespace:  dep.Namespace,
+			Name:       dep.Name,
+			UID:        uid,
+		}
+		recorder.Event(ref, corev1.EventTypeWarning, kubernetes.ReasonClusterDrift, message)
+	}
+}
+
+// drifted reports whether a deployment is missing from one of the listed
+// clusters or has different specs in two of them
+func drifted(hashes map[string]string, listed int) bool {
+	if len(hashes) < listed {
+		return true
+	}
+	var first string
+	for _, hash := range hashes {
+		if first == "" {
+			first = hash
+		} else if hash != first {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueSorted returns the distinct names, sorted
+func uniqueSorted(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,127 @@
+package drift
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	k6stesting "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/testing"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDetectorCheck(t *testing.T) {
+	euDeployments := []runtime.Object{
+		newDeployment("shop", "web", "nginx:1.25", 3),
+		newDeployment("shop", "api", "api:2.0", 2),
+		newDeployment("shop", "worker", "worker:1.0", 1),
+	}
+	usDeployments := []runtime.Object{
+		newDeployment("shop", "web", "nginx:1.25", 6),
+		newDeployment("shop", "api", "api:2.1", 2),
+		newDeployment("other", "db", "postgres:16", 1),
+	}
+	registry := k6stesting.FakeRegistry{
+		"prod-eu": k6stesting.NewFakeCluster("prod-eu", euDeployments...),
+		"prod-us": k6stesting.NewFakeCluster("prod-us", usDeployments...),
+	}
+
+	detector := NewDetector(registry, config.DriftConfig{
+		Groups: []config.DriftGroupConfig{
+			{Name: "prod", Clusters: []string{"prod-us", "prod-eu"}, Namespaces: []string{"shop"}},
+			{Name: "dr", Clusters: []string{"prod-eu", "prod-dr"}},
+		},
+	})
+	if _, checked := detector.Report(); checked {
+		t.Error("Expected no report before the first check")
+	}
+
+	report := detector.Check(context.Background())
+	if len(report.Groups) != 2 {
+		t.Fatalf("Expected 2 group reports, got %d", len(report.Groups))
+	}
+
+	prod := report.Groups[0]
+	if prod.Clusters[0] != "prod-eu" || len(prod.Errors) != 0 {
+		t.Errorf("Expected sorted clusters and no errors, got %v and %v", prod.Clusters, prod.Errors)
+	}
+	want := map[string]bool{"api": true, "web": false, "worker": true}
+	if len(prod.Deployments) != len(want) {
+		t.Fatalf("Expected %d deployments in namespace shop, got %+v", len(want), prod.Deployments)
+	}
+	for _, dep := range prod.Deployments {
+		if dep.Drifted != want[dep.Name] {
+			t.Errorf("Expected %s drifted=%v, got %v (hashes %v)", dep.Name, want[dep.Name], dep.Drifted, dep.Hashes)
+		}
+	}
+	if prod.Drifted != 2 {
+		t.Errorf("Expected 2 drifted deployments, got %d", prod.Drifted)
+	}
+
+	// A cluster that is not configured is reported and left out of the comparison
+	dr := report.Groups[1]
+	if dr.Errors["prod-dr"] == "" || dr.Drifted != 0 {
+		t.Errorf("Expected an error for prod-dr and no drift, got %+v", dr)
+	}
+	if report.Drifted != 2 {
+		t.Errorf("Expected 2 drifted deployments overall, got %d", report.Drifted)
+	}
+
+	if latest, checked := detector.Report(); !checked || latest.Drifted != 2 {
+		t.Errorf("Expected the report to be stored, got %+v", latest)
+	}
+	if len(detector.alarms) != 2 || !detector.alarms["prod/shop/api"] {
+		t.Errorf("Expected alarms for the drifted deployments, got %v", detector.alarms)
+	}
+}
+
+// fakeRecorders records the events of each cluster
+type fakeRecorders map[string]*record.FakeRecorder
+
+func (r fakeRecorders) Recorder(cluster string) record.EventRecorder {
+	if recorder, ok := r[cluster]; ok {
+		return recorder
+	}
+	return nil
+}
+
+func TestDetectorEvents(t *testing.T) {
+	registry := k6stesting.FakeRegistry{
+		"prod-eu": k6stesting.NewFakeCluster("prod-eu",
+			newDeployment("shop", "api", "api:2.0", 2),
+			newDeployment("shop", "worker", "worker:1.0", 1),
+		),
+		"prod-us": k6stesting.NewFakeCluster("prod-us", newDeployment("shop", "api", "api:2.1", 2)),
+	}
+	recorders := fakeRecorders{
+		"prod-eu": record.NewFakeRecorder(10),
+		"prod-us": record.NewFakeRecorder(10),
+	}
+	detector := NewDetector(registry, config.DriftConfig{
+		Groups: []config.DriftGroupConfig{{Name: "prod", Clusters: []string{"prod-eu", "prod-us"}}},
+	})
+	detector.SetEventRecorders(recorders)
+
+	// Events are recorded when drift starts, not at every check
+	detector.Check(context.Background())
+	detector.Check(context.Background())
+	events := map[string][]string{}
+	for name, recorder := range recorders {
+		close(recorder.Events)
+		for event := range recorder.Events {
+			events[name] = append(events[name], event)
+		}
+	}
+	if len(events["prod-eu"]) != 2 || len(events["prod-us"]) != 1 {
+		t.Fatalf("Expected 2 events in prod-eu and 1 in prod-us, got %v", events)
+	}
+	want := "Warning ClusterDriftDetected Spec differs across the clusters of drift group prod"
+	if events["prod-us"][0] != want {
+		t.Errorf("Expected %q, got %q", want, events["prod-us"][0])
+	}
+	missing := "Warning ClusterDriftDetected Missing from clusters prod-us of drift group prod"
+	if !slices.Contains(events["prod-eu"], missing) || !slices.Contains(events["prod-eu"], want) {
+		t.Errorf("Expected %q and %q in prod-eu, got %v", want, missing, events["prod-eu"])
+	}
+}
@@ -0,0 +1,33 @@
+// Package drift detects configuration drift between clusters that are meant
+// to run identical deployments, by comparing normalized hashes of their specs,
+// and between deployments and the manifests declared for them.
+package drift
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// restartedAtAnnotation is set on the pod template by "kubectl rollout restart"
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// SpecHash returns a hash of a deployment's spec that is equal for deployments
+// configured alike. The replica count and rollout restart annotation differ
+// legitimately between environments and are left out; map keys and resource
+// quantities are hashed in their canonical form.
+func SpecHash(dep *appsv1.Deployment) string {
+	spec := dep.Spec.DeepCopy()
+	spec.Replicas = nil
+	delete(spec.Template.Annotations, restartedAtAnnotation)
+	if len(spec.Template.Annotations) == 0 {
+		spec.Template.Annotations = nil
+	}
+
+	// Encoding a spec, made of plain API types, cannot fail
+	data, _ := json.Marshal(spec)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,54 @@
+package drift
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newDeployment(namespace, name, image string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name, "tier": "web"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  name,
+						Image: image,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestSpecHash(t *testing.T) {
+	base := newDeployment("shop", "web", "nginx:1.25", 3)
+
+	// Replicas, restarts and the form of quantities don't change the hash
+	scaled := newDeployment("shop", "web", "nginx:1.25", 10)
+	scaled.Spec.Template.Annotations = map[string]string{restartedAtAnnotation: "2026-01-01T00:00:00Z"}
+	scaled.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("1000m")
+	if SpecHash(base) != SpecHash(scaled) {
+		t.Error("Expected equal hashes for deployments differing only in replicas, restarts and quantity form")
+	}
+	if scaled.Spec.Replicas == nil || scaled.Spec.Template.Annotations[restartedAtAnnotation] == "" {
+		t.Error("Expected SpecHash to leave the deployment unchanged")
+	}
+
+	upgraded := newDeployment("shop", "web", "nginx:1.26", 3)
+	if SpecHash(base) == SpecHash(upgraded) {
+		t.Error("Expected different hashes for different images")
+	}
+	if len(SpecHash(base)) != 16 {
+		t.Errorf("Expected a 16 character hash, got %q", SpecHash(base))
+	}
+}
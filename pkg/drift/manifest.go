@@ -0,0 +1,353 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/manifest"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// DesiredManifestAnnotation holds the manifest "k6s apply" last applied to a
+// deployment, as compact JSON
+const DesiredManifestAnnotation = "k6s.io/desired-manifest"
+
+// AppliedSource is the source reported for manifests recorded by "k6s apply"
+const AppliedSource = "k6s apply"
+
+// defaultManifestInterval is used when no interval is configured
+const defaultManifestInterval = time.Minute
+
+// Manifest drift statuses
+const (
+	StatusInSync  = "InSync"
+	StatusDrifted = "Drifted"
+	StatusMissing = "Missing"
+)
+
+// Desired is the manifest declared for a deployment and where it was read from
+type Desired struct {
+	Deployment *appsv1.Deployment
+	Source     string
+}
+
+// DeploymentDrift reports how a live deployment differs from its declared
+// manifest. Fields are changes from the manifest to the live object, for the
+// replicas, images, resources and labels the manifest declares.
+type DeploymentDrift struct {
+	Namespace string                        `json:"namespace"`
+	Name      string                        `json:"name"`
+	Source    string                        `json:"source"`
+	Status    string                        `json:"status"`
+	Fields    []kubernetes.DeploymentChange `json:"fields"`
+}
+
+// ManifestReport is the result of comparing deployments with their declared
+// manifests. Manifests that could not be read are reported in Errors.
+type ManifestReport struct {
+	CheckedAt   time.Time         `json:"checkedAt"`
+	Path        string            `json:"path,omitempty"`
+	Deployments []DeploymentDrift `json:"deployments"`
+	Drifted     int               `json:"drifted"`
+	Missing     int               `json:"missing"`
+	Errors      []string          `json:"errors,omitempty"`
+}
+
+// RecordDesired returns a JSON manifest with the manifest itself recorded in
+// the DesiredManifestAnnotation, so that the applied deployment carries its
+// declared state
+func RecordDesired(data []byte) ([]byte, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+		obj["metadata"] = metadata
+	}
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if annotations == nil {
+		annotations = make(map[string]interface{})
+	}
+
+	// A manifest exported from a live deployment must not record its
+	// previous manifest
+	delete(annotations, DesiredManifestAnnotation)
+	if len(annotations) == 0 {
+		delete(metadata, "annotations")
+	} else {
+		metadata["annotations"] = annotations
+	}
+	desired, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations[DesiredManifestAnnotation] = string(desired)
+	metadata["annotations"] = annotations
+	return json.Marshal(obj)
+}
+
+// Applied returns the manifest recorded on a deployment by "k6s apply", and
+// false when it has none
+func Applied(dep *appsv1.Deployment) (Desired, bool, error) {
+	data, exists := dep.Annotations[DesiredManifestAnnotation]
+	if !exists {
+		return Desired{}, false, nil
+	}
+	desired := &appsv1.Deployment{}
+	if err := json.Unmarshal([]byte(data), desired); err != nil {
+		return Desired{}, false, fmt.Errorf("deployment %s/%s: invalid %s annotation: %w", dep.Namespace, dep.Name, DesiredManifestAnnotation, err)
+	}
+	desired.Namespace, desired.Name = dep.Namespace, dep.Name
+	return Desired{Deployment: desired, Source: AppliedSource}, true, nil
+}
+
+// Read returns the deployments declared in a directory of manifests, read
+// recursively, with namespace set on those without one
+func Read(path, namespace string) ([]Desired, error) {
+	manifests, err := manifest.Read([]string{path}, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.SetNamespace(manifests, namespace, false); err != nil {
+		return nil, err
+	}
+	desired := make([]Desired, 0, len(manifests))
+	for _, m := range manifests {
+		desired = append(desired, Desired{Deployment: m.Deployment, Source: m.Source})
+	}
+	return desired, nil
+}
+
+// CompareManifests compares declared manifests with the live deployments,
+// keyed by namespace/name, and returns the result of each sorted by
+// namespace and name. A deployment declared twice is compared with its last
+// manifest.
+func CompareManifests(desired []Desired, live map[string]*appsv1.Deployment) []DeploymentDrift {
+	declared := make(map[string]Desired, len(desired))
+	for _, d := range desired {
+		declared[d.Deployment.Namespace+"/"+d.Deployment.Name] = d
+	}
+
+	drifts := make([]DeploymentDrift, 0, len(declared))
+	for key, d := range declared {
+		drift := DeploymentDrift{
+			Namespace: d.Deployment.Namespace,
+			Name:      d.Deployment.Name,
+			Source:    d.Source,
+			Status:    StatusInSync,
+			Fields:    []kubernetes.DeploymentChange{},
+		}
+		if dep, exists := live[key]; !exists {
+			drift.Status = StatusMissing
+		} else if changes := kubernetes.CompareDeployments(d.Deployment, dep); len(changes) > 0 {
+			drift.Status = StatusDrifted
+			drift.Fields = changes
+		}
+		drifts = append(drifts, drift)
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Namespace != drifts[j].Namespace {
+			return drifts[i].Namespace < drifts[j].Namespace
+		}
+		return drifts[i].Name < drifts[j].Name
+	})
+	return drifts
+}
+
+// ManifestDetector periodically compares the cached deployments with the
+// manifests declared for them, those recorded by "k6s apply" and those of
+// the configured directory, and logs a warning when a deployment starts to
+// drift from its manifest or goes missing
+type ManifestDetector struct {
+	deployments *kubernetes.DeploymentInformer
+	log         *logger.Logger
+
+	mu      sync.RWMutex
+	cfg     config.ManifestDriftConfig
+	report  ManifestReport
+	checked bool
+
+	// Deployments alarmed on, keyed by namespace/name
+	alarms map[string]bool
+
+	// Records an event on deployments that start to drift, nil to only log
+	recorder record.EventRecorder
+}
+
+// NewManifestDetector creates a detector comparing the deployments of informer
+func NewManifestDetector(deployments *kubernetes.DeploymentInformer, cfg config.ManifestDriftConfig) *ManifestDetector {
+	return &ManifestDetector{
+		deployments: deployments,
+		cfg:         cfg,
+		alarms:      make(map[string]bool),
+		log:         logger.WithComponent("manifest-drift"),
+	}
+}
+
+// SetConfig replaces the manifest directory and interval, taking effect at
+// the next check
+func (d *ManifestDetector) SetConfig(cfg config.ManifestDriftConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cfg = cfg
+	if !cfg.Enabled {
+		d.checked = false
+	}
+}
+
+// SetEventRecorder records a warning event on each deployment that starts to
+// drift from its manifest. It must be called before Start.
+func (d *ManifestDetector) SetEventRecorder(recorder record.EventRecorder) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.recorder = recorder
+}
+
+// Enabled reports whether manifest drift detection is enabled
+func (d *ManifestDetector) Enabled() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.cfg.Enabled
+}
+
+// Report returns the result of the last check, and false before the first
+// one or while detection is disabled
+func (d *ManifestDetector) Report() (ManifestReport, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.report, d.checked
+}
+
+// Start checks for drift every interval while detection is enabled, until
+// ctx is cancelled
+func (d *ManifestDetector) Start(ctx context.Context) {
+	for {
+		d.mu.RLock()
+		enabled, interval := d.cfg.Enabled, d.cfg.Interval
+		d.mu.RUnlock()
+		if interval <= 0 {
+			interval = defaultManifestInterval
+		}
+
+		if enabled && d.deployments.HasSynced() {
+			d.Check()
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Check compares the cached deployments with their manifests once, raises
+// alarms for new drift and stores the report
+func (d *ManifestDetector) Check() ManifestReport {
+	d.mu.RLock()
+	cfg := d.cfg
+	d.mu.RUnlock()
+
+	report := ManifestReport{CheckedAt: time.Now().UTC(), Path: cfg.Path, Deployments: []DeploymentDrift{}}
+	deployments, err := d.deployments.ListDeployments()
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("listing deployments: %v", err))
+	}
+
+	live := make(map[string]*appsv1.Deployment, len(deployments))
+	var desired []Desired
+	for _, dep := range deployments {
+		live[dep.Namespace+"/"+dep.Name] = dep
+		applied, ok, err := Applied(dep)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		} else if ok {
+			desired = append(desired, applied)
+		}
+	}
+	// Manifests of the directory take precedence over recorded ones
+	if cfg.Path != "" {
+		declared, err := Read(cfg.Path, cfg.Namespace)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			d.log.Warn("Failed to read manifests for drift detection", map[string]interface{}{
+				"path":  cfg.Path,
+				"error": err.Error(),
+			})
+		}
+		desired = append(desired, declared...)
+	}
+
+	report.Deployments = CompareManifests(desired, live)
+	for _, dep := range report.Deployments {
+		switch dep.Status {
+		case StatusDrifted:
+			report.Drifted++
+		case StatusMissing:
+			report.Missing++
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.raiseAlarms(report, live)
+	d.report = report
+	d.checked = true
+	return report
+}
+
+// raiseAlarms logs deployments that started or stopped differing from their
+// manifest since the previous check, and records an event on those of live
+// that started. The caller must hold d.mu.
+func (d *ManifestDetector) raiseAlarms(report ManifestReport, live map[string]*appsv1.Deployment) {
+	current := make(map[string]bool)
+	for _, dep := range report.Deployments {
+		if dep.Status == StatusInSync {
+			continue
+		}
+		key := dep.Namespace + "/" + dep.Name
+		current[key] = true
+		if d.alarms[key] {
+			continue
+		}
+		fields := make([]string, 0, len(dep.Fields))
+		for _, field := range dep.Fields {
+			fields = append(fields, field.Field)
+		}
+		d.log.Warn("Deployment drifted from its manifest", map[string]interface{}{
+			"namespace":  dep.Namespace,
+			"deployment": dep.Name,
+			"source":     dep.Source,
+			"status":     dep.Status,
+			"fields":     fields,
+		})
+		if obj, exists := live[key]; exists && d.recorder != nil {
+			d.recorder.Eventf(obj, corev1.EventTypeWarning, kubernetes.ReasonDriftDetected,
+				"Differs from its manifest (%s) in %s", dep.Source, strings.Join(fields, ", "))
+		}
+	}
+
+	for key := range d.alarms {
+		if !current[key] {
+			d.log.Info("Deployment matches its manifest again", map[string]interface{}{
+				"deployment": key,
+			})
+		}
+	}
+	d.alarms = current
+}
@@ -0,0 +1,150 @@
+package drift
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// applied returns a live deployment recording the manifest of declared, as
+// "k6s apply" does
+func applied(t *testing.T, live, declared *appsv1.Deployment) *appsv1.Deployment {
+	t.Helper()
+	declared.APIVersion, declared.Kind = "apps/v1", "Deployment"
+	data, err := json.Marshal(declared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := RecordDesired(data)
+	if err != nil {
+		t.Fatalf("RecordDesired() error = %v", err)
+	}
+	var dep appsv1.Deployment
+	if err := json.Unmarshal(recorded, &dep); err != nil {
+		t.Fatal(err)
+	}
+	live.Annotations = dep.Annotations
+	return live
+}
+
+func TestRecordDesired(t *testing.T) {
+	data := []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","annotations":{"team":"shop","k6s.io/desired-manifest":"{}"}},"spec":{"replicas":2}}`)
+	recorded, err := RecordDesired(data)
+	if err != nil {
+		t.Fatalf("RecordDesired() error = %v", err)
+	}
+
+	var dep appsv1.Deployment
+	if err := json.Unmarshal(recorded, &dep); err != nil {
+		t.Fatalf("Invalid manifest: %v", err)
+	}
+	if dep.Annotations["team"] != "shop" {
+		t.Errorf("Expected other annotations to be kept, got %v", dep.Annotations)
+	}
+	desired, ok, err := Applied(&dep)
+	if err != nil || !ok {
+		t.Fatalf("Applied() = %v, %v", ok, err)
+	}
+	if desired.Source != AppliedSource || *desired.Deployment.Spec.Replicas != 2 {
+		t.Errorf("Unexpected desired manifest: %+v", desired)
+	}
+	if _, nested := desired.Deployment.Annotations[DesiredManifestAnnotation]; nested {
+		t.Error("Expected the recorded manifest not to record a previous one")
+	}
+
+	if _, ok, _ := Applied(newDeployment("shop", "api", "api:1.0", 1)); ok {
+		t.Error("Expected no manifest on a deployment that was not applied")
+	}
+	invalid := newDeployment("shop", "bad", "bad:1.0", 1)
+	invalid.Annotations = map[string]string{DesiredManifestAnnotation: "{"}
+	if _, _, err := Applied(invalid); err == nil {
+		t.Error("Expected an error for an invalid recorded manifest")
+	}
+}
+
+func TestManifestDetectorCheck(t *testing.T) {
+	dir := t.TempDir()
+	manifests := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+      - name: api
+        image: api:2.0
+        resources:
+          requests:
+            cpu: "1"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: queue
+  namespace: jobs
+spec:
+  replicas: 1
+`
+	if err := os.WriteFile(filepath.Join(dir, "shop.yaml"), []byte(manifests), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	clientset := fake.NewSimpleClientset(
+		// Scaled by hand since it was applied
+		applied(t, newDeployment("shop", "web", "nginx:1.25", 5), newDeployment("shop", "web", "nginx:1.25", 3)),
+		// Applied at 1.0, then declared at 2.0 in the directory
+		applied(t, newDeployment("shop", "api", "api:2.0", 2), newDeployment("shop", "api", "api:1.0", 2)),
+		newDeployment("shop", "worker", "worker:1.0", 1),
+	)
+	deployments := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+
+	detector := NewManifestDetector(deployments, config.ManifestDriftConfig{Enabled: true, Path: dir, Namespace: "shop"})
+	if _, checked := detector.Report(); checked {
+		t.Error("Expected no report before the first check")
+	}
+
+	report := detector.Check()
+	if len(report.Errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", report.Errors)
+	}
+	want := map[string]string{"jobs/queue": StatusMissing, "shop/api": StatusInSync, "shop/web": StatusDrifted}
+	if len(report.Deployments) != len(want) {
+		t.Fatalf("Expected %d declared deployments, got %+v", len(want), report.Deployments)
+	}
+	for _, dep := range report.Deployments {
+		if status := want[dep.Namespace+"/"+dep.Name]; dep.Status != status {
+			t.Errorf("Expected %s/%s %s, got %s (%+v)", dep.Namespace, dep.Name, status, dep.Status, dep.Fields)
+		}
+	}
+	if api := report.Deployments[1]; api.Source != filepath.Join(dir, "shop.yaml") {
+		t.Errorf("Expected the directory to take precedence over the recorded manifest, got source %s", api.Source)
+	}
+	if web := report.Deployments[2]; len(web.Fields) != 1 || web.Fields[0].Field != "replicas" || web.Source != AppliedSource {
+		t.Errorf("Expected the replicas of web to drift from its applied manifest, got %+v", web)
+	}
+	if report.Drifted != 1 || report.Missing != 1 {
+		t.Errorf("Expected 1 drifted and 1 missing deployment, got %d and %d", report.Drifted, report.Missing)
+	}
+	if len(detector.alarms) != 2 || !detector.alarms["shop/web"] {
+		t.Errorf("Expected alarms for the drifted and missing deployments, got %v", detector.alarms)
+	}
+
+	// An unreadable directory is reported, recorded manifests are still compared
+	detector.SetConfig(config.ManifestDriftConfig{Enabled: true, Path: filepath.Join(dir, "missing")})
+	report = detector.Check()
+	if len(report.Errors) != 1 || len(report.Deployments) != 2 {
+		t.Errorf("Expected an error and the 2 applied deployments, got %+v", report)
+	}
+}
@@ -0,0 +1,69 @@
+// Package gitops reconciles deployments from the manifests of a Git
+// repository, applying them to the cluster and reverting changes made to
+// them in the cluster.
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repository is a shallow clone of a Git repository, kept up to date with
+// the git command
+type Repository struct {
+	// URL or path of the repository, as given to git clone
+	URL string
+
+	// Branch to follow; empty follows the remote's default branch
+	Branch string
+
+	// Dir is the directory of the clone
+	Dir string
+}
+
+// Sync clones the repository, or fetches the latest commit of its branch and
+// resets the clone to it, and returns the commit checked out
+func (r *Repository) Sync(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(r.Dir, ".git")); os.IsNotExist(err) {
+		args := []string{"clone", "--depth", "1"}
+		if r.Branch != "" {
+			args = append(args, "--branch", r.Branch)
+		}
+		if _, err := git(ctx, "", append(args, "--", r.URL, r.Dir)...); err != nil {
+			return "", err
+		}
+	} else {
+		// The repository may have changed since the clone
+		if _, err := git(ctx, r.Dir, "remote", "set-url", "origin", r.URL); err != nil {
+			return "", err
+		}
+		ref := r.Branch
+		if ref == "" {
+			ref = "HEAD"
+		}
+		if _, err := git(ctx, r.Dir, "fetch", "--depth", "1", "origin", ref); err != nil {
+			return "", err
+		}
+		if _, err := git(ctx, r.Dir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+	}
+	return git(ctx, r.Dir, "rev-parse", "HEAD")
+}
+
+// git runs a git command in dir and returns its trimmed output. Credentials
+// are never prompted for.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...) // #nosec G204 - arguments are passed to git without a shell
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,77 @@
+package gitops
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// newOrigin creates a Git repository with files committed to branch main
+func newOrigin(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--initial-branch", "main")
+	commitFiles(t, dir, files)
+	return dir
+}
+
+// commitFiles writes files to a repository and commits them
+func commitFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "update")
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := git(context.Background(), dir, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRepositorySync(t *testing.T) {
+	origin := newOrigin(t, map[string]string{"README": "v1"})
+	repo := Repository{URL: origin, Branch: "main", Dir: filepath.Join(t.TempDir(), "clone")}
+
+	revision, err := repo.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if want := runGit(t, origin, "rev-parse", "HEAD"); revision != want {
+		t.Errorf("Expected revision %s after clone, got %s", want, revision)
+	}
+
+	commitFiles(t, origin, map[string]string{"README": "v2"})
+	revision, err = repo.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if want := runGit(t, origin, "rev-parse", "HEAD"); revision != want {
+		t.Errorf("Expected revision %s after fetch, got %s", want, revision)
+	}
+	data, err := os.ReadFile(filepath.Join(repo.Dir, "README"))
+	if err != nil || string(data) != "v2" {
+		t.Errorf("Expected the clone to be reset to the new commit, got %q (%v)", data, err)
+	}
+
+	repo.Branch = "missing"
+	if _, err := repo.Sync(context.Background()); err == nil {
+		t.Error("Expected an error fetching a missing branch")
+	}
+}
@@ -0,0 +1,338 @@
+package gitops
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/manifest"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Sync statuses of the deployments declared in the repository
+const (
+	StatusSynced    = "Synced"    // applied, or matching the repository while suspended
+	StatusOutOfSync = "OutOfSync" // differing from the repository while suspended
+	StatusFailed    = "Failed"    // could not be applied or compared
+)
+
+// defaultInterval is used when no interval is configured
+const defaultInterval = time.Minute
+
+// applyTimeout bounds the server-side apply of one deployment
+const applyTimeout = 30 * time.Second
+
+// ResourceStatus reports the sync of a deployment declared in the repository
+type ResourceStatus struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	LastApplied string `json:"lastApplied,omitempty"`
+}
+
+// Status reports the last sync of the repository and of each deployment it
+// declares. Times are RFC 3339 strings, as in the controller status.
+type Status struct {
+	Repository string           `json:"repository"`
+	Branch     string           `json:"branch,omitempty"`
+	Revision   string           `json:"revision,omitempty"`
+	Suspended  bool             `json:"suspended"`
+	LastSync   string           `json:"lastSync,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	Resources  []ResourceStatus `json:"resources"`
+}
+
+// Syncer polls a Git repository of deployment manifests and applies them to
+// a cluster with a server-side apply. It also serves as the desired state of
+// the deployment reconciler, which reverts changes made in the cluster to
+// the deployments the repository declares.
+type Syncer struct {
+	client *kubernetes.Client
+	log    *logger.Logger
+
+	mu        sync.RWMutex
+	cfg       config.GitOpsConfig
+	repo      Repository
+	revision  string
+	lastSync  time.Time
+	lastError string
+
+	// Manifests of the last revision read and the sync status of their
+	// deployments, keyed by namespace/name
+	manifests map[string]manifest.Manifest
+	resources map[string]ResourceStatus
+}
+
+// NewSyncer creates a syncer applying the repository of cfg with client
+func NewSyncer(client *kubernetes.Client, cfg config.GitOpsConfig) *Syncer {
+	return &Syncer{
+		client:    client,
+		cfg:       cfg,
+		repo:      repository(cfg),
+		manifests: make(map[string]manifest.Manifest),
+		resources: make(map[string]ResourceStatus),
+		log:       logger.WithComponent("gitops"),
+	}
+}
+
+// repository returns the clone of the repository of cfg
+func repository(cfg config.GitOpsConfig) Repository {
+	dir := cfg.CheckoutDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "k6s-gitops")
+	}
+	return Repository{URL: cfg.Repository, Branch: cfg.Branch, Dir: dir}
+}
+
+// SetConfig replaces the repository, interval and suspend flag, taking
+// effect at the next sync
+func (s *Syncer) SetConfig(cfg config.GitOpsConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cfg.Suspend != s.cfg.Suspend {
+		s.log.Info("GitOps sync suspend changed", map[string]interface{}{
+			"suspended": cfg.Suspend,
+		})
+	}
+	s.cfg = cfg
+	s.repo = repository(cfg)
+}
+
+// Status returns the status of the last sync, with deployments sorted by
+// namespace and name
+func (s *Syncer) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := Status{
+		Repository: s.cfg.Repository,
+		Branch:     s.cfg.Branch,
+		Revision:   s.revision,
+		Suspended:  s.cfg.Suspend,
+		Error:      s.lastError,
+		Resources:  make([]ResourceStatus, 0, len(s.resources)),
+	}
+	if !s.lastSync.IsZero() {
+		status.LastSync = s.lastSync.UTC().Format(time.RFC3339)
+	}
+	for _, resource := range s.resources {
+		status.Resources = append(status.Resources, resource)
+	}
+	sort.Slice(status.Resources, func(i, j int) bool {
+		a, b := status.Resources[i], status.Resources[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return status
+}
+
+// Start syncs the repository every interval until ctx is cancelled. It
+// implements manager.Runnable, so it only runs on the elected leader.
+func (s *Syncer) Start(ctx context.Context) error {
+	s.mu.RLock()
+	s.log.Info("Starting GitOps sync", map[string]interface{}{
+		"repository": s.cfg.Repository,
+		"branch":     s.cfg.Branch,
+		"path":       s.cfg.Path,
+		"interval":   s.cfg.Interval.String(),
+	})
+	s.mu.RUnlock()
+
+	for {
+		s.Sync(ctx)
+
+		s.mu.RLock()
+		interval := s.cfg.Interval
+		s.mu.RUnlock()
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Sync fetches the latest commit of the repository and applies the
+// deployments it declares, or only compares them with the cluster while
+// suspended, and returns the resulting status. When the repository can't be
+// fetched or read, the deployments of the previous revision are kept.
+func (s *Syncer) Sync(ctx context.Context) Status {
+	s.mu.RLock()
+	cfg, repo, previous := s.cfg, s.repo, s.revision
+	s.mu.RUnlock()
+
+	revision, err := repo.Sync(ctx)
+	var manifests []manifest.Manifest
+	if err == nil {
+		manifests, err = read(repo.Dir, cfg)
+	}
+	if err != nil {
+		s.log.Warn("Failed to sync GitOps repository", map[string]interface{}{
+			"repository": cfg.Repository,
+			"error":      err.Error(),
+		})
+		s.mu.Lock()
+		s.lastSync = time.Now()
+		s.lastError = err.Error()
+		s.mu.Unlock()
+		return s.Status()
+	}
+	if revision != previous {
+		s.log.Info("Syncing GitOps revision", map[string]interface{}{
+			"repository":  cfg.Repository,
+			"revision":    revision,
+			"deployments": len(manifests),
+			"suspended":   cfg.Suspend,
+		})
+	}
+
+	declared := make(map[string]manifest.Manifest, len(manifests))
+	resources := make(map[string]ResourceStatus, len(manifests))
+	for _, m := range manifests {
+		key := m.Deployment.Namespace + "/" + m.Deployment.Name
+		declared[key] = m
+		resources[key] = s.syncDeployment(ctx, m, cfg)
+	}
+
+	s.mu.Lock()
+	s.revision = revision
+	s.lastSync = time.Now()
+	s.lastError = ""
+	s.manifests = declared
+	s.resources = resources
+	s.mu.Unlock()
+	return s.Status()
+}
+
+// read returns the deployments declared in the manifest directory of a
+// clone, with sources relative to the clone
+func read(dir string, cfg config.GitOpsConfig) ([]manifest.Manifest, error) {
+	manifests, err := manifest.Read([]string{filepath.Join(dir, cfg.Path)}, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.SetNamespace(manifests, cfg.Namespace, false); err != nil {
+		return nil, err
+	}
+	for i := range manifests {
+		if rel, err := filepath.Rel(dir, manifests[i].Source); err == nil {
+			manifests[i].Source = rel
+		}
+	}
+	return manifests, nil
+}
+
+// syncDeployment applies a declared deployment, or compares it with the
+// cluster while suspended
+func (s *Syncer) syncDeployment(ctx context.Context, m manifest.Manifest, cfg config.GitOpsConfig) ResourceStatus {
+	dep := m.Deployment
+	status := ResourceStatus{Namespace: dep.Namespace, Name: dep.Name, Source: m.Source, Status: StatusSynced}
+
+	if cfg.Suspend {
+		live, err := s.client.DeploymentGet(dep.Namespace, dep.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			status.Status = StatusOutOfSync
+		case err != nil:
+			status.Status = StatusFailed
+			status.Error = err.Error()
+		case len(kubernetes.CompareDeployments(dep, live)) > 0:
+			status.Status = StatusOutOfSync
+		}
+		return status
+	}
+
+	if err := s.apply(ctx, m, cfg.ForceConflicts); err != nil {
+		status.Status = StatusFailed
+		status.Error = err.Error()
+		s.log.Warn("Failed to apply GitOps deployment", map[string]interface{}{
+			"namespace":  dep.Namespace,
+			"deployment": dep.Name,
+			"source":     m.Source,
+			"error":      err.Error(),
+		})
+		return status
+	}
+	status.LastApplied = time.Now().UTC().Format(time.RFC3339)
+	return status
+}
+
+// apply applies a declared deployment with a server-side apply, recording
+// the manifest for drift detection
+func (s *Syncer) apply(ctx context.Context, m manifest.Manifest, force bool) error {
+	data, err := drift.RecordDesired(m.Data)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, applyTimeout)
+	defer cancel()
+	_, err = s.client.DeploymentApply(ctx, m.Deployment.Namespace, m.Deployment.Name, data, kubernetes.ApplyOptions{Force: force})
+	return err
+}
+
+// ReconcileDeployment reverts a deployment declared in the repository to its
+// manifest when its replicas, images, resources or labels changed in the
+// cluster, and recreates it when it was deleted; live is nil for a deleted
+// deployment. Deployments the repository doesn't declare are left alone,
+// and while suspended only the sync status is updated.
+func (s *Syncer) ReconcileDeployment(ctx context.Context, namespace, name string, live *appsv1.Deployment) error {
+	key := namespace + "/" + name
+	s.mu.RLock()
+	m, declared := s.manifests[key]
+	cfg := s.cfg
+	s.mu.RUnlock()
+	if !declared {
+		return nil
+	}
+
+	inSync := live != nil && len(kubernetes.CompareDeployments(m.Deployment, live)) == 0
+	status := ResourceStatus{Namespace: namespace, Name: name, Source: m.Source, Status: StatusSynced}
+	var err error
+	switch {
+	case cfg.Suspend && !inSync:
+		status.Status = StatusOutOfSync
+	case cfg.Suspend || inSync:
+		// Keep the time of the last apply
+		s.mu.RLock()
+		status.LastApplied = s.resources[key].LastApplied
+		s.mu.RUnlock()
+	default:
+		if err = s.apply(ctx, m, cfg.ForceConflicts); err != nil {
+			status.Status = StatusFailed
+			status.Error = err.Error()
+		} else {
+			status.LastApplied = time.Now().UTC().Format(time.RFC3339)
+			message := "Reverted deployment to its GitOps manifest"
+			if live == nil {
+				message = "Recreated deleted deployment from its GitOps manifest"
+			}
+			s.log.Info(message, map[string]interface{}{
+				"namespace":  namespace,
+				"deployment": name,
+				"source":     m.Source,
+			})
+		}
+	}
+
+	s.mu.Lock()
+	s.resources[key] = status
+	s.mu.Unlock()
+	return err
+}
@@ -0,0 +1,137 @@
+package gitops
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const webManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+`
+
+func newDeployment(namespace, name, image string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: image}}},
+			},
+		},
+	}
+}
+
+// newTestSyncer returns a syncer of a repository declaring shop/web, and the
+// namespace/name of the deployments it applies
+func newTestSyncer(t *testing.T, suspend bool, objects ...runtime.Object) (*Syncer, *[]string) {
+	t.Helper()
+	origin := newOrigin(t, map[string]string{"deploy/web.yaml": webManifest, "README": "not a manifest"})
+
+	clientset := fake.NewSimpleClientset(objects...)
+	applied := &[]string{}
+	clientset.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchActionImpl)
+		*applied = append(*applied, patch.GetNamespace()+"/"+patch.GetName())
+		return true, &appsv1.Deployment{}, nil
+	})
+
+	syncer := NewSyncer(kubernetes.NewClientWithClientset(clientset), config.GitOpsConfig{
+		Enabled:     true,
+		Repository:  origin,
+		Path:        "deploy",
+		Namespace:   "shop",
+		Suspend:     suspend,
+		CheckoutDir: filepath.Join(t.TempDir(), "clone"),
+	})
+	return syncer, applied
+}
+
+func TestSyncerSync(t *testing.T) {
+	syncer, applied := newTestSyncer(t, false)
+
+	status := syncer.Sync(context.Background())
+	if status.Error != "" || status.Revision == "" {
+		t.Fatalf("Expected a synced revision, got %+v", status)
+	}
+	if len(*applied) != 1 || (*applied)[0] != "shop/web" {
+		t.Errorf("Expected shop/web to be applied, got %v", *applied)
+	}
+	if len(status.Resources) != 1 {
+		t.Fatalf("Expected one resource, got %+v", status.Resources)
+	}
+	resource := status.Resources[0]
+	if resource.Status != StatusSynced || resource.Source != filepath.Join("deploy", "web.yaml") || resource.LastApplied == "" {
+		t.Errorf("Expected shop/web synced from deploy/web.yaml, got %+v", resource)
+	}
+
+	syncer.SetConfig(config.GitOpsConfig{Repository: "/nonexistent", CheckoutDir: filepath.Join(t.TempDir(), "other")})
+	status = syncer.Sync(context.Background())
+	if status.Error == "" || len(status.Resources) != 1 {
+		t.Errorf("Expected an error keeping the previous resources, got %+v", status)
+	}
+}
+
+func TestSyncerSuspended(t *testing.T) {
+	syncer, applied := newTestSyncer(t, true, newDeployment("shop", "web", "nginx:1.26", 2))
+
+	status := syncer.Sync(context.Background())
+	if len(*applied) != 0 {
+		t.Errorf("Expected nothing applied while suspended, got %v", *applied)
+	}
+	if !status.Suspended || len(status.Resources) != 1 || status.Resources[0].Status != StatusOutOfSync {
+		t.Errorf("Expected shop/web out of sync, got %+v", status)
+	}
+
+	if err := syncer.ReconcileDeployment(context.Background(), "shop", "web", nil); err != nil {
+		t.Fatalf("ReconcileDeployment() error = %v", err)
+	}
+	if len(*applied) != 0 {
+		t.Errorf("Expected a deleted deployment not to be recreated while suspended, got %v", *applied)
+	}
+}
+
+func TestSyncerReconcileDeployment(t *testing.T) {
+	syncer, applied := newTestSyncer(t, false)
+	syncer.Sync(context.Background())
+	*applied = nil
+
+	ctx := context.Background()
+	if err := syncer.ReconcileDeployment(ctx, "shop", "web", newDeployment("shop", "web", "nginx:1.27", 2)); err != nil {
+		t.Fatalf("ReconcileDeployment() error = %v", err)
+	}
+	if err := syncer.ReconcileDeployment(ctx, "shop", "api", newDeployment("shop", "api", "api:1", 1)); err != nil {
+		t.Fatalf("ReconcileDeployment() error = %v", err)
+	}
+	if len(*applied) != 0 {
+		t.Fatalf("Expected in-sync and undeclared deployments to be left alone, got %v", *applied)
+	}
+
+	if err := syncer.ReconcileDeployment(ctx, "shop", "web", newDeployment("shop", "web", "nginx:1.27", 5)); err != nil {
+		t.Fatalf("ReconcileDeployment() error = %v", err)
+	}
+	if err := syncer.ReconcileDeployment(ctx, "shop", "web", nil); err != nil {
+		t.Fatalf("ReconcileDeployment() error = %v", err)
+	}
+	if len(*applied) != 2 {
+		t.Errorf("Expected a scaled and a deleted deployment to be re-applied, got %v", *applied)
+	}
+}
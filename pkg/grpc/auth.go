@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/grpc/k6spb"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/security"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticator authenticates and authorizes the callers of the gRPC API,
+// such as the HTTP API server with its configured authentication
+type Authenticator interface {
+	// Authenticate returns who presents a bearer token and a TLS client
+	// certificate chain, either of which may be empty
+	Authenticate(token string, certs []*x509.Certificate) (security.Identity, bool, error)
+	// Authorize returns the role of a caller and whether it may call method
+	// on route of the HTTP API
+	Authorize(identity security.Identity, method, route string) (security.Role, bool)
+}
+
+// methodRoutes maps gRPC methods to the HTTP API routes returning the same
+// data, so that roles allow the same reads over both APIs. Other methods,
+// such as reflection, are authorized as reads of their full method name.
+var methodRoutes = map[string]string{
+	k6spb.DeploymentService_ListDeployments_FullMethodName: "/api/v1/deployments",
+	k6spb.DeploymentService_GetDeployment_FullMethodName:   "/api/v1/deployments/{namespace}/{name}",
+	k6spb.ClusterService_ListClusters_FullMethodName:       "/api/v1/clusters",
+	k6spb.ClusterService_GetCluster_FullMethodName:         "/api/v1/clusters/{name}",
+}
+
+// WithAuth returns the server options requiring every call to authenticate
+// with auth and be allowed by the caller's role
+func WithAuth(auth Authenticator) []grpclib.ServerOption {
+	return []grpclib.ServerOption{
+		grpclib.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+			if err := authorize(ctx, auth, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpclib.ChainStreamInterceptor(func(srv interface{}, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+			if err := authorize(stream.Context(), auth, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+// authorize authenticates the caller of fullMethod from the bearer token in
+// its metadata and its TLS client certificate, and checks its role
+func authorize(ctx context.Context, auth Authenticator, fullMethod string) error {
+	identity, ok, err := auth.Authenticate(bearerToken(ctx), peerCertificates(ctx))
+	if err != nil {
+		logger.Warn("gRPC authentication failed", map[string]interface{}{
+			"method": fullMethod,
+			"error":  err.Error(),
+		})
+		return status.Error(codes.Unauthenticated, "missing or invalid credentials")
+	}
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing or invalid credentials")
+	}
+
+	route, found := methodRoutes[fullMethod]
+	if !found {
+		route = fullMethod
+	}
+	role, allowed := auth.Authorize(identity, "GET", route)
+	if !allowed {
+		logger.Warn("gRPC call denied", map[string]interface{}{
+			"principal": identity.Name,
+			"role":      string(role),
+			"method":    fullMethod,
+		})
+		metrics.APIAuthorizationDenials.WithLabelValues(string(role), "GET", route).Inc()
+		return status.Errorf(codes.PermissionDenied, "role %s may not call %s", role, fullMethod)
+	}
+	return nil
+}
+
+// bearerToken returns the bearer token of the authorization metadata of a call
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get("authorization") {
+		if token, found := strings.CutPrefix(value, "Bearer "); found {
+			return token
+		}
+	}
+	return ""
+}
+
+// peerCertificates returns the certificate chain the caller presented over TLS
+func peerCertificates(ctx context.Context) []*x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return info.State.PeerCertificates
+}
@@ -0,0 +1,254 @@
+// Package grpc exposes deployment and cluster data over gRPC.
+// Service definitions live in proto/k6s.proto; regenerate k6spb with "make proto".
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/grpc/k6spb"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Server serves the gRPC API alongside the HTTP API
+type Server struct {
+	port     int
+	informer *kubernetes.DeploymentInformer
+	registry cluster.ClusterRegistry
+	server   *grpclib.Server
+}
+
+// NewServer creates a new gRPC server with opts, such as those of WithAuth.
+// The informer and registry are optional; services without a backing source
+// return Unavailable.
+func NewServer(port int, informer *kubernetes.DeploymentInformer, registry cluster.ClusterRegistry, opts ...grpclib.ServerOption) *Server {
+	opts = append([]grpclib.ServerOption{grpclib.ChainUnaryInterceptor(loggingInterceptor)}, opts...)
+	s := &Server{
+		port:     port,
+		informer: informer,
+		registry: registry,
+		server:   grpclib.NewServer(opts...),
+	}
+
+	k6spb.RegisterDeploymentServiceServer(s.server, &deploymentService{informer: informer})
+	k6spb.RegisterClusterServiceServer(s.server, &clusterService{registry: registry})
+	reflection.Register(s.server)
+
+	return s
+}
+
+// Start listens on the configured port and serves until Stop is called
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", s.port, err)
+	}
+
+	logger.Info("Starting gRPC server", map[string]interface{}{
+		"port": s.port,
+	})
+
+	return s.Serve(lis)
+}
+
+// Serve serves gRPC requests on an existing listener
+func (s *Server) Serve(lis net.Listener) error {
+	return s.server.Serve(lis)
+}
+
+// Stop gracefully stops the server, waiting for in-flight calls
+func (s *Server) Stop() {
+	s.server.GracefulStop()
+}
+
+// loggingInterceptor logs each unary call at debug level
+func loggingInterceptor(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	logger.Debug("gRPC call", map[string]interface{}{
+		"method": info.FullMethod,
+		"code":   status.Code(err).String(),
+	})
+	return resp, err
+}
+
+// deploymentService implements k6spb.DeploymentServiceServer from the informer cache
+type deploymentService struct {
+	k6spb.UnimplementedDeploymentServiceServer
+	informer *kubernetes.DeploymentInformer
+}
+
+// ListDeployments lists cached deployments, filtered by namespace and label selector
+func (d *deploymentService) ListDeployments(ctx context.Context, req *k6spb.ListDeploymentsRequest) (*k6spb.ListDeploymentsResponse, error) {
+	if err := d.checkReady(); err != nil {
+		return nil, err
+	}
+
+	selector := labels.Everything()
+	if req.GetLabelSelector() != "" {
+		parsed, err := labels.Parse(req.GetLabelSelector())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid label selector: %v", err)
+		}
+		selector = parsed
+	}
+
+	deployments, err := d.informer.ListDeployments()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list deployments: %v", err)
+	}
+
+	resp := &k6spb.ListDeploymentsResponse{
+		Items: make([]*k6spb.Deployment, 0, len(deployments)),
+	}
+	for _, dep := range deployments {
+		if req.GetNamespace() != "" && dep.Namespace != req.GetNamespace() {
+			continue
+		}
+		if !selector.Matches(labels.Set(dep.Labels)) {
+			continue
+		}
+		resp.Items = append(resp.Items, toProtoDeployment(dep))
+	}
+
+	sort.Slice(resp.Items, func(i, j int) bool {
+		if resp.Items[i].Namespace != resp.Items[j].Namespace {
+			return resp.Items[i].Namespace < resp.Items[j].Namespace
+		}
+		return resp.Items[i].Name < resp.Items[j].Name
+	})
+
+	return resp, nil
+}
+
+// GetDeployment returns a single cached deployment
+func (d *deploymentService) GetDeployment(ctx context.Context, req *k6spb.GetDeploymentRequest) (*k6spb.Deployment, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if err := d.checkReady(); err != nil {
+		return nil, err
+	}
+
+	namespace := req.GetNamespace()
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	dep, err := d.informer.GetDeployment(namespace, req.GetName())
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "deployment %s/%s not found", namespace, req.GetName())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get deployment: %v", err)
+	}
+
+	return toProtoDeployment(dep), nil
+}
+
+// checkReady reports Unavailable until the informer cache is synced
+func (d *deploymentService) checkReady() error {
+	if d.informer == nil || !d.informer.IsStarted() {
+		return status.Error(codes.Unavailable, "deployment informer is not started")
+	}
+	if !d.informer.HasSynced() {
+		return status.Error(codes.Unavailable, "deployment informer cache is not synced")
+	}
+	return nil
+}
+
+// toProtoDeployment converts a Kubernetes deployment to its protobuf representation
+func toProtoDeployment(dep *appsv1.Deployment) *k6spb.Deployment {
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+
+	images := make([]string, 0, len(dep.Spec.Template.Spec.Containers))
+	for _, c := range dep.Spec.Template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+
+	return &k6spb.Deployment{
+		Name:              dep.Name,
+		Namespace:         dep.Namespace,
+		Replicas:          replicas,
+		ReadyReplicas:     dep.Status.ReadyReplicas,
+		UpdatedReplicas:   dep.Status.UpdatedReplicas,
+		AvailableReplicas: dep.Status.AvailableReplicas,
+		Labels:            dep.Labels,
+		Images:            images,
+		CreatedAt:         timestamppb.New(dep.CreationTimestamp.Time),
+	}
+}
+
+// clusterService implements k6spb.ClusterServiceServer from the cluster registry
+type clusterService struct {
+	k6spb.UnimplementedClusterServiceServer
+	registry cluster.ClusterRegistry
+}
+
+// ListClusters lists registered clusters sorted by name
+func (c *clusterService) ListClusters(ctx context.Context, req *k6spb.ListClustersRequest) (*k6spb.ListClustersResponse, error) {
+	if c.registry == nil {
+		return nil, status.Error(codes.Unavailable, "cluster registry is not configured")
+	}
+
+	names := c.registry.ListClusters()
+	sort.Strings(names)
+
+	resp := &k6spb.ListClustersResponse{
+		Items: make([]*k6spb.Cluster, 0, len(names)),
+	}
+	for _, name := range names {
+		client, exists := c.registry.GetCluster(name)
+		if !exists {
+			continue
+		}
+		if req.GetEnabledOnly() && !client.IsEnabled() {
+			continue
+		}
+		resp.Items = append(resp.Items, toProtoCluster(client))
+	}
+
+	return resp, nil
+}
+
+// GetCluster returns a single registered cluster
+func (c *clusterService) GetCluster(ctx context.Context, req *k6spb.GetClusterRequest) (*k6spb.Cluster, error) {
+	if c.registry == nil {
+		return nil, status.Error(codes.Unavailable, "cluster registry is not configured")
+	}
+
+	client, exists := c.registry.GetCluster(req.GetName())
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "cluster %s not found", req.GetName())
+	}
+
+	return toProtoCluster(client), nil
+}
+
+// toProtoCluster converts a registered cluster to its protobuf representation
+func toProtoCluster(client cluster.ClusterClient) *k6spb.Cluster {
+	pb := &k6spb.Cluster{
+		Name:    client.GetName(),
+		Enabled: client.IsEnabled(),
+	}
+	if cfg, ok := client.(*cluster.ClusterConfig); ok {
+		pb.Context = cfg.Context
+		pb.Namespace = cfg.Namespace
+		pb.Primary = cfg.Primary
+	}
+	return pb
+}
@@ -0,0 +1,176 @@
+package grpc
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/grpc/k6spb"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/security"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func startTestServer(t *testing.T, informer *kubernetes.DeploymentInformer, registry cluster.ClusterRegistry, opts ...grpclib.ServerOption) *grpclib.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := NewServer(0, informer, registry, opts...)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpclib.NewClient("passthrough:///bufnet",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestDeploymentService(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", Labels: map[string]string{"app": "web"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod", Labels: map[string]string{"app": "api"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		},
+	)
+	informer := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("failed to start informer: %v", err)
+	}
+	defer informer.Stop()
+
+	client := k6spb.NewDeploymentServiceClient(startTestServer(t, informer, nil))
+	ctx := context.Background()
+
+	list, err := client.ListDeployments(ctx, &k6spb.ListDeploymentsRequest{Namespace: "prod", LabelSelector: "app=web"})
+	if err != nil {
+		t.Fatalf("ListDeployments() error = %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "web" || list.Items[0].Replicas != 3 {
+		t.Errorf("Unexpected deployments: %v", list.Items)
+	}
+
+	dep, err := client.GetDeployment(ctx, &k6spb.GetDeploymentRequest{Namespace: "prod", Name: "api"})
+	if err != nil {
+		t.Fatalf("GetDeployment() error = %v", err)
+	}
+	if dep.Replicas != 2 || dep.Namespace != "prod" {
+		t.Errorf("Unexpected deployment: %v", dep)
+	}
+
+	_, err = client.GetDeployment(ctx, &k6spb.GetDeploymentRequest{Namespace: "prod", Name: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+
+	_, err = client.ListDeployments(ctx, &k6spb.ListDeploymentsRequest{LabelSelector: "app in ("})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestClusterService(t *testing.T) {
+	registry := cluster.NewInMemoryClusterRegistry()
+	_ = registry.AddCluster("prod", &cluster.ClusterConfig{Enabled: true, Primary: true, Namespace: "production"})
+	_ = registry.AddCluster("lab", &cluster.ClusterConfig{Enabled: false})
+
+	conn := startTestServer(t, nil, registry)
+	client := k6spb.NewClusterServiceClient(conn)
+	ctx := context.Background()
+
+	list, err := client.ListClusters(ctx, &k6spb.ListClustersRequest{})
+	if err != nil {
+		t.Fatalf("ListClusters() error = %v", err)
+	}
+	if len(list.Items) != 2 || list.Items[0].Name != "lab" {
+		t.Errorf("Expected 2 clusters sorted by name, got %v", list.Items)
+	}
+
+	enabled, err := client.ListClusters(ctx, &k6spb.ListClustersRequest{EnabledOnly: true})
+	if err != nil {
+		t.Fatalf("ListClusters() error = %v", err)
+	}
+	if len(enabled.Items) != 1 || !enabled.Items[0].Primary || enabled.Items[0].Namespace != "production" {
+		t.Errorf("Unexpected enabled clusters: %v", enabled.Items)
+	}
+
+	if _, err := client.GetCluster(ctx, &k6spb.GetClusterRequest{Name: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+
+	deployments := k6spb.NewDeploymentServiceClient(conn)
+	if _, err := deployments.ListDeployments(ctx, &k6spb.ListDeploymentsRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable without informer, got %v", err)
+	}
+}
+
+// tokenAuthenticator authenticates configured tokens, and only lets admins
+// read clusters
+type tokenAuthenticator map[string]security.Identity
+
+func (a tokenAuthenticator) Authenticate(token string, _ []*x509.Certificate) (security.Identity, bool, error) {
+	identity, ok := a[token]
+	return identity, ok, nil
+}
+
+func (a tokenAuthenticator) Authorize(identity security.Identity, method, route string) (security.Role, bool) {
+	if route == "/api/v1/clusters" && identity.Role != security.RoleAdmin {
+		return identity.Role, false
+	}
+	return identity.Role, security.Allows(identity.Role, method, route)
+}
+
+func TestAuth(t *testing.T) {
+	registry := cluster.NewInMemoryClusterRegistry()
+	_ = registry.AddCluster("prod", &cluster.ClusterConfig{Enabled: true})
+
+	auth := tokenAuthenticator{
+		"admin-token":  {Name: "admin", Role: security.RoleAdmin},
+		"viewer-token": {Name: "dashboard", Role: security.RoleViewer},
+	}
+	client := k6spb.NewClusterServiceClient(startTestServer(t, nil, registry, WithAuth(auth)...))
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	if _, err := client.ListClusters(context.Background(), &k6spb.ListClustersRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a token, got %v", err)
+	}
+	if _, err := client.ListClusters(withToken("guess"), &k6spb.ListClustersRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated with an unknown token, got %v", err)
+	}
+	if _, err := client.ListClusters(withToken("viewer-token"), &k6spb.ListClustersRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied for a role the route doesn't allow, got %v", err)
+	}
+	if _, err := client.GetCluster(withToken("viewer-token"), &k6spb.GetClusterRequest{Name: "prod"}); err != nil {
+		t.Errorf("GetCluster() error = %v", err)
+	}
+	if _, err := client.ListClusters(withToken("admin-token"), &k6spb.ListClustersRequest{}); err != nil {
+		t.Errorf("ListClusters() error = %v", err)
+	}
+}
@@ -0,0 +1,337 @@
+package history
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	bolt "go.etcd.io/bbolt"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// Deployment event types
+const (
+	EventAdded   = "added"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// eventsBucket holds events keyed by their time and a sequence number, so
+// that keys sort chronologically
+var eventsBucket = []byte("events")
+
+// pruneInterval is how often events past the retention period are removed
+const pruneInterval = time.Hour
+
+// openTimeout bounds waiting for the lock of a database another process
+// holds open
+const openTimeout = time.Second
+
+// ErrLocked is returned when the event database is held open by another
+// process, such as a running k6s server
+var ErrLocked = errors.New("event history is in use by another process")
+
+// Event is a recorded deployment event. Changes are reported for updates.
+type Event struct {
+	Time      time.Time                     `json:"time"`
+	Type      string                        `json:"type"`
+	Cluster   string                        `json:"cluster,omitempty"`
+	Namespace string                        `json:"namespace"`
+	Name      string                        `json:"name"`
+	Changes   []kubernetes.DeploymentChange `json:"changes,omitempty"`
+}
+
+// EventQuery selects recorded events. Empty fields match every event.
+type EventQuery struct {
+	Namespace string
+	Name      string
+	Since     time.Time
+
+	// Maximum number of events returned, the most recent ones (0 = all)
+	Limit int
+}
+
+// EventStore keeps deployment events in an embedded bbolt database for the
+// retention period
+type EventStore struct {
+	db        *bolt.DB
+	retention time.Duration
+	now       func() time.Time
+	log       *logger.Logger
+}
+
+// DefaultEventsPath returns the event database of a history directory
+// (DefaultDir when empty)
+func DefaultEventsPath(dir string) string {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	return filepath.Join(dir, "events.db")
+}
+
+// OpenEventStore opens the event database at path, creating it if needed,
+// keeping events for the retention period (0 = forever). Only one process
+// can have the database open for writing.
+func OpenEventStore(path string, retention time.Duration) (*EventStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	db, err := openDB(path, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{eventsBucket, samplesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize event history: %w", err)
+	}
+	return newEventStore(db, retention), nil
+}
+
+// OpenEventStoreReadOnly opens the event database at path for queries
+func OpenEventStoreReadOnly(path string) (*EventStore, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open event history: %w", err)
+	}
+	db, err := openDB(path, &bolt.Options{Timeout: openTimeout, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return newEventStore(db, 0), nil
+}
+
+// openDB opens a bbolt database, reporting a database locked by another
+// process as ErrLocked
+func openDB(path string, opts *bolt.Options) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, opts)
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event history: %w", err)
+	}
+	return db, nil
+}
+
+func newEventStore(db *bolt.DB, retention time.Duration) *EventStore {
+	return &EventStore{
+		db:        db,
+		retention: retention,
+		now:       time.Now,
+		log:       logger.WithComponent("event-history"),
+	}
+}
+
+// Close closes the database
+func (s *EventStore) Close() error {
+	return s.db.Close()
+}
+
+// Record stores an event, timestamped now when it has no time
+func (s *EventStore) Record(event Event) error {
+	if event.Time.IsZero() {
+		event.Time = s.now()
+	}
+	event.Time = event.Time.UTC()
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(eventsBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		return bucket.Put(eventKey(event.Time, seq), value)
+	})
+}
+
+// Query returns the events matching q, most recent first
+func (s *EventStore) Query(q EventQuery) ([]Event, error) {
+	events := []Event{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(eventsBucket)
+		if bucket == nil {
+			return nil
+		}
+		var since []byte
+		if !q.Since.IsZero() {
+			since = eventKey(q.Since, 0)
+		}
+
+		c := bucket.Cursor()
+		for key, value := c.Last(); key != nil; key, value = c.Prev() {
+			if since != nil && string(key) < string(since) {
+				break
+			}
+			var event Event
+			if err := json.Unmarshal(value, &event); err != nil {
+				return fmt.Errorf("failed to decode event: %w", err)
+			}
+			if (q.Namespace != "" && event.Namespace != q.Namespace) || (q.Name != "" && event.Name != q.Name) {
+				continue
+			}
+			events = append(events, event)
+			if q.Limit > 0 && len(events) == q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	return events, err
+}
+
+// Prune removes the events and samples past the retention period and
+// returns how many
+func (s *EventStore) Prune() (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	cutoff := s.now().Add(-s.retention)
+	eventCutoff := eventKey(cutoff, 0)
+
+	pruned := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(eventsBucket)
+		// Deleting while iterating would skip keys, so expired keys are
+		// collected first
+		var expired [][]byte
+		c := bucket.Cursor()
+		for key, _ := c.First(); key != nil && string(key) < string(eventCutoff); key, _ = c.Next() {
+			expired = append(expired, append([]byte(nil), key...))
+		}
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		pruned = len(expired)
+
+		prunedSamples, err := pruneSamples(tx, cutoff)
+		pruned += prunedSamples
+		return err
+	})
+	return pruned, err
+}
+
+// Start prunes expired events and samples every hour until ctx is cancelled
+func (s *EventStore) Start(ctx context.Context) {
+	for {
+		if pruned, err := s.Prune(); err != nil {
+			s.log.Warn("Failed to prune event history", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else if pruned > 0 {
+			s.log.Debug("Pruned event history", map[string]interface{}{
+				"events": pruned,
+			})
+		}
+
+		timer := time.NewTimer(pruneInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// eventKey returns the key of an event: its time in nanoseconds and seq,
+// both big-endian
+func eventKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
+
+// ParseSince parses a query's start as an RFC 3339 time, or a duration
+// before now such as "2h"
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid since %q: duration must not be negative", value)
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q: must be an RFC 3339 time or a duration such as 2h", value)
+	}
+	return t, nil
+}
+
+// informerHandler records the events of a deployment informer
+type informerHandler struct {
+	store   *EventStore
+	cluster string
+
+	// When the handler was created; older deployments listed when the
+	// informer starts are not recorded as added
+	since time.Time
+}
+
+// InformerHandler returns a handler recording the deployment events of an
+// informer watching cluster in store. Updates changing none of the replicas,
+// images, resources or labels are skipped, as are deployments created before
+// the handler.
+func InformerHandler(store *EventStore, cluster string) kubernetes.DeploymentEventHandler {
+	return &informerHandler{store: store, cluster: cluster, since: time.Now()}
+}
+
+// OnAdd records a deployment created since the handler
+func (h *informerHandler) OnAdd(obj *appsv1.Deployment) {
+	if obj.CreationTimestamp.Time.Before(h.since.Truncate(time.Second)) {
+		return
+	}
+	h.record(EventAdded, obj, nil)
+}
+
+// OnUpdate records the changes of an updated deployment
+func (h *informerHandler) OnUpdate(oldObj, newObj *appsv1.Deployment) {
+	changes := kubernetes.CompareDeployments(oldObj, newObj)
+	if len(changes) == 0 {
+		return
+	}
+	h.record(EventUpdated, newObj, changes)
+}
+
+// OnDelete records a deleted deployment
+func (h *informerHandler) OnDelete(obj *appsv1.Deployment) {
+	h.record(EventDeleted, obj, nil)
+}
+
+// record stores an event of a deployment, logging failures
+func (h *informerHandler) record(eventType string, obj *appsv1.Deployment, changes []kubernetes.DeploymentChange) {
+	err := h.store.Record(Event{
+		Type:      eventType,
+		Cluster:   h.cluster,
+		Namespace: obj.Namespace,
+		Name:      obj.Name,
+		Changes:   changes,
+	})
+	if err != nil {
+		h.store.log.Warn("Failed to record deployment event", map[string]interface{}{
+			"namespace":  obj.Namespace,
+			"deployment": obj.Name,
+			"event":      eventType,
+			"error":      err.Error(),
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "events.db")
+	store, err := OpenEventStore(path, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("OpenEventStore() error = %v", err)
+	}
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	scaled := []kubernetes.DeploymentChange{{Type: "scale", Field: "replicas", OldValue: 2, NewValue: 3, Description: "Replicas changed from 2 to 3"}}
+	for _, event := range []Event{
+		{Time: now.Add(-48 * time.Hour), Type: EventAdded, Namespace: "shop", Name: "web"},
+		{Time: now.Add(-2 * time.Hour), Type: EventAdded, Namespace: "shop", Name: "api"},
+		{Time: now.Add(-time.Hour), Type: EventUpdated, Namespace: "shop", Name: "web", Changes: scaled},
+		// Events recorded at the same time are all kept
+		{Time: now.Add(-time.Hour), Type: EventDeleted, Namespace: "store", Name: "web"},
+		{Type: EventDeleted, Cluster: "eu", Namespace: "shop", Name: "web"},
+	} {
+		if err := store.Record(event); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	events, err := store.Query(EventQuery{Namespace: "shop", Name: "web"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(events) != 3 || events[0].Type != EventDeleted || events[0].Cluster != "eu" || !events[0].Time.Equal(now) {
+		t.Fatalf("Expected the 3 events of shop/web, most recent first, got %+v", events)
+	}
+	if len(events[1].Changes) != 1 || events[1].Changes[0].Description != "Replicas changed from 2 to 3" {
+		t.Errorf("Expected the recorded changes, got %+v", events[1].Changes)
+	}
+
+	tests := []struct {
+		name  string
+		query EventQuery
+		want  int
+	}{
+		{"all", EventQuery{}, 5},
+		{"since", EventQuery{Since: now.Add(-time.Hour)}, 3},
+		{"name in every namespace", EventQuery{Name: "web", Since: now.Add(-time.Hour)}, 3},
+		{"limit", EventQuery{Limit: 2}, 2},
+		{"no match", EventQuery{Namespace: "billing"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := store.Query(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != tt.want {
+				t.Errorf("Expected %d events, got %d: %+v", tt.want, len(events), events)
+			}
+		})
+	}
+
+	pruned, err := store.Prune()
+	if err != nil || pruned != 1 {
+		t.Fatalf("Expected the event past retention to be pruned, got %d, %v", pruned, err)
+	}
+	if events, _ := store.Query(EventQuery{}); len(events) != 4 {
+		t.Errorf("Expected 4 events after pruning, got %d", len(events))
+	}
+
+	// The database can't be opened while the store holds it
+	if _, err := OpenEventStoreReadOnly(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked while the store is open, got %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	readOnly, err := OpenEventStoreReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenEventStoreReadOnly() error = %v", err)
+	}
+	defer readOnly.Close()
+	if events, _ := readOnly.Query(EventQuery{}); len(events) != 4 {
+		t.Errorf("Expected the events to persist, got %d", len(events))
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2h", now.Add(-2 * time.Hour), false},
+		{"2025-07-01T09:30:00Z", time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC), false},
+		{"-1h", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.value, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestInformerHandler(t *testing.T) {
+	store, err := OpenEventStore(filepath.Join(t.TempDir(), "events.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	handler := InformerHandler(store, "eu")
+	existing := newDeployment("shop", "existing")
+	existing.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	web := newDeployment("shop", "web")
+	web.CreationTimestamp = metav1.Now()
+	scaled := web.DeepCopy()
+	scaled.Spec.Replicas = int32Ptr(5)
+
+	handler.OnAdd(existing)
+	handler.OnAdd(web)
+	handler.OnUpdate(web, web.DeepCopy())
+	handler.OnUpdate(web, scaled)
+	handler.OnDelete(scaled)
+
+	events, err := store.Query(EventQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %+v", events)
+	}
+	for i, want := range []string{EventDeleted, EventUpdated, EventAdded} {
+		if events[i].Type != want || events[i].Name != "web" || events[i].Cluster != "eu" {
+			t.Errorf("Expected event %d to be web %s, got %+v", i, want, events[i])
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// samplesBucket holds deployment samples keyed by namespace/name, a zero
+// byte and their time, so that the samples of a deployment are contiguous
+// and sort chronologically
+var samplesBucket = []byte("samples")
+
+// Sample is the replica count and pod restarts of a deployment at a point
+// of its time series
+type Sample struct {
+	Time      time.Time `json:"time"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+
+	// Desired and available replicas
+	Replicas  int32 `json:"replicas"`
+	Available int32 `json:"available"`
+
+	// Container restarts of the deployment's pods since the previous sample
+	Restarts int32 `json:"restarts"`
+}
+
+// SampleQuery selects recorded samples. Empty fields match every sample.
+type SampleQuery struct {
+	Namespace string
+	Name      string
+	Since     time.Time
+}
+
+// RecordSamples stores samples, timestamped now when they have no time
+func (s *EventStore) RecordSamples(samples []Sample) error {
+	now := s.now()
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(samplesBucket)
+		for _, sample := range samples {
+			if sample.Time.IsZero() {
+				sample.Time = now
+			}
+			sample.Time = sample.Time.UTC()
+			value, err := json.Marshal(sample)
+			if err != nil {
+				return fmt.Errorf("failed to encode sample: %w", err)
+			}
+			if err := bucket.Put(sampleKey(sample.Namespace, sample.Name, sample.Time), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Samples returns the samples matching q, oldest first for each deployment,
+// deployments sorted by namespace and name
+func (s *EventStore) Samples(q SampleQuery) ([]Sample, error) {
+	samples := []Sample{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(samplesBucket)
+		if bucket == nil {
+			return nil
+		}
+		var prefix []byte
+		if q.Namespace != "" && q.Name != "" {
+			prefix = []byte(q.Namespace + "/" + q.Name + "\x00")
+		} else if q.Namespace != "" {
+			prefix = []byte(q.Namespace + "/")
+		}
+
+		c := bucket.Cursor()
+		for key, value := c.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = c.Next() {
+			if !q.Since.IsZero() && sampleTime(key).Before(q.Since) {
+				continue
+			}
+			var sample Sample
+			if err := json.Unmarshal(value, &sample); err != nil {
+				return fmt.Errorf("failed to decode sample: %w", err)
+			}
+			if q.Name != "" && sample.Name != q.Name {
+				continue
+			}
+			samples = append(samples, sample)
+		}
+		return nil
+	})
+	return samples, err
+}
+
+// pruneSamples removes the samples older than cutoff and returns how many
+func pruneSamples(tx *bolt.Tx, cutoff time.Time) (int, error) {
+	bucket := tx.Bucket(samplesBucket)
+	if bucket == nil {
+		return 0, nil
+	}
+	var expired [][]byte
+	c := bucket.Cursor()
+	for key, _ := c.First(); key != nil; key, _ = c.Next() {
+		if sampleTime(key).Before(cutoff) {
+			expired = append(expired, append([]byte(nil), key...))
+		}
+	}
+	for _, key := range expired {
+		if err := bucket.Delete(key); err != nil {
+			return 0, err
+		}
+	}
+	return len(expired), nil
+}
+
+// sampleKey returns the key of a sample: namespace/name, a zero byte and
+// its time in nanoseconds, big-endian
+func sampleKey(namespace, name string, t time.Time) []byte {
+	key := make([]byte, 0, len(namespace)+len(name)+10)
+	key = append(key, namespace+"/"+name+"\x00"...)
+	return binary.BigEndian.AppendUint64(key, uint64(t.UnixNano()))
+}
+
+// sampleTime returns the time of a sample key
+func sampleTime(key []byte) time.Time {
+	if len(key) < 8 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[len(key)-8:])))
+}
@@ -0,0 +1,68 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSamples(t *testing.T) {
+	store, err := OpenEventStore(filepath.Join(t.TempDir(), "events.db"), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("OpenEventStore() error = %v", err)
+	}
+	defer store.Close()
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	if err := store.RecordSamples([]Sample{
+		{Time: now.Add(-48 * time.Hour), Namespace: "shop", Name: "web", Replicas: 3, Available: 3},
+		{Time: now.Add(-2 * time.Minute), Namespace: "shop", Name: "web", Replicas: 3, Available: 3},
+		{Time: now.Add(-time.Minute), Namespace: "shop", Name: "web", Replicas: 3, Available: 2, Restarts: 4},
+		{Namespace: "shop", Name: "web", Replicas: 0},
+		// A namespace sharing the prefix of another
+		{Namespace: "shop-eu", Name: "web", Replicas: 1, Available: 1},
+		{Namespace: "store", Name: "web", Replicas: 1, Available: 1},
+		{Namespace: "shop", Name: "webhook", Replicas: 1, Available: 1},
+	}); err != nil {
+		t.Fatalf("RecordSamples() error = %v", err)
+	}
+
+	samples, err := store.Samples(SampleQuery{Namespace: "shop", Name: "web", Since: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Samples() error = %v", err)
+	}
+	if len(samples) != 3 || samples[1].Restarts != 4 || samples[2].Replicas != 0 || !samples[2].Time.Equal(now) {
+		t.Fatalf("Expected the last 3 samples of shop/web, oldest first, got %+v", samples)
+	}
+
+	tests := []struct {
+		name  string
+		query SampleQuery
+		want  int
+	}{
+		{"all", SampleQuery{}, 7},
+		{"namespace", SampleQuery{Namespace: "shop"}, 5},
+		{"name in every namespace", SampleQuery{Name: "web", Since: now}, 3},
+		{"no match", SampleQuery{Namespace: "billing"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples, err := store.Samples(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(samples) != tt.want {
+				t.Errorf("Expected %d samples, got %d: %+v", tt.want, len(samples), samples)
+			}
+		})
+	}
+
+	pruned, err := store.Prune()
+	if err != nil || pruned != 1 {
+		t.Fatalf("Expected the sample past retention to be pruned, got %d, %v", pruned, err)
+	}
+	if samples, _ := store.Samples(SampleQuery{}); len(samples) != 6 {
+		t.Errorf("Expected 6 samples after pruning, got %d", len(samples))
+	}
+}
@@ -0,0 +1,248 @@
+// Package history stores manifest snapshots of deployments deleted through
+// k6s, forming a recycle bin they can be undeleted from within a retention
+// window.
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// revisionAnnotation is maintained by the deployment controller and must not
+// be carried over to a recreated deployment
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// clusterAnnotation records the cluster a snapshot was deleted from. It is
+// only kept in the snapshot and dropped when the deployment is loaded.
+const clusterAnnotation = "k6s.io/deleted-from"
+
+// ErrNotFound is returned when no snapshot is available for a deployment
+var ErrNotFound = errors.New("no deleted deployment snapshot found")
+
+// Entry identifies a snapshot of a deleted deployment
+type Entry struct {
+	Namespace string
+	Name      string
+	DeletedAt time.Time
+	ExpiresAt time.Time
+
+	// Cluster the deployment was deleted from, empty when not recorded
+	Cluster string
+
+	path string
+}
+
+// Store keeps snapshots on disk as <dir>/deleted/<namespace>/<name>/<unix-nanos>.yaml
+type Store struct {
+	dir       string
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewStore creates a store in dir (DefaultDir when empty) keeping snapshots
+// for the retention period
+func NewStore(dir string, retention time.Duration) *Store {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	return &Store{
+		dir:       dir,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// DefaultDir returns the default history directory, ~/.k6s/history
+func DefaultDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".k6s", "history")
+	}
+	return filepath.Join(homeDir, ".k6s", "history")
+}
+
+// SaveDeleted snapshots the manifest of a deployment of cluster about to be
+// deleted
+func (s *Store) SaveDeleted(cluster string, dep *appsv1.Deployment) (Entry, error) {
+	dir, err := s.deploymentDir(dep.Namespace, dep.Name)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	manifest := snapshotManifest(dep)
+	if cluster != "" {
+		if manifest.Annotations == nil {
+			manifest.Annotations = map[string]string{}
+		}
+		manifest.Annotations[clusterAnnotation] = cluster
+	}
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to marshal deployment manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Entry{}, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	deletedAt := s.now()
+	entry := s.entry(dep.Namespace, dep.Name, deletedAt, filepath.Join(dir, strconv.FormatInt(deletedAt.UnixNano(), 10)+".yaml"))
+	entry.Cluster = cluster
+	if err := os.WriteFile(entry.path, data, 0600); err != nil {
+		return Entry{}, fmt.Errorf("failed to write deployment snapshot: %w", err)
+	}
+	return entry, nil
+}
+
+// Deleted returns the snapshots within the retention window, newest first.
+// Expired snapshots are pruned along the way.
+func (s *Store) Deleted() ([]Entry, error) {
+	root := filepath.Join(s.dir, "deleted")
+	paths, err := filepath.Glob(filepath.Join(root, "*", "*", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	entries := make([]Entry, 0, len(paths))
+	for _, path := range paths {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		nanos, err := strconv.ParseInt(strings.TrimSuffix(parts[2], ".yaml"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		entry := s.entry(parts[0], parts[1], time.Unix(0, nanos), path)
+		if !now.Before(entry.ExpiresAt) {
+			_ = os.Remove(path)
+			continue
+		}
+		entry.Cluster = snapshotCluster(path)
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// LatestDeleted returns the newest snapshot of a deployment within the retention window
+func (s *Store) LatestDeleted(namespace, name string) (Entry, error) {
+	if _, err := s.deploymentDir(namespace, name); err != nil {
+		return Entry{}, err
+	}
+
+	entries, err := s.Deleted()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, entry := range entries {
+		if entry.Namespace == namespace && entry.Name == name {
+			return entry, nil
+		}
+	}
+	return Entry{}, fmt.Errorf("%w for %s/%s within %v", ErrNotFound, namespace, name, s.retention)
+}
+
+// Load returns the deployment manifest of a snapshot, ready to be created again
+func (s *Store) Load(entry Entry) (*appsv1.Deployment, error) {
+	data, err := os.ReadFile(entry.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployment snapshot: %w", err)
+	}
+
+	dep := &appsv1.Deployment{}
+	if err := yaml.UnmarshalStrict(data, dep); err != nil {
+		return nil, fmt.Errorf("failed to parse deployment snapshot: %w", err)
+	}
+	delete(dep.Annotations, clusterAnnotation)
+	if len(dep.Annotations) == 0 {
+		dep.Annotations = nil
+	}
+	return dep, nil
+}
+
+// snapshotCluster returns the cluster recorded in a snapshot file, or "" when
+// it records none or cannot be read
+func snapshotCluster(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var snapshot metav1.PartialObjectMetadata
+	if err := yaml.Unmarshal(data, &snapshot); err != nil {
+		return ""
+	}
+	return snapshot.Annotations[clusterAnnotation]
+}
+
+// Remove deletes a snapshot, e.g. once the deployment has been undeleted
+func (s *Store) Remove(entry Entry) error {
+	if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove deployment snapshot: %w", err)
+	}
+	return nil
+}
+
+// entry builds the entry of a snapshot file
+func (s *Store) entry(namespace, name string, deletedAt time.Time, path string) Entry {
+	return Entry{
+		Namespace: namespace,
+		Name:      name,
+		DeletedAt: deletedAt,
+		ExpiresAt: deletedAt.Add(s.retention),
+		path:      path,
+	}
+}
+
+// deploymentDir returns the snapshot directory of a deployment, rejecting
+// names that are not valid Kubernetes names and could escape the store
+func (s *Store) deploymentDir(namespace, name string) (string, error) {
+	for _, value := range []string{namespace, name} {
+		if errs := validation.IsDNS1123Subdomain(value); len(errs) > 0 {
+			return "", fmt.Errorf("invalid deployment reference %q: %s", value, errs[0])
+		}
+	}
+	return filepath.Join(s.dir, "deleted", namespace, name), nil
+}
+
+// snapshotManifest returns a copy of the deployment without server-populated
+// fields, so that it can be created again as is
+func snapshotManifest(dep *appsv1.Deployment) *appsv1.Deployment {
+	manifest := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dep.Name,
+			Namespace: dep.Namespace,
+			Labels:    dep.Labels,
+		},
+		Spec: *dep.Spec.DeepCopy(),
+	}
+
+	for key, value := range dep.Annotations {
+		if key == revisionAnnotation {
+			continue
+		}
+		if manifest.Annotations == nil {
+			manifest.Annotations = make(map[string]string, len(dep.Annotations))
+		}
+		manifest.Annotations[key] = value
+	}
+	return manifest
+}
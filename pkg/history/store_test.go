@@ -0,0 +1,89 @@
+package history
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func newDeployment(namespace, name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			UID:             "8d1c6a4e",
+			ResourceVersion: "42",
+			Labels:          map[string]string{"app": name},
+			Annotations:     map[string]string{revisionAnnotation: "3", "team": "web"},
+		},
+		Spec:   appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 3},
+	}
+}
+
+func TestStore(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	store := NewStore(t.TempDir(), 24*time.Hour)
+	store.now = func() time.Time { return now }
+
+	if _, err := store.SaveDeleted("prod-eu", newDeployment("prod", "web")); err != nil {
+		t.Fatalf("SaveDeleted() error = %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := store.SaveDeleted("", newDeployment("prod", "api")); err != nil {
+		t.Fatalf("SaveDeleted() error = %v", err)
+	}
+
+	entries, err := store.Deleted()
+	if err != nil {
+		t.Fatalf("Deleted() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "api" || entries[1].Name != "web" {
+		t.Fatalf("Expected api then web, got %+v", entries)
+	}
+	if entries[0].Cluster != "" || entries[1].Cluster != "prod-eu" {
+		t.Errorf("Expected web to record prod-eu and api no cluster, got %q and %q", entries[1].Cluster, entries[0].Cluster)
+	}
+
+	entry, err := store.LatestDeleted("prod", "web")
+	if err != nil {
+		t.Fatalf("LatestDeleted() error = %v", err)
+	}
+	dep, err := store.Load(entry)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if dep.UID != "" || dep.ResourceVersion != "" || dep.Status.ReadyReplicas != 0 {
+		t.Errorf("Expected server-populated fields to be dropped, got %+v", dep.ObjectMeta)
+	}
+	if *dep.Spec.Replicas != 3 || dep.Labels["app"] != "web" || dep.Annotations["team"] != "web" {
+		t.Errorf("Expected spec, labels and annotations to be kept, got %+v", dep)
+	}
+	if _, exists := dep.Annotations[revisionAnnotation]; exists {
+		t.Error("Expected the revision annotation to be dropped")
+	}
+	if _, exists := dep.Annotations[clusterAnnotation]; exists {
+		t.Error("Expected the cluster annotation not to be restored")
+	}
+
+	// Snapshots expire after the retention window
+	now = now.Add(23*time.Hour + time.Minute)
+	if _, err := store.LatestDeleted("prod", "web"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an expired snapshot, got %v", err)
+	}
+	if err := store.Remove(entries[0]); err != nil {
+		t.Errorf("Remove() error = %v", err)
+	}
+	if entries, _ := store.Deleted(); len(entries) != 0 {
+		t.Errorf("Expected no snapshots left, got %+v", entries)
+	}
+
+	if _, err := store.LatestDeleted("prod", "../etc"); err == nil {
+		t.Error("Expected an invalid name to be rejected")
+	}
+}
@@ -2,34 +2,60 @@ package kubernetes
 
 import (
 	"fmt"
-	"path/filepath"
+	"sort"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
 )
 
 // Client wraps kubernetes client with helper methods
 type Client struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
 }
 
 // NewClient creates a new Kubernetes client from kubeconfig
 func NewClient(kubeconfig string) (*Client, error) {
-	// Build kubeconfig path
-	if kubeconfig == "" {
-		if home := homedir.HomeDir(); home != "" {
-			kubeconfig = filepath.Join(home, ".kube", "config")
-		}
+	config, err := RestConfig(kubeconfig)
+	if err != nil {
+		return nil, err
 	}
 
-	// Load kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	return NewClientForConfig(config)
+}
+
+// NewClientWithConfig creates a new Kubernetes client from kubeconfig, tuned
+// by cfg
+func NewClientWithConfig(kubeconfig string, cfg config.KubeClientConfig) (*Client, error) {
+	restConfig, err := RestConfig(kubeconfig)
 	if err != nil {
-		return nil, fmt.Errorf("error loading kubeconfig: %w", err)
+		return nil, err
+	}
+	ApplyClientConfig(restConfig, cfg)
+
+	return NewClientForConfig(restConfig)
+}
+
+// ApplyClientConfig applies the rate limits and content type of cfg to a
+// REST config. With the protobuf content type, responses that can't be
+// encoded as protobuf, such as custom resources, still arrive as JSON.
+func ApplyClientConfig(restConfig *rest.Config, cfg config.KubeClientConfig) {
+	if cfg.QPS > 0 {
+		restConfig.QPS = cfg.QPS
 	}
+	if cfg.Burst > 0 {
+		restConfig.Burst = cfg.Burst
+	}
+	if cfg.ContentType == "protobuf" {
+		restConfig.ContentType = runtime.ContentTypeProtobuf
+		restConfig.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
+	}
+}
 
-	// Create Kubernetes client
+// NewClientForConfig creates a new Kubernetes client from a REST config
+func NewClientForConfig(config *rest.Config) (*Client, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("error creating kubernetes client: %w", err)
@@ -38,6 +64,72 @@ func NewClient(kubeconfig string) (*Client, error) {
 	return &Client{clientset: clientset}, nil
 }
 
+// NewClientWithClientset creates a new Kubernetes client from an existing clientset
+func NewClientWithClientset(clientset kubernetes.Interface) *Client {
+	return &Client{clientset: clientset}
+}
+
+// RestConfig resolves the API server configuration used by every k6s command.
+// An explicit kubeconfig wins; otherwise the in-cluster config is used when
+// running in a pod, then KUBECONFIG or ~/.kube/config.
+func RestConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("error loading kubeconfig %s: %w", kubeconfig, err)
+		}
+		return config, nil
+	}
+
+	if config, err := rest.InClusterConfig(); err == nil {
+		return config, nil
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
+// CurrentContext returns the kubeconfig context RestConfig connects with, or
+// "" when it uses the in-cluster config or the kubeconfig cannot be read
+func CurrentContext(kubeconfig string) string {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	} else if _, err := rest.InClusterConfig(); err == nil {
+		return ""
+	}
+
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return ""
+	}
+	return raw.CurrentContext
+}
+
+// Contexts returns the sorted context names of kubeconfig, or of KUBECONFIG
+// or ~/.kube/config when it is empty, and nil when they cannot be read
+func Contexts(kubeconfig string) []string {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return nil
+	}
+	contexts := make([]string, 0, len(raw.Contexts))
+	for name := range raw.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts
+}
+
 // Clientset returns the underlying kubernetes clientset
 func (c *Client) Clientset() kubernetes.Interface {
 	return c.clientset
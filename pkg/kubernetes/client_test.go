@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"k8s.io/client-go/rest"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://test.example:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abc
+`
+
+func TestRestConfig(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+
+	config, err := RestConfig(path)
+	if err != nil {
+		t.Fatalf("RestConfig() error = %v", err)
+	}
+	if config.Host != "https://test.example:6443" {
+		t.Errorf("Expected host from explicit kubeconfig, got %s", config.Host)
+	}
+
+	t.Setenv("KUBECONFIG", path)
+	config, err = RestConfig("")
+	if err != nil {
+		t.Fatalf("RestConfig() error = %v", err)
+	}
+	if config.Host != "https://test.example:6443" {
+		t.Errorf("Expected host from KUBECONFIG, got %s", config.Host)
+	}
+
+	if _, err := RestConfig(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing kubeconfig")
+	}
+}
+
+func TestContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+
+	if contexts := Contexts(path); len(contexts) != 1 || contexts[0] != "test" {
+		t.Errorf("Contexts() = %v, want [test]", contexts)
+	}
+	t.Setenv("KUBECONFIG", path)
+	if contexts := Contexts(""); len(contexts) != 1 || contexts[0] != "test" {
+		t.Errorf("Expected the contexts of KUBECONFIG, got %v", contexts)
+	}
+	if contexts := Contexts(filepath.Join(t.TempDir(), "missing")); contexts != nil {
+		t.Errorf("Expected no contexts for a missing kubeconfig, got %v", contexts)
+	}
+}
+
+func TestApplyClientConfig(t *testing.T) {
+	restConfig := &rest.Config{QPS: 5, Burst: 10}
+	ApplyClientConfig(restConfig, config.KubeClientConfig{QPS: 50, Burst: 100, ContentType: "protobuf"})
+	if restConfig.QPS != 50 || restConfig.Burst != 100 {
+		t.Errorf("Expected QPS 50 and burst 100, got %v and %d", restConfig.QPS, restConfig.Burst)
+	}
+	if restConfig.ContentType != "application/vnd.kubernetes.protobuf" {
+		t.Errorf("Expected the protobuf content type, got %q", restConfig.ContentType)
+	}
+	if restConfig.AcceptContentTypes != "application/vnd.kubernetes.protobuf,application/json" {
+		t.Errorf("Expected JSON to be accepted as a fallback, got %q", restConfig.AcceptContentTypes)
+	}
+
+	// Unset limits and the JSON content type keep the client defaults
+	restConfig = &rest.Config{QPS: 5, Burst: 10}
+	ApplyClientConfig(restConfig, config.KubeClientConfig{ContentType: "json"})
+	if restConfig.QPS != 5 || restConfig.Burst != 10 || restConfig.ContentType != "" {
+		t.Errorf("Expected the client defaults, got %+v", restConfig)
+	}
+}
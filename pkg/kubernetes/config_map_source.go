@@ -0,0 +1,151 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultConfigMapKey is the key of the configuration file in a ConfigMap
+const DefaultConfigMapKey = "k6s.yaml"
+
+// inClusterNamespaceFile holds the namespace of the pod in-cluster
+const inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// ConfigMapSource reads the configuration file from a key of a ConfigMap and
+// watches the ConfigMap for changes
+type ConfigMapSource struct {
+	clientset kubernetes.Interface
+	namespace string
+	name      string
+	key       string
+}
+
+// NewConfigMapSource creates a source reading key ("" = k6s.yaml) of the
+// ConfigMap namespace/name
+func NewConfigMapSource(clientset kubernetes.Interface, namespace, name, key string) *ConfigMapSource {
+	if key == "" {
+		key = DefaultConfigMapKey
+	}
+	return &ConfigMapSource{
+		clientset: clientset,
+		namespace: namespace,
+		name:      name,
+		key:       key,
+	}
+}
+
+// ParseConfigMapRef splits a NAMESPACE/NAME reference to a ConfigMap. A bare
+// NAME is in the namespace of the pod when running in-cluster, and in the
+// default namespace otherwise.
+func ParseConfigMapRef(ref string) (namespace, name string, err error) {
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found {
+		namespace, name = InClusterNamespace(), ref
+	}
+	if namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid ConfigMap %q, expected NAMESPACE/NAME or NAME", ref)
+	}
+	return namespace, name, nil
+}
+
+// InClusterNamespace returns the namespace of the pod when running
+// in-cluster, and "default" otherwise
+func InClusterNamespace() string {
+	data, err := os.ReadFile(inClusterNamespaceFile)
+	if err != nil {
+		return metav1.NamespaceDefault
+	}
+	if namespace := strings.TrimSpace(string(data)); namespace != "" {
+		return namespace
+	}
+	return metav1.NamespaceDefault
+}
+
+// String describes the source in logs
+func (s *ConfigMapSource) String() string {
+	return fmt.Sprintf("ConfigMap %s/%s key %s", s.namespace, s.name, s.key)
+}
+
+// Read returns the configuration file held by the ConfigMap
+func (s *ConfigMapSource) Read(ctx context.Context) ([]byte, error) {
+	cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return s.data(cm)
+}
+
+// data returns the configuration file of cm
+func (s *ConfigMapSource) data(cm *corev1.ConfigMap) ([]byte, error) {
+	if data, ok := cm.Data[s.key]; ok {
+		return []byte(data), nil
+	}
+	if data, ok := cm.BinaryData[s.key]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("ConfigMap %s/%s has no key %s", s.namespace, s.name, s.key)
+}
+
+// Watch calls changed when the configuration file of the ConfigMap changes,
+// or the ConfigMap is deleted or created again, until ctx is done. Only this
+// ConfigMap is listed and watched, so reading it needs no access to other
+// ConfigMaps.
+func (s *ConfigMapSource) Watch(ctx context.Context, changed func()) error {
+	selector := fields.OneTermEqualSelector("metadata.name", s.name).String()
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return s.clientset.CoreV1().ConfigMaps(s.namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return s.clientset.CoreV1().ConfigMaps(s.namespace).Watch(ctx, options)
+		},
+	}, &corev1.ConfigMap{}, 0, cache.Indexers{})
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		// The initial list also adds the ConfigMap, catching changes made
+		// since it was read
+		AddFunc: func(obj interface{}) {
+			changed()
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldCM, okOld := oldObj.(*corev1.ConfigMap)
+			newCM, okNew := newObj.(*corev1.ConfigMap)
+			if !okOld || !okNew || !s.sameData(oldCM, newCM) {
+				changed()
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			changed()
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add ConfigMap event handler: %w", err)
+	}
+
+	go informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to sync ConfigMap %s/%s", s.namespace, s.name)
+	}
+	return nil
+}
+
+// sameData reports whether two versions of the ConfigMap hold the same
+// configuration file, so that changes of other keys or metadata are ignored
+func (s *ConfigMapSource) sameData(oldCM, newCM *corev1.ConfigMap) bool {
+	oldData, oldErr := s.data(oldCM)
+	newData, newErr := s.data(newCM)
+	return (oldErr == nil) == (newErr == nil) && bytes.Equal(oldData, newData)
+}
@@ -0,0 +1,94 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigMapSource(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "k6s", Name: "k6s-config"},
+		Data:       map[string]string{DefaultConfigMapKey: "log_level: info\n"},
+	}
+	clientset := fake.NewSimpleClientset(cm)
+	source := NewConfigMapSource(clientset, "k6s", "k6s-config", "")
+
+	reloader, err := config.NewSourceReloader(source)
+	if err != nil {
+		t.Fatalf("NewSourceReloader() error = %v", err)
+	}
+	if reloader.Current().LogLevel != "info" || reloader.Path() != "" {
+		t.Fatalf("Expected the configuration of the ConfigMap, got log level %q and path %q", reloader.Current().LogLevel, reloader.Path())
+	}
+	if want := "ConfigMap k6s/k6s-config key k6s.yaml"; reloader.Name() != want {
+		t.Errorf("Name() = %q, want %q", reloader.Name(), want)
+	}
+
+	changes := make(chan [2]string, 4)
+	reloader.Subscribe(func(oldConfig, newConfig *config.Config) {
+		if oldConfig.LogLevel != newConfig.LogLevel {
+			changes <- [2]string{oldConfig.LogLevel, newConfig.LogLevel}
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := reloader.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	update := func(data map[string]string) {
+		cm = cm.DeepCopy()
+		cm.Data = data
+		if _, err := clientset.CoreV1().ConfigMaps("k6s").Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	update(map[string]string{DefaultConfigMapKey: "log_level: debug\n"})
+	select {
+	case change := <-changes:
+		if change != [2]string{"info", "debug"} {
+			t.Errorf("Unexpected change %v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reload")
+	}
+
+	// An invalid configuration is rejected and the previous one kept
+	update(map[string]string{DefaultConfigMapKey: "log_level: loud\n"})
+	select {
+	case change := <-changes:
+		t.Errorf("Expected invalid config to be rejected, got %v", change)
+	case <-time.After(500 * time.Millisecond):
+	}
+	if reloader.Current().LogLevel != "debug" {
+		t.Errorf("Expected previous config to stay in effect, got %q", reloader.Current().LogLevel)
+	}
+
+	update(map[string]string{"other.yaml": "log_level: warn\n"})
+	if err := reloader.Reload(); err == nil {
+		t.Error("Expected Reload() to fail without the configuration key")
+	}
+}
+
+func TestParseConfigMapRef(t *testing.T) {
+	namespace, name, err := ParseConfigMapRef("k6s/k6s-config")
+	if err != nil || namespace != "k6s" || name != "k6s-config" {
+		t.Errorf("ParseConfigMapRef() = %q, %q, %v", namespace, name, err)
+	}
+	if _, name, err := ParseConfigMapRef("k6s-config"); err != nil || name != "k6s-config" {
+		t.Errorf("Expected a bare name to be accepted, got %q, %v", name, err)
+	}
+	for _, ref := range []string{"", "k6s/", "/k6s-config", "a/b/c"} {
+		if _, _, err := ParseConfigMapRef(ref); err == nil {
+			t.Errorf("Expected %q to be rejected", ref)
+		}
+	}
+}
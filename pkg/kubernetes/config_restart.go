@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// RestartOnConfigChangeAnnotation opts a deployment into a rollout
+	// restart when the data of a ConfigMap or Secret it references changes
+	RestartOnConfigChangeAnnotation = "k6s.io/restart-on-config-change"
+
+	// configRestartedAtAnnotation is set on the pod template to restart a
+	// deployment after a config change, like restartedAtAnnotation
+	configRestartedAtAnnotation = "k6s.io/config-restarted-at"
+
+	// configRestartPatchTimeout bounds the patch restarting one deployment
+	configRestartPatchTimeout = 10 * time.Second
+)
+
+// RestartsOnConfigChange reports whether a deployment is opted into rollout
+// restarts on config changes
+func RestartsOnConfigChange(dep *appsv1.Deployment) bool {
+	return dep.Annotations[RestartOnConfigChangeAnnotation] == "true"
+}
+
+// ConfigRestarter restarts the deployments annotated with
+// RestartOnConfigChangeAnnotation=true when a ConfigMap or Secret they
+// reference changes, by stamping their pod template. It implements
+// ConfigChangeHandler and finds the deployments through the config source
+// index of the deployment cache.
+type ConfigRestarter struct {
+	clientset   kubernetes.Interface
+	deployments *DeploymentInformer
+}
+
+// NewConfigRestarter creates a restarter patching deployments with clientset
+func NewConfigRestarter(clientset kubernetes.Interface, deployments *DeploymentInformer) *ConfigRestarter {
+	return &ConfigRestarter{clientset: clientset, deployments: deployments}
+}
+
+// OnConfigChange restarts the opted-in deployments referencing a changed
+// ConfigMap or Secret. Deletions don't restart deployments, since new pods
+// could not start without a required config source; paused deployments are
+// skipped.
+func (r *ConfigRestarter) OnConfigChange(change ConfigChange) {
+	if change.Deleted {
+		return
+	}
+	deployments, err := r.deployments.DeploymentsReferencing(change.Kind, change.Namespace, change.Name)
+	if err != nil {
+		informerLog().Warn().
+			Err(err).
+			Str("kind", change.Kind).
+			Str("namespace", change.Namespace).
+			Str("name", change.Name).
+			Msg("Failed to look up deployments referencing changed config")
+		return
+	}
+
+	for _, dep := range deployments {
+		if !RestartsOnConfigChange(dep) {
+			continue
+		}
+		if dep.Spec.Paused {
+			informerLog().Info().
+				Str("namespace", dep.Namespace).
+				Str("name", dep.Name).
+				Str("config", change.Kind+"/"+change.Name).
+				Msg("Skipping config change restart of paused deployment")
+			continue
+		}
+		if err := r.restart(dep, change); err != nil {
+			informerLog().Error().
+				Err(err).
+				Str("namespace", dep.Namespace).
+				Str("name", dep.Name).
+				Str("config", change.Kind+"/"+change.Name).
+				Msg("Failed to restart deployment after config change")
+			continue
+		}
+		informerLog().Info().
+			Str("namespace", dep.Namespace).
+			Str("name", dep.Name).
+			Str("config", change.Kind+"/"+change.Name).
+			Strs("keys", change.Keys).
+			Msg("Restarted deployment after config change")
+	}
+}
+
+// restart stamps the pod template of a deployment, recording the config
+// change as the change cause shown in its rollout history
+func (r *ConfigRestarter) restart(dep *appsv1.Deployment, change ConfigChange) error {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				changeCauseAnnotation: fmt.Sprintf("%s %s changed (keys: %s)", change.Kind, change.Name, strings.Join(change.Keys, ", ")),
+			},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{configRestartedAtAnnotation: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configRestartPatchTimeout)
+	defer cancel()
+	_, err = r.clientset.AppsV1().Deployments(dep.Namespace).Patch(ctx, dep.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{})
+	return err
+}
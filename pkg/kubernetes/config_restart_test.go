@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigRestarter(t *testing.T) {
+	deployment := func(name string, annotations map[string]string) *appsv1.Deployment {
+		replicas := int32(1)
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Annotations: annotations}}
+		dep.Spec.Replicas = &replicas
+		dep.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name:    name,
+			EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}}}},
+		}}
+		return dep
+	}
+	optedIn := deployment("web", map[string]string{RestartOnConfigChangeAnnotation: "true"})
+	notOptedIn := deployment("worker", nil)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "shop"},
+		Data:       map[string]string{"mode": "blue"},
+	}
+	clientset := fake.NewSimpleClientset(optedIn, notOptedIn, cm)
+
+	deployments := NewDeploymentInformer(clientset, "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+
+	// The config source index links the ConfigMap to both deployments
+	referencing, err := deployments.DeploymentsReferencing(KindConfigMap, "shop", "web-config")
+	if err != nil || len(referencing) != 2 {
+		t.Fatalf("DeploymentsReferencing() = %d deployments, %v", len(referencing), err)
+	}
+	if other, _ := deployments.DeploymentsReferencing(KindSecret, "shop", "web-config"); len(other) != 0 {
+		t.Errorf("Expected no deployments referencing a Secret, got %d", len(other))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	configs := NewConfigInformer(clientset, "", time.Minute)
+	configs.AddHandler(NewConfigRestarter(clientset, deployments))
+	if err := configs.Start(ctx); err != nil {
+		t.Fatalf("configs.Start() error = %v", err)
+	}
+	defer configs.Stop()
+
+	cm = cm.DeepCopy()
+	cm.Data["mode"] = "green"
+	if _, err := clientset.CoreV1().ConfigMaps("shop").Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	for {
+		dep, err := clientset.AppsV1().Deployments("shop").Get(ctx, "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if dep.Spec.Template.Annotations[configRestartedAtAnnotation] != "" {
+			if cause := dep.Annotations[changeCauseAnnotation]; cause != "ConfigMap web-config changed (keys: mode)" {
+				t.Errorf("Expected the config change as change cause, got %q", cause)
+			}
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("Expected the opted-in deployment to be restarted")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	worker, err := clientset.AppsV1().Deployments("shop").Get(ctx, "worker", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(worker.Spec.Template.Annotations) != 0 {
+		t.Errorf("Expected the deployment without the annotation not to be restarted, got %v", worker.Spec.Template.Annotations)
+	}
+}
@@ -0,0 +1,439 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Kinds of the config sources deployments reference
+const (
+	KindConfigMap = "ConfigMap"
+	KindSecret    = "Secret"
+)
+
+// ConfigSource is a ConfigMap or Secret referenced by the pod template of a
+// deployment, with where it is referenced from, such as "volume:config",
+// "envFrom", "env:DB_PASSWORD" or "imagePullSecrets"
+type ConfigSource struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	References []string `json:"references"`
+	Optional   bool     `json:"optional"`
+}
+
+// DeploymentConfigSources returns the ConfigMaps and Secrets the pod template
+// of a deployment references, sorted by kind and name. A source is optional
+// only when every reference to it is.
+func DeploymentConfigSources(dep *appsv1.Deployment) []ConfigSource {
+	sources := make(map[string]*ConfigSource)
+	add := func(kind, name, reference string, optional *bool) {
+		if name == "" {
+			return
+		}
+		key := kind + "/" + name
+		source, exists := sources[key]
+		if !exists {
+			source = &ConfigSource{Kind: kind, Name: name, Optional: true}
+			sources[key] = source
+		}
+		source.References = append(source.References, reference)
+		source.Optional = source.Optional && optional != nil && *optional
+	}
+
+	spec := dep.Spec.Template.Spec
+	for _, volume := range spec.Volumes {
+		reference := "volume:" + volume.Name
+		if cm := volume.ConfigMap; cm != nil {
+			add(KindConfigMap, cm.Name, reference, cm.Optional)
+		}
+		if secret := volume.Secret; secret != nil {
+			add(KindSecret, secret.SecretName, reference, secret.Optional)
+		}
+		if projected := volume.Projected; projected != nil {
+			for _, source := range projected.Sources {
+				if cm := source.ConfigMap; cm != nil {
+					add(KindConfigMap, cm.Name, reference, cm.Optional)
+				}
+				if secret := source.Secret; secret != nil {
+					add(KindSecret, secret.Name, reference, secret.Optional)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if cm := envFrom.ConfigMapRef; cm != nil {
+				add(KindConfigMap, cm.Name, "envFrom", cm.Optional)
+			}
+			if secret := envFrom.SecretRef; secret != nil {
+				add(KindSecret, secret.Name, "envFrom", secret.Optional)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if cm := env.ValueFrom.ConfigMapKeyRef; cm != nil {
+				add(KindConfigMap, cm.Name, "env:"+env.Name, cm.Optional)
+			}
+			if secret := env.ValueFrom.SecretKeyRef; secret != nil {
+				add(KindSecret, secret.Name, "env:"+env.Name, secret.Optional)
+			}
+		}
+	}
+
+	for _, pullSecret := range spec.ImagePullSecrets {
+		add(KindSecret, pullSecret.Name, "imagePullSecrets", nil)
+	}
+
+	result := make([]ConfigSource, 0, len(sources))
+	for _, source := range sources {
+		source.References = uniqueStrings(source.References)
+		result = append(result, *source)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// ReferencesConfig reports whether the pod template of a deployment
+// references a ConfigMap or Secret
+func ReferencesConfig(dep *appsv1.Deployment, kind, name string) bool {
+	for _, source := range DeploymentConfigSources(dep) {
+		if source.Kind == kind && source.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueStrings returns values without repetitions, in order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := values[:0]
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// ConfigChange describes a change of the data of a ConfigMap or Secret:
+// the keys added, changed or removed, or its deletion
+type ConfigChange struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Keys      []string `json:"keys,omitempty"`
+	Deleted   bool     `json:"deleted,omitempty"`
+}
+
+// ConfigChangeHandler is notified of ConfigMap and Secret data changes
+type ConfigChangeHandler interface {
+	OnConfigChange(change ConfigChange)
+}
+
+// ConfigChangeHandlerFunc adapts a function to ConfigChangeHandler
+type ConfigChangeHandlerFunc func(change ConfigChange)
+
+// OnConfigChange calls f
+func (f ConfigChangeHandlerFunc) OnConfigChange(change ConfigChange) {
+	f(change)
+}
+
+// ConfigObject is a cached ConfigMap or Secret. Secret values are never
+// cached: the keys of a Secret map to digests of their values.
+type ConfigObject struct {
+	Kind            string    `json:"kind"`
+	Namespace       string    `json:"namespace"`
+	Name            string    `json:"name"`
+	Keys            []string  `json:"keys"`
+	ResourceVersion string    `json:"resourceVersion"`
+	Created         time.Time `json:"created"`
+	LastChanged     time.Time `json:"lastChanged,omitzero"`
+}
+
+// ConfigInformer caches the ConfigMaps and Secrets of a namespace, or of all
+// namespaces, and notifies handlers when their data changes. Secrets are
+// cached redacted.
+type ConfigInformer struct {
+	configMaps cache.SharedIndexInformer
+	secrets    cache.SharedIndexInformer
+	set        *InformerSet
+	startOnce  sync.Once
+	stopOnce   sync.Once
+
+	// Notify handlers of the shared informers' events
+	registrations map[cache.SharedIndexInformer]cache.ResourceEventHandlerRegistration
+
+	mu       sync.RWMutex
+	handlers []ConfigChangeHandler
+	changed  map[string]time.Time // last data change by kind/namespace/name
+}
+
+// NewConfigInformer creates an informer watching the ConfigMaps and Secrets
+// of namespace; an empty namespace watches all namespaces
+func NewConfigInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *ConfigInformer {
+	return NewConfigInformerFor(NewInformerSet(clientset, namespace, resyncPeriod))
+}
+
+// NewConfigInformerFor creates an informer sharing the ConfigMap and Secret
+// watches and caches of set
+func NewConfigInformerFor(set *InformerSet) *ConfigInformer {
+	configMaps := set.informerFor(&corev1.ConfigMap{}, stripConfig, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(set.scoped(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.CoreV1().ConfigMaps(namespace).Watch(context.TODO(), options)
+			},
+		}), &corev1.ConfigMap{}, resyncPeriod, cache.Indexers{})
+	})
+
+	secrets := set.informerFor(&corev1.Secret{}, redactSecret, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(set.scoped(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.CoreV1().Secrets(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.CoreV1().Secrets(namespace).Watch(context.TODO(), options)
+			},
+		}), &corev1.Secret{}, resyncPeriod, cache.Indexers{})
+	})
+
+	ci := &ConfigInformer{
+		configMaps: configMaps,
+		secrets:    secrets,
+		set:        set,
+		changed:    make(map[string]time.Time),
+	}
+	// The informers may be shared, so the handlers are removed on Stop
+	cmRegistration, _ := configMaps.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldCM, ok := oldObj.(*corev1.ConfigMap)
+			newCM, ok2 := newObj.(*corev1.ConfigMap)
+			if ok && ok2 {
+				ci.notifyChanged(KindConfigMap, newCM.ObjectMeta, configMapData(oldCM), configMapData(newCM))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if cm, ok := deletedObject(obj).(*corev1.ConfigMap); ok {
+				ci.notify(ConfigChange{Kind: KindConfigMap, Namespace: cm.Namespace, Name: cm.Name, Deleted: true})
+			}
+		},
+	})
+	secretRegistration, _ := secrets.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSecret, ok := oldObj.(*corev1.Secret)
+			newSecret, ok2 := newObj.(*corev1.Secret)
+			if ok && ok2 {
+				ci.notifyChanged(KindSecret, newSecret.ObjectMeta, oldSecret.Data, newSecret.Data)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if secret, ok := deletedObject(obj).(*corev1.Secret); ok {
+				ci.notify(ConfigChange{Kind: KindSecret, Namespace: secret.Namespace, Name: secret.Name, Deleted: true})
+			}
+		},
+	})
+	ci.registrations = map[cache.SharedIndexInformer]cache.ResourceEventHandlerRegistration{
+		configMaps: cmRegistration,
+		secrets:    secretRegistration,
+	}
+	return ci
+}
+
+// stripConfig drops the managed fields and the last-applied annotation of a
+// ConfigMap
+func stripConfig(obj interface{}) (interface{}, error) {
+	if cm, ok := obj.(*corev1.ConfigMap); ok {
+		cm.ManagedFields = nil
+		delete(cm.Annotations, lastAppliedAnnotation)
+	}
+	return obj, nil
+}
+
+// redactSecret replaces the values of a Secret with their SHA-256 digests,
+// which still tell changed values apart, and drops the last-applied
+// annotation, which holds the values of secrets created with kubectl apply
+func redactSecret(obj interface{}) (interface{}, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return obj, nil
+	}
+	secret.ManagedFields = nil
+	delete(secret.Annotations, lastAppliedAnnotation)
+	for key, value := range secret.Data {
+		digest := sha256.Sum256(value)
+		secret.Data[key] = digest[:]
+	}
+	secret.StringData = nil
+	return secret, nil
+}
+
+// deletedObject unwraps the final state of an object deleted while the
+// watch was down
+func deletedObject(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
+
+// configMapData returns the string and binary data of a ConfigMap together
+func configMapData(cm *corev1.ConfigMap) map[string][]byte {
+	data := make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+	for key, value := range cm.Data {
+		data[key] = []byte(value)
+	}
+	for key, value := range cm.BinaryData {
+		data[key] = value
+	}
+	return data
+}
+
+// changedKeys returns the sorted keys added, changed or removed between two
+// versions of config data
+func changedKeys(oldData, newData map[string][]byte) []string {
+	var keys []string
+	for key, value := range newData {
+		if oldValue, exists := oldData[key]; !exists || !bytes.Equal(oldValue, value) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range oldData {
+		if _, exists := newData[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// notifyChanged notifies handlers of an update that changed the data of a
+// ConfigMap or Secret; resyncs and metadata changes are ignored
+func (ci *ConfigInformer) notifyChanged(kind string, meta metav1.ObjectMeta, oldData, newData map[string][]byte) {
+	keys := changedKeys(oldData, newData)
+	if len(keys) == 0 {
+		return
+	}
+	ci.notify(ConfigChange{Kind: kind, Namespace: meta.Namespace, Name: meta.Name, Keys: keys})
+}
+
+// notify records a change and delivers it to the handlers
+func (ci *ConfigInformer) notify(change ConfigChange) {
+	key := change.Kind + "/" + change.Namespace + "/" + change.Name
+	ci.mu.Lock()
+	if change.Deleted {
+		delete(ci.changed, key)
+	} else {
+		ci.changed[key] = time.Now().UTC()
+	}
+	handlers := ci.handlers
+	ci.mu.Unlock()
+	for _, handler := range handlers {
+		handler.OnConfigChange(change)
+	}
+}
+
+// AddHandler registers a handler notified of ConfigMap and Secret changes
+func (ci *ConfigInformer) AddHandler(handler ConfigChangeHandler) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.handlers = append(ci.handlers, handler)
+}
+
+// Start starts watching ConfigMaps and Secrets and waits until both caches
+// sync or ctx is done; on failure the informer is stopped
+func (ci *ConfigInformer) Start(ctx context.Context) error {
+	ci.startOnce.Do(func() {
+		ci.set.run(ci.configMaps, ci.secrets)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), ci.configMaps.HasSynced, ci.secrets.HasSynced) {
+		ci.Stop()
+		return fmt.Errorf("failed to sync ConfigMap and Secret caches: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching ConfigMaps and Secrets
+func (ci *ConfigInformer) Stop() {
+	ci.stopOnce.Do(func() {
+		for informer, registration := range ci.registrations {
+			if registration != nil {
+				_ = informer.RemoveEventHandler(registration)
+			}
+		}
+		ci.set.release(ci.configMaps, ci.secrets)
+	})
+}
+
+// HasSynced reports whether the ConfigMap and Secret caches have synced
+func (ci *ConfigInformer) HasSynced() bool {
+	return ci.configMaps.HasSynced() && ci.secrets.HasSynced()
+}
+
+// Get returns a cached ConfigMap or Secret, and false when it doesn't exist
+func (ci *ConfigInformer) Get(kind, namespace, name string) (*ConfigObject, bool) {
+	informer := ci.configMaps
+	if kind == KindSecret {
+		informer = ci.secrets
+	}
+	obj, exists, err := informer.GetStore().GetByKey(namespace + "/" + name)
+	if err != nil || !exists {
+		return nil, false
+	}
+
+	var meta metav1.ObjectMeta
+	var data map[string][]byte
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		meta, data = o.ObjectMeta, configMapData(o)
+	case *corev1.Secret:
+		meta, data = o.ObjectMeta, o.Data
+	default:
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ci.mu.RLock()
+	lastChanged := ci.changed[kind+"/"+namespace+"/"+name]
+	ci.mu.RUnlock()
+	return &ConfigObject{
+		Kind:            kind,
+		Namespace:       meta.Namespace,
+		Name:            meta.Name,
+		Keys:            keys,
+		ResourceVersion: meta.ResourceVersion,
+		Created:         meta.CreationTimestamp.UTC(),
+		LastChanged:     lastChanged,
+	}, true
+}
@@ -0,0 +1,127 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeploymentConfigSources(t *testing.T) {
+	optional := true
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}
+	dep.Spec.Template.Spec = corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"},
+			}}},
+			{Name: "certs", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "web-tls"}, Optional: &optional}},
+			}}}},
+		},
+		Containers: []corev1.Container{{
+			Name:    "web",
+			EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}}}},
+			Env: []corev1.EnvVar{
+				{Name: "PLAIN", Value: "1"},
+				{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password",
+				}}},
+			},
+		}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}
+
+	want := []ConfigSource{
+		{Kind: KindConfigMap, Name: "web-config", References: []string{"volume:config", "envFrom"}},
+		{Kind: KindSecret, Name: "db", References: []string{"env:DB_PASSWORD"}},
+		{Kind: KindSecret, Name: "registry", References: []string{"imagePullSecrets"}},
+		{Kind: KindSecret, Name: "web-tls", References: []string{"volume:certs"}, Optional: true},
+	}
+	if got := DeploymentConfigSources(dep); !reflect.DeepEqual(got, want) {
+		t.Errorf("DeploymentConfigSources() = %+v, want %+v", got, want)
+	}
+	if !ReferencesConfig(dep, KindSecret, "db") || ReferencesConfig(dep, KindConfigMap, "db") {
+		t.Error("Expected ReferencesConfig to match kind and name")
+	}
+}
+
+func TestConfigInformer(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "shop"},
+		Data:       map[string]string{"mode": "blue", "level": "info"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "db",
+			Namespace:   "shop",
+			Annotations: map[string]string{lastAppliedAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`},
+		},
+		Data: map[string][]byte{"password": []byte("hunter2")},
+	}
+	clientset := fake.NewSimpleClientset(cm, secret)
+
+	informer := NewConfigInformer(clientset, "", time.Minute)
+	changes := make(chan ConfigChange, 4)
+	informer.AddHandler(ConfigChangeHandlerFunc(func(change ConfigChange) { changes <- change }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	// Secret values and the last-applied annotation are not cached
+	obj, _, _ := informer.secrets.GetStore().GetByKey("shop/db")
+	cached := obj.(*corev1.Secret)
+	if string(cached.Data["password"]) == "hunter2" || cached.Annotations[lastAppliedAnnotation] != "" {
+		t.Errorf("Expected the cached secret to be redacted, got %+v", cached)
+	}
+	if got, exists := informer.Get(KindSecret, "shop", "db"); !exists || !reflect.DeepEqual(got.Keys, []string{"password"}) {
+		t.Errorf("Get() = %+v, %v", got, exists)
+	}
+
+	// A metadata change is not a config change
+	cm = cm.DeepCopy()
+	cm.Labels = map[string]string{"team": "shop"}
+	if _, err := clientset.CoreV1().ConfigMaps("shop").Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	cm = cm.DeepCopy()
+	cm.Data["mode"] = "green"
+	if _, err := clientset.CoreV1().ConfigMaps("shop").Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	select {
+	case change := <-changes:
+		want := ConfigChange{Kind: KindConfigMap, Namespace: "shop", Name: "web-config", Keys: []string{"mode"}}
+		if !reflect.DeepEqual(change, want) {
+			t.Errorf("Expected %+v, got %+v", want, change)
+		}
+	case <-ctx.Done():
+		t.Fatal("Expected a ConfigMap change")
+	}
+	if got, _ := informer.Get(KindConfigMap, "shop", "web-config"); got.LastChanged.IsZero() {
+		t.Error("Expected the last change to be recorded")
+	}
+
+	secret = secret.DeepCopy()
+	secret.Data["password"] = []byte("correct horse")
+	if _, err := clientset.CoreV1().Secrets("shop").Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	select {
+	case change := <-changes:
+		if change.Kind != KindSecret || !reflect.DeepEqual(change.Keys, []string{"password"}) {
+			t.Errorf("Expected the changed secret key, got %+v", change)
+		}
+	case <-ctx.Done():
+		t.Fatal("Expected a Secret change")
+	}
+}
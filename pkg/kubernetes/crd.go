@@ -0,0 +1,47 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// crdGVR identifies CustomResourceDefinitions
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// ApplyCRD creates or updates a CustomResourceDefinition from a YAML
+// manifest with a server-side apply by the k6s field manager, taking over
+// fields owned by other managers, and returns its name
+func ApplyCRD(ctx context.Context, client dynamic.Interface, manifest []byte, dryRun bool) (string, error) {
+	data, err := yaml.YAMLToJSON(manifest)
+	if err != nil {
+		return "", fmt.Errorf("decoding CustomResourceDefinition: %w", err)
+	}
+	crd := &unstructured.Unstructured{}
+	if err := crd.UnmarshalJSON(data); err != nil {
+		return "", fmt.Errorf("decoding CustomResourceDefinition: %w", err)
+	}
+	if crd.GetKind() != "CustomResourceDefinition" || crd.GetName() == "" {
+		return "", fmt.Errorf("not a named CustomResourceDefinition: %s %q", crd.GetKind(), crd.GetName())
+	}
+
+	force := true
+	opts := metav1.PatchOptions{FieldManager: ApplyFieldManager, Force: &force}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	if _, err := client.Resource(crdGVR).Patch(ctx, crd.GetName(), types.ApplyPatchType, data, opts); err != nil {
+		return "", fmt.Errorf("applying CustomResourceDefinition %s: %w", crd.GetName(), err)
+	}
+	return crd.GetName(), nil
+}
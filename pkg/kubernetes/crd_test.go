@@ -0,0 +1,43 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/charts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestApplyCRD(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	var applied []string
+	client.PrependReactor("patch", "customresourcedefinitions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchActionImpl)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			t.Errorf("Expected a server-side apply, got %s", patch.GetPatchType())
+		}
+		applied = append(applied, patch.GetName())
+		return true, &unstructured.Unstructured{}, nil
+	})
+
+	manifests, err := charts.CRDs()
+	if err != nil {
+		t.Fatalf("CRDs() error = %v", err)
+	}
+	for _, manifest := range manifests {
+		if _, err := ApplyCRD(context.Background(), client, manifest, false); err != nil {
+			t.Fatalf("ApplyCRD() error = %v", err)
+		}
+	}
+	if len(applied) != 2 || applied[0] != "k6scontrollers.k6s.io" || applied[1] != "k6sdeploymentpolicies.k6s.io" {
+		t.Errorf("Expected the chart's CRDs to be applied, got %v", applied)
+	}
+
+	if _, err := ApplyCRD(context.Background(), client, []byte("kind: ConfigMap\nmetadata:\n  name: x\n"), false); err == nil {
+		t.Error("Expected an error for a manifest that is not a CRD")
+	}
+}
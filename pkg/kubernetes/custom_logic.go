@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"reflect"
 
-	"github.com/rs/zerolog/log"
 	appsv1 "k8s.io/api/apps/v1"
 )
 
@@ -36,13 +35,13 @@ func (dca *DeploymentChangeAnalyzer) AnalyzeUpdate(oldObj, newObj *appsv1.Deploy
 	// Verify object exists in cache
 	cachedObj, err := dca.informer.GetDeployment(newObj.Namespace, newObj.Name)
 	if err != nil {
-		log.Warn().
+		informerLog().Warn().
 			Err(err).
 			Str("namespace", newObj.Namespace).
 			Str("name", newObj.Name).
 			Msg("Failed to get deployment from cache")
 	} else {
-		log.Debug().
+		informerLog().Debug().
 			Str("namespace", newObj.Namespace).
 			Str("name", newObj.Name).
 			Int64("cache_generation", cachedObj.Generation).
@@ -51,17 +50,7 @@ func (dca *DeploymentChangeAnalyzer) AnalyzeUpdate(oldObj, newObj *appsv1.Deploy
 	}
 
 	// Analyze replica changes
-	if oldObj.Spec.Replicas != nil && newObj.Spec.Replicas != nil {
-		if *oldObj.Spec.Replicas != *newObj.Spec.Replicas {
-			changes = append(changes, DeploymentChange{
-				Type:        "spec",
-				Field:       "replicas",
-				OldValue:    *oldObj.Spec.Replicas,
-				NewValue:    *newObj.Spec.Replicas,
-				Description: fmt.Sprintf("Replicas changed from %d to %d", *oldObj.Spec.Replicas, *newObj.Spec.Replicas),
-			})
-		}
-	}
+	changes = append(changes, dca.analyzeReplicaChanges(oldObj, newObj)...)
 
 	// Analyze image changes
 	changes = append(changes, dca.analyzeImageChanges(oldObj, newObj)...)
@@ -79,6 +68,20 @@ func (dca *DeploymentChangeAnalyzer) AnalyzeUpdate(oldObj, newObj *appsv1.Deploy
 	return changes
 }
 
+// CompareDeployments compares the same deployment as found in two places,
+// e.g. two clusters, and returns the differences in replicas, images,
+// resources and labels as changes from base to other
+func CompareDeployments(base, other *appsv1.Deployment) []DeploymentChange {
+	dca := &DeploymentChangeAnalyzer{}
+
+	var changes []DeploymentChange
+	changes = append(changes, dca.analyzeReplicaChanges(base, other)...)
+	changes = append(changes, dca.analyzeImageChanges(base, other)...)
+	changes = append(changes, dca.analyzeResourceChanges(base, other)...)
+	changes = append(changes, dca.analyzeLabelChanges(base, other)...)
+	return changes
+}
+
 // AnalyzeDelete performs analysis of deployment deletion with cache verification
 func (dca *DeploymentChangeAnalyzer) AnalyzeDelete(obj *appsv1.Deployment) map[string]interface{} {
 	analysis := make(map[string]interface{})
@@ -88,13 +91,13 @@ func (dca *DeploymentChangeAnalyzer) AnalyzeDelete(obj *appsv1.Deployment) map[s
 	if err == nil && cachedObj != nil {
 		analysis["cache_status"] = "still_exists"
 		analysis["cache_generation"] = cachedObj.Generation
-		log.Warn().
+		informerLog().Warn().
 			Str("namespace", obj.Namespace).
 			Str("name", obj.Name).
 			Msg("Delete event received but deployment still exists in cache")
 	} else {
 		analysis["cache_status"] = "not_found"
-		log.Debug().
+		informerLog().Debug().
 			Str("namespace", obj.Namespace).
 			Str("name", obj.Name).
 			Msg("Delete event confirmed - deployment not in cache")
@@ -123,6 +126,25 @@ func (dca *DeploymentChangeAnalyzer) AnalyzeDelete(obj *appsv1.Deployment) map[s
 	return analysis
 }
 
+// analyzeReplicaChanges compares the replica count between old and new deployments
+func (dca *DeploymentChangeAnalyzer) analyzeReplicaChanges(oldObj, newObj *appsv1.Deployment) []DeploymentChange {
+	var changes []DeploymentChange
+
+	if oldObj.Spec.Replicas != nil && newObj.Spec.Replicas != nil {
+		if *oldObj.Spec.Replicas != *newObj.Spec.Replicas {
+			changes = append(changes, DeploymentChange{
+				Type:        "spec",
+				Field:       "replicas",
+				OldValue:    *oldObj.Spec.Replicas,
+				NewValue:    *newObj.Spec.Replicas,
+				Description: fmt.Sprintf("Replicas changed from %d to %d", *oldObj.Spec.Replicas, *newObj.Spec.Replicas),
+			})
+		}
+	}
+
+	return changes
+}
+
 // analyzeImageChanges compares container images between old and new deployments
 func (dca *DeploymentChangeAnalyzer) analyzeImageChanges(oldObj, newObj *appsv1.Deployment) []DeploymentChange {
 	var changes []DeploymentChange
@@ -228,26 +250,24 @@ func (dca *DeploymentChangeAnalyzer) analyzeResourceChanges(oldObj, newObj *apps
 	return changes
 }
 
-// findRelatedDeployments searches cache for deployments with similar labels
+// findRelatedDeployments looks up the deployments sharing obj's app label in
+// the app label index
 func (dca *DeploymentChangeAnalyzer) findRelatedDeployments(obj *appsv1.Deployment) ([]*appsv1.Deployment, error) {
-	allDeployments, err := dca.informer.ListDeployments()
+	appLabel, exists := obj.Labels["app"]
+	if !exists {
+		return nil, nil
+	}
+	deployments, err := dca.informer.DeploymentsByIndex(AppLabelIndex, AppLabelKey(obj.Namespace, appLabel))
 	if err != nil {
 		return nil, err
 	}
 
 	var related []*appsv1.Deployment
-	
-	// Look for deployments with same app label
-	if appLabel, exists := obj.Labels["app"]; exists {
-		for _, dep := range allDeployments {
-			if dep.Namespace == obj.Namespace && dep.Name != obj.Name {
-				if depAppLabel, depExists := dep.Labels["app"]; depExists && depAppLabel == appLabel {
-					related = append(related, dep)
-				}
-			}
+	for _, dep := range deployments {
+		if dep.Name != obj.Name {
+			related = append(related, dep)
 		}
 	}
-
 	return related, nil
 }
 
@@ -264,7 +284,7 @@ func NewCustomLogicEventHandler(informer *DeploymentInformer) *CustomLogicEventH
 }
 
 func (h *CustomLogicEventHandler) OnAdd(obj *appsv1.Deployment) {
-	log.Info().
+	informerLog().Info().
 		Str("namespace", obj.Namespace).
 		Str("name", obj.Name).
 		Int32("replicas", *obj.Spec.Replicas).
@@ -278,7 +298,7 @@ func (h *CustomLogicEventHandler) OnAdd(obj *appsv1.Deployment) {
 func (h *CustomLogicEventHandler) OnUpdate(oldObj, newObj *appsv1.Deployment) {
 	changes := h.analyzer.AnalyzeUpdate(oldObj, newObj)
 	
-	logEvent := log.Info().
+	logEvent := informerLog().Info().
 		Str("namespace", newObj.Namespace).
 		Str("name", newObj.Name).
 		Str("handler", "custom_logic").
@@ -292,7 +312,7 @@ func (h *CustomLogicEventHandler) OnUpdate(oldObj, newObj *appsv1.Deployment) {
 		changeFields = append(changeFields, change.Field)
 		
 		// Log detailed change information
-		log.Debug().
+		informerLog().Debug().
 			Str("namespace", newObj.Namespace).
 			Str("name", newObj.Name).
 			Str("change_type", change.Type).
@@ -315,7 +335,7 @@ func (h *CustomLogicEventHandler) OnUpdate(oldObj, newObj *appsv1.Deployment) {
 func (h *CustomLogicEventHandler) OnDelete(obj *appsv1.Deployment) {
 	analysis := h.analyzer.AnalyzeDelete(obj)
 	
-	logEvent := log.Info().
+	logEvent := informerLog().Info().
 		Str("namespace", obj.Namespace).
 		Str("name", obj.Name).
 		Str("handler", "custom_logic")
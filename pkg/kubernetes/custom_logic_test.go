@@ -189,6 +189,30 @@ func TestAnalyzeDelete(t *testing.T) {
 }
 
 // Helper function to create test deployments
+func TestCompareDeployments(t *testing.T) {
+	prod := createTestDeployment("web", "nginx:1.25", 6)
+	staging := createTestDeployment("web", "nginx:1.26", 2)
+	staging.Labels["env"] = "staging"
+	staging.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("500m"),
+	}
+	// Annotations and strategy are expected to differ between clusters
+	staging.Annotations = map[string]string{"note": "canary"}
+	staging.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+
+	changes := CompareDeployments(prod, staging)
+
+	var fields []string
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	assert.Equal(t, []string{"replicas", "containers[0].image", "containers[0].resources", "labels"}, fields)
+	assert.Equal(t, int32(6), changes[0].OldValue)
+	assert.Equal(t, int32(2), changes[0].NewValue)
+
+	assert.Empty(t, CompareDeployments(prod, createTestDeployment("web", "nginx:1.25", 6)))
+}
+
 func createTestDeployment(name, image string, replicas int32) *appsv1.Deployment {
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
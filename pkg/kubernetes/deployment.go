@@ -2,14 +2,19 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeploymentList lists deployments in the specified namespace
@@ -17,9 +22,21 @@ func (c *Client) DeploymentList(namespace string) (*appsv1.DeploymentList, error
 	return c.clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
 }
 
+// DeploymentListSelected lists deployments in the specified namespace matching a label selector
+func (c *Client) DeploymentListSelected(namespace, labelSelector string) (*appsv1.DeploymentList, error) {
+	return c.clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
+}
+
 // DeploymentCreate creates a new deployment
 func (c *Client) DeploymentCreate(namespace, name, image string, replicas int32) error {
-	deployment := &appsv1.Deployment{
+	_, err := c.clientset.AppsV1().Deployments(namespace).Create(context.TODO(), NewDeployment(namespace, name, image, replicas), metav1.CreateOptions{})
+	return err
+}
+
+// NewDeployment returns a deployment running a single container of image,
+// selecting its pods by the label app=name
+func NewDeployment(namespace, name, image string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
@@ -53,18 +70,97 @@ func (c *Client) DeploymentCreate(namespace, name, image string, replicas int32)
 			},
 		},
 	}
+}
 
-	_, err := c.clientset.AppsV1().Deployments(namespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
-	return err
+// DeploymentGet gets a deployment
+func (c *Client) DeploymentGet(namespace, name string) (*appsv1.Deployment, error) {
+	return c.clientset.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// DeploymentCreateFrom creates a deployment from a manifest, e.g. a snapshot
+// of a deleted deployment
+func (c *Client) DeploymentCreateFrom(ctx context.Context, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
+	return c.clientset.AppsV1().Deployments(deployment.Namespace).Create(ctx, deployment, metav1.CreateOptions{})
+}
+
+// DeploymentDelete deletes a deployment. With a resourceVersion the
+// deployment is only deleted when it has not changed since that version was
+// read, and the delete fails with a conflict error otherwise.
+func (c *Client) DeploymentDelete(ctx context.Context, namespace, name, resourceVersion string) error {
+	opts := metav1.DeleteOptions{}
+	if resourceVersion != "" {
+		opts.Preconditions = &metav1.Preconditions{ResourceVersion: &resourceVersion}
+	}
+	return c.clientset.AppsV1().Deployments(namespace).Delete(ctx, name, opts)
+}
+
+// DeploymentScale sets the replica count of a deployment with a merge patch.
+// With a resourceVersion the patch only applies when the deployment has not
+// changed since that version was read, and fails with a conflict error
+// otherwise; without one the current version is scaled.
+func (c *Client) DeploymentScale(ctx context.Context, namespace, name string, replicas int32, resourceVersion string) (*appsv1.Deployment, error) {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": replicas},
+	}
+	if resourceVersion != "" {
+		patch["metadata"] = map[string]interface{}{"resourceVersion": resourceVersion}
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return c.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+}
+
+// ApplyFieldManager is the field manager of server-side applies made by k6s
+const ApplyFieldManager = "k6s"
+
+// ApplyOptions configures a server-side apply
+type ApplyOptions struct {
+	// Take ownership of fields managed by other field managers
+	Force bool
+
+	// Validate the apply on the server without persisting it
+	DryRun bool
+}
+
+// DeploymentApply creates or updates a deployment from its manifest, given
+// as JSON, with a server-side apply
+func (c *Client) DeploymentApply(ctx context.Context, namespace, name string, manifest []byte, opts ApplyOptions) (*appsv1.Deployment, error) {
+	patchOptions := metav1.PatchOptions{
+		FieldManager: ApplyFieldManager,
+		Force:        &opts.Force,
+	}
+	if opts.DryRun {
+		patchOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	return c.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.ApplyPatchType, manifest, patchOptions)
+}
+
+// DeploymentListFiltered lists deployments in the specified namespace
+// matching a label selector and a field selector, e.g. metadata.name=web
+func (c *Client) DeploymentListFiltered(namespace, labelSelector, fieldSelector string) (*appsv1.DeploymentList, error) {
+	return c.clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+		FieldSelector: fieldSelector,
+	})
 }
 
-// DeploymentDelete deletes a deployment
-func (c *Client) DeploymentDelete(namespace, name string) error {
-	return c.clientset.AppsV1().Deployments(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+// DeploymentPrintOptions selects the columns printed for deployments
+type DeploymentPrintOptions struct {
+	// Print the NAMESPACE column
+	ShowNamespace bool
+
+	// Print the CONTAINERS, IMAGES, SELECTOR and STRATEGY columns, like
+	// kubectl get -o wide
+	Wide bool
+
+	// Print the LABELS column
+	ShowLabels bool
 }
 
 // DeploymentPrint prints deployments in kubectl-like format
-func DeploymentPrint(deployments []appsv1.Deployment, showNamespace bool) {
+func DeploymentPrint(deployments []appsv1.Deployment, opts DeploymentPrintOptions) {
 	if len(deployments) == 0 {
 		fmt.Println("No resources found.")
 		return
@@ -74,28 +170,79 @@ func DeploymentPrint(deployments []appsv1.Deployment, showNamespace bool) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	defer w.Flush()
 
-	// Print header
-	if showNamespace {
-		fmt.Fprintln(w, "NAMESPACE\tNAME\tREADY\tUP-TO-DATE\tAVAILABLE\tAGE")
-	} else {
-		fmt.Fprintln(w, "NAME\tREADY\tUP-TO-DATE\tAVAILABLE\tAGE")
-	}
-
-	// Print each deployment
-	for _, deploy := range deployments {
-		ready := fmt.Sprintf("%d/%d", deploy.Status.ReadyReplicas, deploy.Status.Replicas)
-		upToDate := fmt.Sprintf("%d", deploy.Status.UpdatedReplicas)
-		available := fmt.Sprintf("%d", deploy.Status.AvailableReplicas)
-		age := FormatAge(deploy.CreationTimestamp.Time)
-
-		if showNamespace {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
-				deploy.Namespace, deploy.Name, ready, upToDate, available, age)
-		} else {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-				deploy.Name, ready, upToDate, available, age)
+	fmt.Fprintln(w, strings.Join(DeploymentColumns(opts), "\t"))
+	for i := range deployments {
+		fmt.Fprintln(w, strings.Join(DeploymentRow(&deployments[i], opts), "\t"))
+	}
+}
+
+// DeploymentColumns returns the column headers printed for deployments
+func DeploymentColumns(opts DeploymentPrintOptions) []string {
+	var columns []string
+	if opts.ShowNamespace {
+		columns = append(columns, "NAMESPACE")
+	}
+	columns = append(columns, "NAME", "READY", "UP-TO-DATE", "AVAILABLE", "AGE")
+	if opts.Wide {
+		columns = append(columns, "CONTAINERS", "IMAGES", "SELECTOR", "STRATEGY")
+	}
+	if opts.ShowLabels {
+		columns = append(columns, "LABELS")
+	}
+	return columns
+}
+
+// DeploymentRow returns the columns printed for a deployment, in the order
+// of DeploymentColumns
+func DeploymentRow(deploy *appsv1.Deployment, opts DeploymentPrintOptions) []string {
+	var row []string
+	if opts.ShowNamespace {
+		row = append(row, deploy.Namespace)
+	}
+	row = append(row,
+		deploy.Name,
+		fmt.Sprintf("%d/%d", deploy.Status.ReadyReplicas, deploy.Status.Replicas),
+		fmt.Sprintf("%d", deploy.Status.UpdatedReplicas),
+		fmt.Sprintf("%d", deploy.Status.AvailableReplicas),
+		FormatAge(deploy.CreationTimestamp.Time),
+	)
+	if opts.Wide {
+		var containers, images []string
+		for _, container := range deploy.Spec.Template.Spec.Containers {
+			containers = append(containers, container.Name)
+			images = append(images, container.Image)
+		}
+		row = append(row,
+			strings.Join(containers, ","),
+			strings.Join(images, ","),
+			metav1.FormatLabelSelector(deploy.Spec.Selector),
+			deploymentStrategy(deploy),
+		)
+	}
+	if opts.ShowLabels {
+		row = append(row, labels.FormatLabels(deploy.Labels))
+	}
+	return row
+}
+
+// deploymentStrategy describes the update strategy of a deployment, with the
+// surge and unavailability of rolling updates, e.g. RollingUpdate(25%/25%)
+func deploymentStrategy(deploy *appsv1.Deployment) string {
+	strategy := deploy.Spec.Strategy
+	if strategy.Type == "" {
+		return "<none>"
+	}
+	if strategy.Type != appsv1.RollingUpdateDeploymentStrategyType || strategy.RollingUpdate == nil {
+		return string(strategy.Type)
+	}
+	intOrString := func(value *intstr.IntOrString) string {
+		if value == nil {
+			return "<none>"
 		}
+		return value.String()
 	}
+	return fmt.Sprintf("%s(%s/%s)", strategy.Type,
+		intOrString(strategy.RollingUpdate.MaxSurge), intOrString(strategy.RollingUpdate.MaxUnavailable))
 }
 
 // FormatAge formats age like kubectl
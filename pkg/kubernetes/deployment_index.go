@@ -0,0 +1,260 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ErrUnknownIndex is returned when querying an index the deployment cache
+// doesn't have
+var ErrUnknownIndex = errors.New("unknown index")
+
+// Indexes of the deployment cache, queried with DeploymentsByIndex
+const (
+	// NamespaceIndex indexes deployments by namespace
+	NamespaceIndex = cache.NamespaceIndex
+
+	// AppLabelIndex indexes deployments by their app label, keyed by
+	// AppLabelKey
+	AppLabelIndex = "app"
+
+	// ImageIndex indexes deployments by the images of their containers and
+	// init containers
+	ImageIndex = "image"
+
+	// OwnerIndex indexes deployments by their owners, keyed by OwnerKey
+	OwnerIndex = "owner"
+)
+
+// deploymentIndexers are the indexers of the deployment cache
+var deploymentIndexers = cache.Indexers{
+	NamespaceIndex:    cache.MetaNamespaceIndexFunc,
+	AppLabelIndex:     indexByAppLabel,
+	ImageIndex:        indexByImage,
+	OwnerIndex:        indexByOwner,
+	configSourceIndex: indexByConfigSource,
+}
+
+// DeploymentIndexes returns the names of the indexes every deployment cache
+// has, sorted
+func DeploymentIndexes() []string {
+	return []string{AppLabelIndex, ImageIndex, NamespaceIndex, OwnerIndex}
+}
+
+// deploymentIndexersFor returns the indexers of a deployment cache with the
+// additional indexes of the configuration. Indexes whose field doesn't parse
+// are left out, as the configuration is validated before.
+func deploymentIndexersFor(indexes []config.IndexConfig) cache.Indexers {
+	if len(indexes) == 0 {
+		return deploymentIndexers
+	}
+	indexers := make(cache.Indexers, len(deploymentIndexers)+len(indexes))
+	for name, indexFunc := range deploymentIndexers {
+		indexers[name] = indexFunc
+	}
+	for _, index := range indexes {
+		if _, ok := indexers[index.Name]; ok {
+			informerLog().Warn().Str("index", index.Name).Msg("Skipping informer index shadowing an existing index")
+			continue
+		}
+		indexFunc, err := FieldIndexFunc(index)
+		if err != nil {
+			informerLog().Warn().Err(err).Str("index", index.Name).Msg("Skipping informer index")
+			continue
+		}
+		indexers[index.Name] = indexFunc
+	}
+	return indexers
+}
+
+// FieldIndexFunc returns the function indexing deployments by the values of
+// the field of index. Deployments without the field aren't indexed; fields
+// holding a list, such as the images of all containers, index each value.
+func FieldIndexFunc(index config.IndexConfig) (cache.IndexFunc, error) {
+	path := jsonpath.New(index.Name).AllowMissingKeys(true)
+	if err := path.Parse(index.JSONPath()); err != nil {
+		return nil, fmt.Errorf("invalid field %s: %w", index.Field, err)
+	}
+
+	// A JSONPath keeps state while it evaluates
+	var mu sync.Mutex
+	return func(obj interface{}) ([]string, error) {
+		dep, ok := obj.(*appsv1.Deployment)
+		if !ok {
+			return nil, nil
+		}
+		mu.Lock()
+		results, err := path.FindResults(dep)
+		mu.Unlock()
+		if err != nil {
+			return nil, nil
+		}
+
+		var values []string
+		seen := make(map[string]bool)
+		for _, result := range results {
+			for _, value := range result {
+				if s, ok := indexValue(value); ok && !seen[s] {
+					seen[s] = true
+					values = append(values, s)
+				}
+			}
+		}
+		return values, nil
+	}, nil
+}
+
+// indexValue formats a scalar field value as an index value
+func indexValue(value reflect.Value) (string, bool) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), value.String() != ""
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return fmt.Sprint(value.Interface()), true
+	}
+	return "", false
+}
+
+// AppLabelKey is the AppLabelIndex key of the deployments of a namespace
+// labeled app=value
+func AppLabelKey(namespace, app string) string {
+	return namespace + "/" + app
+}
+
+// OwnerKey is the OwnerIndex key of the deployments of a namespace owned by
+// an object of kind
+func OwnerKey(namespace, kind, name string) string {
+	return namespace + "/" + kind + "/" + name
+}
+
+// indexByAppLabel returns the AppLabelIndex key of a deployment
+func indexByAppLabel(obj interface{}) ([]string, error) {
+	dep, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, nil
+	}
+	app, ok := dep.Labels["app"]
+	if !ok {
+		return nil, nil
+	}
+	return []string{AppLabelKey(dep.Namespace, app)}, nil
+}
+
+// indexByImage returns the ImageIndex keys of a deployment
+func indexByImage(obj interface{}) ([]string, error) {
+	dep, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, nil
+	}
+	spec := dep.Spec.Template.Spec
+	seen := make(map[string]bool, len(spec.Containers)+len(spec.InitContainers))
+	var images []string
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			if container.Image != "" && !seen[container.Image] {
+				seen[container.Image] = true
+				images = append(images, container.Image)
+			}
+		}
+	}
+	return images, nil
+}
+
+// indexByOwner returns the OwnerIndex keys of a deployment
+func indexByOwner(obj interface{}) ([]string, error) {
+	dep, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(dep.OwnerReferences))
+	for _, owner := range dep.OwnerReferences {
+		keys = append(keys, OwnerKey(dep.Namespace, owner.Kind, owner.Name))
+	}
+	return keys, nil
+}
+
+// DeploymentsByIndex returns the cached deployments whose index has value,
+// sorted by namespace and name
+func (di *DeploymentInformer) DeploymentsByIndex(index, value string) ([]*appsv1.Deployment, error) {
+	indexer, err := di.cacheIndexer()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := indexer.GetIndexers()[index]; !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownIndex, index)
+	}
+	objects, err := indexer.ByIndex(index, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up deployments by %s: %w", index, err)
+	}
+	deployments := make([]*appsv1.Deployment, 0, len(objects))
+	for _, obj := range objects {
+		if deployment, ok := obj.(*appsv1.Deployment); ok {
+			deployments = append(deployments, deployment)
+		}
+	}
+	sort.Slice(deployments, func(i, j int) bool {
+		if deployments[i].Namespace != deployments[j].Namespace {
+			return deployments[i].Namespace < deployments[j].Namespace
+		}
+		return deployments[i].Name < deployments[j].Name
+	})
+	return deployments, nil
+}
+
+// Indexes returns the names of the indexes of the deployment cache that can
+// be queried, including the additional indexes of the configuration, sorted
+func (di *DeploymentInformer) Indexes() []string {
+	di.mu.RLock()
+	defer di.mu.RUnlock()
+
+	names := make([]string, 0, len(di.indexers))
+	for name := range di.indexers {
+		if name != configSourceIndex {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IndexValues returns the values of an index of the deployment cache and
+// how many deployments have each
+func (di *DeploymentInformer) IndexValues(index string) (map[string]int, error) {
+	indexer, err := di.cacheIndexer()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := indexer.GetIndexers()[index]; !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownIndex, index)
+	}
+	values := make(map[string]int)
+	for _, value := range indexer.ListIndexFuncValues(index) {
+		keys, err := indexer.IndexKeys(index, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up deployments by %s: %w", index, err)
+		}
+		if len(keys) > 0 {
+			values[value] = len(keys)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,138 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newIndexedDeployment(namespace, name, app string, images ...string) *appsv1.Deployment {
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"app": app}},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+	}
+	for i, image := range images {
+		dep.Spec.Template.Spec.Containers = append(dep.Spec.Template.Spec.Containers, corev1.Container{Name: fmt.Sprintf("%s-%d", name, i), Image: image})
+	}
+	return dep
+}
+
+func TestDeploymentInformer_DeploymentsByIndex(t *testing.T) {
+	owned := newIndexedDeployment("shop", "cart", "cart", "redis:7")
+	owned.OwnerReferences = []metav1.OwnerReference{{Kind: "Application", Name: "storefront"}}
+	informer := NewDeploymentInformer(fake.NewSimpleClientset(
+		newIndexedDeployment("shop", "web", "web", "nginx:1.25", "envoy:1.30"),
+		newIndexedDeployment("shop", "web-canary", "web", "nginx:1.25"),
+		newIndexedDeployment("payments", "web", "web", "nginx:1.25"),
+		owned,
+	), "", time.Minute)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	tests := []struct {
+		name  string
+		index string
+		value string
+		want  []string
+	}{
+		{"app label", AppLabelIndex, AppLabelKey("shop", "web"), []string{"shop/web", "shop/web-canary"}},
+		{"image", ImageIndex, "nginx:1.25", []string{"payments/web", "shop/web", "shop/web-canary"}},
+		{"sidecar image", ImageIndex, "envoy:1.30", []string{"shop/web"}},
+		{"namespace", NamespaceIndex, "payments", []string{"payments/web"}},
+		{"owner", OwnerIndex, OwnerKey("shop", "Application", "storefront"), []string{"shop/cart"}},
+		{"no match", ImageIndex, "nginx:1.24", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployments, err := informer.DeploymentsByIndex(tt.index, tt.value)
+			if err != nil {
+				t.Fatalf("DeploymentsByIndex() error = %v", err)
+			}
+			var got []string
+			for _, dep := range deployments {
+				got = append(got, dep.Namespace+"/"+dep.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("DeploymentsByIndex() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("DeploymentsByIndex() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	values, err := informer.IndexValues(ImageIndex)
+	if err != nil {
+		t.Fatalf("IndexValues() error = %v", err)
+	}
+	if values["nginx:1.25"] != 3 || values["redis:7"] != 1 || len(values) != 3 {
+		t.Errorf("IndexValues() = %v", values)
+	}
+	if _, err := informer.IndexValues("color"); err == nil {
+		t.Error("expected an error for an unknown index")
+	}
+}
+
+func TestDeploymentInformer_ConfiguredIndexes(t *testing.T) {
+	payments := newIndexedDeployment("shop", "checkout", "checkout", "checkout:1.0", "envoy:1.30")
+	payments.Annotations = map[string]string{"example.com/team": "payments"}
+	search := newIndexedDeployment("shop", "search", "search", "search:2.1")
+	search.Annotations = map[string]string{"example.com/team": "search"}
+
+	cfg := config.DefaultConfig()
+	cfg.Controller.Informer.Transform = "none"
+	cfg.Controller.Informer.Indexes = []config.IndexConfig{
+		{Name: "team", Field: "metadata.annotations['example.com/team']"},
+		{Name: "images", Field: "spec.template.spec.containers[*].image"},
+		{Name: "replicas", Field: "spec.replicas"},
+	}
+	informer := NewDeploymentInformerWithConfig(fake.NewSimpleClientset(payments, search, newIndexedDeployment("shop", "legacy", "legacy")), cfg)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	want := []string{"app", "image", "images", "namespace", "owner", "replicas", "team"}
+	if got := informer.Indexes(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Indexes() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		index string
+		value string
+		want  int
+	}{
+		{"team", "payments", 1},
+		{"team", "search", 1},
+		{"images", "envoy:1.30", 1},
+		{"replicas", "1", 3},
+	}
+	for _, tt := range tests {
+		deployments, err := informer.DeploymentsByIndex(tt.index, tt.value)
+		if err != nil {
+			t.Fatalf("DeploymentsByIndex(%s, %s) error = %v", tt.index, tt.value, err)
+		}
+		if len(deployments) != tt.want {
+			t.Errorf("DeploymentsByIndex(%s, %s) returned %d deployments, want %d", tt.index, tt.value, len(deployments), tt.want)
+		}
+	}
+
+	// Deployments without the field aren't indexed
+	if values, _ := informer.IndexValues("team"); len(values) != 2 {
+		t.Errorf("IndexValues(team) = %v, want payments and search", values)
+	}
+	if _, err := informer.DeploymentsByIndex("color", "red"); !errors.Is(err, ErrUnknownIndex) {
+		t.Errorf("expected ErrUnknownIndex, got %v", err)
+	}
+}
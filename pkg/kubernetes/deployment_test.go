@@ -1,8 +1,17 @@
 package kubernetes
 
 import (
+	"context"
 	"testing"
 	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestFormatAge(t *testing.T) {
@@ -85,3 +94,62 @@ func TestFormatAge(t *testing.T) {
 		})
 	}
 }
+
+func TestDeploymentApply(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	var patch k8stesting.PatchActionImpl
+	clientset.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch = action.(k8stesting.PatchActionImpl)
+		return true, &appsv1.Deployment{}, nil
+	})
+	client := NewClientWithClientset(clientset)
+
+	manifest := []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"}}`)
+	if _, err := client.DeploymentApply(context.Background(), "shop", "web", manifest, ApplyOptions{}); err != nil {
+		t.Fatalf("DeploymentApply() error = %v", err)
+	}
+
+	if patch.GetPatchType() != types.ApplyPatchType || patch.GetNamespace() != "shop" || patch.GetName() != "web" {
+		t.Errorf("Expected a server-side apply of shop/web, got %s %s/%s", patch.GetPatchType(), patch.GetNamespace(), patch.GetName())
+	}
+	if string(patch.GetPatch()) != string(manifest) {
+		t.Errorf("Expected the manifest as the patch, got %s", patch.GetPatch())
+	}
+}
+
+func TestDeploymentRow(t *testing.T) {
+	deploy := NewDeployment("shop", "web", "nginx:1.27", 3)
+	deploy.Labels = map[string]string{"tier": "frontend", "app": "web"}
+	deploy.CreationTimestamp = metav1.NewTime(time.Now().Add(-5 * time.Minute))
+	deploy.Status.Replicas = 3
+	deploy.Status.ReadyReplicas = 2
+	deploy.Status.UpdatedReplicas = 3
+	deploy.Status.AvailableReplicas = 2
+	maxSurge, maxUnavailable := intstr.FromString("25%"), intstr.FromInt32(1)
+	deploy.Spec.Strategy = appsv1.DeploymentStrategy{
+		Type:          appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge, MaxUnavailable: &maxUnavailable},
+	}
+
+	opts := DeploymentPrintOptions{ShowNamespace: true, Wide: true, ShowLabels: true}
+	columns := DeploymentColumns(opts)
+	expected := []string{"shop", "web", "2/3", "3", "2", "5m", "web", "nginx:1.27", "app=web", "RollingUpdate(25%/1)", "app=web,tier=frontend"}
+	row := DeploymentRow(deploy, opts)
+	if len(columns) != len(row) {
+		t.Fatalf("Expected a value for each of the columns %v, got %v", columns, row)
+	}
+	for i := range expected {
+		if row[i] != expected[i] {
+			t.Errorf("Expected %s to be %q, got %q", columns[i], expected[i], row[i])
+		}
+	}
+
+	if got := DeploymentRow(deploy, DeploymentPrintOptions{}); len(got) != 5 || got[0] != "web" {
+		t.Errorf("Expected the default columns, got %v", got)
+	}
+	deploy.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	deploy.Labels = nil
+	if got := DeploymentRow(deploy, DeploymentPrintOptions{Wide: true, ShowLabels: true}); got[8] != "Recreate" || got[9] != "<none>" {
+		t.Errorf("Expected Recreate and no labels, got %v", got)
+	}
+}
@@ -0,0 +1,188 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeploymentDescription combines the spec and status of a deployment with its
+// revisions, pods and events, as shown by "k6s deployment describe"
+type DeploymentDescription struct {
+	Name              string                 `json:"name"`
+	Namespace         string                 `json:"namespace"`
+	CreationTimestamp time.Time              `json:"creationTimestamp"`
+	Labels            map[string]string      `json:"labels,omitempty"`
+	Annotations       map[string]string      `json:"annotations,omitempty"`
+	Selector          string                 `json:"selector"`
+	Strategy          string                 `json:"strategy"`
+	MaxUnavailable    string                 `json:"maxUnavailable,omitempty"`
+	MaxSurge          string                 `json:"maxSurge,omitempty"`
+	MinReadySeconds   int32                  `json:"minReadySeconds"`
+	Replicas          ReplicaCounts          `json:"replicas"`
+	Containers        []ContainerDescription `json:"containers"`
+	Conditions        []ConditionDescription `json:"conditions,omitempty"`
+	Revisions         []Revision             `json:"revisions"`
+	Pods              []PodDescription       `json:"pods"`
+	Events            []ResourceEvent        `json:"events"`
+}
+
+// ReplicaCounts are the replica counts of a deployment
+type ReplicaCounts struct {
+	Desired     int32 `json:"desired"`
+	Updated     int32 `json:"updated"`
+	Total       int32 `json:"total"`
+	Ready       int32 `json:"ready"`
+	Available   int32 `json:"available"`
+	Unavailable int32 `json:"unavailable"`
+}
+
+// ContainerDescription is a container of the pod template of a deployment
+type ContainerDescription struct {
+	Name     string            `json:"name"`
+	Image    string            `json:"image"`
+	Ports    []string          `json:"ports,omitempty"`
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+// ConditionDescription is a condition of a deployment
+type ConditionDescription struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// PodDescription is a pod of a deployment. Ready is the number of ready
+// containers out of all containers, as in kubectl.
+type PodDescription struct {
+	Name     string    `json:"name"`
+	Phase    string    `json:"phase"`
+	Ready    string    `json:"ready"`
+	Restarts int32     `json:"restarts"`
+	Node     string    `json:"node,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+// DescribeDeployment describes a deployment with the replica sets it controls,
+// their pods and the events about all of them. replicaSets and pods may
+// include objects of other owners; they are ignored.
+func DescribeDeployment(dep *appsv1.Deployment, replicaSets []*appsv1.ReplicaSet, pods []*corev1.Pod, events []*corev1.Event) DeploymentDescription {
+	description := DeploymentDescription{
+		Name:              dep.Name,
+		Namespace:         dep.Namespace,
+		CreationTimestamp: dep.CreationTimestamp.UTC(),
+		Labels:            dep.Labels,
+		Annotations:       dep.Annotations,
+		Strategy:          string(dep.Spec.Strategy.Type),
+		MinReadySeconds:   dep.Spec.MinReadySeconds,
+		Replicas: ReplicaCounts{
+			Updated:     dep.Status.UpdatedReplicas,
+			Total:       dep.Status.Replicas,
+			Ready:       dep.Status.ReadyReplicas,
+			Available:   dep.Status.AvailableReplicas,
+			Unavailable: dep.Status.UnavailableReplicas,
+		},
+		Containers: make([]ContainerDescription, 0, len(dep.Spec.Template.Spec.Containers)),
+		Revisions:  DeploymentRevisions(dep, replicaSets),
+		Pods:       make([]PodDescription, 0),
+		Events:     DeploymentEvents(dep, replicaSets, pods, events),
+	}
+
+	if dep.Spec.Replicas != nil {
+		description.Replicas.Desired = *dep.Spec.Replicas
+	}
+	if dep.Spec.Selector != nil {
+		description.Selector = metav1.FormatLabelSelector(dep.Spec.Selector)
+	}
+	if rolling := dep.Spec.Strategy.RollingUpdate; rolling != nil {
+		if rolling.MaxUnavailable != nil {
+			description.MaxUnavailable = rolling.MaxUnavailable.String()
+		}
+		if rolling.MaxSurge != nil {
+			description.MaxSurge = rolling.MaxSurge.String()
+		}
+	}
+
+	for _, container := range dep.Spec.Template.Spec.Containers {
+		description.Containers = append(description.Containers, describeContainer(container))
+	}
+	for _, condition := range dep.Status.Conditions {
+		description.Conditions = append(description.Conditions, ConditionDescription{
+			Type:    string(condition.Type),
+			Status:  string(condition.Status),
+			Reason:  condition.Reason,
+			Message: condition.Message,
+		})
+	}
+
+	_, owned := ownedBy(dep, replicaSets, pods)
+	for _, pod := range owned {
+		description.Pods = append(description.Pods, DescribePod(pod))
+	}
+	sort.Slice(description.Pods, func(i, j int) bool { return description.Pods[i].Name < description.Pods[j].Name })
+
+	return description
+}
+
+// DeploymentDescribe describes a deployment read from the API server
+func (c *Client) DeploymentDescribe(ctx context.Context, namespace, name string) (DeploymentDescription, error) {
+	objects, err := c.deploymentObjects(ctx, namespace, name)
+	if err != nil {
+		return DeploymentDescription{}, err
+	}
+	return DescribeDeployment(objects.deployment, objects.replicaSets, objects.pods, objects.events), nil
+}
+
+// describeContainer describes a container with its ports and resources
+func describeContainer(container corev1.Container) ContainerDescription {
+	description := ContainerDescription{
+		Name:     container.Name,
+		Image:    container.Image,
+		Requests: resourceStrings(container.Resources.Requests),
+		Limits:   resourceStrings(container.Resources.Limits),
+	}
+	for _, port := range container.Ports {
+		description.Ports = append(description.Ports, fmt.Sprintf("%d/%s", port.ContainerPort, port.Protocol))
+	}
+	return description
+}
+
+// resourceStrings formats a resource list, or returns nil when it is empty
+func resourceStrings(resources corev1.ResourceList) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	formatted := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		formatted[string(name)] = quantity.String()
+	}
+	return formatted
+}
+
+// DescribePod summarizes a pod like a row of kubectl get pods
+func DescribePod(pod *corev1.Pod) PodDescription {
+	var ready int
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+		restarts += status.RestartCount
+	}
+
+	return PodDescription{
+		Name:     pod.Name,
+		Phase:    string(pod.Status.Phase),
+		Ready:    fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+		Restarts: restarts,
+		Node:     pod.Spec.NodeName,
+		Created:  pod.CreationTimestamp.UTC(),
+	}
+}
@@ -0,0 +1,81 @@
+package kubernetes
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDescribeDeployment(t *testing.T) {
+	replicas := int32(2)
+	maxSurge := intstr.FromString("25%")
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "web-uid", Annotations: map[string]string{revisionAnnotation: "1"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Strategy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge},
+			},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "web",
+				Image: "web:1",
+				Ports: []corev1.ContainerPort{{ContainerPort: 8080, Protocol: corev1.ProtocolTCP}},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				},
+			}}}},
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas:            2,
+			ReadyReplicas:       1,
+			UnavailableReplicas: 1,
+			Conditions:          []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Reason: "MinimumReplicasUnavailable"}},
+		},
+	}
+	rs := newRevisionReplicaSet(dep, "web-abc", "1", "web:1", 2)
+	rs.UID = "rs-uid"
+	controller := true
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "shop",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc", UID: "rs-uid", Controller: &controller}},
+			},
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "web"}}},
+			Status: corev1.PodStatus{Phase: corev1.PodPending},
+		}
+	}
+	other := pod("api-1")
+	other.OwnerReferences[0].UID = "api-uid"
+
+	d := DescribeDeployment(dep, []*appsv1.ReplicaSet{rs}, []*corev1.Pod{pod("web-abc-2"), pod("web-abc-1"), other}, nil)
+
+	if d.Selector != "app=web" || d.Strategy != "RollingUpdate" || d.MaxSurge != "25%" || d.MaxUnavailable != "" {
+		t.Errorf("Unexpected spec: %+v", d)
+	}
+	if d.Replicas != (ReplicaCounts{Desired: 2, Total: 2, Ready: 1, Unavailable: 1}) {
+		t.Errorf("Unexpected replicas: %+v", d.Replicas)
+	}
+	if c := d.Containers[0]; c.Ports[0] != "8080/TCP" || c.Requests["cpu"] != "100m" || c.Limits != nil {
+		t.Errorf("Unexpected container: %+v", c)
+	}
+	if len(d.Conditions) != 1 || d.Conditions[0].Reason != "MinimumReplicasUnavailable" {
+		t.Errorf("Unexpected conditions: %+v", d.Conditions)
+	}
+	if len(d.Revisions) != 1 || !d.Revisions[0].Current {
+		t.Errorf("Unexpected revisions: %+v", d.Revisions)
+	}
+	if len(d.Pods) != 2 || d.Pods[0].Name != "web-abc-1" || d.Pods[0].Ready != "0/1" || d.Pods[0].Phase != "Pending" {
+		t.Errorf("Expected the pods of the deployment sorted by name, got %+v", d.Pods)
+	}
+	if d.Events == nil || len(d.Events) != 0 {
+		t.Errorf("Expected an empty event list, got %+v", d.Events)
+	}
+}
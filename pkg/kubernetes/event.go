@@ -0,0 +1,248 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ResourceEvent is a Kubernetes event about a deployment or one of its
+// replica sets or pods
+type ResourceEvent struct {
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Object    string    `json:"object"`
+	Count     int32     `json:"count"`
+	FirstSeen time.Time `json:"firstSeen,omitzero"`
+	LastSeen  time.Time `json:"lastSeen,omitzero"`
+}
+
+// DeploymentEvents returns the events about a deployment, the replica sets it
+// controls and their pods, most recent first. replicaSets and pods may
+// include objects of other owners; they are ignored.
+func DeploymentEvents(dep *appsv1.Deployment, replicaSets []*appsv1.ReplicaSet, pods []*corev1.Pod, events []*corev1.Event) []ResourceEvent {
+	involved := map[types.UID]bool{dep.UID: true}
+	replicaSets, pods = ownedBy(dep, replicaSets, pods)
+	for _, rs := range replicaSets {
+		involved[rs.UID] = true
+	}
+	for _, pod := range pods {
+		involved[pod.UID] = true
+	}
+
+	result := make([]ResourceEvent, 0)
+	for _, event := range events {
+		object := event.InvolvedObject
+		if object.Namespace != dep.Namespace {
+			continue
+		}
+		// Some events about a deployment only carry its name
+		byName := object.UID == "" && object.Kind == "Deployment" && object.Name == dep.Name
+		if (object.UID != "" && involved[object.UID]) || byName {
+			result = append(result, resourceEvent(event))
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].LastSeen.After(result[j].LastSeen) })
+	return result
+}
+
+// ownedBy returns the replica sets controlled by a deployment and the pods
+// controlled by those replica sets
+func ownedBy(dep *appsv1.Deployment, replicaSets []*appsv1.ReplicaSet, pods []*corev1.Pod) ([]*appsv1.ReplicaSet, []*corev1.Pod) {
+	owned := make(map[types.UID]bool)
+	var ownedReplicaSets []*appsv1.ReplicaSet
+	for _, rs := range replicaSets {
+		if owner := metav1.GetControllerOf(rs); owner != nil && owner.UID == dep.UID {
+			owned[rs.UID] = true
+			ownedReplicaSets = append(ownedReplicaSets, rs)
+		}
+	}
+	var ownedPods []*corev1.Pod
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(pod); owner != nil && owned[owner.UID] {
+			ownedPods = append(ownedPods, pod)
+		}
+	}
+	return ownedReplicaSets, ownedPods
+}
+
+// resourceEvent converts an event, reading the times and count of events
+// recorded through the events.k8s.io API from their series
+func resourceEvent(event *corev1.Event) ResourceEvent {
+	result := ResourceEvent{
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Message:   event.Message,
+		Object:    event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+		Count:     event.Count,
+		FirstSeen: event.FirstTimestamp.UTC(),
+		LastSeen:  event.LastTimestamp.UTC(),
+	}
+	if event.Series != nil {
+		result.Count = event.Series.Count
+		result.LastSeen = event.Series.LastObservedTime.UTC()
+	}
+	if result.FirstSeen.IsZero() {
+		result.FirstSeen = event.EventTime.UTC()
+	}
+	if result.LastSeen.IsZero() {
+		result.LastSeen = result.FirstSeen
+	}
+	if result.LastSeen.IsZero() {
+		result.LastSeen = event.CreationTimestamp.UTC()
+	}
+	if result.Count == 0 {
+		result.Count = 1
+	}
+	return result
+}
+
+// DeploymentEvents returns the events about a deployment, its replica sets and
+// their pods read from the API server
+func (c *Client) DeploymentEvents(ctx context.Context, namespace, name string) ([]ResourceEvent, error) {
+	objects, err := c.deploymentObjects(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return DeploymentEvents(objects.deployment, objects.replicaSets, objects.pods, objects.events), nil
+}
+
+// deploymentObjects is a deployment with the replica sets and pods matching
+// its selector and the events of its namespace
+type deploymentObjects struct {
+	deployment  *appsv1.Deployment
+	replicaSets []*appsv1.ReplicaSet
+	pods        []*corev1.Pod
+	events      []*corev1.Event
+}
+
+// deploymentObjects reads a deployment and the objects related to it from the API server
+func (c *Client) deploymentObjects(ctx context.Context, namespace, name string) (*deploymentObjects, error) {
+	dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := c.replicaSetsOf(ctx, dep)
+	if err != nil {
+		return nil, err
+	}
+	options, err := selectorOptions(dep)
+	if err != nil {
+		return nil, err
+	}
+	podList, err := c.clientset.CoreV1().Pods(namespace).List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	eventList, err := c.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	objects := &deploymentObjects{
+		deployment:  dep,
+		replicaSets: replicaSets,
+		pods:        make([]*corev1.Pod, 0, len(podList.Items)),
+		events:      make([]*corev1.Event, 0, len(eventList.Items)),
+	}
+	for i := range podList.Items {
+		objects.pods = append(objects.pods, &podList.Items[i])
+	}
+	for i := range eventList.Items {
+		objects.events = append(objects.events, &eventList.Items[i])
+	}
+	return objects, nil
+}
+
+// EventInformer caches the events of a namespace, or of all namespaces,
+// indexed by namespace
+type EventInformer struct {
+	informer  cache.SharedIndexInformer
+	set       *InformerSet
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewEventInformer creates an informer watching the events of namespace; an
+// empty namespace watches all namespaces. Managed fields are dropped from
+// cached events.
+func NewEventInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *EventInformer {
+	return NewEventInformerFor(NewInformerSet(clientset, namespace, resyncPeriod))
+}
+
+// NewEventInformerFor creates an informer sharing the event watch and cache of set
+func NewEventInformerFor(set *InformerSet) *EventInformer {
+	informer := set.informerFor(&corev1.Event{}, stripManagedFields, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		listWatcher := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.CoreV1().Events(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.CoreV1().Events(namespace).Watch(context.TODO(), options)
+			},
+		}
+
+		return cache.NewSharedIndexInformer(set.scoped(listWatcher), &corev1.Event{}, resyncPeriod, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})
+	})
+
+	return &EventInformer{
+		informer: informer,
+		set:      set,
+	}
+}
+
+// Start starts watching events and waits until the cache syncs or ctx is
+// done; on failure the informer is stopped
+func (ei *EventInformer) Start(ctx context.Context) error {
+	ei.startOnce.Do(func() {
+		ei.set.run(ei.informer)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), ei.informer.HasSynced) {
+		ei.Stop()
+		return fmt.Errorf("failed to sync event cache: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching events
+func (ei *EventInformer) Stop() {
+	ei.stopOnce.Do(func() {
+		ei.set.release(ei.informer)
+	})
+}
+
+// HasSynced reports whether the event cache has synced
+func (ei *EventInformer) HasSynced() bool {
+	return ei.informer.HasSynced()
+}
+
+// ListEvents returns the cached events of a namespace
+func (ei *EventInformer) ListEvents(namespace string) ([]*corev1.Event, error) {
+	objects, err := ei.informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*corev1.Event, 0, len(objects))
+	for _, obj := range objects {
+		if event, ok := obj.(*corev1.Event); ok {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,87 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newEvent creates an event about an object last seen at lastSeen
+func newEvent(name, eventType, reason, kind, object string, uid types.UID, lastSeen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: "shop", Name: object, UID: uid},
+		Type:           eventType,
+		Reason:         reason,
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
+
+func TestDeploymentEvents(t *testing.T) {
+	controller := true
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "web-uid"}}
+	dep.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "web-abc",
+		Namespace:       "shop",
+		UID:             "rs-uid",
+		Labels:          map[string]string{"app": "web"},
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &controller}},
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "web-abc-1",
+		Namespace:       "shop",
+		UID:             "pod-uid",
+		Labels:          map[string]string{"app": "web"},
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc", UID: "rs-uid", Controller: &controller}},
+	}}
+
+	now := time.Now().Truncate(time.Second)
+	clientset := fake.NewSimpleClientset(dep, rs, pod,
+		newEvent("e1", corev1.EventTypeNormal, "ScalingReplicaSet", "Deployment", "web", "web-uid", now.Add(-3*time.Minute)),
+		newEvent("e2", corev1.EventTypeNormal, "SuccessfulCreate", "ReplicaSet", "web-abc", "rs-uid", now.Add(-2*time.Minute)),
+		newEvent("e3", corev1.EventTypeWarning, "FailedScheduling", "Pod", "web-abc-1", "pod-uid", now.Add(-time.Minute)),
+		newEvent("e4", corev1.EventTypeWarning, "BackOff", "Pod", "api-1", "other-uid", now),
+	)
+
+	client := NewClientWithClientset(clientset)
+	events, err := client.DeploymentEvents(context.Background(), "shop", "web")
+	if err != nil {
+		t.Fatalf("DeploymentEvents() error = %v", err)
+	}
+
+	want := []string{"FailedScheduling", "SuccessfulCreate", "ScalingReplicaSet"}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), events)
+	}
+	for i, event := range events {
+		if event.Reason != want[i] {
+			t.Errorf("Expected %s at %d, got %+v", want[i], i, event)
+		}
+	}
+	if events[0].Object != "Pod/web-abc-1" || events[0].Count != 1 || !events[0].LastSeen.Equal(now.Add(-time.Minute)) {
+		t.Errorf("Unexpected pod event: %+v", events[0])
+	}
+
+	informer := NewEventInformer(clientset, "", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+	cached, err := informer.ListEvents("shop")
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	// Without replica sets and pods only the deployment's own events remain
+	if events := DeploymentEvents(dep, nil, nil, cached); len(events) != 1 || events[0].Reason != "ScalingReplicaSet" {
+		t.Errorf("Expected only the deployment event, got %+v", events)
+	}
+}
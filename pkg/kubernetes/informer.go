@@ -3,12 +3,17 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
-	"github.com/rs/zerolog/log"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/telemetry"
+	"github.com/rs/zerolog"
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -20,12 +25,35 @@ import (
 type DeploymentInformer struct {
 	clientset       kubernetes.Interface
 	informer        cache.SharedIndexInformer
-	stopper         chan struct{}
+	set             *InformerSet
 	namespace       string
 	resyncPeriod    time.Duration
 	started         bool
 	mu              sync.RWMutex
 	eventHandlers   []DeploymentEventHandler
+
+	// transform, listChunkSize and indexers are reapplied when the
+	// informer is rebuilt
+	transform       cache.TransformFunc
+	listChunkSize   int64
+	indexers        cache.Indexers
+
+	// Records events and cache lookups, labeled with cluster
+	metrics         *metrics.Metrics
+	cluster         string
+
+	// Skip updates changing nothing handlers act on, see isNoopUpdate
+	suppressNoop    bool
+
+	// Registration of the event handlers with the shared informer
+	registration    cache.ResourceEventHandlerRegistration
+
+	// Start is waiting for the cache to sync, without holding mu
+	starting        bool
+
+	// Deployments loaded from a snapshot, served until the cache has synced
+	snapshotMu      sync.RWMutex
+	snapshot        *loadedSnapshot
 }
 
 // DeploymentEventHandler defines the interface for handling deployment events
@@ -36,10 +64,16 @@ type DeploymentEventHandler interface {
 }
 
 // DefaultDeploymentEventHandler provides a default implementation with logging
-type DefaultDeploymentEventHandler struct{}
+type DefaultDeploymentEventHandler struct {
+	// Suppresses repeated entries of a deployment, nil to log them all
+	dedup *logDeduper
+}
 
 func (h *DefaultDeploymentEventHandler) OnAdd(obj *appsv1.Deployment) {
-	log.Info().
+	if !h.dedup.allow(obj.Namespace, obj.Name, "add", "Deployment added") {
+		return
+	}
+	informerLog().Info().
 		Str("namespace", obj.Namespace).
 		Str("name", obj.Name).
 		Int32("replicas", *obj.Spec.Replicas).
@@ -47,7 +81,18 @@ func (h *DefaultDeploymentEventHandler) OnAdd(obj *appsv1.Deployment) {
 }
 
 func (h *DefaultDeploymentEventHandler) OnUpdate(oldObj, newObj *appsv1.Deployment) {
-	logEvent := log.Info().
+	// Spec changes bump the generation, status changes the observed one
+	kind := "update"
+	if oldObj.Generation != newObj.Generation {
+		kind = "spec"
+	} else if oldObj.Status.ObservedGeneration != newObj.Status.ObservedGeneration {
+		kind = "status"
+	}
+	if !h.dedup.allow(newObj.Namespace, newObj.Name, kind, "Deployment updated") {
+		return
+	}
+
+	logEvent := informerLog().Info().
 		Str("namespace", newObj.Namespace).
 		Str("name", newObj.Name)
 
@@ -69,12 +114,12 @@ func (h *DefaultDeploymentEventHandler) OnUpdate(oldObj, newObj *appsv1.Deployme
 		}
 	}
 
-	// Check for generation changes (indicates spec changes)
-	if oldObj.Generation != newObj.Generation {
+	switch kind {
+	case "spec":
 		logEvent = logEvent.
 			Int64("generation", newObj.Generation).
 			Str("change_type", "spec")
-	} else if oldObj.Status.ObservedGeneration != newObj.Status.ObservedGeneration {
+	case "status":
 		logEvent = logEvent.
 			Int64("observed_generation", newObj.Status.ObservedGeneration).
 			Str("change_type", "status")
@@ -84,7 +129,10 @@ func (h *DefaultDeploymentEventHandler) OnUpdate(oldObj, newObj *appsv1.Deployme
 }
 
 func (h *DefaultDeploymentEventHandler) OnDelete(obj *appsv1.Deployment) {
-	log.Info().
+	if !h.dedup.allow(obj.Namespace, obj.Name, "delete", "Deployment deleted") {
+		return
+	}
+	informerLog().Info().
 		Str("namespace", obj.Namespace).
 		Str("name", obj.Name).
 		Msg("Deployment deleted")
@@ -105,38 +153,101 @@ func (h *KubectlStyleEventHandler) OnDelete(obj *appsv1.Deployment) {
 	fmt.Printf("DELETED   %s/%s\n", obj.Namespace, obj.Name)
 }
 
-// NewDeploymentInformer creates a new deployment informer
-func NewDeploymentInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *DeploymentInformer {
-	if resyncPeriod == 0 {
-		resyncPeriod = 30 * time.Second
-	}
-
-	if namespace == "" {
-		namespace = metav1.NamespaceAll
-	}
-
+// newSharedDeploymentInformer creates the shared informer that lists and watches
+// the deployments of namespace in set, listing listChunkSize at a time when it
+// is positive, with indexers
+func newSharedDeploymentInformer(set *InformerSet, namespace string, resyncPeriod time.Duration, listChunkSize int64, indexers cache.Indexers) cache.SharedIndexInformer {
+	clientset := set.Clientset()
 	listWatcher := &cache.ListWatch{
-		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+		ListFunc: chunkedList(func(options metav1.ListOptions) (runtime.Object, error) {
 			return clientset.AppsV1().Deployments(namespace).List(context.TODO(), options)
-		},
+		}, listChunkSize),
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			// Bookmarks keep the last resourceVersion current, so a
+			// restarted watch resumes from it instead of relisting and
+			// replaying every deployment as an update
+			options.AllowWatchBookmarks = true
 			return clientset.AppsV1().Deployments(namespace).Watch(context.TODO(), options)
 		},
 	}
 
-	informer := cache.NewSharedIndexInformer(
-		listWatcher,
+	return cache.NewSharedIndexInformer(
+		set.scoped(listWatcher),
 		&appsv1.Deployment{},
 		resyncPeriod,
-		cache.Indexers{},
+		indexers,
 	)
+}
+
+// chunkedList makes the paginated lists of an informer request chunkSize
+// objects per page when it is positive. client-go's reflector follows the
+// continue tokens, and asks for the initial list at resourceVersion "0",
+// which the API server's watch cache answers in one response whatever the
+// limit; that list is read from etcd instead so that it is paginated. Lists
+// the reflector sends unpaginated, such as relists served from the watch
+// cache and its fallback after an expired continue token, are left alone.
+func chunkedList(list cache.ListFunc, chunkSize int64) cache.ListFunc {
+	if chunkSize <= 0 {
+		return list
+	}
+	return func(options metav1.ListOptions) (runtime.Object, error) {
+		if options.Limit > 0 {
+			options.Limit = chunkSize
+			if options.ResourceVersion == "0" {
+				options.ResourceVersion = ""
+			}
+		}
+		return list(options)
+	}
+}
+
+// configSourceIndex indexes deployments by the ConfigMaps and Secrets they
+// reference, keyed by configSourceKey
+const configSourceIndex = "configSource"
+
+// configSourceKey is the configSourceIndex key of a ConfigMap or Secret
+func configSourceKey(kind, namespace, name string) string {
+	return namespace + "/" + kind + "/" + name
+}
+
+// indexByConfigSource returns the configSourceIndex keys of a deployment
+func indexByConfigSource(obj interface{}) ([]string, error) {
+	dep, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, nil
+	}
+	sources := DeploymentConfigSources(dep)
+	keys := make([]string, 0, len(sources))
+	for _, source := range sources {
+		keys = append(keys, configSourceKey(source.Kind, dep.Namespace, source.Name))
+	}
+	return keys, nil
+}
+
+// informerLog returns the logger of the informers and their event handlers
+func informerLog() *zerolog.Logger {
+	return logger.ForComponent(logger.ComponentInformer).Zerolog()
+}
+
+// NewDeploymentInformer creates a new deployment informer
+func NewDeploymentInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *DeploymentInformer {
+	if resyncPeriod == 0 {
+		resyncPeriod = 30 * time.Second
+	}
+
+	if namespace == "" {
+		namespace = metav1.NamespaceAll
+	}
+
+	set := NewInformerSet(clientset, namespace, resyncPeriod)
 
 	di := &DeploymentInformer{
 		clientset:    clientset,
-		informer:     informer,
+		informer:     set.informerFor(&appsv1.Deployment{}, nil, deploymentInformerFunc(set, 0, deploymentIndexers)),
+		set:          set,
 		namespace:    namespace,
 		resyncPeriod: resyncPeriod,
-		stopper:      make(chan struct{}),
+		indexers:     deploymentIndexers,
 		started:      false,
 	}
 
@@ -156,28 +267,15 @@ func NewDeploymentInformerWithKubectlStyle(clientset kubernetes.Interface, names
 		namespace = metav1.NamespaceAll
 	}
 
-	listWatcher := &cache.ListWatch{
-		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
-			return clientset.AppsV1().Deployments(namespace).List(context.TODO(), options)
-		},
-		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
-			return clientset.AppsV1().Deployments(namespace).Watch(context.TODO(), options)
-		},
-	}
-
-	informer := cache.NewSharedIndexInformer(
-		listWatcher,
-		&appsv1.Deployment{},
-		resyncPeriod,
-		cache.Indexers{},
-	)
+	set := NewInformerSet(clientset, namespace, resyncPeriod)
 
 	di := &DeploymentInformer{
 		clientset:    clientset,
-		informer:     informer,
+		informer:     set.informerFor(&appsv1.Deployment{}, nil, deploymentInformerFunc(set, 0, deploymentIndexers)),
+		set:          set,
 		namespace:    namespace,
 		resyncPeriod: resyncPeriod,
-		stopper:      make(chan struct{}),
+		indexers:     deploymentIndexers,
 		started:      false,
 	}
 
@@ -197,28 +295,15 @@ func NewDeploymentInformerWithCustomLogic(clientset kubernetes.Interface, namesp
 		namespace = metav1.NamespaceAll
 	}
 
-	listWatcher := &cache.ListWatch{
-		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
-			return clientset.AppsV1().Deployments(namespace).List(context.TODO(), options)
-		},
-		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
-			return clientset.AppsV1().Deployments(namespace).Watch(context.TODO(), options)
-		},
-	}
-
-	informer := cache.NewSharedIndexInformer(
-		listWatcher,
-		&appsv1.Deployment{},
-		resyncPeriod,
-		cache.Indexers{},
-	)
+	set := NewInformerSet(clientset, namespace, resyncPeriod)
 
 	di := &DeploymentInformer{
 		clientset:    clientset,
-		informer:     informer,
+		informer:     set.informerFor(&appsv1.Deployment{}, nil, deploymentInformerFunc(set, 0, deploymentIndexers)),
+		set:          set,
 		namespace:    namespace,
 		resyncPeriod: resyncPeriod,
-		stopper:      make(chan struct{}),
+		indexers:     deploymentIndexers,
 		started:      false,
 	}
 
@@ -234,79 +319,165 @@ func NewDeploymentInformerWithConfig(clientset kubernetes.Interface, cfg *config
 		cfg = config.DefaultConfig()
 	}
 
-	namespace := cfg.Controller.Single.Namespace
-	if namespace == "" {
-		namespace = metav1.NamespaceAll
-	}
-
 	resyncPeriod := cfg.Controller.ResyncPeriod
 	if resyncPeriod == 0 {
 		resyncPeriod = 30 * time.Second
 	}
 
-	// Create list/watch functions
-	listWatcher := &cache.ListWatch{
-		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
-			return clientset.AppsV1().Deployments(namespace).List(context.TODO(), options)
-		},
-		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
-			return clientset.AppsV1().Deployments(namespace).Watch(context.TODO(), options)
-		},
+	return NewDeploymentInformerFor(NewInformerSetForScope(clientset, cfg.Controller.Single.NamespaceScope(), resyncPeriod), cfg)
+}
+
+// NewDeploymentInformerFor creates a deployment informer sharing the
+// deployment watch and cache of set, which decides the namespaces and resync
+// period. The informer settings of cfg apply when set creates the shared
+// informer.
+func NewDeploymentInformerFor(set *InformerSet, cfg *config.Config) *DeploymentInformer {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
 	}
 
-	informer := cache.NewSharedIndexInformer(
-		listWatcher,
-		&appsv1.Deployment{},
-		resyncPeriod,
-		cache.Indexers{},
-	)
+	namespace := strings.Join(set.Namespaces(), ",")
+	resyncPeriod := set.ResyncPeriod()
+	listChunkSize := cfg.Controller.Informer.ListChunkSize
+
+	// Trim objects before they enter the cache
+	transform := TransformFor(cfg.Controller.Informer.Transform)
+	indexers := deploymentIndexersFor(cfg.Controller.Informer.Indexes)
 
 	di := &DeploymentInformer{
-		clientset:    clientset,
-		informer:     informer,
-		namespace:    namespace,
-		resyncPeriod: resyncPeriod,
-		stopper:      make(chan struct{}),
-		started:      false,
+		clientset:     set.Clientset(),
+		informer:      set.informerFor(&appsv1.Deployment{}, transform, deploymentInformerFunc(set, listChunkSize, indexers)),
+		set:           set,
+		namespace:     namespace,
+		resyncPeriod:  resyncPeriod,
+		transform:     transform,
+		listChunkSize: listChunkSize,
+		indexers:      indexers,
+		suppressNoop:  cfg.Controller.Informer.SuppressNoopUpdates,
+		started:       false,
 	}
 
 	// Add default event handler
-	di.AddEventHandler(&DefaultDeploymentEventHandler{})
+	di.AddEventHandler(&DefaultDeploymentEventHandler{dedup: newLogDeduper(cfg.Log.DedupWindow)})
 
-	log.Debug().
+	informerLog().Debug().
 		Str("namespace", namespace).
 		Dur("resync_period", resyncPeriod).
+		Str("transform", cfg.Controller.Informer.Transform).
+		Int64("list_chunk_size", listChunkSize).
+		Bool("suppress_noop_updates", cfg.Controller.Informer.SuppressNoopUpdates).
 		Msg("Created deployment informer with configuration")
 
 	return di
 }
 
-// AddEventHandler adds an event handler to the informer
+// deploymentInformerFunc returns the constructor of a shared deployment
+// informer for an InformerSet
+func deploymentInformerFunc(set *InformerSet, listChunkSize int64, indexers cache.Indexers) newInformerFunc {
+	return func(_ kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		return newSharedDeploymentInformer(set, namespace, resyncPeriod, listChunkSize, indexers)
+	}
+}
+
+// SetMetrics records the informer's deployment events and cache lookups in m,
+// labeled with cluster. It must be called before Start.
+func (di *DeploymentInformer) SetMetrics(m *metrics.Metrics, cluster string) {
+	di.mu.Lock()
+	defer di.mu.Unlock()
+	di.metrics = m
+	di.cluster = cluster
+}
+
+// SetSuppressNoopUpdates sets whether updates changing neither the spec,
+// status, labels nor annotations of a deployment, such as resyncs, are
+// skipped instead of passed to handlers. It must be called before Start.
+func (di *DeploymentInformer) SetSuppressNoopUpdates(suppress bool) {
+	di.mu.Lock()
+	defer di.mu.Unlock()
+	di.suppressNoop = suppress
+}
+
+// isNoopUpdate reports whether an update changes nothing handlers act on,
+// and why: "resync" when the informer redelivered the cached object, and
+// "unchanged" when only fields such as managedFields or the resourceVersion
+// changed
+func isNoopUpdate(oldObj, newObj *appsv1.Deployment) (bool, string) {
+	if oldObj.ResourceVersion == newObj.ResourceVersion {
+		return true, "resync"
+	}
+	if oldObj.Generation == newObj.Generation &&
+		equality.Semantic.DeepEqual(oldObj.Spec, newObj.Spec) &&
+		equality.Semantic.DeepEqual(oldObj.Status, newObj.Status) &&
+		equality.Semantic.DeepEqual(oldObj.Labels, newObj.Labels) &&
+		equality.Semantic.DeepEqual(oldObj.Annotations, newObj.Annotations) &&
+		equality.Semantic.DeepEqual(oldObj.DeletionTimestamp, newObj.DeletionTimestamp) &&
+		equality.Semantic.DeepEqual(oldObj.Finalizers, newObj.Finalizers) {
+		return true, "unchanged"
+	}
+	return false, ""
+}
+
+// Clientset returns the clientset the informer lists and watches through
+func (di *DeploymentInformer) Clientset() kubernetes.Interface {
+	return di.clientset
+}
+
+// InformerSet returns the set the informer shares its watch and cache with,
+// so informers of other resources can share the same namespaces
+func (di *DeploymentInformer) InformerSet() *InformerSet {
+	return di.set
+}
+
+// AddEventHandler adds an event handler to the informer. It is safe to call
+// while the informer runs; the handler then receives the events delivered
+// after it was added, but not the adds of deployments already cached.
 func (di *DeploymentInformer) AddEventHandler(handler DeploymentEventHandler) {
 	di.mu.Lock()
 	defer di.mu.Unlock()
 
 	di.eventHandlers = append(di.eventHandlers, handler)
+}
+
+// handlers returns the event handlers events are delivered to. Handlers are
+// only ever appended or replaced, so the returned slice stays valid without
+// holding mu while they run.
+func (di *DeploymentInformer) handlers() []DeploymentEventHandler {
+	di.mu.RLock()
+	defer di.mu.RUnlock()
+	return di.eventHandlers
+}
+
+// ReplaceEventHandlers replaces the event handlers of the informer, such as
+// the default logging handler, with handlers
+func (di *DeploymentInformer) ReplaceEventHandlers(handlers ...DeploymentEventHandler) {
+	di.mu.Lock()
+	defer di.mu.Unlock()
+
+	di.eventHandlers = handlers
 
 	if di.started {
-		log.Warn().Msg("Adding event handler to already started informer")
+		informerLog().Warn().Msg("Replacing event handlers of already started informer")
 	}
 }
 
-// Start starts the informer
+// Start starts the informer and waits for its cache to sync. A snapshot
+// loaded with LoadSnapshot is served meanwhile.
 func (di *DeploymentInformer) Start() error {
 	di.mu.Lock()
 	defer di.mu.Unlock()
 
-	if di.started {
+	if di.started || di.starting {
 		return fmt.Errorf("informer is already started")
 	}
 
 	// Add event handlers to the informer
-	_, err := di.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	registration, err := di.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			if deployment, ok := obj.(*appsv1.Deployment); ok {
-				for _, handler := range di.eventHandlers {
+				span := telemetry.StartInformerEvent("deployment", "add", di.cluster, deployment.Namespace, deployment.Name)
+				defer span.End()
+				di.metrics.RecordDeploymentEvent(di.cluster, deployment.Namespace, "add")
+				for _, handler := range di.handlers() {
 					handler.OnAdd(deployment)
 				}
 			}
@@ -314,7 +485,16 @@ func (di *DeploymentInformer) Start() error {
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			if oldDeployment, ok := oldObj.(*appsv1.Deployment); ok {
 				if newDeployment, ok := newObj.(*appsv1.Deployment); ok {
-					for _, handler := range di.eventHandlers {
+					if di.suppressNoop {
+						if noop, reason := isNoopUpdate(oldDeployment, newDeployment); noop {
+							di.metrics.RecordSuppressedUpdate(di.cluster, newDeployment.Namespace, reason)
+							return
+						}
+					}
+					span := telemetry.StartInformerEvent("deployment", "update", di.cluster, newDeployment.Namespace, newDeployment.Name)
+					defer span.End()
+					di.metrics.RecordDeploymentEvent(di.cluster, newDeployment.Namespace, "update")
+					for _, handler := range di.handlers() {
 						handler.OnUpdate(oldDeployment, newDeployment)
 					}
 				}
@@ -322,7 +502,10 @@ func (di *DeploymentInformer) Start() error {
 		},
 		DeleteFunc: func(obj interface{}) {
 			if deployment, ok := obj.(*appsv1.Deployment); ok {
-				for _, handler := range di.eventHandlers {
+				span := telemetry.StartInformerEvent("deployment", "delete", di.cluster, deployment.Namespace, deployment.Name)
+				defer span.End()
+				di.metrics.RecordDeploymentEvent(di.cluster, deployment.Namespace, "delete")
+				for _, handler := range di.handlers() {
 					handler.OnDelete(deployment)
 				}
 			}
@@ -332,36 +515,138 @@ func (di *DeploymentInformer) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to add event handler: %w", err)
 	}
+	di.registration = registration
 
-	// Start the informer
-	go di.informer.Run(di.stopper)
+	// Start the informer, unless another informer of the set already runs it
+	stopped := di.set.run(di.informer)
 
-	// Wait for cache to sync
-	if !cache.WaitForCacheSync(di.stopper, di.informer.HasSynced) {
-		close(di.stopper)
+	// Wait for cache to sync, letting readers of a snapshot and Stop in
+	di.starting = true
+	informer := di.informer
+	di.mu.Unlock()
+	synced := cache.WaitForCacheSync(stopped, informer.HasSynced)
+	di.mu.Lock()
+
+	if !di.starting {
+		return fmt.Errorf("informer was stopped before its cache synced")
+	}
+	di.starting = false
+	if !synced {
+		di.release()
 		return fmt.Errorf("failed to sync cache")
 	}
 
 	di.started = true
+	di.replaceSnapshot(informer.GetIndexer())
 
 	return nil
 }
 
-// Stop stops the informer
+// Stop stops the informer, and stops serving a loaded snapshot
 func (di *DeploymentInformer) Stop() {
+	di.setSnapshot(nil)
+
 	di.mu.Lock()
 	defer di.mu.Unlock()
 
-	if !di.started {
+	if !di.started && !di.starting {
 		return
 	}
 
-	close(di.stopper)
+	di.release()
 	di.started = false
+	di.starting = false
+}
+
+// release removes the informer's event handlers from the shared informer and
+// stops it unless other informers of the set use it. di.mu must be held.
+func (di *DeploymentInformer) release() {
+	if di.registration != nil {
+		_ = di.informer.RemoveEventHandler(di.registration)
+		di.registration = nil
+	}
+	di.set.release(di.informer)
+}
+
+// ResyncPeriod returns the current resync period
+func (di *DeploymentInformer) ResyncPeriod() time.Duration {
+	di.mu.RLock()
+	defer di.mu.RUnlock()
+	return di.resyncPeriod
+}
+
+// SetResyncPeriod changes the resync period. client-go fixes the period when
+// an informer is created, so a started informer is stopped and rebuilt; its
+// cache is relisted and handlers see every deployment added again. The
+// rebuilt informer is no longer shared with the other informers of the set.
+func (di *DeploymentInformer) SetResyncPeriod(resyncPeriod time.Duration) error {
+	if resyncPeriod == 0 {
+		resyncPeriod = 30 * time.Second
+	}
+
+	di.mu.Lock()
+	if resyncPeriod == di.resyncPeriod {
+		di.mu.Unlock()
+		return nil
+	}
+	if di.starting {
+		di.mu.Unlock()
+		return fmt.Errorf("informer is starting")
+	}
+
+	wasStarted := di.started
+	if wasStarted {
+		di.release()
+		di.started = false
+	}
+
+	informer := di.set.newInformer(deploymentInformerFunc(di.set, di.listChunkSize, di.indexers), resyncPeriod)
+	if di.transform != nil {
+		if err := informer.SetTransform(di.transform); err != nil {
+			di.mu.Unlock()
+			return fmt.Errorf("failed to set informer transform: %w", err)
+		}
+	}
+
+	informerLog().Info().
+		Dur("old_resync_period", di.resyncPeriod).
+		Dur("new_resync_period", resyncPeriod).
+		Bool("restart", wasStarted).
+		Msg("Changing deployment informer resync period")
+
+	di.informer = informer
+	di.resyncPeriod = resyncPeriod
+	di.mu.Unlock()
+
+	if !wasStarted {
+		return nil
+	}
+	return di.Start()
+}
+
+// cacheIndexer returns the indexer deployments are read from: the loaded
+// snapshot until the cache has synced, then the cache of the started
+// informer
+func (di *DeploymentInformer) cacheIndexer() (cache.Indexer, error) {
+	if snapshot := di.loadedSnapshot(); snapshot != nil {
+		return snapshot.indexer, nil
+	}
+
+	di.mu.RLock()
+	defer di.mu.RUnlock()
+
+	if !di.started {
+		return nil, fmt.Errorf("informer is not started")
+	}
+	return di.informer.GetIndexer(), nil
 }
 
-// IsStarted returns whether the informer is started
+// IsStarted returns whether the informer is started, or serves a snapshot
 func (di *DeploymentInformer) IsStarted() bool {
+	if di.Stale() {
+		return true
+	}
+
 	di.mu.RLock()
 	defer di.mu.RUnlock()
 	return di.started
@@ -369,8 +654,9 @@ func (di *DeploymentInformer) IsStarted() bool {
 
 // GetDeployment retrieves a deployment from the cache
 func (di *DeploymentInformer) GetDeployment(namespace, name string) (*appsv1.Deployment, error) {
-	if !di.IsStarted() {
-		return nil, fmt.Errorf("informer is not started")
+	indexer, err := di.cacheIndexer()
+	if err != nil {
+		return nil, err
 	}
 
 	key := name
@@ -378,14 +664,16 @@ func (di *DeploymentInformer) GetDeployment(namespace, name string) (*appsv1.Dep
 		key = namespace + "/" + name
 	}
 
-	obj, exists, err := di.informer.GetIndexer().GetByKey(key)
+	obj, exists, err := indexer.GetByKey(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get deployment from cache: %w", err)
 	}
 
 	if !exists {
+		di.metrics.RecordCacheMiss("deployment")
 		return nil, fmt.Errorf("deployment %s not found in cache", key)
 	}
+	di.metrics.RecordCacheHit("deployment")
 
 	deployment, ok := obj.(*appsv1.Deployment)
 	if !ok {
@@ -397,11 +685,12 @@ func (di *DeploymentInformer) GetDeployment(namespace, name string) (*appsv1.Dep
 
 // ListDeployments returns all deployments from the cache
 func (di *DeploymentInformer) ListDeployments() ([]*appsv1.Deployment, error) {
-	if !di.IsStarted() {
-		return nil, fmt.Errorf("informer is not started")
+	indexer, err := di.cacheIndexer()
+	if err != nil {
+		return nil, err
 	}
 
-	objects := di.informer.GetIndexer().List()
+	objects := indexer.List()
 	deployments := make([]*appsv1.Deployment, 0, len(objects))
 
 	for _, obj := range objects {
@@ -413,7 +702,36 @@ func (di *DeploymentInformer) ListDeployments() ([]*appsv1.Deployment, error) {
 	return deployments, nil
 }
 
-// HasSynced returns true if the informer's cache has synced
+// DeploymentsReferencing returns the cached deployments referencing a
+// ConfigMap or Secret, looked up in the config source index
+func (di *DeploymentInformer) DeploymentsReferencing(kind, namespace, name string) ([]*appsv1.Deployment, error) {
+	indexer, err := di.cacheIndexer()
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := indexer.ByIndex(configSourceIndex, configSourceKey(kind, namespace, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up deployments by config source: %w", err)
+	}
+	deployments := make([]*appsv1.Deployment, 0, len(objects))
+	for _, obj := range objects {
+		if deployment, ok := obj.(*appsv1.Deployment); ok {
+			deployments = append(deployments, deployment)
+		}
+	}
+	return deployments, nil
+}
+
+// HasSynced returns true if the informer's cache has synced, or a snapshot
+// is served
 func (di *DeploymentInformer) HasSynced() bool {
-	return di.informer.HasSynced()
+	if di.Stale() {
+		return true
+	}
+
+	di.mu.RLock()
+	informer := di.informer
+	di.mu.RUnlock()
+	return informer.HasSynced()
 }
@@ -0,0 +1,226 @@
+package kubernetes
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// InformerSet shares one informer per resource type of a cluster, created by
+// a SharedInformerFactory, between the informers built from it, so that they
+// share the watch connection and cache of each type. A shared informer runs
+// while any informer using it is started.
+//
+// A set watching a list of namespaces has a factory per namespace, and
+// shares informers merging the informers of each namespace.
+type InformerSet struct {
+	clientset    kubernetes.Interface
+	namespaces   []string
+	excluded     []string
+	resyncPeriod time.Duration
+
+	// Factories by namespace, "" for cluster-scoped resources and sets
+	// watching all namespaces
+	factories map[string]informers.SharedInformerFactory
+
+	mu      sync.Mutex
+	merged  map[reflect.Type]cache.SharedIndexInformer
+	running map[cache.SharedIndexInformer]*sharedRun
+}
+
+// sharedRun is a running shared informer and how many informers use it
+type sharedRun struct {
+	stopper chan struct{}
+	refs    int
+}
+
+// newInformerFunc creates the informer of a resource in namespace, "" for
+// all namespaces
+type newInformerFunc func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer
+
+// NewInformerSet creates the informer set of a cluster watching namespace;
+// an empty namespace watches all namespaces. Cluster-scoped resources such as
+// nodes are always watched in full.
+func NewInformerSet(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *InformerSet {
+	return NewInformerSetForScope(clientset, config.NewNamespaceScope(namespace, nil), resyncPeriod)
+}
+
+// NewInformerSetForScope creates the informer set of a cluster watching the
+// namespaces of scope. Namespaces are excluded with a field selector, as a
+// watch of all namespaces can't leave out some.
+func NewInformerSetForScope(clientset kubernetes.Interface, scope config.NamespaceScope, resyncPeriod time.Duration) *InformerSet {
+	s := &InformerSet{
+		clientset:    clientset,
+		namespaces:   scope.Include,
+		resyncPeriod: resyncPeriod,
+		factories:    map[string]informers.SharedInformerFactory{metav1.NamespaceAll: informers.NewSharedInformerFactory(clientset, resyncPeriod)},
+		merged:       make(map[reflect.Type]cache.SharedIndexInformer),
+		running:      make(map[cache.SharedIndexInformer]*sharedRun),
+	}
+	if scope.All() {
+		s.excluded = scope.Exclude
+	}
+	for _, namespace := range s.namespaces {
+		s.factories[namespace] = informers.NewSharedInformerFactoryWithOptions(clientset, resyncPeriod, informers.WithNamespace(namespace))
+	}
+	return s
+}
+
+// Clientset returns the clientset the set lists and watches through
+func (s *InformerSet) Clientset() kubernetes.Interface {
+	return s.clientset
+}
+
+// Namespaces returns the namespaces the set watches, nil for all namespaces
+func (s *InformerSet) Namespaces() []string {
+	return s.namespaces
+}
+
+// ResyncPeriod returns the resync period of the set's informers
+func (s *InformerSet) ResyncPeriod() time.Duration {
+	return s.resyncPeriod
+}
+
+// informerFor returns the shared informer of obj's namespaced type, created
+// by newFunc with transform the first time it is requested. Indexers must be
+// set in newFunc, as the informer is shared as created. The factory replaces
+// the transform of the informers it creates, so it is set here instead.
+func (s *InformerSet) informerFor(obj runtime.Object, transform cache.TransformFunc, newFunc newInformerFunc) cache.SharedIndexInformer {
+	if len(s.namespaces) <= 1 {
+		namespace := metav1.NamespaceAll
+		if len(s.namespaces) == 1 {
+			namespace = s.namespaces[0]
+		}
+		return s.factoryInformerFor(namespace, obj, transform, newFunc)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objType := reflect.TypeOf(obj)
+	if informer, ok := s.merged[objType]; ok {
+		return informer
+	}
+	informer := newMultiNamespaceInformer(s.namespaces, func(namespace string) cache.SharedIndexInformer {
+		return s.factoryInformerFor(namespace, obj, transform, newFunc)
+	})
+	s.merged[objType] = informer
+	return informer
+}
+
+// clusterInformerFor returns the shared informer of obj's cluster-scoped
+// type, like informerFor
+func (s *InformerSet) clusterInformerFor(obj runtime.Object, transform cache.TransformFunc, newFunc func(kubernetes.Interface, time.Duration) cache.SharedIndexInformer) cache.SharedIndexInformer {
+	return s.factoryInformerFor(metav1.NamespaceAll, obj, transform, func(clientset kubernetes.Interface, _ string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		return newFunc(clientset, resyncPeriod)
+	})
+}
+
+// factoryInformerFor returns the informer of obj's type from the factory of
+// namespace
+func (s *InformerSet) factoryInformerFor(namespace string, obj runtime.Object, transform cache.TransformFunc, newFunc newInformerFunc) cache.SharedIndexInformer {
+	created := false
+	informer := s.factories[namespace].InformerFor(obj, func(clientset kubernetes.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		created = true
+		return newFunc(clientset, namespace, resyncPeriod)
+	})
+	if created && transform != nil {
+		_ = informer.SetTransform(transform)
+	}
+	return informer
+}
+
+// newInformer creates an informer of the set's namespaces with newFunc that
+// isn't shared
+func (s *InformerSet) newInformer(newFunc newInformerFunc, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	switch len(s.namespaces) {
+	case 0:
+		return newFunc(s.clientset, metav1.NamespaceAll, resyncPeriod)
+	case 1:
+		return newFunc(s.clientset, s.namespaces[0], resyncPeriod)
+	}
+	return newMultiNamespaceInformer(s.namespaces, func(namespace string) cache.SharedIndexInformer {
+		return newFunc(s.clientset, namespace, resyncPeriod)
+	})
+}
+
+// scoped returns lw leaving out the namespaces excluded from the set
+func (s *InformerSet) scoped(lw *cache.ListWatch) *cache.ListWatch {
+	if len(s.excluded) == 0 {
+		return lw
+	}
+	selectors := make([]fields.Selector, 0, len(s.excluded))
+	for _, namespace := range s.excluded {
+		selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+	}
+	excluded := fields.AndSelectors(selectors...).String()
+	tweak := func(options *metav1.ListOptions) {
+		if options.FieldSelector == "" {
+			options.FieldSelector = excluded
+		} else {
+			options.FieldSelector += "," + excluded
+		}
+	}
+
+	list, watchFunc := lw.ListFunc, lw.WatchFunc
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			tweak(&options)
+			return list(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			tweak(&options)
+			return watchFunc(options)
+		},
+		DisableChunking: lw.DisableChunking,
+	}
+}
+
+// run starts the shared informers that aren't running yet and returns the
+// channel closed when the first of them stops. Each call must be paired with
+// a release.
+func (s *InformerSet) run(sharedInformers ...cache.SharedIndexInformer) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stopped chan struct{}
+	for _, informer := range sharedInformers {
+		r, ok := s.running[informer]
+		if !ok {
+			r = &sharedRun{stopper: make(chan struct{})}
+			s.running[informer] = r
+			go informer.Run(r.stopper)
+		}
+		r.refs++
+		if stopped == nil {
+			stopped = r.stopper
+		}
+	}
+	return stopped
+}
+
+// release stops the shared informers no other informer uses anymore. A
+// stopped informer can't be started again.
+func (s *InformerSet) release(sharedInformers ...cache.SharedIndexInformer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, informer := range sharedInformers {
+		r, ok := s.running[informer]
+		if !ok || r.refs == 0 {
+			continue
+		}
+		r.refs--
+		if r.refs == 0 {
+			close(r.stopper)
+		}
+	}
+}
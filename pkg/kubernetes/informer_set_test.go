@@ -0,0 +1,121 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestInformerSet(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set := NewInformerSet(clientset, "shop", time.Minute)
+	first := NewPodInformerFor(set)
+	second := NewPodInformerFor(set)
+	if first.informer != second.informer {
+		t.Fatal("Expected informers of one set to share the pod informer")
+	}
+
+	if err := first.Start(ctx); err != nil {
+		t.Fatalf("first.Start() error = %v", err)
+	}
+	if err := second.Start(ctx); err != nil {
+		t.Fatalf("second.Start() error = %v", err)
+	}
+
+	// The shared informer keeps running while an informer uses it
+	first.Stop()
+	if _, err := clientset.CoreV1().Pods("shop").Create(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "shop"}}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pods, err := second.ListPods("shop")
+		if err != nil {
+			t.Fatalf("ListPods() error = %v", err)
+		}
+		if len(pods) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the shared informer to see web-2, got %d pods", len(pods))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	second.Stop()
+	run := set.running[second.informer]
+	select {
+	case <-run.stopper:
+	default:
+		t.Error("Expected the shared informer to stop once no informer uses it")
+	}
+}
+
+func TestInformerSet_Namespaces(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "payments"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set := NewInformerSetForScope(clientset, config.NewNamespaceScope("shop, payments", nil), time.Minute)
+	pods := NewPodInformerFor(set)
+	if err := pods.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer pods.Stop()
+
+	if all := pods.informer.GetIndexer().List(); len(all) != 2 {
+		t.Errorf("Expected the pods of shop and payments, got %d", len(all))
+	}
+	if shop, _ := pods.ListPods("shop"); len(shop) != 1 || shop[0].Name != "web-1" {
+		t.Errorf("Expected web-1 in shop, got %v", shop)
+	}
+	if _, exists, _ := pods.informer.GetIndexer().GetByKey("kube-system/coredns"); exists {
+		t.Error("Expected kube-system not to be watched")
+	}
+	if _, exists, _ := pods.informer.GetIndexer().GetByKey("payments/api-1"); !exists {
+		t.Error("Expected payments/api-1 to be cached")
+	}
+}
+
+func TestInformerSet_Scoped(t *testing.T) {
+	set := NewInformerSetForScope(fake.NewSimpleClientset(), config.NewNamespaceScope("", []string{"kube-system", "kube-public"}), time.Minute)
+
+	var listed, watched metav1.ListOptions
+	lw := set.scoped(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			listed = options
+			return &corev1.PodList{}, nil
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			watched = options
+			return watch.NewFake(), nil
+		},
+	})
+	_, _ = lw.List(metav1.ListOptions{FieldSelector: "spec.nodeName=node-1"})
+	_, _ = lw.Watch(metav1.ListOptions{})
+
+	want := "spec.nodeName=node-1,metadata.namespace!=kube-system,metadata.namespace!=kube-public"
+	if listed.FieldSelector != want {
+		t.Errorf("Expected list field selector %q, got %q", want, listed.FieldSelector)
+	}
+	if want := "metadata.namespace!=kube-system,metadata.namespace!=kube-public"; watched.FieldSelector != want {
+		t.Errorf("Expected watch field selector %q, got %q", want, watched.FieldSelector)
+	}
+}
@@ -1,12 +1,14 @@
 package kubernetes
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -142,6 +144,44 @@ func TestDeploymentInformer_AddEventHandler(t *testing.T) {
 	}
 }
 
+func TestDeploymentInformer_AddEventHandlerAfterStart(t *testing.T) {
+	informer := NewDeploymentInformer(fake.NewSimpleClientset(), "test", 30*time.Second)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	// Handlers added while events are delivered receive the later ones
+	handler := &TestEventHandler{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			informer.AddEventHandler(&TestEventHandler{})
+		}
+		informer.AddEventHandler(handler)
+	}()
+	for _, name := range []string{"web", "api", "worker"} {
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}, Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(1)}}
+		if _, err := informer.Clientset().AppsV1().Deployments("test").Create(context.TODO(), dep, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	<-done
+
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "late", Namespace: "test"}, Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(1)}}
+	if _, err := informer.Clientset().AppsV1().Deployments("test").Create(context.TODO(), dep, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !handler.GetOnAddCalled() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a handler added after Start to receive later events")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
 func TestDeploymentInformer_IsStarted(t *testing.T) {
 	clientset := fake.NewSimpleClientset()
 	informer := NewDeploymentInformer(clientset, "test", 30*time.Second)
@@ -236,6 +276,143 @@ func TestDeploymentInformer_HasSynced(t *testing.T) {
 	}
 }
 
+func TestDeploymentInformer_SetResyncPeriod(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+	})
+	informer := NewDeploymentInformer(clientset, "test", 30*time.Second)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	if err := informer.SetResyncPeriod(time.Minute); err != nil {
+		t.Fatalf("SetResyncPeriod() error = %v", err)
+	}
+	if informer.ResyncPeriod() != time.Minute {
+		t.Errorf("expected resync period 1m, got %v", informer.ResyncPeriod())
+	}
+
+	// The rebuilt informer is started again with a synced cache
+	if !informer.IsStarted() || !informer.HasSynced() {
+		t.Fatal("expected informer to be restarted and synced")
+	}
+	if _, err := informer.GetDeployment("test", "web"); err != nil {
+		t.Errorf("GetDeployment() after resync change error = %v", err)
+	}
+}
+
+func TestIsNoopUpdate(t *testing.T) {
+	base := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test", ResourceVersion: "1", Generation: 1, Labels: map[string]string{"app": "web"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+	}
+	bumped := func(mutate func(*appsv1.Deployment)) *appsv1.Deployment {
+		dep := base.DeepCopy()
+		dep.ResourceVersion = "2"
+		mutate(dep)
+		return dep
+	}
+
+	tests := []struct {
+		name       string
+		newObj     *appsv1.Deployment
+		wantNoop   bool
+		wantReason string
+	}{
+		{"resync", base.DeepCopy(), true, "resync"},
+		{"managed fields only", bumped(func(d *appsv1.Deployment) {
+			d.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+		}), true, "unchanged"},
+		{"spec change", bumped(func(d *appsv1.Deployment) {
+			d.Generation = 2
+			d.Spec.Replicas = int32Ptr(3)
+		}), false, ""},
+		{"status change", bumped(func(d *appsv1.Deployment) { d.Status.ReadyReplicas = 2 }), false, ""},
+		{"label change", bumped(func(d *appsv1.Deployment) { d.Labels["tier"] = "web" }), false, ""},
+		{"annotation change", bumped(func(d *appsv1.Deployment) {
+			d.Annotations = map[string]string{"k6s.io/owner": "shop"}
+		}), false, ""},
+		{"deletion", bumped(func(d *appsv1.Deployment) {
+			now := metav1.Now()
+			d.DeletionTimestamp = &now
+		}), false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			noop, reason := isNoopUpdate(base, tt.newObj)
+			if noop != tt.wantNoop || reason != tt.wantReason {
+				t.Errorf("isNoopUpdate() = %v, %q, want %v, %q", noop, reason, tt.wantNoop, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDeploymentInformer_SuppressNoopUpdates(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test", ResourceVersion: "1"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+	}
+	informer := NewDeploymentInformer(fake.NewSimpleClientset(dep), "test", 30*time.Second)
+	informer.SetSuppressNoopUpdates(true)
+	handler := &TestEventHandler{}
+	informer.AddEventHandler(handler)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	// An update only bumping the resourceVersion is suppressed
+	noop := dep.DeepCopy()
+	noop.ResourceVersion = "2"
+	if _, err := informer.Clientset().AppsV1().Deployments("test").Update(context.TODO(), noop, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if handler.GetOnUpdateCalled() {
+		t.Fatal("expected an update changing nothing to be suppressed")
+	}
+
+	scaled := noop.DeepCopy()
+	scaled.ResourceVersion = "3"
+	scaled.Spec.Replicas = int32Ptr(3)
+	if _, err := informer.Clientset().AppsV1().Deployments("test").Update(context.TODO(), scaled, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !handler.GetOnUpdateCalled() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a spec change to reach handlers")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestChunkedList(t *testing.T) {
+	var got []metav1.ListOptions
+	list := chunkedList(func(options metav1.ListOptions) (runtime.Object, error) {
+		got = append(got, options)
+		return &appsv1.DeploymentList{}, nil
+	}, 100)
+
+	// The reflector's initial list, a continued page and an unpaginated relist
+	_, _ = list(metav1.ListOptions{ResourceVersion: "0", Limit: 500})
+	_, _ = list(metav1.ListOptions{Continue: "token", Limit: 500})
+	_, _ = list(metav1.ListOptions{ResourceVersion: "42"})
+
+	want := []metav1.ListOptions{
+		{Limit: 100},
+		{Continue: "token", Limit: 100},
+		{ResourceVersion: "42"},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("list %d: expected options %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
 // Helper function to create int32 pointer
 func int32Ptr(i int32) *int32 {
 	return &i
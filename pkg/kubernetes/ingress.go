@@ -0,0 +1,297 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// hostIndex indexes Ingresses by the hosts of their rules; rules without a
+// host and default backends are indexed under catchAllHost
+const hostIndex = "host"
+
+// catchAllHost is the host of routes matching requests for any host
+const catchAllHost = "*"
+
+// Route is a host and path an Ingress routes to a backend Service, with the
+// deployments whose pods the Service selects, null when deployments are not
+// cached. Host is "*" for rules matching any host and for default backends,
+// which have no path.
+type Route struct {
+	Host          string   `json:"host"`
+	Path          string   `json:"path,omitempty"`
+	PathType      string   `json:"pathType,omitempty"`
+	Namespace     string   `json:"namespace"`
+	Ingress       string   `json:"ingress"`
+	IngressClass  string   `json:"ingressClass,omitempty"`
+	TLS           bool     `json:"tls"`
+	Service       string   `json:"service,omitempty"`
+	ServicePort   string   `json:"servicePort,omitempty"`
+	ServiceExists bool     `json:"serviceExists"`
+	Deployments   []string `json:"deployments"`
+}
+
+// IngressInformer caches the Ingresses and Services of a namespace, or of all
+// namespaces, to resolve hosts to the deployments serving them
+type IngressInformer struct {
+	ingresses cache.SharedIndexInformer
+	services  cache.SharedIndexInformer
+	set       *InformerSet
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewIngressInformer creates an informer watching the Ingresses and Services
+// of namespace; an empty namespace watches all namespaces. Managed fields are
+// dropped from cached objects.
+func NewIngressInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *IngressInformer {
+	return NewIngressInformerFor(NewInformerSet(clientset, namespace, resyncPeriod))
+}
+
+// NewIngressInformerFor creates an informer sharing the Ingress and Service
+// watches and caches of set
+func NewIngressInformerFor(set *InformerSet) *IngressInformer {
+	ingresses := set.informerFor(&networkingv1.Ingress{}, stripManagedFields, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(set.scoped(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.NetworkingV1().Ingresses(namespace).Watch(context.TODO(), options)
+			},
+		}), &networkingv1.Ingress{}, resyncPeriod, cache.Indexers{
+			hostIndex: indexByHost,
+		})
+	})
+
+	services := set.informerFor(&corev1.Service{}, stripManagedFields, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(set.scoped(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.CoreV1().Services(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.CoreV1().Services(namespace).Watch(context.TODO(), options)
+			},
+		}), &corev1.Service{}, resyncPeriod, cache.Indexers{})
+	})
+
+	return &IngressInformer{
+		ingresses: ingresses,
+		services:  services,
+		set:       set,
+	}
+}
+
+// indexByHost indexes an Ingress by the lowercased hosts of its rules
+func indexByHost(obj interface{}) ([]string, error) {
+	ingress, ok := obj.(*networkingv1.Ingress)
+	if !ok {
+		return nil, nil
+	}
+	var hosts []string
+	if ingress.Spec.DefaultBackend != nil {
+		hosts = append(hosts, catchAllHost)
+	}
+	for _, rule := range ingress.Spec.Rules {
+		hosts = append(hosts, ruleHost(rule))
+	}
+	return uniqueStrings(hosts), nil
+}
+
+// ruleHost returns the lowercased host of a rule, or catchAllHost
+func ruleHost(rule networkingv1.IngressRule) string {
+	if rule.Host == "" {
+		return catchAllHost
+	}
+	return strings.ToLower(rule.Host)
+}
+
+// Start starts watching Ingresses and Services and waits until both caches
+// sync or ctx is done; on failure the informer is stopped
+func (ii *IngressInformer) Start(ctx context.Context) error {
+	ii.startOnce.Do(func() {
+		ii.set.run(ii.ingresses, ii.services)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), ii.ingresses.HasSynced, ii.services.HasSynced) {
+		ii.Stop()
+		return fmt.Errorf("failed to sync Ingress and Service caches: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching Ingresses and Services
+func (ii *IngressInformer) Stop() {
+	ii.stopOnce.Do(func() {
+		ii.set.release(ii.ingresses, ii.services)
+	})
+}
+
+// HasSynced reports whether the Ingress and Service caches have synced
+func (ii *IngressInformer) HasSynced() bool {
+	return ii.ingresses.HasSynced() && ii.services.HasSynced()
+}
+
+// Routes returns the cached routes sorted by host, path and namespace, with
+// the deployments among deployments their Services select. With a host, only
+// the routes matching requests for it are returned: those of the host itself,
+// of a wildcard host covering it such as *.example.com, and catch-all routes.
+func (ii *IngressInformer) Routes(host string, deployments []*appsv1.Deployment) ([]Route, error) {
+	var objects []interface{}
+	if host == "" {
+		objects = ii.ingresses.GetIndexer().List()
+	} else {
+		host = strings.ToLower(host)
+		keys := []string{host, catchAllHost}
+		if _, parent, ok := strings.Cut(host, "."); ok {
+			keys = append(keys, "*."+parent)
+		}
+		seen := make(map[string]bool)
+		for _, key := range keys {
+			matches, err := ii.ingresses.GetIndexer().ByIndex(hostIndex, key)
+			if err != nil {
+				return nil, err
+			}
+			for _, obj := range matches {
+				if ingress, ok := obj.(*networkingv1.Ingress); ok && !seen[ingress.Namespace+"/"+ingress.Name] {
+					seen[ingress.Namespace+"/"+ingress.Name] = true
+					objects = append(objects, ingress)
+				}
+			}
+		}
+	}
+
+	var routes []Route
+	for _, obj := range objects {
+		ingress, ok := obj.(*networkingv1.Ingress)
+		if !ok {
+			continue
+		}
+		for _, route := range ingressRoutes(ingress) {
+			if host != "" && !hostMatches(route.Host, host) {
+				continue
+			}
+			ii.resolve(&route, deployments)
+			routes = append(routes, route)
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Host != routes[j].Host {
+			return routes[i].Host < routes[j].Host
+		}
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Namespace+"/"+routes[i].Ingress < routes[j].Namespace+"/"+routes[j].Ingress
+	})
+	return routes, nil
+}
+
+// hostMatches reports whether a route host matches requests for host
+func hostMatches(routeHost, host string) bool {
+	if routeHost == catchAllHost || routeHost == host {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(routeHost, "*.")
+	if !ok {
+		return false
+	}
+	// A wildcard covers a single DNS label
+	_, parent, _ := strings.Cut(host, ".")
+	return parent == suffix
+}
+
+// ingressRoutes returns the routes of an Ingress, without their deployments
+func ingressRoutes(ingress *networkingv1.Ingress) []Route {
+	tlsHosts := make(map[string]bool)
+	for _, tls := range ingress.Spec.TLS {
+		for _, host := range tls.Hosts {
+			tlsHosts[strings.ToLower(host)] = true
+		}
+	}
+	route := func(host string, backend networkingv1.IngressBackend) Route {
+		r := Route{
+			Host:      host,
+			Namespace: ingress.Namespace,
+			Ingress:   ingress.Name,
+			TLS:       tlsHosts[host],
+		}
+		if ingress.Spec.IngressClassName != nil {
+			r.IngressClass = *ingress.Spec.IngressClassName
+		}
+		if backend.Service != nil {
+			r.Service = backend.Service.Name
+			r.ServicePort = backend.Service.Port.Name
+			if r.ServicePort == "" {
+				r.ServicePort = strconv.Itoa(int(backend.Service.Port.Number))
+			}
+		}
+		return r
+	}
+
+	var routes []Route
+	if ingress.Spec.DefaultBackend != nil {
+		routes = append(routes, route(catchAllHost, *ingress.Spec.DefaultBackend))
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		host := ruleHost(rule)
+		for _, path := range rule.HTTP.Paths {
+			r := route(host, path.Backend)
+			r.Path = path.Path
+			if path.PathType != nil {
+				r.PathType = string(*path.PathType)
+			}
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// resolve looks up the backend Service of a route and sets the deployments
+// among deployments in its namespace whose pod template the Service selects.
+// Deployments stays nil when deployments is nil, as they are unknown.
+func (ii *IngressInformer) resolve(route *Route, deployments []*appsv1.Deployment) {
+	if deployments != nil {
+		route.Deployments = []string{}
+	}
+	if route.Service == "" {
+		return
+	}
+	obj, exists, err := ii.services.GetIndexer().GetByKey(route.Namespace + "/" + route.Service)
+	if err != nil || !exists {
+		return
+	}
+	service, ok := obj.(*corev1.Service)
+	if !ok {
+		return
+	}
+	route.ServiceExists = true
+	if len(service.Spec.Selector) == 0 {
+		return
+	}
+
+	selector := labels.SelectorFromSet(service.Spec.Selector)
+	for _, dep := range deployments {
+		if dep.Namespace == route.Namespace && selector.Matches(labels.Set(dep.Spec.Template.Labels)) {
+			route.Deployments = append(route.Deployments, dep.Name)
+		}
+	}
+	sort.Strings(route.Deployments)
+}
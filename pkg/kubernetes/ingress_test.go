@@ -0,0 +1,113 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// ingressPath routes a path prefix to a service port
+func ingressPath(path, service string, port int32) networkingv1.HTTPIngressPath {
+	prefix := networkingv1.PathTypePrefix
+	return networkingv1.HTTPIngressPath{
+		Path:     path,
+		PathType: &prefix,
+		Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+			Name: service,
+			Port: networkingv1.ServiceBackendPort{Number: port},
+		}},
+	}
+}
+
+func TestIngressInformerRoutes(t *testing.T) {
+	class := "nginx"
+	shop := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "shop"},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &class,
+			TLS:              []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}}},
+			Rules: []networkingv1.IngressRule{
+				{Host: "Shop.example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{ingressPath("/", "web", 80), ingressPath("/api", "api", 8080)},
+				}}},
+				{Host: "*.example.com", IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{ingressPath("/", "missing", 80)},
+				}}},
+			},
+		},
+	}
+	fallback := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: "shop"},
+		Spec: networkingv1.IngressSpec{DefaultBackend: &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+			Name: "web",
+			Port: networkingv1.ServiceBackendPort{Name: "http"},
+		}}},
+	}
+	clientset := fake.NewSimpleClientset(shop, fallback,
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}, Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "web"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"}},
+	)
+	deployment := func(namespace, name, app string) *appsv1.Deployment {
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		dep.Spec.Template.Labels = map[string]string{"app": app, "tier": "frontend"}
+		return dep
+	}
+	deployments := []*appsv1.Deployment{
+		deployment("shop", "web", "web"),
+		deployment("shop", "web-canary", "web"),
+		deployment("other", "web", "web"),
+	}
+
+	informer := NewIngressInformer(clientset, "", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	routes, err := informer.Routes("", deployments)
+	if err != nil {
+		t.Fatalf("Routes() error = %v", err)
+	}
+	if len(routes) != 4 {
+		t.Fatalf("Expected four routes, got %+v", routes)
+	}
+	catchAll := Route{Host: "*", Namespace: "shop", Ingress: "fallback", Service: "web", ServicePort: "http", ServiceExists: true, Deployments: []string{"web", "web-canary"}}
+	if !reflect.DeepEqual(routes[0], catchAll) {
+		t.Errorf("routes[0] = %+v, want %+v", routes[0], catchAll)
+	}
+
+	// A host matches its own rules, wildcards covering it and catch-all routes
+	routes, _ = informer.Routes("SHOP.example.com", deployments)
+	var hosts []string
+	for _, route := range routes {
+		hosts = append(hosts, route.Host+route.Path)
+	}
+	if want := []string{"*", "*.example.com/", "shop.example.com/", "shop.example.com/api"}; !reflect.DeepEqual(hosts, want) {
+		t.Errorf("Routes(shop.example.com) = %v, want %v", hosts, want)
+	}
+	web := routes[2]
+	if !web.TLS || web.IngressClass != "nginx" || web.PathType != "Prefix" || web.ServicePort != "80" || !reflect.DeepEqual(web.Deployments, []string{"web", "web-canary"}) {
+		t.Errorf("Unexpected shop.example.com/ route: %+v", web)
+	}
+	if missing := routes[1]; missing.ServiceExists || len(missing.Deployments) != 0 {
+		t.Errorf("Expected the missing service not to resolve, got %+v", missing)
+	}
+	if api := routes[3]; !api.ServiceExists || len(api.Deployments) != 0 {
+		t.Errorf("Expected a service without selector to select no deployments, got %+v", api)
+	}
+
+	// Wildcards cover a single label
+	routes, _ = informer.Routes("a.b.example.com", nil)
+	if len(routes) != 1 || routes[0].Host != "*" || routes[0].Deployments != nil {
+		t.Errorf("Expected only the catch-all route without deployments, got %+v", routes)
+	}
+}
@@ -0,0 +1,468 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Job statuses, from the conditions and counts of a job
+const (
+	JobComplete  = "Complete"
+	JobFailed    = "Failed"
+	JobSuspended = "Suspended"
+	JobRunning   = "Running"
+	JobPending   = "Pending"
+)
+
+// Types of the job events delivered to JobEventHandler. A job is finished
+// once it completes or fails.
+const (
+	JobEventAdded    = "added"
+	JobEventUpdated  = "updated"
+	JobEventFinished = "finished"
+	JobEventDeleted  = "deleted"
+)
+
+// cronJobIndex indexes jobs by the namespace/name of the CronJob that
+// created them
+const cronJobIndex = "cronJob"
+
+// JobSummary summarizes the progress of a job. Completions is the number of
+// succeeded pods out of the completions wanted, as in kubectl. Duration runs
+// from the start of the job to its completion, or until now while it runs.
+type JobSummary struct {
+	Name           string    `json:"name"`
+	Namespace      string    `json:"namespace"`
+	Status         string    `json:"status"`
+	Completions    string    `json:"completions"`
+	Active         int32     `json:"active"`
+	Succeeded      int32     `json:"succeeded"`
+	Failed         int32     `json:"failed"`
+	CronJob        string    `json:"cronJob,omitempty"`
+	StartTime      time.Time `json:"startTime,omitzero"`
+	CompletionTime time.Time `json:"completionTime,omitzero"`
+	Duration       string    `json:"duration,omitempty"`
+	Age            string    `json:"age"`
+}
+
+// CronJobSummary summarizes a CronJob. Succeeded and Failed count the cached
+// jobs it created that completed or failed, which the CronJob's history
+// limits bound.
+type CronJobSummary struct {
+	Name               string    `json:"name"`
+	Namespace          string    `json:"namespace"`
+	Schedule           string    `json:"schedule"`
+	TimeZone           string    `json:"timeZone,omitempty"`
+	Suspended          bool      `json:"suspended"`
+	Active             int       `json:"active"`
+	Succeeded          int       `json:"succeeded"`
+	Failed             int       `json:"failed"`
+	LastScheduleTime   time.Time `json:"lastScheduleTime,omitzero"`
+	LastSuccessfulTime time.Time `json:"lastSuccessfulTime,omitzero"`
+	Age                string    `json:"age"`
+}
+
+// SummarizeJob summarizes a job
+func SummarizeJob(job *batchv1.Job) JobSummary {
+	completions := int32(1)
+	if job.Spec.Completions != nil {
+		completions = *job.Spec.Completions
+	}
+
+	summary := JobSummary{
+		Name:        job.Name,
+		Namespace:   job.Namespace,
+		Status:      JobStatus(job),
+		Completions: fmt.Sprintf("%d/%d", job.Status.Succeeded, completions),
+		Active:      job.Status.Active,
+		Succeeded:   job.Status.Succeeded,
+		Failed:      job.Status.Failed,
+		Age:         FormatAge(job.CreationTimestamp.Time),
+	}
+	if owner := metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+		summary.CronJob = owner.Name
+	}
+	if job.Status.StartTime != nil {
+		summary.StartTime = job.Status.StartTime.Time
+		end := time.Now()
+		if job.Status.CompletionTime != nil {
+			summary.CompletionTime = job.Status.CompletionTime.Time
+			end = summary.CompletionTime
+		}
+		// FormatAge formats the time elapsed since a time, so shift the start
+		// by the time passed since the end
+		summary.Duration = FormatAge(summary.StartTime.Add(time.Since(end)))
+	}
+	return summary
+}
+
+// JobStatus returns the status of a job: Complete or Failed from its
+// conditions, Suspended, Running while pods are active, and Pending otherwise
+func JobStatus(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return JobComplete
+		case batchv1.JobFailed:
+			return JobFailed
+		}
+	}
+	switch {
+	case job.Spec.Suspend != nil && *job.Spec.Suspend:
+		return JobSuspended
+	case job.Status.Active > 0:
+		return JobRunning
+	default:
+		return JobPending
+	}
+}
+
+// jobFinished reports whether a job completed or failed
+func jobFinished(job *batchv1.Job) bool {
+	status := JobStatus(job)
+	return status == JobComplete || status == JobFailed
+}
+
+// JobList lists jobs in the specified namespace
+func (c *Client) JobList(namespace string) (*batchv1.JobList, error) {
+	return c.clientset.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{})
+}
+
+// JobPrint prints jobs in kubectl-like format
+func JobPrint(jobs []batchv1.Job, showNamespace bool) {
+	if len(jobs) == 0 {
+		fmt.Println("No resources found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if showNamespace {
+		fmt.Fprintln(w, "NAMESPACE\tNAME\tSTATUS\tCOMPLETIONS\tDURATION\tAGE")
+	} else {
+		fmt.Fprintln(w, "NAME\tSTATUS\tCOMPLETIONS\tDURATION\tAGE")
+	}
+	for i := range jobs {
+		summary := SummarizeJob(&jobs[i])
+		if showNamespace {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				summary.Namespace, summary.Name, summary.Status, summary.Completions, summary.Duration, summary.Age)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				summary.Name, summary.Status, summary.Completions, summary.Duration, summary.Age)
+		}
+	}
+}
+
+// JobEvent is a change of a cached job
+type JobEvent struct {
+	Type string     `json:"type"`
+	Job  JobSummary `json:"job"`
+}
+
+// JobEventHandler is notified of job changes
+type JobEventHandler interface {
+	OnJobEvent(event JobEvent)
+}
+
+// JobEventHandlerFunc adapts a function to JobEventHandler
+type JobEventHandlerFunc func(event JobEvent)
+
+// OnJobEvent calls f
+func (f JobEventHandlerFunc) OnJobEvent(event JobEvent) {
+	f(event)
+}
+
+// JobInformer caches the Jobs and CronJobs of a namespace, or of all
+// namespaces, with jobs indexed by the CronJob that created them. Handlers
+// are notified of job changes, with a finished event when a job completes or
+// fails.
+type JobInformer struct {
+	jobs      cache.SharedIndexInformer
+	cronJobs  cache.SharedIndexInformer
+	set       *InformerSet
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	// Notifies handlers of the shared jobs informer's events
+	registration cache.ResourceEventHandlerRegistration
+
+	mu       sync.RWMutex
+	handlers []JobEventHandler
+}
+
+// NewJobInformer creates an informer watching the Jobs and CronJobs of
+// namespace; an empty namespace watches all namespaces. Managed fields are
+// dropped from cached objects.
+func NewJobInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *JobInformer {
+	return NewJobInformerFor(NewInformerSet(clientset, namespace, resyncPeriod))
+}
+
+// NewJobInformerFor creates an informer sharing the Job and CronJob watches
+// and caches of set
+func NewJobInformerFor(set *InformerSet) *JobInformer {
+	jobs := set.informerFor(&batchv1.Job{}, stripManagedFields, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(set.scoped(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.BatchV1().Jobs(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.BatchV1().Jobs(namespace).Watch(context.TODO(), options)
+			},
+		}), &batchv1.Job{}, resyncPeriod, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+			cronJobIndex:         indexByCronJob,
+		})
+	})
+
+	cronJobs := set.informerFor(&batchv1.CronJob{}, stripManagedFields, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(set.scoped(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.BatchV1().CronJobs(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.BatchV1().CronJobs(namespace).Watch(context.TODO(), options)
+			},
+		}), &batchv1.CronJob{}, resyncPeriod, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})
+	})
+
+	ji := &JobInformer{
+		jobs:     jobs,
+		cronJobs: cronJobs,
+		set:      set,
+	}
+	// The jobs informer may be shared, so the handler is removed on Stop
+	ji.registration, _ = jobs.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if job, ok := obj.(*batchv1.Job); ok {
+				ji.notify(JobEventAdded, job)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldJob, ok := oldObj.(*batchv1.Job)
+			newJob, ok2 := newObj.(*batchv1.Job)
+			if !ok || !ok2 || oldJob.ResourceVersion == newJob.ResourceVersion {
+				return
+			}
+			if !jobFinished(oldJob) && jobFinished(newJob) {
+				ji.notify(JobEventFinished, newJob)
+				return
+			}
+			ji.notify(JobEventUpdated, newJob)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if job, ok := deletedObject(obj).(*batchv1.Job); ok {
+				ji.notify(JobEventDeleted, job)
+			}
+		},
+	})
+	return ji
+}
+
+// indexByCronJob indexes a job by the namespace/name of the CronJob
+// controlling it
+func indexByCronJob(obj interface{}) ([]string, error) {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil, nil
+	}
+	owner := metav1.GetControllerOf(job)
+	if owner == nil || owner.Kind != "CronJob" {
+		return nil, nil
+	}
+	return []string{job.Namespace + "/" + owner.Name}, nil
+}
+
+// notify delivers a job event to the handlers
+func (ji *JobInformer) notify(eventType string, job *batchv1.Job) {
+	ji.mu.RLock()
+	handlers := ji.handlers
+	ji.mu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+	event := JobEvent{Type: eventType, Job: SummarizeJob(job)}
+	for _, handler := range handlers {
+		handler.OnJobEvent(event)
+	}
+}
+
+// AddHandler registers a handler notified of job changes. Jobs cached before
+// the handler is added are not replayed.
+func (ji *JobInformer) AddHandler(handler JobEventHandler) {
+	ji.mu.Lock()
+	defer ji.mu.Unlock()
+	ji.handlers = append(ji.handlers, handler)
+}
+
+// Start starts watching Jobs and CronJobs and waits until both caches sync
+// or ctx is done; on failure the informer is stopped
+func (ji *JobInformer) Start(ctx context.Context) error {
+	ji.startOnce.Do(func() {
+		ji.set.run(ji.jobs, ji.cronJobs)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), ji.jobs.HasSynced, ji.cronJobs.HasSynced) {
+		ji.Stop()
+		return fmt.Errorf("failed to sync Job and CronJob caches: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching Jobs and CronJobs
+func (ji *JobInformer) Stop() {
+	ji.stopOnce.Do(func() {
+		if ji.registration != nil {
+			_ = ji.jobs.RemoveEventHandler(ji.registration)
+		}
+		ji.set.release(ji.jobs, ji.cronJobs)
+	})
+}
+
+// HasSynced reports whether the Job and CronJob caches have synced
+func (ji *JobInformer) HasSynced() bool {
+	return ji.jobs.HasSynced() && ji.cronJobs.HasSynced()
+}
+
+// GetJob returns a job from the cache, and false when it doesn't exist
+func (ji *JobInformer) GetJob(namespace, name string) (*batchv1.Job, bool) {
+	obj, exists, err := ji.jobs.GetIndexer().GetByKey(namespace + "/" + name)
+	if err != nil || !exists {
+		return nil, false
+	}
+	job, ok := obj.(*batchv1.Job)
+	return job, ok
+}
+
+// ListJobs returns the cached jobs of a namespace, or of all namespaces when
+// namespace is empty, sorted by namespace and name
+func (ji *JobInformer) ListJobs(namespace string) ([]*batchv1.Job, error) {
+	objects, err := listNamespace(ji.jobs.GetIndexer(), namespace)
+	if err != nil {
+		return nil, err
+	}
+	return sortedJobs(objects), nil
+}
+
+// CronJobJobs returns the cached jobs created by a CronJob, most recent first
+func (ji *JobInformer) CronJobJobs(namespace, name string) ([]*batchv1.Job, error) {
+	objects, err := ji.jobs.GetIndexer().ByIndex(cronJobIndex, namespace+"/"+name)
+	if err != nil {
+		return nil, err
+	}
+	jobs := sortedJobs(objects)
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[j].CreationTimestamp.Before(&jobs[i].CreationTimestamp)
+	})
+	return jobs, nil
+}
+
+// GetCronJob returns a CronJob from the cache, and false when it doesn't exist
+func (ji *JobInformer) GetCronJob(namespace, name string) (*batchv1.CronJob, bool) {
+	obj, exists, err := ji.cronJobs.GetIndexer().GetByKey(namespace + "/" + name)
+	if err != nil || !exists {
+		return nil, false
+	}
+	cronJob, ok := obj.(*batchv1.CronJob)
+	return cronJob, ok
+}
+
+// ListCronJobs returns the cached CronJobs of a namespace, or of all
+// namespaces when namespace is empty, sorted by namespace and name
+func (ji *JobInformer) ListCronJobs(namespace string) ([]*batchv1.CronJob, error) {
+	objects, err := listNamespace(ji.cronJobs.GetIndexer(), namespace)
+	if err != nil {
+		return nil, err
+	}
+	cronJobs := make([]*batchv1.CronJob, 0, len(objects))
+	for _, obj := range objects {
+		if cronJob, ok := obj.(*batchv1.CronJob); ok {
+			cronJobs = append(cronJobs, cronJob)
+		}
+	}
+	sort.Slice(cronJobs, func(i, j int) bool {
+		if cronJobs[i].Namespace != cronJobs[j].Namespace {
+			return cronJobs[i].Namespace < cronJobs[j].Namespace
+		}
+		return cronJobs[i].Name < cronJobs[j].Name
+	})
+	return cronJobs, nil
+}
+
+// SummarizeCronJob summarizes a CronJob, counting the outcomes of the cached
+// jobs it created
+func (ji *JobInformer) SummarizeCronJob(cronJob *batchv1.CronJob) CronJobSummary {
+	summary := CronJobSummary{
+		Name:      cronJob.Name,
+		Namespace: cronJob.Namespace,
+		Schedule:  cronJob.Spec.Schedule,
+		Suspended: cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		Active:    len(cronJob.Status.Active),
+		Age:       FormatAge(cronJob.CreationTimestamp.Time),
+	}
+	if cronJob.Spec.TimeZone != nil {
+		summary.TimeZone = *cronJob.Spec.TimeZone
+	}
+	if cronJob.Status.LastScheduleTime != nil {
+		summary.LastScheduleTime = cronJob.Status.LastScheduleTime.Time
+	}
+	if cronJob.Status.LastSuccessfulTime != nil {
+		summary.LastSuccessfulTime = cronJob.Status.LastSuccessfulTime.Time
+	}
+
+	jobs, _ := ji.CronJobJobs(cronJob.Namespace, cronJob.Name)
+	for _, job := range jobs {
+		switch JobStatus(job) {
+		case JobComplete:
+			summary.Succeeded++
+		case JobFailed:
+			summary.Failed++
+		}
+	}
+	return summary
+}
+
+// listNamespace lists the objects of an indexer in a namespace, or all of
+// them when namespace is empty
+func listNamespace(indexer cache.Indexer, namespace string) ([]interface{}, error) {
+	if namespace == "" {
+		return indexer.List(), nil
+	}
+	return indexer.ByIndex(cache.NamespaceIndex, namespace)
+}
+
+// sortedJobs returns the jobs among objects sorted by namespace and name
+func sortedJobs(objects []interface{}) []*batchv1.Job {
+	jobs := make([]*batchv1.Job, 0, len(objects))
+	for _, obj := range objects {
+		if job, ok := obj.(*batchv1.Job); ok {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Namespace != jobs[j].Namespace {
+			return jobs[i].Namespace < jobs[j].Namespace
+		}
+		return jobs[i].Name < jobs[j].Name
+	})
+	return jobs
+}
@@ -0,0 +1,121 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestJob returns a job of namespace shop, created by cronJob unless it
+// is empty
+func newTestJob(name, cronJob string, created time.Time) *batchv1.Job {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         "shop",
+		CreationTimestamp: metav1.NewTime(created),
+	}}
+	if cronJob != "" {
+		controller := true
+		job.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: cronJob, Controller: &controller}}
+	}
+	return job
+}
+
+func TestSummarizeJob(t *testing.T) {
+	start := time.Now().Add(-10 * time.Minute)
+	completions := int32(3)
+	job := newTestJob("backup-1", "backup", start)
+	job.Spec.Completions = &completions
+	job.Status = batchv1.JobStatus{
+		StartTime:      &metav1.Time{Time: start},
+		CompletionTime: &metav1.Time{Time: start.Add(90 * time.Second)},
+		Succeeded:      3,
+		Failed:         1,
+		Conditions:     []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+	}
+
+	summary := SummarizeJob(job)
+	if summary.Status != JobComplete || summary.Completions != "3/3" || summary.Failed != 1 || summary.CronJob != "backup" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if summary.Duration != "1m30s" {
+		t.Errorf("Expected the duration from start to completion, got %q", summary.Duration)
+	}
+
+	suspend := true
+	for want, job := range map[string]*batchv1.Job{
+		JobFailed:    {Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}}},
+		JobSuspended: {Spec: batchv1.JobSpec{Suspend: &suspend}},
+		JobRunning:   {Status: batchv1.JobStatus{Active: 1}},
+		JobPending:   {},
+	} {
+		if got := JobStatus(job); got != want {
+			t.Errorf("JobStatus() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestJobInformer(t *testing.T) {
+	now := time.Now()
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "shop"},
+		Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *"},
+		Status:     batchv1.CronJobStatus{LastScheduleTime: &metav1.Time{Time: now}},
+	}
+	older := newTestJob("backup-1", "backup", now.Add(-2*time.Hour))
+	older.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	running := newTestJob("backup-2", "backup", now.Add(-time.Minute))
+	running.Status.Active = 1
+	clientset := fake.NewSimpleClientset(cronJob, older, running, newTestJob("migrate", "", now))
+
+	informer := NewJobInformer(clientset, "", time.Minute)
+	events := make(chan JobEvent, 8)
+	informer.AddHandler(JobEventHandlerFunc(func(event JobEvent) { events <- event }))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	jobs, err := informer.ListJobs("shop")
+	if err != nil || len(jobs) != 3 || jobs[0].Name != "backup-1" {
+		t.Fatalf("ListJobs() = %v, %v", jobs, err)
+	}
+	created, err := informer.CronJobJobs("shop", "backup")
+	if err != nil || len(created) != 2 || created[0].Name != "backup-2" {
+		t.Fatalf("Expected the jobs of the CronJob most recent first, got %v, %v", created, err)
+	}
+	summary := informer.SummarizeCronJob(cronJob)
+	if summary.Schedule != "0 * * * *" || summary.Failed != 1 || summary.Succeeded != 0 || summary.LastScheduleTime.IsZero() {
+		t.Errorf("Unexpected CronJob summary: %+v", summary)
+	}
+
+	// Existing jobs are added, and completing a job finishes it
+	for range 3 {
+		if event := <-events; event.Type != JobEventAdded {
+			t.Errorf("Expected added events for cached jobs, got %+v", event)
+		}
+	}
+	running = running.DeepCopy()
+	running.ResourceVersion = "2"
+	running.Status.Active = 0
+	running.Status.Succeeded = 1
+	running.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	if _, err := clientset.BatchV1().Jobs("shop").UpdateStatus(ctx, running, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	select {
+	case event := <-events:
+		if event.Type != JobEventFinished || event.Job.Name != "backup-2" || event.Job.Status != JobComplete {
+			t.Errorf("Expected backup-2 to finish, got %+v", event)
+		}
+	case <-ctx.Done():
+		t.Fatal("Expected a finished event")
+	}
+}
@@ -0,0 +1,61 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// Watch event types, as printed by kubectl
+const (
+	WatchEventAdded    = "ADDED"
+	WatchEventModified = "MODIFIED"
+	WatchEventDeleted  = "DELETED"
+)
+
+// WatchEvent is a deployment event as streamed by JSONStreamEventHandler
+type WatchEvent struct {
+	Type    string             `json:"type"`
+	Time    time.Time          `json:"time"`
+	Object  *appsv1.Deployment `json:"object"`
+	Changes []DeploymentChange `json:"changes,omitempty"`
+}
+
+// JSONStreamEventHandler writes each deployment event as one JSON object per
+// line, for jq pipelines and external automation
+type JSONStreamEventHandler struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONStreamEventHandler creates a handler writing events to w
+func NewJSONStreamEventHandler(w io.Writer) *JSONStreamEventHandler {
+	return &JSONStreamEventHandler{encoder: json.NewEncoder(w)}
+}
+
+func (h *JSONStreamEventHandler) OnAdd(obj *appsv1.Deployment) {
+	h.write(WatchEvent{Type: WatchEventAdded, Object: obj})
+}
+
+func (h *JSONStreamEventHandler) OnUpdate(oldObj, newObj *appsv1.Deployment) {
+	h.write(WatchEvent{Type: WatchEventModified, Object: newObj, Changes: CompareDeployments(oldObj, newObj)})
+}
+
+func (h *JSONStreamEventHandler) OnDelete(obj *appsv1.Deployment) {
+	h.write(WatchEvent{Type: WatchEventDeleted, Object: obj})
+}
+
+// write encodes an event, dropping it when the writer fails, e.g. once the
+// reading end of a pipe is closed
+func (h *JSONStreamEventHandler) write(event WatchEvent) {
+	event.Time = time.Now().UTC()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.encoder.Encode(event); err != nil {
+		informerLog().Debug().Err(err).Str("type", event.Type).Msg("Failed to write watch event")
+	}
+}
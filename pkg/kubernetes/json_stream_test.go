@@ -0,0 +1,62 @@
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestJSONStreamEventHandler(t *testing.T) {
+	var out bytes.Buffer
+	handler := NewJSONStreamEventHandler(&out)
+
+	web := newSnapshotDeployment("shop", "web", "1")
+	scaled := web.DeepCopy()
+	scaled.ResourceVersion = "2"
+	scaled.Spec.Replicas = int32Ptr(3)
+	handler.OnAdd(web)
+	handler.OnUpdate(web, scaled)
+	handler.OnDelete(scaled)
+
+	var events []WatchEvent
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var event WatchEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Expected one JSON object per line, got %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+
+	for i, want := range []string{WatchEventAdded, WatchEventModified, WatchEventDeleted} {
+		if events[i].Type != want || events[i].Object == nil || events[i].Object.Name != "web" || events[i].Time.IsZero() {
+			t.Errorf("Event %d = %+v, want a %s event of web", i, events[i], want)
+		}
+	}
+	if changes := events[1].Changes; len(changes) != 1 || changes[0].Field != "replicas" || changes[0].NewValue != 3.0 {
+		t.Errorf("Expected the replica change, got %+v", changes)
+	}
+	if events[0].Changes != nil || events[2].Changes != nil {
+		t.Error("Expected changes on updates only")
+	}
+}
+
+func TestDeploymentInformer_ReplaceEventHandlers(t *testing.T) {
+	informer := NewDeploymentInformer(fake.NewSimpleClientset(), "test", 30*time.Second)
+
+	informer.ReplaceEventHandlers(NewJSONStreamEventHandler(&bytes.Buffer{}))
+
+	if len(informer.eventHandlers) != 1 {
+		t.Fatalf("expected 1 event handler, got %d", len(informer.eventHandlers))
+	}
+	if _, ok := informer.eventHandlers[0].(*JSONStreamEventHandler); !ok {
+		t.Errorf("expected the default handler to be replaced, got %T", informer.eventHandlers[0])
+	}
+}
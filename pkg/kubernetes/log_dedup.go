@@ -0,0 +1,77 @@
+package kubernetes
+
+import (
+	"sync"
+	"time"
+)
+
+// logDedupKey identifies repeated informer entries: a kind of change, such
+// as a status update, of a deployment
+type logDedupKey struct {
+	namespace string
+	name      string
+	kind      string
+}
+
+// logDedupEntry counts the entries suppressed in a window
+type logDedupEntry struct {
+	message  string
+	repeated int
+}
+
+// logDeduper logs the first informer entry of a key in a window and counts
+// the others, which are summarized in a "repeated N times" entry when the
+// window ends. A nil deduper logs every entry.
+type logDeduper struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[logDedupKey]*logDedupEntry
+}
+
+// newLogDeduper returns a deduper of entries within window, nil when window
+// is not positive
+func newLogDeduper(window time.Duration) *logDeduper {
+	if window <= 0 {
+		return nil
+	}
+	return &logDeduper{window: window, seen: map[logDedupKey]*logDedupEntry{}}
+}
+
+// allow reports whether the entry of a change of kind to a deployment is
+// logged, counting it otherwise
+func (d *logDeduper) allow(namespace, name, kind, message string) bool {
+	if d == nil {
+		return true
+	}
+	key := logDedupKey{namespace: namespace, name: name, kind: kind}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if entry, ok := d.seen[key]; ok {
+		entry.repeated++
+		return false
+	}
+	d.seen[key] = &logDedupEntry{message: message}
+	time.AfterFunc(d.window, func() { d.flush(key) })
+	return true
+}
+
+// flush ends the window of key, logging how often its entry repeated
+func (d *logDeduper) flush(key logDedupKey) {
+	d.mu.Lock()
+	entry := d.seen[key]
+	delete(d.seen, key)
+	d.mu.Unlock()
+
+	if entry == nil || entry.repeated == 0 {
+		return
+	}
+	informerLog().Info().
+		Str("namespace", key.namespace).
+		Str("name", key.name).
+		Str("change_kind", key.kind).
+		Int("repeated", entry.repeated).
+		Dur("window", d.window).
+		Msgf("%s repeated %d times", entry.message, entry.repeated)
+}
@@ -0,0 +1,50 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogDeduper(t *testing.T) {
+	var disabled *logDeduper
+	if !disabled.allow("shop", "web", "status", "Deployment updated") || !disabled.allow("shop", "web", "status", "Deployment updated") {
+		t.Error("Expected a nil deduper to log every entry")
+	}
+	if newLogDeduper(0) != nil {
+		t.Error("Expected no deduper without a window")
+	}
+
+	d := newLogDeduper(time.Hour)
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if d.allow("shop", "web", "status", "Deployment updated") {
+			allowed++
+		}
+	}
+	if allowed != 1 {
+		t.Errorf("Expected the first entry only, got %d", allowed)
+	}
+	if !d.allow("shop", "web", "spec", "Deployment updated") || !d.allow("shop", "api", "status", "Deployment updated") {
+		t.Error("Expected other deployments and kinds of change to be logged")
+	}
+
+	key := logDedupKey{namespace: "shop", name: "web", kind: "status"}
+	if repeated := d.seen[key].repeated; repeated != 4 {
+		t.Errorf("Expected 4 repeated entries, got %d", repeated)
+	}
+	d.flush(key)
+	if !d.allow("shop", "web", "status", "Deployment updated") {
+		t.Error("Expected a new window once the previous one ended")
+	}
+
+	short := newLogDeduper(10 * time.Millisecond)
+	short.allow("shop", "web", "status", "Deployment updated")
+	short.allow("shop", "web", "status", "Deployment updated")
+	time.Sleep(50 * time.Millisecond)
+	short.mu.Lock()
+	pending := len(short.seen)
+	short.mu.Unlock()
+	if pending != 0 {
+		t.Errorf("Expected the window to end on its own, got %d pending keys", pending)
+	}
+}
@@ -0,0 +1,241 @@
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultContainerAnnotation names the container kubectl logs and exec use
+// when none is given
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// LogOptions configures the logs streamed by StreamLogs
+type LogOptions struct {
+	// Container streamed from each pod; empty streams the container of the
+	// kubectl.kubernetes.io/default-container annotation, or the first one
+	Container string
+
+	// Stream every container of each pod
+	AllContainers bool
+
+	// Keep streaming new lines until the context is done
+	Follow bool
+
+	// Only stream lines newer than Since (0 = all)
+	Since time.Duration
+
+	// Only stream the last TailLines lines of each container (negative = all)
+	TailLines int64
+
+	// Start each line with its timestamp
+	Timestamps bool
+
+	// Start each line with [pod/NAME/CONTAINER]. Lines of several
+	// containers are always prefixed.
+	Prefix bool
+}
+
+// LogSource is a container whose logs are streamed
+type LogSource struct {
+	Namespace string
+	Pod       string
+	Container string
+}
+
+// String returns the source as pod/NAME/CONTAINER, the prefix of its lines
+func (s LogSource) String() string {
+	return "pod/" + s.Pod + "/" + s.Container
+}
+
+// LogPods returns the pods whose logs target names: a pod as NAME or
+// pod/NAME, or the pods of a deployment as deploy/NAME or deployment/NAME.
+// Pods of a deployment that haven't started yet are left out.
+func (c *Client) LogPods(ctx context.Context, namespace, target string) ([]corev1.Pod, error) {
+	kind, name, found := strings.Cut(target, "/")
+	if !found {
+		kind, name = "pod", target
+	}
+	if name == "" {
+		return nil, fmt.Errorf("missing name in %q", target)
+	}
+
+	switch strings.ToLower(kind) {
+	case "pod", "pods", "po":
+		pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return []corev1.Pod{*pod}, nil
+	case "deployment", "deployments", "deploy":
+		dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		options, err := selectorOptions(dep)
+		if err != nil {
+			return nil, err
+		}
+		list, err := c.clientset.CoreV1().Pods(namespace).List(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		var pods []corev1.Pod
+		for _, pod := range list.Items {
+			if pod.Status.Phase != corev1.PodPending {
+				pods = append(pods, pod)
+			}
+		}
+		if len(pods) == 0 {
+			return nil, fmt.Errorf("deployment %s/%s has no started pods", namespace, name)
+		}
+		sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+		return pods, nil
+	}
+	return nil, fmt.Errorf("unsupported resource %q (use a pod name, pod/NAME or deploy/NAME)", kind)
+}
+
+// LogSources returns the containers of pods streamed with opts
+func LogSources(pods []corev1.Pod, opts LogOptions) ([]LogSource, error) {
+	var sources []LogSource
+	for _, pod := range pods {
+		var containers []string
+		switch {
+		case opts.AllContainers:
+			for _, container := range pod.Spec.Containers {
+				containers = append(containers, container.Name)
+			}
+		case opts.Container != "":
+			if !hasContainer(&pod, opts.Container) {
+				return nil, fmt.Errorf("container %s is not valid for pod %s", opts.Container, pod.Name)
+			}
+			containers = []string{opts.Container}
+		default:
+			container := pod.Annotations[defaultContainerAnnotation]
+			if container == "" || !hasContainer(&pod, container) {
+				if len(pod.Spec.Containers) == 0 {
+					return nil, fmt.Errorf("pod %s has no containers", pod.Name)
+				}
+				container = pod.Spec.Containers[0].Name
+			}
+			containers = []string{container}
+		}
+
+		for _, container := range containers {
+			sources = append(sources, LogSource{Namespace: pod.Namespace, Pod: pod.Name, Container: container})
+		}
+	}
+	return sources, nil
+}
+
+// hasContainer reports whether a pod has a container or init container of
+// that name
+func hasContainer(pod *corev1.Pod, name string) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// StreamLogs streams the logs of sources to w concurrently, writing whole
+// lines so the streams don't interleave within a line. It returns once every
+// stream ends, or ctx is done while following; a source that fails doesn't
+// stop the others, and its error is returned.
+func StreamLogs(ctx context.Context, clientset kubernetes.Interface, sources []LogSource, opts LogOptions, w io.Writer) error {
+	prefix := opts.Prefix || len(sources) > 1
+	out := &lineWriter{w: w}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(sources))
+	for i, source := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := streamSource(ctx, clientset, source, opts, prefix, out); err != nil {
+				errs[i] = fmt.Errorf("failed to stream logs of %s: %w", source, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// streamSource copies the log lines of one container to out
+func streamSource(ctx context.Context, clientset kubernetes.Interface, source LogSource, opts LogOptions, prefix bool, out *lineWriter) error {
+	logOptions := &corev1.PodLogOptions{
+		Container:  source.Container,
+		Follow:     opts.Follow,
+		Timestamps: opts.Timestamps,
+	}
+	if opts.Since > 0 {
+		seconds := int64(opts.Since.Round(time.Second).Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		logOptions.SinceSeconds = &seconds
+	}
+	if opts.TailLines >= 0 {
+		tailLines := opts.TailLines
+		logOptions.TailLines = &tailLines
+	}
+
+	stream, err := clientset.CoreV1().Pods(source.Namespace).GetLogs(source.Pod, logOptions).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	linePrefix := ""
+	if prefix {
+		linePrefix = "[" + source.String() + "] "
+	}
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n"
+			}
+			if werr := out.write(linePrefix + line); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF || (err != nil && ctx.Err() != nil) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// lineWriter serializes the lines written by concurrent streams
+type lineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// write writes one line
+func (lw *lineWriter) write(line string) error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	_, err := io.WriteString(lw.w, line)
+	return err
+}
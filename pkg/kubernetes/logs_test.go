@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLogPods(t *testing.T) {
+	newPod := func(name string, phase corev1.PodPhase, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: labels},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	web := map[string]string{"app": "web"}
+	client := &Client{clientset: fake.NewSimpleClientset(
+		NewDeployment("shop", "web", "nginx", 3),
+		newPod("web-b", corev1.PodRunning, web),
+		newPod("web-a", corev1.PodRunning, web),
+		newPod("web-c", corev1.PodPending, web),
+		newPod("api", corev1.PodRunning, map[string]string{"app": "api"}),
+	)}
+	ctx := context.Background()
+
+	tests := []struct {
+		target   string
+		expected []string
+	}{
+		{"api", []string{"api"}},
+		{"pod/api", []string{"api"}},
+		// Pods of a deployment that haven't started are left out
+		{"deploy/web", []string{"web-a", "web-b"}},
+		{"deployment/web", []string{"web-a", "web-b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			pods, err := client.LogPods(ctx, "shop", tt.target)
+			if err != nil {
+				t.Fatalf("LogPods() error = %v", err)
+			}
+			var names []string
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+
+	for _, target := range []string{"svc/web", "deploy/", "deploy/missing"} {
+		if _, err := client.LogPods(ctx, "shop", target); err == nil {
+			t.Errorf("Expected an error for %s", target)
+		}
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web-a",
+			Namespace:   "shop",
+			Annotations: map[string]string{defaultContainerAnnotation: "web"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "proxy"}, {Name: "web"}}},
+	}
+
+	sources, err := LogSources([]corev1.Pod{pod}, LogOptions{})
+	if err != nil || len(sources) != 1 || sources[0].Container != "web" {
+		t.Fatalf("Expected the default container, got %v, %v", sources, err)
+	}
+	if _, err := LogSources([]corev1.Pod{pod}, LogOptions{Container: "db"}); err == nil {
+		t.Error("Expected an error for a container the pod doesn't have")
+	}
+
+	clientset := fake.NewSimpleClientset(&pod)
+	var out bytes.Buffer
+	if err := StreamLogs(context.Background(), clientset, sources, LogOptions{TailLines: -1}, &out); err != nil {
+		t.Fatalf("StreamLogs() error = %v", err)
+	}
+	if out.String() != "fake logs\n" {
+		t.Errorf("Expected the lines of one container without prefix, got %q", out.String())
+	}
+
+	// Lines of several containers are prefixed with their source
+	sources, _ = LogSources([]corev1.Pod{pod}, LogOptions{AllContainers: true})
+	out.Reset()
+	if err := StreamLogs(context.Background(), clientset, sources, LogOptions{TailLines: -1}, &out); err != nil {
+		t.Fatalf("StreamLogs() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a line of each container, got %q", out.String())
+	}
+	for _, prefix := range []string{"[pod/web-a/proxy] fake logs", "[pod/web-a/web] fake logs"} {
+		if lines[0] != prefix && lines[1] != prefix {
+			t.Errorf("Expected the line %q, got %q", prefix, lines)
+		}
+	}
+}
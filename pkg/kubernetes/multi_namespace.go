@@ -0,0 +1,358 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// multiNamespaceInformer is a shared informer watching a list of namespaces
+// through one informer per namespace, so that only those namespaces need to
+// be listable. Objects are routed to the informer of their namespace, and
+// lists merge the caches of all namespaces.
+type multiNamespaceInformer struct {
+	namespaces []string
+	informers  map[string]cache.SharedIndexInformer
+}
+
+// newMultiNamespaceInformer creates the informer of namespaces, with
+// newInformer creating the informer of each namespace
+func newMultiNamespaceInformer(namespaces []string, newInformer func(namespace string) cache.SharedIndexInformer) *multiNamespaceInformer {
+	m := &multiNamespaceInformer{
+		namespaces: namespaces,
+		informers:  make(map[string]cache.SharedIndexInformer, len(namespaces)),
+	}
+	for _, namespace := range namespaces {
+		m.informers[namespace] = newInformer(namespace)
+	}
+	return m
+}
+
+// multiNamespaceRegistration is the registration of a handler with the
+// informer of each namespace
+type multiNamespaceRegistration map[string]cache.ResourceEventHandlerRegistration
+
+// HasSynced reports whether the handler has synced in every namespace
+func (r multiNamespaceRegistration) HasSynced() bool {
+	for _, registration := range r {
+		if !registration.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// AddEventHandler adds handler to the informer of every namespace
+func (m *multiNamespaceInformer) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	return m.addEventHandler(func(informer cache.SharedIndexInformer) (cache.ResourceEventHandlerRegistration, error) {
+		return informer.AddEventHandler(handler)
+	})
+}
+
+// AddEventHandlerWithResyncPeriod adds handler to the informer of every
+// namespace
+func (m *multiNamespaceInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) (cache.ResourceEventHandlerRegistration, error) {
+	return m.addEventHandler(func(informer cache.SharedIndexInformer) (cache.ResourceEventHandlerRegistration, error) {
+		return informer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	})
+}
+
+// addEventHandler adds a handler with add to every informer, removing it
+// again from all of them if one fails
+func (m *multiNamespaceInformer) addEventHandler(add func(cache.SharedIndexInformer) (cache.ResourceEventHandlerRegistration, error)) (cache.ResourceEventHandlerRegistration, error) {
+	registration := make(multiNamespaceRegistration, len(m.informers))
+	for namespace, informer := range m.informers {
+		r, err := add(informer)
+		if err != nil {
+			_ = m.RemoveEventHandler(registration)
+			return nil, fmt.Errorf("namespace %s: %w", namespace, err)
+		}
+		registration[namespace] = r
+	}
+	return registration, nil
+}
+
+// RemoveEventHandler removes a handler added by AddEventHandler
+func (m *multiNamespaceInformer) RemoveEventHandler(handle cache.ResourceEventHandlerRegistration) error {
+	registration, ok := handle.(multiNamespaceRegistration)
+	if !ok {
+		return fmt.Errorf("registration %T was not added to a multi-namespace informer", handle)
+	}
+	for namespace, r := range registration {
+		if err := m.informers[namespace].RemoveEventHandler(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetStore returns the merged caches of all namespaces
+func (m *multiNamespaceInformer) GetStore() cache.Store {
+	return m.GetIndexer()
+}
+
+// GetController returns the informer itself, which runs the informers of
+// all namespaces
+func (m *multiNamespaceInformer) GetController() cache.Controller {
+	return m
+}
+
+// Run runs the informers of all namespaces until stopCh is closed
+func (m *multiNamespaceInformer) Run(stopCh <-chan struct{}) {
+	var wg sync.WaitGroup
+	for _, informer := range m.informers {
+		wg.Add(1)
+		go func(informer cache.SharedIndexInformer) {
+			defer wg.Done()
+			informer.Run(stopCh)
+		}(informer)
+	}
+	wg.Wait()
+}
+
+// HasSynced reports whether the caches of all namespaces have synced
+func (m *multiNamespaceInformer) HasSynced() bool {
+	for _, informer := range m.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// LastSyncResourceVersion returns "", as every namespace is at its own
+// resource version
+func (m *multiNamespaceInformer) LastSyncResourceVersion() string {
+	return ""
+}
+
+// SetWatchErrorHandler sets handler on the informers of all namespaces
+func (m *multiNamespaceInformer) SetWatchErrorHandler(handler cache.WatchErrorHandler) error {
+	for _, informer := range m.informers {
+		if err := informer.SetWatchErrorHandler(handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetTransform sets handler on the informers of all namespaces
+func (m *multiNamespaceInformer) SetTransform(handler cache.TransformFunc) error {
+	for _, informer := range m.informers {
+		if err := informer.SetTransform(handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsStopped reports whether the informers of all namespaces have stopped
+func (m *multiNamespaceInformer) IsStopped() bool {
+	for _, informer := range m.informers {
+		if !informer.IsStopped() {
+			return false
+		}
+	}
+	return true
+}
+
+// AddIndexers adds indexers to the informers of all namespaces
+func (m *multiNamespaceInformer) AddIndexers(indexers cache.Indexers) error {
+	for _, informer := range m.informers {
+		if err := informer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetIndexer returns the merged caches of all namespaces
+func (m *multiNamespaceInformer) GetIndexer() cache.Indexer {
+	return multiNamespaceIndexer{m}
+}
+
+// multiNamespaceIndexer merges the caches of a multi-namespace informer
+type multiNamespaceIndexer struct {
+	m *multiNamespaceInformer
+}
+
+// indexerOf returns the cache of obj's namespace, nil when the namespace
+// isn't watched
+func (i multiNamespaceIndexer) indexerOf(obj interface{}) (cache.Indexer, error) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return nil, err
+	}
+	return i.indexerOfKey(key)
+}
+
+// indexerOfKey returns the cache of the namespace of a namespace/name key
+func (i multiNamespaceIndexer) indexerOfKey(key string) (cache.Indexer, error) {
+	namespace, _, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+	informer, ok := i.m.informers[namespace]
+	if !ok {
+		return nil, nil
+	}
+	return informer.GetIndexer(), nil
+}
+
+// Add adds obj to the cache of its namespace
+func (i multiNamespaceIndexer) Add(obj interface{}) error {
+	return i.update(obj, cache.Indexer.Add)
+}
+
+// Update updates obj in the cache of its namespace
+func (i multiNamespaceIndexer) Update(obj interface{}) error {
+	return i.update(obj, cache.Indexer.Update)
+}
+
+// Delete deletes obj from the cache of its namespace
+func (i multiNamespaceIndexer) Delete(obj interface{}) error {
+	return i.update(obj, cache.Indexer.Delete)
+}
+
+// update applies op to obj and the cache of its namespace
+func (i multiNamespaceIndexer) update(obj interface{}, op func(cache.Indexer, interface{}) error) error {
+	indexer, err := i.indexerOf(obj)
+	if err != nil {
+		return err
+	}
+	if indexer == nil {
+		return fmt.Errorf("namespace of %T is not watched", obj)
+	}
+	return op(indexer, obj)
+}
+
+// List returns the objects of all namespaces
+func (i multiNamespaceIndexer) List() []interface{} {
+	var objects []interface{}
+	for _, namespace := range i.m.namespaces {
+		objects = append(objects, i.m.informers[namespace].GetIndexer().List()...)
+	}
+	return objects
+}
+
+// ListKeys returns the keys of all namespaces
+func (i multiNamespaceIndexer) ListKeys() []string {
+	var keys []string
+	for _, namespace := range i.m.namespaces {
+		keys = append(keys, i.m.informers[namespace].GetIndexer().ListKeys()...)
+	}
+	return keys
+}
+
+// Get returns obj from the cache of its namespace
+func (i multiNamespaceIndexer) Get(obj interface{}) (interface{}, bool, error) {
+	indexer, err := i.indexerOf(obj)
+	if err != nil || indexer == nil {
+		return nil, false, err
+	}
+	return indexer.Get(obj)
+}
+
+// GetByKey returns the object of a namespace/name key
+func (i multiNamespaceIndexer) GetByKey(key string) (interface{}, bool, error) {
+	indexer, err := i.indexerOfKey(key)
+	if err != nil || indexer == nil {
+		return nil, false, err
+	}
+	return indexer.GetByKey(key)
+}
+
+// Replace replaces the cache of each namespace with its objects of list
+func (i multiNamespaceIndexer) Replace(list []interface{}, resourceVersion string) error {
+	byNamespace := make(map[cache.Indexer][]interface{}, len(i.m.informers))
+	for _, informer := range i.m.informers {
+		byNamespace[informer.GetIndexer()] = nil
+	}
+	for _, obj := range list {
+		indexer, err := i.indexerOf(obj)
+		if err != nil {
+			return err
+		}
+		if indexer != nil {
+			byNamespace[indexer] = append(byNamespace[indexer], obj)
+		}
+	}
+	for indexer, objects := range byNamespace {
+		if err := indexer.Replace(objects, resourceVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resync resyncs the caches of all namespaces
+func (i multiNamespaceIndexer) Resync() error {
+	for _, informer := range i.m.informers {
+		if err := informer.GetIndexer().Resync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Index returns the objects sharing obj's index values in its namespace
+func (i multiNamespaceIndexer) Index(indexName string, obj interface{}) ([]interface{}, error) {
+	indexer, err := i.indexerOf(obj)
+	if err != nil || indexer == nil {
+		return nil, err
+	}
+	return indexer.Index(indexName, obj)
+}
+
+// IndexKeys returns the keys indexed by indexedValue in all namespaces
+func (i multiNamespaceIndexer) IndexKeys(indexName, indexedValue string) ([]string, error) {
+	var keys []string
+	for _, namespace := range i.m.namespaces {
+		namespaceKeys, err := i.m.informers[namespace].GetIndexer().IndexKeys(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, namespaceKeys...)
+	}
+	return keys, nil
+}
+
+// ListIndexFuncValues returns the values of an index in all namespaces
+func (i multiNamespaceIndexer) ListIndexFuncValues(indexName string) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, namespace := range i.m.namespaces {
+		for _, value := range i.m.informers[namespace].GetIndexer().ListIndexFuncValues(indexName) {
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// ByIndex returns the objects indexed by indexedValue in all namespaces
+func (i multiNamespaceIndexer) ByIndex(indexName, indexedValue string) ([]interface{}, error) {
+	var objects []interface{}
+	for _, namespace := range i.m.namespaces {
+		namespaceObjects, err := i.m.informers[namespace].GetIndexer().ByIndex(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, namespaceObjects...)
+	}
+	return objects, nil
+}
+
+// GetIndexers returns the indexers, which all namespaces share
+func (i multiNamespaceIndexer) GetIndexers() cache.Indexers {
+	return i.m.informers[i.m.namespaces[0]].GetIndexer().GetIndexers()
+}
+
+// AddIndexers adds indexers to the caches of all namespaces
+func (i multiNamespaceIndexer) AddIndexers(indexers cache.Indexers) error {
+	return i.m.AddIndexers(indexers)
+}
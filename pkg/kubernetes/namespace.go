@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NamespaceInformer caches the namespaces of a cluster
+type NamespaceInformer struct {
+	informer  cache.SharedIndexInformer
+	set       *InformerSet
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewNamespaceInformer creates an informer watching all namespaces
+func NewNamespaceInformer(clientset kubernetes.Interface, resyncPeriod time.Duration) *NamespaceInformer {
+	return NewNamespaceInformerFor(NewInformerSet(clientset, "", resyncPeriod))
+}
+
+// NewNamespaceInformerFor creates an informer sharing the namespace watch and
+// cache of set
+func NewNamespaceInformerFor(set *InformerSet) *NamespaceInformer {
+	informer := set.clusterInformerFor(&corev1.Namespace{}, nil, func(clientset kubernetes.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		listWatcher := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.CoreV1().Namespaces().List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.CoreV1().Namespaces().Watch(context.TODO(), options)
+			},
+		}
+		return cache.NewSharedIndexInformer(listWatcher, &corev1.Namespace{}, resyncPeriod, cache.Indexers{})
+	})
+
+	return &NamespaceInformer{
+		informer: informer,
+		set:      set,
+	}
+}
+
+// Start starts watching namespaces and waits until the cache syncs or ctx is
+// done; on failure the informer is stopped. Starting a started informer only
+// waits for the sync.
+func (ni *NamespaceInformer) Start(ctx context.Context) error {
+	ni.startOnce.Do(func() {
+		ni.set.run(ni.informer)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), ni.informer.HasSynced) {
+		ni.Stop()
+		return fmt.Errorf("failed to sync namespace cache: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching namespaces
+func (ni *NamespaceInformer) Stop() {
+	ni.stopOnce.Do(func() {
+		ni.set.release(ni.informer)
+	})
+}
+
+// HasSynced reports whether the namespace cache has synced
+func (ni *NamespaceInformer) HasSynced() bool {
+	return ni.informer.HasSynced()
+}
+
+// GetNamespace returns a namespace from the cache, and false when it doesn't exist
+func (ni *NamespaceInformer) GetNamespace(name string) (*corev1.Namespace, bool) {
+	obj, exists, err := ni.informer.GetIndexer().GetByKey(name)
+	if err != nil || !exists {
+		return nil, false
+	}
+	namespace, ok := obj.(*corev1.Namespace)
+	return namespace, ok
+}
+
+// ListNamespaces returns the cached namespaces sorted by name
+func (ni *NamespaceInformer) ListNamespaces() []*corev1.Namespace {
+	objects := ni.informer.GetIndexer().List()
+	namespaces := make([]*corev1.Namespace, 0, len(objects))
+	for _, obj := range objects {
+		if namespace, ok := obj.(*corev1.Namespace); ok {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	return namespaces
+}
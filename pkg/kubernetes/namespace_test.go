@@ -0,0 +1,41 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNamespaceInformer(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "search"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}},
+	)
+	informer := NewNamespaceInformer(clientset, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	// Starting again only waits for the sync
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("second Start() error = %v", err)
+	}
+
+	namespaces := informer.ListNamespaces()
+	if len(namespaces) != 2 || namespaces[0].Name != "payments" || namespaces[1].Name != "search" {
+		t.Errorf("Expected namespaces sorted by name, got %v", namespaces)
+	}
+	if _, exists := informer.GetNamespace("payments"); !exists {
+		t.Error("Expected payments to be cached")
+	}
+	if _, exists := informer.GetNamespace("unknown"); exists {
+		t.Error("Expected unknown not to be cached")
+	}
+}
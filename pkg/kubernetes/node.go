@@ -0,0 +1,268 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// nodeRoleLabelPrefix prefixes the labels naming the roles of a node, such
+// as node-role.kubernetes.io/control-plane
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// NodeResources is the cpu, memory and pod count of a node's capacity or
+// allocatable resources, as Kubernetes quantities
+type NodeResources struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+	Pods   string `json:"pods"`
+}
+
+// NodeTaint is a taint keeping pods without a matching toleration off a node
+type NodeTaint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// NodeCondition is a condition reported by the kubelet of a node
+type NodeCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime,omitzero"`
+}
+
+// NodeSummary summarizes the status and resources of a node. Status is
+// Ready, NotReady or Unknown, followed by ",SchedulingDisabled" when the node
+// is cordoned, as in kubectl.
+type NodeSummary struct {
+	Name          string            `json:"name"`
+	Status        string            `json:"status"`
+	Roles         []string          `json:"roles,omitempty"`
+	Version       string            `json:"version"`
+	InternalIP    string            `json:"internalIP,omitempty"`
+	Unschedulable bool              `json:"unschedulable"`
+	Capacity      NodeResources     `json:"capacity"`
+	Allocatable   NodeResources     `json:"allocatable"`
+	Taints        []NodeTaint       `json:"taints,omitempty"`
+	Conditions    []NodeCondition   `json:"conditions,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Age           string            `json:"age"`
+}
+
+// SummarizeNode summarizes a node
+func SummarizeNode(node *corev1.Node) NodeSummary {
+	summary := NodeSummary{
+		Name:          node.Name,
+		Status:        nodeStatus(node),
+		Roles:         nodeRoles(node),
+		Version:       node.Status.NodeInfo.KubeletVersion,
+		Unschedulable: node.Spec.Unschedulable,
+		Capacity:      nodeResources(node.Status.Capacity),
+		Allocatable:   nodeResources(node.Status.Allocatable),
+		Labels:        node.Labels,
+		Age:           FormatAge(node.CreationTimestamp.Time),
+	}
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			summary.InternalIP = address.Address
+			break
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		summary.Taints = append(summary.Taints, NodeTaint{
+			Key:    taint.Key,
+			Value:  taint.Value,
+			Effect: string(taint.Effect),
+		})
+	}
+	for _, condition := range node.Status.Conditions {
+		summary.Conditions = append(summary.Conditions, NodeCondition{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime.Time,
+		})
+	}
+	return summary
+}
+
+// nodeStatus returns the kubectl status of a node from its Ready condition
+func nodeStatus(node *corev1.Node) string {
+	status := "Unknown"
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady {
+			continue
+		}
+		switch condition.Status {
+		case corev1.ConditionTrue:
+			status = "Ready"
+		case corev1.ConditionFalse:
+			status = "NotReady"
+		}
+	}
+	if node.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+// nodeRoles returns the sorted roles of a node from its role labels
+func nodeRoles(node *corev1.Node) []string {
+	var roles []string
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, nodeRoleLabelPrefix); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// nodeResources picks the cpu, memory and pod count of a resource list
+func nodeResources(resources corev1.ResourceList) NodeResources {
+	return NodeResources{
+		CPU:    resources.Cpu().String(),
+		Memory: resources.Memory().String(),
+		Pods:   resources.Pods().String(),
+	}
+}
+
+// NodeList lists the nodes of the cluster
+func (c *Client) NodeList() (*corev1.NodeList, error) {
+	return c.clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+}
+
+// NodePrint prints nodes in kubectl-like format, with their allocatable
+// resources and taints
+func NodePrint(nodes []corev1.Node) {
+	if len(nodes) == 0 {
+		fmt.Println("No resources found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tSTATUS\tROLES\tCPU\tMEMORY\tPODS\tTAINTS\tAGE\tVERSION")
+	for i := range nodes {
+		summary := SummarizeNode(&nodes[i])
+		roles := "<none>"
+		if len(summary.Roles) > 0 {
+			roles = strings.Join(summary.Roles, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			summary.Name, summary.Status, roles,
+			summary.Allocatable.CPU, summary.Allocatable.Memory, summary.Allocatable.Pods,
+			len(summary.Taints), summary.Age, summary.Version)
+	}
+}
+
+// NodeInformer caches the nodes of a cluster
+type NodeInformer struct {
+	informer  cache.SharedIndexInformer
+	set       *InformerSet
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewNodeInformer creates an informer watching all nodes. Managed fields and
+// the list of container images, the bulk of a node status, are dropped from
+// cached nodes.
+func NewNodeInformer(clientset kubernetes.Interface, resyncPeriod time.Duration) *NodeInformer {
+	return NewNodeInformerFor(NewInformerSet(clientset, "", resyncPeriod))
+}
+
+// NewNodeInformerFor creates an informer sharing the node watch and cache of set
+func NewNodeInformerFor(set *InformerSet) *NodeInformer {
+	informer := set.clusterInformerFor(&corev1.Node{}, stripNode, func(clientset kubernetes.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		listWatcher := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.CoreV1().Nodes().List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.CoreV1().Nodes().Watch(context.TODO(), options)
+			},
+		}
+
+		return cache.NewSharedIndexInformer(listWatcher, &corev1.Node{}, resyncPeriod, cache.Indexers{})
+	})
+
+	return &NodeInformer{
+		informer: informer,
+		set:      set,
+	}
+}
+
+// stripNode drops managed fields and container images from a node
+func stripNode(obj interface{}) (interface{}, error) {
+	if node, ok := obj.(*corev1.Node); ok {
+		node.ManagedFields = nil
+		node.Status.Images = nil
+	}
+	return obj, nil
+}
+
+// Start starts watching nodes and waits until the cache syncs or ctx is done;
+// on failure the informer is stopped
+func (ni *NodeInformer) Start(ctx context.Context) error {
+	ni.startOnce.Do(func() {
+		ni.set.run(ni.informer)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), ni.informer.HasSynced) {
+		ni.Stop()
+		return fmt.Errorf("failed to sync node cache: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching nodes
+func (ni *NodeInformer) Stop() {
+	ni.stopOnce.Do(func() {
+		ni.set.release(ni.informer)
+	})
+}
+
+// HasSynced reports whether the node cache has synced
+func (ni *NodeInformer) HasSynced() bool {
+	return ni.informer.HasSynced()
+}
+
+// GetNode returns a node from the cache, and false when it doesn't exist
+func (ni *NodeInformer) GetNode(name string) (*corev1.Node, bool) {
+	obj, exists, err := ni.informer.GetIndexer().GetByKey(name)
+	if err != nil || !exists {
+		return nil, false
+	}
+	node, ok := obj.(*corev1.Node)
+	return node, ok
+}
+
+// ListNodes returns the cached nodes sorted by name
+func (ni *NodeInformer) ListNodes() []*corev1.Node {
+	objects := ni.informer.GetIndexer().List()
+	nodes := make([]*corev1.Node, 0, len(objects))
+	for _, obj := range objects {
+		if node, ok := obj.(*corev1.Node); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
@@ -0,0 +1,119 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSummarizeNode(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{nodeRoleLabelPrefix + "worker": "", nodeRoleLabelPrefix + "control-plane": "", "zone": "a"},
+		},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+			Taints:        []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3800m"),
+				corev1.ResourceMemory: resource.MustParse("15Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Reason: "KubeletReady"},
+			},
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "node-1"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+			NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.30.1"},
+		},
+	}
+
+	summary := SummarizeNode(node)
+	if summary.Status != "Ready,SchedulingDisabled" {
+		t.Errorf("Status = %q", summary.Status)
+	}
+	if want := []string{"control-plane", "worker"}; !reflect.DeepEqual(summary.Roles, want) {
+		t.Errorf("Roles = %v, want %v", summary.Roles, want)
+	}
+	if want := (NodeResources{CPU: "3800m", Memory: "15Gi", Pods: "110"}); summary.Allocatable != want {
+		t.Errorf("Allocatable = %+v, want %+v", summary.Allocatable, want)
+	}
+	if summary.Capacity.CPU != "4" || summary.InternalIP != "10.0.0.1" || summary.Version != "v1.30.1" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if want := []NodeTaint{{Key: "dedicated", Value: "gpu", Effect: "NoSchedule"}}; !reflect.DeepEqual(summary.Taints, want) {
+		t.Errorf("Taints = %+v, want %+v", summary.Taints, want)
+	}
+	if len(summary.Conditions) != 2 || summary.Conditions[1].Reason != "KubeletReady" {
+		t.Errorf("Conditions = %+v", summary.Conditions)
+	}
+
+	// Without a Ready condition the status is unknown
+	if status := SummarizeNode(&corev1.Node{}).Status; status != "Unknown" {
+		t.Errorf("Expected Unknown without a Ready condition, got %q", status)
+	}
+}
+
+func TestNodeInformer(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status:     corev1.NodeStatus{Images: []corev1.ContainerImage{{Names: []string{"nginx:1.25"}}}},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}, Spec: corev1.PodSpec{NodeName: "node-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "payments"}, Spec: corev1.PodSpec{NodeName: "node-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "shop"}},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	informer := NewNodeInformer(clientset, time.Minute)
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	nodes := informer.ListNodes()
+	if len(nodes) != 2 || nodes[0].Name != "node-1" || nodes[1].Name != "node-2" {
+		t.Fatalf("Expected nodes sorted by name, got %v", nodes)
+	}
+	node, exists := informer.GetNode("node-1")
+	if !exists {
+		t.Fatal("Expected node-1 to be cached")
+	}
+	if len(node.Status.Images) != 0 {
+		t.Errorf("Expected images to be dropped from cached nodes, got %v", node.Status.Images)
+	}
+
+	// Pods are indexed by the node they are scheduled on
+	pods := NewPodInformer(clientset, "", time.Minute)
+	if err := pods.Start(ctx); err != nil {
+		t.Fatalf("pods.Start() error = %v", err)
+	}
+	defer pods.Stop()
+	onNode, err := pods.ListNodePods("node-1")
+	if err != nil {
+		t.Fatalf("ListNodePods() error = %v", err)
+	}
+	if len(onNode) != 2 || onNode[0].Name != "api-1" || onNode[1].Name != "web-1" {
+		t.Errorf("Expected the pods of node-1 sorted by namespace, got %v", onNode)
+	}
+}
@@ -0,0 +1,116 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// allContainers selects every container of a deployment in ParseContainerImages
+const allContainers = "*"
+
+// ParsePatchType parses the patch type names of kubectl patch --type
+func ParsePatchType(name string) (types.PatchType, error) {
+	switch name {
+	case "strategic":
+		return types.StrategicMergePatchType, nil
+	case "merge":
+		return types.MergePatchType, nil
+	case "json":
+		return types.JSONPatchType, nil
+	}
+	return "", fmt.Errorf("unsupported patch type %q (use strategic, merge or json)", name)
+}
+
+// DeploymentPatch applies a JSON patch document of the given type to a
+// deployment and returns the patched deployment
+func (c *Client) DeploymentPatch(ctx context.Context, namespace, name string, patchType types.PatchType, patch []byte) (*appsv1.Deployment, error) {
+	if !json.Valid(patch) {
+		return nil, fmt.Errorf("patch is not valid JSON")
+	}
+	return c.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+}
+
+// ParseContainerImages parses CONTAINER=IMAGE arguments, as in kubectl set
+// image, into images by container name. The container "*" selects every
+// container.
+func ParseContainerImages(args []string) (map[string]string, error) {
+	images := make(map[string]string, len(args))
+	for _, arg := range args {
+		container, image, ok := strings.Cut(arg, "=")
+		if !ok || container == "" || image == "" {
+			return nil, fmt.Errorf("invalid image %q, expected CONTAINER=IMAGE", arg)
+		}
+		if _, exists := images[container]; exists {
+			return nil, fmt.Errorf("container %s is given more than once", container)
+		}
+		images[container] = image
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("at least one CONTAINER=IMAGE is required")
+	}
+	return images, nil
+}
+
+// DeploymentSetImage sets the images of containers and init containers of a
+// deployment with a strategic merge patch, keyed by container name. It
+// fails without patching when a container does not exist.
+func (c *Client) DeploymentSetImage(ctx context.Context, namespace, name string, images map[string]string) (*appsv1.Deployment, error) {
+	dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	containerImages := func(containers []corev1.Container) []map[string]interface{} {
+		var patches []map[string]interface{}
+		for _, container := range containers {
+			image, ok := images[container.Name]
+			if !ok {
+				image, ok = images[allContainers]
+			}
+			if ok {
+				found[container.Name] = true
+				patches = append(patches, map[string]interface{}{"name": container.Name, "image": image})
+			}
+		}
+		return patches
+	}
+
+	podSpec := map[string]interface{}{}
+	if patches := containerImages(dep.Spec.Template.Spec.Containers); len(patches) > 0 {
+		podSpec["containers"] = patches
+	}
+	if patches := containerImages(dep.Spec.Template.Spec.InitContainers); len(patches) > 0 {
+		podSpec["initContainers"] = patches
+	}
+
+	var missing []string
+	for container := range images {
+		if container != allContainers && !found[container] {
+			missing = append(missing, container)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("unable to find container named %s in deployment %s/%s", strings.Join(missing, ", "), namespace, name)
+	}
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{"spec": podSpec},
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return c.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, data, metav1.PatchOptions{})
+}
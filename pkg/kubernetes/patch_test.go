@@ -0,0 +1,119 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newPatchTestDeployment() *appsv1.Deployment {
+	replicas := int32(2)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "migrate", Image: "web:1"}},
+					Containers: []corev1.Container{
+						{Name: "web", Image: "web:1", Env: []corev1.EnvVar{{Name: "MODE", Value: "production"}}},
+						{Name: "proxy", Image: "envoy:1.30"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestParseContainerImages(t *testing.T) {
+	images, err := ParseContainerImages([]string{"web=web:2", "proxy=registry.example:5000/envoy:1.31"})
+	if err != nil {
+		t.Fatalf("ParseContainerImages() error = %v", err)
+	}
+	if images["web"] != "web:2" || images["proxy"] != "registry.example:5000/envoy:1.31" {
+		t.Errorf("Unexpected images %v", images)
+	}
+
+	for _, args := range [][]string{nil, {"web"}, {"=web:2"}, {"web="}, {"web=web:2", "web=web:3"}} {
+		if _, err := ParseContainerImages(args); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
+
+func TestDeploymentSetImage(t *testing.T) {
+	client := NewClientWithClientset(fake.NewSimpleClientset(newPatchTestDeployment()))
+	ctx := context.Background()
+
+	dep, err := client.DeploymentSetImage(ctx, "shop", "web", map[string]string{"web": "web:2"})
+	if err != nil {
+		t.Fatalf("DeploymentSetImage() error = %v", err)
+	}
+	containers := dep.Spec.Template.Spec.Containers
+	if len(containers) != 2 || containers[0].Image != "web:2" || containers[1].Image != "envoy:1.30" {
+		t.Errorf("Expected only the web image to change, got %+v", containers)
+	}
+	if len(containers[0].Env) != 1 {
+		t.Errorf("Expected the env of web to be kept, got %+v", containers[0].Env)
+	}
+	if dep.Spec.Template.Spec.InitContainers[0].Image != "web:1" {
+		t.Errorf("Expected the init container to be left alone, got %+v", dep.Spec.Template.Spec.InitContainers)
+	}
+
+	dep, err = client.DeploymentSetImage(ctx, "shop", "web", map[string]string{"*": "web:3"})
+	if err != nil {
+		t.Fatalf("DeploymentSetImage() error = %v", err)
+	}
+	for _, c := range append(dep.Spec.Template.Spec.InitContainers, dep.Spec.Template.Spec.Containers...) {
+		if c.Image != "web:3" {
+			t.Errorf("Expected every container to run web:3, got %s=%s", c.Name, c.Image)
+		}
+	}
+
+	_, err = client.DeploymentSetImage(ctx, "shop", "web", map[string]string{"web": "web:4", "sidecar": "x:1"})
+	if err == nil || !strings.Contains(err.Error(), "sidecar") {
+		t.Fatalf("Expected an error naming the missing container, got %v", err)
+	}
+	if dep, _ := client.DeploymentGet("shop", "web"); dep.Spec.Template.Spec.Containers[0].Image != "web:3" {
+		t.Errorf("Expected no patch when a container is missing, got %s", dep.Spec.Template.Spec.Containers[0].Image)
+	}
+}
+
+func TestDeploymentPatch(t *testing.T) {
+	client := NewClientWithClientset(fake.NewSimpleClientset(newPatchTestDeployment()))
+	ctx := context.Background()
+
+	patchType, err := ParsePatchType("merge")
+	if err != nil {
+		t.Fatalf("ParsePatchType() error = %v", err)
+	}
+	dep, err := client.DeploymentPatch(ctx, "shop", "web", patchType, []byte(`{"spec":{"replicas":5}}`))
+	if err != nil {
+		t.Fatalf("DeploymentPatch() error = %v", err)
+	}
+	if *dep.Spec.Replicas != 5 {
+		t.Errorf("Expected 5 replicas, got %d", *dep.Spec.Replicas)
+	}
+
+	dep, err = client.DeploymentPatch(ctx, "shop", "web", types.JSONPatchType,
+		[]byte(`[{"op":"replace","path":"/spec/template/spec/containers/1/image","value":"envoy:1.31"}]`))
+	if err != nil {
+		t.Fatalf("DeploymentPatch() error = %v", err)
+	}
+	if dep.Spec.Template.Spec.Containers[1].Image != "envoy:1.31" {
+		t.Errorf("Expected the proxy image to be replaced, got %+v", dep.Spec.Template.Spec.Containers)
+	}
+
+	if _, err := client.DeploymentPatch(ctx, "shop", "web", types.MergePatchType, []byte(`{"spec":`)); err == nil {
+		t.Error("Expected an error for an invalid patch")
+	}
+	if _, err := ParsePatchType("apply"); err == nil {
+		t.Error("Expected an error for an unsupported patch type")
+	}
+}
@@ -0,0 +1,131 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PDBInformer caches the PodDisruptionBudgets of a namespace, or of all
+// namespaces, to find those protecting the pods of a deployment
+type PDBInformer struct {
+	informer  cache.SharedIndexInformer
+	set       *InformerSet
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewPDBInformer creates an informer watching the PodDisruptionBudgets of
+// namespace; an empty namespace watches all namespaces. Managed fields are
+// dropped from cached objects.
+func NewPDBInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *PDBInformer {
+	return NewPDBInformerFor(NewInformerSet(clientset, namespace, resyncPeriod))
+}
+
+// NewPDBInformerFor creates an informer sharing the PodDisruptionBudget watch
+// and cache of set
+func NewPDBInformerFor(set *InformerSet) *PDBInformer {
+	informer := set.informerFor(&policyv1.PodDisruptionBudget{}, stripManagedFields, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(set.scoped(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.PolicyV1().PodDisruptionBudgets(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.PolicyV1().PodDisruptionBudgets(namespace).Watch(context.TODO(), options)
+			},
+		}), &policyv1.PodDisruptionBudget{}, resyncPeriod, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})
+	})
+
+	return &PDBInformer{
+		informer: informer,
+		set:      set,
+	}
+}
+
+// Start starts watching PodDisruptionBudgets and waits until the cache syncs
+// or ctx is done; on failure the informer is stopped
+func (pi *PDBInformer) Start(ctx context.Context) error {
+	pi.startOnce.Do(func() {
+		pi.set.run(pi.informer)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), pi.informer.HasSynced) {
+		pi.Stop()
+		return fmt.Errorf("failed to sync PodDisruptionBudget cache: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching PodDisruptionBudgets
+func (pi *PDBInformer) Stop() {
+	pi.stopOnce.Do(func() {
+		pi.set.release(pi.informer)
+	})
+}
+
+// HasSynced reports whether the PodDisruptionBudget cache has synced
+func (pi *PDBInformer) HasSynced() bool {
+	return pi.informer.HasSynced()
+}
+
+// ListPDBs returns the cached PodDisruptionBudgets of a namespace sorted by
+// name
+func (pi *PDBInformer) ListPDBs(namespace string) ([]*policyv1.PodDisruptionBudget, error) {
+	objects, err := pi.informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		return nil, err
+	}
+	pdbs := make([]*policyv1.PodDisruptionBudget, 0, len(objects))
+	for _, obj := range objects {
+		if pdb, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+			pdbs = append(pdbs, pdb)
+		}
+	}
+	sort.Slice(pdbs, func(i, j int) bool { return pdbs[i].Name < pdbs[j].Name })
+	return pdbs, nil
+}
+
+// ListAllPDBs returns the cached PodDisruptionBudgets of every namespace
+// sorted by namespace and name
+func (pi *PDBInformer) ListAllPDBs() []*policyv1.PodDisruptionBudget {
+	objects := pi.informer.GetIndexer().List()
+	pdbs := make([]*policyv1.PodDisruptionBudget, 0, len(objects))
+	for _, obj := range objects {
+		if pdb, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+			pdbs = append(pdbs, pdb)
+		}
+	}
+	sort.Slice(pdbs, func(i, j int) bool {
+		if pdbs[i].Namespace != pdbs[j].Namespace {
+			return pdbs[i].Namespace < pdbs[j].Namespace
+		}
+		return pdbs[i].Name < pdbs[j].Name
+	})
+	return pdbs
+}
+
+// PDBSelects reports whether a PodDisruptionBudget selects pods with
+// podLabels in its namespace. An empty selector selects every pod, and a
+// null or invalid one none.
+func PDBSelects(pdb *policyv1.PodDisruptionBudget, podLabels map[string]string) bool {
+	if pdb.Spec.Selector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(podLabels))
+}
@@ -0,0 +1,45 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPDBInformer(t *testing.T) {
+	web := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	}
+	all := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "all"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{}},
+	}
+	none := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "none"}}
+
+	informer := NewPDBInformer(fake.NewSimpleClientset(web, all, none), "", time.Minute)
+	if err := informer.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	pdbs, err := informer.ListPDBs("shop")
+	if err != nil || len(pdbs) != 2 || pdbs[0].Name != "none" {
+		t.Errorf("Expected the budgets of shop sorted by name, got %v, %v", pdbs, err)
+	}
+	if pdbs := informer.ListAllPDBs(); len(pdbs) != 3 || pdbs[0].Namespace != "ops" {
+		t.Errorf("Expected every budget sorted by namespace, got %v", pdbs)
+	}
+
+	labels := map[string]string{"app": "web"}
+	if !PDBSelects(web, labels) || PDBSelects(web, map[string]string{"app": "api"}) {
+		t.Error("Expected the web budget to select only app=web")
+	}
+	if !PDBSelects(all, labels) || PDBSelects(none, labels) {
+		t.Error("Expected an empty selector to select every pod and a null one none")
+	}
+}
@@ -0,0 +1,399 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PodList lists pods in the specified namespace matching a label selector
+// and a field selector, e.g. spec.nodeName=node-1
+func (c *Client) PodList(namespace, labelSelector, fieldSelector string) (*corev1.PodList, error) {
+	return c.clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+		FieldSelector: fieldSelector,
+	})
+}
+
+// PodGet gets a pod
+func (c *Client) PodGet(namespace, name string) (*corev1.Pod, error) {
+	return c.clientset.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// PodDelete deletes a pod. A negative gracePeriod keeps the pod's own
+// termination grace period, and 0 deletes it immediately.
+func (c *Client) PodDelete(ctx context.Context, namespace, name string, gracePeriod int64) error {
+	opts := metav1.DeleteOptions{}
+	if gracePeriod >= 0 {
+		opts.GracePeriodSeconds = &gracePeriod
+	}
+	return c.clientset.CoreV1().Pods(namespace).Delete(ctx, name, opts)
+}
+
+// PodStatus returns the status of a pod as kubectl prints it: the reason a
+// container is waiting or terminated, such as CrashLoopBackOff or
+// OOMKilled, the progress of init containers, Terminating while the pod is
+// deleted, or its phase
+func PodStatus(pod *corev1.Pod) string {
+	status := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		status = pod.Status.Reason
+	}
+
+	initializing := false
+	for i, container := range pod.Status.InitContainerStatuses {
+		switch {
+		case container.State.Terminated != nil && container.State.Terminated.ExitCode == 0:
+			continue
+		case container.State.Terminated != nil:
+			if container.State.Terminated.Reason != "" {
+				status = "Init:" + container.State.Terminated.Reason
+			} else {
+				status = fmt.Sprintf("Init:ExitCode:%d", container.State.Terminated.ExitCode)
+			}
+		case container.State.Waiting != nil && container.State.Waiting.Reason != "" && container.State.Waiting.Reason != "PodInitializing":
+			status = "Init:" + container.State.Waiting.Reason
+		default:
+			status = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing {
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
+				status = container.State.Waiting.Reason
+			} else if container.State.Terminated != nil && container.State.Terminated.Reason != "" {
+				status = container.State.Terminated.Reason
+			}
+		}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		status = "Terminating"
+	}
+	return status
+}
+
+// PodPrintOptions selects the columns printed for pods
+type PodPrintOptions struct {
+	// Print the NAMESPACE column
+	ShowNamespace bool
+
+	// Print the IP and NODE columns, like kubectl get -o wide
+	Wide bool
+
+	// Print the LABELS column
+	ShowLabels bool
+}
+
+// PodPrint prints pods in kubectl-like format
+func PodPrint(pods []corev1.Pod, opts PodPrintOptions) {
+	if len(pods) == 0 {
+		fmt.Println("No resources found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, strings.Join(PodColumns(opts), "\t"))
+	for i := range pods {
+		fmt.Fprintln(w, strings.Join(PodRow(&pods[i], opts), "\t"))
+	}
+}
+
+// PodColumns returns the column headers printed for pods
+func PodColumns(opts PodPrintOptions) []string {
+	var columns []string
+	if opts.ShowNamespace {
+		columns = append(columns, "NAMESPACE")
+	}
+	columns = append(columns, "NAME", "READY", "STATUS", "RESTARTS", "AGE")
+	if opts.Wide {
+		columns = append(columns, "IP", "NODE")
+	}
+	if opts.ShowLabels {
+		columns = append(columns, "LABELS")
+	}
+	return columns
+}
+
+// PodRow returns the columns printed for a pod, in the order of PodColumns
+func PodRow(pod *corev1.Pod, opts PodPrintOptions) []string {
+	var ready int
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+		restarts += status.RestartCount
+	}
+
+	var row []string
+	if opts.ShowNamespace {
+		row = append(row, pod.Namespace)
+	}
+	row = append(row,
+		pod.Name,
+		fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+		PodStatus(pod),
+		fmt.Sprintf("%d", restarts),
+		FormatAge(pod.CreationTimestamp.Time),
+	)
+	if opts.Wide {
+		row = append(row, valueOrNone(pod.Status.PodIP), valueOrNone(pod.Spec.NodeName))
+	}
+	if opts.ShowLabels {
+		row = append(row, labels.FormatLabels(pod.Labels))
+	}
+	return row
+}
+
+// valueOrNone returns value, or <none> when it is empty
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+// PodEvent is a change of a cached pod, typed like kubectl watch events
+type PodEvent struct {
+	Type   string      `json:"type"`
+	Time   time.Time   `json:"time"`
+	Object *corev1.Pod `json:"object"`
+}
+
+// PodEventHandler is notified of pod changes
+type PodEventHandler interface {
+	OnPodEvent(event PodEvent)
+}
+
+// PodEventHandlerFunc adapts a function to PodEventHandler
+type PodEventHandlerFunc func(event PodEvent)
+
+// OnPodEvent calls f
+func (f PodEventHandlerFunc) OnPodEvent(event PodEvent) {
+	f(event)
+}
+
+// PodInformer caches the pods of a namespace, or of all namespaces, indexed
+// by namespace, by node and by the claims they mount. Handlers are notified
+// of pod changes.
+type PodInformer struct {
+	informer  cache.SharedIndexInformer
+	set       *InformerSet
+	startOnce sync.Once
+	stopOnce  sync.Once
+
+	// Notifies handlers of the shared pods informer's events
+	registration cache.ResourceEventHandlerRegistration
+
+	mu       sync.RWMutex
+	handlers []PodEventHandler
+}
+
+// NewPodInformer creates an informer watching the pods of namespace; an empty
+// namespace watches all namespaces. Managed fields are dropped from cached pods.
+func NewPodInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *PodInformer {
+	return NewPodInformerFor(NewInformerSet(clientset, namespace, resyncPeriod))
+}
+
+// NewPodInformerFor creates an informer sharing the pod watch and cache of set
+func NewPodInformerFor(set *InformerSet) *PodInformer {
+	informer := set.informerFor(&corev1.Pod{}, stripManagedFields, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		listWatcher := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.CoreV1().Pods(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.CoreV1().Pods(namespace).Watch(context.TODO(), options)
+			},
+		}
+
+		return cache.NewSharedIndexInformer(set.scoped(listWatcher), &corev1.Pod{}, resyncPeriod, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+			nodeIndex:            indexByNode,
+			claimIndex:           indexByClaim,
+		})
+	})
+
+	pi := &PodInformer{
+		informer: informer,
+		set:      set,
+	}
+	// The pods informer may be shared, so the handler is removed on Stop
+	pi.registration, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				pi.notify(WatchEventAdded, pod)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok := oldObj.(*corev1.Pod)
+			newPod, ok2 := newObj.(*corev1.Pod)
+			if !ok || !ok2 || oldPod.ResourceVersion == newPod.ResourceVersion {
+				return
+			}
+			pi.notify(WatchEventModified, newPod)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if pod, ok := deletedObject(obj).(*corev1.Pod); ok {
+				pi.notify(WatchEventDeleted, pod)
+			}
+		},
+	})
+	return pi
+}
+
+// notify delivers a pod event to the handlers
+func (pi *PodInformer) notify(eventType string, pod *corev1.Pod) {
+	pi.mu.RLock()
+	handlers := pi.handlers
+	pi.mu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+	event := PodEvent{Type: eventType, Time: time.Now().UTC(), Object: pod}
+	for _, handler := range handlers {
+		handler.OnPodEvent(event)
+	}
+}
+
+// AddHandler registers a handler notified of pod changes. Pods cached before
+// the handler is added are not replayed.
+func (pi *PodInformer) AddHandler(handler PodEventHandler) {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	pi.handlers = append(pi.handlers, handler)
+}
+
+// nodeIndex indexes pods by the name of the node they are scheduled on
+const nodeIndex = "node"
+
+// indexByNode indexes a scheduled pod by its node name
+func indexByNode(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil, nil
+	}
+	return []string{pod.Spec.NodeName}, nil
+}
+
+// Start starts watching pods and waits until the cache syncs or ctx is done;
+// on failure the informer is stopped
+func (pi *PodInformer) Start(ctx context.Context) error {
+	pi.startOnce.Do(func() {
+		pi.set.run(pi.informer)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), pi.informer.HasSynced) {
+		pi.Stop()
+		return fmt.Errorf("failed to sync pod cache: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching pods
+func (pi *PodInformer) Stop() {
+	pi.stopOnce.Do(func() {
+		if pi.registration != nil {
+			_ = pi.informer.RemoveEventHandler(pi.registration)
+		}
+		pi.set.release(pi.informer)
+	})
+}
+
+// HasSynced reports whether the pod cache has synced
+func (pi *PodInformer) HasSynced() bool {
+	return pi.informer.HasSynced()
+}
+
+// ListPods returns the cached pods of a namespace sorted by name
+func (pi *PodInformer) ListPods(namespace string) ([]*corev1.Pod, error) {
+	objects, err := pi.informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(objects))
+	for _, obj := range objects {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, nil
+}
+
+// ListAllPods returns the cached pods of every namespace sorted by namespace
+// and name
+func (pi *PodInformer) ListAllPods() []*corev1.Pod {
+	objects := pi.informer.GetIndexer().List()
+	pods := make([]*corev1.Pod, 0, len(objects))
+	for _, obj := range objects {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	return pods
+}
+
+// ListNodePods returns the cached pods scheduled on a node sorted by
+// namespace and name
+func (pi *PodInformer) ListNodePods(node string) ([]*corev1.Pod, error) {
+	objects, err := pi.informer.GetIndexer().ByIndex(nodeIndex, node)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(objects))
+	for _, obj := range objects {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+// ListClaimPods returns the cached pods mounting a PersistentVolumeClaim
+// sorted by name
+func (pi *PodInformer) ListClaimPods(namespace, claim string) ([]*corev1.Pod, error) {
+	objects, err := pi.informer.GetIndexer().ByIndex(claimIndex, namespace+"/"+claim)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(objects))
+	for _, obj := range objects {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, nil
+}
@@ -0,0 +1,113 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestPod(name string, statuses ...corev1.ContainerStatus) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.7", ContainerStatuses: statuses},
+	}
+	for _, status := range statuses {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: status.Name})
+	}
+	return pod
+}
+
+func TestPodStatus(t *testing.T) {
+	running := corev1.ContainerStatus{Name: "web", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+	crashing := corev1.ContainerStatus{Name: "sidecar", RestartCount: 4, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}
+
+	terminating := newTestPod("web", running)
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	initializing := newTestPod("web", running)
+	initializing.Spec.InitContainers = []corev1.Container{{Name: "migrate"}, {Name: "seed"}}
+	initializing.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{Name: "migrate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+		{Name: "seed", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+	}
+	evicted := newTestPod("web")
+	evicted.Status.Phase = corev1.PodFailed
+	evicted.Status.Reason = "Evicted"
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected string
+	}{
+		{"running", newTestPod("web", running), "Running"},
+		{"waiting container", newTestPod("web", running, crashing), "CrashLoopBackOff"},
+		{"init containers", initializing, "Init:1/2"},
+		{"pod reason", evicted, "Evicted"},
+		{"terminating", terminating, "Terminating"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PodStatus(tt.pod); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	pod := newTestPod("web-1", running, crashing)
+	pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	opts := PodPrintOptions{ShowNamespace: true, Wide: true, ShowLabels: true}
+	columns := PodColumns(opts)
+	row := PodRow(pod, opts)
+	expected := []string{"shop", "web-1", "1/2", "CrashLoopBackOff", "4", "2h", "10.0.0.7", "node-1", "app=web"}
+	if len(columns) != len(row) {
+		t.Fatalf("Expected a value for each of the columns %v, got %v", columns, row)
+	}
+	for i := range expected {
+		if row[i] != expected[i] {
+			t.Errorf("Expected %s to be %q, got %q", columns[i], expected[i], row[i])
+		}
+	}
+}
+
+func TestPodInformerHandlers(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestPod("web-1"))
+	informer := NewPodInformer(clientset, "shop", time.Minute)
+	events := make(chan PodEvent, 8)
+	informer.AddHandler(PodEventHandlerFunc(func(event PodEvent) { events <- event }))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	expectEvent := func(eventType string) {
+		t.Helper()
+		select {
+		case event := <-events:
+			if event.Type != eventType || event.Object.Name != "web-1" || event.Time.IsZero() {
+				t.Errorf("Expected %s of web-1, got %+v", eventType, event)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Expected a %s event", eventType)
+		}
+	}
+
+	// Cached pods are added, then changes are delivered as they happen
+	expectEvent(WatchEventAdded)
+	pod := newTestPod("web-1")
+	pod.ResourceVersion = "2"
+	pod.Status.Phase = corev1.PodSucceeded
+	if _, err := clientset.CoreV1().Pods("shop").UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	expectEvent(WatchEventModified)
+	if err := clientset.CoreV1().Pods("shop").Delete(ctx, "web-1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	expectEvent(WatchEventDeleted)
+}
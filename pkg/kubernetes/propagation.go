@@ -0,0 +1,99 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LabelPropagator supplies labels a deployment inherits without setting them
+// itself, such as cost allocation labels from its namespace
+type LabelPropagator interface {
+	PropagatedLabels(dep *appsv1.Deployment) map[string]string
+}
+
+// EffectiveLabels returns the labels of a deployment merged with the labels
+// propagated to it. The deployment's own labels win, and its label map is
+// returned unchanged when nothing is propagated.
+func EffectiveLabels(dep *appsv1.Deployment, propagator LabelPropagator) map[string]string {
+	if propagator == nil {
+		return dep.Labels
+	}
+
+	propagated := propagator.PropagatedLabels(dep)
+	missing := false
+	for key := range propagated {
+		if _, exists := dep.Labels[key]; !exists {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return dep.Labels
+	}
+
+	merged := make(map[string]string, len(dep.Labels)+len(propagated))
+	for key, value := range propagated {
+		merged[key] = value
+	}
+	for key, value := range dep.Labels {
+		merged[key] = value
+	}
+	return merged
+}
+
+// NamespaceLabelPropagator propagates configured labels from namespaces onto
+// the deployments in them, reading namespaces from a namespace informer
+type NamespaceLabelPropagator struct {
+	keys       []string
+	namespaces *NamespaceInformer
+}
+
+// NewNamespaceLabelPropagator creates a propagator for the given label keys
+// with its own namespace informer
+func NewNamespaceLabelPropagator(clientset kubernetes.Interface, keys []string, resyncPeriod time.Duration) *NamespaceLabelPropagator {
+	return NewNamespaceLabelPropagatorFor(NewNamespaceInformer(clientset, resyncPeriod), keys)
+}
+
+// NewNamespaceLabelPropagatorFor creates a propagator for the given label keys
+// reading namespaces from an existing informer
+func NewNamespaceLabelPropagatorFor(namespaces *NamespaceInformer, keys []string) *NamespaceLabelPropagator {
+	return &NamespaceLabelPropagator{
+		keys:       keys,
+		namespaces: namespaces,
+	}
+}
+
+// Start starts the namespace informer and waits until the cache syncs or ctx
+// is done; on failure the informer is stopped
+func (p *NamespaceLabelPropagator) Start(ctx context.Context) error {
+	return p.namespaces.Start(ctx)
+}
+
+// Stop stops the namespace informer
+func (p *NamespaceLabelPropagator) Stop() {
+	p.namespaces.Stop()
+}
+
+// PropagatedLabels returns the configured labels set on the deployment's namespace
+func (p *NamespaceLabelPropagator) PropagatedLabels(dep *appsv1.Deployment) map[string]string {
+	namespace, exists := p.namespaces.GetNamespace(dep.Namespace)
+	if !exists {
+		return nil
+	}
+
+	var propagated map[string]string
+	for _, key := range p.keys {
+		value, exists := namespace.Labels[key]
+		if !exists {
+			continue
+		}
+		if propagated == nil {
+			propagated = make(map[string]string, len(p.keys))
+		}
+		propagated[key] = value
+	}
+	return propagated
+}
@@ -0,0 +1,53 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNamespaceLabelPropagator(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "payments",
+			Labels: map[string]string{"team": "payments", "cost-center": "cc-42", "env": "prod"},
+		},
+	})
+	propagator := NewNamespaceLabelPropagator(clientset, []string{"team", "cost-center"}, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := propagator.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer propagator.Stop()
+
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      "api",
+		Namespace: "payments",
+		Labels:    map[string]string{"app": "api", "team": "checkout"},
+	}}
+
+	labels := EffectiveLabels(dep, propagator)
+	if labels["cost-center"] != "cc-42" {
+		t.Errorf("Expected cost-center to be propagated, got %v", labels)
+	}
+	if labels["team"] != "checkout" {
+		t.Errorf("Expected the deployment's own team label to win, got %v", labels)
+	}
+	if _, exists := labels["env"]; exists {
+		t.Errorf("Expected only configured labels to be propagated, got %v", labels)
+	}
+	if dep.Labels["cost-center"] != "" {
+		t.Error("Expected the cached deployment to be left unchanged")
+	}
+
+	other := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "unknown"}}
+	if labels := EffectiveLabels(other, propagator); len(labels) != 0 {
+		t.Errorf("Expected no labels for an unknown namespace, got %v", labels)
+	}
+}
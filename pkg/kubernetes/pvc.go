@@ -0,0 +1,269 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// claimIndex indexes pods by the namespace/name of the
+// PersistentVolumeClaims their volumes mount
+const claimIndex = "claim"
+
+// accessModeNames are the kubectl abbreviations of access modes
+var accessModeNames = map[corev1.PersistentVolumeAccessMode]string{
+	corev1.ReadWriteOnce:    "RWO",
+	corev1.ReadOnlyMany:     "ROX",
+	corev1.ReadWriteMany:    "RWX",
+	corev1.ReadWriteOncePod: "RWOP",
+}
+
+// PVCSummary summarizes a PersistentVolumeClaim. Capacity is the size of the
+// bound volume, and Requested the size the claim asks for.
+type PVCSummary struct {
+	Name         string   `json:"name"`
+	Namespace    string   `json:"namespace"`
+	Phase        string   `json:"phase"`
+	Volume       string   `json:"volume,omitempty"`
+	Capacity     string   `json:"capacity,omitempty"`
+	Requested    string   `json:"requested,omitempty"`
+	AccessModes  []string `json:"accessModes,omitempty"`
+	StorageClass string   `json:"storageClass,omitempty"`
+	VolumeMode   string   `json:"volumeMode,omitempty"`
+	Age          string   `json:"age"`
+}
+
+// SummarizePVC summarizes a PersistentVolumeClaim
+func SummarizePVC(pvc *corev1.PersistentVolumeClaim) PVCSummary {
+	summary := PVCSummary{
+		Name:      pvc.Name,
+		Namespace: pvc.Namespace,
+		Phase:     string(pvc.Status.Phase),
+		Volume:    pvc.Spec.VolumeName,
+		Age:       FormatAge(pvc.CreationTimestamp.Time),
+	}
+	if size, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		summary.Capacity = size.String()
+	}
+	if size, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		summary.Requested = size.String()
+	}
+	for _, mode := range pvc.Status.AccessModes {
+		summary.AccessModes = append(summary.AccessModes, accessModeName(mode))
+	}
+	if len(summary.AccessModes) == 0 {
+		// Unbound claims report the modes they ask for
+		for _, mode := range pvc.Spec.AccessModes {
+			summary.AccessModes = append(summary.AccessModes, accessModeName(mode))
+		}
+	}
+	if pvc.Spec.StorageClassName != nil {
+		summary.StorageClass = *pvc.Spec.StorageClassName
+	}
+	if pvc.Spec.VolumeMode != nil {
+		summary.VolumeMode = string(*pvc.Spec.VolumeMode)
+	}
+	return summary
+}
+
+// accessModeName abbreviates an access mode like kubectl
+func accessModeName(mode corev1.PersistentVolumeAccessMode) string {
+	if name, ok := accessModeNames[mode]; ok {
+		return name
+	}
+	return string(mode)
+}
+
+// PodClaims returns the names of the PersistentVolumeClaims a pod mounts,
+// including the claims created for its generic ephemeral volumes
+func PodClaims(pod *corev1.Pod) []string {
+	var claims []string
+	for _, volume := range pod.Spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+		case volume.Ephemeral != nil:
+			claims = append(claims, pod.Name+"-"+volume.Name)
+		}
+	}
+	return claims
+}
+
+// indexByClaim indexes a pod by the namespace/name of the claims it mounts
+func indexByClaim(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, nil
+	}
+	claims := PodClaims(pod)
+	keys := make([]string, 0, len(claims))
+	for _, claim := range claims {
+		keys = append(keys, pod.Namespace+"/"+claim)
+	}
+	return keys, nil
+}
+
+// PVCList lists PersistentVolumeClaims in the specified namespace
+func (c *Client) PVCList(namespace string) (*corev1.PersistentVolumeClaimList, error) {
+	return c.clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+}
+
+// PVCMounts returns the names of the pods of a namespace, or of all
+// namespaces, mounting each claim, keyed by the namespace/name of the claim
+func (c *Client) PVCMounts(namespace string) (map[string][]string, error) {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	mounts := make(map[string][]string)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, claim := range PodClaims(pod) {
+			key := pod.Namespace + "/" + claim
+			mounts[key] = append(mounts[key], pod.Name)
+		}
+	}
+	return mounts, nil
+}
+
+// PVCPrint prints claims in kubectl-like format, with the pods mounting them
+// from mounts, keyed by namespace/name
+func PVCPrint(pvcs []corev1.PersistentVolumeClaim, mounts map[string][]string, showNamespace bool) {
+	if len(pvcs) == 0 {
+		fmt.Println("No resources found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	header := "NAME\tSTATUS\tVOLUME\tCAPACITY\tACCESS MODES\tSTORAGECLASS\tMOUNTED BY\tAGE"
+	if showNamespace {
+		header = "NAMESPACE\t" + header
+	}
+	fmt.Fprintln(w, header)
+	for i := range pvcs {
+		summary := SummarizePVC(&pvcs[i])
+		mountedBy := "<none>"
+		if pods := mounts[summary.Namespace+"/"+summary.Name]; len(pods) > 0 {
+			sort.Strings(pods)
+			mountedBy = strings.Join(pods, ",")
+		}
+		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+			summary.Name, summary.Phase, summary.Volume, summary.Capacity,
+			strings.Join(summary.AccessModes, ","), summary.StorageClass, mountedBy, summary.Age)
+		if showNamespace {
+			row = summary.Namespace + "\t" + row
+		}
+		fmt.Fprintln(w, row)
+	}
+}
+
+// PVCInformer caches the PersistentVolumeClaims of a namespace, or of all
+// namespaces
+type PVCInformer struct {
+	informer  cache.SharedIndexInformer
+	set       *InformerSet
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewPVCInformer creates an informer watching the PersistentVolumeClaims of
+// namespace; an empty namespace watches all namespaces. Managed fields are
+// dropped from cached claims.
+func NewPVCInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *PVCInformer {
+	return NewPVCInformerFor(NewInformerSet(clientset, namespace, resyncPeriod))
+}
+
+// NewPVCInformerFor creates an informer sharing the claim watch and cache of set
+func NewPVCInformerFor(set *InformerSet) *PVCInformer {
+	informer := set.informerFor(&corev1.PersistentVolumeClaim{}, stripManagedFields, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		listWatcher := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.CoreV1().PersistentVolumeClaims(namespace).Watch(context.TODO(), options)
+			},
+		}
+
+		return cache.NewSharedIndexInformer(set.scoped(listWatcher), &corev1.PersistentVolumeClaim{}, resyncPeriod, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})
+	})
+
+	return &PVCInformer{
+		informer: informer,
+		set:      set,
+	}
+}
+
+// Start starts watching claims and waits until the cache syncs or ctx is
+// done; on failure the informer is stopped
+func (pi *PVCInformer) Start(ctx context.Context) error {
+	pi.startOnce.Do(func() {
+		pi.set.run(pi.informer)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), pi.informer.HasSynced) {
+		pi.Stop()
+		return fmt.Errorf("failed to sync PersistentVolumeClaim cache: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching claims
+func (pi *PVCInformer) Stop() {
+	pi.stopOnce.Do(func() {
+		pi.set.release(pi.informer)
+	})
+}
+
+// HasSynced reports whether the claim cache has synced
+func (pi *PVCInformer) HasSynced() bool {
+	return pi.informer.HasSynced()
+}
+
+// GetPVC returns a claim from the cache, and false when it doesn't exist
+func (pi *PVCInformer) GetPVC(namespace, name string) (*corev1.PersistentVolumeClaim, bool) {
+	obj, exists, err := pi.informer.GetIndexer().GetByKey(namespace + "/" + name)
+	if err != nil || !exists {
+		return nil, false
+	}
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	return pvc, ok
+}
+
+// ListPVCs returns the cached claims of a namespace, or of all namespaces
+// when namespace is empty, sorted by namespace and name
+func (pi *PVCInformer) ListPVCs(namespace string) ([]*corev1.PersistentVolumeClaim, error) {
+	objects, err := listNamespace(pi.informer.GetIndexer(), namespace)
+	if err != nil {
+		return nil, err
+	}
+	pvcs := make([]*corev1.PersistentVolumeClaim, 0, len(objects))
+	for _, obj := range objects {
+		if pvc, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	sort.Slice(pvcs, func(i, j int) bool {
+		if pvcs[i].Namespace != pvcs[j].Namespace {
+			return pvcs[i].Namespace < pvcs[j].Namespace
+		}
+		return pvcs[i].Name < pvcs[j].Name
+	})
+	return pvcs, nil
+}
@@ -0,0 +1,101 @@
+package kubernetes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSummarizePVC(t *testing.T) {
+	class := "fast"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "shop"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &class,
+			VolumeName:       "pv-1",
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:       corev1.ClaimBound,
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadOnlyMany},
+			Capacity:    corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+		},
+	}
+
+	summary := SummarizePVC(pvc)
+	if summary.Phase != "Bound" || summary.Volume != "pv-1" || summary.Capacity != "20Gi" || summary.Requested != "10Gi" || summary.StorageClass != "fast" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if want := []string{"RWO", "ROX"}; !reflect.DeepEqual(summary.AccessModes, want) {
+		t.Errorf("AccessModes = %v, want %v", summary.AccessModes, want)
+	}
+
+	// Pending claims report the access modes they ask for
+	pvc.Status = corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}
+	if summary := SummarizePVC(pvc); summary.Capacity != "" || !reflect.DeepEqual(summary.AccessModes, []string{"RWO"}) {
+		t.Errorf("Unexpected pending summary: %+v", summary)
+	}
+}
+
+func TestPVCInformer(t *testing.T) {
+	claimVolume := func(name, claim string) corev1.Volume {
+		return corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+		}}
+	}
+	clientset := fake.NewSimpleClientset(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "shop"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "shop"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "search"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-1", Namespace: "shop"}, Spec: corev1.PodSpec{Volumes: []corev1.Volume{claimVolume("data", "data")}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "shop"}, Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			claimVolume("data", "data"),
+			{Name: "scratch", VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}}},
+		}}},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	informer := NewPVCInformer(clientset, "", time.Minute)
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	pvcs, err := informer.ListPVCs("shop")
+	if err != nil || len(pvcs) != 2 || pvcs[0].Name != "cache" {
+		t.Fatalf("ListPVCs() = %v, %v", pvcs, err)
+	}
+	if all, _ := informer.ListPVCs(""); len(all) != 3 || all[0].Namespace != "search" {
+		t.Errorf("Expected the claims of all namespaces sorted by namespace, got %v", all)
+	}
+	if _, exists := informer.GetPVC("search", "data"); !exists {
+		t.Error("Expected search/data to be cached")
+	}
+
+	// Pods are indexed by the claims they mount
+	pods := NewPodInformer(clientset, "", time.Minute)
+	if err := pods.Start(ctx); err != nil {
+		t.Fatalf("pods.Start() error = %v", err)
+	}
+	defer pods.Stop()
+	mounting, err := pods.ListClaimPods("shop", "data")
+	if err != nil || len(mounting) != 2 || mounting[0].Name != "db-0" {
+		t.Errorf("ListClaimPods() = %v, %v", mounting, err)
+	}
+	if ephemeral, _ := pods.ListClaimPods("shop", "db-0-scratch"); len(ephemeral) != 1 {
+		t.Errorf("Expected the claim of the ephemeral volume to be indexed, got %v", ephemeral)
+	}
+	if other, _ := pods.ListClaimPods("search", "data"); len(other) != 0 {
+		t.Errorf("Expected claims to be matched within their namespace, got %v", other)
+	}
+}
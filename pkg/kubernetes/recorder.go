@@ -0,0 +1,47 @@
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// EventComponent is the source component of the events k6s records
+const EventComponent = "k6s"
+
+// Reasons of the events k6s records about deployments
+const (
+	// A deployment breaks a deployment policy selecting it
+	ReasonPolicyViolation = "PolicyViolation"
+
+	// A deployment was scaled into the replica bounds of a policy
+	ReasonPolicyEnforced = "PolicyEnforced"
+
+	// A deployment started to differ from its declared manifest
+	ReasonDriftDetected = "DriftDetected"
+
+	// A deployment started to differ across the clusters of a drift group
+	ReasonClusterDrift = "ClusterDriftDetected"
+
+	// A deployment could not be propagated to a member cluster
+	ReasonPropagationFailed = "PropagationFailed"
+
+	// The rollout of a deployment stopped progressing
+	ReasonRolloutStuck = "RolloutStuck"
+)
+
+// ClusterRecorders returns the event recorder of a cluster, nil when events
+// can't be recorded there
+type ClusterRecorders interface {
+	Recorder(cluster string) record.EventRecorder
+}
+
+// NewEventRecorder returns a recorder writing events through clientset, and
+// a function that stops it once the queued events are written
+func NewEventRecorder(clientset kubernetes.Interface) (record.EventRecorder, func()) {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: EventComponent}), broadcaster.Shutdown
+}
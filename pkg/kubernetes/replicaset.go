@@ -0,0 +1,235 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// revisionAnnotation is set by the deployment controller on deployments
+	// and their replica sets
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+
+	// changeCauseAnnotation records why a revision was rolled out
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+
+	// deploymentIndex indexes replica sets by the namespace/name of the
+	// deployment controlling them
+	deploymentIndex = "deployment"
+)
+
+// Revision is a rollout revision of a deployment, backed by one replica set
+type Revision struct {
+	Revision    int64     `json:"revision"`
+	ReplicaSet  string    `json:"replicaSet"`
+	Images      []string  `json:"images"`
+	Replicas    int32     `json:"replicas"`
+	Ready       int32     `json:"ready"`
+	Available   int32     `json:"available"`
+	ChangeCause string    `json:"changeCause,omitempty"`
+	Created     time.Time `json:"created"`
+	Current     bool      `json:"current"`
+}
+
+// DeploymentRevisions returns the revisions of a deployment, in ascending
+// order, from the replica sets it controls. Replica sets of other owners are
+// ignored.
+func DeploymentRevisions(dep *appsv1.Deployment, replicaSets []*appsv1.ReplicaSet) []Revision {
+	current := dep.Annotations[revisionAnnotation]
+
+	revisions := make([]Revision, 0, len(replicaSets))
+	for _, rs := range replicaSets {
+		if owner := metav1.GetControllerOf(rs); owner == nil || owner.UID != dep.UID {
+			continue
+		}
+		number, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		revision := Revision{
+			Revision:    number,
+			ReplicaSet:  rs.Name,
+			Images:      make([]string, 0, len(rs.Spec.Template.Spec.Containers)),
+			Ready:       rs.Status.ReadyReplicas,
+			Available:   rs.Status.AvailableReplicas,
+			ChangeCause: rs.Annotations[changeCauseAnnotation],
+			Created:     rs.CreationTimestamp.UTC(),
+			Current:     rs.Annotations[revisionAnnotation] == current,
+		}
+		if rs.Spec.Replicas != nil {
+			revision.Replicas = *rs.Spec.Replicas
+		}
+		for _, container := range rs.Spec.Template.Spec.Containers {
+			revision.Images = append(revision.Images, container.Image)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions
+}
+
+// DeploymentRevisions returns the revisions of a deployment read from the API server
+func (c *Client) DeploymentRevisions(ctx context.Context, namespace, name string) ([]Revision, error) {
+	dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := c.replicaSetsOf(ctx, dep)
+	if err != nil {
+		return nil, err
+	}
+	return DeploymentRevisions(dep, replicaSets), nil
+}
+
+// selectorOptions returns list options selecting the pods of a deployment
+func selectorOptions(dep *appsv1.Deployment) (metav1.ListOptions, error) {
+	options := metav1.ListOptions{}
+	if dep.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+		if err != nil {
+			return options, fmt.Errorf("invalid selector of deployment %s/%s: %w", dep.Namespace, dep.Name, err)
+		}
+		options.LabelSelector = selector.String()
+	}
+	return options, nil
+}
+
+// replicaSetsOf lists the replica sets matching the selector of a deployment.
+// Ownership is checked by the callers.
+func (c *Client) replicaSetsOf(ctx context.Context, dep *appsv1.Deployment) ([]*appsv1.ReplicaSet, error) {
+	options, err := selectorOptions(dep)
+	if err != nil {
+		return nil, err
+	}
+	list, err := c.clientset.AppsV1().ReplicaSets(dep.Namespace).List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaSets := make([]*appsv1.ReplicaSet, 0, len(list.Items))
+	for i := range list.Items {
+		replicaSets = append(replicaSets, &list.Items[i])
+	}
+	return replicaSets, nil
+}
+
+// ReplicaSetInformer caches the replica sets of a namespace, or of all
+// namespaces, indexed by the deployment controlling them
+type ReplicaSetInformer struct {
+	informer  cache.SharedIndexInformer
+	set       *InformerSet
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewReplicaSetInformer creates an informer watching the replica sets of
+// namespace; an empty namespace watches all namespaces. Managed fields are
+// dropped from cached replica sets.
+func NewReplicaSetInformer(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) *ReplicaSetInformer {
+	return NewReplicaSetInformerFor(NewInformerSet(clientset, namespace, resyncPeriod))
+}
+
+// NewReplicaSetInformerFor creates an informer sharing the replica set watch and cache of set
+func NewReplicaSetInformerFor(set *InformerSet) *ReplicaSetInformer {
+	informer := set.informerFor(&appsv1.ReplicaSet{}, stripManagedFields, func(clientset kubernetes.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		listWatcher := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.AppsV1().ReplicaSets(namespace).Watch(context.TODO(), options)
+			},
+		}
+
+		return cache.NewSharedIndexInformer(set.scoped(listWatcher), &appsv1.ReplicaSet{}, resyncPeriod, cache.Indexers{
+			deploymentIndex: indexByDeployment,
+		})
+	})
+
+	return &ReplicaSetInformer{
+		informer: informer,
+		set:      set,
+	}
+}
+
+// indexByDeployment indexes a replica set by the namespace/name of the
+// deployment controlling it
+func indexByDeployment(obj interface{}) ([]string, error) {
+	rs, ok := obj.(*appsv1.ReplicaSet)
+	if !ok {
+		return nil, nil
+	}
+	owner := metav1.GetControllerOf(rs)
+	if owner == nil || owner.Kind != "Deployment" {
+		return nil, nil
+	}
+	return []string{rs.Namespace + "/" + owner.Name}, nil
+}
+
+// Start starts watching replica sets and waits until the cache syncs or ctx
+// is done; on failure the informer is stopped
+func (ri *ReplicaSetInformer) Start(ctx context.Context) error {
+	ri.startOnce.Do(func() {
+		ri.set.run(ri.informer)
+	})
+
+	if !cache.WaitForCacheSync(ctx.Done(), ri.informer.HasSynced) {
+		ri.Stop()
+		return fmt.Errorf("failed to sync replica set cache: %w", ctx.Err())
+	}
+	return nil
+}
+
+// Stop stops watching replica sets
+func (ri *ReplicaSetInformer) Stop() {
+	ri.stopOnce.Do(func() {
+		ri.set.release(ri.informer)
+	})
+}
+
+// HasSynced reports whether the replica set cache has synced
+func (ri *ReplicaSetInformer) HasSynced() bool {
+	return ri.informer.HasSynced()
+}
+
+// ReplicaSets returns the cached replica sets controlled by a deployment
+func (ri *ReplicaSetInformer) ReplicaSets(dep *appsv1.Deployment) ([]*appsv1.ReplicaSet, error) {
+	objects, err := ri.informer.GetIndexer().ByIndex(deploymentIndex, dep.Namespace+"/"+dep.Name)
+	if err != nil {
+		return nil, err
+	}
+	replicaSets := make([]*appsv1.ReplicaSet, 0, len(objects))
+	for _, obj := range objects {
+		rs, ok := obj.(*appsv1.ReplicaSet)
+		if !ok {
+			continue
+		}
+		// A former deployment of the same name may have left replica sets behind
+		if owner := metav1.GetControllerOf(rs); owner != nil && owner.UID == dep.UID {
+			replicaSets = append(replicaSets, rs)
+		}
+	}
+	return replicaSets, nil
+}
+
+// Revisions returns the revisions of a deployment from the cached replica sets
+func (ri *ReplicaSetInformer) Revisions(dep *appsv1.Deployment) ([]Revision, error) {
+	replicaSets, err := ri.ReplicaSets(dep)
+	if err != nil {
+		return nil, err
+	}
+	return DeploymentRevisions(dep, replicaSets), nil
+}
@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newRevisionReplicaSet creates a replica set of revision controlled by dep
+func newRevisionReplicaSet(dep *appsv1.Deployment, name, revision, image string, replicas int32) *appsv1.ReplicaSet {
+	controller := true
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   dep.Namespace,
+			Labels:      map[string]string{"app": dep.Name},
+			Annotations: map[string]string{revisionAnnotation: revision},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       dep.Name,
+				UID:        dep.UID,
+				Controller: &controller,
+			}},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}}},
+		},
+		Status: appsv1.ReplicaSetStatus{ReadyReplicas: replicas},
+	}
+}
+
+func TestDeploymentRevisions(t *testing.T) {
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:        "web",
+		Namespace:   "shop",
+		UID:         types.UID("web-uid"),
+		Annotations: map[string]string{revisionAnnotation: "10"},
+	}}
+	dep.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+
+	current := newRevisionReplicaSet(dep, "web-c", "10", "web:3", 3)
+	current.Annotations[changeCauseAnnotation] = "bump to web:3"
+	// A replica set of a former deployment with the same name
+	orphan := newRevisionReplicaSet(dep, "web-x", "1", "web:0", 0)
+	orphan.OwnerReferences[0].UID = "old-uid"
+
+	clientset := fake.NewSimpleClientset(dep,
+		current,
+		newRevisionReplicaSet(dep, "web-a", "2", "web:1", 0),
+		newRevisionReplicaSet(dep, "web-b", "9", "web:2", 0),
+		orphan,
+	)
+
+	want := []Revision{
+		{Revision: 2, ReplicaSet: "web-a", Images: []string{"web:1"}},
+		{Revision: 9, ReplicaSet: "web-b", Images: []string{"web:2"}},
+		{Revision: 10, ReplicaSet: "web-c", Images: []string{"web:3"}, Replicas: 3, Ready: 3, ChangeCause: "bump to web:3", Current: true},
+	}
+	check := func(source string, revisions []Revision) {
+		t.Helper()
+		if len(revisions) != len(want) {
+			t.Fatalf("%s: expected %d revisions, got %+v", source, len(want), revisions)
+		}
+		for i, got := range revisions {
+			w := want[i]
+			if got.Revision != w.Revision || got.ReplicaSet != w.ReplicaSet || got.Images[0] != w.Images[0] ||
+				got.Replicas != w.Replicas || got.Ready != w.Ready || got.ChangeCause != w.ChangeCause || got.Current != w.Current {
+				t.Errorf("%s: expected %+v, got %+v", source, w, got)
+			}
+		}
+	}
+
+	client := NewClientWithClientset(clientset)
+	revisions, err := client.DeploymentRevisions(context.Background(), "shop", "web")
+	if err != nil {
+		t.Fatalf("DeploymentRevisions() error = %v", err)
+	}
+	check("client", revisions)
+
+	informer := NewReplicaSetInformer(clientset, "", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := informer.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+	revisions, err = informer.Revisions(dep)
+	if err != nil {
+		t.Fatalf("Revisions() error = %v", err)
+	}
+	check("informer", revisions)
+}
@@ -0,0 +1,254 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// restartedAtAnnotation is set on the pod template to restart a
+	// deployment, as kubectl rollout restart does
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+	// podTemplateHashLabel is added to pod templates by the deployment
+	// controller and must not be copied back into a deployment
+	podTemplateHashLabel = "pod-template-hash"
+)
+
+// ErrProgressDeadlineExceeded is returned when a rollout stopped progressing
+var ErrProgressDeadlineExceeded = errors.New("exceeded its progress deadline")
+
+// RolloutStatus describes the rollout of a deployment like kubectl rollout
+// status. It reports whether the rollout is complete, and fails when the
+// rollout exceeded its progress deadline.
+func RolloutStatus(dep *appsv1.Deployment) (string, bool, error) {
+	if dep.Generation > dep.Status.ObservedGeneration {
+		return "Waiting for deployment spec update to be observed...", false, nil
+	}
+
+	for _, condition := range dep.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return "", false, fmt.Errorf("deployment %q %w", dep.Name, ErrProgressDeadlineExceeded)
+		}
+	}
+
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+	status := dep.Status
+	switch {
+	case status.UpdatedReplicas < desired:
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...", dep.Name, status.UpdatedReplicas, desired), false, nil
+	case status.Replicas > status.UpdatedReplicas:
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...", dep.Name, status.Replicas-status.UpdatedReplicas), false, nil
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...", dep.Name, status.AvailableReplicas, status.UpdatedReplicas), false, nil
+	}
+	return fmt.Sprintf("deployment %q successfully rolled out", dep.Name), true, nil
+}
+
+// WatchRolloutStatus follows the rollout of a deployment through an informer
+// on that deployment, calling report with each new status message, until the
+// rollout completes, fails or ctx is done
+func (c *Client) WatchRolloutStatus(ctx context.Context, namespace, name string, report func(string)) error {
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	listWatcher := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return c.clientset.AppsV1().Deployments(namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return c.clientset.AppsV1().Deployments(namespace).Watch(ctx, options)
+		},
+	}
+	informer := cache.NewSharedIndexInformer(listWatcher, &appsv1.Deployment{}, 0, cache.Indexers{})
+
+	updates := make(chan *appsv1.Deployment, 1)
+	send := func(obj interface{}) {
+		dep, ok := obj.(*appsv1.Deployment)
+		if !ok || dep.Name != name {
+			return
+		}
+		// Only the latest state matters, drop a pending older one
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- dep
+	}
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    send,
+		UpdateFunc: func(_, obj interface{}) { send(obj) },
+	})
+	if err != nil {
+		return err
+	}
+
+	stopper := make(chan struct{})
+	defer close(stopper)
+	go informer.Run(stopper)
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to watch deployment %s/%s: %w", namespace, name, ctx.Err())
+	}
+	if _, exists, _ := informer.GetIndexer().GetByKey(namespace + "/" + name); !exists {
+		return fmt.Errorf("deployment %s/%s not found", namespace, name)
+	}
+
+	last := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for deployment %s/%s rollout: %w", namespace, name, ctx.Err())
+		case dep := <-updates:
+			message, done, err := RolloutStatus(dep)
+			if err != nil {
+				return err
+			}
+			if message != last {
+				report(message)
+				last = message
+			}
+			if done {
+				return nil
+			}
+		}
+	}
+}
+
+// RolloutRestart restarts the pods of a deployment by stamping its pod
+// template, like kubectl rollout restart
+func (c *Client) RolloutRestart(ctx context.Context, namespace, name string) error {
+	dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if dep.Spec.Paused {
+		return fmt.Errorf("can't restart paused deployment %s/%s (run rollout resume first)", namespace, name)
+	}
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{restartedAtAnnotation: time.Now().Format(time.RFC3339)},
+				},
+			},
+		},
+	}
+	return c.patchDeployment(ctx, namespace, name, types.StrategicMergePatchType, patch)
+}
+
+// RolloutPause pauses the rollout of a deployment; it fails when the
+// deployment is already paused
+func (c *Client) RolloutPause(ctx context.Context, namespace, name string) error {
+	return c.setPaused(ctx, namespace, name, true)
+}
+
+// RolloutResume resumes the rollout of a paused deployment; it fails when
+// the deployment is not paused
+func (c *Client) RolloutResume(ctx context.Context, namespace, name string) error {
+	return c.setPaused(ctx, namespace, name, false)
+}
+
+// setPaused pauses or resumes a deployment
+func (c *Client) setPaused(ctx context.Context, namespace, name string, paused bool) error {
+	dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if dep.Spec.Paused == paused {
+		state := "paused"
+		if !paused {
+			state = "not paused"
+		}
+		return fmt.Errorf("deployment %s/%s is already %s", namespace, name, state)
+	}
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"paused": paused},
+	}
+	return c.patchDeployment(ctx, namespace, name, types.MergePatchType, patch)
+}
+
+// RolloutUndo rolls a deployment back to the pod template of a revision, or
+// of the revision before the current one when toRevision is 0, like kubectl
+// rollout undo. It returns the revision rolled back to.
+func (c *Client) RolloutUndo(ctx context.Context, namespace, name string, toRevision int64) (int64, error) {
+	dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	if dep.Spec.Paused {
+		return 0, fmt.Errorf("can't roll back paused deployment %s/%s (run rollout resume first)", namespace, name)
+	}
+
+	replicaSets, err := c.replicaSetsOf(ctx, dep)
+	if err != nil {
+		return 0, err
+	}
+	revisions := DeploymentRevisions(dep, replicaSets)
+
+	var target *Revision
+	for i := range revisions {
+		switch revision := &revisions[i]; {
+		case toRevision != 0 && revision.Revision == toRevision:
+			target = revision
+		case toRevision == 0 && !revision.Current:
+			// Revisions are ascending, so this ends at the latest previous one
+			target = revision
+		}
+	}
+	if target == nil {
+		if toRevision == 0 {
+			return 0, fmt.Errorf("no rollout history found for deployment %s/%s", namespace, name)
+		}
+		return 0, fmt.Errorf("unable to find revision %d of deployment %s/%s", toRevision, namespace, name)
+	}
+
+	var template corev1.PodTemplateSpec
+	for _, rs := range replicaSets {
+		if rs.Name == target.ReplicaSet {
+			template = *rs.Spec.Template.DeepCopy()
+		}
+	}
+	delete(template.Labels, podTemplateHashLabel)
+	if apiequality.Semantic.DeepEqual(template, dep.Spec.Template) {
+		return 0, fmt.Errorf("skipped rollback of deployment %s/%s: current template already matches revision %d", namespace, name, target.Revision)
+	}
+
+	// Replace the whole template, so that fields only set in the current
+	// revision are dropped, guarded by the version the history was read from
+	patch := []map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": dep.ResourceVersion},
+		{"op": "replace", "path": "/spec/template", "value": template},
+	}
+	if err := c.patchDeployment(ctx, namespace, name, types.JSONPatchType, patch); err != nil {
+		return 0, err
+	}
+	return target.Revision, nil
+}
+
+// patchDeployment applies a patch of the given type to a deployment
+func (c *Client) patchDeployment(ctx context.Context, namespace, name string, patchType types.PatchType, patch interface{}) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = c.clientset.AppsV1().Deployments(namespace).Patch(ctx, name, patchType, data, metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,163 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRolloutStatus(t *testing.T) {
+	replicas := int32(3)
+	tests := []struct {
+		name    string
+		status  appsv1.DeploymentStatus
+		gen     int64
+		want    string
+		done    bool
+		wantErr error
+	}{
+		{"spec not observed", appsv1.DeploymentStatus{ObservedGeneration: 1}, 2, "Waiting for deployment spec update to be observed...", false, nil},
+		{"updating", appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 1, Replicas: 3}, 2,
+			`Waiting for deployment "web" rollout to finish: 1 out of 3 new replicas have been updated...`, false, nil},
+		{"terminating old", appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 3, Replicas: 4}, 2,
+			`Waiting for deployment "web" rollout to finish: 1 old replicas are pending termination...`, false, nil},
+		{"becoming available", appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 3, Replicas: 3, AvailableReplicas: 2}, 2,
+			`Waiting for deployment "web" rollout to finish: 2 of 3 updated replicas are available...`, false, nil},
+		{"complete", appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 3, Replicas: 3, AvailableReplicas: 3}, 2,
+			`deployment "web" successfully rolled out`, true, nil},
+		{"deadline exceeded", appsv1.DeploymentStatus{ObservedGeneration: 2, Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"},
+		}}, 2, "", false, ErrProgressDeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: tt.gen},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     tt.status,
+			}
+			message, done, err := RolloutStatus(dep)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if message != tt.want || done != tt.done {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.want, tt.done, message, done)
+			}
+		})
+	}
+}
+
+func TestWatchRolloutStatus(t *testing.T) {
+	replicas := int32(2)
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 1, Replicas: 2},
+	}
+	clientset := fake.NewSimpleClientset(dep)
+	client := NewClientWithClientset(clientset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var messages []string
+	first := make(chan struct{})
+	go func() {
+		<-first
+		rolledOut := dep.DeepCopy()
+		rolledOut.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 2, Replicas: 2, AvailableReplicas: 2}
+		_, _ = clientset.AppsV1().Deployments("shop").UpdateStatus(ctx, rolledOut, metav1.UpdateOptions{})
+	}()
+	err := client.WatchRolloutStatus(ctx, "shop", "web", func(message string) {
+		if len(messages) == 0 {
+			close(first)
+		}
+		messages = append(messages, message)
+	})
+	if err != nil {
+		t.Fatalf("WatchRolloutStatus() error = %v", err)
+	}
+	if len(messages) != 2 || messages[1] != `deployment "web" successfully rolled out` {
+		t.Errorf("Expected progress then completion, got %q", messages)
+	}
+
+	if err := client.WatchRolloutStatus(ctx, "shop", "api", func(string) {}); err == nil {
+		t.Error("Expected an error for an unknown deployment")
+	}
+}
+
+func TestRolloutPauseResumeRestart(t *testing.T) {
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}
+	clientset := fake.NewSimpleClientset(dep)
+	client := NewClientWithClientset(clientset)
+	ctx := context.Background()
+	get := func() *appsv1.Deployment {
+		dep, _ := clientset.AppsV1().Deployments("shop").Get(ctx, "web", metav1.GetOptions{})
+		return dep
+	}
+
+	if err := client.RolloutResume(ctx, "shop", "web"); err == nil {
+		t.Error("Expected resuming a deployment that is not paused to fail")
+	}
+	if err := client.RolloutPause(ctx, "shop", "web"); err != nil || !get().Spec.Paused {
+		t.Fatalf("Expected the deployment to be paused, error = %v", err)
+	}
+	if err := client.RolloutRestart(ctx, "shop", "web"); err == nil {
+		t.Error("Expected restarting a paused deployment to fail")
+	}
+	if err := client.RolloutResume(ctx, "shop", "web"); err != nil || get().Spec.Paused {
+		t.Fatalf("Expected the deployment to be resumed, error = %v", err)
+	}
+	if err := client.RolloutRestart(ctx, "shop", "web"); err != nil {
+		t.Fatalf("RolloutRestart() error = %v", err)
+	}
+	if get().Spec.Template.Annotations[restartedAtAnnotation] == "" {
+		t.Error("Expected the pod template to be stamped")
+	}
+}
+
+func TestRolloutUndo(t *testing.T) {
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:            "web",
+		Namespace:       "shop",
+		UID:             "web-uid",
+		ResourceVersion: "5",
+		Annotations:     map[string]string{revisionAnnotation: "3"},
+	}}
+	dep.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	dep.Spec.Template.Spec.Containers = newRevisionReplicaSet(dep, "", "", "web:3", 1).Spec.Template.Spec.Containers
+
+	old := newRevisionReplicaSet(dep, "web-b", "2", "web:2", 0)
+	old.Spec.Template.Labels = map[string]string{"app": "web", podTemplateHashLabel: "b"}
+	clientset := fake.NewSimpleClientset(dep,
+		newRevisionReplicaSet(dep, "web-a", "1", "web:1", 0),
+		old,
+		newRevisionReplicaSet(dep, "web-c", "3", "web:3", 1),
+	)
+	client := NewClientWithClientset(clientset)
+	ctx := context.Background()
+
+	if _, err := client.RolloutUndo(ctx, "shop", "web", 7); err == nil {
+		t.Error("Expected an unknown revision to fail")
+	}
+	if _, err := client.RolloutUndo(ctx, "shop", "web", 3); err == nil {
+		t.Error("Expected rolling back to the current template to be skipped")
+	}
+
+	revision, err := client.RolloutUndo(ctx, "shop", "web", 0)
+	if err != nil {
+		t.Fatalf("RolloutUndo() error = %v", err)
+	}
+	if revision != 2 {
+		t.Errorf("Expected to roll back to revision 2, got %d", revision)
+	}
+	updated, _ := clientset.AppsV1().Deployments("shop").Get(ctx, "web", metav1.GetOptions{})
+	template := updated.Spec.Template
+	if template.Spec.Containers[0].Image != "web:2" || template.Labels["app"] != "web" || template.Labels[podTemplateHashLabel] != "" {
+		t.Errorf("Expected the template of revision 2 without its hash label, got %+v", template)
+	}
+}
@@ -0,0 +1,212 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// snapshotVersion is the format version of deployment cache snapshots
+const snapshotVersion = 1
+
+// DefaultSnapshotInterval is how often the deployment cache is saved by
+// default
+const DefaultSnapshotInterval = time.Minute
+
+// ErrSnapshotRejected is returned for snapshots that can't be served, such as
+// those of another namespace scope or older than the maximum age
+var ErrSnapshotRejected = errors.New("deployment snapshot rejected")
+
+// deploymentSnapshot is the deployment cache as saved to disk
+type deploymentSnapshot struct {
+	Version int `json:"version"`
+
+	// Namespaces the informer watched, empty for all
+	Namespace string `json:"namespace"`
+
+	TakenAt     time.Time           `json:"takenAt"`
+	Deployments []appsv1.Deployment `json:"deployments"`
+}
+
+// loadedSnapshot is a snapshot served until the cache has synced
+type loadedSnapshot struct {
+	takenAt time.Time
+	indexer cache.Indexer
+}
+
+// SaveSnapshot writes the deployments of the synced cache to path, replacing
+// the previous snapshot atomically
+func (di *DeploymentInformer) SaveSnapshot(path string) error {
+	if di.Stale() {
+		return fmt.Errorf("deployment cache has not synced")
+	}
+	deployments, err := di.ListDeployments()
+	if err != nil {
+		return err
+	}
+
+	snapshot := deploymentSnapshot{
+		Version:     snapshotVersion,
+		Namespace:   di.namespace,
+		TakenAt:     time.Now().UTC(),
+		Deployments: make([]appsv1.Deployment, 0, len(deployments)),
+	}
+	for _, dep := range deployments {
+		snapshot.Deployments = append(snapshot.Deployments, *dep)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode deployment snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write deployment snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write deployment snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write deployment snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write deployment snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot loads the snapshot at path, to be served until the cache has
+// synced. Snapshots of other namespaces, older than maxAge (0 = any age) or
+// holding deployments without a resourceVersion are rejected. It must be
+// called before Start, and returns the number of deployments loaded.
+func (di *DeploymentInformer) LoadSnapshot(path string, maxAge time.Duration) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var snapshot deploymentSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrSnapshotRejected, err)
+	}
+
+	switch {
+	case snapshot.Version != snapshotVersion:
+		return 0, fmt.Errorf("%w: unsupported version %d", ErrSnapshotRejected, snapshot.Version)
+	case snapshot.Namespace != di.namespace:
+		return 0, fmt.Errorf("%w: taken of namespaces %q, not %q", ErrSnapshotRejected, snapshot.Namespace, di.namespace)
+	case maxAge > 0 && time.Since(snapshot.TakenAt) > maxAge:
+		return 0, fmt.Errorf("%w: taken %s ago", ErrSnapshotRejected, time.Since(snapshot.TakenAt).Round(time.Second))
+	}
+
+	di.mu.RLock()
+	indexers := di.indexers
+	di.mu.RUnlock()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, indexers)
+	for i := range snapshot.Deployments {
+		dep := &snapshot.Deployments[i]
+		if dep.ResourceVersion == "" {
+			return 0, fmt.Errorf("%w: deployment %s/%s has no resourceVersion", ErrSnapshotRejected, dep.Namespace, dep.Name)
+		}
+		if err := indexer.Add(dep); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrSnapshotRejected, err)
+		}
+	}
+
+	di.setSnapshot(&loadedSnapshot{takenAt: snapshot.TakenAt, indexer: indexer})
+	return len(snapshot.Deployments), nil
+}
+
+// RunSnapshots saves the deployment cache to path every interval (0 = 1m)
+// until ctx is done, and once more then
+func (di *DeploymentInformer) RunSnapshots(ctx context.Context, path string, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSnapshotInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	save := func() {
+		if !di.IsStarted() || di.Stale() {
+			return
+		}
+		if err := di.SaveSnapshot(path); err != nil {
+			informerLog().Warn().Err(err).Str("path", path).Msg("Failed to save deployment snapshot")
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			save()
+			return
+		case <-ticker.C:
+			save()
+		}
+	}
+}
+
+// Stale reports whether deployments are served from a snapshot because the
+// cache has not synced yet
+func (di *DeploymentInformer) Stale() bool {
+	return di.loadedSnapshot() != nil
+}
+
+// loadedSnapshot returns the snapshot served, nil when there is none
+func (di *DeploymentInformer) loadedSnapshot() *loadedSnapshot {
+	di.snapshotMu.RLock()
+	defer di.snapshotMu.RUnlock()
+	return di.snapshot
+}
+
+// setSnapshot sets the snapshot served, nil to stop serving one
+func (di *DeploymentInformer) setSnapshot(snapshot *loadedSnapshot) {
+	di.snapshotMu.Lock()
+	defer di.snapshotMu.Unlock()
+	di.snapshot = snapshot
+}
+
+// replaceSnapshot stops serving the loaded snapshot once the cache has
+// synced, and logs how far the snapshot was behind the synced cache by
+// comparing resourceVersions
+func (di *DeploymentInformer) replaceSnapshot(synced cache.Indexer) {
+	snapshot := di.loadedSnapshot()
+	if snapshot == nil {
+		return
+	}
+	di.setSnapshot(nil)
+
+	changed, removed := 0, 0
+	for _, obj := range snapshot.indexer.List() {
+		old, ok := obj.(*appsv1.Deployment)
+		if !ok {
+			continue
+		}
+		current, exists, err := synced.Get(old)
+		if err != nil || !exists {
+			removed++
+			continue
+		}
+		if dep, ok := current.(*appsv1.Deployment); !ok || dep.ResourceVersion != old.ResourceVersion {
+			changed++
+		}
+	}
+	added := len(synced.ListKeys()) - (len(snapshot.indexer.ListKeys()) - removed)
+
+	informerLog().Info().
+		Time("taken_at", snapshot.takenAt).
+		Int("changed", changed).
+		Int("added", added).
+		Int("removed", removed).
+		Msg("Deployment cache synced, replacing snapshot")
+}
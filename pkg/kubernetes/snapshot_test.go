@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newSnapshotDeployment(namespace, name, resourceVersion string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			ResourceVersion: resourceVersion,
+			Labels:          map[string]string{"app": name},
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+	}
+}
+
+func TestDeploymentInformer_Snapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots", "deployments.json")
+
+	informer := NewDeploymentInformer(fake.NewSimpleClientset(
+		newSnapshotDeployment("shop", "web", "1"),
+		newSnapshotDeployment("shop", "api", "2"),
+	), "", time.Minute)
+	if err := informer.SaveSnapshot(path); err == nil {
+		t.Error("Expected saving an unstarted informer to fail")
+	}
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := informer.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	informer.Stop()
+
+	// A restarted informer serves the snapshot until its cache has synced
+	restarted := NewDeploymentInformer(fake.NewSimpleClientset(
+		newSnapshotDeployment("shop", "web", "3"),
+	), "", time.Minute)
+	loaded, err := restarted.LoadSnapshot(path, time.Hour)
+	if err != nil || loaded != 2 {
+		t.Fatalf("LoadSnapshot() = %d, %v, want 2 deployments", loaded, err)
+	}
+	if !restarted.Stale() || !restarted.IsStarted() || !restarted.HasSynced() {
+		t.Error("Expected the snapshot to be served as a stale, synced cache")
+	}
+	if dep, err := restarted.GetDeployment("shop", "api"); err != nil || dep.ResourceVersion != "2" {
+		t.Errorf("GetDeployment() = %v, %v, want api from the snapshot", dep, err)
+	}
+	if deps, err := restarted.DeploymentsByIndex(AppLabelIndex, AppLabelKey("shop", "web")); err != nil || len(deps) != 1 {
+		t.Errorf("Expected the snapshot to be indexed, got %v, %v", deps, err)
+	}
+
+	if err := restarted.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer restarted.Stop()
+	if restarted.Stale() {
+		t.Error("Expected the synced cache to replace the snapshot")
+	}
+	deployments, err := restarted.ListDeployments()
+	if err != nil || len(deployments) != 1 || deployments[0].ResourceVersion != "3" {
+		t.Errorf("Expected the live web only, got %v, %v", deployments, err)
+	}
+}
+
+func TestDeploymentInformer_LoadSnapshotRejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployments.json")
+	informer := NewDeploymentInformer(fake.NewSimpleClientset(newSnapshotDeployment("shop", "web", "1")), "", time.Minute)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := informer.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	informer.Stop()
+
+	scoped := NewDeploymentInformer(fake.NewSimpleClientset(), "shop", time.Minute)
+	if _, err := scoped.LoadSnapshot(path, 0); !errors.Is(err, ErrSnapshotRejected) {
+		t.Errorf("Expected a snapshot of other namespaces to be rejected, got %v", err)
+	}
+
+	other := NewDeploymentInformer(fake.NewSimpleClientset(), "", time.Minute)
+	time.Sleep(2 * time.Millisecond)
+	if _, err := other.LoadSnapshot(path, time.Millisecond); !errors.Is(err, ErrSnapshotRejected) {
+		t.Errorf("Expected an old snapshot to be rejected, got %v", err)
+	}
+	if _, err := other.LoadSnapshot(filepath.Join(t.TempDir(), "missing.json"), 0); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing snapshot to be reported as such, got %v", err)
+	}
+	if other.Stale() {
+		t.Error("Expected no snapshot to be served")
+	}
+}
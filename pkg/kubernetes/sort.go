@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// SortDeployments sorts deployments by the value of a field, given as a
+// JSONPath like kubectl get --sort-by, e.g. .metadata.creationTimestamp or
+// {.status.readyReplicas}. Numbers compare by value and other fields as
+// text; deployments without the field come first, and deployments with equal
+// values keep their order. An empty field leaves deployments unsorted.
+func SortDeployments(deployments []appsv1.Deployment, field string) error {
+	return sortByField(deployments, field)
+}
+
+// SortPods sorts pods by the value of a field like SortDeployments, e.g.
+// .spec.nodeName or .status.startTime
+func SortPods(pods []corev1.Pod, field string) error {
+	return sortByField(pods, field)
+}
+
+// sortByField sorts objects by the value of a JSONPath field, evaluated on
+// their unstructured form so timestamps and quantities compare as text
+func sortByField[T any](objects []T, field string) error {
+	if strings.TrimSpace(field) == "" {
+		return nil
+	}
+	path := jsonpath.New("sort-by").AllowMissingKeys(true)
+	if err := path.Parse(relaxedJSONPath(field)); err != nil {
+		return fmt.Errorf("invalid sort field %s: %w", field, err)
+	}
+
+	keys := make([]interface{}, len(objects))
+	for i := range objects {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&objects[i])
+		if err != nil {
+			return fmt.Errorf("failed to sort by %s: %w", field, err)
+		}
+		results, err := path.FindResults(obj)
+		if err != nil {
+			return fmt.Errorf("failed to sort by %s: %w", field, err)
+		}
+		if len(results) > 0 && len(results[0]) > 0 {
+			keys[i] = results[0][0].Interface()
+		}
+	}
+
+	order := make([]int, len(objects))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return lessSortKey(keys[order[i]], keys[order[j]])
+	})
+	sorted := make([]T, len(objects))
+	for i, index := range order {
+		sorted[i] = objects[index]
+	}
+	copy(objects, sorted)
+	return nil
+}
+
+// relaxedJSONPath turns a field given as .status.replicas, status.replicas or
+// {.status.replicas} into a JSONPath template
+func relaxedJSONPath(field string) string {
+	field = strings.TrimSpace(field)
+	field = strings.TrimSuffix(strings.TrimPrefix(field, "{"), "}")
+	if !strings.HasPrefix(field, ".") {
+		field = "." + field
+	}
+	return "{" + field + "}"
+}
+
+// lessSortKey orders the field values of two objects, missing values first
+func lessSortKey(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	x, xNumber := sortNumber(a)
+	y, yNumber := sortNumber(b)
+	if xNumber && yNumber {
+		return x < y
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+// sortNumber returns a numeric field value as a float64
+func sortNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSortDeployments(t *testing.T) {
+	now := time.Now()
+	newDeployment := func(name string, ready int32, created time.Time, team string) appsv1.Deployment {
+		deploy := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)}}
+		deploy.Status.ReadyReplicas = ready
+		if team != "" {
+			deploy.Labels = map[string]string{"team": team}
+		}
+		return deploy
+	}
+	deployments := func() []appsv1.Deployment {
+		return []appsv1.Deployment{
+			newDeployment("web", 10, now.Add(-time.Hour), "shop"),
+			newDeployment("api", 2, now, ""),
+			newDeployment("worker", 9, now.Add(-2*time.Hour), "data"),
+		}
+	}
+	names := func(deployments []appsv1.Deployment) []string {
+		var names []string
+		for _, deploy := range deployments {
+			names = append(names, deploy.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		field    string
+		expected []string
+	}{
+		{"", []string{"web", "api", "worker"}},
+		{".metadata.name", []string{"api", "web", "worker"}},
+		{"{.metadata.name}", []string{"api", "web", "worker"}},
+		// Numbers compare by value, not as text
+		{"status.readyReplicas", []string{"api", "worker", "web"}},
+		{".metadata.creationTimestamp", []string{"worker", "web", "api"}},
+		// Deployments without the field come first
+		{".metadata.labels.team", []string{"api", "worker", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			sorted := deployments()
+			if err := SortDeployments(sorted, tt.field); err != nil {
+				t.Fatalf("SortDeployments() error = %v", err)
+			}
+			if got := names(sorted); len(got) != len(tt.expected) || got[0] != tt.expected[0] || got[1] != tt.expected[1] || got[2] != tt.expected[2] {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if err := SortDeployments(deployments(), ".metadata[name"); err == nil {
+		t.Error("Expected an error for an invalid field")
+	}
+}
+
+func TestSortPods(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-1"}, Spec: corev1.PodSpec{NodeName: "node-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-3"}, Spec: corev1.PodSpec{NodeName: "node-a"}},
+	}
+	if err := SortPods(pods, ".spec.nodeName"); err != nil {
+		t.Fatalf("SortPods() error = %v", err)
+	}
+	if pods[0].Name != "web-2" || pods[1].Name != "web-3" || pods[2].Name != "web-1" {
+		t.Errorf("Expected web-2, web-3, web-1, got %s, %s, %s", pods[0].Name, pods[1].Name, pods[2].Name)
+	}
+}
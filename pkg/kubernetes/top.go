@@ -0,0 +1,357 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// metricsAPIPath is the path of the resource metrics API served by
+// metrics-server
+const metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+// Orders of resource usage, most used first
+const (
+	TopSortCPU    = "cpu"
+	TopSortMemory = "memory"
+)
+
+// ContainerMetrics is the resource usage of a container
+type ContainerMetrics struct {
+	Name  string              `json:"name"`
+	Usage corev1.ResourceList `json:"usage"`
+}
+
+// PodMetrics is the resource usage of the containers of a pod, as served by
+// metrics.k8s.io/v1beta1
+type PodMetrics struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Timestamp         metav1.Time        `json:"timestamp"`
+	Window            metav1.Duration    `json:"window"`
+	Containers        []ContainerMetrics `json:"containers"`
+}
+
+// NodeMetrics is the resource usage of a node, as served by
+// metrics.k8s.io/v1beta1
+type NodeMetrics struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Timestamp         metav1.Time         `json:"timestamp"`
+	Window            metav1.Duration     `json:"window"`
+	Usage             corev1.ResourceList `json:"usage"`
+}
+
+// PodUsage is the cpu, in millicores, and memory, in bytes, used by a pod
+type PodUsage struct {
+	Namespace string
+	Name      string
+	Node      string
+	CPU       int64
+	Memory    int64
+}
+
+// NodeUsage is the cpu and memory used by a node, and their percentage of
+// its allocatable resources (-1 when the node isn't cached)
+type NodeUsage struct {
+	Name          string
+	CPU           int64
+	Memory        int64
+	CPUPercent    int
+	MemoryPercent int
+}
+
+// DeploymentUsage is the cpu and memory used by the pods of a deployment
+// reported by metrics-server
+type DeploymentUsage struct {
+	Namespace string
+	Name      string
+	Pods      int
+	CPU       int64
+	Memory    int64
+}
+
+// PodMetrics lists the resource usage of the pods of namespace ("" = all
+// namespaces) from metrics-server
+func (c *Client) PodMetrics(ctx context.Context, namespace string) ([]PodMetrics, error) {
+	path := metricsAPIPath + "/pods"
+	if namespace != "" {
+		path = metricsAPIPath + "/namespaces/" + namespace + "/pods"
+	}
+	var list struct {
+		Items []PodMetrics `json:"items"`
+	}
+	if err := c.getMetrics(ctx, path, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// NodeMetrics lists the resource usage of the nodes from metrics-server
+func (c *Client) NodeMetrics(ctx context.Context) ([]NodeMetrics, error) {
+	var list struct {
+		Items []NodeMetrics `json:"items"`
+	}
+	if err := c.getMetrics(ctx, metricsAPIPath+"/nodes", &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// getMetrics reads a list of the metrics API into list
+func (c *Client) getMetrics(ctx context.Context, path string, list interface{}) error {
+	data, err := c.clientset.Discovery().RESTClient().Get().AbsPath(path).DoRaw(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("metrics API not available, is metrics-server installed? %w", err)
+		}
+		return fmt.Errorf("failed to get metrics: %w", err)
+	}
+	if err := json.Unmarshal(data, list); err != nil {
+		return fmt.Errorf("failed to decode metrics: %w", err)
+	}
+	return nil
+}
+
+// TopPods joins the usage of pods with the cached pods matching selector.
+// Pods that are no longer cached are left out.
+func TopPods(metrics []PodMetrics, pods []*corev1.Pod, selector labels.Selector) []PodUsage {
+	cached := make(map[string]*corev1.Pod, len(pods))
+	for _, pod := range pods {
+		cached[pod.Namespace+"/"+pod.Name] = pod
+	}
+
+	usage := make([]PodUsage, 0, len(metrics))
+	for _, m := range metrics {
+		pod, ok := cached[m.Namespace+"/"+m.Name]
+		if !ok || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		cpu, memory := podMetricsUsage(m)
+		usage = append(usage, PodUsage{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Node:      pod.Spec.NodeName,
+			CPU:       cpu,
+			Memory:    memory,
+		})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Namespace != usage[j].Namespace {
+			return usage[i].Namespace < usage[j].Namespace
+		}
+		return usage[i].Name < usage[j].Name
+	})
+	return usage
+}
+
+// TopNodes joins the usage of nodes with the allocatable resources of the
+// cached nodes
+func TopNodes(metrics []NodeMetrics, nodes []*corev1.Node) []NodeUsage {
+	cached := make(map[string]*corev1.Node, len(nodes))
+	for _, node := range nodes {
+		cached[node.Name] = node
+	}
+
+	usage := make([]NodeUsage, 0, len(metrics))
+	for _, m := range metrics {
+		u := NodeUsage{
+			Name:          m.Name,
+			CPU:           m.Usage.Cpu().MilliValue(),
+			Memory:        m.Usage.Memory().Value(),
+			CPUPercent:    -1,
+			MemoryPercent: -1,
+		}
+		if node, ok := cached[m.Name]; ok {
+			u.CPUPercent = percent(u.CPU, node.Status.Allocatable.Cpu().MilliValue())
+			u.MemoryPercent = percent(u.Memory, node.Status.Allocatable.Memory().Value())
+		}
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Name < usage[j].Name })
+	return usage
+}
+
+// TopDeployments sums the usage of the cached pods of each deployment
+// matching selector. Pods count those with metrics.
+func TopDeployments(metrics []PodMetrics, deployments []*appsv1.Deployment, pods []*corev1.Pod, selector labels.Selector) ([]DeploymentUsage, error) {
+	byPod := make(map[string]PodMetrics, len(metrics))
+	for _, m := range metrics {
+		byPod[m.Namespace+"/"+m.Name] = m
+	}
+
+	usage := make([]DeploymentUsage, 0, len(deployments))
+	for _, dep := range deployments {
+		if !selector.Matches(labels.Set(dep.Labels)) {
+			continue
+		}
+		podSelector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of deployment %s/%s: %w", dep.Namespace, dep.Name, err)
+		}
+
+		u := DeploymentUsage{Namespace: dep.Namespace, Name: dep.Name}
+		for _, pod := range pods {
+			if pod.Namespace != dep.Namespace || !podSelector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			if m, ok := byPod[pod.Namespace+"/"+pod.Name]; ok {
+				cpu, memory := podMetricsUsage(m)
+				u.Pods++
+				u.CPU += cpu
+				u.Memory += memory
+			}
+		}
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Namespace != usage[j].Namespace {
+			return usage[i].Namespace < usage[j].Namespace
+		}
+		return usage[i].Name < usage[j].Name
+	})
+	return usage, nil
+}
+
+// podMetricsUsage sums the cpu, in millicores, and memory, in bytes, of the
+// containers of a pod
+func podMetricsUsage(m PodMetrics) (cpu, memory int64) {
+	for _, container := range m.Containers {
+		cpu += container.Usage.Cpu().MilliValue()
+		memory += container.Usage.Memory().Value()
+	}
+	return cpu, memory
+}
+
+// percent returns used as a percentage of total, or -1 without a total
+func percent(used, total int64) int {
+	if total <= 0 {
+		return -1
+	}
+	return int(used * 100 / total)
+}
+
+// resourceUsage is the cpu, in millicores, and memory, in bytes, used by a
+// pod, node or deployment
+type resourceUsage interface {
+	usage() (cpu, memory int64)
+}
+
+func (u PodUsage) usage() (int64, int64)        { return u.CPU, u.Memory }
+func (u NodeUsage) usage() (int64, int64)       { return u.CPU, u.Memory }
+func (u DeploymentUsage) usage() (int64, int64) { return u.CPU, u.Memory }
+
+// SortTopUsage orders usage by cpu or memory, most used first, keeping the
+// order of equal usage. An empty order keeps usage unsorted.
+func SortTopUsage[T resourceUsage](usage []T, by string) error {
+	var key func(T) int64
+	switch by {
+	case "":
+		return nil
+	case TopSortCPU:
+		key = func(u T) int64 {
+			cpu, _ := u.usage()
+			return cpu
+		}
+	case TopSortMemory:
+		key = func(u T) int64 {
+			_, memory := u.usage()
+			return memory
+		}
+	default:
+		return fmt.Errorf("unsupported sort order %q (use cpu or memory)", by)
+	}
+	sort.SliceStable(usage, func(i, j int) bool { return key(usage[i]) > key(usage[j]) })
+	return nil
+}
+
+// TopPodsPrint prints the usage of pods in kubectl top format
+func TopPodsPrint(usage []PodUsage, showNamespace bool) {
+	if len(usage) == 0 {
+		fmt.Println("No resources found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if showNamespace {
+		fmt.Fprintln(w, "NAMESPACE\tNAME\tCPU(cores)\tMEMORY(bytes)\tNODE")
+	} else {
+		fmt.Fprintln(w, "NAME\tCPU(cores)\tMEMORY(bytes)\tNODE")
+	}
+	for _, u := range usage {
+		row := fmt.Sprintf("%s\t%s\t%s\t%s", u.Name, formatCPU(u.CPU), formatMemory(u.Memory), valueOrNone(u.Node))
+		if showNamespace {
+			row = u.Namespace + "\t" + row
+		}
+		fmt.Fprintln(w, row)
+	}
+}
+
+// TopNodesPrint prints the usage of nodes in kubectl top format
+func TopNodesPrint(usage []NodeUsage) {
+	if len(usage) == 0 {
+		fmt.Println("No resources found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tCPU(cores)\tCPU%\tMEMORY(bytes)\tMEMORY%")
+	for _, u := range usage {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			u.Name, formatCPU(u.CPU), formatPercent(u.CPUPercent), formatMemory(u.Memory), formatPercent(u.MemoryPercent))
+	}
+}
+
+// TopDeploymentsPrint prints the usage of deployments in kubectl top format,
+// with the number of pods reporting metrics
+func TopDeploymentsPrint(usage []DeploymentUsage, showNamespace bool) {
+	if len(usage) == 0 {
+		fmt.Println("No resources found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if showNamespace {
+		fmt.Fprintln(w, "NAMESPACE\tNAME\tPODS\tCPU(cores)\tMEMORY(bytes)")
+	} else {
+		fmt.Fprintln(w, "NAME\tPODS\tCPU(cores)\tMEMORY(bytes)")
+	}
+	for _, u := range usage {
+		row := fmt.Sprintf("%s\t%d\t%s\t%s", u.Name, u.Pods, formatCPU(u.CPU), formatMemory(u.Memory))
+		if showNamespace {
+			row = u.Namespace + "\t" + row
+		}
+		fmt.Fprintln(w, row)
+	}
+}
+
+// formatCPU formats millicores like kubectl top, e.g. 250m
+func formatCPU(milli int64) string {
+	return fmt.Sprintf("%dm", milli)
+}
+
+// formatMemory formats bytes like kubectl top, e.g. 128Mi
+func formatMemory(bytes int64) string {
+	return fmt.Sprintf("%dMi", bytes/(1024*1024))
+}
+
+// formatPercent formats a percentage, <unknown> when it is unknown
+func formatPercent(p int) string {
+	if p < 0 {
+		return "<unknown>"
+	}
+	return fmt.Sprintf("%d%%", p)
+}
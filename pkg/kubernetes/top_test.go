@@ -0,0 +1,110 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+)
+
+func TestPodMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/metrics.k8s.io/v1beta1/namespaces/shop/pods" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodMetricsList","apiVersion":"metrics.k8s.io/v1beta1","items":[
+			{"metadata":{"name":"web-1","namespace":"shop"},"window":"30s","containers":[
+				{"name":"web","usage":{"cpu":"250m","memory":"64Mi"}},
+				{"name":"proxy","usage":{"cpu":"1500000n","memory":"16Mi"}}]}]}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := client.PodMetrics(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("PodMetrics() error = %v", err)
+	}
+	if len(metrics) != 1 || metrics[0].Name != "web-1" || len(metrics[0].Containers) != 2 {
+		t.Fatalf("Unexpected pod metrics: %+v", metrics)
+	}
+	if cpu, memory := podMetricsUsage(metrics[0]); cpu != 252 || memory != 80*1024*1024 {
+		t.Errorf("Expected 252m and 80Mi, got %dm and %d bytes", cpu, memory)
+	}
+
+	// Without metrics-server the API isn't served
+	if _, err := client.NodeMetrics(context.Background()); err == nil {
+		t.Error("Expected an error without the metrics API")
+	}
+}
+
+func TestTopJoins(t *testing.T) {
+	podMetrics := func(name, cpu, memory string) PodMetrics {
+		return PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			Containers: []ContainerMetrics{{Name: "main", Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}}},
+		}
+	}
+	pod := func(name, app string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{"app": app}},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+		}
+	}
+	metrics := []PodMetrics{
+		podMetrics("web-1", "100m", "100Mi"),
+		podMetrics("web-2", "300m", "50Mi"),
+		podMetrics("api-1", "200m", "200Mi"),
+		// A pod deleted since metrics-server last scraped it
+		podMetrics("web-0", "900m", "900Mi"),
+	}
+	pods := []*corev1.Pod{pod("web-1", "web"), pod("web-2", "web"), pod("api-1", "api"), pod("api-2", "api")}
+
+	usage := TopPods(metrics, pods, labels.SelectorFromSet(labels.Set{"app": "web"}))
+	if len(usage) != 2 || usage[0].Name != "web-1" || usage[1].CPU != 300 || usage[1].Node != "node-1" {
+		t.Errorf("Expected the cached web pods, got %+v", usage)
+	}
+
+	deployments := []*appsv1.Deployment{NewDeployment("shop", "web", "nginx", 2), NewDeployment("shop", "api", "api", 2)}
+	byDeployment, err := TopDeployments(metrics, deployments, pods, labels.Everything())
+	if err != nil {
+		t.Fatalf("TopDeployments() error = %v", err)
+	}
+	if len(byDeployment) != 2 || byDeployment[0].Name != "api" || byDeployment[0].Pods != 1 || byDeployment[1].CPU != 400 || byDeployment[1].Memory != 150*1024*1024 {
+		t.Errorf("Expected the usage of each deployment summed over its pods, got %+v", byDeployment)
+	}
+	if err := SortTopUsage(byDeployment, TopSortCPU); err != nil || byDeployment[0].Name != "web" {
+		t.Errorf("Expected web to use the most cpu, got %+v, %v", byDeployment, err)
+	}
+	if err := SortTopUsage(byDeployment, "disk"); err == nil {
+		t.Error("Expected an error for an unsupported sort order")
+	}
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	node.Status.Allocatable = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+	nodeMetrics := []NodeMetrics{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("256Mi")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")}},
+	}
+	nodes := TopNodes(nodeMetrics, []*corev1.Node{node})
+	if len(nodes) != 2 || nodes[0].CPUPercent != 25 || nodes[0].MemoryPercent != 25 || nodes[1].CPUPercent != -1 {
+		t.Errorf("Expected percentages of the allocatable resources of cached nodes, got %+v", nodes)
+	}
+}
@@ -0,0 +1,85 @@
+package kubernetes
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+)
+
+// lastAppliedAnnotation is written by kubectl apply and can be as large as the object itself
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// TransformFor returns the cache transform for a configured transform mode,
+// or nil when objects should be cached unchanged
+func TransformFor(mode string) cache.TransformFunc {
+	switch mode {
+	case "strip":
+		return stripDeployment
+	case "metadata":
+		return metadataDeployment
+	default:
+		return nil
+	}
+}
+
+// stripManagedFields drops the managed fields of any object, for caches of
+// pods and replica sets that are only read for their spec and status
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
+
+// stripDeployment drops managed fields and the last-applied annotation
+func stripDeployment(obj interface{}) (interface{}, error) {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return obj, nil
+	}
+
+	deployment.ManagedFields = nil
+	if _, exists := deployment.Annotations[lastAppliedAnnotation]; exists {
+		annotations := make(map[string]string, len(deployment.Annotations)-1)
+		for k, v := range deployment.Annotations {
+			if k != lastAppliedAnnotation {
+				annotations[k] = v
+			}
+		}
+		deployment.Annotations = annotations
+	}
+
+	return deployment, nil
+}
+
+// metadataDeployment keeps metadata, replica settings, container images and status,
+// which is everything the API and change analyzer need for day-to-day views
+func metadataDeployment(obj interface{}) (interface{}, error) {
+	stripped, err := stripDeployment(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, ok := stripped.(*appsv1.Deployment)
+	if !ok {
+		return stripped, nil
+	}
+
+	containers := make([]corev1.Container, 0, len(deployment.Spec.Template.Spec.Containers))
+	for _, c := range deployment.Spec.Template.Spec.Containers {
+		containers = append(containers, corev1.Container{
+			Name:  c.Name,
+			Image: c.Image,
+		})
+	}
+
+	deployment.Spec.Template = corev1.PodTemplateSpec{
+		ObjectMeta: deployment.Spec.Template.ObjectMeta,
+		Spec: corev1.PodSpec{
+			Containers: containers,
+		},
+	}
+
+	return deployment, nil
+}
@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTransformFor(t *testing.T) {
+	newDeployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "default",
+				Annotations: map[string]string{
+					lastAppliedAnnotation: `{"big":"blob"}`,
+					"team":                "payments",
+				},
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(3),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:  "web",
+							Image: "nginx:1.25",
+							Env:   []corev1.EnvVar{{Name: "MODE", Value: "prod"}},
+						}},
+						Volumes: []corev1.Volume{{Name: "data"}},
+					},
+				},
+			},
+		}
+	}
+
+	if TransformFor("") != nil || TransformFor("none") != nil {
+		t.Error("Expected no transform for empty and none modes")
+	}
+
+	obj, err := TransformFor("strip")(newDeployment())
+	if err != nil {
+		t.Fatalf("strip transform error = %v", err)
+	}
+	stripped := obj.(*appsv1.Deployment)
+	if stripped.ManagedFields != nil {
+		t.Error("Expected managed fields to be dropped")
+	}
+	if _, exists := stripped.Annotations[lastAppliedAnnotation]; exists {
+		t.Error("Expected last-applied annotation to be dropped")
+	}
+	if stripped.Annotations["team"] != "payments" {
+		t.Error("Expected other annotations to be kept")
+	}
+	if len(stripped.Spec.Template.Spec.Containers[0].Env) != 1 {
+		t.Error("Expected strip transform to keep the pod template")
+	}
+
+	obj, err = TransformFor("metadata")(newDeployment())
+	if err != nil {
+		t.Fatalf("metadata transform error = %v", err)
+	}
+	reduced := obj.(*appsv1.Deployment)
+	container := reduced.Spec.Template.Spec.Containers[0]
+	if container.Image != "nginx:1.25" || container.Name != "web" {
+		t.Errorf("Expected container name and image to be kept, got %s %s", container.Name, container.Image)
+	}
+	if len(container.Env) != 0 || len(reduced.Spec.Template.Spec.Volumes) != 0 {
+		t.Error("Expected metadata transform to drop env and volumes")
+	}
+	if *reduced.Spec.Replicas != 3 {
+		t.Errorf("Expected replicas to be kept, got %d", *reduced.Spec.Replicas)
+	}
+}
@@ -0,0 +1,128 @@
+// Package lifecycle coordinates the ordered shutdown of the components of a
+// long-running command
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+)
+
+// DefaultShutdownTimeout bounds a shutdown when no timeout is configured
+const DefaultShutdownTimeout = 30 * time.Second
+
+// lateHookGrace is how long a hook running after the shutdown timeout gets to
+// return, enough for hooks that stop a component at once
+const lateHookGrace = time.Second
+
+// Hook stops a component, returning once it stopped or ctx is done
+type Hook func(ctx context.Context) error
+
+// namedHook is a hook with the component name shutdown logs use
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Coordinator runs shutdown hooks one after the other, in the reverse order
+// they were added like deferred calls, within a shared timeout
+type Coordinator struct {
+	timeout time.Duration
+
+	mu    sync.Mutex
+	hooks []namedHook
+	done  bool
+}
+
+// NewCoordinator creates a coordinator giving the whole shutdown timeout
+// (DefaultShutdownTimeout when 0)
+func NewCoordinator(timeout time.Duration) *Coordinator {
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	return &Coordinator{timeout: timeout}
+}
+
+// OnShutdown adds the hook stopping a component. A component added after the
+// components it uses stops before them, so request serving, added last,
+// stops first.
+func (c *Coordinator) OnShutdown(name string, hook Hook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, namedHook{name, hook})
+}
+
+// Stop adapts a stop function that doesn't wait to a hook
+func Stop(stop func()) Hook {
+	return func(context.Context) error {
+		stop()
+		return nil
+	}
+}
+
+// Shutdown runs the hooks once. A failing hook doesn't stop the next ones. A
+// hook still running at the timeout is abandoned, and the remaining hooks run
+// with a done context and a short grace, so that components that stop at once
+// still do.
+func (c *Coordinator) Shutdown() error {
+	c.mu.Lock()
+	if c.done {
+		c.mu.Unlock()
+		return nil
+	}
+	c.done = true
+	hooks := c.hooks
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		if err := run(ctx, h); err != nil {
+			logger.Warn("Failed to stop component", map[string]interface{}{
+				"component": h.name,
+				"error":     err.Error(),
+			})
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		logger.Debug("Component stopped", map[string]interface{}{
+			"component": h.name,
+		})
+	}
+
+	logger.Info("Shutdown complete", map[string]interface{}{
+		"duration_ms": time.Since(start).Milliseconds(),
+		"failures":    len(errs),
+	})
+	return errors.Join(errs...)
+}
+
+// run runs a hook until it returns or ctx is done, or for a hook starting
+// after ctx is done, for lateHookGrace
+func run(ctx context.Context, h namedHook) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- h.hook(ctx)
+	}()
+
+	wait := ctx
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		wait, cancel = context.WithTimeout(context.Background(), lateHookGrace)
+		defer cancel()
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-wait.Done():
+		return fmt.Errorf("not stopped within the shutdown timeout: %w", context.DeadlineExceeded)
+	}
+}
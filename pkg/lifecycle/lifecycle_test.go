@@ -0,0 +1,60 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCoordinatorShutdown(t *testing.T) {
+	c := NewCoordinator(time.Second)
+
+	var stopped []string
+	c.OnShutdown("informer", Stop(func() { stopped = append(stopped, "informer") }))
+	c.OnShutdown("workers", func(context.Context) error {
+		stopped = append(stopped, "workers")
+		return errors.New("worker busy")
+	})
+	c.OnShutdown("http server", Stop(func() { stopped = append(stopped, "http server") }))
+
+	err := c.Shutdown()
+	if want := []string{"http server", "workers", "informer"}; !reflect.DeepEqual(stopped, want) {
+		t.Errorf("Expected components to stop in reverse order %v, got %v", want, stopped)
+	}
+	if err == nil || !strings.Contains(err.Error(), "workers: worker busy") {
+		t.Errorf("Expected the failed component in the error, got %v", err)
+	}
+
+	// Shutdown runs once
+	if err := c.Shutdown(); err != nil || len(stopped) != 3 {
+		t.Errorf("Expected a second shutdown to do nothing, got %v and %v", err, stopped)
+	}
+}
+
+func TestCoordinatorShutdownTimeout(t *testing.T) {
+	c := NewCoordinator(50 * time.Millisecond)
+
+	informerStopped := false
+	c.OnShutdown("informer", Stop(func() { informerStopped = true }))
+	release := make(chan struct{})
+	defer close(release)
+	c.OnShutdown("http server", func(context.Context) error {
+		<-release
+		return nil
+	})
+
+	start := time.Now()
+	err := c.Shutdown()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the stuck component to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected shutdown to give up at the timeout, took %v", elapsed)
+	}
+	if !informerStopped {
+		t.Error("Expected components after the stuck one to be stopped")
+	}
+}
@@ -0,0 +1,247 @@
+// Package lint checks deployments for missing resource requests and limits,
+// probes, replicas and PodDisruptionBudgets.
+package lint
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+)
+
+// IgnoreAnnotation lists, comma-separated, the rules not checked on a
+// deployment
+const IgnoreAnnotation = "k6s.io/lint-ignore"
+
+// Severities of findings
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Rules deployments are checked against
+const (
+	RuleMissingRequests       = "missing-requests"
+	RuleMissingLimits         = "missing-limits"
+	RuleLimitBelowRequest     = "limit-below-request"
+	RuleMissingReadinessProbe = "missing-readiness-probe"
+	RuleMissingLivenessProbe  = "missing-liveness-probe"
+	RuleSingleReplica         = "single-replica"
+	RuleMissingPDB            = "missing-pdb"
+)
+
+// Rules are the rules in the order they are checked, with their severity
+var Rules = []struct {
+	Name     string
+	Severity string
+}{
+	{RuleMissingRequests, SeverityWarning},
+	{RuleMissingLimits, SeverityWarning},
+	{RuleLimitBelowRequest, SeverityError},
+	{RuleMissingReadinessProbe, SeverityWarning},
+	{RuleMissingLivenessProbe, SeverityWarning},
+	{RuleSingleReplica, SeverityError},
+	{RuleMissingPDB, SeverityWarning},
+}
+
+// Finding is a rule a deployment, or one of its containers, breaks
+type Finding struct {
+	Namespace  string `json:"namespace"`
+	Deployment string `json:"deployment"`
+	Container  string `json:"container,omitempty"`
+	Rule       string `json:"rule"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+}
+
+// Report is the result of linting deployments, with findings sorted by
+// deployment, container and rule
+type Report struct {
+	Deployments int       `json:"deployments"`
+	Passed      int       `json:"passed"`
+	Errors      int       `json:"errors"`
+	Warnings    int       `json:"warnings"`
+	Findings    []Finding `json:"findings"`
+}
+
+// Options configures the rules checked
+type Options struct {
+	// Namespaces, or path.Match patterns such as prod-*, whose deployments
+	// must run more than one replica
+	ProductionNamespaces []string
+
+	// Rules that are not checked
+	DisabledRules []string
+}
+
+// ValidateRules returns an error naming the first unknown rule of names
+func ValidateRules(names []string) error {
+	for _, name := range names {
+		if severity(name) == "" {
+			known := make([]string, 0, len(Rules))
+			for _, rule := range Rules {
+				known = append(known, rule.Name)
+			}
+			return fmt.Errorf("unknown lint rule %q (use %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// severity returns the severity of a rule, empty for an unknown rule
+func severity(rule string) string {
+	for _, r := range Rules {
+		if r.Name == rule {
+			return r.Severity
+		}
+	}
+	return ""
+}
+
+// ruleOrder returns the position of a rule in Rules
+func ruleOrder(rule string) int {
+	for i, r := range Rules {
+		if r.Name == rule {
+			return i
+		}
+	}
+	return len(Rules)
+}
+
+// Lint checks deployments against the enabled rules, looking up the
+// PodDisruptionBudgets protecting them in pdbs
+func Lint(deployments []*appsv1.Deployment, pdbs []*policyv1.PodDisruptionBudget, opts Options) Report {
+	report := Report{Findings: []Finding{}}
+	for _, dep := range deployments {
+		findings := LintDeployment(dep, pdbs, opts)
+		report.Deployments++
+		if len(findings) == 0 {
+			report.Passed++
+		}
+		for _, finding := range findings {
+			if finding.Severity == SeverityError {
+				report.Errors++
+			} else {
+				report.Warnings++
+			}
+		}
+		report.Findings = append(report.Findings, findings...)
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Deployment != b.Deployment {
+			return a.Deployment < b.Deployment
+		}
+		if a.Container != b.Container {
+			return a.Container < b.Container
+		}
+		return ruleOrder(a.Rule) < ruleOrder(b.Rule)
+	})
+	return report
+}
+
+// LintDeployment returns the findings of a deployment, leaving out disabled
+// rules and those of its IgnoreAnnotation
+func LintDeployment(dep *appsv1.Deployment, pdbs []*policyv1.PodDisruptionBudget, opts Options) []Finding {
+	skipped := make(map[string]bool)
+	for _, rule := range opts.DisabledRules {
+		skipped[rule] = true
+	}
+	for _, rule := range strings.Split(dep.Annotations[IgnoreAnnotation], ",") {
+		skipped[strings.TrimSpace(rule)] = true
+	}
+
+	var findings []Finding
+	add := func(container, rule, message string) {
+		if !skipped[rule] {
+			findings = append(findings, Finding{
+				Namespace:  dep.Namespace,
+				Deployment: dep.Name,
+				Container:  container,
+				Rule:       rule,
+				Severity:   severity(rule),
+				Message:    message,
+			})
+		}
+	}
+
+	for _, container := range dep.Spec.Template.Spec.Containers {
+		lintResources(container, add)
+		if container.ReadinessProbe == nil {
+			add(container.Name, RuleMissingReadinessProbe, "no readiness probe")
+		}
+		if container.LivenessProbe == nil {
+			add(container.Name, RuleMissingLivenessProbe, "no liveness probe")
+		}
+	}
+
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	production := isProduction(dep.Namespace, opts.ProductionNamespaces)
+	if production && replicas == 1 {
+		add("", RuleSingleReplica, "runs a single replica in a production namespace")
+	}
+	// A budget only matters for deployments that must stay available
+	if replicas > 1 || (production && replicas > 0) {
+		if !protected(dep, pdbs) {
+			add("", RuleMissingPDB, "no PodDisruptionBudget selects its pods")
+		}
+	}
+	return findings
+}
+
+// lintResources adds the findings of the cpu and memory requests and limits
+// of a container
+func lintResources(container corev1.Container, add func(container, rule, message string)) {
+	var noRequests, noLimits []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		request, hasRequest := container.Resources.Requests[name]
+		limit, hasLimit := container.Resources.Limits[name]
+		// A limit without a request is also its request
+		if !hasRequest && !hasLimit {
+			noRequests = append(noRequests, string(name))
+		}
+		if !hasLimit {
+			noLimits = append(noLimits, string(name))
+		}
+		if hasRequest && hasLimit && limit.Cmp(request) < 0 {
+			add(container.Name, RuleLimitBelowRequest, fmt.Sprintf("%s limit %s is below its request %s", name, limit.String(), request.String()))
+		}
+	}
+	if len(noRequests) > 0 {
+		add(container.Name, RuleMissingRequests, "no "+strings.Join(noRequests, " or ")+" request")
+	}
+	if len(noLimits) > 0 {
+		add(container.Name, RuleMissingLimits, "no "+strings.Join(noLimits, " or ")+" limit")
+	}
+}
+
+// isProduction reports whether a namespace matches one of patterns
+func isProduction(namespace string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// protected reports whether one of pdbs selects the pods of a deployment
+func protected(dep *appsv1.Deployment, pdbs []*policyv1.PodDisruptionBudget) bool {
+	for _, pdb := range pdbs {
+		if pdb.Namespace == dep.Namespace && kubernetes.PDBSelects(pdb, dep.Spec.Template.Labels) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package lint
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newDeployment returns a deployment of app=name whose container sets every
+// request, limit and probe
+func newDeployment(namespace, name string, replicas int32) *appsv1.Deployment {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+		corev1.ResourceMemory: resource.MustParse("128Mi"),
+	}
+	probe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz"}}}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:           name,
+					Resources:      corev1.ResourceRequirements{Requests: resources, Limits: resources.DeepCopy()},
+					ReadinessProbe: probe,
+					LivenessProbe:  probe,
+				}}},
+			},
+		},
+	}
+}
+
+// newPDB returns a PodDisruptionBudget selecting app=app
+func newPDB(namespace, app string) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: app},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+		},
+	}
+}
+
+// rules returns the rules of findings
+func rules(findings []Finding) []string {
+	names := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		names = append(names, finding.Rule)
+	}
+	return names
+}
+
+func TestLintDeployment(t *testing.T) {
+	opts := Options{ProductionNamespaces: []string{"prod-*"}}
+	pdbs := []*policyv1.PodDisruptionBudget{newPDB("shop", "web"), newPDB("prod-eu", "api")}
+
+	if findings := LintDeployment(newDeployment("shop", "web", 3), pdbs, opts); len(findings) != 0 {
+		t.Errorf("Expected no findings, got %+v", findings)
+	}
+	if findings := LintDeployment(newDeployment("shop", "worker", 1), pdbs, opts); len(findings) != 0 {
+		t.Errorf("Expected no budget required of a single replica outside production, got %+v", findings)
+	}
+
+	findings := LintDeployment(newDeployment("prod-eu", "worker", 1), pdbs, opts)
+	if got := rules(findings); len(got) != 2 || got[0] != RuleSingleReplica || got[1] != RuleMissingPDB {
+		t.Errorf("Expected single-replica and missing-pdb in production, got %v", got)
+	}
+	if findings[0].Severity != SeverityError || findings[0].Container != "" {
+		t.Errorf("Expected a deployment-level error, got %+v", findings[0])
+	}
+
+	dep := newDeployment("shop", "web", 3)
+	container := &dep.Spec.Template.Spec.Containers[0]
+	container.Resources.Requests = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}
+	container.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")}
+	container.LivenessProbe = nil
+	findings = LintDeployment(dep, pdbs, opts)
+	got := rules(Lint([]*appsv1.Deployment{dep}, pdbs, opts).Findings)
+	want := []string{RuleMissingRequests, RuleMissingLimits, RuleLimitBelowRequest, RuleMissingLivenessProbe}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+	for _, finding := range findings {
+		if finding.Rule == RuleMissingRequests && finding.Message != "no cpu request" {
+			t.Errorf("Expected only the cpu request missing, got %q", finding.Message)
+		}
+	}
+
+	dep.Annotations = map[string]string{IgnoreAnnotation: "missing-limits, missing-liveness-probe"}
+	opts.DisabledRules = []string{RuleMissingRequests}
+	if got := rules(LintDeployment(dep, pdbs, opts)); len(got) != 1 || got[0] != RuleLimitBelowRequest {
+		t.Errorf("Expected ignored and disabled rules skipped, got %v", got)
+	}
+}
+
+func TestLint(t *testing.T) {
+	web := newDeployment("shop", "web", 2)
+	api := newDeployment("prod", "api", 1)
+	report := Lint([]*appsv1.Deployment{web, api}, nil, Options{ProductionNamespaces: []string{"prod"}})
+	if report.Deployments != 2 || report.Passed != 0 || report.Errors != 1 || report.Warnings != 2 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if len(report.Findings) != 3 || report.Findings[0].Namespace != "prod" {
+		t.Errorf("Expected findings sorted by namespace, got %+v", report.Findings)
+	}
+
+	if err := ValidateRules([]string{RuleMissingPDB, "no-such-rule"}); err == nil {
+		t.Error("Expected an error for an unknown rule")
+	}
+}
@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// levels are the log levels in effect: the level of the whole process and
+// overrides for the loggers of some components
+type levels struct {
+	base       zerolog.Level
+	components map[string]zerolog.Level
+}
+
+var (
+	// levelsMu serializes level changes
+	levelsMu sync.Mutex
+
+	// activeLevels are read by levelHook for every entry
+	activeLevels atomic.Pointer[levels]
+)
+
+// componentKey is the context key of the component of a logger, read by
+// levelHook
+type componentKey struct{}
+
+// ParseLevel converts a level name, such as debug, to a zerolog level
+func ParseLevel(level string) (zerolog.Level, error) {
+	switch strings.ToLower(level) {
+	case "trace":
+		return zerolog.TraceLevel, nil
+	case "debug":
+		return zerolog.DebugLevel, nil
+	case "info":
+		return zerolog.InfoLevel, nil
+	case "warn", "warning":
+		return zerolog.WarnLevel, nil
+	case "error":
+		return zerolog.ErrorLevel, nil
+	case "fatal":
+		return zerolog.FatalLevel, nil
+	case "panic":
+		return zerolog.PanicLevel, nil
+	default:
+		return zerolog.NoLevel, fmt.Errorf("unknown log level %q (use trace, debug, info, warn, error, fatal or panic)", level)
+	}
+}
+
+// Level returns the log level of the process, such as info
+func Level() string {
+	return currentLevels().base.String()
+}
+
+// ComponentLevels returns the log level overrides of components
+func ComponentLevels() map[string]string {
+	current := currentLevels()
+	out := make(map[string]string, len(current.components))
+	for component, level := range current.components {
+		out[component] = level.String()
+	}
+	return out
+}
+
+// SetComponentLevel overrides the log level of the loggers of a component,
+// such as informer, more or less verbose than the process level. An empty
+// level removes the override.
+func SetComponentLevel(component, level string) error {
+	if component == "" {
+		return fmt.Errorf("component is required")
+	}
+	var lvl zerolog.Level
+	if level != "" {
+		var err error
+		if lvl, err = ParseLevel(level); err != nil {
+			return err
+		}
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	current := currentLevels()
+	components := make(map[string]zerolog.Level, len(current.components)+1)
+	for c, l := range current.components {
+		components[c] = l
+	}
+	if level == "" {
+		delete(components, component)
+	} else {
+		components[component] = lvl
+	}
+	applyLevels(&levels{base: current.base, components: components})
+	return nil
+}
+
+// SetComponentLevels replaces the component level overrides with overrides,
+// keyed by component. Nothing changes when a level is invalid.
+func SetComponentLevels(overrides map[string]string) error {
+	components := make(map[string]zerolog.Level, len(overrides))
+	for component, level := range overrides {
+		if component == "" {
+			return fmt.Errorf("component is required")
+		}
+		lvl, err := ParseLevel(level)
+		if err != nil {
+			return fmt.Errorf("component %s: %w", component, err)
+		}
+		components[component] = lvl
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	applyLevels(&levels{base: currentLevels().base, components: components})
+	return nil
+}
+
+// ResetComponentLevels removes every component level override
+func ResetComponentLevels() {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	applyLevels(&levels{base: currentLevels().base})
+}
+
+// setBaseLevel sets the log level of the process, keeping component
+// overrides
+func setBaseLevel(level zerolog.Level) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	applyLevels(&levels{base: level, components: currentLevels().components})
+}
+
+// currentLevels returns the levels in effect
+func currentLevels() *levels {
+	if current := activeLevels.Load(); current != nil {
+		return current
+	}
+	return &levels{base: zerolog.GlobalLevel()}
+}
+
+// applyLevels puts levels into effect. The zerolog global level is the most
+// verbose of them, and levelHook drops the entries of loggers below their own
+// level.
+func applyLevels(l *levels) {
+	global := l.base
+	for _, level := range l.components {
+		if level < global {
+			global = level
+		}
+	}
+	activeLevels.Store(l)
+	zerolog.SetGlobalLevel(global)
+}
+
+// withComponent returns ctx carrying the component of a logger
+func withComponent(ctx context.Context, component string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if component == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, componentKey{}, component)
+}
+
+// levelEnabled reports whether entries of level are logged by the loggers of
+// component ("" for loggers without one)
+func levelEnabled(component string, level zerolog.Level) bool {
+	current := currentLevels()
+	if min, ok := current.components[component]; ok {
+		return level >= min
+	}
+	return level >= current.base
+}
+
+// levelHook drops entries below the level of their logger's component, or
+// the process level. Without component overrides the zerolog global level
+// already filters them.
+type levelHook struct{}
+
+// Run implements zerolog.Hook
+func (levelHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	current := activeLevels.Load()
+	if current == nil || len(current.components) == 0 || level == zerolog.NoLevel {
+		return
+	}
+	component, _ := e.GetCtx().Value(componentKey{}).(string)
+	if !levelEnabled(component, level) {
+		e.Discard()
+	}
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestComponentLevels(t *testing.T) {
+	New()
+	previous, level := log.Logger, Level()
+	defer func() {
+		log.Logger = previous
+		ResetComponentLevels()
+		SetLevel(level)
+	}()
+
+	var buf bytes.Buffer
+	log.Logger = zerolog.New(&buf).Hook(levelHook{})
+	SetLevel("info")
+	informer := New().WithComponent("informer").WithNamespace("shop")
+	server := New().WithComponent("server")
+
+	if err := SetComponentLevel("informer", "debug"); err != nil {
+		t.Fatalf("SetComponentLevel() error = %v", err)
+	}
+	if err := SetComponentLevel("server", "warn"); err != nil {
+		t.Fatalf("SetComponentLevel() error = %v", err)
+	}
+	if err := SetComponentLevel("server", "loud"); err == nil {
+		t.Error("Expected an unknown level to be rejected")
+	}
+
+	informer.Debug("informer debug", nil)
+	server.Info("server info", nil)
+	New().Debug("process debug", nil)
+	New().Info("process info", nil)
+	log.Debug().Msg("direct debug")
+
+	out := buf.String()
+	for _, want := range []string{"informer debug", "process info"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q to be logged, got %s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"server info", "process debug", "direct debug"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Expected %q to be dropped, got %s", unwanted, out)
+		}
+	}
+	if !informer.GetLogr().V(1).Enabled() || server.GetLogr().Enabled() {
+		t.Error("Expected logr loggers to follow their component level")
+	}
+
+	// Removing overrides restores the process level
+	if err := SetComponentLevel("informer", ""); err != nil {
+		t.Fatalf("SetComponentLevel() error = %v", err)
+	}
+	if levels := ComponentLevels(); len(levels) != 1 || levels["server"] != "warn" {
+		t.Errorf("Expected only the server override, got %v", levels)
+	}
+	ResetComponentLevels()
+	if zerolog.GlobalLevel() != zerolog.InfoLevel || len(ComponentLevels()) != 0 {
+		t.Errorf("Expected the process level only, got %v and %v", zerolog.GlobalLevel(), ComponentLevels())
+	}
+}
+
+func TestSetComponentLevels(t *testing.T) {
+	level := Level()
+	defer func() {
+		ResetComponentLevels()
+		SetLevel(level)
+	}()
+	SetLevel("info")
+
+	if err := SetComponentLevels(map[string]string{ComponentInformer: "debug", ComponentServer: "warn"}); err != nil {
+		t.Fatalf("SetComponentLevels() error = %v", err)
+	}
+	if err := SetComponentLevels(map[string]string{ComponentReconciler: "loud"}); err == nil {
+		t.Error("Expected an unknown level to be rejected")
+	}
+	levels := ComponentLevels()
+	if len(levels) != 2 || levels[ComponentInformer] != "debug" || levels[ComponentServer] != "warn" {
+		t.Errorf("Expected the previous levels to stay after a rejected change, got %v", levels)
+	}
+	if zerolog.GlobalLevel() != zerolog.DebugLevel {
+		t.Errorf("Expected the global level to follow the most verbose component, got %v", zerolog.GlobalLevel())
+	}
+
+	if ForComponent(ComponentInformer) != ForComponent(ComponentInformer) {
+		t.Error("Expected component loggers to be reused")
+	}
+	if !ForComponent(ComponentInformer).GetLogr().V(1).Enabled() || ForComponent(ComponentServer).GetLogr().Enabled() {
+		t.Error("Expected component loggers to follow their level")
+	}
+}
+
+func TestComponentSampling(t *testing.T) {
+	New()
+	previous := global
+	defer func() {
+		global = previous
+		SetComponentSampling(ComponentInformer, 0, 0, 0)
+	}()
+
+	var buf bytes.Buffer
+	global = &Logger{logger: zerolog.New(&buf)}
+	SetComponentSampling(ComponentInformer, 2, time.Hour, 0)
+	for i := 0; i < 5; i++ {
+		ForComponent(ComponentInformer).Info("Deployment updated", nil)
+	}
+	ForComponent(ComponentInformer).Warn("Watch restarted", nil)
+
+	if n := strings.Count(buf.String(), "Deployment updated"); n != 2 {
+		t.Errorf("Expected a burst of 2 entries, got %d", n)
+	}
+	if !strings.Contains(buf.String(), "Watch restarted") {
+		t.Error("Expected warnings not to be sampled")
+	}
+
+	buf.Reset()
+	SetComponentSampling(ComponentInformer, 0, 0, 0)
+	for i := 0; i < 5; i++ {
+		ForComponent(ComponentInformer).Info("Deployment updated", nil)
+	}
+	if n := strings.Count(buf.String(), "Deployment updated"); n != 5 {
+		t.Errorf("Expected every entry once sampling stopped, got %d", n)
+	}
+}
@@ -3,10 +3,11 @@ package logger
 import (
 	"context"
 	"os"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -14,11 +15,34 @@ import (
 // Logger represents a structured logger with context
 type Logger struct {
 	logger zerolog.Logger
+
+	// Component of the logger, whose level may be overridden
+	component string
+
+	// Context of the logger's entries, carrying its component
+	ctx context.Context
 }
 
 // Global logger instance
 var global *Logger
 
+// Components whose log levels are usually configured. Components are named
+// by WithComponent; these are the ones of the informers, the HTTP API and the
+// reconcilers.
+const (
+	ComponentInformer   = "informer"
+	ComponentServer     = "server"
+	ComponentReconciler = "reconciler"
+)
+
+// componentLoggers caches the loggers returned by ForComponent until the
+// next Init
+var componentLoggers sync.Map
+
+// componentSamplers sample the entries of the loggers returned by
+// ForComponent, keyed by component
+var componentSamplers sync.Map
+
 // Config holds logger configuration
 type Config struct {
 	Level       string `yaml:"level" env:"LOG_LEVEL" default:"info"`
@@ -30,6 +54,7 @@ type Config struct {
 // Init initializes the global logger with environment-aware configuration
 func Init(config Config) {
 	configureLogger(config)
+	componentLoggers.Clear()
 	global = &Logger{
 		logger: log.Logger,
 	}
@@ -52,44 +77,48 @@ func New() *Logger {
 
 // WithContext returns a logger with context values
 func (l *Logger) WithContext(ctx context.Context) *Logger {
+	ctx = withComponent(ctx, l.component)
 	return &Logger{
-		logger: l.logger.With().Ctx(ctx).Logger(),
+		logger:    l.logger.With().Ctx(ctx).Logger(),
+		component: l.component,
+		ctx:       ctx,
 	}
 }
 
 // WithNamespace returns a logger with namespace context
 func (l *Logger) WithNamespace(namespace string) *Logger {
-	return &Logger{
-		logger: l.logger.With().Str("namespace", namespace).Logger(),
-	}
+	return l.derive(l.logger.With().Str("namespace", namespace).Logger())
 }
 
 // WithDeployment returns a logger with deployment context
 func (l *Logger) WithDeployment(deployment string) *Logger {
-	return &Logger{
-		logger: l.logger.With().Str("deployment", deployment).Logger(),
-	}
+	return l.derive(l.logger.With().Str("deployment", deployment).Logger())
 }
 
 // WithCluster returns a logger with cluster context
 func (l *Logger) WithCluster(cluster string) *Logger {
-	return &Logger{
-		logger: l.logger.With().Str("cluster", cluster).Logger(),
-	}
+	return l.derive(l.logger.With().Str("cluster", cluster).Logger())
 }
 
 // WithComponent returns a logger with component context
 func (l *Logger) WithComponent(component string) *Logger {
+	ctx := withComponent(l.ctx, component)
 	return &Logger{
-		logger: l.logger.With().Str("component", component).Logger(),
+		logger:    l.logger.With().Str("component", component).Ctx(ctx).Logger(),
+		component: component,
+		ctx:       ctx,
 	}
 }
 
+// Zerolog returns the underlying zerolog logger, for code using zerolog's
+// API. Its entries follow the level of the logger's component.
+func (l *Logger) Zerolog() *zerolog.Logger {
+	return &l.logger
+}
+
 // WithField returns a logger with a single field
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	return &Logger{
-		logger: l.logger.With().Interface(key, value).Logger(),
-	}
+	return l.derive(l.logger.With().Interface(key, value).Logger())
 }
 
 // WithFields returns a logger with multiple fields
@@ -98,16 +127,18 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	for k, v := range fields {
 		event = event.Interface(k, v)
 	}
-	return &Logger{
-		logger: event.Logger(),
-	}
+	return l.derive(event.Logger())
+}
+
+// derive returns a logger writing to logger, of the same component
+func (l *Logger) derive(logger zerolog.Logger) *Logger {
+	return &Logger{logger: logger, component: l.component, ctx: l.ctx}
 }
 
-// GetLogr returns a basic logr.Logger interface (placeholder for future integration)
+// GetLogr returns a logr.Logger writing to the logger, for controller-runtime
+// and the reconcilers
 func (l *Logger) GetLogr() logr.Logger {
-	// For now, return a no-op logger since we don't have zerologr
-	noop := &noopLogger{}
-	return logr.New(noop)
+	return logr.New(newLogSink(l.logger, l.component))
 }
 
 // Debug logs a debug message with optional fields
@@ -212,6 +243,44 @@ func WithComponent(component string) *Logger {
 	return global.WithComponent(component)
 }
 
+// ForComponent returns the logger of a component, like WithComponent, but
+// reuses it across calls, for code logging in hot paths
+func ForComponent(component string) *Logger {
+	if l, ok := componentLoggers.Load(component); ok {
+		return l.(*Logger)
+	}
+	created := WithComponent(component)
+	if sampler, ok := componentSamplers.Load(component); ok {
+		created = created.derive(created.logger.Sample(sampler.(zerolog.Sampler)))
+	}
+	l, _ := componentLoggers.LoadOrStore(component, created)
+	return l.(*Logger)
+}
+
+// SetComponentSampling samples the trace, debug and info entries of the
+// logger ForComponent returns for a component: burst entries are logged per
+// period (0 = 1s), then every thereafter-th one (0 = none). A zero burst
+// stops sampling. Warnings and errors are always logged.
+func SetComponentSampling(component string, burst uint32, period time.Duration, thereafter uint32) {
+	if burst == 0 {
+		componentSamplers.Delete(component)
+	} else {
+		if period <= 0 {
+			period = time.Second
+		}
+		sampler := &zerolog.BurstSampler{Burst: burst, Period: period}
+		if thereafter > 0 {
+			sampler.NextSampler = &zerolog.BasicSampler{N: thereafter}
+		}
+		componentSamplers.Store(component, zerolog.LevelSampler{
+			TraceSampler: sampler,
+			DebugSampler: sampler,
+			InfoSampler:  sampler,
+		})
+	}
+	componentLoggers.Delete(component)
+}
+
 // WithField returns a logger with a single field
 func WithField(key string, value interface{}) *Logger {
 	if global == nil {
@@ -276,33 +345,26 @@ func Trace(msg string, fields map[string]interface{}) {
 	global.Trace(msg, fields)
 }
 
-// parseLogLevel converts string to zerolog.Level
+// parseLogLevel converts string to zerolog.Level, info when unknown
 func parseLogLevel(level string) zerolog.Level {
-	switch strings.ToLower(level) {
-	case "trace":
-		return zerolog.TraceLevel
-	case "debug":
-		return zerolog.DebugLevel
-	case "info":
-		return zerolog.InfoLevel
-	case "warn", "warning":
-		return zerolog.WarnLevel
-	case "error":
-		return zerolog.ErrorLevel
-	case "fatal":
-		return zerolog.FatalLevel
-	case "panic":
-		return zerolog.PanicLevel
-	default:
+	lvl, err := ParseLevel(level)
+	if err != nil {
 		return zerolog.InfoLevel
 	}
+	return lvl
+}
+
+// SetLevel changes the global log level at runtime, keeping the levels of
+// components
+func SetLevel(level string) {
+	setBaseLevel(parseLogLevel(level))
 }
 
 // configureLogger configures the global logger with environment-specific settings
 func configureLogger(config Config) {
 	// Set global log level
 	level := parseLogLevel(config.Level)
-	zerolog.SetGlobalLevel(level)
+	setBaseLevel(level)
 	
 	// Set time format
 	zerolog.TimeFieldFormat = time.RFC3339Nano
@@ -324,7 +386,8 @@ func configureLogger(config Config) {
 		logger = zerolog.New(os.Stderr).With().
 			Timestamp().
 			Str("service", "k6s-controller").
-			Str("version", getVersion()).
+			Str("version", version.Version).
+			Str("commit", version.Get().Commit).
 			Logger()
 		
 		if isProd {
@@ -351,23 +414,7 @@ func configureLogger(config Config) {
 		logger = logger.With().Caller().Logger()
 	}
 	
-	log.Logger = logger
+	// Drop entries below the level of their component
+	log.Logger = logger.Hook(levelHook{})
 }
 
-// getVersion returns the application version from environment or default
-func getVersion() string {
-	if version := os.Getenv("K6S_VERSION"); version != "" {
-		return version
-	}
-	return "dev"
-}
-
-// noopLogger is a no-op implementation of logr.LogSink
-type noopLogger struct{}
-
-func (l *noopLogger) Init(info logr.RuntimeInfo) {}
-func (l *noopLogger) Enabled(level int) bool { return false }
-func (l *noopLogger) Info(level int, msg string, keysAndValues ...interface{}) {}
-func (l *noopLogger) Error(err error, msg string, keysAndValues ...interface{}) {}
-func (l *noopLogger) WithValues(keysAndValues ...interface{}) logr.LogSink { return l }
-func (l *noopLogger) WithName(name string) logr.LogSink { return l }
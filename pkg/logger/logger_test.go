@@ -2,10 +2,13 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestInit(t *testing.T) {
@@ -93,3 +96,71 @@ func TestLoggerWithFields(t *testing.T) {
 		t.Error("Expected fields logger to be created, got nil")
 	}
 }
+
+func TestSetLevel(t *testing.T) {
+	defer SetLevel(Level())
+
+	SetLevel("warn")
+	if zerolog.GlobalLevel() != zerolog.WarnLevel {
+		t.Errorf("Expected warn level, got %v", zerolog.GlobalLevel())
+	}
+	SetLevel("debug")
+	if zerolog.GlobalLevel() != zerolog.DebugLevel {
+		t.Errorf("Expected debug level, got %v", zerolog.GlobalLevel())
+	}
+}
+
+func TestGetLogr(t *testing.T) {
+	defer SetLevel(Level())
+	SetLevel("debug")
+
+	var buf bytes.Buffer
+	l := &Logger{logger: zerolog.New(&buf)}
+	rlog := l.GetLogr().WithName("controller").WithName("deployment").WithValues("cluster", "prod")
+
+	entries := func() []map[string]interface{} {
+		var out []map[string]interface{}
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var entry map[string]interface{}
+			if err := dec.Decode(&entry); err != nil {
+				t.Fatalf("Failed to decode log entry: %v", err)
+			}
+			out = append(out, entry)
+		}
+		return out
+	}
+
+	rlog.Info("Reconciled", "deployment", types.NamespacedName{Namespace: "shop", Name: "web"})
+	rlog.V(1).Info("Requeued")
+	rlog.V(2).Info("Skipped")
+	rlog.Error(errors.New("conflict"), "Failed to update")
+
+	got := entries()
+	if len(got) != 3 {
+		t.Fatalf("Expected info, debug and error entries, got %v", got)
+	}
+	want := []struct{ level, message string }{
+		{"info", "Reconciled"},
+		{"debug", "Requeued"},
+		{"error", "Failed to update"},
+	}
+	for i, w := range want {
+		if got[i]["level"] != w.level || got[i]["message"] != w.message {
+			t.Errorf("Expected %s entry %q, got %v", w.level, w.message, got[i])
+		}
+		if got[i]["logger"] != "controller.deployment" || got[i]["cluster"] != "prod" {
+			t.Errorf("Expected the logger name and values, got %v", got[i])
+		}
+	}
+	if got[0]["deployment"] != "shop/web" {
+		t.Errorf("Expected a namespaced name to be logged as a string, got %v", got[0]["deployment"])
+	}
+	if got[2]["error"] != "conflict" {
+		t.Errorf("Expected the error, got %v", got[2]["error"])
+	}
+
+	if !rlog.V(1).Enabled() || rlog.V(2).Enabled() {
+		t.Error("Expected V-levels to follow the global level")
+	}
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/rs/zerolog"
+)
+
+// logSink is a logr.LogSink writing to a zerolog logger, so controller-runtime,
+// its webhook server and the reconcilers log through the unified logger.
+// logr V-levels map to zerolog levels: V(0) is info, V(1) debug and V(2) and
+// above trace.
+type logSink struct {
+	logger zerolog.Logger
+
+	// Component of the logger, whose level may be overridden
+	component string
+
+	// Name of the logger, joined with dots, logged as "logger"
+	name string
+
+	// Frames between the caller of logr and the sink
+	depth int
+}
+
+// newLogSink returns a sink writing to the logger of a component
+func newLogSink(logger zerolog.Logger, component string) *logSink {
+	return &logSink{logger: logger, component: component}
+}
+
+// Init records the call depth of the logr.Logger wrapping the sink
+func (s *logSink) Init(info logr.RuntimeInfo) {
+	s.depth = info.CallDepth
+}
+
+// Enabled reports whether entries of a V-level are logged
+func (s *logSink) Enabled(level int) bool {
+	lvl := zerologLevel(level)
+	return lvl >= s.logger.GetLevel() && lvl >= zerolog.GlobalLevel() && levelEnabled(s.component, lvl)
+}
+
+// Info logs a non-error message at a V-level
+func (s *logSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.log(s.logger.WithLevel(zerologLevel(level)), msg, keysAndValues)
+}
+
+// Error logs an error message, which is logged at every V-level
+func (s *logSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	event := s.logger.Error()
+	if err != nil {
+		event = event.Err(err)
+	}
+	s.log(event, msg, keysAndValues)
+}
+
+// WithValues returns a sink adding key/value pairs to its entries
+func (s *logSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	clone := *s
+	clone.logger = s.logger.With().Fields(logValues(keysAndValues)).Logger()
+	return &clone
+}
+
+// WithName returns a sink with name appended to its name
+func (s *logSink) WithName(name string) logr.LogSink {
+	clone := *s
+	if clone.name == "" {
+		clone.name = name
+	} else {
+		clone.name += "." + name
+	}
+	return &clone
+}
+
+// WithCallDepth returns a sink reporting callers depth more frames up
+func (s *logSink) WithCallDepth(depth int) logr.LogSink {
+	clone := *s
+	clone.depth += depth
+	return &clone
+}
+
+// log writes an entry unless its level is disabled
+func (s *logSink) log(event *zerolog.Event, msg string, keysAndValues []interface{}) {
+	if event == nil {
+		return
+	}
+	if s.name != "" {
+		event = event.Str("logger", s.name)
+	}
+	// Skip Info or Error and the logr.Logger method calling it
+	event.CallerSkipFrame(1 + s.depth).Fields(logValues(keysAndValues)).Msg(msg)
+}
+
+// zerologLevel maps a logr V-level to a zerolog level
+func zerologLevel(level int) zerolog.Level {
+	switch {
+	case level <= 0:
+		return zerolog.InfoLevel
+	case level == 1:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.TraceLevel
+	}
+}
+
+// logValues prepares logr key/value pairs for zerolog: values implementing
+// fmt.Stringer, such as namespaced names, are logged as strings rather than
+// JSON objects, and keys that aren't strings are formatted
+func logValues(keysAndValues []interface{}) []interface{} {
+	if len(keysAndValues)%2 == 1 {
+		keysAndValues = append(keysAndValues, "(MISSING)")
+	}
+	values := make([]interface{}, len(keysAndValues))
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, value := keysAndValues[i], keysAndValues[i+1]
+		if _, ok := key.(string); !ok {
+			key = fmt.Sprint(key)
+		}
+		switch v := value.(type) {
+		case error, zerolog.LogObjectMarshaler:
+		case fmt.Stringer:
+			value = stringValue(v)
+		}
+		values[i], values[i+1] = key, value
+	}
+	return values
+}
+
+// stringValue formats a fmt.Stringer, which may panic on a nil pointer
+func stringValue(v fmt.Stringer) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprintf("<panic: %v>", r)
+		}
+	}()
+	return v.String()
+}
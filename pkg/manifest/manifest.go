@@ -0,0 +1,166 @@
+// Package manifest reads Kubernetes deployment manifests from files,
+// directories and stdin, used by "k6s apply".
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Stdin is the path that reads manifests from standard input
+const Stdin = "-"
+
+// Manifest is a deployment decoded from a manifest document
+type Manifest struct {
+	// Source is the file the document was read from, "-" for stdin
+	Source string
+
+	// Data is the document as JSON, ready for a server-side apply
+	Data []byte
+
+	Deployment *appsv1.Deployment
+}
+
+// Read reads the deployments of paths. Directories are read for .yaml, .yml
+// and .json files, including subdirectories when recursive is set, and "-"
+// reads stdin.
+func Read(paths []string, recursive bool, stdin io.Reader) ([]Manifest, error) {
+	var manifests []Manifest
+	for _, path := range paths {
+		if path == Stdin {
+			data, err := io.ReadAll(stdin)
+			if err != nil {
+				return nil, fmt.Errorf("reading stdin: %w", err)
+			}
+			decoded, err := Decode(data, Stdin)
+			if err != nil {
+				return nil, err
+			}
+			manifests = append(manifests, decoded...)
+			continue
+		}
+
+		files, err := files(path, recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file) // #nosec G304 - path is provided by the user running the command
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := Decode(data, file)
+			if err != nil {
+				return nil, err
+			}
+			manifests = append(manifests, decoded...)
+		}
+	}
+	return manifests, nil
+}
+
+// files returns path, or the manifest files in it when it is a directory
+func files(path string, recursive bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Decode decodes the deployments of a multi-document YAML or JSON manifest.
+// Empty documents are skipped; any other kind of object is an error.
+func Decode(data []byte, source string) ([]Manifest, error) {
+	var manifests []Manifest
+	for i, doc := range split(data) {
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		jsonData, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", source, i+1, err)
+		}
+		if string(jsonData) == "null" {
+			// Comments only
+			continue
+		}
+
+		dep := &appsv1.Deployment{}
+		if err := yaml.Unmarshal(jsonData, dep); err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", source, i+1, err)
+		}
+		if dep.APIVersion != "apps/v1" || dep.Kind != "Deployment" {
+			return nil, fmt.Errorf("%s: document %d: unsupported object %s %s, only apps/v1 Deployment can be applied",
+				source, i+1, dep.APIVersion, dep.Kind)
+		}
+		if dep.Name == "" {
+			return nil, fmt.Errorf("%s: document %d: deployment has no name", source, i+1)
+		}
+		manifests = append(manifests, Manifest{Source: source, Data: jsonData, Deployment: dep})
+	}
+	return manifests, nil
+}
+
+// split splits a YAML stream on "---" document separators
+func split(data []byte) [][]byte {
+	var docs [][]byte
+	var doc bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimRight(line, " \t") == "---" {
+			docs = append(docs, bytes.Clone(doc.Bytes()))
+			doc.Reset()
+			continue
+		}
+		doc.WriteString(line)
+		doc.WriteByte('\n')
+	}
+	return append(docs, doc.Bytes())
+}
+
+// SetNamespace sets the namespace of manifests without one to namespace. With
+// explicit set, as for a namespace given on the command line, a manifest
+// declaring a different namespace is an error.
+func SetNamespace(manifests []Manifest, namespace string, explicit bool) error {
+	for i := range manifests {
+		dep := manifests[i].Deployment
+		switch {
+		case dep.Namespace == "":
+			dep.Namespace = namespace
+		case explicit && dep.Namespace != namespace:
+			return fmt.Errorf("%s: deployment %s is in namespace %s, not %s", manifests[i].Source, dep.Name, dep.Namespace, namespace)
+		}
+	}
+	return nil
+}
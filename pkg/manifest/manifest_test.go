@@ -0,0 +1,163 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const webManifest = `# web frontend
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.27
+          env:
+            - name: MODE
+              value: production
+          readinessProbe:
+            httpGet:
+              path: /healthz
+              port: 80
+---
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: shop
+spec:
+  selector:
+    matchLabels:
+      app: api
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+        - name: api
+          image: api:2
+`
+
+func TestDecode(t *testing.T) {
+	manifests, err := Decode([]byte(webManifest), "web.yaml")
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("Expected 2 deployments, got %d", len(manifests))
+	}
+
+	web := manifests[0].Deployment
+	container := web.Spec.Template.Spec.Containers[0]
+	if web.Name != "web" || container.Env[0].Value != "production" || container.ReadinessProbe.HTTPGet.Path != "/healthz" {
+		t.Errorf("Expected the full web deployment, got %+v", web)
+	}
+	if !strings.Contains(string(manifests[0].Data), `"readinessProbe"`) {
+		t.Errorf("Expected the JSON document, got %s", manifests[0].Data)
+	}
+	if manifests[1].Deployment.Namespace != "shop" || manifests[1].Source != "web.yaml" {
+		t.Errorf("Expected api in shop from web.yaml, got %+v", manifests[1])
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"other kind", "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n", "unsupported object v1 Service"},
+		{"no name", "apiVersion: apps/v1\nkind: Deployment\n", "has no name"},
+		{"invalid yaml", "apiVersion: apps/v1\nkind: [Deployment\n", "document 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode([]byte(tt.manifest), "bad.yaml")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("web.yaml", webManifest)
+	write("README.md", "not a manifest")
+	write("jobs/worker.json", `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"worker"}}`)
+
+	manifests, err := Read([]string{dir}, false, nil)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Errorf("Expected the 2 deployments of the top directory, got %d", len(manifests))
+	}
+
+	manifests, err = Read([]string{dir}, true, nil)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(manifests) != 3 || manifests[0].Deployment.Name != "worker" {
+		t.Errorf("Expected worker to be read recursively, got %d deployments", len(manifests))
+	}
+
+	stdin := strings.NewReader("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: cli\n")
+	manifests, err = Read([]string{Stdin}, false, stdin)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Source != Stdin {
+		t.Errorf("Expected one deployment from stdin, got %+v", manifests)
+	}
+
+	if _, err := Read([]string{filepath.Join(dir, "missing.yaml")}, false, nil); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestSetNamespace(t *testing.T) {
+	manifests, err := Decode([]byte(webManifest), "web.yaml")
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if err := SetNamespace(manifests, "default", false); err != nil {
+		t.Fatalf("SetNamespace() error = %v", err)
+	}
+	if manifests[0].Deployment.Namespace != "default" || manifests[1].Deployment.Namespace != "shop" {
+		t.Errorf("Expected web in default and api in shop, got %s and %s",
+			manifests[0].Deployment.Namespace, manifests[1].Deployment.Namespace)
+	}
+
+	if err := SetNamespace(manifests, "staging", true); err == nil {
+		t.Error("Expected an error for a manifest in another namespace than the explicit one")
+	}
+	if err := SetNamespace(manifests[1:], "shop", true); err != nil {
+		t.Errorf("Expected the explicit namespace of the manifest to match, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// APIRequests counts HTTP API requests by route template and status
+	APIRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "k6s_api_requests_total",
+			Help: "Total number of HTTP API requests",
+		},
+		[]string{"method", "route", "status"},
+	)
+
+	// APIRequestDuration observes how long HTTP API requests take to handle
+	APIRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "k6s_api_request_duration_seconds",
+			Help:    "Duration of HTTP API requests",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "route"},
+	)
+
+	// APIRequestsInFlight is the number of HTTP API requests being handled
+	APIRequestsInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "k6s_api_requests_in_flight",
+			Help: "Number of HTTP API requests being handled",
+		},
+	)
+)
+
+// APIAuthorizationDenials counts HTTP API requests rejected because the
+// caller's role doesn't allow them
+var APIAuthorizationDenials = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k6s_api_authorization_denials_total",
+		Help: "Total number of HTTP API requests denied by role",
+	},
+	[]string{"role", "method", "route"},
+)
+
+// APIRateLimited counts HTTP API requests rejected because their client
+// exceeded its rate limit
+var APIRateLimited = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k6s_api_rate_limited_total",
+		Help: "Total number of HTTP API requests rejected by the rate limit",
+	},
+	[]string{"route"},
+)
+
+// APIResponseCache counts HTTP API requests answered from the response cache
+// (hit) and handled to fill it (miss)
+var APIResponseCache = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k6s_api_response_cache_requests_total",
+		Help: "Total number of cacheable HTTP API requests by cache result",
+	},
+	[]string{"route", "result"},
+)
+
+// APISharedQueries counts expensive HTTP API queries by whether they were
+// computed, shared with a concurrent identical query (shared) or answered
+// from a recent result (memoized)
+var APISharedQueries = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "k6s_api_shared_queries_total",
+		Help: "Total number of expensive HTTP API queries by how they were answered",
+	},
+	[]string{"query", "result"},
+)
+
+// RegisterAPIMetrics registers the HTTP API metrics. Registering them again
+// is not an error.
+func RegisterAPIMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{APIRequests, APIRequestDuration, APIRequestsInFlight, APIAuthorizationDenials, APIRateLimited, APIResponseCache, APISharedQueries} {
+		if err := registerer.Register(collector); err != nil {
+			var registered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &registered) {
+				return err
+			}
+		}
+	}
+	return nil
+}
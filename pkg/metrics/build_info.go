@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
+)
+
+// BuildInfo is always 1, with the build information of the running binary as
+// labels, so that metrics can be joined with the deployed version
+var BuildInfo = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "k6s_build_info",
+		Help: "Build information of the running k6s binary",
+	},
+	[]string{"version", "commit", "build_date", "go_version"},
+)
+
+// RegisterBuildInfo sets and registers the build_info gauge. Registering it
+// again is not an error.
+func RegisterBuildInfo(registerer prometheus.Registerer) error {
+	info := version.Get()
+	BuildInfo.WithLabelValues(info.Version, info.Commit, info.BuildDate, info.GoVersion).Set(1)
+
+	if err := registerer.Register(BuildInfo); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &registered) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ClusterAPIRequestDuration observes requests to the API servers of the
+	// member clusters, watches excepted
+	ClusterAPIRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "k6s_cluster_api_request_duration_seconds",
+			Help:    "Duration of requests to the API server of a cluster",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"cluster", "method", "code"},
+	)
+
+	// ClusterClients is the number of cluster clients held by the pool
+	ClusterClients = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "k6s_cluster_clients",
+			Help: "Number of cluster API clients held by the client pool",
+		},
+	)
+
+	// ClusterClientEvictions counts cluster clients closed by the pool, by
+	// reason: capacity, unhealthy, removed or changed
+	ClusterClientEvictions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "k6s_cluster_client_evictions_total",
+			Help: "Total number of cluster API clients closed by the client pool",
+		},
+		[]string{"reason"},
+	)
+)
+
+// RegisterClusterClientMetrics registers the cluster client metrics.
+// Registering them again is not an error.
+func RegisterClusterClientMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{ClusterAPIRequestDuration, ClusterClients, ClusterClientEvictions} {
+		if err := registerer.Register(collector); err != nil {
+			var registered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &registered) {
+				return err
+			}
+		}
+	}
+	return nil
+}
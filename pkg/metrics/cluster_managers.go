@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ClusterManagerStartTime is the Unix time the manager of each cluster
+	// last started
+	ClusterManagerStartTime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k6s_cluster_manager_start_time_seconds",
+			Help: "Unix time the controller manager of a cluster last started",
+		},
+		[]string{"cluster"},
+	)
+
+	// ClusterManagerRestarts counts managers started for a cluster after its first
+	ClusterManagerRestarts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "k6s_cluster_manager_restarts_total",
+			Help: "Total number of controller manager restarts per cluster",
+		},
+		[]string{"cluster"},
+	)
+
+	// ClusterManagerFailures counts cluster managers that exited with an error
+	ClusterManagerFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "k6s_cluster_manager_failures_total",
+			Help: "Total number of controller managers of a cluster that failed",
+		},
+		[]string{"cluster"},
+	)
+
+	// ClusterDegraded is 1 while the manager of a cluster failed and is
+	// retried with backoff
+	ClusterDegraded = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k6s_cluster_degraded",
+			Help: "Whether the controller manager of a cluster failed and is being retried (1) or not (0)",
+		},
+		[]string{"cluster"},
+	)
+
+	// ClusterStartRetries counts retried startups of the manager of a cluster
+	ClusterStartRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "k6s_cluster_start_retries_total",
+			Help: "Total number of retried controller manager startups per cluster",
+		},
+		[]string{"cluster"},
+	)
+)
+
+// RegisterClusterManagerMetrics registers the cluster manager metrics.
+// Registering them again is not an error.
+func RegisterClusterManagerMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{ClusterManagerStartTime, ClusterManagerRestarts, ClusterManagerFailures, ClusterDegraded, ClusterStartRetries} {
+		if err := registerer.Register(collector); err != nil {
+			var registered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &registered) {
+				return err
+			}
+		}
+	}
+	return nil
+}
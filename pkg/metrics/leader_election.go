@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// LeaderInfo is 1 for the identity holding the leader election lease
+	LeaderInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k6s_leader_election_leader_info",
+			Help: "Identity holding the leader election lease, 1 for the current leader",
+		},
+		[]string{"lease", "identity"},
+	)
+
+	// IsLeader is 1 while this replica holds the leader election lease
+	IsLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "k6s_leader_election_is_leader",
+			Help: "Whether this replica is the leader (1) or not (0)",
+		},
+	)
+
+	// ClusterLeader is 1 for the clusters whose lease this replica holds, in
+	// multi-cluster mode with leader election
+	ClusterLeader = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k6s_cluster_leader",
+			Help: "Whether this replica is the leader of a cluster (1) or not (0)",
+		},
+		[]string{"cluster"},
+	)
+)
+
+// RegisterLeaderElectionMetrics registers the leader election metrics.
+// Registering them again is not an error.
+func RegisterLeaderElectionMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{LeaderInfo, IsLeader, ClusterLeader} {
+		if err := registerer.Register(collector); err != nil {
+			var registered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &registered) {
+				return err
+			}
+		}
+	}
+	return nil
+}
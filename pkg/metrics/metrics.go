@@ -2,15 +2,20 @@
 package metrics
 
 import (
+	"errors"
+
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
-// Metrics holds all Prometheus metrics
+// Metrics holds all Prometheus metrics. Its methods do nothing on a nil
+// Metrics, so components record metrics only once they are given one.
 type Metrics struct {
 	// Deployment metrics
 	DeploymentEvents *prometheus.CounterVec
 	DeploymentStatus *prometheus.GaugeVec
+
+	// Informer updates not passed to handlers
+	SuppressedUpdates *prometheus.CounterVec
 	
 	// Reconciliation metrics
 	ReconciliationDuration *prometheus.HistogramVec
@@ -30,10 +35,21 @@ type Metrics struct {
 	CacheSize   *prometheus.GaugeVec
 }
 
-// New creates a new Metrics instance
+// New creates a new Metrics instance registered with the default registry
 func New() *Metrics {
-	return &Metrics{
-		DeploymentEvents: promauto.NewCounterVec(
+	m, err := NewWithRegisterer(prometheus.DefaultRegisterer)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// NewWithRegisterer creates a Metrics instance registered with registerer.
+// Metrics already registered there are reused, so that components created
+// again share their series.
+func NewWithRegisterer(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		DeploymentEvents: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "k6s_deployment_events_total",
 				Help: "Total number of deployment events processed",
@@ -41,7 +57,15 @@ func New() *Metrics {
 			[]string{"cluster", "namespace", "event_type"},
 		),
 		
-		DeploymentStatus: promauto.NewGaugeVec(
+		SuppressedUpdates: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "k6s_informer_suppressed_updates_total",
+				Help: "Total number of informer updates suppressed as unchanged",
+			},
+			[]string{"cluster", "namespace", "reason"},
+		),
+		
+		DeploymentStatus: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "k6s_deployment_status",
 				Help: "Current status of deployments",
@@ -49,7 +73,7 @@ func New() *Metrics {
 			[]string{"cluster", "namespace", "deployment", "status"},
 		),
 		
-		ReconciliationDuration: promauto.NewHistogramVec(
+		ReconciliationDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name: "k6s_reconciliation_duration_seconds",
 				Help: "Duration of reconciliation operations",
@@ -58,7 +82,7 @@ func New() *Metrics {
 			[]string{"cluster", "controller"},
 		),
 		
-		ReconciliationErrors: promauto.NewCounterVec(
+		ReconciliationErrors: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "k6s_reconciliation_errors_total",
 				Help: "Total number of reconciliation errors",
@@ -66,7 +90,7 @@ func New() *Metrics {
 			[]string{"cluster", "controller", "error_type"},
 		),
 		
-		ControllerRestarts: promauto.NewCounterVec(
+		ControllerRestarts: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "k6s_controller_restarts_total",
 				Help: "Total number of controller restarts",
@@ -74,7 +98,7 @@ func New() *Metrics {
 			[]string{"controller", "reason"},
 		),
 		
-		ControllerUptime: promauto.NewGaugeVec(
+		ControllerUptime: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "k6s_controller_uptime_seconds",
 				Help: "Controller uptime in seconds",
@@ -82,7 +106,7 @@ func New() *Metrics {
 			[]string{"controller"},
 		),
 		
-		ClusterConnections: promauto.NewGaugeVec(
+		ClusterConnections: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "k6s_cluster_connections",
 				Help: "Current number of cluster connections",
@@ -90,7 +114,7 @@ func New() *Metrics {
 			[]string{"cluster", "status"},
 		),
 		
-		ClusterLatency: promauto.NewHistogramVec(
+		ClusterLatency: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name: "k6s_cluster_latency_seconds",
 				Help: "Cluster API latency",
@@ -99,7 +123,7 @@ func New() *Metrics {
 			[]string{"cluster", "operation"},
 		),
 		
-		CacheHits: promauto.NewCounterVec(
+		CacheHits: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "k6s_cache_hits_total",
 				Help: "Total number of cache hits",
@@ -107,7 +131,7 @@ func New() *Metrics {
 			[]string{"cache_type"},
 		),
 		
-		CacheMisses: promauto.NewCounterVec(
+		CacheMisses: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "k6s_cache_misses_total",
 				Help: "Total number of cache misses",
@@ -115,7 +139,7 @@ func New() *Metrics {
 			[]string{"cache_type"},
 		),
 		
-		CacheSize: promauto.NewGaugeVec(
+		CacheSize: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "k6s_cache_size",
 				Help: "Current cache size",
@@ -123,59 +147,132 @@ func New() *Metrics {
 			[]string{"cache_type"},
 		),
 	}
+
+	var err error
+	register := func(collector prometheus.Collector) prometheus.Collector {
+		if err != nil {
+			return collector
+		}
+		if regErr := registerer.Register(collector); regErr != nil {
+			var registered prometheus.AlreadyRegisteredError
+			if errors.As(regErr, &registered) {
+				return registered.ExistingCollector
+			}
+			err = regErr
+		}
+		return collector
+	}
+	m.DeploymentEvents = register(m.DeploymentEvents).(*prometheus.CounterVec)
+	m.SuppressedUpdates = register(m.SuppressedUpdates).(*prometheus.CounterVec)
+	m.DeploymentStatus = register(m.DeploymentStatus).(*prometheus.GaugeVec)
+	m.ReconciliationDuration = register(m.ReconciliationDuration).(*prometheus.HistogramVec)
+	m.ReconciliationErrors = register(m.ReconciliationErrors).(*prometheus.CounterVec)
+	m.ControllerRestarts = register(m.ControllerRestarts).(*prometheus.CounterVec)
+	m.ControllerUptime = register(m.ControllerUptime).(*prometheus.GaugeVec)
+	m.ClusterConnections = register(m.ClusterConnections).(*prometheus.GaugeVec)
+	m.ClusterLatency = register(m.ClusterLatency).(*prometheus.HistogramVec)
+	m.CacheHits = register(m.CacheHits).(*prometheus.CounterVec)
+	m.CacheMisses = register(m.CacheMisses).(*prometheus.CounterVec)
+	m.CacheSize = register(m.CacheSize).(*prometheus.GaugeVec)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
 // RecordDeploymentEvent records a deployment event
 func (m *Metrics) RecordDeploymentEvent(cluster, namespace, eventType string) {
+	if m == nil {
+		return
+	}
 	m.DeploymentEvents.WithLabelValues(cluster, namespace, eventType).Inc()
 }
 
+// RecordSuppressedUpdate records an informer update that was not passed to
+// handlers, for reason "resync" or "unchanged"
+func (m *Metrics) RecordSuppressedUpdate(cluster, namespace, reason string) {
+	if m == nil {
+		return
+	}
+	m.SuppressedUpdates.WithLabelValues(cluster, namespace, reason).Inc()
+}
+
 // SetDeploymentStatus sets the deployment status
 func (m *Metrics) SetDeploymentStatus(cluster, namespace, deployment, status string, value float64) {
+	if m == nil {
+		return
+	}
 	m.DeploymentStatus.WithLabelValues(cluster, namespace, deployment, status).Set(value)
 }
 
 // RecordReconciliationDuration records reconciliation duration
 func (m *Metrics) RecordReconciliationDuration(cluster, controller string, duration float64) {
+	if m == nil {
+		return
+	}
 	m.ReconciliationDuration.WithLabelValues(cluster, controller).Observe(duration)
 }
 
 // RecordReconciliationError records a reconciliation error
 func (m *Metrics) RecordReconciliationError(cluster, controller, errorType string) {
+	if m == nil {
+		return
+	}
 	m.ReconciliationErrors.WithLabelValues(cluster, controller, errorType).Inc()
 }
 
 // RecordControllerRestart records a controller restart
 func (m *Metrics) RecordControllerRestart(controller, reason string) {
+	if m == nil {
+		return
+	}
 	m.ControllerRestarts.WithLabelValues(controller, reason).Inc()
 }
 
 // SetControllerUptime sets the controller uptime
 func (m *Metrics) SetControllerUptime(controller string, uptime float64) {
+	if m == nil {
+		return
+	}
 	m.ControllerUptime.WithLabelValues(controller).Set(uptime)
 }
 
 // SetClusterConnections sets the number of cluster connections
 func (m *Metrics) SetClusterConnections(cluster, status string, count float64) {
+	if m == nil {
+		return
+	}
 	m.ClusterConnections.WithLabelValues(cluster, status).Set(count)
 }
 
 // RecordClusterLatency records cluster API latency
 func (m *Metrics) RecordClusterLatency(cluster, operation string, latency float64) {
+	if m == nil {
+		return
+	}
 	m.ClusterLatency.WithLabelValues(cluster, operation).Observe(latency)
 }
 
 // RecordCacheHit records a cache hit
 func (m *Metrics) RecordCacheHit(cacheType string) {
+	if m == nil {
+		return
+	}
 	m.CacheHits.WithLabelValues(cacheType).Inc()
 }
 
 // RecordCacheMiss records a cache miss
 func (m *Metrics) RecordCacheMiss(cacheType string) {
+	if m == nil {
+		return
+	}
 	m.CacheMisses.WithLabelValues(cacheType).Inc()
 }
 
 // SetCacheSize sets the cache size
 func (m *Metrics) SetCacheSize(cacheType string, size float64) {
+	if m == nil {
+		return
+	}
 	m.CacheSize.WithLabelValues(cacheType).Set(size)
 }
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ShardMembers is the number of replicas sharing the clusters
+	ShardMembers = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "k6s_shard_members",
+			Help: "Number of controller replicas sharing the clusters",
+		},
+	)
+
+	// ShardClusters is the number of enabled clusters assigned to this
+	// replica
+	ShardClusters = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "k6s_shard_clusters",
+			Help: "Number of enabled clusters assigned to this replica",
+		},
+	)
+)
+
+// RegisterShardingMetrics registers the sharding metrics. Registering them
+// again is not an error.
+func RegisterShardingMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{ShardMembers, ShardClusters} {
+		if err := registerer.Register(collector); err != nil {
+			var registered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &registered) {
+				return err
+			}
+		}
+	}
+	return nil
+}
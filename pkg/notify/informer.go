@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// informerHandler notifies the events of a deployment informer
+type informerHandler struct {
+	notifier *Notifier
+	cluster  string
+
+	// When the handler was created; older deployments listed when the
+	// informer starts are not notified as added
+	since time.Time
+}
+
+// InformerHandler returns a handler notifying the deployment events of an
+// informer watching cluster. Updates changing none of the replicas, images,
+// resources or labels, such as status updates and resyncs, are skipped, as
+// are deployments created before the handler.
+func InformerHandler(notifier *Notifier, cluster string) kubernetes.DeploymentEventHandler {
+	return &informerHandler{notifier: notifier, cluster: cluster, since: time.Now()}
+}
+
+// OnAdd notifies a deployment created since the handler
+func (h *informerHandler) OnAdd(obj *appsv1.Deployment) {
+	if obj.CreationTimestamp.Time.Before(h.since.Truncate(time.Second)) {
+		return
+	}
+	h.notifier.Notify(h.event(EventAdded, obj, nil))
+}
+
+// OnUpdate notifies the changes of an updated deployment
+func (h *informerHandler) OnUpdate(oldObj, newObj *appsv1.Deployment) {
+	changes := kubernetes.CompareDeployments(oldObj, newObj)
+	if len(changes) == 0 {
+		return
+	}
+	h.notifier.Notify(h.event(EventUpdated, newObj, changes))
+}
+
+// OnDelete notifies a deleted deployment
+func (h *informerHandler) OnDelete(obj *appsv1.Deployment) {
+	h.notifier.Notify(h.event(EventDeleted, obj, nil))
+}
+
+// event returns the event of a deployment
+func (h *informerHandler) event(eventType string, obj *appsv1.Deployment, changes []kubernetes.DeploymentChange) Event {
+	return Event{
+		Type:      eventType,
+		Source:    SourceInformer,
+		Cluster:   h.cluster,
+		Namespace: obj.Namespace,
+		Name:      obj.Name,
+		Labels:    obj.Labels,
+		Changes:   changes,
+	}
+}
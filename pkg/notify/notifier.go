@@ -0,0 +1,370 @@
+// Package notify delivers deployment events matching configurable rules to
+// Slack, generic HTTP endpoints and email
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Event types
+const (
+	EventAdded   = "added"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+
+	// The rollout of a deployment stopped progressing
+	EventRolloutStuck = "rollout-stuck"
+
+	// The pods of a deployment restarted far more than usual
+	EventRestartSpike = "restart-spike"
+
+	// A deployment was scaled to zero replicas
+	EventScaleToZero = "scale-to-zero"
+
+	// The primary cluster failed over to another cluster
+	EventFailover = "failover"
+)
+
+// Event sources
+const (
+	// The deployment informer of the k6s server
+	SourceInformer = "informer"
+
+	// The deployment reconciler of the controller
+	SourceController = "controller"
+
+	// The rollout tracker of the k6s server
+	SourceRollout = "rollout"
+
+	// The anomaly detector of the k6s server
+	SourceAnomaly = "anomaly"
+
+	// The primary cluster failover of the controller
+	SourceFailover = "failover"
+)
+
+// queueSize is the number of notifications a sink holds while delivering;
+// more are dropped
+const queueSize = 100
+
+// sendTimeout bounds a single delivery attempt
+const sendTimeout = 10 * time.Second
+
+// Event is a deployment event. Changes are only reported for updates seen
+// by the informer, and Message for events of the rollout tracker, the
+// anomaly detector and failover. Failover events concern the cluster
+// promoted to primary and have no namespace or name.
+type Event struct {
+	Type      string                        `json:"type"`
+	Source    string                        `json:"source"`
+	Cluster   string                        `json:"cluster,omitempty"`
+	Namespace string                        `json:"namespace"`
+	Name      string                        `json:"name"`
+	Labels    map[string]string             `json:"labels,omitempty"`
+	Changes   []kubernetes.DeploymentChange `json:"changes,omitempty"`
+	Message   string                        `json:"message,omitempty"`
+	Time      time.Time                     `json:"time"`
+}
+
+// Notification is an event delivered to a sink, with the first rule it
+// matched
+type Notification struct {
+	Rule string `json:"rule"`
+	Event
+}
+
+// Summary describes the notification in a line, such as
+// "Deployment prod/shop/web updated: Replicas changed from 2 to 3"
+func (n Notification) Summary() string {
+	if n.Name == "" {
+		return fmt.Sprintf("Cluster %s %s: %s", n.Cluster, n.Type, n.Message)
+	}
+	target := n.Namespace + "/" + n.Name
+	if n.Cluster != "" {
+		target = n.Cluster + "/" + target
+	}
+	summary := fmt.Sprintf("Deployment %s %s", target, n.Type)
+	if len(n.Changes) > 0 {
+		descriptions := make([]string, 0, len(n.Changes))
+		for _, change := range n.Changes {
+			descriptions = append(descriptions, change.Description)
+		}
+		summary += ": " + strings.Join(descriptions, ", ")
+	} else if n.Message != "" {
+		summary += ": " + n.Message
+	}
+	return summary
+}
+
+// Notifier matches deployment events against the configured rules and
+// delivers those matching to the rules' sinks. Each sink delivers in the
+// background, retrying failed deliveries, so Notify never blocks.
+type Notifier struct {
+	log *logger.Logger
+
+	mu    sync.RWMutex
+	cfg   config.NotificationsConfig
+	rules []rule
+	sinks map[string]*sinkWorker
+
+	// Context of Start, nil before; sinks are started in it
+	ctx context.Context
+}
+
+// rule is a compiled notification rule. Empty sets match everything.
+type rule struct {
+	name       string
+	events     map[string]bool
+	sources    map[string]bool
+	namespaces map[string]bool
+	changes    map[string]bool
+	selector   labels.Selector
+	sinks      []string
+}
+
+// sinkWorker delivers the notifications queued for a sink
+type sinkWorker struct {
+	name    string
+	sink    Sink
+	queue   chan Notification
+	limiter *rate.Limiter
+	cancel  context.CancelFunc
+
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// New creates a notifier for cfg. Invalid sinks are logged and skipped.
+func New(cfg config.NotificationsConfig) *Notifier {
+	n := &Notifier{log: logger.WithComponent("notify")}
+	n.SetConfig(cfg)
+	return n
+}
+
+// SetConfig replaces the rules and sinks. Notifications still queued for
+// the previous sinks are dropped.
+func (n *Notifier) SetConfig(cfg config.NotificationsConfig) {
+	rules := make([]rule, 0, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("rule-%d", i+1)
+		}
+		rules = append(rules, rule{
+			name:       name,
+			events:     toSet(r.Events),
+			sources:    toSet(r.Sources),
+			namespaces: toSet(r.Namespaces),
+			changes:    toSet(r.Changes),
+			selector:   labels.SelectorFromSet(r.Selector),
+			sinks:      r.Sinks,
+		})
+	}
+
+	sinks := make(map[string]*sinkWorker, len(cfg.Sinks))
+	for _, sinkConfig := range cfg.Sinks {
+		sink, err := NewSink(sinkConfig)
+		if err != nil {
+			n.log.Warn("Skipping notification sink", map[string]interface{}{
+				"sink":  sinkConfig.Name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		worker := &sinkWorker{
+			name:        sinkConfig.Name,
+			sink:        sink,
+			queue:       make(chan Notification, queueSize),
+			limiter:     rate.NewLimiter(rate.Inf, 0),
+			maxAttempts: cfg.MaxAttempts,
+			backoff:     cfg.RetryBackoff,
+		}
+		if cfg.RateLimit > 0 {
+			worker.limiter = rate.NewLimiter(rate.Limit(float64(cfg.RateLimit)/60), cfg.RateLimit)
+		}
+		sinks[sinkConfig.Name] = worker
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, worker := range n.sinks {
+		if worker.cancel != nil {
+			worker.cancel()
+		}
+	}
+	n.cfg = cfg
+	n.rules = rules
+	n.sinks = sinks
+	if n.ctx != nil {
+		n.startSinks()
+	}
+}
+
+// Enabled reports whether notifications are delivered
+func (n *Notifier) Enabled() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.cfg.Enabled
+}
+
+// Start delivers notifications until ctx is cancelled
+func (n *Notifier) Start(ctx context.Context) {
+	n.mu.Lock()
+	n.ctx = ctx
+	n.startSinks()
+	n.mu.Unlock()
+
+	<-ctx.Done()
+}
+
+// startSinks starts a goroutine delivering the queue of each sink. The
+// caller must hold n.mu.
+func (n *Notifier) startSinks() {
+	for _, worker := range n.sinks {
+		ctx, cancel := context.WithCancel(n.ctx)
+		worker.cancel = cancel
+		go worker.run(ctx, n.log)
+	}
+}
+
+// Notify queues event for the sinks of the rules it matches. An event
+// matching several rules is delivered once to each sink. Events are dropped
+// when a sink is over its rate limit or its queue is full.
+func (n *Notifier) Notify(event Event) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if !n.cfg.Enabled {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	queued := make(map[string]bool)
+	for _, r := range n.rules {
+		if !r.matches(event) {
+			continue
+		}
+		for _, name := range r.sinks {
+			worker, exists := n.sinks[name]
+			if !exists || queued[name] {
+				continue
+			}
+			queued[name] = true
+			n.enqueue(worker, Notification{Rule: r.name, Event: event})
+		}
+	}
+}
+
+// enqueue queues a notification for a sink unless it is over its rate
+// limit or its queue is full
+func (n *Notifier) enqueue(worker *sinkWorker, notification Notification) {
+	fields := map[string]interface{}{
+		"sink":       worker.name,
+		"rule":       notification.Rule,
+		"namespace":  notification.Namespace,
+		"deployment": notification.Name,
+	}
+	if !worker.limiter.Allow() {
+		n.log.Warn("Notification dropped, sink over its rate limit", fields)
+		return
+	}
+	select {
+	case worker.queue <- notification:
+	default:
+		n.log.Warn("Notification dropped, sink queue full", fields)
+	}
+}
+
+// matches reports whether event satisfies every condition of the rule
+func (r rule) matches(event Event) bool {
+	if !matchesSet(r.events, event.Type) || !matchesSet(r.sources, event.Source) || !matchesSet(r.namespaces, event.Namespace) {
+		return false
+	}
+	if !r.selector.Matches(labels.Set(event.Labels)) {
+		return false
+	}
+	if len(r.changes) == 0 {
+		return true
+	}
+	for _, change := range event.Changes {
+		if r.changes[changeKind(change.Field)] {
+			return true
+		}
+	}
+	return false
+}
+
+// run delivers queued notifications until ctx is cancelled
+func (w *sinkWorker) run(ctx context.Context, log *logger.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-w.queue:
+			if err := w.deliver(ctx, notification); err != nil {
+				log.Warn("Failed to deliver notification", map[string]interface{}{
+					"sink":       w.name,
+					"rule":       notification.Rule,
+					"namespace":  notification.Namespace,
+					"deployment": notification.Name,
+					"attempts":   w.maxAttempts,
+					"error":      err.Error(),
+				})
+			}
+		}
+	}
+}
+
+// deliver sends a notification, retrying with a doubling backoff
+func (w *sinkWorker) deliver(ctx context.Context, notification Notification) error {
+	backoff := w.backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err = w.sink.Send(sendCtx, notification)
+		cancel()
+		if err == nil || attempt >= w.maxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// changeKind returns the kind of a changed field, such as "image" for
+// "containers[0].image"
+func changeKind(field string) string {
+	return field[strings.LastIndex(field, ".")+1:]
+}
+
+// matchesSet reports whether value is in set, or set is empty
+func matchesSet(set map[string]bool, value string) bool {
+	return len(set) == 0 || set[value]
+}
+
+// toSet returns the values as a set
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,278 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// recordingServer records the notifications posted to it, failing the
+// first failures requests
+type recordingServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	received []Notification
+	headers  http.Header
+}
+
+func newRecordingServer(failures int) *recordingServer {
+	s := &recordingServer{failures: failures}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var notification Notification
+		_ = json.NewDecoder(r.Body).Decode(&notification)
+		s.received = append(s.received, notification)
+		s.headers = r.Header
+	}))
+	return s
+}
+
+func (s *recordingServer) notifications() []Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Notification(nil), s.received...)
+}
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for notifications")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNotifierRules(t *testing.T) {
+	srv := newRecordingServer(0)
+	defer srv.Close()
+
+	n := New(config.NotificationsConfig{
+		Enabled:     true,
+		MaxAttempts: 1,
+		Sinks: []config.NotificationSinkConfig{
+			{Name: "hook", Type: "webhook", URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer token"}},
+		},
+		Rules: []config.NotificationRuleConfig{
+			{Name: "prod-images", Events: []string{EventUpdated}, Namespaces: []string{"prod"}, Changes: []string{"image"}, Sinks: []string{"hook"}},
+			{Name: "shop-deletes", Events: []string{EventDeleted}, Selector: map[string]string{"team": "shop"}, Sinks: []string{"hook"}},
+			// Overlaps prod-images, which is delivered once
+			{Name: "prod-everything", Namespaces: []string{"prod"}, Sinks: []string{"hook"}},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Start(ctx)
+
+	image := kubernetes.DeploymentChange{Type: "image", Field: "containers[0].image", Description: "Container web image changed from nginx:1.25 to nginx:1.26"}
+	replicas := kubernetes.DeploymentChange{Type: "scale", Field: "replicas", Description: "Replicas changed from 2 to 3"}
+	n.Notify(Event{Type: EventUpdated, Source: SourceInformer, Namespace: "prod", Name: "web", Changes: []kubernetes.DeploymentChange{image}})
+	n.Notify(Event{Type: EventUpdated, Source: SourceInformer, Namespace: "staging", Name: "web", Changes: []kubernetes.DeploymentChange{image}})
+	n.Notify(Event{Type: EventUpdated, Source: SourceInformer, Namespace: "staging", Name: "api", Changes: []kubernetes.DeploymentChange{replicas}})
+	n.Notify(Event{Type: EventDeleted, Source: SourceController, Namespace: "staging", Name: "cart", Labels: map[string]string{"team": "shop"}})
+	n.Notify(Event{Type: EventDeleted, Source: SourceController, Namespace: "staging", Name: "auth", Labels: map[string]string{"team": "identity"}})
+
+	waitFor(t, func() bool { return len(srv.notifications()) >= 2 })
+	// Give unexpected notifications time to arrive
+	time.Sleep(20 * time.Millisecond)
+	got := srv.notifications()
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 notifications, got %d: %+v", len(got), got)
+	}
+	if got[0].Rule != "prod-images" || got[0].Namespace != "prod" || got[0].Name != "web" || len(got[0].Changes) != 1 {
+		t.Errorf("Unexpected first notification %+v", got[0])
+	}
+	if got[1].Rule != "shop-deletes" || got[1].Name != "cart" || got[1].Source != SourceController {
+		t.Errorf("Unexpected second notification %+v", got[1])
+	}
+	if auth := srv.headers.Get("Authorization"); auth != "Bearer token" {
+		t.Errorf("Expected the configured Authorization header, got %q", auth)
+	}
+}
+
+func TestNotifierRetries(t *testing.T) {
+	srv := newRecordingServer(2)
+	defer srv.Close()
+
+	n := New(config.NotificationsConfig{
+		Enabled:      true,
+		MaxAttempts:  3,
+		RetryBackoff: time.Millisecond,
+		Sinks:        []config.NotificationSinkConfig{{Name: "hook", Type: "webhook", URL: srv.URL}},
+		Rules:        []config.NotificationRuleConfig{{Sinks: []string{"hook"}}},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Start(ctx)
+
+	n.Notify(Event{Type: EventAdded, Namespace: "prod", Name: "web"})
+	waitFor(t, func() bool { return len(srv.notifications()) == 1 })
+	if got := srv.notifications()[0]; got.Rule != "rule-1" || got.Time.IsZero() {
+		t.Errorf("Expected the unnamed rule and the event time, got %+v", got)
+	}
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	srv := newRecordingServer(0)
+	defer srv.Close()
+
+	n := New(config.NotificationsConfig{
+		Enabled:     true,
+		MaxAttempts: 1,
+		RateLimit:   2,
+		Sinks:       []config.NotificationSinkConfig{{Name: "hook", Type: "webhook", URL: srv.URL}},
+		Rules:       []config.NotificationRuleConfig{{Sinks: []string{"hook"}}},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Start(ctx)
+
+	for _, name := range []string{"web", "api", "cart", "auth"} {
+		n.Notify(Event{Type: EventAdded, Namespace: "prod", Name: name})
+	}
+	waitFor(t, func() bool { return len(srv.notifications()) >= 2 })
+	time.Sleep(20 * time.Millisecond)
+	if got := srv.notifications(); len(got) != 2 {
+		t.Errorf("Expected the rate limit to drop all but 2 notifications, got %d", len(got))
+	}
+}
+
+func TestNotifierDisabled(t *testing.T) {
+	n := New(config.NotificationsConfig{
+		Sinks: []config.NotificationSinkConfig{{Name: "hook", Type: "webhook", URL: "http://127.0.0.1:1"}},
+		Rules: []config.NotificationRuleConfig{{Sinks: []string{"hook"}}},
+	})
+	n.Notify(Event{Type: EventAdded, Namespace: "prod", Name: "web"})
+	if queued := len(n.sinks["hook"].queue); queued != 0 {
+		t.Errorf("Expected a disabled notifier to queue nothing, got %d", queued)
+	}
+}
+
+func TestSlackSink(t *testing.T) {
+	var body map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer srv.Close()
+
+	sink, err := NewSink(config.NotificationSinkConfig{Name: "slack", Type: "slack", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notification := Notification{Rule: "prod", Event: Event{
+		Type:      EventUpdated,
+		Cluster:   "eu",
+		Namespace: "prod",
+		Name:      "web",
+		Changes:   []kubernetes.DeploymentChange{{Field: "replicas", Description: "Replicas changed from 2 to 3"}},
+	}}
+	if err := sink.Send(context.TODO(), notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := "Deployment eu/prod/web updated: Replicas changed from 2 to 3"; body["text"] != want {
+		t.Errorf("Expected text %q, got %q", want, body["text"])
+	}
+
+	failover := Notification{Rule: "prod", Event: Event{
+		Type:    EventFailover,
+		Source:  SourceFailover,
+		Cluster: "eu",
+		Message: "promoted to primary, replacing unreachable cluster us",
+	}}
+	if err := sink.Send(context.TODO(), failover); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := "Cluster eu failover: promoted to primary, replacing unreachable cluster us"; body["text"] != want {
+		t.Errorf("Expected text %q, got %q", want, body["text"])
+	}
+}
+
+func TestEmailSink(t *testing.T) {
+	var addr, from string
+	var to []string
+	var msg []byte
+	sink := &emailSink{
+		cfg: config.SMTPConfig{Host: "smtp.example.com", Port: 587, From: "k6s@example.com", To: []string{"ops@example.com"}},
+		send: func(a string, auth smtp.Auth, f string, t []string, m []byte) error {
+			addr, from, to, msg = a, f, t, m
+			return nil
+		},
+	}
+
+	notification := Notification{Rule: "deletes", Event: Event{Type: EventDeleted, Namespace: "prod", Name: "web"}}
+	if err := sink.Send(context.TODO(), notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if addr != "smtp.example.com:587" || from != "k6s@example.com" || len(to) != 1 || to[0] != "ops@example.com" {
+		t.Errorf("Unexpected envelope %s %s %v", addr, from, to)
+	}
+	if !strings.Contains(string(msg), "Subject: [k6s] Deployment prod/web deleted\r\n") {
+		t.Errorf("Expected the summary as the subject, got %q", msg)
+	}
+}
+
+func TestInformerHandler(t *testing.T) {
+	srv := newRecordingServer(0)
+	defer srv.Close()
+
+	n := New(config.NotificationsConfig{
+		Enabled:     true,
+		MaxAttempts: 1,
+		Sinks:       []config.NotificationSinkConfig{{Name: "hook", Type: "webhook", URL: srv.URL}},
+		Rules:       []config.NotificationRuleConfig{{Sinks: []string{"hook"}}},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Start(ctx)
+
+	handler := InformerHandler(n, "eu")
+	replicas := func(n int32) *int32 { return &n }
+	existing := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "existing", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))}}
+	web := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web", CreationTimestamp: metav1.Now()}}
+	web.Spec.Replicas = replicas(2)
+	scaled := web.DeepCopy()
+	scaled.Spec.Replicas = replicas(3)
+
+	// Listing existing deployments and status-only updates are not notified
+	handler.OnAdd(existing)
+	handler.OnAdd(web)
+	handler.OnUpdate(web, web.DeepCopy())
+	handler.OnUpdate(web, scaled)
+	handler.OnDelete(scaled)
+
+	waitFor(t, func() bool { return len(srv.notifications()) >= 3 })
+	time.Sleep(20 * time.Millisecond)
+	got := srv.notifications()
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 notifications, got %+v", got)
+	}
+	for i, want := range []string{EventAdded, EventUpdated, EventDeleted} {
+		if got[i].Type != want || got[i].Name != "web" || got[i].Cluster != "eu" || got[i].Source != SourceInformer {
+			t.Errorf("Expected notification %d to be web %s, got %+v", i, want, got[i])
+		}
+	}
+	if len(got[1].Changes) != 1 || got[1].Changes[0].Field != "replicas" {
+		t.Errorf("Expected the replica change, got %+v", got[1].Changes)
+	}
+}
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+)
+
+// Sink delivers notifications to a destination
+type Sink interface {
+	Send(ctx context.Context, notification Notification) error
+}
+
+// NewSink creates the sink of a sink configuration
+func NewSink(cfg config.NotificationSinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case "slack":
+		return &slackSink{url: cfg.URL, client: http.DefaultClient}, nil
+	case "webhook":
+		return &webhookSink{url: cfg.URL, headers: cfg.Headers, client: http.DefaultClient}, nil
+	case "email":
+		return &emailSink{cfg: cfg.SMTP, send: smtp.SendMail}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+	}
+}
+
+// slackSink posts the summary of notifications to a Slack incoming webhook
+type slackSink struct {
+	url    string
+	client *http.Client
+}
+
+// Send posts the notification summary as the message text
+func (s *slackSink) Send(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, s.client, s.url, nil, map[string]string{"text": notification.Summary()})
+}
+
+// webhookSink posts notifications as JSON to an HTTP endpoint
+type webhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// Send posts the notification with the configured headers
+func (s *webhookSink) Send(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, s.client, s.url, s.headers, notification)
+}
+
+// emailSink mails notifications through an SMTP server
+type emailSink struct {
+	cfg config.SMTPConfig
+
+	// Sends a message, smtp.SendMail outside tests
+	send func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Send mails the notification summary as the subject, with the event as the
+// body. The SMTP exchange is not bound by ctx.
+func (s *emailSink) Send(ctx context.Context, notification Notification) error {
+	body, err := json.MarshalIndent(notification, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [k6s] %s\r\n", notification.Summary())
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.Write(body)
+	msg.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	if err := s.send(addr, auth, s.cfg.From, s.cfg.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("sending mail through %s: %w", addr, err)
+	}
+	return nil
+}
+
+// postJSON posts body as JSON to url, failing on any status but 2xx
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded %s", url, resp.Status)
+	}
+	return nil
+}
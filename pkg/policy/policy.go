@@ -0,0 +1,216 @@
+// Package policy evaluates deployments against the rules of
+// K6sDeploymentPolicy objects: replica bounds, allowed image registries and
+// required labels.
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GVR identifies the namespaced K6sDeploymentPolicy resource
+var GVR = schema.GroupVersionResource{
+	Group:    "k6s.io",
+	Version:  "v1alpha1",
+	Resource: "k6sdeploymentpolicies",
+}
+
+// Kind is the kind of policy objects
+const Kind = "K6sDeploymentPolicy"
+
+// defaultRegistry is the registry of images without one
+const defaultRegistry = "docker.io"
+
+// Rules a deployment can violate
+const (
+	RuleMinReplicas       = "minReplicas"
+	RuleMaxReplicas       = "maxReplicas"
+	RuleAllowedRegistries = "allowedRegistries"
+	RuleRequiredLabels    = "requiredLabels"
+)
+
+// Spec is the desired state of a K6sDeploymentPolicy. Rules left empty are
+// not checked.
+type Spec struct {
+	// Labels of the deployments the policy applies to; empty selects every
+	// deployment of the policy's namespace
+	Selector map[string]string `json:"selector,omitempty"`
+
+	// Replica bounds of selected deployments
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// Registries, or registry/repository prefixes, images may be pulled from
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+
+	// Label keys every selected deployment must have
+	RequiredLabels []string `json:"requiredLabels,omitempty"`
+
+	// Scale deployments into the replica bounds instead of only reporting
+	// them; other violations can only be reported
+	Enforce bool `json:"enforce,omitempty"`
+}
+
+// Violation is a rule a deployment breaks
+type Violation struct {
+	Deployment string `json:"deployment"`
+	Rule       string `json:"rule"`
+	Message    string `json:"message"`
+}
+
+// Status is the status reported on a K6sDeploymentPolicy
+type Status struct {
+	ObservedGeneration int64       `json:"observedGeneration"`
+	MatchedDeployments int         `json:"matchedDeployments"`
+	ViolationCount     int         `json:"violationCount"`
+	Violations         []Violation `json:"violations"`
+	LastEvaluated      string      `json:"lastEvaluated,omitempty"`
+}
+
+// Policy is a K6sDeploymentPolicy object
+type Policy struct {
+	Namespace  string
+	Name       string
+	Generation int64
+	Spec       Spec
+	Status     Status
+}
+
+// FromUnstructured converts a K6sDeploymentPolicy object
+func FromUnstructured(obj *unstructured.Unstructured) (*Policy, error) {
+	p := &Policy{Namespace: obj.GetNamespace(), Name: obj.GetName(), Generation: obj.GetGeneration()}
+	if spec, ok := obj.Object["spec"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &p.Spec); err != nil {
+			return nil, fmt.Errorf("policy %s/%s: invalid spec: %w", p.Namespace, p.Name, err)
+		}
+	}
+	if status, ok := obj.Object["status"].(map[string]interface{}); ok {
+		// A status that can't be read is rewritten
+		_ = runtime.DefaultUnstructuredConverter.FromUnstructured(status, &p.Status)
+	}
+	return p, nil
+}
+
+// Matches reports whether the policy applies to a deployment
+func (p *Policy) Matches(dep *appsv1.Deployment) bool {
+	return dep.Namespace == p.Namespace && labels.SelectorFromSet(p.Spec.Selector).Matches(labels.Set(dep.Labels))
+}
+
+// Evaluate returns the rules a deployment breaks, without checking whether
+// the policy applies to it
+func (p *Policy) Evaluate(dep *appsv1.Deployment) []Violation {
+	var violations []Violation
+	violate := func(rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{Deployment: dep.Name, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	replicas := replicasOf(dep)
+	if minimum := p.Spec.MinReplicas; minimum != nil && replicas < *minimum {
+		violate(RuleMinReplicas, "%d replicas, below the minimum of %d", replicas, *minimum)
+	}
+	if maximum := p.Spec.MaxReplicas; maximum != nil && replicas > *maximum {
+		violate(RuleMaxReplicas, "%d replicas, above the maximum of %d", replicas, *maximum)
+	}
+
+	if len(p.Spec.AllowedRegistries) > 0 {
+		podSpec := dep.Spec.Template.Spec
+		for _, c := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+			if !Allowed(c.Image, p.Spec.AllowedRegistries) {
+				violate(RuleAllowedRegistries, "container %s uses image %s from a registry that is not allowed", c.Name, c.Image)
+			}
+		}
+	}
+
+	for _, key := range p.Spec.RequiredLabels {
+		if _, ok := dep.Labels[key]; !ok {
+			violate(RuleRequiredLabels, "missing required label %s", key)
+		}
+	}
+	return violations
+}
+
+// Replicas returns the replicas an enforcing policy scales a deployment to,
+// and false when the policy doesn't enforce or the deployment is within its
+// bounds
+func (p *Policy) Replicas(dep *appsv1.Deployment) (int32, bool) {
+	if !p.Spec.Enforce {
+		return 0, false
+	}
+	replicas := replicasOf(dep)
+	switch {
+	case p.Spec.MinReplicas != nil && replicas < *p.Spec.MinReplicas:
+		return *p.Spec.MinReplicas, true
+	case p.Spec.MaxReplicas != nil && replicas > *p.Spec.MaxReplicas:
+		return *p.Spec.MaxReplicas, true
+	}
+	return 0, false
+}
+
+// Allowed reports whether an image comes from one of the allowed registries.
+// An entry is a registry, such as ghcr.io, or a registry and repository
+// prefix, such as ghcr.io/acme; images without a registry are pulled from
+// docker.io.
+func Allowed(image string, registries []string) bool {
+	ref := Reference(image)
+	for _, allowed := range registries {
+		allowed = strings.TrimSuffix(allowed, "/")
+		if ref == allowed || strings.HasPrefix(ref, allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Reference returns the registry and repository of an image, without its
+// tag or digest, adding the registry of images without one
+func Reference(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	first, _, found := strings.Cut(image, "/")
+	if !found || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		return defaultRegistry + "/" + image
+	}
+	return image
+}
+
+// NewStatus returns the status of a policy evaluated against the
+// deployments it applies to, with violations sorted by deployment and rule
+func NewStatus(p *Policy, deployments []*appsv1.Deployment) Status {
+	status := Status{ObservedGeneration: p.Generation, Violations: []Violation{}}
+	for _, dep := range deployments {
+		if !p.Matches(dep) {
+			continue
+		}
+		status.MatchedDeployments++
+		status.Violations = append(status.Violations, p.Evaluate(dep)...)
+	}
+	sort.SliceStable(status.Violations, func(i, j int) bool {
+		a, b := status.Violations[i], status.Violations[j]
+		if a.Deployment != b.Deployment {
+			return a.Deployment < b.Deployment
+		}
+		return a.Rule < b.Rule
+	})
+	status.ViolationCount = len(status.Violations)
+	return status
+}
+
+// replicasOf returns the replicas of a deployment, 1 when unset
+func replicasOf(dep *appsv1.Deployment) int32 {
+	if dep.Spec.Replicas == nil {
+		return 1
+	}
+	return *dep.Spec.Replicas
+}
@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func newDeployment(name string, replicas int32, labels map[string]string, images ...string) *appsv1.Deployment {
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, Labels: labels},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(replicas)},
+	}
+	for i, image := range images {
+		dep.Spec.Template.Spec.Containers = append(dep.Spec.Template.Spec.Containers, corev1.Container{Name: fmt.Sprintf("%s-%d", name, i), Image: image})
+	}
+	return dep
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		image      string
+		registries []string
+		want       bool
+	}{
+		{"nginx:1.27", []string{"docker.io"}, true},
+		{"nginx", []string{"ghcr.io"}, false},
+		{"ghcr.io/acme/web:1.0", []string{"ghcr.io/acme"}, true},
+		{"ghcr.io/acme-evil/web:1.0", []string{"ghcr.io/acme"}, false},
+		{"ghcr.io/acme/web@sha256:abcd", []string{"ghcr.io/acme/"}, true},
+		{"localhost:5000/web:dev", []string{"localhost:5000"}, true},
+		{"registry.example/web", []string{"docker.io", "registry.example"}, true},
+	}
+	for _, tt := range tests {
+		if got := Allowed(tt.image, tt.registries); got != tt.want {
+			t.Errorf("Allowed(%q, %v) = %v, want %v", tt.image, tt.registries, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p := &Policy{Namespace: "shop", Name: "restricted", Spec: Spec{
+		Selector:          map[string]string{"tier": "web"},
+		MinReplicas:       int32Ptr(2),
+		MaxReplicas:       int32Ptr(5),
+		AllowedRegistries: []string{"ghcr.io/acme"},
+		RequiredLabels:    []string{"team"},
+		Enforce:           true,
+	}}
+
+	good := newDeployment("web", 3, map[string]string{"tier": "web", "team": "shop"}, "ghcr.io/acme/web:1")
+	bad := newDeployment("api", 8, map[string]string{"tier": "web"}, "ghcr.io/acme/api:1", "nginx:1.27")
+	other := newDeployment("batch", 0, map[string]string{"tier": "batch"}, "busybox")
+
+	if violations := p.Evaluate(good); len(violations) != 0 {
+		t.Errorf("Expected no violations, got %+v", violations)
+	}
+	if replicas, ok := p.Replicas(bad); !ok || replicas != 5 {
+		t.Errorf("Expected api to be scaled to 5, got %d %v", replicas, ok)
+	}
+	if p.Matches(other) {
+		t.Error("Expected batch not to be selected")
+	}
+
+	status := NewStatus(p, []*appsv1.Deployment{good, bad, other})
+	if status.MatchedDeployments != 2 || status.ViolationCount != 3 {
+		t.Fatalf("Expected 2 matched deployments and 3 violations, got %+v", status)
+	}
+	rules := []string{status.Violations[0].Rule, status.Violations[1].Rule, status.Violations[2].Rule}
+	if rules[0] != RuleAllowedRegistries || rules[1] != RuleMaxReplicas || rules[2] != RuleRequiredLabels {
+		t.Errorf("Expected violations sorted by rule, got %v", rules)
+	}
+
+	p.Spec.Enforce = false
+	if _, ok := p.Replicas(bad); ok {
+		t.Error("Expected a policy without enforce not to scale")
+	}
+}
+
+func TestFromUnstructured(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"namespace": "shop", "name": "restricted", "generation": int64(2)},
+		"spec": map[string]interface{}{
+			"minReplicas":    int64(2),
+			"requiredLabels": []interface{}{"team"},
+			"enforce":        true,
+		},
+		"status": map[string]interface{}{"violationCount": int64(1)},
+	}}
+	p, err := FromUnstructured(obj)
+	if err != nil {
+		t.Fatalf("FromUnstructured() error = %v", err)
+	}
+	if p.Generation != 2 || *p.Spec.MinReplicas != 2 || !p.Spec.Enforce || p.Spec.RequiredLabels[0] != "team" || p.Status.ViolationCount != 1 {
+		t.Errorf("Unexpected policy %+v", p)
+	}
+
+	obj.Object["spec"] = map[string]interface{}{"minReplicas": "two"}
+	if _, err := FromUnstructured(obj); err == nil {
+		t.Error("Expected an error for an invalid spec")
+	}
+}
@@ -0,0 +1,481 @@
+// Package propagation copies deployments labeled k6s.io/propagate=true from
+// the primary cluster to the other enabled clusters, and tracks the outcome
+// per deployment and cluster.
+package propagation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	k6skube "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// PropagateLabel marks deployments of the primary cluster to propagate
+	PropagateLabel = "k6s.io/propagate"
+
+	// SourceAnnotation records the cluster a deployment was propagated from.
+	// Deployments without it are never overwritten.
+	SourceAnnotation = "k6s.io/propagated-from"
+
+	// requestTimeout bounds the requests made to one cluster during a sync
+	requestTimeout = 30 * time.Second
+
+	// defaultInterval is used when no interval is configured
+	defaultInterval = time.Minute
+)
+
+// ignoredAnnotations are maintained by Kubernetes or kubectl in each cluster
+// and are neither propagated nor compared
+var ignoredAnnotations = []string{
+	"deployment.kubernetes.io/revision",
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// State is the outcome of propagating a deployment to a cluster
+type State string
+
+const (
+	StateCreated  State = "created"
+	StateUpdated  State = "updated"
+	StateInSync   State = "in-sync"
+	StateConflict State = "conflict"
+	StateFailed   State = "failed"
+)
+
+// ClusterStatus is the outcome of propagating a deployment to one cluster
+type ClusterStatus struct {
+	State State  `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// DeploymentStatus reports a propagated deployment in each member cluster
+type DeploymentStatus struct {
+	Namespace string                   `json:"namespace"`
+	Name      string                   `json:"name"`
+	Clusters  map[string]ClusterStatus `json:"clusters"`
+
+	// UID of the deployment in the primary cluster, for recording events
+	uid types.UID
+}
+
+// Report is the result of a propagation sync. Failed counts the deployments
+// and clusters that are not in sync, because of a conflict or an error.
+type Report struct {
+	SyncedAt    time.Time          `json:"syncedAt"`
+	Primary     string             `json:"primary"`
+	Clusters    []string           `json:"clusters"`
+	Deployments []DeploymentStatus `json:"deployments"`
+	Failed      int                `json:"failed"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// Propagator periodically creates and updates the deployments labeled for
+// propagation on the primary cluster in every other enabled cluster. It
+// neither deletes deployments nor overwrites ones it did not create.
+type Propagator struct {
+	registry cluster.ClusterRegistry
+	log      *logger.Logger
+
+	mu     sync.RWMutex
+	cfg    config.PropagationConfig
+	report Report
+	synced bool
+
+	// Records an event on deployments that start failing to propagate, nil
+	// to only log
+	recorders k6skube.ClusterRecorders
+
+	// Deployments failing to propagate, keyed by namespace/name/cluster
+	failing map[string]bool
+}
+
+// NewPropagator creates a propagator between the clusters of registry
+func NewPropagator(registry cluster.ClusterRegistry, cfg config.PropagationConfig) *Propagator {
+	return &Propagator{
+		registry: registry,
+		cfg:      cfg,
+		failing:  make(map[string]bool),
+		log:      logger.WithComponent("propagation"),
+	}
+}
+
+// SetEventRecorders records a warning event on each deployment of the
+// primary cluster that starts failing to propagate to a member cluster. It
+// must be called before Start.
+func (p *Propagator) SetEventRecorders(recorders k6skube.ClusterRecorders) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recorders = recorders
+}
+
+// SetConfig replaces the propagation settings, taking effect at the next sync
+func (p *Propagator) SetConfig(cfg config.PropagationConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg = cfg
+	if !cfg.Enabled {
+		p.synced = false
+	}
+}
+
+// Enabled reports whether propagation is enabled
+func (p *Propagator) Enabled() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.cfg.Enabled
+}
+
+// Report returns the result of the last sync, and false before the first one
+// or while propagation is disabled
+func (p *Propagator) Report() (Report, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.report, p.synced
+}
+
+// Start syncs every interval while propagation is enabled, until ctx is cancelled
+func (p *Propagator) Start(ctx context.Context) {
+	for {
+		p.mu.RLock()
+		enabled, interval := p.cfg.Enabled, p.cfg.Interval
+		p.mu.RUnlock()
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+
+		if enabled {
+			p.Sync(ctx)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Sync propagates the labeled deployments of the primary cluster to every
+// other enabled cluster once, and stores the report
+func (p *Propagator) Sync(ctx context.Context) Report {
+	p.mu.RLock()
+	namespaces := p.cfg.Namespaces
+	p.mu.RUnlock()
+
+	report := p.sync(ctx, namespaces)
+	if report.Error != "" {
+		p.log.Warn("Failed to propagate deployments", map[string]interface{}{
+			"primary": report.Primary,
+			"error":   report.Error,
+		})
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordFailures(report)
+	p.report = report
+	p.synced = true
+	return report
+}
+
+// recordFailures records an event on the deployments that started failing
+// to propagate to a cluster since the previous sync. The caller must hold
+// p.mu.
+func (p *Propagator) recordFailures(report Report) {
+	failing := make(map[string]bool)
+	for _, dep := range report.Deployments {
+		for clusterName, status := range dep.Clusters {
+			if status.State != StateFailed && status.State != StateConflict {
+				continue
+			}
+			key := dep.Namespace + "/" + dep.Name + "/" + clusterName
+			failing[key] = true
+			if p.failing[key] || p.recorders == nil {
+				continue
+			}
+			recorder := p.recorders.Recorder(report.Primary)
+			if recorder == nil {
+				continue
+			}
+			ref := &corev1.ObjectReference{
+				Kind:       "Deployment",
+				APIVersion: "apps/v1",
+				Namespace:  dep.Namespace,
+				Name:       dep.Name,
+				UID:        dep.uid,
+			}
+			recorder.Eventf(ref, corev1.EventTypeWarning, k6skube.ReasonPropagationFailed,
+				"Failed to propagate to cluster %s: %s", clusterName, status.Error)
+		}
+	}
+	p.failing = failing
+}
+
+// sync lists the deployments to propagate and applies them to each member
+func (p *Propagator) sync(ctx context.Context, namespaces []string) Report {
+	report := Report{SyncedAt: time.Now().UTC(), Clusters: []string{}, Deployments: []DeploymentStatus{}}
+
+	clusters := p.registry.GetEnabledClusters()
+	for name, client := range clusters {
+		if isPrimary(client) {
+			report.Primary = name
+		} else {
+			report.Clusters = append(report.Clusters, name)
+		}
+	}
+	sort.Strings(report.Clusters)
+	if report.Primary == "" {
+		report.Error = "no enabled primary cluster"
+		return report
+	}
+
+	sources, err := listPropagated(ctx, clusters[report.Primary], namespaces)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	for _, source := range sources {
+		report.Deployments = append(report.Deployments, DeploymentStatus{
+			Namespace: source.Namespace,
+			Name:      source.Name,
+			Clusters:  make(map[string]ClusterStatus, len(report.Clusters)),
+			uid:       source.UID,
+		})
+	}
+
+	for _, name := range report.Clusters {
+		clientset, err := clusters[name].GetKubernetesClient()
+		for i := range sources {
+			status := ClusterStatus{State: StateFailed}
+			if err != nil {
+				status.Error = err.Error()
+			} else {
+				status = p.apply(ctx, clientset, &sources[i], report.Primary, name)
+			}
+			if status.State == StateFailed || status.State == StateConflict {
+				report.Failed++
+			}
+			report.Deployments[i].Clusters[name] = status
+		}
+	}
+	return report
+}
+
+// apply creates or updates the copy of source in a member cluster
+func (p *Propagator) apply(ctx context.Context, clientset kubernetes.Interface, source *appsv1.Deployment, primary, clusterName string) ClusterStatus {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	deployments := clientset.AppsV1().Deployments(source.Namespace)
+	desired := propagated(source, primary)
+	existing, err := deployments.Get(ctx, source.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if _, err := deployments.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return p.failed(source, clusterName, fmt.Errorf("failed to create deployment: %w", err))
+		}
+		p.log.Info("Propagated deployment", map[string]interface{}{
+			"namespace":  source.Namespace,
+			"deployment": source.Name,
+			"cluster":    clusterName,
+		})
+		return ClusterStatus{State: StateCreated}
+	case err != nil:
+		return p.failed(source, clusterName, fmt.Errorf("failed to get deployment: %w", err))
+	}
+
+	if _, propagatedBefore := existing.Annotations[SourceAnnotation]; !propagatedBefore {
+		return ClusterStatus{State: StateConflict, Error: fmt.Sprintf("deployment exists without the %s annotation and is left unchanged", SourceAnnotation)}
+	}
+	if inSync(existing, desired) {
+		return ClusterStatus{State: StateInSync}
+	}
+
+	updated := existing.DeepCopy()
+	updated.Labels = desired.Labels
+	updated.Annotations = desired.Annotations
+	for _, key := range ignoredAnnotations {
+		if value, exists := existing.Annotations[key]; exists {
+			updated.Annotations[key] = value
+		}
+	}
+	updated.Spec = desired.Spec
+	if _, err := deployments.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return p.failed(source, clusterName, fmt.Errorf("failed to update deployment: %w", err))
+	}
+	p.log.Info("Updated propagated deployment", map[string]interface{}{
+		"namespace":  source.Namespace,
+		"deployment": source.Name,
+		"cluster":    clusterName,
+	})
+	return ClusterStatus{State: StateUpdated}
+}
+
+// failed logs and reports a deployment that could not be propagated
+func (p *Propagator) failed(source *appsv1.Deployment, clusterName string, err error) ClusterStatus {
+	p.log.Warn("Failed to propagate deployment", map[string]interface{}{
+		"namespace":  source.Namespace,
+		"deployment": source.Name,
+		"cluster":    clusterName,
+		"error":      err.Error(),
+	})
+	return ClusterStatus{State: StateFailed, Error: err.Error()}
+}
+
+// RemoveCopies deletes the copies of a deployment propagated from the source
+// cluster from every other enabled cluster. Deployments of the same name
+// that were not propagated from source are left unchanged, and copies that
+// are already gone are ignored.
+func RemoveCopies(ctx context.Context, registry cluster.ClusterRegistry, source, namespace, name string) error {
+	log := logger.WithComponent("propagation")
+	var errs []error
+	for clusterName, client := range registry.GetEnabledClusters() {
+		if clusterName == source {
+			continue
+		}
+		removed, err := removeCopy(ctx, client, source, namespace, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", clusterName, err))
+			continue
+		}
+		if removed {
+			log.Info("Removed propagated deployment", map[string]interface{}{
+				"namespace":  namespace,
+				"deployment": name,
+				"cluster":    clusterName,
+			})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeCopy deletes the copy of a deployment propagated from source to a
+// member cluster, and reports whether there was one
+func removeCopy(ctx context.Context, client cluster.ClusterClient, source, namespace, name string) (bool, error) {
+	clientset, err := client.GetKubernetesClient()
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	deployments := clientset.AppsV1().Deployments(namespace)
+	existing, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to get deployment: %w", err)
+	case existing.Annotations[SourceAnnotation] != source:
+		return false, nil
+	}
+
+	// The precondition keeps a deployment recreated in the meantime
+	err = deployments.Delete(ctx, name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &existing.UID}})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete deployment: %w", err)
+	}
+	return true, nil
+}
+
+// listPropagated lists the deployments of the primary cluster labeled for
+// propagation, sorted by namespace and name
+func listPropagated(ctx context.Context, primary cluster.ClusterClient, namespaces []string) ([]appsv1.Deployment, error) {
+	clientset, err := primary.GetKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	var deployments []appsv1.Deployment
+	for _, namespace := range namespaces {
+		list, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: PropagateLabel + "=true"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments of the primary cluster: %w", err)
+		}
+		deployments = append(deployments, list.Items...)
+	}
+	sort.Slice(deployments, func(i, j int) bool {
+		a, b := deployments[i], deployments[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return deployments, nil
+}
+
+// propagated returns the deployment to create in a member cluster: the
+// source's name, labels, annotations and spec, marked with its origin
+func propagated(source *appsv1.Deployment, primary string) *appsv1.Deployment {
+	annotations := maps.Clone(source.Annotations)
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for _, key := range ignoredAnnotations {
+		delete(annotations, key)
+	}
+	annotations[SourceAnnotation] = primary
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   source.Namespace,
+			Name:        source.Name,
+			Labels:      maps.Clone(source.Labels),
+			Annotations: annotations,
+		},
+		Spec: *source.Spec.DeepCopy(),
+	}
+}
+
+// inSync reports whether a member's deployment matches the desired copy
+func inSync(existing, desired *appsv1.Deployment) bool {
+	annotations := maps.Clone(existing.Annotations)
+	for _, key := range ignoredAnnotations {
+		delete(annotations, key)
+	}
+	return drift.SpecHash(existing) == drift.SpecHash(desired) &&
+		replicas(existing) == replicas(desired) &&
+		maps.Equal(existing.Labels, desired.Labels) &&
+		maps.Equal(annotations, desired.Annotations)
+}
+
+// replicas returns the desired replica count, which defaults to 1
+func replicas(dep *appsv1.Deployment) int32 {
+	if dep.Spec.Replicas == nil {
+		return 1
+	}
+	return *dep.Spec.Replicas
+}
+
+// isPrimary reports whether a cluster client is marked as primary
+func isPrimary(client cluster.ClusterClient) bool {
+	primary, ok := client.(interface{ IsPrimary() bool })
+	return ok && primary.IsPrimary()
+}
@@ -0,0 +1,176 @@
+package propagation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	k6stesting "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/testing"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func newDeployment(name, image string, replicas int32, labels map[string]string, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, Labels: labels, Annotations: annotations},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: image}}},
+			},
+		},
+	}
+}
+
+func TestPropagatorSync(t *testing.T) {
+	propagate := map[string]string{PropagateLabel: "true"}
+	primary := fake.NewSimpleClientset(
+		newDeployment("web", "nginx:1.26", 3, propagate, map[string]string{"deployment.kubernetes.io/revision": "4"}),
+		newDeployment("api", "api:2.0", 2, propagate, nil),
+		newDeployment("db", "postgres:16", 1, nil, nil),
+	)
+	// web was propagated before and is outdated, api was created by hand
+	member := fake.NewSimpleClientset(
+		newDeployment("web", "nginx:1.25", 3, propagate, map[string]string{SourceAnnotation: "prod"}),
+		newDeployment("api", "api:1.0", 2, nil, nil),
+	)
+	empty := fake.NewSimpleClientset()
+	registry := k6stesting.FakeRegistry{
+		"prod":    k6stesting.FakeCluster{Name: "prod", Primary: true, Clientset: primary},
+		"eu":      k6stesting.FakeCluster{Name: "eu", Clientset: member},
+		"staging": k6stesting.FakeCluster{Name: "staging", Clientset: empty},
+	}
+
+	propagator := NewPropagator(registry, config.PropagationConfig{Enabled: true})
+	if _, synced := propagator.Report(); synced {
+		t.Error("Expected no report before the first sync")
+	}
+
+	ctx := context.Background()
+	report := propagator.Sync(ctx)
+	if report.Primary != "prod" || report.Error != "" || len(report.Clusters) != 2 || report.Clusters[0] != "eu" {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	if len(report.Deployments) != 2 || report.Deployments[0].Name != "api" || report.Deployments[1].Name != "web" {
+		t.Fatalf("Expected api and web to be propagated, got %+v", report.Deployments)
+	}
+
+	want := map[string]map[string]State{
+		"api": {"eu": StateConflict, "staging": StateCreated},
+		"web": {"eu": StateUpdated, "staging": StateCreated},
+	}
+	for _, dep := range report.Deployments {
+		for clusterName, state := range want[dep.Name] {
+			if got := dep.Clusters[clusterName].State; got != state {
+				t.Errorf("Expected %s in %s to be %s, got %s", dep.Name, clusterName, state, got)
+			}
+		}
+	}
+	if report.Failed != 1 {
+		t.Errorf("Expected 1 conflict, got %d", report.Failed)
+	}
+
+	web, err := empty.AppsV1().Deployments("shop").Get(ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected web to be created in staging: %v", err)
+	}
+	if web.Annotations[SourceAnnotation] != "prod" || web.Annotations["deployment.kubernetes.io/revision"] != "" {
+		t.Errorf("Expected the source annotation without the revision, got %v", web.Annotations)
+	}
+	web, _ = member.AppsV1().Deployments("shop").Get(ctx, "web", metav1.GetOptions{})
+	if web.Spec.Template.Spec.Containers[0].Image != "nginx:1.26" {
+		t.Errorf("Expected web to be updated in eu, got %s", web.Spec.Template.Spec.Containers[0].Image)
+	}
+	api, _ := member.AppsV1().Deployments("shop").Get(ctx, "api", metav1.GetOptions{})
+	if api.Spec.Template.Spec.Containers[0].Image != "api:1.0" {
+		t.Error("Expected the hand-made api deployment to be left unchanged")
+	}
+
+	// Nothing changes on the next sync
+	report = propagator.Sync(ctx)
+	for _, dep := range report.Deployments {
+		for clusterName, status := range dep.Clusters {
+			if status.State != StateInSync && status.State != StateConflict {
+				t.Errorf("Expected %s in %s to be in sync, got %s", dep.Name, clusterName, status.State)
+			}
+		}
+	}
+
+	if stored, synced := propagator.Report(); !synced || stored.Failed != 1 {
+		t.Errorf("Expected the report to be stored, got %+v", stored)
+	}
+}
+
+func TestPropagatorWithoutPrimary(t *testing.T) {
+	registry := k6stesting.FakeRegistry{
+		"eu": k6stesting.FakeCluster{Name: "eu", Clientset: fake.NewSimpleClientset()},
+	}
+	report := NewPropagator(registry, config.PropagationConfig{Enabled: true}).Sync(context.Background())
+	if report.Error == "" || len(report.Deployments) != 0 {
+		t.Errorf("Expected an error without a primary cluster, got %+v", report)
+	}
+}
+
+func TestRemoveCopies(t *testing.T) {
+	copied := map[string]string{SourceAnnotation: "us"}
+	eu := fake.NewSimpleClientset(newDeployment("web", "nginx:1.26", 3, nil, copied))
+	ap := fake.NewSimpleClientset(newDeployment("web", "nginx:1.25", 1, nil, nil))
+	registry := k6stesting.FakeRegistry{
+		"us": k6stesting.FakeCluster{Name: "us", Primary: true, Clientset: fake.NewSimpleClientset()},
+		"eu": k6stesting.FakeCluster{Name: "eu", Clientset: eu},
+		"ap": k6stesting.FakeCluster{Name: "ap", Clientset: ap},
+		"sa": k6stesting.FakeCluster{Name: "sa", Clientset: fake.NewSimpleClientset()},
+	}
+
+	ctx := context.Background()
+	if err := RemoveCopies(ctx, registry, "us", "shop", "web"); err != nil {
+		t.Fatalf("RemoveCopies() error = %v", err)
+	}
+	if _, err := eu.AppsV1().Deployments("shop").Get(ctx, "web", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the copy in eu to be removed, got %v", err)
+	}
+	if _, err := ap.AppsV1().Deployments("shop").Get(ctx, "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the deployment not propagated to ap to be kept, got %v", err)
+	}
+}
+
+// fakeRecorders records the events of each cluster
+type fakeRecorders map[string]*record.FakeRecorder
+
+func (r fakeRecorders) Recorder(cluster string) record.EventRecorder {
+	if recorder, ok := r[cluster]; ok {
+		return recorder
+	}
+	return nil
+}
+
+func TestPropagatorEvents(t *testing.T) {
+	registry := k6stesting.FakeRegistry{
+		"us": k6stesting.FakeCluster{Name: "us", Primary: true, Clientset: fake.NewSimpleClientset(
+			newDeployment("web", "nginx:1.26", 3, map[string]string{PropagateLabel: "true"}, nil),
+		)},
+		"eu": k6stesting.FakeCluster{Name: "eu", Clientset: fake.NewSimpleClientset(newDeployment("web", "nginx:1.25", 1, nil, nil))},
+	}
+	recorders := fakeRecorders{"us": record.NewFakeRecorder(10)}
+	propagator := NewPropagator(registry, config.PropagationConfig{Enabled: true})
+	propagator.SetEventRecorders(recorders)
+
+	// The conflict is recorded once on the deployment of the primary cluster
+	propagator.Sync(context.Background())
+	propagator.Sync(context.Background())
+	close(recorders["us"].Events)
+	var events []string
+	for event := range recorders["us"].Events {
+		events = append(events, event)
+	}
+	if len(events) != 1 || !strings.HasPrefix(events[0], "Warning PropagationFailed Failed to propagate to cluster eu: ") {
+		t.Errorf("Expected one propagation failure event, got %v", events)
+	}
+}
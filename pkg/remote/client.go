@@ -0,0 +1,280 @@
+// Package remote is a client for the HTTP API of a running k6s server,
+// used by "k6s attach" to run CLI commands without a local kubeconfig.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/compliance"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/server"
+)
+
+// Client calls the API of a remote k6s server
+type Client struct {
+	baseURL *url.URL
+	token   string
+	cluster string
+	http    *http.Client
+}
+
+// APIError is an error response returned by the server
+type APIError struct {
+	StatusCode int
+	Err        string
+	Message    string
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s (HTTP %d)", e.Err, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s (HTTP %d)", e.Err, e.Message, e.StatusCode)
+}
+
+// NewClient creates a client for the server at serverURL. The token is sent as
+// a bearer token when set; cluster selects the cluster the server must serve.
+func NewClient(serverURL, token, cluster string) (*Client, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL %s: %w", serverURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid server URL %s: scheme must be http or https", serverURL)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %s: missing host", serverURL)
+	}
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+
+	return &Client{
+		baseURL: parsed,
+		token:   token,
+		cluster: cluster,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// ListDeployments lists deployments, in all namespaces when namespace is empty
+func (c *Client) ListDeployments(ctx context.Context, namespace string) ([]server.DeploymentResponse, error) {
+	query := url.Values{}
+	if namespace != "" {
+		query.Set("namespace", namespace)
+	}
+
+	var list server.DeploymentListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/deployments", query, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetDeployment returns a single deployment
+func (c *Client) GetDeployment(ctx context.Context, namespace, name string) (*server.DeploymentResponse, error) {
+	var deployment server.DeploymentResponse
+	path := fmt.Sprintf("/api/v1/deployments/%s/%s", url.PathEscape(namespace), url.PathEscape(name))
+	if err := c.do(ctx, http.MethodGet, path, url.Values{}, nil, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// ScaleDeployment sets the replica count of a deployment
+func (c *Client) ScaleDeployment(ctx context.Context, namespace, name string, replicas int32) (*server.ScaleResponse, error) {
+	var result server.ScaleResponse
+	path := fmt.Sprintf("/api/v1/deployments/%s/%s/scale", url.PathEscape(namespace), url.PathEscape(name))
+	if err := c.do(ctx, http.MethodPut, path, url.Values{}, server.ScaleRequest{Replicas: &replicas}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ClusterStatus returns the health the server tracks for a configured cluster
+func (c *Client) ClusterStatus(ctx context.Context, name string) (*server.ClusterStatusResponse, error) {
+	var status server.ClusterStatusResponse
+	path := fmt.Sprintf("/api/v1/clusters/%s/status", url.PathEscape(name))
+	if err := c.do(ctx, http.MethodGet, path, url.Values{}, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// EventHistory queries the deployment events recorded by the server, most
+// recent first
+func (c *Client) EventHistory(ctx context.Context, q history.EventQuery) ([]history.Event, error) {
+	query := url.Values{}
+	if q.Namespace != "" {
+		query.Set("namespace", q.Namespace)
+	}
+	if q.Name != "" {
+		query.Set("name", q.Name)
+	}
+	if !q.Since.IsZero() {
+		query.Set("since", q.Since.UTC().Format(time.RFC3339))
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+
+	var list server.HistoryResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/history", query, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// Compliance returns the last image compliance scan of the server, keeping
+// the findings of namespace, when set, of at least severity
+func (c *Client) Compliance(ctx context.Context, namespace, severity string) (*compliance.Report, error) {
+	query := url.Values{}
+	if namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	if severity != "" {
+		query.Set("severity", severity)
+	}
+
+	var report compliance.Report
+	if err := c.do(ctx, http.MethodGet, "/api/v1/compliance", query, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Watch streams deployment events over a WebSocket, calling fn for each event
+// until the context is cancelled or the connection fails
+func (c *Client) Watch(ctx context.Context, namespace, labelSelector string, fn func(server.DeploymentEvent)) error {
+	query := url.Values{}
+	if namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+
+	wsURL := c.url("/api/v1/deployments/ws", query)
+	if wsURL.Scheme == "https" {
+		wsURL.Scheme = "wss"
+	} else {
+		wsURL.Scheme = "ws"
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL.String(), c.header())
+	if err != nil {
+		if resp != nil {
+			defer resp.Body.Close()
+			return decodeError(resp)
+		}
+		return fmt.Errorf("failed to connect to %s: %w", c.baseURL.Redacted(), err)
+	}
+	defer conn.Close()
+
+	// Unblock reads when the caller is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var msg server.WSServerMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+			return fmt.Errorf("watch connection failed: %w", err)
+		}
+
+		switch msg.Type {
+		case server.WSMessageEvent:
+			if msg.Event != nil {
+				fn(*msg.Event)
+			}
+		case server.WSMessageError:
+			return fmt.Errorf("server error: %s", msg.Error)
+		}
+	}
+}
+
+// do sends a JSON request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, query).String(), reader)
+	if err != nil {
+		return err
+	}
+	req.Header = c.header()
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", c.baseURL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// url builds a request URL, adding the cluster selector when set
+func (c *Client) url(path string, query url.Values) *url.URL {
+	if c.cluster != "" {
+		query.Set("cluster", c.cluster)
+	}
+	u := *c.baseURL
+	u.Path = c.baseURL.Path + path
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+// header returns the request headers, including the bearer token when set
+func (c *Client) header() http.Header {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	return header
+}
+
+// decodeError converts an error response into an APIError
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Err: http.StatusText(resp.StatusCode)}
+
+	var body server.ErrorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&body); err == nil && body.Error != "" {
+		apiErr.Err = body.Error
+		apiErr.Message = body.Message
+	}
+	return apiErr
+}
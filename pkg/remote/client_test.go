@@ -0,0 +1,136 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/server"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+// startServer serves a k6s API backed by a fake clientset and returns its URL
+func startServer(t *testing.T) string {
+	t.Helper()
+
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", Labels: map[string]string{"app": "web"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+	})
+	informer := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("informer.Start() error = %v", err)
+	}
+	t.Cleanup(informer.Stop)
+
+	cfg := config.DefaultConfig().Server
+	cfg.ClusterName = "prod-eu"
+	cfg.Auth.Tokens = []config.APIToken{{Name: "ci", Token: "0123456789abcdef", Role: "admin"}}
+	srv := server.NewWithConfig(cfg)
+	srv.SetDeploymentInformer(informer)
+	srv.SetDeploymentClient(kubernetes.NewClientWithClientset(clientset))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		_ = fasthttp.Serve(ln, srv.Handler())
+	}()
+
+	return "http://" + ln.Addr().String()
+}
+
+func TestClient(t *testing.T) {
+	serverURL := startServer(t)
+	ctx := context.Background()
+
+	client, err := NewClient(serverURL, "0123456789abcdef", "prod-eu")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	deployments, err := client.ListDeployments(ctx, "prod")
+	if err != nil {
+		t.Fatalf("ListDeployments() error = %v", err)
+	}
+	if len(deployments) != 1 || deployments[0].Name != "web" {
+		t.Fatalf("Expected prod/web, got %+v", deployments)
+	}
+
+	events := make(chan server.DeploymentEvent, 1)
+	watchCtx, cancel := context.WithCancel(ctx)
+	watchDone := make(chan error, 1)
+	go func() {
+		watchDone <- client.Watch(watchCtx, "prod", "app=web", func(event server.DeploymentEvent) {
+			select {
+			case events <- event:
+			default:
+			}
+		})
+	}()
+	// Give the watch time to subscribe before changing the deployment
+	time.Sleep(200 * time.Millisecond)
+
+	result, err := client.ScaleDeployment(ctx, "prod", "web", 5)
+	if err != nil {
+		t.Fatalf("ScaleDeployment() error = %v", err)
+	}
+	if result.Replicas != 5 {
+		t.Errorf("Expected 5 replicas, got %d", result.Replicas)
+	}
+
+	select {
+	case event := <-events:
+		if event.Deployment.Name != "web" || event.Deployment.Replicas != 5 {
+			t.Errorf("Expected update event for scaled web, got %+v", event.Deployment)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for watch event")
+	}
+	cancel()
+	if err := <-watchDone; err != nil {
+		t.Errorf("Watch() error = %v", err)
+	}
+
+	if _, err := client.GetDeployment(ctx, "prod", "missing"); !isStatus(err, 404) {
+		t.Errorf("Expected 404 for missing deployment, got %v", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	serverURL := startServer(t)
+	ctx := context.Background()
+
+	unauthorized, _ := NewClient(serverURL, "wrong", "")
+	if _, err := unauthorized.ListDeployments(ctx, ""); !isStatus(err, 401) {
+		t.Errorf("Expected 401 for invalid token, got %v", err)
+	}
+	if err := unauthorized.Watch(ctx, "", "", func(server.DeploymentEvent) {}); !isStatus(err, 401) {
+		t.Errorf("Expected 401 for unauthorized watch, got %v", err)
+	}
+
+	otherCluster, _ := NewClient(serverURL, "0123456789abcdef", "prod-us")
+	if _, err := otherCluster.ListDeployments(ctx, ""); !isStatus(err, 404) {
+		t.Errorf("Expected 404 for another cluster, got %v", err)
+	}
+
+	if _, err := NewClient("ftp://k6s.example", "", ""); err == nil {
+		t.Error("Expected error for unsupported scheme")
+	}
+}
+
+func isStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
@@ -0,0 +1,180 @@
+// Package rollout scores the rollout health of deployments and detects
+// rollouts that stopped progressing.
+package rollout
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Rollout health statuses
+const (
+	// The rollout is complete and every replica is available
+	StatusHealthy = "Healthy"
+
+	// A rollout is in progress, within its progress deadline
+	StatusProgressing = "Progressing"
+
+	// The rollout is complete, but replicas are unavailable or pods crash-loop
+	StatusDegraded = "Degraded"
+
+	// The rollout stopped progressing
+	StatusStuck = "Stuck"
+
+	// The rollout is paused
+	StatusPaused = "Paused"
+)
+
+// defaultProgressDeadline is the progress deadline of deployments that don't
+// set progressDeadlineSeconds, as defaulted by the API server
+const defaultProgressDeadline = 600 * time.Second
+
+// newReplicaSetAvailable is the reason of the Progressing condition of
+// deployments whose last rollout completed
+const newReplicaSetAvailable = "NewReplicaSetAvailable"
+
+// crashLoopReason is the waiting reason of containers restarted with backoff
+const crashLoopReason = "CrashLoopBackOff"
+
+// Health is the rollout health of a deployment. Score goes from 0 to 100:
+// 60 points for the share of desired replicas available, 20 for the share
+// updated to the current revision and 20 for the share of pods of that
+// revision that aren't crash-looping; a stuck rollout halves it.
+type Health struct {
+	Namespace               string     `json:"namespace"`
+	Name                    string     `json:"name"`
+	Status                  string     `json:"status"`
+	Score                   int        `json:"score"`
+	Reason                  string     `json:"reason,omitempty"`
+	Desired                 int32      `json:"desired"`
+	Updated                 int32      `json:"updated"`
+	Available               int32      `json:"available"`
+	Unavailable             int32      `json:"unavailable"`
+	CrashLoopingPods        []string   `json:"crashLoopingPods,omitempty"`
+	NotReadySince           *time.Time `json:"notReadySince,omitempty"`
+	ProgressDeadlineSeconds int32      `json:"progressDeadlineSeconds"`
+}
+
+// Ready reports whether the rollout of a deployment is complete with every
+// replica available
+func Ready(dep *appsv1.Deployment) bool {
+	_, complete, err := kubernetes.RolloutStatus(dep)
+	return err == nil && complete && dep.Status.UnavailableReplicas == 0
+}
+
+// Evaluate returns the rollout health of a deployment at now. newPods are the
+// pods of its current revision, nil when pods are not cached; notReadySince
+// is when the deployment was first seen not Ready, zero while it is. A
+// rollout is stuck once it exceeded its progress deadline, when pods of its
+// new revision crash-loop, or when it wasn't Ready for longer than the
+// progress deadline.
+func Evaluate(dep *appsv1.Deployment, newPods []*corev1.Pod, notReadySince, now time.Time) Health {
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+	deadline := defaultProgressDeadline
+	if dep.Spec.ProgressDeadlineSeconds != nil {
+		deadline = time.Duration(*dep.Spec.ProgressDeadlineSeconds) * time.Second
+	}
+	health := Health{
+		Namespace:               dep.Namespace,
+		Name:                    dep.Name,
+		Desired:                 desired,
+		Updated:                 dep.Status.UpdatedReplicas,
+		Available:               dep.Status.AvailableReplicas,
+		Unavailable:             dep.Status.UnavailableReplicas,
+		ProgressDeadlineSeconds: int32(deadline / time.Second),
+	}
+	if !notReadySince.IsZero() {
+		since := notReadySince
+		health.NotReadySince = &since
+	}
+	for _, pod := range newPods {
+		if crashLooping(pod) {
+			health.CrashLoopingPods = append(health.CrashLoopingPods, pod.Name)
+		}
+	}
+	crashing := len(health.CrashLoopingPods)
+
+	// A completed rollout losing replicas is degraded rather than in progress
+	_, complete, err := kubernetes.RolloutStatus(dep)
+	rolledOut := complete || (dep.Status.UpdatedReplicas >= desired && rolloutCompleted(dep))
+	switch {
+	case dep.Spec.Paused:
+		health.Status = StatusPaused
+		health.Reason = "rollout is paused"
+	case errors.Is(err, kubernetes.ErrProgressDeadlineExceeded):
+		health.Status = StatusStuck
+		health.Reason = fmt.Sprintf("exceeded its progress deadline of %s", deadline)
+	case !rolledOut && crashing > 0:
+		health.Status = StatusStuck
+		health.Reason = fmt.Sprintf("%d pods of the new revision are crash-looping", crashing)
+	case !notReadySince.IsZero() && now.Sub(notReadySince) > deadline:
+		health.Status = StatusStuck
+		health.Reason = fmt.Sprintf("%d replicas unavailable for longer than the progress deadline of %s", health.Unavailable, deadline)
+	case !rolledOut:
+		health.Status = StatusProgressing
+	case health.Unavailable > 0 || health.Available < desired:
+		health.Status = StatusDegraded
+		health.Reason = fmt.Sprintf("%d of %d replicas available", health.Available, desired)
+	case crashing > 0:
+		health.Status = StatusDegraded
+		health.Reason = fmt.Sprintf("%d pods are crash-looping", crashing)
+	default:
+		health.Status = StatusHealthy
+	}
+
+	health.Score = score(health, len(newPods))
+	return health
+}
+
+// rolloutCompleted reports whether the deployment controller marked the last
+// rollout of a deployment as completed
+func rolloutCompleted(dep *appsv1.Deployment) bool {
+	for _, condition := range dep.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing {
+			return condition.Reason == newReplicaSetAvailable
+		}
+	}
+	return false
+}
+
+// score returns the score of a deployment's health, with pods of its
+// current revision
+func score(health Health, pods int) int {
+	share := func(n, of int32) float64 {
+		if of <= 0 || n >= of {
+			return 1
+		}
+		if n <= 0 {
+			return 0
+		}
+		return float64(n) / float64(of)
+	}
+	notCrashing := 1.0
+	if pods > 0 {
+		notCrashing = 1 - float64(len(health.CrashLoopingPods))/float64(pods)
+	}
+	value := 60*share(health.Available, health.Desired) + 20*share(health.Updated, health.Desired) + 20*notCrashing
+	if health.Status == StatusStuck {
+		value /= 2
+	}
+	return int(value)
+}
+
+// crashLooping reports whether a container of a pod is restarted with backoff
+func crashLooping(pod *corev1.Pod) bool {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == crashLoopReason {
+			return true
+		}
+	}
+	return false
+}
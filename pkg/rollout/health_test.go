@@ -0,0 +1,104 @@
+package rollout
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newDeployment returns a deployment of replicas with its rollout complete
+func newDeployment(namespace, name string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          replicas,
+			UpdatedReplicas:   replicas,
+			ReadyReplicas:     replicas,
+			AvailableReplicas: replicas,
+		},
+	}
+}
+
+// newPod returns a pod, crash-looping when crashing is true
+func newPod(name string, crashing bool) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name}}
+	status := corev1.ContainerStatus{Name: "app", Ready: !crashing}
+	if crashing {
+		status.State.Waiting = &corev1.ContainerStateWaiting{Reason: crashLoopReason}
+	}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{status}
+	return pod
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	rolling := newDeployment("shop", "web", 4)
+	rolling.Status.UpdatedReplicas = 2
+	rolling.Status.AvailableReplicas = 3
+	rolling.Status.UnavailableReplicas = 1
+
+	exceeded := newDeployment("shop", "web", 2)
+	exceeded.Status.UpdatedReplicas = 1
+	exceeded.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"}}
+
+	degraded := newDeployment("shop", "web", 2)
+	degraded.Status.AvailableReplicas = 1
+	degraded.Status.UnavailableReplicas = 1
+	degraded.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Reason: newReplicaSetAvailable}}
+
+	paused := newDeployment("shop", "web", 2)
+	paused.Spec.Paused = true
+	paused.Status.UpdatedReplicas = 1
+
+	tests := []struct {
+		name          string
+		dep           *appsv1.Deployment
+		pods          []*corev1.Pod
+		notReadySince time.Time
+		status        string
+		score         int
+	}{
+		{"complete", newDeployment("shop", "web", 2), []*corev1.Pod{newPod("web-a", false), newPod("web-b", false)}, time.Time{}, StatusHealthy, 100},
+		{"rolling out", rolling, nil, now.Add(-time.Minute), StatusProgressing, 75},
+		{"deadline exceeded", exceeded, nil, now.Add(-time.Minute), StatusStuck, 45},
+		{"new pods crash-looping", rolling, []*corev1.Pod{newPod("web-a", true), newPod("web-b", false)}, now.Add(-time.Minute), StatusStuck, 32},
+		{"not ready past the deadline", degraded, nil, now.Add(-11 * time.Minute), StatusStuck, 35},
+		{"replicas unavailable", degraded, nil, now.Add(-time.Minute), StatusDegraded, 70},
+		{"complete with crash-looping pods", newDeployment("shop", "web", 2), []*corev1.Pod{newPod("web-a", true), newPod("web-b", false)}, time.Time{}, StatusDegraded, 90},
+		{"paused", paused, nil, time.Time{}, StatusPaused, 90},
+	}
+	for _, tt := range tests {
+		health := Evaluate(tt.dep, tt.pods, tt.notReadySince, now)
+		if health.Status != tt.status || health.Score != tt.score {
+			t.Errorf("%s: Evaluate() = %s scored %d (%s), want %s scored %d", tt.name, health.Status, health.Score, health.Reason, tt.status, tt.score)
+		}
+	}
+}
+
+func TestFilterAndParseStatus(t *testing.T) {
+	report := Report{Deployments: []Health{
+		{Namespace: "shop", Name: "api", Status: StatusStuck},
+		{Namespace: "shop", Name: "web", Status: StatusHealthy},
+		{Namespace: "ops", Name: "cron", Status: StatusStuck},
+	}}
+	status, err := ParseStatus("stuck")
+	if err != nil || status != StatusStuck {
+		t.Fatalf("ParseStatus(stuck) = %q, %v", status, err)
+	}
+	if _, err := ParseStatus("broken"); err == nil {
+		t.Error("Expected an error for an unknown status")
+	}
+
+	filtered := Filter(report, "shop", status)
+	if len(filtered.Deployments) != 1 || filtered.Deployments[0].Name != "api" || filtered.Stuck != 1 {
+		t.Errorf("Expected the stuck api deployment of shop, got %+v", filtered)
+	}
+	if all := Filter(report, "", ""); len(all.Deployments) != 3 || all.Stuck != 2 {
+		t.Errorf("Expected every deployment without filters, got %+v", all)
+	}
+}
@@ -0,0 +1,358 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/notify"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// defaultInterval is used when no interval is configured
+const defaultInterval = 30 * time.Second
+
+// Report is the rollout health of the cached deployments, sorted by
+// namespace and name
+type Report struct {
+	CheckedAt   time.Time `json:"checkedAt"`
+	Deployments []Health  `json:"deployments"`
+	Progressing int       `json:"progressing"`
+	Degraded    int       `json:"degraded"`
+	Stuck       int       `json:"stuck"`
+	Errors      []string  `json:"errors,omitempty"`
+}
+
+// count tallies the deployments of a report by status
+func (r *Report) count() {
+	r.Progressing, r.Degraded, r.Stuck = 0, 0, 0
+	for _, health := range r.Deployments {
+		switch health.Status {
+		case StatusProgressing:
+			r.Progressing++
+		case StatusDegraded:
+			r.Degraded++
+		case StatusStuck:
+			r.Stuck++
+		}
+	}
+}
+
+// Filter returns the deployments of a report in namespace with status; empty
+// values match every namespace and status
+func Filter(report Report, namespace, status string) Report {
+	deployments := []Health{}
+	for _, health := range report.Deployments {
+		if (namespace == "" || health.Namespace == namespace) && (status == "" || health.Status == status) {
+			deployments = append(deployments, health)
+		}
+	}
+	report.Deployments = deployments
+	report.count()
+	return report
+}
+
+// ParseStatus returns the status named by s, ignoring case; empty for an
+// empty s
+func ParseStatus(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, status := range []string{StatusHealthy, StatusProgressing, StatusDegraded, StatusStuck, StatusPaused} {
+		if strings.EqualFold(s, status) {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("invalid status %q: must be Healthy, Progressing, Degraded, Stuck or Paused", s)
+}
+
+// Tracker periodically scores the rollout health of the cached deployments,
+// and warns when a rollout gets stuck: it logs a warning, records a
+// RolloutStuck event on the deployment and delivers a rollout-stuck
+// notification
+type Tracker struct {
+	deployments *kubernetes.DeploymentInformer
+	log         *logger.Logger
+
+	mu      sync.RWMutex
+	cfg     config.RolloutHealthConfig
+	report  Report
+	checked bool
+
+	// Pods of the current revision of deployments are looked up in these
+	// caches; nil when pods can't be cached
+	pods        *kubernetes.PodInformer
+	replicaSets *kubernetes.ReplicaSetInformer
+
+	// When deployments were first seen not Ready, keyed by namespace/name
+	notReadySince map[string]time.Time
+
+	// Deployments alarmed on, keyed by namespace/name
+	alarms map[string]bool
+
+	// Records an event on stuck deployments, nil to only log
+	recorder record.EventRecorder
+
+	// Delivers rollout-stuck notifications, nil to only log
+	notifier *notify.Notifier
+	cluster  string
+}
+
+// NewTracker creates a tracker of the deployments of informer
+func NewTracker(deployments *kubernetes.DeploymentInformer, cfg config.RolloutHealthConfig) *Tracker {
+	return &Tracker{
+		deployments:   deployments,
+		cfg:           cfg,
+		notReadySince: make(map[string]time.Time),
+		alarms:        make(map[string]bool),
+		log:           logger.WithComponent("rollout"),
+	}
+}
+
+// SetConfig replaces the interval, taking effect at the next check
+func (t *Tracker) SetConfig(cfg config.RolloutHealthConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg = cfg
+	if !cfg.Enabled {
+		t.checked = false
+	}
+}
+
+// SetPodInformers sets the started caches the pods of the current revision
+// of deployments are looked up in. It must be called before Start, which
+// otherwise shares the caches of the deployment informer's set.
+func (t *Tracker) SetPodInformers(pods *kubernetes.PodInformer, replicaSets *kubernetes.ReplicaSetInformer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pods, t.replicaSets = pods, replicaSets
+}
+
+// SetEventRecorder records a warning event on each deployment whose rollout
+// gets stuck. It must be called before Start.
+func (t *Tracker) SetEventRecorder(recorder record.EventRecorder) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recorder = recorder
+}
+
+// SetNotifier delivers a rollout-stuck notification, reported for cluster,
+// for each deployment whose rollout gets stuck. It must be called before
+// Start.
+func (t *Tracker) SetNotifier(notifier *notify.Notifier, cluster string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.notifier, t.cluster = notifier, cluster
+}
+
+// Enabled reports whether rollout health tracking is enabled
+func (t *Tracker) Enabled() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.cfg.Enabled
+}
+
+// Report returns the result of the last check, and false before the first
+// one or while tracking is disabled
+func (t *Tracker) Report() (Report, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.report, t.checked
+}
+
+// Start checks the rollouts every interval while tracking is enabled, until
+// ctx is cancelled
+func (t *Tracker) Start(ctx context.Context) {
+	t.startPodInformers(ctx)
+
+	for {
+		t.mu.RLock()
+		enabled, interval := t.cfg.Enabled, t.cfg.Interval
+		t.mu.RUnlock()
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+
+		if enabled && t.deployments.HasSynced() {
+			t.Check()
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// startPodInformers starts pod and replica set caches sharing the watches of
+// the deployment informer's set, unless they were set, and stops them when
+// ctx is done. Without permission to list them, crash-looping pods are not
+// detected.
+func (t *Tracker) startPodInformers(ctx context.Context) {
+	t.mu.RLock()
+	started := t.pods != nil
+	t.mu.RUnlock()
+	if started {
+		return
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	set := t.deployments.InformerSet()
+	pods := kubernetes.NewPodInformerFor(set)
+	if err := pods.Start(syncCtx); err != nil {
+		t.log.Warn("Crash-looping pods not detected", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	replicaSets := kubernetes.NewReplicaSetInformerFor(set)
+	if err := replicaSets.Start(syncCtx); err != nil {
+		pods.Stop()
+		t.log.Warn("Crash-looping pods not detected", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		replicaSets.Stop()
+		pods.Stop()
+	}()
+	t.SetPodInformers(pods, replicaSets)
+}
+
+// Check scores the rollouts of the cached deployments once, raises alarms
+// for newly stuck ones and stores the report
+func (t *Tracker) Check() Report {
+	now := time.Now().UTC()
+	report := Report{CheckedAt: now, Deployments: []Health{}}
+	deployments, err := t.deployments.ListDeployments()
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("listing deployments: %v", err))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	live := make(map[string]*appsv1.Deployment, len(deployments))
+	notReadySince := make(map[string]time.Time)
+	for _, dep := range deployments {
+		key := dep.Namespace + "/" + dep.Name
+		live[key] = dep
+		if !Ready(dep) && !dep.Spec.Paused {
+			since, seen := t.notReadySince[key]
+			if !seen {
+				since = now
+			}
+			notReadySince[key] = since
+		}
+
+		newPods, err := t.newPods(dep)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("deployment %s: %v", key, err))
+		}
+		report.Deployments = append(report.Deployments, Evaluate(dep, newPods, notReadySince[key], now))
+	}
+	sort.Slice(report.Deployments, func(i, j int) bool {
+		a, b := report.Deployments[i], report.Deployments[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	report.count()
+
+	t.notReadySince = notReadySince
+	t.raiseAlarms(report, live)
+	t.report = report
+	t.checked = true
+	return report
+}
+
+// newPods returns the cached pods of the current revision of a deployment,
+// nil when pods are not cached. The caller must hold t.mu.
+func (t *Tracker) newPods(dep *appsv1.Deployment) ([]*corev1.Pod, error) {
+	if t.pods == nil || t.replicaSets == nil {
+		return nil, nil
+	}
+	revisions, err := t.replicaSets.Revisions(dep)
+	if err != nil {
+		return nil, err
+	}
+	current := ""
+	for _, revision := range revisions {
+		if revision.Current {
+			current = revision.ReplicaSet
+		}
+	}
+	if current == "" {
+		return nil, nil
+	}
+
+	pods, err := t.pods.ListPods(dep.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var owned []*corev1.Pod
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "ReplicaSet" && owner.Name == current {
+			owned = append(owned, pod)
+		}
+	}
+	return owned, nil
+}
+
+// raiseAlarms warns of deployments whose rollout got stuck since the previous
+// check, and logs those progressing again. The caller must hold t.mu.
+func (t *Tracker) raiseAlarms(report Report, live map[string]*appsv1.Deployment) {
+	current := make(map[string]bool)
+	for _, health := range report.Deployments {
+		if health.Status != StatusStuck {
+			continue
+		}
+		key := health.Namespace + "/" + health.Name
+		current[key] = true
+		if t.alarms[key] {
+			continue
+		}
+		t.log.Warn("Deployment rollout stuck", map[string]interface{}{
+			"namespace":  health.Namespace,
+			"deployment": health.Name,
+			"reason":     health.Reason,
+			"score":      health.Score,
+		})
+		dep := live[key]
+		if t.recorder != nil {
+			t.recorder.Eventf(dep, corev1.EventTypeWarning, kubernetes.ReasonRolloutStuck, "Rollout stuck: %s", health.Reason)
+		}
+		if t.notifier != nil {
+			t.notifier.Notify(notify.Event{
+				Type:      notify.EventRolloutStuck,
+				Source:    notify.SourceRollout,
+				Cluster:   t.cluster,
+				Namespace: health.Namespace,
+				Name:      health.Name,
+				Labels:    dep.Labels,
+				Message:   health.Reason,
+			})
+		}
+	}
+
+	for key := range t.alarms {
+		if !current[key] {
+			t.log.Info("Deployment rollout no longer stuck", map[string]interface{}{
+				"deployment": key,
+			})
+		}
+	}
+	t.alarms = current
+}
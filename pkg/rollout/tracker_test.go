@@ -0,0 +1,85 @@
+package rollout
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTrackerCheck(t *testing.T) {
+	web := newDeployment("shop", "web", 2)
+	web.UID = types.UID("web-uid")
+	web.Annotations = map[string]string{"deployment.kubernetes.io/revision": "2"}
+	web.Status.UpdatedReplicas = 1
+	api := newDeployment("shop", "api", 1)
+
+	controller := true
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "shop",
+		Name:            "web-2",
+		Annotations:     map[string]string{"deployment.kubernetes.io/revision": "2"},
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: web.UID, Controller: &controller}},
+	}}
+	crashing := newPod("web-2-a", true)
+	crashing.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-2", Controller: &controller}}
+	// Pods of the previous revision don't count
+	old := newPod("web-1-a", true)
+	old.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-1", Controller: &controller}}
+
+	deployments := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(web, api, rs, crashing, old), "", time.Minute)
+	tracker := NewTracker(deployments, config.RolloutHealthConfig{Enabled: true})
+	recorder := record.NewFakeRecorder(10)
+	tracker.SetEventRecorder(recorder)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracker.startPodInformers(ctx)
+
+	if _, checked := tracker.Report(); checked {
+		t.Error("Expected no report before the first check")
+	}
+	report := tracker.Check()
+	if len(report.Deployments) != 2 || report.Stuck != 1 {
+		t.Fatalf("Expected web to be stuck, got %+v", report)
+	}
+	health := report.Deployments[1]
+	if health.Name != "web" || health.Status != StatusStuck || len(health.CrashLoopingPods) != 1 || health.CrashLoopingPods[0] != "web-2-a" {
+		t.Errorf("Expected web stuck on its crash-looping new pod, got %+v", health)
+	}
+	if report.Deployments[0].Status != StatusHealthy || report.Deployments[0].Score != 100 {
+		t.Errorf("Expected api to be healthy, got %+v", report.Deployments[0])
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "RolloutStuck") {
+			t.Errorf("Expected a RolloutStuck event, got %q", event)
+		}
+	default:
+		t.Error("Expected a RolloutStuck event")
+	}
+
+	// The alarm is only raised once
+	tracker.Check()
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("Expected no event for a rollout still stuck, got %q", event)
+	default:
+	}
+
+	tracker.SetConfig(config.RolloutHealthConfig{})
+	if _, checked := tracker.Report(); checked {
+		t.Error("Expected no report once tracking is disabled")
+	}
+}
@@ -0,0 +1,128 @@
+package security
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Role is the set of API endpoints a caller may use
+type Role string
+
+const (
+	// RoleViewer may read every endpoint and preview changes
+	RoleViewer Role = "viewer"
+	// RoleOperator may also create, scale and delete deployments
+	RoleOperator Role = "operator"
+	// RoleAdmin may use every endpoint, including cluster management
+	RoleAdmin Role = "admin"
+)
+
+// rank orders roles; each role has the permissions of the lower ones
+var rank = map[Role]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ParseRole parses a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := rank[role]; !ok {
+		return "", fmt.Errorf("unknown role %q (use viewer, operator or admin)", name)
+	}
+	return role, nil
+}
+
+// permission allows a method on a route template of the HTTP API, as
+// reported in logs and metrics; "*" matches any
+type permission struct {
+	method string
+	route  string
+}
+
+// permissions lists what each role adds to the roles below it
+var permissions = map[Role][]permission{
+	RoleViewer: {
+		{"GET", "*"},
+		{"HEAD", "*"},
+		// Previews don't change the cluster
+		{"POST", "/api/v1/batch/scale/preview"},
+	},
+	RoleOperator: {
+		{"POST", "/api/v1/deployments"},
+		{"DELETE", "/api/v1/deployments/{namespace}/{name}"},
+		{"PUT", "/api/v1/deployments/{namespace}/{name}/scale"},
+	},
+	RoleAdmin: {
+		{"*", "*"},
+	},
+}
+
+// Allows reports whether role may call method on route
+func Allows(role Role, method, route string) bool {
+	for r, perms := range permissions {
+		if rank[r] > rank[role] {
+			continue
+		}
+		for _, p := range perms {
+			if (p.method == "*" || p.method == method) && (p.route == "*" || p.route == route) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Identity is an authenticated API caller
+type Identity struct {
+	Name   string
+	Groups []string
+
+	// Role carried by the credential itself, such as a configured token
+	// (empty = resolved from bindings)
+	Role Role
+}
+
+// Binding grants a role to users and groups
+type Binding struct {
+	Role   Role
+	Users  []string
+	Groups []string
+}
+
+// Authorizer decides which API requests authenticated callers may make
+type Authorizer struct {
+	bindings    []Binding
+	defaultRole Role
+}
+
+// NewAuthorizer creates an authorizer granting roles through bindings, and
+// defaultRole to callers without a role or binding
+func NewAuthorizer(bindings []Binding, defaultRole Role) *Authorizer {
+	return &Authorizer{bindings: bindings, defaultRole: defaultRole}
+}
+
+// RoleOf returns the role of a caller: the role of its credential, or else
+// the highest role bound to its name or one of its groups, or else the
+// default role
+func (a *Authorizer) RoleOf(id Identity) Role {
+	if id.Role != "" {
+		return id.Role
+	}
+
+	var role Role
+	for _, binding := range a.bindings {
+		bound := slices.Contains(binding.Users, id.Name) ||
+			slices.ContainsFunc(binding.Groups, func(group string) bool { return slices.Contains(id.Groups, group) })
+		if bound && rank[binding.Role] > rank[role] {
+			role = binding.Role
+		}
+	}
+	if role == "" {
+		return a.defaultRole
+	}
+	return role
+}
+
+// Authorize returns the role of a caller and whether it may call method on
+// route
+func (a *Authorizer) Authorize(id Identity, method, route string) (Role, bool) {
+	role := a.RoleOf(id)
+	return role, Allows(role, method, route)
+}
@@ -0,0 +1,54 @@
+package security
+
+import "testing"
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		role   Role
+		method string
+		route  string
+		want   bool
+	}{
+		{RoleViewer, "GET", "/api/v1/deployments", true},
+		{RoleViewer, "POST", "/api/v1/batch/scale/preview", true},
+		{RoleViewer, "PUT", "/api/v1/deployments/{namespace}/{name}/scale", false},
+		{RoleOperator, "GET", "/api/v1/clusters", true},
+		{RoleOperator, "PUT", "/api/v1/deployments/{namespace}/{name}/scale", true},
+		{RoleOperator, "POST", "/api/v1/clusters", false},
+		{RoleAdmin, "DELETE", "/api/v1/clusters/{name}", true},
+		{Role("unknown"), "GET", "/api/v1/deployments", false},
+	}
+	for _, tt := range tests {
+		if got := Allows(tt.role, tt.method, tt.route); got != tt.want {
+			t.Errorf("Allows(%s, %s, %s) = %v, want %v", tt.role, tt.method, tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizerRoleOf(t *testing.T) {
+	authorizer := NewAuthorizer([]Binding{
+		{Role: RoleOperator, Groups: []string{"deployers"}},
+		{Role: RoleAdmin, Users: []string{"alice"}},
+	}, RoleViewer)
+
+	tests := []struct {
+		name string
+		id   Identity
+		want Role
+	}{
+		{"credential role", Identity{Name: "alice", Role: RoleViewer}, RoleViewer},
+		{"bound user", Identity{Name: "alice"}, RoleAdmin},
+		{"bound group", Identity{Name: "bob", Groups: []string{"deployers"}}, RoleOperator},
+		{"highest binding", Identity{Name: "alice", Groups: []string{"deployers"}}, RoleAdmin},
+		{"default role", Identity{Name: "carol"}, RoleViewer},
+	}
+	for _, tt := range tests {
+		if got := authorizer.RoleOf(tt.id); got != tt.want {
+			t.Errorf("%s: RoleOf() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := ParseRole("root"); err == nil {
+		t.Error("Expected an unknown role to be rejected")
+	}
+}
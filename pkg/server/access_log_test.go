@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/valyala/fasthttp"
+)
+
+func TestAccessLoggerWritesFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "access.log")
+	al, err := NewAccessLogger(config.AccessLogConfig{
+		Enabled:        true,
+		File:           file,
+		HashPrincipals: true,
+		HashKey:        "0123456789abcdef",
+		ExcludeHealth:  true,
+	})
+	if err != nil {
+		t.Fatalf("NewAccessLogger() error = %v", err)
+	}
+
+	handler := al.Middleware(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	for _, path := range []string{"/health", "/api/v1/deployments/prod/web"} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(path)
+		ctx.Request.Header.SetMethod("GET")
+		ctx.SetUserValue(principalKey, "alice")
+		handler(ctx)
+	}
+
+	if err := al.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("failed to open access log: %v", err)
+	}
+	defer f.Close()
+
+	var entries []AccessLogEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AccessLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid access log line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry (health excluded), got %d", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Route != "/api/v1/deployments/{namespace}/{name}" {
+		t.Errorf("Expected templated route, got %s", entry.Route)
+	}
+	if entry.Status != fasthttp.StatusOK {
+		t.Errorf("Expected status 200, got %d", entry.Status)
+	}
+	if entry.Principal == "alice" || !strings.HasPrefix(entry.Principal, "hmac-sha256:") {
+		t.Errorf("Expected hashed principal, got %s", entry.Principal)
+	}
+	if entry.Principal != hashPrincipal([]byte("0123456789abcdef"), "alice") {
+		t.Errorf("Expected stable principal hash, got %s", entry.Principal)
+	}
+	if entry.Principal == hashPrincipal([]byte("fedcba9876543210"), "alice") {
+		t.Error("Expected principal hashes to depend on the key")
+	}
+}
+
+func TestAccessLoggerGeneratesHashKey(t *testing.T) {
+	al, err := NewAccessLogger(config.AccessLogConfig{Enabled: true, HashPrincipals: true})
+	if err != nil {
+		t.Fatalf("NewAccessLogger() error = %v", err)
+	}
+	if len(al.hashKey) != 32 {
+		t.Errorf("Expected a generated 32-byte key, got %d bytes", len(al.hashKey))
+	}
+}
+
+func TestAccessLoggerRecordDuringClose(t *testing.T) {
+	al, err := NewAccessLogger(config.AccessLogConfig{
+		Enabled: true,
+		File:    filepath.Join(t.TempDir(), "access.log"),
+	})
+	if err != nil {
+		t.Fatalf("NewAccessLogger() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				al.Record(AccessLogEntry{Method: "GET", Route: "/api/v1/deployments", Status: fasthttp.StatusOK})
+			}
+		}()
+	}
+
+	if err := al.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	wg.Wait()
+}
+
+func TestRouteFor(t *testing.T) {
+	tests := map[string]string{
+		"/health":                            "/health",
+		"/metrics":                           "/metrics",
+		"/api/v1/deployments":                "/api/v1/deployments",
+		"/api/v1/deployments/watch":          "/api/v1/deployments/watch",
+		"/api/v1/deployments/ws":             "/api/v1/deployments/ws",
+		"/api/v1/deployments/web":            "/api/v1/deployments/{name}",
+		"/api/v1/deployments/prod/web":       "/api/v1/deployments/{namespace}/{name}",
+		"/api/v1/deployments/prod/web/scale": "/api/v1/deployments/{namespace}/{name}/scale",
+		"/something/else":                    "unmatched",
+	}
+
+	for path, expected := range tests {
+		if got := routeFor(path); got != expected {
+			t.Errorf("routeFor(%s) = %s, expected %s", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DeploymentGroup sums the deployments sharing a label value. Deployments
+// without the label, directly or propagated, are grouped under an empty value.
+type DeploymentGroup struct {
+	Value       string `json:"value"`
+	Deployments int    `json:"deployments"`
+	Replicas    int32  `json:"replicas"`
+	Ready       int32  `json:"ready"`
+	Available   int32  `json:"available"`
+}
+
+// DeploymentAggregateResponse represents deployments grouped by a label
+type DeploymentAggregateResponse struct {
+	GroupBy string            `json:"groupBy"`
+	Groups  []DeploymentGroup `json:"groups"`
+	Total   int               `json:"total"`
+}
+
+// handleAggregateDeployments handles GET /api/v1/deployments/aggregate
+func (dh *DeploymentHandler) handleAggregateDeployments(ctx *fasthttp.RequestCtx) {
+	if !dh.informer.IsStarted() || !dh.informer.HasSynced() {
+		dh.sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Deployment informer cache is not synced")
+		return
+	}
+
+	groupBy := string(ctx.QueryArgs().Peek("groupBy"))
+	if groupBy == "" {
+		dh.sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "groupBy is required")
+		return
+	}
+	if errs := validation.IsQualifiedName(groupBy); len(errs) > 0 {
+		dh.sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "invalid groupBy label key: "+errs[0])
+		return
+	}
+
+	opts, err := parseListOptions(ctx.QueryArgs())
+	if err != nil {
+		dh.sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+
+	if opts.index != "" && !dh.hasIndex(opts.index) {
+		dh.sendError(ctx, fasthttp.StatusBadRequest, "Bad request", dh.unknownIndexMessage(opts.index))
+		return
+	}
+
+	deployments, err := opts.candidates(dh.informer)
+	if err != nil {
+		requestLog(ctx).Error("Failed to list deployments from cache", err, map[string]interface{}{})
+		dh.sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve deployments")
+		return
+	}
+	deployments = opts.filter(deployments, dh.labels)
+
+	dh.sendJSON(ctx, fasthttp.StatusOK, DeploymentAggregateResponse{
+		GroupBy: groupBy,
+		Groups:  aggregateDeployments(deployments, groupBy, dh.labels),
+		Total:   len(deployments),
+	})
+}
+
+// aggregateDeployments groups deployments by the value of a label, sorted by value
+func aggregateDeployments(deployments []*appsv1.Deployment, key string, propagator kubernetes.LabelPropagator) []DeploymentGroup {
+	groups := make(map[string]*DeploymentGroup)
+	for _, dep := range deployments {
+		value := kubernetes.EffectiveLabels(dep, propagator)[key]
+		group, exists := groups[value]
+		if !exists {
+			group = &DeploymentGroup{Value: value}
+			groups[value] = group
+		}
+		group.Deployments++
+		group.Replicas += specReplicas(dep)
+		group.Ready += dep.Status.ReadyReplicas
+		group.Available += dep.Status.AvailableReplicas
+	}
+
+	result := make([]DeploymentGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// namespaceLabels propagates fixed labels per namespace
+type namespaceLabels map[string]map[string]string
+
+func (n namespaceLabels) PropagatedLabels(dep *appsv1.Deployment) map[string]string {
+	return n[dep.Namespace]
+}
+
+func TestAggregateDeployments(t *testing.T) {
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(
+		newTestDeployment("payments", "api", 3, map[string]string{"app": "api"}),
+		newTestDeployment("payments", "worker", 2, map[string]string{"app": "worker"}),
+		newTestDeployment("search", "web", 4, map[string]string{"app": "web", "team": "search"}),
+		newTestDeployment("sandbox", "demo", 1, map[string]string{"app": "demo"}),
+	), "", time.Minute)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("informer.Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	handler := NewDeploymentHandler(informer)
+	handler.SetLabelPropagator(namespaceLabels{"payments": {"team": "payments"}})
+
+	get := func(uri string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		ctx.Request.Header.SetMethod("GET")
+		handler.HandleDeployments(ctx)
+		return ctx
+	}
+
+	ctx := get("/api/v1/deployments/aggregate?groupBy=team")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var response DeploymentAggregateResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+
+	want := []DeploymentGroup{
+		{Value: "", Deployments: 1, Replicas: 1},
+		{Value: "payments", Deployments: 2, Replicas: 5},
+		{Value: "search", Deployments: 1, Replicas: 4},
+	}
+	if response.Total != 4 || len(response.Groups) != len(want) {
+		t.Fatalf("Unexpected aggregate: %+v", response)
+	}
+	for i, group := range want {
+		got := response.Groups[i]
+		if got.Value != group.Value || got.Deployments != group.Deployments || got.Replicas != group.Replicas {
+			t.Errorf("Group %d: expected %+v, got %+v", i, group, got)
+		}
+	}
+
+	// Propagated labels are visible to label selectors and in responses
+	ctx = get("/api/v1/deployments?labelSelector=team%3Dpayments")
+	var list DeploymentListResponse
+	_ = json.Unmarshal(ctx.Response.Body(), &list)
+	if list.Total != 2 || list.Items[0].Labels["team"] != "payments" {
+		t.Errorf("Expected 2 deployments with the propagated team label, got %+v", list)
+	}
+
+	for _, uri := range []string{"/api/v1/deployments/aggregate", "/api/v1/deployments/aggregate?groupBy=bad%20key"} {
+		if status := get(uri).Response.StatusCode(); status != fasthttp.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", uri, status)
+		}
+	}
+}
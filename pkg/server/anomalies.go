@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/anomaly"
+	"github.com/valyala/fasthttp"
+)
+
+// SetAnomalyDetector sets the detector whose latest report is served at
+// /api/v1/anomalies
+func (s *Server) SetAnomalyDetector(detector *anomaly.Detector) {
+	s.anomalies = detector
+}
+
+// handleAnomalies handles GET /api/v1/anomalies. It returns the anomalies
+// flagged by the last check, filtered by the namespace and type query
+// parameters.
+func (s *Server) handleAnomalies(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.anomalies == nil || !s.anomalies.Enabled() {
+		s.handleServiceUnavailable(ctx, "Anomaly detection not configured (controller.anomalies.enabled)")
+		return
+	}
+
+	anomalyType, err := anomaly.ParseType(string(ctx.QueryArgs().Peek("type")))
+	if err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+	report, checked := s.anomalies.Report()
+	if !checked {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Anomaly detection has not completed a check yet")
+		return
+	}
+	sendJSON(ctx, fasthttp.StatusOK, anomaly.Filter(report, string(ctx.QueryArgs().Peek("namespace")), anomalyType))
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/anomaly"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleAnomalies(t *testing.T) {
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/anomalies", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a detector, got %d", ctx.Response.StatusCode())
+	}
+
+	store, err := history.OpenEventStore(filepath.Join(t.TempDir(), "events.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	// web ran replicas a minute ago and api is expected to scale to zero
+	if err := store.RecordSamples([]history.Sample{
+		{Time: time.Now().Add(-time.Minute), Namespace: "shop", Name: "web", Replicas: 2},
+		{Time: time.Now().Add(-time.Minute), Namespace: "ops", Name: "api", Replicas: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	web := newTestDeployment("shop", "web", 0, nil)
+	api := newTestDeployment("ops", "api", 0, nil)
+	api.Annotations = map[string]string{anomaly.AllowScaleToZeroAnnotation: "true"}
+	deployments := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(web, api), "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+
+	cfg := config.DefaultConfig().Controller.Anomalies
+	detector := anomaly.NewDetector(deployments, store, cfg)
+	srv.SetAnomalyDetector(detector)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/anomalies", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while detection is disabled, got %d", ctx.Response.StatusCode())
+	}
+
+	cfg.Enabled = true
+	detector.SetConfig(cfg)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/anomalies", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first check, got %d", ctx.Response.StatusCode())
+	}
+
+	detector.Check()
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/anomalies?type=scale-to-zero", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var report anomaly.Report
+	if err := json.Unmarshal(ctx.Response.Body(), &report); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(report.Anomalies) != 1 || report.Anomalies[0].Name != "web" || report.Deployments != 2 {
+		t.Errorf("Expected web to be flagged as scaled to zero, got %+v", report)
+	}
+
+	ctx = serve(srv, fasthttp.MethodGet, "/api/v1/anomalies?namespace=ops", "")
+	if err := json.Unmarshal(ctx.Response.Body(), &report); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(report.Anomalies) != 0 {
+		t.Errorf("Expected no anomalies in ops, got %+v", report.Anomalies)
+	}
+
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/anomalies?type=outage", ""); ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown type, got %d", ctx.Response.StatusCode())
+	}
+	if ctx := serve(srv, fasthttp.MethodPost, "/api/v1/anomalies", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", ctx.Response.StatusCode())
+	}
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/security"
+	"github.com/valyala/fasthttp"
+)
+
+// tokenReviewTimeout bounds the TokenReview of one request
+const tokenReviewTimeout = 5 * time.Second
+
+// authMiddleware requires /api/ requests to authenticate with a configured
+// method, records the authenticated name as the request principal, rate
+// limits it when limiting by principal, and rejects requests the caller's
+// role doesn't allow. Without configured methods the API is open, as before.
+func (s *Server) authMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !s.config.Auth.Enabled() || !strings.HasPrefix(string(ctx.Path()), "/api/") {
+			next(ctx)
+			return
+		}
+
+		identity, ok := s.authenticate(ctx)
+		if !ok {
+			if s.limitsByPrincipal() && !s.allowRequest(ctx, "ip:"+clientIP(ctx)) {
+				return
+			}
+			ctx.Response.Header.Set("WWW-Authenticate", `Bearer realm="k6s"`)
+			sendError(ctx, fasthttp.StatusUnauthorized, "Unauthorized", "Missing or invalid credentials")
+			return
+		}
+		ctx.SetUserValue(principalKey, identity.Name)
+		if s.limitsByPrincipal() && !s.allowRequest(ctx, "principal:"+identity.Name) {
+			return
+		}
+
+		method := string(ctx.Method())
+		route := routeFor(string(ctx.Path()))
+		role, allowed := s.authorizer.Authorize(identity, method, route)
+		if !allowed {
+			requestLog(ctx).Warn("API request denied", map[string]interface{}{
+				"principal": identity.Name,
+				"role":      string(role),
+				"method":    method,
+				"route":     route,
+			})
+			metrics.APIAuthorizationDenials.WithLabelValues(string(role), method, route).Inc()
+			sendError(ctx, fasthttp.StatusForbidden, "Forbidden",
+				fmt.Sprintf("Role %s may not %s %s", role, method, route))
+			return
+		}
+		next(ctx)
+	}
+}
+
+// SetTokenReviewer sets the reviewer of bearer tokens that match no
+// configured token
+func (s *Server) SetTokenReviewer(reviewer *TokenReviewer) {
+	s.tokenReview = reviewer
+}
+
+// authenticate returns who a request authenticates as: the subject of a
+// verified client certificate, a configured token with its role, or the user
+// a token review reports for the bearer token
+func (s *Server) authenticate(ctx *fasthttp.RequestCtx) (security.Identity, bool) {
+	var certs []*x509.Certificate
+	if ctx.IsTLS() {
+		if state := ctx.TLSConnectionState(); state != nil {
+			certs = state.PeerCertificates
+		}
+	}
+	if identity, ok := s.authenticateCertificate(certs); ok {
+		return identity, true
+	}
+
+	presented, found := strings.CutPrefix(string(ctx.Request.Header.Peek("Authorization")), "Bearer ")
+	if !found || presented == "" {
+		return security.Identity{}, false
+	}
+	identity, ok, err := s.authenticateToken(presented)
+	if err != nil {
+		requestLog(ctx).Warn("Token review failed", map[string]interface{}{
+			"error":     err.Error(),
+			"remote_ip": clientIP(ctx),
+		})
+		return security.Identity{}, false
+	}
+	return identity, ok
+}
+
+// Authenticate returns who a caller of another API, such as the gRPC API,
+// authenticates as with a bearer token and the certificate chain it
+// presented over TLS, checked like the credentials of HTTP API requests
+func (s *Server) Authenticate(token string, certs []*x509.Certificate) (security.Identity, bool, error) {
+	if err := s.loadClientCAs(); err != nil {
+		return security.Identity{}, false, err
+	}
+	if identity, ok := s.authenticateCertificate(certs); ok {
+		return identity, true, nil
+	}
+	if token == "" {
+		return security.Identity{}, false, nil
+	}
+	return s.authenticateToken(token)
+}
+
+// Authorize returns the role of an authenticated caller and whether it may
+// call method on route of the HTTP API
+func (s *Server) Authorize(identity security.Identity, method, route string) (security.Role, bool) {
+	return s.authorizer.Authorize(identity, method, route)
+}
+
+// authenticateToken returns who a bearer token authenticates as: a
+// configured token with its role, if any, or the user a token review reports
+func (s *Server) authenticateToken(presented string) (security.Identity, bool, error) {
+	for _, token := range s.config.Auth.Tokens {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token.Token)) == 1 {
+			// Tokens without a role are bound by name, like other callers
+			return security.Identity{Name: token.Name, Role: security.Role(token.Role)}, true, nil
+		}
+	}
+
+	if s.tokenReview == nil {
+		return security.Identity{}, false, nil
+	}
+	reviewCtx, cancel := context.WithTimeout(context.Background(), tokenReviewTimeout)
+	defer cancel()
+	user, ok, err := s.tokenReview.Review(reviewCtx, presented)
+	if err != nil {
+		return security.Identity{}, false, err
+	}
+	return user, ok && user.Name != "", nil
+}
+
+// authenticateCertificate returns the common name and organizations of a
+// client certificate chain, as user and groups, when it is verified by the
+// configured client CAs
+func (s *Server) authenticateCertificate(certs []*x509.Certificate) (security.Identity, bool) {
+	if s.clientCAs == nil || len(certs) == 0 {
+		return security.Identity{}, false
+	}
+
+	leaf := certs[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         s.clientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil || leaf.Subject.CommonName == "" {
+		return security.Identity{}, false
+	}
+	return security.Identity{Name: leaf.Subject.CommonName, Groups: leaf.Subject.Organization}, true
+}
+
+// newAuthorizer creates the authorizer of the configured role bindings.
+// Callers without a role or binding are viewers unless another default role
+// is configured.
+func newAuthorizer(auth config.AuthConfig) *security.Authorizer {
+	bindings := make([]security.Binding, 0, len(auth.RoleBindings))
+	for _, binding := range auth.RoleBindings {
+		bindings = append(bindings, security.Binding{
+			Role:   security.Role(binding.Role),
+			Users:  binding.Users,
+			Groups: binding.Groups,
+		})
+	}
+	defaultRole := security.RoleViewer
+	if auth.DefaultRole != "" {
+		defaultRole = security.Role(auth.DefaultRole)
+	}
+	return security.NewAuthorizer(bindings, defaultRole)
+}
+
+// loadClientCAs loads the configured client CAs, once for the HTTP API and
+// the callers of Authenticate
+func (s *Server) loadClientCAs() error {
+	s.clientCAsOnce.Do(func() {
+		if s.config.Auth.ClientCAFile == "" {
+			return
+		}
+		pool, err := loadCertPool(s.config.Auth.ClientCAFile)
+		if err != nil {
+			s.clientCAsErr = fmt.Errorf("loading client CA file: %w", err)
+			return
+		}
+		s.clientCAs = pool
+	})
+	return s.clientCAsErr
+}
+
+// loadCertPool reads a pool of PEM certificates from a file
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is set by the server configuration
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// checkCluster rejects API requests that select a cluster this server doesn't
+// serve. The deployment list also accepts every cluster.
+func (s *Server) checkCluster(ctx *fasthttp.RequestCtx) bool {
+	cluster := string(ctx.QueryArgs().Peek("cluster"))
+	if cluster == "" || cluster == s.config.ClusterName {
+		return true
+	}
+	if cluster == allClusters && string(ctx.Path()) == "/api/v1/deployments" {
+		return true
+	}
+
+	message := fmt.Sprintf("Cluster %s is not served by this server", cluster)
+	if s.config.ClusterName != "" {
+		message += fmt.Sprintf(" (serving %s)", s.config.ClusterName)
+	}
+	sendError(ctx, fasthttp.StatusNotFound, "Not found", message)
+	return false
+}
@@ -0,0 +1,332 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/valyala/fasthttp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func serve(srv *Server, method, uri, token string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	if token != "" {
+		ctx.Request.Header.Set("Authorization", "Bearer "+token)
+	}
+	srv.Handler()(ctx)
+	return ctx
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	cfg := config.DefaultConfig().Server
+	cfg.ClusterName = "prod"
+	cfg.Auth.Tokens = []config.APIToken{{Name: "ci", Token: "0123456789abcdef"}}
+	srv := NewWithConfig(cfg)
+
+	tests := []struct {
+		name   string
+		uri    string
+		token  string
+		status int
+	}{
+		{"health is open", "/health", "", fasthttp.StatusOK},
+		{"missing token", "/api/v1/deployments", "", fasthttp.StatusUnauthorized},
+		{"wrong token", "/api/v1/deployments", "wrong", fasthttp.StatusUnauthorized},
+		{"valid token", "/api/v1/deployments", "0123456789abcdef", fasthttp.StatusServiceUnavailable},
+		{"served cluster", "/api/v1/deployments?cluster=prod", "0123456789abcdef", fasthttp.StatusServiceUnavailable},
+		{"other cluster", "/api/v1/deployments?cluster=dev", "0123456789abcdef", fasthttp.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := serve(srv, fasthttp.MethodGet, tt.uri, tt.token)
+			if ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+}
+
+func TestScaleRequiresTokens(t *testing.T) {
+	srv := New(0)
+
+	ctx := serve(srv, fasthttp.MethodPut, "/api/v1/deployments/prod/web/scale", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Errorf("Expected scaling to be forbidden without API tokens, got %d", ctx.Response.StatusCode())
+	}
+}
+
+// newTokenReviewer returns a reviewer accepting the token "sa-token" as the
+// default service account of shop, and the number of reviews made
+func newTokenReviewer() (*TokenReviewer, *int) {
+	clientset := fake.NewSimpleClientset()
+	reviews := 0
+	clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "sa-token" {
+			review.Status.Authenticated = true
+			review.Status.User.Username = "system:serviceaccount:shop:default"
+			review.Status.User.Groups = []string{"system:serviceaccounts", "system:serviceaccounts:shop"}
+		}
+		return true, review, nil
+	})
+	return NewTokenReviewer(clientset.AuthenticationV1().TokenReviews(), nil, time.Minute, 0), &reviews
+}
+
+func TestTokenReviewAuthentication(t *testing.T) {
+	cfg := config.DefaultConfig().Server
+	cfg.Auth.Tokens = []config.APIToken{{Name: "ci", Token: "0123456789abcdef"}}
+	cfg.Auth.TokenReview.Enabled = true
+	srv := NewWithConfig(cfg)
+	reviewer, reviews := newTokenReviewer()
+	srv.SetTokenReviewer(reviewer)
+
+	var principal string
+	handler := srv.authMiddleware(func(ctx *fasthttp.RequestCtx) {
+		principal, _ = ctx.UserValue(principalKey).(string)
+	})
+	authenticate := func(token string) int {
+		principal = ""
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v1/deployments")
+		ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		handler(ctx)
+		return ctx.Response.StatusCode()
+	}
+
+	if status := authenticate("0123456789abcdef"); status != fasthttp.StatusOK || principal != "ci" || *reviews != 0 {
+		t.Errorf("Expected configured tokens without a review, got %d as %q after %d reviews", status, principal, *reviews)
+	}
+	if status := authenticate("sa-token"); status != fasthttp.StatusOK || principal != "system:serviceaccount:shop:default" {
+		t.Errorf("Expected the reviewed user, got %d as %q", status, principal)
+	}
+	if status := authenticate("expired"); status != fasthttp.StatusUnauthorized {
+		t.Errorf("Expected a rejected token to be unauthorized, got %d", status)
+	}
+
+	authenticate("sa-token")
+	authenticate("expired")
+	if *reviews != 2 {
+		t.Errorf("Expected review results to be cached, got %d reviews", *reviews)
+	}
+}
+
+func TestTokenReviewerExpiry(t *testing.T) {
+	reviewer, reviews := newTokenReviewer()
+	reviewer.ttl = time.Millisecond
+
+	for i := 0; i < 2; i++ {
+		if user, ok, err := reviewer.Review(context.Background(), "sa-token"); err != nil || !ok || user.Name == "" {
+			t.Fatalf("Review() = %+v, %v, %v", user, ok, err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if *reviews != 2 {
+		t.Errorf("Expected an expired result to be reviewed again, got %d reviews", *reviews)
+	}
+	if len(reviewer.cache) != 1 {
+		t.Errorf("Expected expired results to be dropped, got %d cached", len(reviewer.cache))
+	}
+}
+
+func TestTokenReviewerCacheSize(t *testing.T) {
+	reviewer, reviews := newTokenReviewer()
+	reviewer.size = 2
+
+	// Rejected tokens are kept apart, in a small cache of their own
+	for i := 0; i < 2*rejectedTokenCacheSize; i++ {
+		if _, ok, err := reviewer.Review(context.Background(), fmt.Sprintf("random-%d", i)); err != nil || ok {
+			t.Fatalf("Review() = %v, %v", ok, err)
+		}
+	}
+	if len(reviewer.rejected) != rejectedTokenCacheSize || len(reviewer.cache) != 0 {
+		t.Errorf("Expected %d rejected tokens cached, got %d rejected and %d accepted", rejectedTokenCacheSize, len(reviewer.rejected), len(reviewer.cache))
+	}
+
+	reviewer.Review(context.Background(), "sa-token")
+	reviewer.Review(context.Background(), "sa-token")
+	if len(reviewer.cache) != 1 || *reviews != 2*rejectedTokenCacheSize+1 {
+		t.Errorf("Expected the accepted token to be cached, got %d cached after %d reviews", len(reviewer.cache), *reviews)
+	}
+
+	// Full caches drop expired results first, and then any other
+	now := time.Now()
+	cache := map[[sha256.Size]byte]tokenReview{
+		sha256.Sum256([]byte("expired")): {expires: now.Add(-time.Second)},
+		sha256.Sum256([]byte("valid")):   {expires: now.Add(time.Minute)},
+	}
+	cacheReview(cache, 2, sha256.Sum256([]byte("new")), tokenReview{expires: now.Add(time.Minute)}, now)
+	if _, found := cache[sha256.Sum256([]byte("expired"))]; found || len(cache) != 2 {
+		t.Errorf("Expected the expired result to make room, got %d cached", len(cache))
+	}
+	cacheReview(cache, 2, sha256.Sum256([]byte("newer")), tokenReview{expires: now.Add(time.Minute)}, now)
+	if _, found := cache[sha256.Sum256([]byte("newer"))]; !found || len(cache) != 2 {
+		t.Errorf("Expected a full cache to stay at its size, got %d cached", len(cache))
+	}
+}
+
+// tlsConn is a connection reporting a TLS state with peer certificates
+type tlsConn struct {
+	net.Conn
+	peers []*x509.Certificate
+}
+
+func (c tlsConn) Handshake() error { return nil }
+func (c tlsConn) ConnectionState() tls.ConnectionState {
+	return tls.ConnectionState{HandshakeComplete: true, PeerCertificates: c.peers}
+}
+
+// newCertificate creates a certificate for cn signed by parent, or
+// self-signed when parent is nil
+func newCertificate(t *testing.T, cn string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestClientCertificateAuthentication(t *testing.T) {
+	ca, caKey := newCertificate(t, "k6s clients", true, nil, nil)
+	client, _ := newCertificate(t, "portal", false, ca, caKey)
+	otherCA, otherKey := newCertificate(t, "other", true, nil, nil)
+	stranger, _ := newCertificate(t, "stranger", false, otherCA, otherKey)
+
+	cfg := config.DefaultConfig().Server
+	cfg.Auth.ClientCAFile = "/etc/k6s/client-ca.crt"
+	srv := NewWithConfig(cfg)
+	srv.clientCAs = x509.NewCertPool()
+	srv.clientCAs.AddCert(ca)
+
+	var principal string
+	handler := srv.authMiddleware(func(ctx *fasthttp.RequestCtx) {
+		principal, _ = ctx.UserValue(principalKey).(string)
+	})
+
+	tests := []struct {
+		name      string
+		peers     []*x509.Certificate
+		tls       bool
+		status    int
+		principal string
+	}{
+		{"verified certificate", []*x509.Certificate{client}, true, fasthttp.StatusOK, "portal"},
+		{"unknown CA", []*x509.Certificate{stranger}, true, fasthttp.StatusUnauthorized, ""},
+		{"no certificate", nil, true, fasthttp.StatusUnauthorized, ""},
+		{"plain HTTP", nil, false, fasthttp.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal = ""
+			ctx := &fasthttp.RequestCtx{}
+			if tt.tls {
+				ctx.Init2(tlsConn{peers: tt.peers}, nil, false)
+			}
+			ctx.Request.SetRequestURI("/api/v1/deployments")
+			handler(ctx)
+			if ctx.Response.StatusCode() != tt.status || principal != tt.principal {
+				t.Errorf("Expected %d as %q, got %d as %q", tt.status, tt.principal, ctx.Response.StatusCode(), principal)
+			}
+		})
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	cfg := config.DefaultConfig().Server
+	cfg.Auth.Tokens = []config.APIToken{
+		{Name: "ci", Token: "0123456789abcdef"},
+		{Name: "dashboard", Token: "viewer-token-0001", Role: "viewer"},
+		{Name: "deployer", Token: "operator-token-01", Role: "operator"},
+		{Name: "release", Token: "release-token-001"},
+	}
+	cfg.Auth.TokenReview.Enabled = true
+	cfg.Auth.RoleBindings = []config.RoleBinding{
+		{Role: "operator", Groups: []string{"system:serviceaccounts:shop"}},
+		{Role: "admin", Users: []string{"release"}},
+	}
+	srv := NewWithConfig(cfg)
+	reviewer, _ := newTokenReviewer()
+	srv.SetTokenReviewer(reviewer)
+	handler := srv.authMiddleware(func(ctx *fasthttp.RequestCtx) {})
+
+	tests := []struct {
+		name   string
+		method string
+		uri    string
+		token  string
+		status int
+	}{
+		{"viewer reads", fasthttp.MethodGet, "/api/v1/deployments", "viewer-token-0001", fasthttp.StatusOK},
+		{"viewer previews", fasthttp.MethodPost, "/api/v1/batch/scale/preview", "viewer-token-0001", fasthttp.StatusOK},
+		{"viewer scales", fasthttp.MethodPut, "/api/v1/deployments/prod/web/scale", "viewer-token-0001", fasthttp.StatusForbidden},
+		{"operator scales", fasthttp.MethodPut, "/api/v1/deployments/prod/web/scale", "operator-token-01", fasthttp.StatusOK},
+		{"operator deletes", fasthttp.MethodDelete, "/api/v1/deployments/prod/web", "operator-token-01", fasthttp.StatusOK},
+		{"operator adds cluster", fasthttp.MethodPost, "/api/v1/clusters", "operator-token-01", fasthttp.StatusForbidden},
+		{"token without role", fasthttp.MethodPost, "/api/v1/clusters", "0123456789abcdef", fasthttp.StatusForbidden},
+		{"token without role reads", fasthttp.MethodGet, "/api/v1/clusters", "0123456789abcdef", fasthttp.StatusOK},
+		{"bound token", fasthttp.MethodPost, "/api/v1/clusters", "release-token-001", fasthttp.StatusOK},
+		{"bound group", fasthttp.MethodPost, "/api/v1/deployments", "sa-token", fasthttp.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(tt.method)
+			ctx.Request.SetRequestURI(tt.uri)
+			ctx.Request.Header.Set("Authorization", "Bearer "+tt.token)
+			handler(ctx)
+			if ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+
+	// Reviewed users without a binding get the default role
+	srv.authorizer = newAuthorizer(config.AuthConfig{})
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetRequestURI("/api/v1/deployments")
+	ctx.Request.Header.Set("Authorization", "Bearer sa-token")
+	handler(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Errorf("Expected an unbound user to be a viewer, got %d", ctx.Response.StatusCode())
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/batch"
+	"github.com/valyala/fasthttp"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ScalePreviewRequest selects the deployments of a batch scale to preview
+type ScalePreviewRequest struct {
+	Namespace     string `json:"namespace,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	Replicas      *int32 `json:"replicas"`
+}
+
+// handleScalePreview handles POST /api/v1/batch/scale/preview. It reports
+// what scaling the selected deployments would change without applying it,
+// so that a UI can ask for approval before calling the scale endpoint.
+func (s *Server) handleScalePreview(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsPost() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.deploymentHandler == nil {
+		s.handleServiceUnavailable(ctx, "Deployment informer not configured")
+		return
+	}
+	informer := s.deploymentHandler.informer
+	if !informer.IsStarted() || !informer.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Deployment informer cache is not synced")
+		return
+	}
+
+	var req ScalePreviewRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Replicas == nil || *req.Replicas < 0 {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "replicas must be a non-negative number")
+		return
+	}
+
+	opts := listOptions{namespace: req.Namespace, selector: labels.Everything()}
+	if req.LabelSelector != "" {
+		selector, err := labels.Parse(req.LabelSelector)
+		if err != nil {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", fmt.Sprintf("invalid label selector: %v", err))
+			return
+		}
+		opts.selector = selector
+	}
+
+	deployments, err := opts.candidates(informer)
+	if err != nil {
+		requestLog(ctx).Error("Failed to list deployments from cache", err, map[string]interface{}{})
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve deployments")
+		return
+	}
+	deployments = opts.filter(deployments, s.labels)
+
+	sendJSON(ctx, fasthttp.StatusOK, batch.Scale(s.config.ClusterName, deployments, *req.Replicas))
+}
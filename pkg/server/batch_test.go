@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/batch"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScalePreview(t *testing.T) {
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(
+		newTestDeployment("prod", "web", 2, map[string]string{"tier": "frontend"}),
+		newTestDeployment("prod", "admin", 3, map[string]string{"tier": "frontend"}),
+		newTestDeployment("prod", "api", 2, map[string]string{"tier": "backend"}),
+	), "", time.Minute)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("informer.Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	cfg := config.DefaultConfig().Server
+	cfg.ClusterName = "prod-eu"
+	srv := NewWithConfig(cfg)
+	srv.SetDeploymentInformer(informer)
+
+	preview := func(method, body string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI("/api/v1/batch/scale/preview")
+		ctx.Request.SetBodyString(body)
+		srv.Handler()(ctx)
+		return ctx
+	}
+
+	ctx := preview(fasthttp.MethodPost, `{"namespace":"prod","labelSelector":"tier=frontend","replicas":3}`)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var plan batch.Plan
+	if err := json.Unmarshal(ctx.Response.Body(), &plan); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(plan.Changes) != 1 || plan.Changes[0].Name != "web" || plan.Changes[0].Cluster != "prod-eu" || plan.Unchanged != 1 {
+		t.Errorf("Expected only web to change in prod-eu, got %+v", plan)
+	}
+
+	// Previewing never scales
+	if dep, _ := informer.GetDeployment("prod", "web"); *dep.Spec.Replicas != 2 {
+		t.Errorf("Expected preview not to scale, got %d replicas", *dep.Spec.Replicas)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{"missing replicas", fasthttp.MethodPost, `{"labelSelector":"tier=frontend"}`, fasthttp.StatusBadRequest},
+		{"invalid selector", fasthttp.MethodPost, `{"labelSelector":"tier in","replicas":1}`, fasthttp.StatusBadRequest},
+		{"GET", fasthttp.MethodGet, "", fasthttp.StatusMethodNotAllowed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if ctx := preview(tt.method, tt.body); ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+}
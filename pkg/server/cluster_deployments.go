@@ -0,0 +1,440 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+// allClusters is the cluster query parameter value aggregating every cluster
+const allClusters = "*"
+
+var (
+	// errClusterNotServed is returned for clusters that are neither registered
+	// nor served by the local informer
+	errClusterNotServed = errors.New("cluster is not configured")
+
+	// errClusterNotSynced is returned while a cluster's cache is syncing
+	errClusterNotSynced = errors.New("deployment cache is not synced")
+)
+
+// ClusterDeploymentResponse is a deployment annotated with its cluster
+type ClusterDeploymentResponse struct {
+	Cluster string `json:"cluster"`
+	DeploymentResponse
+}
+
+// ClusterDeploymentListResponse represents deployments of one or more
+// clusters. Errors holds the clusters that could not be listed.
+type ClusterDeploymentListResponse struct {
+	Items    []ClusterDeploymentResponse `json:"items"`
+	Count    int                         `json:"count"`
+	Total    int                         `json:"total"`
+	Continue string                      `json:"continue,omitempty"`
+	Errors   map[string]string           `json:"errors,omitempty"`
+}
+
+// MaskedClusterDeploymentListResponse represents deployments of one or more
+// clusters with a field mask applied; the cluster is always included
+type MaskedClusterDeploymentListResponse struct {
+	Items    []map[string]interface{} `json:"items"`
+	Count    int                      `json:"count"`
+	Total    int                      `json:"total"`
+	Continue string                   `json:"continue,omitempty"`
+	Errors   map[string]string        `json:"errors,omitempty"`
+}
+
+// ClusterInformers keeps a deployment informer for each enabled cluster of a
+// registry, so that deployments of every cluster are served from caches.
+// Informers follow the registry: a cluster whose client is replaced, e.g.
+// after a config reload, gets a new informer.
+type ClusterInformers struct {
+	registry    cluster.ClusterRegistry
+	newInformer func(k8s.Interface) *kubernetes.DeploymentInformer
+
+	mu        sync.Mutex
+	informers map[string]*clusterInformer
+}
+
+// clusterInformer is the informer of one cluster, started in the background
+type clusterInformer struct {
+	client   cluster.ClusterClient
+	informer *kubernetes.DeploymentInformer
+
+	mu      sync.Mutex
+	done    bool // Start returned
+	err     error
+	stopped bool
+}
+
+// NewClusterInformers creates informers for the clusters of registry, built
+// by newInformer from each cluster's client
+func NewClusterInformers(registry cluster.ClusterRegistry, newInformer func(k8s.Interface) *kubernetes.DeploymentInformer) *ClusterInformers {
+	return &ClusterInformers{
+		registry:    registry,
+		newInformer: newInformer,
+		informers:   make(map[string]*clusterInformer),
+	}
+}
+
+// Sync starts informers for enabled clusters that have none, and stops those
+// of clusters that were removed, disabled or replaced. Informers that failed
+// to start are retried.
+func (ci *ClusterInformers) Sync() {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	enabled := ci.registry.GetEnabledClusters()
+	for name, entry := range ci.informers {
+		if client, exists := enabled[name]; exists && client == entry.client && !entry.failed() {
+			continue
+		}
+		entry.stop()
+		delete(ci.informers, name)
+	}
+
+	for name, client := range enabled {
+		if _, exists := ci.informers[name]; !exists {
+			ci.informers[name] = ci.start(name, client)
+		}
+	}
+}
+
+// Clusters returns the names of the enabled clusters, sorted
+func (ci *ClusterInformers) Clusters() []string {
+	ci.Sync()
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	names := make([]string, 0, len(ci.informers))
+	for name := range ci.informers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Deployments returns the cached deployments of a cluster
+func (ci *ClusterInformers) Deployments(name string) ([]*appsv1.Deployment, error) {
+	ci.Sync()
+
+	ci.mu.Lock()
+	entry, exists := ci.informers[name]
+	ci.mu.Unlock()
+	if !exists {
+		return nil, errClusterNotServed
+	}
+	return entry.deployments()
+}
+
+// CacheSyncState reports whether the informer of a cluster has synced, for
+// cluster health tracking. Clusters without an informer have no state.
+func (ci *ClusterInformers) CacheSyncState(name string) (cluster.CacheSyncState, error) {
+	ci.mu.Lock()
+	entry, exists := ci.informers[name]
+	ci.mu.Unlock()
+	if !exists {
+		return "", nil
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	switch {
+	case !entry.done:
+		return cluster.CacheSyncPending, nil
+	case entry.err != nil:
+		return cluster.CacheSyncFailed, entry.err
+	}
+	return cluster.CacheSyncSynced, nil
+}
+
+// Stop stops every informer
+func (ci *ClusterInformers) Stop() {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	for name, entry := range ci.informers {
+		entry.stop()
+		delete(ci.informers, name)
+	}
+}
+
+// start builds and starts the informer of a cluster in the background, since
+// starting waits for the cache to sync
+func (ci *ClusterInformers) start(name string, client cluster.ClusterClient) *clusterInformer {
+	entry := &clusterInformer{client: client}
+	go func() {
+		clientset, err := client.GetKubernetesClient()
+		var informer *kubernetes.DeploymentInformer
+		if err == nil {
+			informer = ci.newInformer(clientset)
+			err = informer.Start()
+		}
+		if err != nil {
+			serverLog().Warn("Failed to start cluster deployment informer", map[string]interface{}{
+				"cluster": name,
+				"error":   err.Error(),
+			})
+		}
+
+		entry.mu.Lock()
+		entry.informer = informer
+		entry.done = true
+		entry.err = err
+		stopped := entry.stopped
+		entry.mu.Unlock()
+
+		// The cluster was removed while its cache was syncing
+		if stopped && err == nil {
+			informer.Stop()
+		}
+	}()
+	return entry
+}
+
+// deployments returns the cached deployments once the informer has started
+func (e *clusterInformer) deployments() ([]*appsv1.Deployment, error) {
+	e.mu.Lock()
+	done, err, informer := e.done, e.err, e.informer
+	e.mu.Unlock()
+
+	switch {
+	case !done:
+		return nil, errClusterNotSynced
+	case err != nil:
+		return nil, err
+	}
+	return informer.ListDeployments()
+}
+
+// failed reports whether the informer failed to start
+func (e *clusterInformer) failed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.done && e.err != nil
+}
+
+// stop stops the informer, or marks it to be stopped once started
+func (e *clusterInformer) stop() {
+	e.mu.Lock()
+	e.stopped = true
+	informer := e.informer
+	started := e.done && e.err == nil
+	e.mu.Unlock()
+
+	if started {
+		informer.Stop()
+	}
+}
+
+// SetClusterInformers sets the per-cluster informers backing
+// /api/v1/clusters/{name}/deployments and /api/v1/deployments?cluster=*
+func (s *Server) SetClusterInformers(informers *ClusterInformers) {
+	s.clusterInformers = informers
+}
+
+// clusterDeployments returns the deployments of a cluster, from its informer
+// or, for the cluster this server serves, from the local informer
+func (s *Server) clusterDeployments(name string) ([]*appsv1.Deployment, kubernetes.LabelPropagator, error) {
+	if s.clusterInformers != nil {
+		deployments, err := s.clusterInformers.Deployments(name)
+		if !errors.Is(err, errClusterNotServed) {
+			return deployments, nil, err
+		}
+	}
+
+	if name == "" || name != s.config.ClusterName || s.deploymentHandler == nil {
+		return nil, nil, errClusterNotServed
+	}
+	if !s.deploymentHandler.informer.IsStarted() || !s.deploymentHandler.informer.HasSynced() {
+		return nil, nil, errClusterNotSynced
+	}
+	deployments, err := s.deploymentHandler.informer.ListDeployments()
+	return deployments, s.labels, err
+}
+
+// servedClusters returns the clusters deployments can be listed from: the
+// registered clusters and the cluster this server serves, sorted
+func (s *Server) servedClusters() []string {
+	var names []string
+	if s.clusterInformers != nil {
+		names = s.clusterInformers.Clusters()
+	}
+	if local := s.config.ClusterName; local != "" && s.deploymentHandler != nil {
+		if i := sort.SearchStrings(names, local); i == len(names) || names[i] != local {
+			names = append(names, local)
+			sort.Strings(names)
+		}
+	}
+	return names
+}
+
+// clusterDeployment is a deployment and the cluster it was listed from
+type clusterDeployment struct {
+	cluster    string
+	deployment *appsv1.Deployment
+	labels     kubernetes.LabelPropagator
+}
+
+// handleClusterDeployments handles GET /api/v1/clusters/{name}/deployments
+func (s *Server) handleClusterDeployments(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(string(ctx.Path()), "/api/v1/clusters/"), "/deployments")
+	if name == "" || strings.Contains(name, "/") {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid cluster path format")
+		return
+	}
+
+	deployments, labels, err := s.clusterDeployments(name)
+	switch {
+	case errors.Is(err, errClusterNotServed):
+		sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Cluster %s is not configured", name))
+		return
+	case errors.Is(err, errClusterNotSynced):
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", fmt.Sprintf("Deployment cache of cluster %s is not synced", name))
+		return
+	case err != nil:
+		requestLog(ctx).Error("Failed to list cluster deployments", err, map[string]interface{}{
+			"cluster": name,
+		})
+		sendError(ctx, fasthttp.StatusBadGateway, "Bad gateway", fmt.Sprintf("Failed to list deployments of cluster %s: %v", name, err))
+		return
+	}
+
+	rows := make([]clusterDeployment, len(deployments))
+	for i, dep := range deployments {
+		rows[i] = clusterDeployment{cluster: name, deployment: dep, labels: labels}
+	}
+	s.sendClusterDeploymentList(ctx, rows, nil)
+}
+
+// clusterListing is the deployments of every cluster, and the errors of the
+// clusters that could not be listed
+type clusterListing struct {
+	clusters []string
+	rows     []clusterDeployment
+	errors   map[string]string
+}
+
+// handleAggregatedDeployments handles GET /api/v1/deployments?cluster=*,
+// listing the deployments of every cluster. Clusters that cannot be listed
+// are reported in errors rather than failing the request.
+func (s *Server) handleAggregatedDeployments(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+
+	// Concurrent requests share the listing, and filter and page it each
+	listing := s.clusterLists.do("", s.listClusters)
+	if len(listing.clusters) == 0 {
+		s.handleServiceUnavailable(ctx, "No clusters configured")
+		return
+	}
+	s.sendClusterDeploymentList(ctx, listing.rows, listing.errors)
+}
+
+// listClusters lists the deployments of every cluster
+func (s *Server) listClusters() clusterListing {
+	listing := clusterListing{clusters: s.servedClusters()}
+	for _, name := range listing.clusters {
+		deployments, labels, err := s.clusterDeployments(name)
+		if err != nil {
+			if listing.errors == nil {
+				listing.errors = make(map[string]string)
+			}
+			listing.errors[name] = err.Error()
+			continue
+		}
+		for _, dep := range deployments {
+			listing.rows = append(listing.rows, clusterDeployment{cluster: name, deployment: dep, labels: labels})
+		}
+	}
+	return listing
+}
+
+// sendClusterDeploymentList filters, sorts and pages deployments of clusters
+// like /api/v1/deployments, and sends them annotated with their cluster
+func (s *Server) sendClusterDeploymentList(ctx *fasthttp.RequestCtx, rows []clusterDeployment, clusterErrors map[string]string) {
+	fields, err := requestedFields(ctx, s.config.FieldMask)
+	if err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+	opts, err := parseListOptions(ctx.QueryArgs())
+	if err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+	if opts.index != "" {
+		// Indexes are only queried in the cache of the local cluster
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "index is not supported with cluster lists")
+		return
+	}
+
+	filtered := make([]clusterDeployment, 0, len(rows))
+	for _, row := range rows {
+		if opts.matches(row.deployment, row.labels) {
+			filtered = append(filtered, row)
+		}
+	}
+	total := len(filtered)
+
+	// Deployments present in several clusters are ordered by cluster
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		if opts.reverse {
+			a, b = b, a
+		}
+		if opts.less(a.deployment, b.deployment) {
+			return true
+		}
+		if opts.less(b.deployment, a.deployment) {
+			return false
+		}
+		return a.cluster < b.cluster
+	})
+	page, next := pageItems(opts, filtered)
+
+	response := ClusterDeploymentListResponse{
+		Items:    make([]ClusterDeploymentResponse, 0, len(page)),
+		Count:    len(page),
+		Total:    total,
+		Continue: next,
+		Errors:   clusterErrors,
+	}
+	for _, row := range page {
+		dep := deploymentToResponse(row.deployment)
+		dep.Labels = kubernetes.EffectiveLabels(row.deployment, row.labels)
+		response.Items = append(response.Items, ClusterDeploymentResponse{Cluster: row.cluster, DeploymentResponse: dep})
+	}
+
+	if len(fields) == 0 {
+		sendJSON(ctx, fasthttp.StatusOK, response)
+		return
+	}
+	masked := MaskedClusterDeploymentListResponse{
+		Items:    make([]map[string]interface{}, 0, len(response.Items)),
+		Count:    response.Count,
+		Total:    response.Total,
+		Continue: response.Continue,
+		Errors:   response.Errors,
+	}
+	for _, item := range response.Items {
+		fieldsOf := maskDeployment(item.DeploymentResponse, fields)
+		fieldsOf["cluster"] = item.Cluster
+		masked.Items = append(masked.Items, fieldsOf)
+	}
+	sendJSON(ctx, fasthttp.StatusOK, masked)
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	k6stesting "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/testing"
+	"github.com/valyala/fasthttp"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+// newClusterInformersServer returns a server listing the deployments of the
+// eu and us clusters, once their informers have synced
+func newClusterInformersServer(t *testing.T) *Server {
+	t.Helper()
+	registry := k6stesting.FakeRegistry{
+		"eu": k6stesting.NewFakeCluster("eu",
+			newTestDeployment("shop", "web", 3, map[string]string{"app": "web"}),
+			newTestDeployment("shop", "api", 2, map[string]string{"app": "api"}),
+		),
+		"us": k6stesting.NewFakeCluster("us",
+			newTestDeployment("shop", "web", 6, map[string]string{"app": "web"}),
+		),
+	}
+	informers := NewClusterInformers(registry, func(clientset k8s.Interface) *kubernetes.DeploymentInformer {
+		return kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	})
+	t.Cleanup(informers.Stop)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, name := range []string{"eu", "us"} {
+		for {
+			if _, err := informers.Deployments(name); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Informer of cluster %s did not sync", name)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	srv.SetClusterInformers(informers)
+	return srv
+}
+
+func decodeClusterDeployments(t *testing.T, ctx *fasthttp.RequestCtx) ClusterDeploymentListResponse {
+	t.Helper()
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var list ClusterDeploymentListResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &list); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	return list
+}
+
+func TestClusterDeployments(t *testing.T) {
+	srv := newClusterInformersServer(t)
+
+	list := decodeClusterDeployments(t, serve(srv, fasthttp.MethodGet, "/api/v1/clusters/eu/deployments", ""))
+	if list.Total != 2 || len(list.Items) != 2 {
+		t.Fatalf("Expected 2 deployments in eu, got %+v", list)
+	}
+	for _, item := range list.Items {
+		if item.Cluster != "eu" {
+			t.Errorf("Expected cluster eu, got %s", item.Cluster)
+		}
+	}
+	if list.Items[0].Name != "api" || list.Items[1].Name != "web" {
+		t.Errorf("Expected deployments sorted by name, got %s, %s", list.Items[0].Name, list.Items[1].Name)
+	}
+
+	list = decodeClusterDeployments(t, serve(srv, fasthttp.MethodGet, "/api/v1/clusters/eu/deployments?labelSelector=app%3Dweb", ""))
+	if list.Total != 1 || list.Items[0].Name != "web" {
+		t.Errorf("Expected the label selector to apply, got %+v", list)
+	}
+
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/clusters/ap/deployments", ""); ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown cluster, got %d", ctx.Response.StatusCode())
+	}
+	if ctx := serve(srv, fasthttp.MethodPost, "/api/v1/clusters/eu/deployments", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestAggregatedDeployments(t *testing.T) {
+	srv := newClusterInformersServer(t)
+
+	list := decodeClusterDeployments(t, serve(srv, fasthttp.MethodGet, "/api/v1/deployments?cluster=*", ""))
+	if list.Total != 3 || len(list.Errors) != 0 {
+		t.Fatalf("Expected 3 deployments across clusters, got %+v", list)
+	}
+	var rows []string
+	for _, item := range list.Items {
+		rows = append(rows, item.Cluster+"/"+item.Name)
+	}
+	if want := []string{"eu/api", "eu/web", "us/web"}; !slices.Equal(rows, want) {
+		t.Errorf("Expected %v, got %v", want, rows)
+	}
+
+	// Pages span clusters
+	list = decodeClusterDeployments(t, serve(srv, fasthttp.MethodGet, "/api/v1/deployments?cluster=*&sortBy=-replicas&limit=2", ""))
+	if len(list.Items) != 2 || list.Items[0].Cluster != "us" || list.Continue == "" {
+		t.Fatalf("Expected the first page to start with us/web, got %+v", list)
+	}
+	list = decodeClusterDeployments(t, serve(srv, fasthttp.MethodGet, "/api/v1/deployments?cluster=*&sortBy=-replicas&limit=2&continue="+list.Continue, ""))
+	if len(list.Items) != 1 || list.Items[0].Name != "api" {
+		t.Errorf("Expected the last page to hold eu/api, got %+v", list)
+	}
+
+	// Masked rows keep their cluster
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments?cluster=*&fields=name", "")
+	var masked MaskedClusterDeploymentListResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &masked); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(masked.Items) != 3 || masked.Items[0]["cluster"] != "eu" || len(masked.Items[0]) != 2 {
+		t.Errorf("Expected masked rows with name and cluster, got %v", masked.Items)
+	}
+
+	// Requests within the TTL reuse the first listing
+	if memoized := testutil.ToFloat64(metrics.APISharedQueries.WithLabelValues("cluster_deployments", "memoized")); memoized < 3 {
+		t.Errorf("Expected the later requests to reuse the listing, got %v memoized", memoized)
+	}
+
+	// Only the deployment list aggregates clusters
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/web?cluster=*", ""); ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 for cluster=* on a single deployment, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestAggregatedDeploymentsWithoutClusters(t *testing.T) {
+	srv := New(0)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments?cluster=*", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without clusters, got %d", ctx.Response.StatusCode())
+	}
+}
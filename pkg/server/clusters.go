@@ -0,0 +1,274 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	k6serrors "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// clusterConnectivityTimeout bounds a connectivity check made through the API
+const clusterConnectivityTimeout = 5 * time.Second
+
+// ClusterResponse represents a configured cluster, with the credentials of
+// its proxy URL redacted
+type ClusterResponse config.ClusterConfig
+
+// ClusterListResponse represents the configured clusters
+type ClusterListResponse struct {
+	Items []ClusterResponse `json:"items"`
+	Count int               `json:"count"`
+}
+
+// clusterResponse returns a cluster as served by the API. Proxy URLs may carry
+// credentials, which viewers must not read.
+func clusterResponse(c config.ClusterConfig) ClusterResponse {
+	if c.ProxyURL != "" && c.ProxyURL != cluster.ProxyDirect {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			// Unparseable URLs can't be redacted, so they aren't served at all
+			c.ProxyURL = ""
+		} else {
+			c.ProxyURL = proxyURL.Redacted()
+		}
+	}
+	return ClusterResponse(c)
+}
+
+// ClusterStatusResponse reports the tracked health of a cluster
+type ClusterStatusResponse = cluster.ClusterHealth
+
+// ClusterConnectivityResponse reports whether a cluster's API server is
+// reachable, as "k6s cluster check-connectivity --output json" does
+type ClusterConnectivityResponse = cluster.ClusterConnectivity
+
+// SetClusterStore sets the store backing the cluster management endpoints.
+// Changes are written to its configuration file, where config hot reload
+// picks them up.
+func (s *Server) SetClusterStore(store *cluster.ConfigStore) {
+	s.clusters = store
+}
+
+// SetHealthTracker sets the tracker whose cluster health is served at
+// /api/v1/clusters/{name}/status
+func (s *Server) SetHealthTracker(tracker *cluster.HealthTracker) {
+	s.health = tracker
+}
+
+// handleClusters handles /api/v1/clusters and /api/v1/clusters/{name}[/{action}]
+func (s *Server) handleClusters(ctx *fasthttp.RequestCtx) {
+	if s.clusters == nil {
+		s.handleServiceUnavailable(ctx, "Cluster store not configured")
+		return
+	}
+
+	path := strings.TrimPrefix(strings.TrimPrefix(string(ctx.Path()), "/api/v1/clusters"), "/")
+	if path == "" {
+		switch {
+		case ctx.IsGet():
+			s.handleListClusters(ctx)
+		case ctx.IsPost():
+			s.handleAddCluster(ctx)
+		default:
+			sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		}
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 || parts[0] == "" {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid cluster path format")
+		return
+	}
+	name := parts[0]
+
+	if len(parts) == 1 {
+		switch {
+		case ctx.IsGet():
+			s.handleGetCluster(ctx, name)
+		case ctx.IsDelete():
+			s.handleDeleteCluster(ctx, name)
+		default:
+			sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		}
+		return
+	}
+
+	switch action := parts[1]; {
+	case action == "connectivity" && ctx.IsGet():
+		s.handleClusterConnectivity(ctx, name)
+	case action == "status" && ctx.IsGet():
+		s.handleClusterStatus(ctx, name)
+	case (action == "enable" || action == "disable" || action == "primary") && ctx.IsPost():
+		s.handleUpdateCluster(ctx, name, action)
+	case action == "connectivity" || action == "status" || action == "enable" || action == "disable" || action == "primary":
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+	default:
+		s.handleNotFound(ctx)
+	}
+}
+
+// handleListClusters handles GET /api/v1/clusters
+func (s *Server) handleListClusters(ctx *fasthttp.RequestCtx) {
+	clusters, err := s.clusters.List()
+	if err != nil {
+		s.sendClusterError(ctx, "", "list", err)
+		return
+	}
+	items := make([]ClusterResponse, 0, len(clusters))
+	for _, c := range clusters {
+		items = append(items, clusterResponse(c))
+	}
+	sendJSON(ctx, fasthttp.StatusOK, ClusterListResponse{Items: items, Count: len(items)})
+}
+
+// handleGetCluster handles GET /api/v1/clusters/{name}
+func (s *Server) handleGetCluster(ctx *fasthttp.RequestCtx, name string) {
+	clusterConfig, err := s.clusters.Get(name)
+	if err != nil {
+		s.sendClusterError(ctx, name, "get", err)
+		return
+	}
+	sendJSON(ctx, fasthttp.StatusOK, clusterResponse(clusterConfig))
+}
+
+// handleAddCluster handles POST /api/v1/clusters. Clusters are enabled unless
+// the body sets enabled to false.
+func (s *Server) handleAddCluster(ctx *fasthttp.RequestCtx) {
+	if !s.requireClusterWrites(ctx) {
+		return
+	}
+
+	req := config.ClusterConfig{Enabled: true}
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid request body: "+err.Error())
+		return
+	}
+
+	added, err := s.clusters.Add(req)
+	if err != nil {
+		s.sendClusterError(ctx, req.Name, "add", err)
+		return
+	}
+	s.logClusterChange(ctx, added.Name, "add")
+	sendJSON(ctx, fasthttp.StatusCreated, clusterResponse(added))
+}
+
+// handleDeleteCluster handles DELETE /api/v1/clusters/{name}
+func (s *Server) handleDeleteCluster(ctx *fasthttp.RequestCtx, name string) {
+	if !s.requireClusterWrites(ctx) {
+		return
+	}
+
+	if err := s.clusters.Delete(name); err != nil {
+		s.sendClusterError(ctx, name, "delete", err)
+		return
+	}
+	s.logClusterChange(ctx, name, "delete")
+	ctx.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+// handleUpdateCluster handles POST /api/v1/clusters/{name}/{enable,disable,primary}
+func (s *Server) handleUpdateCluster(ctx *fasthttp.RequestCtx, name, action string) {
+	if !s.requireClusterWrites(ctx) {
+		return
+	}
+
+	var updated config.ClusterConfig
+	var err error
+	if action == "primary" {
+		updated, err = s.clusters.SetPrimary(name)
+	} else {
+		updated, err = s.clusters.SetEnabled(name, action == "enable")
+	}
+	if err != nil {
+		s.sendClusterError(ctx, name, action, err)
+		return
+	}
+	s.logClusterChange(ctx, name, action)
+	sendJSON(ctx, fasthttp.StatusOK, clusterResponse(updated))
+}
+
+// handleClusterConnectivity handles GET /api/v1/clusters/{name}/connectivity
+func (s *Server) handleClusterConnectivity(ctx *fasthttp.RequestCtx, name string) {
+	clusterConfig, err := s.clusters.Get(name)
+	if err != nil {
+		s.sendClusterError(ctx, name, "get", err)
+		return
+	}
+
+	result, err := cluster.CheckConnectivity(clusterConfig, clusterConnectivityTimeout)
+	sendJSON(ctx, fasthttp.StatusOK, cluster.DescribeConnectivity(name, result, err))
+}
+
+// handleClusterStatus handles GET /api/v1/clusters/{name}/status. Disabled
+// clusters are reported as such without being checked.
+func (s *Server) handleClusterStatus(ctx *fasthttp.RequestCtx, name string) {
+	if s.health == nil {
+		s.handleServiceUnavailable(ctx, "Cluster health tracking not configured")
+		return
+	}
+
+	clusterConfig, err := s.clusters.Get(name)
+	if err != nil {
+		s.sendClusterError(ctx, name, "get", err)
+		return
+	}
+	if !clusterConfig.Enabled {
+		sendJSON(ctx, fasthttp.StatusOK, ClusterStatusResponse{Name: name, State: cluster.HealthDisabled})
+		return
+	}
+
+	health, tracked := s.health.Health(name)
+	if !tracked {
+		// Enabled in the file, but not picked up by hot reload yet
+		health = ClusterStatusResponse{Name: name, State: cluster.HealthSyncing}
+	}
+	sendJSON(ctx, fasthttp.StatusOK, health)
+}
+
+// requireClusterWrites rejects cluster changes unless API tokens are configured,
+// since they change which clusters the controller manages
+func (s *Server) requireClusterWrites(ctx *fasthttp.RequestCtx) bool {
+	if !s.config.Auth.Enabled() {
+		sendError(ctx, fasthttp.StatusForbidden, "Forbidden", "Cluster management requires API authentication to be configured (server.auth)")
+		return false
+	}
+	return true
+}
+
+// sendClusterError maps a cluster store error to a response
+func (s *Server) sendClusterError(ctx *fasthttp.RequestCtx, name, action string, err error) {
+	switch {
+	case errors.Is(err, cluster.ErrClusterNotFound):
+		sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Cluster %s not found", name))
+	case errors.Is(err, cluster.ErrClusterExists):
+		sendError(ctx, fasthttp.StatusConflict, "Conflict", fmt.Sprintf("Cluster %s already exists", name))
+	case k6serrors.IsType(err, k6serrors.ValidationError):
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.(*k6serrors.AppError).Message)
+	default:
+		requestLog(ctx).Error("Failed to update cluster configuration", err, map[string]interface{}{
+			"cluster": name,
+			"action":  action,
+			"path":    s.clusters.Path(),
+		})
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to access cluster configuration")
+	}
+}
+
+// logClusterChange records who changed a cluster
+func (s *Server) logClusterChange(ctx *fasthttp.RequestCtx, name, action string) {
+	principal, _ := ctx.UserValue(principalKey).(string)
+	requestLog(ctx).Info("Cluster configuration changed", map[string]interface{}{
+		"cluster":   name,
+		"action":    action,
+		"principal": principal,
+	})
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/compliance"
+	"github.com/valyala/fasthttp"
+)
+
+// SetComplianceScanner sets the scanner whose latest report is served at
+// /api/v1/compliance
+func (s *Server) SetComplianceScanner(scanner *compliance.Scanner) {
+	s.compliance = scanner
+}
+
+// handleCompliance handles GET /api/v1/compliance. It returns the findings of
+// the last image scan, filtered by the namespace query parameter and by the
+// minimum severity query parameter.
+func (s *Server) handleCompliance(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.compliance == nil || !s.compliance.Enabled() {
+		s.handleServiceUnavailable(ctx, "Image compliance scanning not configured (controller.compliance.enabled)")
+		return
+	}
+
+	severity, err := compliance.ParseSeverity(string(ctx.QueryArgs().Peek("severity")))
+	if err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+	report, checked := s.compliance.Report()
+	if !checked {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Image compliance scanning has not completed a scan yet")
+		return
+	}
+	sendJSON(ctx, fasthttp.StatusOK, compliance.Filter(report, string(ctx.QueryArgs().Peek("namespace")), severity))
+}
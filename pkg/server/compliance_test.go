@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/compliance"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleCompliance(t *testing.T) {
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/compliance", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a scanner, got %d", ctx.Response.StatusCode())
+	}
+
+	web := newTestDeployment("shop", "web", 2, nil)
+	web.Spec.Template.Spec.Containers = []corev1.Container{{Name: "web", Image: "nginx:latest"}}
+	api := newTestDeployment("ops", "api", 2, nil)
+	api.Spec.Template.Spec.Containers = []corev1.Container{{Name: "api", Image: "ghcr.io/acme/api:1.0"}}
+	deployments := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(web, api), "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+
+	cfg := config.DefaultConfig().Controller.Compliance
+	scanner := compliance.NewScanner(deployments, cfg)
+	srv.SetComplianceScanner(scanner)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/compliance", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while scanning is disabled, got %d", ctx.Response.StatusCode())
+	}
+
+	cfg.Enabled = true
+	scanner.SetConfig(cfg)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/compliance", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first scan, got %d", ctx.Response.StatusCode())
+	}
+
+	scanner.Check()
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/compliance?severity=high", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var report compliance.Report
+	if err := json.Unmarshal(ctx.Response.Body(), &report); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Rule != compliance.RuleLatestTag || report.Deployments != 2 {
+		t.Errorf("Expected only the latest tag finding of web, got %+v", report)
+	}
+
+	ctx = serve(srv, fasthttp.MethodGet, "/api/v1/compliance?namespace=ops", "")
+	if err := json.Unmarshal(ctx.Response.Body(), &report); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Deployment != "api" {
+		t.Errorf("Expected the missing digest finding of api, got %+v", report.Findings)
+	}
+
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/compliance?severity=urgent", ""); ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown severity, got %d", ctx.Response.StatusCode())
+	}
+	if ctx := serve(srv, fasthttp.MethodPost, "/api/v1/compliance", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", ctx.Response.StatusCode())
+	}
+}
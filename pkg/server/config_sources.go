@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+)
+
+// ConfigSourceResponse is a ConfigMap or Secret a deployment references,
+// with its keys and last data change when it is cached. Secret values are
+// never returned.
+type ConfigSourceResponse struct {
+	Kind            string    `json:"kind"`
+	Name            string    `json:"name"`
+	References      []string  `json:"references"`
+	Optional        bool      `json:"optional"`
+	Exists          bool      `json:"exists"`
+	Keys            []string  `json:"keys,omitempty"`
+	ResourceVersion string    `json:"resourceVersion,omitempty"`
+	LastChanged     time.Time `json:"lastChanged,omitzero"`
+}
+
+// DeploymentConfigResponse lists the config sources of a deployment
+type DeploymentConfigResponse struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Sources   []ConfigSourceResponse `json:"sources"`
+}
+
+// SetConfigInformer sets the ConfigMap and Secret cache served at
+// /api/v1/deployments/{namespace}/{name}/config. Deployments referencing a
+// ConfigMap or Secret whose data changes get config_changed events.
+func (s *Server) SetConfigInformer(informer *kubernetes.ConfigInformer) {
+	s.configs = informer
+	informer.AddHandler(kubernetes.ConfigChangeHandlerFunc(func(change kubernetes.ConfigChange) {
+		if s.events != nil {
+			s.events.OnConfigChange(change)
+		}
+	}))
+}
+
+// handleDeploymentConfig handles GET /api/v1/deployments/{namespace}/{name}/config
+func (s *Server) handleDeploymentConfig(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.deploymentHandler == nil {
+		s.handleServiceUnavailable(ctx, "Deployment informer not configured")
+		return
+	}
+	if s.configs == nil {
+		s.handleServiceUnavailable(ctx, "ConfigMap and Secret informer not configured")
+		return
+	}
+	informer := s.deploymentHandler.informer
+	if !informer.IsStarted() || !informer.HasSynced() || !s.configs.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Deployment or config informer cache is not synced")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(string(ctx.Path()), "/api/v1/deployments/"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid config path format")
+		return
+	}
+	namespace, name := parts[0], parts[1]
+
+	dep, err := informer.GetDeployment(namespace, name)
+	if err != nil {
+		sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Deployment %s/%s not found", namespace, name))
+		return
+	}
+
+	sources := kubernetes.DeploymentConfigSources(dep)
+	response := DeploymentConfigResponse{
+		Name:      name,
+		Namespace: namespace,
+		Sources:   make([]ConfigSourceResponse, 0, len(sources)),
+	}
+	for _, source := range sources {
+		item := ConfigSourceResponse{
+			Kind:       source.Kind,
+			Name:       source.Name,
+			References: source.References,
+			Optional:   source.Optional,
+		}
+		if obj, exists := s.configs.Get(source.Kind, namespace, source.Name); exists {
+			item.Exists = true
+			item.Keys = obj.Keys
+			item.ResourceVersion = obj.ResourceVersion
+			item.LastChanged = obj.LastChanged
+		}
+		response.Sources = append(response.Sources, item)
+	}
+	sendJSON(ctx, fasthttp.StatusOK, response)
+}
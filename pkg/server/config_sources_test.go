@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeploymentConfig(t *testing.T) {
+	dep := newTestDeployment("shop", "web", 1, nil)
+	dep.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: "web",
+		EnvFrom: []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}}},
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}}},
+		},
+	}}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "shop"},
+		Data:       map[string]string{"mode": "blue"},
+	}
+	clientset := fake.NewSimpleClientset(dep, cm)
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	deployments := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+	srv.SetDeploymentInformer(deployments)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/web/config", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a config informer, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	configs := kubernetes.NewConfigInformer(clientset, "", time.Minute)
+	if err := configs.Start(ctx); err != nil {
+		t.Fatalf("configs.Start() error = %v", err)
+	}
+	defer configs.Stop()
+	srv.SetConfigInformer(configs)
+
+	resp := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/web/config", "")
+	if resp.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Response.StatusCode(), resp.Response.Body())
+	}
+	var response DeploymentConfigResponse
+	if err := json.Unmarshal(resp.Response.Body(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(response.Sources) != 2 {
+		t.Fatalf("Expected two config sources, got %+v", response.Sources)
+	}
+	if source := response.Sources[0]; source.Name != "web-config" || !source.Exists || len(source.Keys) != 1 {
+		t.Errorf("Expected the cached ConfigMap with its keys, got %+v", source)
+	}
+	if source := response.Sources[1]; source.Name != "missing" || source.Exists {
+		t.Errorf("Expected the missing Secret not to exist, got %+v", source)
+	}
+
+	// Subscribers learn which deployments a config change affects
+	sub := srv.events.Subscribe(EventFilter{})
+	defer srv.events.Unsubscribe(sub)
+	cm = cm.DeepCopy()
+	cm.Data["mode"] = "green"
+	if _, err := clientset.CoreV1().ConfigMaps("shop").Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	select {
+	case event := <-sub.Events():
+		if event.Type != EventConfigChanged || event.Deployment.Name != "web" || event.Config == nil || event.Config.Name != "web-config" {
+			t.Errorf("Expected a config_changed event for web, got %+v", event)
+		}
+	case <-ctx.Done():
+		t.Fatal("Expected a config_changed event")
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ResourceEventResponse is a Kubernetes event about a deployment, one of its
+// replica sets or one of their pods
+type ResourceEventResponse = kubernetes.ResourceEvent
+
+// DeploymentEventsResponse lists the events of a deployment, most recent first
+type DeploymentEventsResponse struct {
+	Name      string                  `json:"name"`
+	Namespace string                  `json:"namespace"`
+	Items     []ResourceEventResponse `json:"items"`
+	Count     int                     `json:"count"`
+}
+
+// SetEventInformer sets the event cache served at
+// /api/v1/deployments/{namespace}/{name}/events
+func (s *Server) SetEventInformer(informer *kubernetes.EventInformer) {
+	s.kubeEvents = informer
+}
+
+// handleDeploymentEvents handles GET /api/v1/deployments/{namespace}/{name}/events.
+// Events of replica sets and pods are included when those are cached too.
+// "type=Warning" keeps only warnings and "limit" bounds the number of events.
+func (s *Server) handleDeploymentEvents(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.deploymentHandler == nil {
+		s.handleServiceUnavailable(ctx, "Deployment informer not configured")
+		return
+	}
+	if s.kubeEvents == nil {
+		s.handleServiceUnavailable(ctx, "Event informer not configured")
+		return
+	}
+	informer := s.deploymentHandler.informer
+	if !informer.IsStarted() || !informer.HasSynced() || !s.kubeEvents.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Deployment or event informer cache is not synced")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(string(ctx.Path()), "/api/v1/deployments/"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid events path format")
+		return
+	}
+	namespace, name := parts[0], parts[1]
+
+	eventType := string(ctx.QueryArgs().Peek("type"))
+	if eventType != "" && eventType != corev1.EventTypeNormal && eventType != corev1.EventTypeWarning {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "type must be Normal or Warning")
+		return
+	}
+	limit := 0
+	if raw := string(ctx.QueryArgs().Peek("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+
+	dep, err := informer.GetDeployment(namespace, name)
+	if err != nil {
+		sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Deployment %s/%s not found", namespace, name))
+		return
+	}
+
+	events, err := s.deploymentEvents(dep)
+	if err != nil {
+		requestLog(ctx).Error("Failed to list events from cache", err, map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		})
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve events")
+		return
+	}
+
+	items := make([]ResourceEventResponse, 0, len(events))
+	for _, event := range events {
+		if eventType != "" && event.Type != eventType {
+			continue
+		}
+		if limit > 0 && len(items) == limit {
+			break
+		}
+		items = append(items, event)
+	}
+
+	sendJSON(ctx, fasthttp.StatusOK, DeploymentEventsResponse{
+		Name:      name,
+		Namespace: namespace,
+		Items:     items,
+		Count:     len(items),
+	})
+}
+
+// deploymentEvents returns the cached events of a deployment and of its
+// replica sets and pods, as far as they are cached
+func (s *Server) deploymentEvents(dep *appsv1.Deployment) ([]kubernetes.ResourceEvent, error) {
+	events, err := s.kubeEvents.ListEvents(dep.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var replicaSets []*appsv1.ReplicaSet
+	if s.replicaSets != nil && s.replicaSets.HasSynced() {
+		if replicaSets, err = s.replicaSets.ReplicaSets(dep); err != nil {
+			return nil, err
+		}
+	}
+	var pods []*corev1.Pod
+	if s.pods != nil && s.pods.HasSynced() {
+		if pods, err = s.pods.ListPods(dep.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	return kubernetes.DeploymentEvents(dep, replicaSets, pods, events), nil
+}
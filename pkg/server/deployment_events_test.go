@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeploymentEventsEndpoint(t *testing.T) {
+	dep := newTestDeployment("shop", "web", 3, nil)
+	dep.UID = "web-uid"
+	event := func(name, eventType, reason string, age time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Deployment", Namespace: "shop", Name: "web", UID: dep.UID},
+			Type:           eventType,
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(time.Now().Add(-age)),
+		}
+	}
+	clientset := fake.NewSimpleClientset(dep,
+		event("e1", corev1.EventTypeNormal, "ScalingReplicaSet", 3*time.Minute),
+		event("e2", corev1.EventTypeWarning, "ProgressDeadlineExceeded", 2*time.Minute),
+		event("e3", corev1.EventTypeWarning, "ReplicaSetCreateError", time.Minute),
+	)
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	deployments := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+	srv.SetDeploymentInformer(deployments)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/web/events", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without an event informer, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := kubernetes.NewEventInformer(clientset, "", time.Minute)
+	if err := events.Start(ctx); err != nil {
+		t.Fatalf("events.Start() error = %v", err)
+	}
+	defer events.Stop()
+	srv.SetEventInformer(events)
+
+	resp := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/web/events?type=Warning&limit=1", "")
+	if resp.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Response.StatusCode(), resp.Response.Body())
+	}
+	var response DeploymentEventsResponse
+	if err := json.Unmarshal(resp.Response.Body(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response.Count != 1 || response.Items[0].Reason != "ReplicaSetCreateError" {
+		t.Errorf("Expected the most recent warning, got %+v", response)
+	}
+
+	for _, uri := range []string{"/api/v1/deployments/shop/web/events?type=Error", "/api/v1/deployments/shop/web/events?limit=0"} {
+		if resp := serve(srv, fasthttp.MethodGet, uri, ""); resp.Response.StatusCode() != fasthttp.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", uri, resp.Response.StatusCode())
+		}
+	}
+	if resp := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/api/events", ""); resp.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown deployment, got %d", resp.Response.StatusCode())
+	}
+}
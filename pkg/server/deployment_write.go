@@ -0,0 +1,221 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// CreateDeploymentRequest is the body of a create request: a deployment
+// running one container of image, selecting its pods by app=name
+type CreateDeploymentRequest struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Image     string `json:"image"`
+
+	// Replicas defaults to 1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Labels of the deployment, in addition to app=name
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// validate checks a create request and returns the problems found
+func (r *CreateDeploymentRequest) validate() []string {
+	var problems []string
+	for _, msg := range validation.IsDNS1123Subdomain(r.Name) {
+		problems = append(problems, "name: "+msg)
+	}
+	for _, msg := range validation.IsDNS1123Label(r.Namespace) {
+		problems = append(problems, "namespace: "+msg)
+	}
+	if strings.TrimSpace(r.Image) == "" || strings.ContainsAny(r.Image, " \t\n") {
+		problems = append(problems, "image: must be a non-empty image reference without whitespace")
+	}
+	if r.Replicas != nil && *r.Replicas < 0 {
+		problems = append(problems, "replicas: must be a non-negative number")
+	}
+	for key, value := range r.Labels {
+		for _, msg := range validation.IsQualifiedName(key) {
+			problems = append(problems, fmt.Sprintf("labels: key %q: %s", key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			problems = append(problems, fmt.Sprintf("labels: value of %s: %s", key, msg))
+		}
+	}
+	return problems
+}
+
+// handleCreateDeployment handles POST /api/v1/deployments. Creating writes to
+// the cluster, so it is only available when API tokens are configured.
+func (s *Server) handleCreateDeployment(ctx *fasthttp.RequestCtx) {
+	if !s.config.Auth.Enabled() {
+		sendError(ctx, fasthttp.StatusForbidden, "Forbidden", "Creating deployments requires API authentication to be configured (server.auth)")
+		return
+	}
+	if s.client == nil {
+		s.handleServiceUnavailable(ctx, "Kubernetes client not configured")
+		return
+	}
+
+	var req CreateDeploymentRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Namespace == "" {
+		req.Namespace = "default"
+	}
+	if problems := req.validate(); len(problems) > 0 {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", strings.Join(problems, "; "))
+		return
+	}
+	replicas := int32(1)
+	if req.Replicas != nil {
+		replicas = *req.Replicas
+	}
+
+	deployment := kubernetes.NewDeployment(req.Namespace, req.Name, req.Image, replicas)
+	if len(req.Labels) > 0 {
+		deployment.Labels = make(map[string]string, len(req.Labels))
+		for key, value := range req.Labels {
+			deployment.Labels[key] = value
+		}
+	}
+
+	created, err := s.client.DeploymentCreateFrom(ctx, deployment)
+	if err != nil {
+		switch {
+		case apierrors.IsAlreadyExists(err):
+			sendError(ctx, fasthttp.StatusConflict, "Conflict", fmt.Sprintf("Deployment %s/%s already exists", req.Namespace, req.Name))
+		case apierrors.IsInvalid(err):
+			sendError(ctx, fasthttp.StatusUnprocessableEntity, "Unprocessable entity", err.Error())
+		case apierrors.IsNotFound(err):
+			sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Namespace %s not found", req.Namespace))
+		default:
+			requestLog(ctx).Error("Failed to create deployment", err, map[string]interface{}{
+				"namespace": req.Namespace,
+				"name":      req.Name,
+			})
+			sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to create deployment")
+		}
+		return
+	}
+
+	principal, _ := ctx.UserValue(principalKey).(string)
+	requestLog(ctx).Info("Created deployment", map[string]interface{}{
+		"namespace": created.Namespace,
+		"name":      created.Name,
+		"image":     req.Image,
+		"replicas":  replicas,
+		"principal": principal,
+	})
+
+	ctx.Response.Header.Set("Location", fmt.Sprintf("/api/v1/deployments/%s/%s", created.Namespace, created.Name))
+	sendJSON(ctx, fasthttp.StatusCreated, deploymentToResponse(created))
+}
+
+// SetDeletedHistory sets the recycle bin deployments deleted through the API
+// are snapshotted into, recording cluster as the cluster they were deleted
+// from, so that "k6s deployment undelete" can restore them
+func (s *Server) SetDeletedHistory(store *history.Store, cluster string) {
+	s.deletedHistory = store
+	s.deletedCluster = cluster
+}
+
+// handleDeleteDeployment handles DELETE /api/v1/deployments/{namespace}/{name}.
+// With ?resourceVersion= the deployment is only deleted when it has not
+// changed since that version. Deleting writes to the cluster, so it is only
+// available when API tokens are configured. With a recycle bin set, the
+// deployment is snapshotted first.
+func (s *Server) handleDeleteDeployment(ctx *fasthttp.RequestCtx) {
+	if !s.config.Auth.Enabled() {
+		sendError(ctx, fasthttp.StatusForbidden, "Forbidden", "Deleting deployments requires API authentication to be configured (server.auth)")
+		return
+	}
+	if s.client == nil {
+		s.handleServiceUnavailable(ctx, "Kubernetes client not configured")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(string(ctx.Path()), "/api/v1/deployments/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid path format. Expected: /api/v1/deployments/{namespace}/{name}")
+		return
+	}
+	namespace, name := parts[0], parts[1]
+	resourceVersion := string(ctx.QueryArgs().Peek("resourceVersion"))
+
+	snapshot, ok := s.snapshotDeleted(ctx, namespace, name)
+	if !ok {
+		return
+	}
+
+	if err := s.client.DeploymentDelete(ctx, namespace, name, resourceVersion); err != nil {
+		if snapshot != nil {
+			_ = s.deletedHistory.Remove(*snapshot)
+		}
+		switch {
+		case apierrors.IsNotFound(err):
+			sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Deployment %s/%s not found", namespace, name))
+		case apierrors.IsConflict(err):
+			sendError(ctx, fasthttp.StatusConflict, "Conflict", fmt.Sprintf("Deployment %s/%s has changed since resource version %s", namespace, name, resourceVersion))
+		default:
+			requestLog(ctx).Error("Failed to delete deployment", err, map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+			})
+			sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to delete deployment")
+		}
+		return
+	}
+
+	principal, _ := ctx.UserValue(principalKey).(string)
+	requestLog(ctx).Info("Deleted deployment", map[string]interface{}{
+		"namespace": namespace,
+		"name":      name,
+		"principal": principal,
+	})
+
+	ctx.SetStatusCode(fasthttp.StatusNoContent)
+}
+
+// snapshotDeleted saves the manifest of a deployment about to be deleted to
+// the recycle bin, if one is set, and returns its entry. It sends the error
+// response and returns false when the deployment can't be snapshotted.
+func (s *Server) snapshotDeleted(ctx *fasthttp.RequestCtx, namespace, name string) (*history.Entry, bool) {
+	if s.deletedHistory == nil {
+		return nil, true
+	}
+
+	deployment, err := s.client.DeploymentGet(namespace, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Deployment %s/%s not found", namespace, name))
+			return nil, false
+		}
+		requestLog(ctx).Error("Failed to get deployment", err, map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		})
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to delete deployment")
+		return nil, false
+	}
+
+	entry, err := s.deletedHistory.SaveDeleted(s.deletedCluster, deployment)
+	if err != nil {
+		requestLog(ctx).Error("Failed to save deployment snapshot", err, map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		})
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to save deployment snapshot")
+		return nil, false
+	}
+	return &entry, true
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCreateDeployment(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestDeployment("shop", "web", 1, nil))
+	cfg := config.DefaultConfig().Server
+	cfg.Auth.Tokens = []config.APIToken{{Name: "ci", Token: "0123456789abcdef", Role: "admin"}}
+	srv := NewWithConfig(cfg)
+	srv.SetDeploymentClient(kubernetes.NewClientWithClientset(clientset))
+
+	create := func(body string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+		ctx.Request.SetRequestURI("/api/v1/deployments")
+		ctx.Request.Header.Set("Authorization", "Bearer 0123456789abcdef")
+		ctx.Request.SetBodyString(body)
+		srv.Handler()(ctx)
+		return ctx
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid body", `{"name":`, fasthttp.StatusBadRequest},
+		{"invalid name", `{"name":"Web_1","image":"nginx"}`, fasthttp.StatusBadRequest},
+		{"invalid namespace", `{"name":"api","namespace":"shop.eu","image":"nginx"}`, fasthttp.StatusBadRequest},
+		{"missing image", `{"name":"api","namespace":"shop"}`, fasthttp.StatusBadRequest},
+		{"negative replicas", `{"name":"api","namespace":"shop","image":"nginx","replicas":-1}`, fasthttp.StatusBadRequest},
+		{"invalid label", `{"name":"api","namespace":"shop","image":"nginx","labels":{"team":"a b"}}`, fasthttp.StatusBadRequest},
+		{"existing deployment", `{"name":"web","namespace":"shop","image":"nginx"}`, fasthttp.StatusConflict},
+		{"created", `{"name":"api","namespace":"shop","image":"api:2","replicas":3,"labels":{"team":"payments"}}`, fasthttp.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := create(tt.body)
+			if ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+
+	dep, err := clientset.AppsV1().Deployments("shop").Get(context.Background(), "api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the deployment to be created: %v", err)
+	}
+	if *dep.Spec.Replicas != 3 || dep.Spec.Template.Spec.Containers[0].Image != "api:2" || dep.Labels["team"] != "payments" {
+		t.Errorf("Unexpected deployment: %+v", dep)
+	}
+
+	ctx := create(`{"name":"worker","image":"worker:1"}`)
+	var response DeploymentResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response.Namespace != "default" || response.Replicas != 1 || response.Image != "worker:1" {
+		t.Errorf("Expected one worker replica in default, got %+v", response)
+	}
+	if location := string(ctx.Response.Header.Peek("Location")); location != "/api/v1/deployments/default/worker" {
+		t.Errorf("Unexpected Location %q", location)
+	}
+}
+
+func TestDeleteDeployment(t *testing.T) {
+	dep := newTestDeployment("shop", "web", 1, nil)
+	dep.ResourceVersion = "7"
+	clientset := fake.NewSimpleClientset(dep)
+	// The fake clientset ignores delete preconditions, the API server
+	// rejects stale ones
+	clientset.PrependReactor("delete", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.DeleteAction).GetDeleteOptions()
+		if pre := opts.Preconditions; pre != nil && pre.ResourceVersion != nil && *pre.ResourceVersion != "7" {
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "web", nil)
+		}
+		return false, nil, nil
+	})
+
+	cfg := config.DefaultConfig().Server
+	cfg.Auth.Tokens = []config.APIToken{{Name: "ci", Token: "0123456789abcdef", Role: "admin"}}
+	srv := NewWithConfig(cfg)
+	srv.SetDeploymentClient(kubernetes.NewClientWithClientset(clientset))
+	recycleBin := history.NewStore(t.TempDir(), time.Hour)
+	srv.SetDeletedHistory(recycleBin, "prod")
+
+	tests := []struct {
+		name   string
+		uri    string
+		token  string
+		status int
+	}{
+		{"missing token", "/api/v1/deployments/shop/web", "", fasthttp.StatusUnauthorized},
+		{"invalid path", "/api/v1/deployments/web", "0123456789abcdef", fasthttp.StatusBadRequest},
+		{"stale resource version", "/api/v1/deployments/shop/web?resourceVersion=6", "0123456789abcdef", fasthttp.StatusConflict},
+		{"deleted", "/api/v1/deployments/shop/web?resourceVersion=7", "0123456789abcdef", fasthttp.StatusNoContent},
+		{"already deleted", "/api/v1/deployments/shop/web", "0123456789abcdef", fasthttp.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := serve(srv, fasthttp.MethodDelete, tt.uri, tt.token)
+			if ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+
+	// Only the deletion that went through is kept in the recycle bin
+	entries, err := recycleBin.Deleted()
+	if err != nil {
+		t.Fatalf("Deleted() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Namespace != "shop" || entries[0].Name != "web" || entries[0].Cluster != "prod" {
+		t.Errorf("Expected one snapshot of shop/web from prod, got %+v", entries)
+	}
+}
+
+func TestDeploymentWritesRequireTokens(t *testing.T) {
+	srv := New(8080)
+	srv.SetDeploymentClient(kubernetes.NewClientWithClientset(fake.NewSimpleClientset(newTestDeployment("shop", "web", 1, nil))))
+
+	for _, method := range []string{fasthttp.MethodPost, fasthttp.MethodDelete} {
+		uri := "/api/v1/deployments"
+		if method == fasthttp.MethodDelete {
+			uri += "/shop/web"
+		}
+		if ctx := serve(srv, method, uri, ""); ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+			t.Errorf("Expected %s without API tokens to be forbidden, got %d", method, ctx.Response.StatusCode())
+		}
+	}
+}
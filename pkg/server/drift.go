@@ -0,0 +1,103 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	"github.com/valyala/fasthttp"
+)
+
+// SetDriftDetector sets the detector whose latest report is served at /api/v1/drift
+func (s *Server) SetDriftDetector(detector *drift.Detector) {
+	s.drift = detector
+}
+
+// handleDrift handles GET /api/v1/drift. It returns the last comparison of
+// deployment spec hashes across each drift group; "drifted=true" keeps only
+// drifted deployments.
+func (s *Server) handleDrift(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.drift == nil {
+		s.handleServiceUnavailable(ctx, "Drift detection not configured (multi_cluster.drift.groups)")
+		return
+	}
+
+	report, checked := s.drift.Report()
+	if !checked {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Drift detection has not completed a check yet")
+		return
+	}
+
+	if string(ctx.QueryArgs().Peek("drifted")) == "true" {
+		report = driftedOnly(report)
+	}
+	sendJSON(ctx, fasthttp.StatusOK, report)
+}
+
+// driftedOnly returns a copy of report keeping only drifted deployments
+func driftedOnly(report drift.Report) drift.Report {
+	groups := make([]drift.GroupReport, 0, len(report.Groups))
+	for _, group := range report.Groups {
+		deployments := []drift.DeploymentHashes{}
+		for _, dep := range group.Deployments {
+			if dep.Drifted {
+				deployments = append(deployments, dep)
+			}
+		}
+		group.Deployments = deployments
+		groups = append(groups, group)
+	}
+	report.Groups = groups
+	return report
+}
+
+// SetManifestDriftDetector sets the detector whose latest report is served at
+// /api/v1/drift/manifests
+func (s *Server) SetManifestDriftDetector(detector *drift.ManifestDetector) {
+	s.manifestDrift = detector
+}
+
+// handleManifestDrift handles GET /api/v1/drift/manifests. It returns the last
+// comparison of deployments with their declared manifests, filtered by the
+// namespace query parameter; "drifted=true" keeps only deployments that
+// drifted or are missing.
+func (s *Server) handleManifestDrift(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.manifestDrift == nil || !s.manifestDrift.Enabled() {
+		s.handleServiceUnavailable(ctx, "Manifest drift detection not configured (controller.drift.enabled)")
+		return
+	}
+
+	report, checked := s.manifestDrift.Report()
+	if !checked {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Manifest drift detection has not completed a check yet")
+		return
+	}
+
+	namespace := string(ctx.QueryArgs().Peek("namespace"))
+	driftedOnly := string(ctx.QueryArgs().Peek("drifted")) == "true"
+	if namespace != "" || driftedOnly {
+		deployments := []drift.DeploymentDrift{}
+		report.Drifted, report.Missing = 0, 0
+		for _, dep := range report.Deployments {
+			if (namespace != "" && dep.Namespace != namespace) || (driftedOnly && dep.Status == drift.StatusInSync) {
+				continue
+			}
+			switch dep.Status {
+			case drift.StatusDrifted:
+				report.Drifted++
+			case drift.StatusMissing:
+				report.Missing++
+			}
+			deployments = append(deployments, dep)
+		}
+		report.Deployments = deployments
+	}
+	sendJSON(ctx, fasthttp.StatusOK, report)
+}
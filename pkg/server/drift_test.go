@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleDrift(t *testing.T) {
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/drift", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a detector, got %d", ctx.Response.StatusCode())
+	}
+
+	detector := drift.NewDetector(cluster.NewInMemoryClusterRegistry(), config.DriftConfig{
+		Groups: []config.DriftGroupConfig{{Name: "prod", Clusters: []string{"prod-eu", "prod-us"}}},
+	})
+	srv.SetDriftDetector(detector)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/drift", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first check, got %d", ctx.Response.StatusCode())
+	}
+
+	detector.Check(context.Background())
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/drift", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var report drift.Report
+	if err := json.Unmarshal(ctx.Response.Body(), &report); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(report.Groups) != 1 || len(report.Groups[0].Errors) != 2 {
+		t.Errorf("Expected the prod group with errors for both unconfigured clusters, got %+v", report.Groups)
+	}
+
+	if ctx := serve(srv, fasthttp.MethodPost, "/api/v1/drift", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestDriftedOnly(t *testing.T) {
+	report := drift.Report{Drifted: 1, Groups: []drift.GroupReport{{
+		Name: "prod",
+		Deployments: []drift.DeploymentHashes{
+			{Namespace: "shop", Name: "web"},
+			{Namespace: "shop", Name: "api", Drifted: true},
+		},
+		Drifted: 1,
+	}}}
+
+	filtered := driftedOnly(report)
+	if deployments := filtered.Groups[0].Deployments; len(deployments) != 1 || deployments[0].Name != "api" {
+		t.Errorf("Expected only the drifted api deployment, got %+v", deployments)
+	}
+	if len(report.Groups[0].Deployments) != 2 {
+		t.Error("Expected the original report to be left unchanged")
+	}
+}
+
+func TestHandleManifestDrift(t *testing.T) {
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/drift/manifests", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a detector, got %d", ctx.Response.StatusCode())
+	}
+
+	web := newTestDeployment("shop", "web", 3, nil)
+	web.Annotations = map[string]string{drift.DesiredManifestAnnotation: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},"spec":{"replicas":2}}`}
+	api := newTestDeployment("shop", "api", 2, nil)
+	api.Annotations = map[string]string{drift.DesiredManifestAnnotation: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"api"},"spec":{"replicas":2}}`}
+	deployments := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(web, api), "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+
+	detector := drift.NewManifestDetector(deployments, config.ManifestDriftConfig{})
+	srv.SetManifestDriftDetector(detector)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/drift/manifests", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while detection is disabled, got %d", ctx.Response.StatusCode())
+	}
+
+	detector.SetConfig(config.ManifestDriftConfig{Enabled: true})
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/drift/manifests", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first check, got %d", ctx.Response.StatusCode())
+	}
+
+	detector.Check()
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/drift/manifests?drifted=true", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var report drift.ManifestReport
+	if err := json.Unmarshal(ctx.Response.Body(), &report); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(report.Deployments) != 1 || report.Deployments[0].Name != "web" || report.Drifted != 1 {
+		t.Errorf("Expected only the drifted web deployment, got %+v", report)
+	}
+
+	ctx = serve(srv, fasthttp.MethodGet, "/api/v1/drift/manifests?namespace=other", "")
+	if err := json.Unmarshal(ctx.Response.Body(), &report); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(report.Deployments) != 0 || report.Drifted != 0 {
+		t.Errorf("Expected no deployments in namespace other, got %+v", report)
+	}
+}
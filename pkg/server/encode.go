@@ -0,0 +1,171 @@
+package server
+
+import (
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/valyala/bytebufferpool"
+	"github.com/valyala/fasthttp"
+)
+
+// The deployment list and get endpoints are the most requested ones, so their
+// responses are written by hand into pooled buffers instead of through
+// encoding/json, which reflects over every item and allocates the whole
+// document. The output is byte for byte what json.Marshal produces.
+
+// sendDeploymentJSON sends a deployment
+func sendDeploymentJSON(ctx *fasthttp.RequestCtx, statusCode int, dep DeploymentResponse) {
+	buf := bytebufferpool.Get()
+	buf.B = appendDeployment(buf.B, dep)
+	sendRawJSON(ctx, statusCode, buf)
+}
+
+// sendDeploymentListJSON sends a page of deployments
+func sendDeploymentListJSON(ctx *fasthttp.RequestCtx, statusCode int, list DeploymentListResponse) {
+	buf := bytebufferpool.Get()
+	buf.B = appendDeploymentList(buf.B, list)
+	sendRawJSON(ctx, statusCode, buf)
+}
+
+// sendRawJSON copies an encoded response into ctx and returns buf to the pool
+func sendRawJSON(ctx *fasthttp.RequestCtx, statusCode int, buf *bytebufferpool.ByteBuffer) {
+	ctx.SetStatusCode(statusCode)
+	ctx.SetContentType("application/json")
+	ctx.SetBody(buf.B)
+	bytebufferpool.Put(buf)
+}
+
+// appendDeploymentList appends list encoded as JSON
+func appendDeploymentList(b []byte, list DeploymentListResponse) []byte {
+	b = append(b, `{"items":`...)
+	if list.Items == nil {
+		b = append(b, "null"...)
+	} else {
+		b = append(b, '[')
+		for i, dep := range list.Items {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendDeployment(b, dep)
+		}
+		b = append(b, ']')
+	}
+	b = append(b, `,"count":`...)
+	b = strconv.AppendInt(b, int64(list.Count), 10)
+	b = append(b, `,"total":`...)
+	b = strconv.AppendInt(b, int64(list.Total), 10)
+	if list.Continue != "" {
+		b = append(b, `,"continue":`...)
+		b = appendJSONString(b, list.Continue)
+	}
+	return append(b, '}')
+}
+
+// appendDeployment appends dep encoded as JSON
+func appendDeployment(b []byte, dep DeploymentResponse) []byte {
+	b = append(b, `{"name":`...)
+	b = appendJSONString(b, dep.Name)
+	b = append(b, `,"namespace":`...)
+	b = appendJSONString(b, dep.Namespace)
+	b = append(b, `,"replicas":`...)
+	b = strconv.AppendInt(b, int64(dep.Replicas), 10)
+	b = append(b, `,"ready":`...)
+	b = strconv.AppendInt(b, int64(dep.Ready), 10)
+	b = append(b, `,"updated":`...)
+	b = strconv.AppendInt(b, int64(dep.Updated), 10)
+	b = append(b, `,"available":`...)
+	b = strconv.AppendInt(b, int64(dep.Available), 10)
+	b = append(b, `,"age":`...)
+	b = appendJSONString(b, dep.Age)
+	if dep.Image != "" {
+		b = append(b, `,"image":`...)
+		b = appendJSONString(b, dep.Image)
+	}
+	if len(dep.Labels) > 0 {
+		b = append(b, `,"labels":`...)
+		b = appendStringMap(b, dep.Labels)
+	}
+	if dep.SpecHash != "" {
+		b = append(b, `,"specHash":`...)
+		b = appendJSONString(b, dep.SpecHash)
+	}
+	return append(b, '}')
+}
+
+// appendStringMap appends m as a JSON object with sorted keys
+func appendStringMap(b []byte, m map[string]string) []byte {
+	// Deployments rarely carry more labels than fit on the stack
+	var stack [16]string
+	keys := stack[:0]
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	b = append(b, '{')
+	for i, key := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, key)
+		b = append(b, ':')
+		b = appendJSONString(b, m[key])
+	}
+	return append(b, '}')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string, escaped like encoding/json:
+// HTML characters, control characters, U+2028 and U+2029 are escaped and
+// invalid UTF-8 is replaced with U+FFFD
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
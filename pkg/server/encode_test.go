@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	for _, s := range []string{
+		"", "web", `quote " and \ backslash`, "<script>&</script>", "tab\tline\nreturn\r",
+		"\b\f\x00\x1f\x7f", "héllo wörld ✓", "line\u2028para\u2029", "bad \xff utf8 \xc3", "emoji 🚀",
+	} {
+		want, _ := json.Marshal(s)
+		if got := appendJSONString(nil, s); string(got) != string(want) {
+			t.Errorf("appendJSONString(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
+
+func TestAppendDeploymentList(t *testing.T) {
+	for _, list := range []DeploymentListResponse{
+		{},
+		{Items: []DeploymentResponse{}, Continue: "token<1>"},
+		benchmarkDeploymentList(3),
+		{Items: []DeploymentResponse{{Name: "bare", Namespace: "default", Replicas: -1}}, Count: 1, Total: 1},
+	} {
+		want, _ := json.Marshal(list)
+		if got := appendDeploymentList(nil, list); string(got) != string(want) {
+			t.Errorf("appendDeploymentList() =\n%s\nwant\n%s", got, want)
+		}
+	}
+
+	many := benchmarkDeploymentList(1)
+	many.Items[0].Labels = make(map[string]string)
+	for i := 0; i < 40; i++ {
+		many.Items[0].Labels[fmt.Sprintf("label-%02d", i)] = "value"
+	}
+	want, _ := json.Marshal(many)
+	if got := appendDeploymentList(nil, many); string(got) != string(want) {
+		t.Errorf("Expected sorted labels beyond the stack buffer, got %s", got)
+	}
+}
+
+func TestSendDeploymentListJSON(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	sendDeploymentListJSON(ctx, fasthttp.StatusOK, benchmarkDeploymentList(2))
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK || string(ctx.Response.Header.ContentType()) != "application/json" {
+		t.Errorf("Expected a 200 JSON response, got %d %s", ctx.Response.StatusCode(), ctx.Response.Header.ContentType())
+	}
+	var list DeploymentListResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &list); err != nil || len(list.Items) != 2 {
+		t.Errorf("Expected 2 decodable items, got %v (err %v)", list.Items, err)
+	}
+}
+
+// benchmarkDeploymentList returns a page of n deployments shaped like real ones
+func benchmarkDeploymentList(n int) DeploymentListResponse {
+	list := DeploymentListResponse{Items: make([]DeploymentResponse, 0, n), Count: n, Total: n * 4, Continue: "eyJvZmZzZXQiOjEwMH0"}
+	for i := 0; i < n; i++ {
+		list.Items = append(list.Items, DeploymentResponse{
+			Name:      fmt.Sprintf("checkout-api-%d", i),
+			Namespace: "payments",
+			Replicas:  3,
+			Ready:     3,
+			Updated:   3,
+			Available: 3,
+			Age:       "12d",
+			Image:     "registry.example.com/payments/checkout-api:v2.14.1",
+			Labels:    map[string]string{"app": "checkout-api", "team": "payments", "tier": "backend", "cost-center": "cc-1234"},
+			SpecHash:  "3f9a1c2b7d4e8f60",
+		})
+	}
+	return list
+}
+
+// BenchmarkDeploymentListEncoding compares encoding a 100 item page with
+// encoding/json and with the pooled writer used by the list endpoint
+func BenchmarkDeploymentListEncoding(b *testing.B) {
+	list := benchmarkDeploymentList(100)
+
+	b.Run("encoding-json", func(b *testing.B) {
+		b.ReportAllocs()
+		ctx := &fasthttp.RequestCtx{}
+		for i := 0; i < b.N; i++ {
+			sendJSON(ctx, fasthttp.StatusOK, list)
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		ctx := &fasthttp.RequestCtx{}
+		for i := 0; i < b.N; i++ {
+			sendDeploymentListJSON(ctx, fasthttp.StatusOK, list)
+		}
+	})
+}
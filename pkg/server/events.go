@@ -0,0 +1,256 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Deployment event types delivered to stream subscribers
+const (
+	EventAdded   = "added"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+
+	// EventConfigChanged is delivered for each deployment referencing a
+	// ConfigMap or Secret whose data changed
+	EventConfigChanged = "config_changed"
+)
+
+// defaultSubscriberBuffer is the number of events buffered per subscriber
+const defaultSubscriberBuffer = 64
+
+// DeploymentEvent represents a deployment event delivered to stream subscribers
+type DeploymentEvent struct {
+	ID         uint64                        `json:"id"`
+	Type       string                        `json:"type"`
+	Timestamp  time.Time                     `json:"timestamp"`
+	Deployment DeploymentResponse            `json:"deployment"`
+	Changes    []kubernetes.DeploymentChange `json:"changes,omitempty"`
+	Analysis   map[string]interface{}        `json:"analysis,omitempty"`
+	Config     *kubernetes.ConfigChange      `json:"config,omitempty"`
+}
+
+// EventFilter selects which events a subscriber receives
+type EventFilter struct {
+	Namespace string
+	Selector  labels.Selector
+}
+
+// Matches reports whether an event passes the filter
+func (f EventFilter) Matches(event *DeploymentEvent) bool {
+	if f.Namespace != "" && event.Deployment.Namespace != f.Namespace {
+		return false
+	}
+	if f.Selector != nil && !f.Selector.Empty() && !f.Selector.Matches(labels.Set(event.Deployment.Labels)) {
+		return false
+	}
+	return true
+}
+
+// Subscriber receives deployment events from an EventHub
+type Subscriber struct {
+	events  chan DeploymentEvent
+	filter  EventFilter
+	dropped atomic.Uint64
+}
+
+// Events returns the channel delivering events; it is closed when the
+// subscriber is removed or the hub is closed
+func (s *Subscriber) Events() <-chan DeploymentEvent {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because the subscriber was too slow
+func (s *Subscriber) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// EventHub fans out informer events to streaming subscribers.
+// It implements kubernetes.DeploymentEventHandler.
+type EventHub struct {
+	informer    *kubernetes.DeploymentInformer
+	analyzer    *kubernetes.DeploymentChangeAnalyzer
+	enrich      bool
+	labels      kubernetes.LabelPropagator
+	nextID      atomic.Uint64
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+	closed      bool
+}
+
+// NewEventHub creates a new event hub. When enrich is true, update and delete
+// events carry the change analysis computed from the informer cache.
+func NewEventHub(informer *kubernetes.DeploymentInformer, enrich bool) *EventHub {
+	return &EventHub{
+		informer:    informer,
+		analyzer:    kubernetes.NewDeploymentChangeAnalyzer(informer),
+		enrich:      enrich,
+		subscribers: make(map[*Subscriber]struct{}),
+	}
+}
+
+// SetLabelPropagator sets the source of labels deployments inherit; it must be
+// called before the informer delivers events
+func (h *EventHub) SetLabelPropagator(propagator kubernetes.LabelPropagator) {
+	h.labels = propagator
+}
+
+// Subscribe registers a new subscriber receiving events that match the filter
+func (h *EventHub) Subscribe(filter EventFilter) *Subscriber {
+	sub := &Subscriber{
+		events: make(chan DeploymentEvent, defaultSubscriberBuffer),
+		filter: filter,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(sub.events)
+		return sub
+	}
+	h.subscribers[sub] = struct{}{}
+
+	serverLog().Debug("Event stream subscriber added", map[string]interface{}{
+		"subscribers": len(h.subscribers),
+	})
+
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its event channel
+func (h *EventHub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, exists := h.subscribers[sub]; !exists {
+		return
+	}
+	delete(h.subscribers, sub)
+	close(sub.events)
+
+	serverLog().Debug("Event stream subscriber removed", map[string]interface{}{
+		"subscribers": len(h.subscribers),
+		"dropped":     sub.Dropped(),
+	})
+}
+
+// SetFilter replaces the filter of an active subscriber
+func (h *EventHub) SetFilter(sub *Subscriber, filter EventFilter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub.filter = filter
+}
+
+// SubscriberCount returns the number of active subscribers
+func (h *EventHub) SubscriberCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// Close removes all subscribers, ending their streams
+func (h *EventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers {
+		close(sub.events)
+		delete(h.subscribers, sub)
+	}
+	h.closed = true
+}
+
+// OnAdd handles deployment add events
+func (h *EventHub) OnAdd(obj *appsv1.Deployment) {
+	h.publish(DeploymentEvent{
+		Type:       EventAdded,
+		Deployment: h.toResponse(obj),
+	})
+}
+
+// OnUpdate handles deployment update events
+func (h *EventHub) OnUpdate(oldObj, newObj *appsv1.Deployment) {
+	event := DeploymentEvent{
+		Type:       EventUpdated,
+		Deployment: h.toResponse(newObj),
+	}
+	if h.enrich && h.hasSubscribers() {
+		event.Changes = h.analyzer.AnalyzeUpdate(oldObj, newObj)
+	}
+	h.publish(event)
+}
+
+// OnDelete handles deployment delete events
+func (h *EventHub) OnDelete(obj *appsv1.Deployment) {
+	event := DeploymentEvent{
+		Type:       EventDeleted,
+		Deployment: h.toResponse(obj),
+	}
+	if h.enrich && h.hasSubscribers() {
+		event.Analysis = h.analyzer.AnalyzeDelete(obj)
+	}
+	h.publish(event)
+}
+
+// OnConfigChange publishes a config_changed event for each cached deployment
+// referencing a ConfigMap or Secret whose data changed or that was deleted.
+// It implements kubernetes.ConfigChangeHandler.
+func (h *EventHub) OnConfigChange(change kubernetes.ConfigChange) {
+	if !h.hasSubscribers() {
+		return
+	}
+	deployments, err := h.informer.DeploymentsReferencing(change.Kind, change.Namespace, change.Name)
+	if err != nil {
+		return
+	}
+	for _, dep := range deployments {
+		h.publish(DeploymentEvent{
+			Type:       EventConfigChanged,
+			Deployment: h.toResponse(dep),
+			Config:     &change,
+		})
+	}
+}
+
+// toResponse converts a deployment for subscribers, including propagated labels
+func (h *EventHub) toResponse(dep *appsv1.Deployment) DeploymentResponse {
+	response := deploymentToResponse(dep)
+	response.Labels = kubernetes.EffectiveLabels(dep, h.labels)
+	return response
+}
+
+// hasSubscribers reports whether anyone is listening, to skip analysis work otherwise
+func (h *EventHub) hasSubscribers() bool {
+	return h.SubscriberCount() > 0
+}
+
+// publish delivers an event to all matching subscribers without blocking;
+// events are dropped for subscribers whose buffer is full
+func (h *EventHub) publish(event DeploymentEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.subscribers) == 0 {
+		return
+	}
+
+	event.ID = h.nextID.Add(1)
+	event.Timestamp = time.Now().UTC()
+
+	for sub := range h.subscribers {
+		if !sub.filter.Matches(&event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestDeployment(namespace, name string, replicas int32, labels map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(replicas),
+		},
+	}
+}
+
+func TestEventHubFiltersAndAnalysis(t *testing.T) {
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(), "", time.Minute)
+	hub := NewEventHub(informer, true)
+
+	selector, _ := labels.Parse("app=web")
+	prod := hub.Subscribe(EventFilter{Namespace: "prod", Selector: selector})
+	all := hub.Subscribe(EventFilter{})
+
+	oldDep := newTestDeployment("prod", "web", 1, map[string]string{"app": "web"})
+	newDep := newTestDeployment("prod", "web", 3, map[string]string{"app": "web"})
+	hub.OnUpdate(oldDep, newDep)
+	hub.OnAdd(newTestDeployment("dev", "api", 1, map[string]string{"app": "api"}))
+
+	event := <-prod.Events()
+	if event.Type != EventUpdated || event.Deployment.Name != "web" {
+		t.Fatalf("Unexpected event: %+v", event)
+	}
+	if len(event.Changes) != 1 || event.Changes[0].Field != "replicas" {
+		t.Errorf("Expected replicas change analysis, got %+v", event.Changes)
+	}
+	select {
+	case extra := <-prod.Events():
+		t.Errorf("Expected filtered subscriber to skip %s/%s", extra.Deployment.Namespace, extra.Deployment.Name)
+	default:
+	}
+
+	if got := len(all.Events()); got != 2 {
+		t.Errorf("Expected unfiltered subscriber to receive 2 events, got %d", got)
+	}
+
+	hub.Unsubscribe(prod)
+	if _, ok := <-prod.Events(); ok {
+		t.Error("Expected channel to be closed after unsubscribe")
+	}
+
+	hub.Close()
+	if hub.SubscriberCount() != 0 {
+		t.Errorf("Expected no subscribers after close, got %d", hub.SubscriberCount())
+	}
+}
+
+func TestEventHubDropsForSlowSubscribers(t *testing.T) {
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(), "", time.Minute)
+	hub := NewEventHub(informer, false)
+	sub := hub.Subscribe(EventFilter{})
+
+	for i := 0; i < defaultSubscriberBuffer+5; i++ {
+		hub.OnAdd(newTestDeployment("default", "web", 1, nil))
+	}
+
+	if sub.Dropped() != 5 {
+		t.Errorf("Expected 5 dropped events, got %d", sub.Dropped())
+	}
+}
+
+func TestWriteSSEEvent(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+
+	event := &DeploymentEvent{ID: 7, Type: EventDeleted, Deployment: DeploymentResponse{Name: "web"}}
+	if err := writeSSEEvent(w, event); err != nil {
+		t.Fatalf("writeSSEEvent() error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "id: 7\nevent: deleted\ndata: {") || !strings.HasSuffix(out, "}\n\n") {
+		t.Errorf("Unexpected SSE frame: %q", out)
+	}
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// clientIPKey is the request user value holding the client address resolved
+// from X-Forwarded-For
+const clientIPKey = "clientIP"
+
+// forwardedPrefixKey is the request user value holding the path prefix a
+// trusted proxy serves the API under
+const forwardedPrefixKey = "forwardedPrefix"
+
+// defaultCORSHeaders are the request headers allowed when none are configured
+var defaultCORSHeaders = []string{"Authorization", "Content-Type"}
+
+// corsMethods are the methods the API accepts cross-origin
+const corsMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+
+// proxyMiddleware lets the API sit behind ingress controllers and SSO proxies.
+// Requests under the configured path prefix are routed as if they had none,
+// and X-Forwarded-For and X-Forwarded-Prefix are honored when the request
+// comes from a trusted proxy.
+func (s *Server) proxyMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	trusted := parseTrustedProxies(s.config.TrustedProxies)
+	prefix := s.config.PathPrefix
+
+	return func(ctx *fasthttp.RequestCtx) {
+		if prefix != "" {
+			if rest, ok := strings.CutPrefix(string(ctx.Path()), prefix); ok && (rest == "" || rest[0] == '/') {
+				if rest == "" {
+					rest = "/"
+				}
+				ctx.URI().SetPath(rest)
+			}
+		}
+
+		if len(trusted) > 0 && trusted.contains(ctx.RemoteIP()) {
+			if ip := forwardedClientIP(string(ctx.Request.Header.Peek("X-Forwarded-For")), trusted); ip != "" {
+				ctx.SetUserValue(clientIPKey, ip)
+			}
+			if forwarded := strings.TrimSuffix(string(ctx.Request.Header.Peek("X-Forwarded-Prefix")), "/"); strings.HasPrefix(forwarded, "/") {
+				ctx.SetUserValue(forwardedPrefixKey, forwarded)
+			}
+		}
+
+		next(ctx)
+	}
+}
+
+// corsMiddleware adds CORS headers for allowed origins and answers preflight
+// requests before authentication, since browsers send them without credentials
+func (s *Server) corsMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	cfg := s.config.CORS
+	if len(cfg.AllowedOrigins) == 0 {
+		return next
+	}
+
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowHeaders := strings.Join(headers, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(ctx *fasthttp.RequestCtx) {
+		origin := string(ctx.Request.Header.Peek("Origin"))
+		if origin == "" {
+			next(ctx)
+			return
+		}
+
+		ctx.Response.Header.Add("Vary", "Origin")
+		allowed := s.corsOriginAllowed(origin)
+		preflight := ctx.IsOptions() && len(ctx.Request.Header.Peek("Access-Control-Request-Method")) > 0
+		if !allowed {
+			if preflight {
+				sendError(ctx, fasthttp.StatusForbidden, "Forbidden", "Origin "+origin+" is not allowed")
+				return
+			}
+			next(ctx)
+			return
+		}
+
+		ctx.Response.Header.Set("Access-Control-Allow-Origin", origin)
+		if !preflight {
+			next(ctx)
+			return
+		}
+
+		ctx.Response.Header.Set("Access-Control-Allow-Methods", corsMethods)
+		ctx.Response.Header.Set("Access-Control-Allow-Headers", allowHeaders)
+		if cfg.MaxAge > 0 {
+			ctx.Response.Header.Set("Access-Control-Max-Age", maxAge)
+		}
+		ctx.SetStatusCode(fasthttp.StatusNoContent)
+	}
+}
+
+// corsOriginAllowed reports whether origin may call the API
+func (s *Server) corsOriginAllowed(origin string) bool {
+	for _, allowed := range s.config.CORS.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWebSocketOrigin accepts WebSocket connections from the server's own
+// origin, from allowed CORS origins and from clients that send no origin
+func (s *Server) checkWebSocketOrigin(ctx *fasthttp.RequestCtx) bool {
+	origin := string(ctx.Request.Header.Peek("Origin"))
+	if origin == "" || s.corsOriginAllowed(origin) {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, string(ctx.Host()))
+}
+
+// externalPrefix returns the path prefix clients reach the API under, as
+// forwarded by a trusted proxy or configured
+func (s *Server) externalPrefix(ctx *fasthttp.RequestCtx) string {
+	if prefix, ok := ctx.UserValue(forwardedPrefixKey).(string); ok {
+		return prefix
+	}
+	return s.config.PathPrefix
+}
+
+// clientIP returns the address of the client, resolved through trusted
+// proxies when they forward it
+func clientIP(ctx *fasthttp.RequestCtx) string {
+	if ip, ok := ctx.UserValue(clientIPKey).(string); ok {
+		return ip
+	}
+	return ctx.RemoteIP().String()
+}
+
+// trustedProxies are the networks whose forwarding headers are trusted
+type trustedProxies []*net.IPNet
+
+// parseTrustedProxies parses IP addresses and CIDR ranges, skipping invalid
+// entries, which configuration validation reports
+func parseTrustedProxies(entries []string) trustedProxies {
+	var networks trustedProxies
+	for _, entry := range entries {
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// contains reports whether ip belongs to a trusted proxy
+func (t trustedProxies) contains(ip net.IP) bool {
+	for _, network := range t {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedClientIP returns the client address from an X-Forwarded-For
+// header: the rightmost address not belonging to a trusted proxy, since
+// addresses further left can be set by the client itself
+func forwardedClientIP(header string, trusted trustedProxies) string {
+	hops := strings.Split(header, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return ""
+		}
+		if !trusted.contains(ip) {
+			return ip.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	cfg := config.DefaultConfig().Server
+	cfg.Auth.Tokens = []config.APIToken{{Name: "ci", Token: "0123456789abcdef"}}
+	cfg.CORS = config.CORSConfig{AllowedOrigins: []string{"https://portal.example.com"}, MaxAge: 10 * time.Minute}
+	srv := NewWithConfig(cfg)
+
+	request := func(method, origin string, preflight bool) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(method)
+		ctx.Request.SetRequestURI("/api/v1/clusters")
+		ctx.Request.Header.Set("Origin", origin)
+		if preflight {
+			ctx.Request.Header.Set("Access-Control-Request-Method", "GET")
+		}
+		srv.Handler()(ctx)
+		return ctx
+	}
+
+	// Preflight requests are answered without a token
+	ctx := request(fasthttp.MethodOptions, "https://portal.example.com", true)
+	if ctx.Response.StatusCode() != fasthttp.StatusNoContent {
+		t.Fatalf("Expected 204 for an allowed preflight, got %d", ctx.Response.StatusCode())
+	}
+	if got := string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")); got != "https://portal.example.com" {
+		t.Errorf("Expected the origin to be allowed, got %q", got)
+	}
+	if got := string(ctx.Response.Header.Peek("Access-Control-Allow-Headers")); got != "Authorization, Content-Type" {
+		t.Errorf("Expected default allowed headers, got %q", got)
+	}
+	if got := string(ctx.Response.Header.Peek("Access-Control-Max-Age")); got != "600" {
+		t.Errorf("Expected max age 600, got %q", got)
+	}
+
+	if ctx := request(fasthttp.MethodOptions, "https://evil.example.com", true); ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Errorf("Expected 403 for a preflight from another origin, got %d", ctx.Response.StatusCode())
+	}
+
+	// Actual requests still require a token, but carry CORS headers
+	ctx = request(fasthttp.MethodGet, "https://portal.example.com", false)
+	if ctx.Response.StatusCode() != fasthttp.StatusUnauthorized || len(ctx.Response.Header.Peek("Access-Control-Allow-Origin")) == 0 {
+		t.Errorf("Expected 401 with CORS headers, got %d", ctx.Response.StatusCode())
+	}
+	if ctx := request(fasthttp.MethodGet, "https://evil.example.com", false); len(ctx.Response.Header.Peek("Access-Control-Allow-Origin")) > 0 {
+		t.Error("Expected no CORS headers for another origin")
+	}
+}
+
+func TestProxyMiddleware(t *testing.T) {
+	cfg := config.DefaultConfig().Server
+	cfg.PathPrefix = "/k6s"
+	cfg.TrustedProxies = []string{"10.0.0.0/8"}
+	srv := NewWithConfig(cfg)
+
+	request := func(uri, remote, forwardedFor string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+		ctx.Request.SetRequestURI(uri)
+		ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP(remote), Port: 40000})
+		if forwardedFor != "" {
+			ctx.Request.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		srv.Handler()(ctx)
+		return ctx
+	}
+
+	for _, uri := range []string{"/k6s/health", "/health"} {
+		if ctx := request(uri, "127.0.0.1", ""); ctx.Response.StatusCode() != fasthttp.StatusOK {
+			t.Errorf("Expected %s to be served, got %d", uri, ctx.Response.StatusCode())
+		}
+	}
+	if ctx := request("/k6shealth", "127.0.0.1", ""); ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected /k6shealth not to match the prefix, got %d", ctx.Response.StatusCode())
+	}
+
+	// Forwarded addresses are only trusted from trusted proxies, skipping proxy hops
+	if ip := clientIP(request("/health", "10.1.2.3", "203.0.113.7, 10.0.0.5")); ip != "203.0.113.7" {
+		t.Errorf("Expected client 203.0.113.7 through trusted proxies, got %s", ip)
+	}
+	if ip := clientIP(request("/health", "198.51.100.1", "203.0.113.7")); ip != "198.51.100.1" {
+		t.Errorf("Expected X-Forwarded-For from an untrusted peer to be ignored, got %s", ip)
+	}
+
+	// The docs and OpenAPI document point at the prefixed paths
+	ctx := request("/k6s/docs", "127.0.0.1", "")
+	if !strings.Contains(string(ctx.Response.Body()), `data-url="/k6s/openapi.json"`) {
+		t.Errorf("Expected the docs to load the prefixed OpenAPI document, got %s", ctx.Response.Body())
+	}
+	ctx = request("/k6s/openapi.json", "127.0.0.1", "")
+	if !strings.Contains(string(ctx.Response.Body()), `"url": "/k6s"`) {
+		t.Error("Expected the OpenAPI document to list the prefix as server URL")
+	}
+}
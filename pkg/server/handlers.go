@@ -6,15 +6,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
-	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
 	"github.com/valyala/fasthttp"
 	appsv1 "k8s.io/api/apps/v1"
 )
 
 // DeploymentHandler handles deployment-related API requests
 type DeploymentHandler struct {
-	informer *kubernetes.DeploymentInformer
+	informer  *kubernetes.DeploymentInformer
+	fieldMask []string
+	labels    kubernetes.LabelPropagator
 }
 
 // NewDeploymentHandler creates a new deployment handler
@@ -24,6 +27,17 @@ func NewDeploymentHandler(informer *kubernetes.DeploymentInformer) *DeploymentHa
 	}
 }
 
+// SetFieldMask sets the fields returned when a request doesn't specify any
+func (dh *DeploymentHandler) SetFieldMask(fields []string) {
+	dh.fieldMask = fields
+}
+
+// SetLabelPropagator sets the source of labels deployments inherit, such as
+// cost allocation labels from their namespace
+func (dh *DeploymentHandler) SetLabelPropagator(propagator kubernetes.LabelPropagator) {
+	dh.labels = propagator
+}
+
 // DeploymentResponse represents a deployment in API response
 type DeploymentResponse struct {
 	Name      string            `json:"name"`
@@ -35,12 +49,25 @@ type DeploymentResponse struct {
 	Age       string            `json:"age"`
 	Image     string            `json:"image,omitempty"`
 	Labels    map[string]string `json:"labels,omitempty"`
+	SpecHash  string            `json:"specHash,omitempty"`
 }
 
-// DeploymentListResponse represents the response for deployment list
+// DeploymentListResponse represents the response for deployment list.
+// Count is the number of items in this page, Total the number matching the
+// query, and Continue the token for the next page when there is one.
 type DeploymentListResponse struct {
-	Items []DeploymentResponse `json:"items"`
-	Count int                  `json:"count"`
+	Items    []DeploymentResponse `json:"items"`
+	Count    int                  `json:"count"`
+	Total    int                  `json:"total"`
+	Continue string               `json:"continue,omitempty"`
+}
+
+// MaskedDeploymentListResponse represents the response for deployment list with a field mask applied
+type MaskedDeploymentListResponse struct {
+	Items    []map[string]interface{} `json:"items"`
+	Count    int                      `json:"count"`
+	Total    int                      `json:"total"`
+	Continue string                   `json:"continue,omitempty"`
 }
 
 // ErrorResponse represents an error response
@@ -54,7 +81,7 @@ func (dh *DeploymentHandler) HandleDeployments(ctx *fasthttp.RequestCtx) {
 	path := string(ctx.Path())
 	method := string(ctx.Method())
 	
-	logger.Debug("Handling deployment request", map[string]interface{}{
+	requestLog(ctx).Debug("Handling deployment request", map[string]interface{}{
 		"method": method,
 		"path":   path,
 	})
@@ -63,6 +90,10 @@ func (dh *DeploymentHandler) HandleDeployments(ctx *fasthttp.RequestCtx) {
 	case "GET":
 		if path == "/api/v1/deployments" {
 			dh.handleListDeployments(ctx)
+		} else if path == "/api/v1/deployments/aggregate" {
+			dh.handleAggregateDeployments(ctx)
+		} else if path == "/api/v1/deployments/index" {
+			dh.handleIndexDeployments(ctx)
 		} else if strings.HasPrefix(path, "/api/v1/deployments/") {
 			dh.handleGetDeployment(ctx)
 		} else {
@@ -86,42 +117,70 @@ func (dh *DeploymentHandler) handleListDeployments(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	fields, err := dh.requestedFields(ctx)
+	if err != nil {
+		dh.sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+
+	opts, err := parseListOptions(ctx.QueryArgs())
+	if err != nil {
+		dh.sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+
+	if opts.index != "" && !dh.hasIndex(opts.index) {
+		dh.sendError(ctx, fasthttp.StatusBadRequest, "Bad request", dh.unknownIndexMessage(opts.index))
+		return
+	}
+
 	// Get deployments from cache
-	deployments, err := dh.informer.ListDeployments()
+	deployments, err := opts.candidates(dh.informer)
 	if err != nil {
-		logger.Error("Failed to list deployments from cache", err, map[string]interface{}{})
+		requestLog(ctx).Error("Failed to list deployments from cache", err, map[string]interface{}{})
 		dh.sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve deployments")
 		return
 	}
 
-	// Filter by namespace if specified
-	namespace := string(ctx.QueryArgs().Peek("namespace"))
-	if namespace != "" {
-		filteredDeployments := make([]*appsv1.Deployment, 0)
-		for _, dep := range deployments {
-			if dep.Namespace == namespace {
-				filteredDeployments = append(filteredDeployments, dep)
-			}
-		}
-		deployments = filteredDeployments
-	}
+	// Filter, sort and page
+	deployments = opts.filter(deployments, dh.labels)
+	total := len(deployments)
+	opts.sort(deployments)
+	deployments, next := opts.page(deployments)
 
 	// Convert to response format
 	response := DeploymentListResponse{
-		Items: make([]DeploymentResponse, 0, len(deployments)),
-		Count: len(deployments),
+		Items:    make([]DeploymentResponse, 0, len(deployments)),
+		Count:    len(deployments),
+		Total:    total,
+		Continue: next,
 	}
 
 	for _, dep := range deployments {
 		response.Items = append(response.Items, dh.convertDeploymentToResponse(dep))
 	}
 
-	logger.Info("Listed deployments", map[string]interface{}{
+	requestLog(ctx).Info("Listed deployments", map[string]interface{}{
 		"count":     response.Count,
-		"namespace": namespace,
+		"total":     response.Total,
+		"namespace": opts.namespace,
 	})
 
-	dh.sendJSON(ctx, fasthttp.StatusOK, response)
+	if len(fields) > 0 {
+		masked := MaskedDeploymentListResponse{
+			Items:    make([]map[string]interface{}, 0, len(response.Items)),
+			Count:    response.Count,
+			Total:    response.Total,
+			Continue: response.Continue,
+		}
+		for _, item := range response.Items {
+			masked.Items = append(masked.Items, maskDeployment(item, fields))
+		}
+		dh.sendJSON(ctx, fasthttp.StatusOK, masked)
+		return
+	}
+
+	sendDeploymentListJSON(ctx, fasthttp.StatusOK, response)
 }
 
 // handleGetDeployment handles GET /api/v1/deployments/{namespace}/{name}
@@ -144,6 +203,12 @@ func (dh *DeploymentHandler) handleGetDeployment(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	fields, err := dh.requestedFields(ctx)
+	if err != nil {
+		dh.sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+
 	// Check if informer is ready
 	if !dh.informer.IsStarted() {
 		dh.sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Deployment informer is not started")
@@ -161,7 +226,7 @@ func (dh *DeploymentHandler) handleGetDeployment(ctx *fasthttp.RequestCtx) {
 		if strings.Contains(err.Error(), "not found") {
 			dh.sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Deployment %s/%s not found", namespace, name))
 		} else {
-			logger.Error("Failed to get deployment from cache", err, map[string]interface{}{
+			requestLog(ctx).Error("Failed to get deployment from cache", err, map[string]interface{}{
 				"namespace": namespace,
 				"name":      name,
 			})
@@ -172,20 +237,33 @@ func (dh *DeploymentHandler) handleGetDeployment(ctx *fasthttp.RequestCtx) {
 
 	response := dh.convertDeploymentToResponse(deployment)
 	
-	logger.Info("Retrieved deployment", map[string]interface{}{
+	requestLog(ctx).Info("Retrieved deployment", map[string]interface{}{
 		"namespace": namespace,
 		"name":      name,
 	})
 
-	dh.sendJSON(ctx, fasthttp.StatusOK, response)
+	if len(fields) > 0 {
+		dh.sendJSON(ctx, fasthttp.StatusOK, maskDeployment(response, fields))
+		return
+	}
+
+	sendDeploymentJSON(ctx, fasthttp.StatusOK, response)
 }
 
 // convertDeploymentToResponse converts a Kubernetes deployment to API response format
 func (dh *DeploymentHandler) convertDeploymentToResponse(dep *appsv1.Deployment) DeploymentResponse {
+	response := deploymentToResponse(dep)
+	response.Labels = kubernetes.EffectiveLabels(dep, dh.labels)
+	return response
+}
+
+// deploymentToResponse converts a Kubernetes deployment to API response format
+func deploymentToResponse(dep *appsv1.Deployment) DeploymentResponse {
 	response := DeploymentResponse{
 		Name:      dep.Name,
 		Namespace: dep.Namespace,
 		Labels:    dep.Labels,
+		SpecHash:  drift.SpecHash(dep),
 	}
 
 	// Set replica counts
@@ -209,14 +287,81 @@ func (dh *DeploymentHandler) convertDeploymentToResponse(dep *appsv1.Deployment)
 	return response
 }
 
+// requestedFields returns the field mask for a request: the "fields" query
+// parameter if present, otherwise the configured default mask
+func (dh *DeploymentHandler) requestedFields(ctx *fasthttp.RequestCtx) ([]string, error) {
+	return requestedFields(ctx, dh.fieldMask)
+}
+
+// requestedFields returns the "fields" query parameter if present, otherwise defaults
+func requestedFields(ctx *fasthttp.RequestCtx, defaults []string) ([]string, error) {
+	param := string(ctx.QueryArgs().Peek("fields"))
+	if param == "" {
+		return defaults, nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !config.IsDeploymentField(field) {
+			return nil, fmt.Errorf("unknown field %q (valid fields: %s)", field, strings.Join(config.DeploymentFields, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// maskDeployment returns only the requested fields of a deployment response
+func maskDeployment(dep DeploymentResponse, fields []string) map[string]interface{} {
+	masked := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		switch field {
+		case "name":
+			masked["name"] = dep.Name
+		case "namespace":
+			masked["namespace"] = dep.Namespace
+		case "replicas":
+			masked["replicas"] = dep.Replicas
+		case "ready":
+			masked["ready"] = dep.Ready
+		case "updated":
+			masked["updated"] = dep.Updated
+		case "available":
+			masked["available"] = dep.Available
+		case "age":
+			masked["age"] = dep.Age
+		case "image":
+			masked["image"] = dep.Image
+		case "labels":
+			masked["labels"] = dep.Labels
+		case "specHash":
+			masked["specHash"] = dep.SpecHash
+		}
+	}
+	return masked
+}
+
 // sendJSON sends a JSON response
 func (dh *DeploymentHandler) sendJSON(ctx *fasthttp.RequestCtx, statusCode int, data interface{}) {
+	sendJSON(ctx, statusCode, data)
+}
+
+// sendError sends an error response
+func (dh *DeploymentHandler) sendError(ctx *fasthttp.RequestCtx, statusCode int, errType, message string) {
+	sendError(ctx, statusCode, errType, message)
+}
+
+// sendJSON sends a JSON response
+func sendJSON(ctx *fasthttp.RequestCtx, statusCode int, data interface{}) {
 	ctx.SetStatusCode(statusCode)
 	ctx.SetContentType("application/json")
 	
 	jsonData, err := json.Marshal(data)
 	if err != nil {
-		logger.Error("Failed to marshal JSON response", err, map[string]interface{}{})
+		requestLog(ctx).Error("Failed to marshal JSON response", err, map[string]interface{}{})
 		ctx.SetStatusCode(fasthttp.StatusInternalServerError)
 		fmt.Fprintf(ctx, `{"error":"internal server error","message":"failed to marshal response"}`)
 		return
@@ -231,12 +376,12 @@ func (dh *DeploymentHandler) sendJSON(ctx *fasthttp.RequestCtx, statusCode int,
 }
 
 // sendError sends an error response
-func (dh *DeploymentHandler) sendError(ctx *fasthttp.RequestCtx, statusCode int, errType, message string) {
+func sendError(ctx *fasthttp.RequestCtx, statusCode int, errType, message string) {
 	response := ErrorResponse{
 		Error:   errType,
 		Message: message,
 	}
-	dh.sendJSON(ctx, statusCode, response)
+	sendJSON(ctx, statusCode, response)
 }
 
 // formatAge formats a time duration into a human-readable age string
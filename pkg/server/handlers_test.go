@@ -205,6 +205,51 @@ func TestConvertDeploymentToResponse(t *testing.T) {
 	}
 }
 
+func TestMaskDeployment(t *testing.T) {
+	response := DeploymentResponse{
+		Name:      "web",
+		Namespace: "prod",
+		Replicas:  3,
+		Image:     "nginx:1.25",
+		Labels:    map[string]string{"app": "web"},
+	}
+
+	masked := maskDeployment(response, []string{"name", "replicas"})
+
+	if len(masked) != 2 {
+		t.Fatalf("Expected 2 fields, got %d: %v", len(masked), masked)
+	}
+	if masked["name"] != "web" || masked["replicas"] != int32(3) {
+		t.Errorf("Unexpected masked values: %v", masked)
+	}
+	if _, exists := masked["labels"]; exists {
+		t.Error("Expected labels to be masked out")
+	}
+}
+
+func TestRequestedFields(t *testing.T) {
+	handler := &DeploymentHandler{}
+	handler.SetFieldMask([]string{"name"})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/v1/deployments")
+	fields, err := handler.requestedFields(ctx)
+	if err != nil || len(fields) != 1 || fields[0] != "name" {
+		t.Errorf("Expected default field mask, got %v (err: %v)", fields, err)
+	}
+
+	ctx.Request.SetRequestURI("/api/v1/deployments?fields=name,image")
+	fields, err = handler.requestedFields(ctx)
+	if err != nil || len(fields) != 2 {
+		t.Errorf("Expected requested fields to override default, got %v (err: %v)", fields, err)
+	}
+
+	ctx.Request.SetRequestURI("/api/v1/deployments?fields=secret")
+	if _, err := handler.requestedFields(ctx); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+}
+
 // int32Ptr returns a pointer to an int32 value
 func int32Ptr(i int32) *int32 {
 	return &i
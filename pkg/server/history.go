@@ -0,0 +1,112 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/valyala/fasthttp"
+)
+
+// HistoryEventResponse is a recorded deployment event
+type HistoryEventResponse = history.Event
+
+// HistoryResponse lists recorded deployment events, most recent first
+type HistoryResponse struct {
+	Items []HistoryEventResponse `json:"items"`
+	Count int                    `json:"count"`
+}
+
+// HistorySamplesResponse lists the recorded replica and restart samples of
+// deployments, oldest first for each deployment
+type HistorySamplesResponse struct {
+	Items []history.Sample `json:"items"`
+	Count int              `json:"count"`
+}
+
+// SetEventHistory sets the event store queried at /api/v1/history
+func (s *Server) SetEventHistory(store *history.EventStore) {
+	s.eventHistory = store
+}
+
+// handleHistory handles GET /api/v1/history. "namespace" and "name" select
+// a deployment's events, "since" takes an RFC 3339 time or a duration such
+// as "2h", and "limit" bounds the number of events.
+func (s *Server) handleHistory(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.eventHistory == nil {
+		s.handleServiceUnavailable(ctx, "Event history not enabled (history.events.enabled)")
+		return
+	}
+
+	args := ctx.QueryArgs()
+	query := history.EventQuery{
+		Namespace: string(args.Peek("namespace")),
+		Name:      string(args.Peek("name")),
+	}
+	if raw := string(args.Peek("since")); raw != "" {
+		since, err := history.ParseSince(raw, time.Now())
+		if err != nil {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+			return
+		}
+		query.Since = since
+	}
+	if raw := string(args.Peek("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "limit must be a positive number")
+			return
+		}
+		query.Limit = n
+	}
+
+	events, err := s.eventHistory.Query(query)
+	if err != nil {
+		requestLog(ctx).Error("Failed to query event history", err, nil)
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to query event history")
+		return
+	}
+	sendJSON(ctx, fasthttp.StatusOK, HistoryResponse{Items: events, Count: len(events)})
+}
+
+// handleHistorySamples handles GET /api/v1/history/samples, the replica and
+// restart time series recorded by anomaly detection. "namespace" and "name"
+// select a deployment's samples and "since" takes an RFC 3339 time or a
+// duration such as "2h".
+func (s *Server) handleHistorySamples(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.eventHistory == nil {
+		s.handleServiceUnavailable(ctx, "Event history not enabled (history.events.enabled)")
+		return
+	}
+
+	args := ctx.QueryArgs()
+	query := history.SampleQuery{
+		Namespace: string(args.Peek("namespace")),
+		Name:      string(args.Peek("name")),
+	}
+	if raw := string(args.Peek("since")); raw != "" {
+		since, err := history.ParseSince(raw, time.Now())
+		if err != nil {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+			return
+		}
+		query.Since = since
+	}
+
+	samples, err := s.eventHistory.Samples(query)
+	if err != nil {
+		requestLog(ctx).Error("Failed to query samples", err, nil)
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to query samples")
+		return
+	}
+	sendJSON(ctx, fasthttp.StatusOK, HistorySamplesResponse{Items: samples, Count: len(samples)})
+}
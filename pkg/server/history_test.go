@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/valyala/fasthttp"
+)
+
+func TestHandleHistory(t *testing.T) {
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/history", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without event history, got %d", ctx.Response.StatusCode())
+	}
+
+	store, err := history.OpenEventStore(filepath.Join(t.TempDir(), "events.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	now := time.Now()
+	for _, event := range []history.Event{
+		{Time: now.Add(-3 * time.Hour), Type: history.EventAdded, Namespace: "shop", Name: "web"},
+		{Time: now.Add(-time.Hour), Type: history.EventUpdated, Namespace: "shop", Name: "web"},
+		{Time: now.Add(-time.Hour), Type: history.EventAdded, Namespace: "shop", Name: "api"},
+	} {
+		if err := store.Record(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv.SetEventHistory(store)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 3},
+		{"?namespace=shop&name=web", 2},
+		{"?name=web&since=2h", 1},
+		{"?since=" + now.Add(-4*time.Hour).UTC().Format(time.RFC3339), 3},
+		{"?limit=1", 1},
+	}
+	for _, tt := range tests {
+		ctx := serve(srv, fasthttp.MethodGet, "/api/v1/history"+tt.query, "")
+		if ctx.Response.StatusCode() != fasthttp.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tt.query, ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+		var resp HistoryResponse
+		if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		if resp.Count != tt.want || len(resp.Items) != tt.want {
+			t.Errorf("%s: expected %d events, got %+v", tt.query, tt.want, resp)
+		}
+	}
+
+	for _, query := range []string{"?since=yesterday", "?limit=0"} {
+		if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/history"+query, ""); ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, ctx.Response.StatusCode())
+		}
+	}
+	if ctx := serve(srv, fasthttp.MethodPost, "/api/v1/history", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestHandleHistorySamples(t *testing.T) {
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/history/samples", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without event history, got %d", ctx.Response.StatusCode())
+	}
+
+	store, err := history.OpenEventStore(filepath.Join(t.TempDir(), "events.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	now := time.Now()
+	if err := store.RecordSamples([]history.Sample{
+		{Time: now.Add(-3 * time.Hour), Namespace: "shop", Name: "web", Replicas: 3, Available: 3},
+		{Time: now.Add(-time.Hour), Namespace: "shop", Name: "web", Replicas: 3, Available: 2, Restarts: 4},
+		{Time: now.Add(-time.Hour), Namespace: "shop", Name: "api", Replicas: 1, Available: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetEventHistory(store)
+
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/history/samples?namespace=shop&name=web&since=2h", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var resp HistorySamplesResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if resp.Count != 1 || resp.Items[0].Restarts != 4 {
+		t.Errorf("Expected the last sample of shop/web, got %+v", resp)
+	}
+
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/history/samples?since=yesterday", ""); ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid since, got %d", ctx.Response.StatusCode())
+	}
+	if ctx := serve(srv, fasthttp.MethodPost, "/api/v1/history/samples", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", ctx.Response.StatusCode())
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// IndexValue is a value of a deployment cache index and how many deployments
+// have it
+type IndexValue struct {
+	Value       string `json:"value"`
+	Deployments int    `json:"deployments"`
+}
+
+// DeploymentIndexResponse represents the values of a deployment cache index
+type DeploymentIndexResponse struct {
+	Index  string       `json:"index"`
+	Values []IndexValue `json:"values"`
+}
+
+// handleIndexDeployments handles GET /api/v1/deployments/index. With a value
+// it returns the deployments the index maps it to, otherwise the values of the
+// index.
+func (dh *DeploymentHandler) handleIndexDeployments(ctx *fasthttp.RequestCtx) {
+	if !dh.informer.IsStarted() || !dh.informer.HasSynced() {
+		dh.sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Deployment informer cache is not synced")
+		return
+	}
+
+	index := string(ctx.QueryArgs().Peek("name"))
+	if !dh.hasIndex(index) {
+		dh.sendError(ctx, fasthttp.StatusBadRequest, "Bad request", dh.unknownIndexMessage(index))
+		return
+	}
+
+	if !ctx.QueryArgs().Has("value") {
+		counts, err := dh.informer.IndexValues(index)
+		if err != nil {
+			requestLog(ctx).Error("Failed to list index values", err, map[string]interface{}{"index": index})
+			dh.sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve index values")
+			return
+		}
+		response := DeploymentIndexResponse{Index: index, Values: make([]IndexValue, 0, len(counts))}
+		for value, deployments := range counts {
+			response.Values = append(response.Values, IndexValue{Value: value, Deployments: deployments})
+		}
+		sort.Slice(response.Values, func(i, j int) bool { return response.Values[i].Value < response.Values[j].Value })
+		dh.sendJSON(ctx, fasthttp.StatusOK, response)
+		return
+	}
+
+	deployments, err := dh.informer.DeploymentsByIndex(index, string(ctx.QueryArgs().Peek("value")))
+	if err != nil {
+		requestLog(ctx).Error("Failed to look up deployments by index", err, map[string]interface{}{"index": index})
+		dh.sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve deployments")
+		return
+	}
+	response := DeploymentListResponse{
+		Items: make([]DeploymentResponse, 0, len(deployments)),
+		Count: len(deployments),
+		Total: len(deployments),
+	}
+	for _, dep := range deployments {
+		response.Items = append(response.Items, dh.convertDeploymentToResponse(dep))
+	}
+	sendDeploymentListJSON(ctx, fasthttp.StatusOK, response)
+}
+
+// hasIndex reports whether name is an index of the deployment cache
+func (dh *DeploymentHandler) hasIndex(name string) bool {
+	for _, index := range dh.informer.Indexes() {
+		if index == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownIndexMessage explains that name isn't an index of the deployment
+// cache
+func (dh *DeploymentHandler) unknownIndexMessage(name string) string {
+	return fmt.Sprintf("invalid index %q (valid indexes: %s)", name, strings.Join(dh.informer.Indexes(), ", "))
+}
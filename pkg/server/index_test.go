@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIndexDeployments(t *testing.T) {
+	withImage := func(dep *appsv1.Deployment, image string) *appsv1.Deployment {
+		dep.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: image}}
+		return dep
+	}
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(
+		withImage(newTestDeployment("payments", "api", 3, map[string]string{"app": "api"}), "api:2.0"),
+		withImage(newTestDeployment("payments", "worker", 2, map[string]string{"app": "api"}), "api:2.0"),
+		withImage(newTestDeployment("search", "web", 4, map[string]string{"app": "web"}), "nginx:1.25"),
+	), "", time.Minute)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("informer.Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	handler := NewDeploymentHandler(informer)
+	get := func(uri string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		ctx.Request.Header.SetMethod("GET")
+		handler.HandleDeployments(ctx)
+		return ctx
+	}
+
+	ctx := get("/api/v1/deployments/index?name=image")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var values DeploymentIndexResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &values); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	want := []IndexValue{{Value: "api:2.0", Deployments: 2}, {Value: "nginx:1.25", Deployments: 1}}
+	if values.Index != "image" || len(values.Values) != len(want) || values.Values[0] != want[0] || values.Values[1] != want[1] {
+		t.Errorf("Expected image values %+v, got %+v", want, values)
+	}
+
+	var list DeploymentListResponse
+	_ = json.Unmarshal(get("/api/v1/deployments/index?name=app&value=payments%2Fapi").Response.Body(), &list)
+	if list.Total != 2 || list.Items[0].Name != "api" || list.Items[1].Name != "worker" {
+		t.Errorf("Expected api and worker by app label, got %+v", list)
+	}
+
+	// The list endpoint filters by image
+	list = DeploymentListResponse{}
+	_ = json.Unmarshal(get("/api/v1/deployments?image=nginx:1.25").Response.Body(), &list)
+	if list.Total != 1 || list.Items[0].Name != "web" {
+		t.Errorf("Expected web running nginx:1.25, got %+v", list)
+	}
+
+	// The list endpoint selects deployments by an index
+	list = DeploymentListResponse{}
+	_ = json.Unmarshal(get("/api/v1/deployments?index=app&value=payments%2Fapi&sortBy=-replicas").Response.Body(), &list)
+	if list.Total != 2 || list.Items[0].Name != "api" {
+		t.Errorf("Expected api and worker by app label, got %+v", list)
+	}
+
+	for _, uri := range []string{
+		"/api/v1/deployments/index",
+		"/api/v1/deployments/index?name=color",
+		"/api/v1/deployments?index=color&value=red",
+		"/api/v1/deployments?index=app",
+	} {
+		if status := get(uri).Response.StatusCode(); status != fasthttp.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", uri, status)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+)
+
+// JobListResponse represents the response for job list
+type JobListResponse struct {
+	Items []kubernetes.JobSummary `json:"items"`
+	Count int                     `json:"count"`
+}
+
+// CronJobResponse represents a CronJob with the cached jobs it created, most
+// recent first
+type CronJobResponse struct {
+	kubernetes.CronJobSummary
+	Jobs []kubernetes.JobSummary `json:"jobs"`
+}
+
+// CronJobListResponse represents the response for CronJob list
+type CronJobListResponse struct {
+	Items []kubernetes.CronJobSummary `json:"items"`
+	Count int                         `json:"count"`
+}
+
+// SetJobInformer sets the Job and CronJob cache served at /api/v1/jobs and
+// /api/v1/cronjobs
+func (s *Server) SetJobInformer(informer *kubernetes.JobInformer) {
+	s.jobs = informer
+}
+
+// handleJobs handles GET /api/v1/jobs, filtered by the namespace and status
+// query parameters, and GET /api/v1/jobs/{namespace}/{name}
+func (s *Server) handleJobs(ctx *fasthttp.RequestCtx) {
+	name, namespace, ok := s.jobRequest(ctx, "/api/v1/jobs")
+	if !ok {
+		return
+	}
+
+	if name != "" {
+		job, exists := s.jobs.GetJob(namespace, name)
+		if !exists {
+			sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Job %s/%s not found", namespace, name))
+			return
+		}
+		sendJSON(ctx, fasthttp.StatusOK, kubernetes.SummarizeJob(job))
+		return
+	}
+
+	jobs, err := s.jobs.ListJobs(namespace)
+	if err != nil {
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve jobs")
+		return
+	}
+	status := string(ctx.QueryArgs().Peek("status"))
+	response := JobListResponse{Items: make([]kubernetes.JobSummary, 0, len(jobs))}
+	for _, job := range jobs {
+		summary := kubernetes.SummarizeJob(job)
+		if status != "" && !strings.EqualFold(summary.Status, status) {
+			continue
+		}
+		response.Items = append(response.Items, summary)
+	}
+	response.Count = len(response.Items)
+	sendJSON(ctx, fasthttp.StatusOK, response)
+}
+
+// handleCronJobs handles GET /api/v1/cronjobs, filtered by the namespace
+// query parameter, and GET /api/v1/cronjobs/{namespace}/{name}
+func (s *Server) handleCronJobs(ctx *fasthttp.RequestCtx) {
+	name, namespace, ok := s.jobRequest(ctx, "/api/v1/cronjobs")
+	if !ok {
+		return
+	}
+
+	if name != "" {
+		cronJob, exists := s.jobs.GetCronJob(namespace, name)
+		if !exists {
+			sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("CronJob %s/%s not found", namespace, name))
+			return
+		}
+		jobs, err := s.jobs.CronJobJobs(namespace, name)
+		if err != nil {
+			sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve jobs")
+			return
+		}
+		response := CronJobResponse{
+			CronJobSummary: s.jobs.SummarizeCronJob(cronJob),
+			Jobs:           make([]kubernetes.JobSummary, 0, len(jobs)),
+		}
+		for _, job := range jobs {
+			response.Jobs = append(response.Jobs, kubernetes.SummarizeJob(job))
+		}
+		sendJSON(ctx, fasthttp.StatusOK, response)
+		return
+	}
+
+	cronJobs, err := s.jobs.ListCronJobs(namespace)
+	if err != nil {
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve CronJobs")
+		return
+	}
+	response := CronJobListResponse{
+		Items: make([]kubernetes.CronJobSummary, 0, len(cronJobs)),
+		Count: len(cronJobs),
+	}
+	for _, cronJob := range cronJobs {
+		response.Items = append(response.Items, s.jobs.SummarizeCronJob(cronJob))
+	}
+	sendJSON(ctx, fasthttp.StatusOK, response)
+}
+
+// jobRequest checks a Job or CronJob request and returns the namespace and
+// name of its path, or an empty name and the namespace query parameter for
+// a list. It sends the error response and returns false when the request
+// can't be served.
+func (s *Server) jobRequest(ctx *fasthttp.RequestCtx, prefix string) (name, namespace string, ok bool) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return "", "", false
+	}
+	if s.jobs == nil {
+		s.handleServiceUnavailable(ctx, "Job informer not configured")
+		return "", "", false
+	}
+	if !s.jobs.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Job informer cache is not synced")
+		return "", "", false
+	}
+
+	path := strings.TrimPrefix(string(ctx.Path()), prefix)
+	if path == "" {
+		return "", string(ctx.QueryArgs().Peek("namespace")), true
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Expected a path of the form "+prefix+"/{namespace}/{name}")
+		return "", "", false
+	}
+	return parts[1], parts[0], true
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleJobs(t *testing.T) {
+	controller := true
+	owned := metav1.ObjectMeta{
+		Name:            "backup-1",
+		Namespace:       "shop",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup", Controller: &controller}},
+	}
+	clientset := fake.NewSimpleClientset(
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "shop"}, Spec: batchv1.CronJobSpec{Schedule: "@daily"}},
+		&batchv1.Job{ObjectMeta: owned, Status: batchv1.JobStatus{
+			Succeeded:  1,
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "shop"}, Status: batchv1.JobStatus{Active: 1}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "search"}},
+	)
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/jobs", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a job informer, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	jobs := kubernetes.NewJobInformer(clientset, "", time.Minute)
+	if err := jobs.Start(ctx); err != nil {
+		t.Fatalf("jobs.Start() error = %v", err)
+	}
+	defer jobs.Stop()
+	srv.SetJobInformer(jobs)
+
+	resp := serve(srv, fasthttp.MethodGet, "/api/v1/jobs?namespace=shop&status=running", "")
+	if resp.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Response.StatusCode(), resp.Response.Body())
+	}
+	var list JobListResponse
+	if err := json.Unmarshal(resp.Response.Body(), &list); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if list.Count != 1 || list.Items[0].Name != "migrate" || list.Items[0].Active != 1 {
+		t.Errorf("Expected the running job of shop, got %+v", list)
+	}
+
+	resp = serve(srv, fasthttp.MethodGet, "/api/v1/jobs/shop/backup-1", "")
+	var job kubernetes.JobSummary
+	if err := json.Unmarshal(resp.Response.Body(), &job); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if job.Status != kubernetes.JobComplete || job.Completions != "1/1" || job.CronJob != "backup" {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+	if resp := serve(srv, fasthttp.MethodGet, "/api/v1/jobs/shop/unknown", ""); resp.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown job, got %d", resp.Response.StatusCode())
+	}
+	if resp := serve(srv, fasthttp.MethodGet, "/api/v1/jobs/shop", ""); resp.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Errorf("Expected 400 without a job name, got %d", resp.Response.StatusCode())
+	}
+
+	resp = serve(srv, fasthttp.MethodGet, "/api/v1/cronjobs/shop/backup", "")
+	var cronJob CronJobResponse
+	if err := json.Unmarshal(resp.Response.Body(), &cronJob); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if cronJob.Schedule != "@daily" || cronJob.Succeeded != 1 || len(cronJob.Jobs) != 1 || cronJob.Jobs[0].Name != "backup-1" {
+		t.Errorf("Unexpected CronJob: %+v", cronJob)
+	}
+
+	resp = serve(srv, fasthttp.MethodGet, "/api/v1/cronjobs", "")
+	var cronJobs CronJobListResponse
+	if err := json.Unmarshal(resp.Response.Body(), &cronJobs); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if cronJobs.Count != 1 {
+		t.Errorf("Expected one CronJob, got %+v", cronJobs)
+	}
+}
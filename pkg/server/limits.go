@@ -0,0 +1,186 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/time/rate"
+)
+
+// idleClientTTL is how long the bucket of a client that stopped making
+// requests is kept
+const idleClientTTL = 10 * time.Minute
+
+// RateLimiter keeps a token bucket per client
+type RateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	clients   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+// clientBucket is the token bucket of one client
+type clientBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a limiter allowing each client requestsPerSecond
+// requests on average and burst at once. A burst of 0 allows one second's
+// worth of requests.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst <= 0 {
+		burst = int(math.Ceil(requestsPerSecond))
+	}
+	return &RateLimiter{
+		limit:   rate.Limit(requestsPerSecond),
+		burst:   burst,
+		clients: make(map[string]*clientBucket),
+	}
+}
+
+// Allow takes a token from the bucket of a client, and otherwise returns
+// how long the client should wait before retrying
+func (l *RateLimiter) Allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > idleClientTTL {
+		for key, bucket := range l.clients {
+			if now.Sub(bucket.lastSeen) > idleClientTTL {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, found := l.clients[client]
+	if !found {
+		bucket = &clientBucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[client] = bucket
+	}
+	bucket.lastSeen = now
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// rateLimitMiddleware rejects /api/ requests of clients over their rate limit
+// with 429. Health checks and documentation are not limited. Requests
+// presenting a bearer token are left to authMiddleware when limiting by
+// authenticated principal, see limitsByPrincipal.
+func (s *Server) rateLimitMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if s.rateLimiter == nil {
+		return next
+	}
+	return func(ctx *fasthttp.RequestCtx) {
+		if !strings.HasPrefix(string(ctx.Path()), "/api/") {
+			next(ctx)
+			return
+		}
+		if s.limitsByPrincipal() && hasBearerToken(ctx) {
+			next(ctx)
+			return
+		}
+		if s.allowRequest(ctx, "ip:"+clientIP(ctx)) {
+			next(ctx)
+		}
+	}
+}
+
+// limitsByPrincipal reports whether requests authenticating with a token are
+// limited by the principal they authenticate as. The token itself can't
+// identify a client before it is verified, since a client could present a new
+// one with every request; requests that fail to authenticate, and every
+// request when authentication is off, are limited by IP instead.
+func (s *Server) limitsByPrincipal() bool {
+	return s.rateLimiter != nil && s.config.Limits.RateLimit.Key == "token" && s.config.Auth.Enabled()
+}
+
+// allowRequest takes a token from the bucket of client, and otherwise
+// responds with 429 and returns false
+func (s *Server) allowRequest(ctx *fasthttp.RequestCtx, client string) bool {
+	allowed, retryAfter := s.rateLimiter.Allow(client, time.Now())
+	if !allowed {
+		metrics.APIRateLimited.WithLabelValues(routeFor(string(ctx.Path()))).Inc()
+		ctx.Response.Header.Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		sendError(ctx, fasthttp.StatusTooManyRequests, "Too many requests", "Rate limit exceeded, retry later")
+	}
+	return allowed
+}
+
+// hasBearerToken reports whether a request presents a bearer token
+func hasBearerToken(ctx *fasthttp.RequestCtx) bool {
+	token, found := strings.CutPrefix(string(ctx.Request.Header.Peek("Authorization")), "Bearer ")
+	return found && token != ""
+}
+
+// timeoutMiddleware fails requests that take longer than the configured
+// request timeout with 503. Watch streams and WebSockets outlive any timeout
+// by design, so they are not limited.
+func (s *Server) timeoutMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	timeout := s.config.Limits.RequestTimeout
+	if timeout <= 0 {
+		return next
+	}
+	return func(ctx *fasthttp.RequestCtx) {
+		// The path may still carry the configured path prefix
+		path := string(ctx.Path())
+		if strings.HasSuffix(path, "/api/v1/deployments/watch") || strings.HasSuffix(path, "/api/v1/deployments/ws") {
+			next(ctx)
+			return
+		}
+
+		// Read before the handler runs, which may set user values
+		id := requestID(ctx)
+		done := make(chan struct{})
+		go func() {
+			next(ctx)
+			close(done)
+		}()
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+			// The server sends this response instead, and doesn't reuse ctx
+			// while the handler still runs
+			body, _ := json.Marshal(ErrorResponse{
+				Error:   "Service unavailable",
+				Message: fmt.Sprintf("Request timed out after %s", timeout),
+			})
+			var response fasthttp.Response
+			response.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			response.Header.SetContentType("application/json")
+			if id != "" {
+				response.Header.Set(requestIDHeader, id)
+			}
+			response.SetBody(body)
+			ctx.TimeoutErrorWithResponse(&response)
+		}
+	}
+}
+
+// newRateLimiter returns the limiter of the configured rate limit, or nil
+// without one
+func newRateLimiter(limits config.LimitsConfig) *RateLimiter {
+	if !limits.RateLimit.Enabled() {
+		return nil
+	}
+	return NewRateLimiter(limits.RateLimit.RequestsPerSecond, limits.RateLimit.Burst)
+}
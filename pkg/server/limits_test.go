@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(1, 2)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow("a", now); !ok {
+			t.Fatalf("Expected request %d within the burst to be allowed", i+1)
+		}
+	}
+	ok, retryAfter := limiter.Allow("a", now)
+	if ok || retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("Expected the third request to wait up to a second, got %v, %v", ok, retryAfter)
+	}
+	if ok, _ := limiter.Allow("b", now); !ok {
+		t.Error("Expected clients to have their own buckets")
+	}
+	if ok, _ := limiter.Allow("a", now.Add(time.Second)); !ok {
+		t.Error("Expected a token after a second")
+	}
+
+	// Idle clients are forgotten
+	limiter.Allow("b", now.Add(2*idleClientTTL))
+	if _, found := limiter.clients["a"]; found {
+		t.Error("Expected the bucket of an idle client to be dropped")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	cfg := config.DefaultConfig().Server
+	cfg.Limits.RateLimit = config.RateLimitConfig{RequestsPerSecond: 1, Key: "token"}
+	cfg.Auth.Tokens = []config.APIToken{
+		{Name: "ci", Token: "0123456789abcdef"},
+		{Name: "dashboard", Token: "fedcba9876543210"},
+	}
+	srv := NewWithConfig(cfg)
+
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments", "0123456789abcdef"); ctx.Response.StatusCode() == fasthttp.StatusTooManyRequests {
+		t.Fatal("Expected the first request to be allowed")
+	}
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments", "0123456789abcdef")
+	if ctx.Response.StatusCode() != fasthttp.StatusTooManyRequests || string(ctx.Response.Header.Peek("Retry-After")) != "1" {
+		t.Errorf("Expected 429 with Retry-After: 1, got %d %q", ctx.Response.StatusCode(), ctx.Response.Header.Peek("Retry-After"))
+	}
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments", "fedcba9876543210"); ctx.Response.StatusCode() == fasthttp.StatusTooManyRequests {
+		t.Error("Expected another token to have its own limit")
+	}
+	if ctx := serve(srv, fasthttp.MethodGet, "/health", ""); ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Errorf("Expected health checks not to be limited, got %d", ctx.Response.StatusCode())
+	}
+
+	// Unverified tokens don't get buckets of their own
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments", "random-1"); ctx.Response.StatusCode() != fasthttp.StatusUnauthorized {
+		t.Errorf("Expected an invalid token to be unauthorized, got %d", ctx.Response.StatusCode())
+	}
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments", "random-2"); ctx.Response.StatusCode() != fasthttp.StatusTooManyRequests {
+		t.Errorf("Expected invalid tokens to share the limit of their IP, got %d", ctx.Response.StatusCode())
+	}
+	if len(srv.rateLimiter.clients) != 3 {
+		t.Errorf("Expected buckets for two principals and one IP, got %d", len(srv.rateLimiter.clients))
+	}
+}
+
+// serveInmemory serves server on an in-memory listener and returns a client
+// of it
+func serveInmemory(t *testing.T, server *fasthttp.Server) *fasthttp.Client {
+	t.Helper()
+	ln := fasthttputil.NewInmemoryListener()
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		_ = server.Serve(ln)
+	}()
+	return &fasthttp.Client{
+		Dial: func(addr string) (net.Conn, error) { return ln.Dial() },
+	}
+}
+
+func TestRequestLimits(t *testing.T) {
+	cfg := config.DefaultConfig().Server
+	cfg.Limits.MaxBodySize = 64
+	cfg.Limits.RequestTimeout = 50 * time.Millisecond
+	srv := NewWithConfig(cfg)
+	client := serveInmemory(t, srv.httpServer())
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.SetRequestURI("http://localhost/api/v1/batch/scale/preview")
+	req.SetBodyString(`{"namespace":"` + strings.Repeat("a", 100) + `"}`)
+	if err := client.Do(req, resp); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.StatusCode() != fasthttp.StatusRequestEntityTooLarge {
+		t.Errorf("Expected a large body to be rejected with 413, got %d", resp.StatusCode())
+	}
+
+	// A handler slower than the timeout
+	release := make(chan struct{})
+	defer close(release)
+	slow := srv.timeoutMiddleware(func(ctx *fasthttp.RequestCtx) {
+		<-release
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+	client = serveInmemory(t, &fasthttp.Server{Handler: slow})
+
+	req.Reset()
+	req.SetRequestURI("http://localhost/api/v1/deployments")
+	if err := client.Do(req, resp); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.StatusCode() != fasthttp.StatusServiceUnavailable || !strings.Contains(string(resp.Body()), "timed out") {
+		t.Errorf("Expected a slow request to time out with 503, got %d: %s", resp.StatusCode(), resp.Body())
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	srv := New(0)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	httpSrv := srv.serve(&fasthttp.Server{Handler: func(ctx *fasthttp.RequestCtx) {
+		close(started)
+		<-release
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	}})
+	client := serveInmemory(t, httpSrv)
+
+	statuses := make(chan int, 1)
+	go func() {
+		status, _, err := client.Get(nil, "http://localhost/slow")
+		if err != nil {
+			status = 0
+		}
+		statuses <- status
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- srv.Shutdown(context.Background())
+	}()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Expected shutdown to wait for the in-flight request, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if status, response := probe(t, srv, "/readyz"); status != fasthttp.StatusServiceUnavailable || result(response, "shutdown").Healthy {
+		t.Errorf("Expected readiness to fail while shutting down, got %d %+v", status, response)
+	}
+
+	close(release)
+	if status := <-statuses; status != fasthttp.StatusOK {
+		t.Errorf("Expected the in-flight request to complete, got %d", status)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
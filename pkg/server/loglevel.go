@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/valyala/fasthttp"
+)
+
+// LogLevelRequest changes the log level of the process and of components.
+// An empty component level removes its override.
+type LogLevelRequest struct {
+	Level      string            `json:"level,omitempty"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+// LogLevelResponse reports the log levels in effect
+type LogLevelResponse struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+// handleLogLevel handles GET and PUT /api/v1/loglevel. Changing levels is
+// only available when API authentication is configured, and lasts until the
+// process restarts or receives SIGHUP.
+func (s *Server) handleLogLevel(ctx *fasthttp.RequestCtx) {
+	switch {
+	case ctx.IsGet():
+		sendJSON(ctx, fasthttp.StatusOK, currentLogLevels())
+		return
+	case !ctx.IsPut():
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if !s.config.Auth.Enabled() {
+		sendError(ctx, fasthttp.StatusForbidden, "Forbidden", "Changing log levels requires API authentication to be configured (server.auth)")
+		return
+	}
+
+	var req LogLevelRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Level == "" && len(req.Components) == 0 {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Set level or components")
+		return
+	}
+	// Validate every level before changing any
+	if req.Level != "" {
+		if _, err := logger.ParseLevel(req.Level); err != nil {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+			return
+		}
+	}
+	for component, level := range req.Components {
+		if component == "" {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Component names must not be empty")
+			return
+		}
+		if level == "" {
+			continue
+		}
+		if _, err := logger.ParseLevel(level); err != nil {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", fmt.Sprintf("Component %s: %v", component, err))
+			return
+		}
+	}
+
+	if req.Level != "" {
+		logger.SetLevel(req.Level)
+	}
+	for component, level := range req.Components {
+		_ = logger.SetComponentLevel(component, level)
+	}
+
+	levels := currentLogLevels()
+	principal, _ := ctx.UserValue(principalKey).(string)
+	requestLog(ctx).Info("Log level changed", map[string]interface{}{
+		"level":      levels.Level,
+		"components": levels.Components,
+		"principal":  principal,
+	})
+	sendJSON(ctx, fasthttp.StatusOK, levels)
+}
+
+// currentLogLevels returns the log levels in effect
+func currentLogLevels() LogLevelResponse {
+	return LogLevelResponse{Level: logger.Level(), Components: logger.ComponentLevels()}
+}
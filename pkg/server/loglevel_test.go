@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/valyala/fasthttp"
+)
+
+func TestLogLevel(t *testing.T) {
+	level := logger.Level()
+	defer func() {
+		logger.ResetComponentLevels()
+		logger.SetLevel(level)
+	}()
+	logger.SetLevel("info")
+
+	cfg := config.DefaultConfig().Server
+	cfg.Auth.Tokens = []config.APIToken{
+		{Name: "admin", Token: "0123456789abcdef", Role: "admin"},
+		{Name: "viewer", Token: "fedcba9876543210", Role: "viewer"},
+	}
+	srv := NewWithConfig(cfg)
+
+	put := func(body, token string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(fasthttp.MethodPut)
+		ctx.Request.SetRequestURI("/api/v1/loglevel")
+		ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		ctx.Request.SetBodyString(body)
+		srv.Handler()(ctx)
+		return ctx
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		token  string
+		status int
+	}{
+		{"viewer", `{"level":"debug"}`, "fedcba9876543210", fasthttp.StatusForbidden},
+		{"empty request", `{}`, "0123456789abcdef", fasthttp.StatusBadRequest},
+		{"unknown level", `{"level":"loud"}`, "0123456789abcdef", fasthttp.StatusBadRequest},
+		{"unknown component level", `{"level":"debug","components":{"informer":"loud"}}`, "0123456789abcdef", fasthttp.StatusBadRequest},
+		{"level and components", `{"level":"warn","components":{"informer":"debug","server":"error"}}`, "0123456789abcdef", fasthttp.StatusOK},
+		{"remove override", `{"components":{"server":""}}`, "0123456789abcdef", fasthttp.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := put(tt.body, tt.token)
+			if ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+
+	// Rejected requests change nothing, accepted ones are reported
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/loglevel", "fedcba9876543210")
+	var levels LogLevelResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &levels); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if levels.Level != "warn" || len(levels.Components) != 1 || levels.Components["informer"] != "debug" {
+		t.Errorf("Expected warn with an informer override, got %+v", levels)
+	}
+
+	unauthenticated := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(unauthenticated, fasthttp.MethodPut, "/api/v1/loglevel", ""); ctx.Response.StatusCode() != fasthttp.StatusForbidden {
+		t.Errorf("Expected changes to require authentication, got %d", ctx.Response.StatusCode())
+	}
+}
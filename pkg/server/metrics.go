@@ -0,0 +1,65 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// SetMetricsGatherer sets the registry served at /metrics, on the API port
+// or on the configured metrics port
+func (s *Server) SetMetricsGatherer(gatherer prometheus.Gatherer) {
+	s.metrics = fasthttpadaptor.NewFastHTTPHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
+
+// metricsMiddleware counts requests by route template and status and
+// observes their latency
+func (s *Server) metricsMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		metrics.APIRequestsInFlight.Inc()
+		defer metrics.APIRequestsInFlight.Dec()
+
+		next(ctx)
+
+		method := string(ctx.Method())
+		route := routeFor(string(ctx.Path()))
+		metrics.APIRequests.WithLabelValues(method, route, strconv.Itoa(ctx.Response.StatusCode())).Inc()
+		metrics.APIRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// handleMetrics serves the metrics registry, unless it has a dedicated port
+func (s *Server) handleMetrics(ctx *fasthttp.RequestCtx) {
+	if s.metrics == nil || s.config.MetricsPort != 0 {
+		s.handleNotFound(ctx)
+		return
+	}
+	s.metrics(ctx)
+}
+
+// serveMetrics serves only /metrics on the dedicated metrics port
+func (s *Server) serveMetrics() {
+	addr := ":" + strconv.Itoa(s.config.MetricsPort)
+	serverLog().Info("Metrics server listening", map[string]interface{}{
+		"address": addr,
+	})
+	srv := s.serve(&fasthttp.Server{Handler: func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) != "/metrics" {
+			s.handleNotFound(ctx)
+			return
+		}
+		s.metrics(ctx)
+	}})
+	err := srv.ListenAndServe(addr)
+	if err != nil {
+		serverLog().Error("Metrics server failed", err, map[string]interface{}{
+			"address": addr,
+		})
+	}
+}
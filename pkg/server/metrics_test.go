@@ -0,0 +1,45 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/valyala/fasthttp"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if err := metrics.RegisterAPIMetrics(registry); err != nil {
+		t.Fatalf("RegisterAPIMetrics() error = %v", err)
+	}
+	srv := New(0)
+	srv.SetMetricsGatherer(registry)
+
+	serve(srv, fasthttp.MethodGet, "/api/v1/deployments/prod/web", "")
+	ctx := serve(srv, fasthttp.MethodGet, "/metrics", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	body := string(ctx.Response.Body())
+	for _, want := range []string{
+		`k6s_api_requests_total{method="GET",route="/api/v1/deployments/{namespace}/{name}",status="503"}`,
+		`k6s_api_request_duration_seconds_count{method="GET",route="/api/v1/deployments/{namespace}/{name}"}`,
+		`k6s_api_requests_in_flight 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %s", want)
+		}
+	}
+
+	// With a dedicated port the API port doesn't serve metrics
+	cfg := config.DefaultConfig().Server
+	cfg.MetricsPort = 9102
+	srv = NewWithConfig(cfg)
+	srv.SetMetricsGatherer(registry)
+	if ctx := serve(srv, fasthttp.MethodGet, "/metrics", ""); ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 with a metrics port, got %d", ctx.Response.StatusCode())
+	}
+}
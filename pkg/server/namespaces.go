@@ -0,0 +1,212 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NamespaceResponse represents a namespace in API responses. Deployments is
+// the number of cached deployments in it, when deployments are cached.
+type NamespaceResponse struct {
+	Name        string            `json:"name"`
+	Phase       string            `json:"phase"`
+	Age         string            `json:"age"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Deployments *int              `json:"deployments,omitempty"`
+}
+
+// NamespaceListResponse represents the response for namespace list
+type NamespaceListResponse struct {
+	Items []NamespaceResponse `json:"items"`
+	Count int                 `json:"count"`
+}
+
+// PodResponse represents a pod in API responses. Ready is the number of
+// ready containers out of all containers, as in kubectl.
+type PodResponse struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Phase     string `json:"phase"`
+	Ready     string `json:"ready"`
+	Restarts  int32  `json:"restarts"`
+	Node      string `json:"node,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	Age       string `json:"age"`
+}
+
+// PodListResponse represents the response for pod list
+type PodListResponse struct {
+	Items []PodResponse `json:"items"`
+	Count int           `json:"count"`
+}
+
+// SetNamespaceInformer sets the namespace cache served at /api/v1/namespaces
+func (s *Server) SetNamespaceInformer(informer *kubernetes.NamespaceInformer) {
+	s.namespaces = informer
+}
+
+// SetPodInformer sets the pod cache served at /api/v1/namespaces/{name}/pods
+func (s *Server) SetPodInformer(informer *kubernetes.PodInformer) {
+	s.pods = informer
+}
+
+// handleNamespaces handles GET /api/v1/namespaces, GET /api/v1/namespaces/{name},
+// and the deployments and pods of a namespace at
+// /api/v1/namespaces/{name}/deployments and /api/v1/namespaces/{name}/pods
+func (s *Server) handleNamespaces(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.namespaces == nil {
+		s.handleServiceUnavailable(ctx, "Namespace informer not configured")
+		return
+	}
+	if !s.namespaces.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Namespace informer cache is not synced")
+		return
+	}
+
+	path := strings.TrimPrefix(string(ctx.Path()), "/api/v1/namespaces")
+	if path == "" {
+		s.handleListNamespaces(ctx)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if parts[0] == "" || len(parts) > 2 {
+		s.handleNotFound(ctx)
+		return
+	}
+	namespace, exists := s.namespaces.GetNamespace(parts[0])
+	if !exists {
+		sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Namespace %s not found", parts[0]))
+		return
+	}
+
+	if len(parts) == 1 {
+		sendJSON(ctx, fasthttp.StatusOK, namespaceResponse(namespace, s.deploymentCounts()))
+		return
+	}
+	switch parts[1] {
+	case "deployments":
+		s.handleNamespaceDeployments(ctx, namespace.Name)
+	case "pods":
+		s.handleNamespacePods(ctx, namespace.Name)
+	default:
+		s.handleNotFound(ctx)
+	}
+}
+
+// handleListNamespaces handles GET /api/v1/namespaces
+func (s *Server) handleListNamespaces(ctx *fasthttp.RequestCtx) {
+	counts := s.deploymentCounts()
+	namespaces := s.namespaces.ListNamespaces()
+
+	response := NamespaceListResponse{
+		Items: make([]NamespaceResponse, 0, len(namespaces)),
+		Count: len(namespaces),
+	}
+	for _, namespace := range namespaces {
+		response.Items = append(response.Items, namespaceResponse(namespace, counts))
+	}
+	sendJSON(ctx, fasthttp.StatusOK, response)
+}
+
+// handleNamespaceDeployments handles GET /api/v1/namespaces/{name}/deployments
+// as GET /api/v1/deployments?namespace={name}
+func (s *Server) handleNamespaceDeployments(ctx *fasthttp.RequestCtx, namespace string) {
+	if s.deploymentHandler == nil {
+		s.handleServiceUnavailable(ctx, "Deployment informer not configured")
+		return
+	}
+	ctx.QueryArgs().Set("namespace", namespace)
+	s.deploymentHandler.handleListDeployments(ctx)
+}
+
+// handleNamespacePods handles GET /api/v1/namespaces/{name}/pods
+func (s *Server) handleNamespacePods(ctx *fasthttp.RequestCtx, namespace string) {
+	if s.pods == nil {
+		s.handleServiceUnavailable(ctx, "Pod informer not configured")
+		return
+	}
+	if !s.pods.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Pod informer cache is not synced")
+		return
+	}
+
+	pods, err := s.pods.ListPods(namespace)
+	if err != nil {
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve pods")
+		return
+	}
+
+	response := PodListResponse{
+		Items: make([]PodResponse, 0, len(pods)),
+		Count: len(pods),
+	}
+	for _, pod := range pods {
+		response.Items = append(response.Items, podResponse(pod))
+	}
+	sendJSON(ctx, fasthttp.StatusOK, response)
+}
+
+// deploymentCounts returns the number of cached deployments per namespace,
+// or nil when deployments are not cached or not synced
+func (s *Server) deploymentCounts() map[string]int {
+	if s.deploymentHandler == nil {
+		return nil
+	}
+	informer := s.deploymentHandler.informer
+	if !informer.IsStarted() || !informer.HasSynced() {
+		return nil
+	}
+	counts, err := informer.IndexValues(kubernetes.NamespaceIndex)
+	if err != nil {
+		return nil
+	}
+	return counts
+}
+
+// namespaceResponse converts a namespace, counting its deployments in counts
+// unless counts is nil
+func namespaceResponse(namespace *corev1.Namespace, counts map[string]int) NamespaceResponse {
+	response := NamespaceResponse{
+		Name:   namespace.Name,
+		Phase:  string(namespace.Status.Phase),
+		Age:    kubernetes.FormatAge(namespace.CreationTimestamp.Time),
+		Labels: namespace.Labels,
+	}
+	if counts != nil {
+		count := counts[namespace.Name]
+		response.Deployments = &count
+	}
+	return response
+}
+
+// podResponse converts a pod to its API response
+func podResponse(pod *corev1.Pod) PodResponse {
+	var ready int
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Ready {
+			ready++
+		}
+		restarts += status.RestartCount
+	}
+
+	return PodResponse{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Phase:     string(pod.Status.Phase),
+		Ready:     fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+		Restarts:  restarts,
+		Node:      pod.Spec.NodeName,
+		IP:        pod.Status.PodIP,
+		Age:       kubernetes.FormatAge(pod.CreationTimestamp.Time),
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleNamespaces(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"team": "payments"}}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "search"}},
+		newTestDeployment("payments", "api", 3, nil),
+		newTestDeployment("payments", "worker", 2, nil),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "payments"},
+			Spec:       corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "api"}, {Name: "proxy"}}},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Ready: true, RestartCount: 2}, {RestartCount: 1}},
+			},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "search"}},
+	)
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/namespaces", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a namespace informer, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	namespaces := kubernetes.NewNamespaceInformer(clientset, time.Minute)
+	if err := namespaces.Start(ctx); err != nil {
+		t.Fatalf("namespaces.Start() error = %v", err)
+	}
+	defer namespaces.Stop()
+	deployments := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+	srv.SetNamespaceInformer(namespaces)
+	srv.SetDeploymentInformer(deployments)
+
+	resp := serve(srv, fasthttp.MethodGet, "/api/v1/namespaces", "")
+	if resp.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Response.StatusCode(), resp.Response.Body())
+	}
+	var list NamespaceListResponse
+	if err := json.Unmarshal(resp.Response.Body(), &list); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if list.Count != 2 || list.Items[0].Name != "payments" || list.Items[1].Name != "search" {
+		t.Fatalf("Unexpected namespaces: %+v", list)
+	}
+	if payments := list.Items[0]; payments.Phase != "Active" || payments.Labels["team"] != "payments" || payments.Deployments == nil || *payments.Deployments != 2 {
+		t.Errorf("Unexpected payments namespace: %+v", payments)
+	}
+	if search := list.Items[1]; search.Deployments == nil || *search.Deployments != 0 {
+		t.Errorf("Expected no deployments in search, got %+v", search)
+	}
+
+	if resp := serve(srv, fasthttp.MethodGet, "/api/v1/namespaces/unknown", ""); resp.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown namespace, got %d", resp.Response.StatusCode())
+	}
+	if resp := serve(srv, fasthttp.MethodDelete, "/api/v1/namespaces/payments", ""); resp.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for DELETE, got %d", resp.Response.StatusCode())
+	}
+
+	resp = serve(srv, fasthttp.MethodGet, "/api/v1/namespaces/payments/deployments?sortBy=-replicas", "")
+	var deps DeploymentListResponse
+	if err := json.Unmarshal(resp.Response.Body(), &deps); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if deps.Count != 2 || deps.Items[0].Name != "api" {
+		t.Errorf("Expected the deployments of payments, got %+v", deps)
+	}
+
+	if resp := serve(srv, fasthttp.MethodGet, "/api/v1/namespaces/payments/pods", ""); resp.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a pod informer, got %d", resp.Response.StatusCode())
+	}
+	pods := kubernetes.NewPodInformer(clientset, "", time.Minute)
+	if err := pods.Start(ctx); err != nil {
+		t.Fatalf("pods.Start() error = %v", err)
+	}
+	defer pods.Stop()
+	srv.SetPodInformer(pods)
+
+	resp = serve(srv, fasthttp.MethodGet, "/api/v1/namespaces/payments/pods", "")
+	var podList PodListResponse
+	if err := json.Unmarshal(resp.Response.Body(), &podList); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	want := PodResponse{Name: "api-1", Namespace: "payments", Phase: "Running", Ready: "1/2", Restarts: 3, Node: "node-1"}
+	if podList.Count != 1 {
+		t.Fatalf("Expected only the pods of payments, got %+v", podList)
+	}
+	if got := podList.Items[0]; got.Name != want.Name || got.Phase != want.Phase || got.Ready != want.Ready || got.Restarts != want.Restarts || got.Node != want.Node {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
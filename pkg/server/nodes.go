@@ -0,0 +1,122 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeResponse represents a node in API responses. Pods is the number of
+// cached pods scheduled on it, when pods are cached.
+type NodeResponse struct {
+	kubernetes.NodeSummary
+	Pods *int `json:"pods,omitempty"`
+}
+
+// NodeListResponse represents the response for node list
+type NodeListResponse struct {
+	Items []NodeResponse `json:"items"`
+	Count int            `json:"count"`
+}
+
+// SetNodeInformer sets the node cache served at /api/v1/nodes
+func (s *Server) SetNodeInformer(informer *kubernetes.NodeInformer) {
+	s.nodes = informer
+}
+
+// handleNodes handles GET /api/v1/nodes, GET /api/v1/nodes/{name}, and the
+// pods scheduled on a node at /api/v1/nodes/{name}/pods
+func (s *Server) handleNodes(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.nodes == nil {
+		s.handleServiceUnavailable(ctx, "Node informer not configured")
+		return
+	}
+	if !s.nodes.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Node informer cache is not synced")
+		return
+	}
+
+	path := strings.TrimPrefix(string(ctx.Path()), "/api/v1/nodes")
+	if path == "" {
+		s.handleListNodes(ctx)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if parts[0] == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "pods") {
+		s.handleNotFound(ctx)
+		return
+	}
+	node, exists := s.nodes.GetNode(parts[0])
+	if !exists {
+		sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Node %s not found", parts[0]))
+		return
+	}
+
+	if len(parts) == 1 {
+		sendJSON(ctx, fasthttp.StatusOK, s.nodeResponse(node))
+		return
+	}
+	s.handleNodePods(ctx, node.Name)
+}
+
+// handleListNodes handles GET /api/v1/nodes
+func (s *Server) handleListNodes(ctx *fasthttp.RequestCtx) {
+	nodes := s.nodes.ListNodes()
+
+	response := NodeListResponse{
+		Items: make([]NodeResponse, 0, len(nodes)),
+		Count: len(nodes),
+	}
+	for _, node := range nodes {
+		response.Items = append(response.Items, s.nodeResponse(node))
+	}
+	sendJSON(ctx, fasthttp.StatusOK, response)
+}
+
+// handleNodePods handles GET /api/v1/nodes/{name}/pods
+func (s *Server) handleNodePods(ctx *fasthttp.RequestCtx, node string) {
+	if s.pods == nil {
+		s.handleServiceUnavailable(ctx, "Pod informer not configured")
+		return
+	}
+	if !s.pods.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Pod informer cache is not synced")
+		return
+	}
+
+	pods, err := s.pods.ListNodePods(node)
+	if err != nil {
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve pods")
+		return
+	}
+
+	response := PodListResponse{
+		Items: make([]PodResponse, 0, len(pods)),
+		Count: len(pods),
+	}
+	for _, pod := range pods {
+		response.Items = append(response.Items, podResponse(pod))
+	}
+	sendJSON(ctx, fasthttp.StatusOK, response)
+}
+
+// nodeResponse converts a node, counting the cached pods scheduled on it when
+// pods are cached and synced
+func (s *Server) nodeResponse(node *corev1.Node) NodeResponse {
+	response := NodeResponse{NodeSummary: kubernetes.SummarizeNode(node)}
+	if s.pods != nil && s.pods.HasSynced() {
+		if pods, err := s.pods.ListNodePods(node.Name); err == nil {
+			count := len(pods)
+			response.Pods = &count
+		}
+	}
+	return response
+}
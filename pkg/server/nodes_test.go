@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleNodes(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}, Spec: corev1.PodSpec{NodeName: "node-1"}},
+	)
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/nodes", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a node informer, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	nodes := kubernetes.NewNodeInformer(clientset, time.Minute)
+	if err := nodes.Start(ctx); err != nil {
+		t.Fatalf("nodes.Start() error = %v", err)
+	}
+	defer nodes.Stop()
+	srv.SetNodeInformer(nodes)
+
+	resp := serve(srv, fasthttp.MethodGet, "/api/v1/nodes", "")
+	if resp.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Response.StatusCode(), resp.Response.Body())
+	}
+	var list NodeListResponse
+	if err := json.Unmarshal(resp.Response.Body(), &list); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if list.Count != 2 || list.Items[0].Name != "node-1" {
+		t.Fatalf("Unexpected nodes: %+v", list)
+	}
+	node := list.Items[0]
+	if node.Status != "Ready" || node.Allocatable.CPU != "2" || node.Allocatable.Memory != "4Gi" || len(node.Taints) != 1 {
+		t.Errorf("Unexpected node-1: %+v", node)
+	}
+	if node.Pods != nil {
+		t.Errorf("Expected no pod count without a pod informer, got %d", *node.Pods)
+	}
+
+	if resp := serve(srv, fasthttp.MethodGet, "/api/v1/nodes/unknown", ""); resp.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown node, got %d", resp.Response.StatusCode())
+	}
+	if resp := serve(srv, fasthttp.MethodGet, "/api/v1/nodes/node-1/pods", ""); resp.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a pod informer, got %d", resp.Response.StatusCode())
+	}
+
+	pods := kubernetes.NewPodInformer(clientset, "", time.Minute)
+	if err := pods.Start(ctx); err != nil {
+		t.Fatalf("pods.Start() error = %v", err)
+	}
+	defer pods.Stop()
+	srv.SetPodInformer(pods)
+
+	resp = serve(srv, fasthttp.MethodGet, "/api/v1/nodes/node-1", "")
+	var got NodeResponse
+	if err := json.Unmarshal(resp.Response.Body(), &got); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if got.Pods == nil || *got.Pods != 1 {
+		t.Errorf("Expected one pod on node-1, got %+v", got)
+	}
+
+	resp = serve(srv, fasthttp.MethodGet, "/api/v1/nodes/node-1/pods", "")
+	var podList PodListResponse
+	if err := json.Unmarshal(resp.Response.Body(), &podList); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if podList.Count != 1 || podList.Items[0].Name != "web-1" || podList.Items[0].Node != "node-1" {
+		t.Errorf("Expected the pods of node-1, got %+v", podList)
+	}
+}
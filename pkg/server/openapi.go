@@ -0,0 +1,952 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/anomaly"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/batch"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/compliance"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/propagation"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/rollout"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
+	"github.com/valyala/fasthttp"
+)
+
+// openAPIComponents lists the types published as named schemas. Schemas are
+// generated from the Go types so the document follows the handlers.
+var openAPIComponents = map[string]reflect.Type{
+	"DeploymentResponse":                  reflect.TypeOf(DeploymentResponse{}),
+	"DeploymentListResponse":              reflect.TypeOf(DeploymentListResponse{}),
+	"MaskedDeploymentListResponse":        reflect.TypeOf(MaskedDeploymentListResponse{}),
+	"ErrorResponse":                       reflect.TypeOf(ErrorResponse{}),
+	"DeploymentEvent":                     reflect.TypeOf(DeploymentEvent{}),
+	"WSClientMessage":                     reflect.TypeOf(WSClientMessage{}),
+	"WSServerMessage":                     reflect.TypeOf(WSServerMessage{}),
+	"ScaleRequest":                        reflect.TypeOf(ScaleRequest{}),
+	"CreateDeploymentRequest":             reflect.TypeOf(CreateDeploymentRequest{}),
+	"ScaleResponse":                       reflect.TypeOf(ScaleResponse{}),
+	"DeploymentAggregateResponse":         reflect.TypeOf(DeploymentAggregateResponse{}),
+	"DeploymentIndexResponse":             reflect.TypeOf(DeploymentIndexResponse{}),
+	"DeploymentGroup":                     reflect.TypeOf(DeploymentGroup{}),
+	"ClusterConfig":                       reflect.TypeOf(config.ClusterConfig{}),
+	"ClusterResponse":                     reflect.TypeOf(ClusterResponse{}),
+	"ClusterListResponse":                 reflect.TypeOf(ClusterListResponse{}),
+	"ClusterConnectivityResponse":         reflect.TypeOf(ClusterConnectivityResponse{}),
+	"ClusterStatusResponse":               reflect.TypeOf(ClusterStatusResponse{}),
+	"ScalePreviewRequest":                 reflect.TypeOf(ScalePreviewRequest{}),
+	"BatchPlan":                           reflect.TypeOf(batch.Plan{}),
+	"BatchChange":                         reflect.TypeOf(batch.Change{}),
+	"DriftReport":                         reflect.TypeOf(drift.Report{}),
+	"DriftGroupReport":                    reflect.TypeOf(drift.GroupReport{}),
+	"DeploymentHashes":                    reflect.TypeOf(drift.DeploymentHashes{}),
+	"ManifestDriftReport":                 reflect.TypeOf(drift.ManifestReport{}),
+	"ComplianceReport":                    reflect.TypeOf(compliance.Report{}),
+	"ComplianceFinding":                   reflect.TypeOf(compliance.Finding{}),
+	"RolloutReport":                       reflect.TypeOf(rollout.Report{}),
+	"RolloutHealth":                       reflect.TypeOf(rollout.Health{}),
+	"DeploymentDrift":                     reflect.TypeOf(drift.DeploymentDrift{}),
+	"DeploymentChange":                    reflect.TypeOf(kubernetes.DeploymentChange{}),
+	"ClusterDeploymentResponse":           reflect.TypeOf(ClusterDeploymentResponse{}),
+	"ClusterDeploymentListResponse":       reflect.TypeOf(ClusterDeploymentListResponse{}),
+	"MaskedClusterDeploymentListResponse": reflect.TypeOf(MaskedClusterDeploymentListResponse{}),
+	"BuildInfo":                           reflect.TypeOf(version.Info{}),
+	"ProbeResponse":                       reflect.TypeOf(ProbeResponse{}),
+	"CheckResult":                         reflect.TypeOf(CheckResult{}),
+	"PropagationReport":                   reflect.TypeOf(propagation.Report{}),
+	"PropagatedDeployment":                reflect.TypeOf(propagation.DeploymentStatus{}),
+	"PropagationStatus":                   reflect.TypeOf(propagation.ClusterStatus{}),
+	"HistoryResponse":                     reflect.TypeOf(HistoryResponse{}),
+	"HistoryEvent":                        reflect.TypeOf(history.Event{}),
+	"HistorySamplesResponse":              reflect.TypeOf(HistorySamplesResponse{}),
+	"HistorySample":                       reflect.TypeOf(history.Sample{}),
+	"AnomalyReport":                       reflect.TypeOf(anomaly.Report{}),
+	"Anomaly":                             reflect.TypeOf(anomaly.Anomaly{}),
+	"LogLevelRequest":                     reflect.TypeOf(LogLevelRequest{}),
+	"LogLevelResponse":                    reflect.TypeOf(LogLevelResponse{}),
+	"NamespaceResponse":                   reflect.TypeOf(NamespaceResponse{}),
+	"NamespaceListResponse":               reflect.TypeOf(NamespaceListResponse{}),
+	"PodResponse":                         reflect.TypeOf(PodResponse{}),
+	"PodListResponse":                     reflect.TypeOf(PodListResponse{}),
+	"NodeResponse":                        reflect.TypeOf(NodeResponse{}),
+	"NodeListResponse":                    reflect.TypeOf(NodeListResponse{}),
+	"NodeResources":                       reflect.TypeOf(kubernetes.NodeResources{}),
+	"NodeTaint":                           reflect.TypeOf(kubernetes.NodeTaint{}),
+	"NodeCondition":                       reflect.TypeOf(kubernetes.NodeCondition{}),
+	"JobSummary":                          reflect.TypeOf(kubernetes.JobSummary{}),
+	"JobListResponse":                     reflect.TypeOf(JobListResponse{}),
+	"CronJobSummary":                      reflect.TypeOf(kubernetes.CronJobSummary{}),
+	"CronJobResponse":                     reflect.TypeOf(CronJobResponse{}),
+	"CronJobListResponse":                 reflect.TypeOf(CronJobListResponse{}),
+	"Route":                               reflect.TypeOf(kubernetes.Route{}),
+	"RouteListResponse":                   reflect.TypeOf(RouteListResponse{}),
+	"PVCResponse":                         reflect.TypeOf(PVCResponse{}),
+	"PVCListResponse":                     reflect.TypeOf(PVCListResponse{}),
+	"RevisionResponse":                    reflect.TypeOf(RevisionResponse{}),
+	"DeploymentRevisionsResponse":         reflect.TypeOf(DeploymentRevisionsResponse{}),
+	"ResourceEventResponse":               reflect.TypeOf(ResourceEventResponse{}),
+	"DeploymentEventsResponse":            reflect.TypeOf(DeploymentEventsResponse{}),
+	"ConfigSourceResponse":                reflect.TypeOf(ConfigSourceResponse{}),
+	"DeploymentConfigResponse":            reflect.TypeOf(DeploymentConfigResponse{}),
+}
+
+// swaggerUIAssets is where Swagger UI is loaded from, pinned to an exact
+// release: npm versions can't be republished, so the files can't change
+// under the page
+const swaggerUIAssets = "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+// swaggerUIScript starts Swagger UI for the OpenAPI document in the data-url
+// attribute of its element, keeping the inline script constant for the
+// Content-Security-Policy
+const swaggerUIScript = `
+    var el = document.getElementById("swagger-ui");
+    window.ui = SwaggerUIBundle({ url: el.dataset.url, dom_id: "#swagger-ui" });
+  `
+
+// swaggerUIPage renders Swagger UI for the OpenAPI document at the
+// HTML-escaped URL substituted for %[2]s
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>k6s API</title>
+  <link rel="stylesheet" href="%[1]s/swagger-ui.css" crossorigin="anonymous" referrerpolicy="no-referrer">
+</head>
+<body>
+  <div id="swagger-ui" data-url="%[2]s"></div>
+  <script src="%[1]s/swagger-ui-bundle.js" crossorigin="anonymous" referrerpolicy="no-referrer"></script>
+  <script>` + swaggerUIScript + `</script>
+</body>
+</html>
+`
+
+// swaggerUIPolicy only lets the docs page run the pinned Swagger UI files and
+// its own script, and fetch the OpenAPI document from this server
+var swaggerUIPolicy = func() string {
+	sum := sha256.Sum256([]byte(swaggerUIScript))
+	return "default-src 'none'; " +
+		"script-src " + swaggerUIAssets + "/swagger-ui-bundle.js 'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'; " +
+		"style-src " + swaggerUIAssets + "/swagger-ui.css 'unsafe-inline'; " +
+		"img-src 'self' data:; connect-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+}()
+
+// handleOpenAPI handles GET /openapi.json. Behind a path prefix, the
+// document lists it as the server URL so that clients call the right paths.
+func (s *Server) handleOpenAPI(ctx *fasthttp.RequestCtx) {
+	doc, err := s.openAPIDocument(s.externalPrefix(ctx))
+	if err != nil {
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to generate OpenAPI document")
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetContentType("application/json")
+	ctx.SetBody(doc)
+}
+
+// openAPIDocument returns the encoded OpenAPI document served under prefix.
+// The document without a prefix is generated once.
+func (s *Server) openAPIDocument(prefix string) ([]byte, error) {
+	if prefix != "" {
+		spec := OpenAPISpec()
+		spec["servers"] = []interface{}{map[string]interface{}{"url": prefix}}
+		return json.MarshalIndent(spec, "", "  ")
+	}
+
+	s.openAPIOnce.Do(func() {
+		s.openAPIDoc, s.openAPIErr = json.MarshalIndent(OpenAPISpec(), "", "  ")
+	})
+	return s.openAPIDoc, s.openAPIErr
+}
+
+// handleDocs handles GET /docs with Swagger UI
+func (s *Server) handleDocs(ctx *fasthttp.RequestCtx) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetContentType("text/html; charset=utf-8")
+	ctx.Response.Header.Set("Content-Security-Policy", swaggerUIPolicy)
+
+	specURL := html.EscapeString(s.externalPrefix(ctx) + "/openapi.json")
+	fmt.Fprintf(ctx, swaggerUIPage, swaggerUIAssets, specURL)
+}
+
+// OpenAPISpec returns the OpenAPI v3 document describing the HTTP API
+func OpenAPISpec() map[string]interface{} {
+	schemas := make(map[string]interface{}, len(openAPIComponents))
+	for name, t := range openAPIComponents {
+		schemas[name] = schemaForStruct(t)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "k6s API",
+			"description": "Deployment data served from the k6s informer cache. API requests may pass cluster=<name> to check they reach the expected cluster.",
+			"version":     "v1",
+		},
+		"paths": map[string]interface{}{
+			"/health": map[string]interface{}{
+				"get": operation("Health check", nil, map[string]interface{}{
+					"200": jsonResponse("Server is healthy", map[string]interface{}{
+						"type":       "object",
+						"properties": map[string]interface{}{"status": map[string]interface{}{"type": "string"}},
+					}),
+				}),
+			},
+			"/livez": map[string]interface{}{
+				"get": operation("Liveness probe: the server answers and, with a sync timeout, became ready in time", []interface{}{
+					queryParam("exclude", "Name of a check to skip; may be repeated"),
+				}, map[string]interface{}{
+					"200": jsonResponse("Every check passes", ref("ProbeResponse")),
+					"503": jsonResponse("A check fails", ref("ProbeResponse")),
+				}),
+			},
+			"/readyz": map[string]interface{}{
+				"get": operation("Readiness probe: the caches of the informers and cluster informers have synced", []interface{}{
+					queryParam("exclude", "Name of a check to skip, e.g. cluster/staging; may be repeated"),
+				}, map[string]interface{}{
+					"200": jsonResponse("Every check passes", ref("ProbeResponse")),
+					"503": jsonResponse("A check fails", ref("ProbeResponse")),
+				}),
+			},
+			"/version": map[string]interface{}{
+				"get": operation("Server version", nil, map[string]interface{}{
+					"200": jsonResponse("Version information", map[string]interface{}{
+						"type":       "object",
+						"properties": map[string]interface{}{"version": map[string]interface{}{"type": "string"}},
+					}),
+				}),
+			},
+			"/metrics": map[string]interface{}{
+				"get": operation("Prometheus metrics of the server, unless served on a dedicated metrics port", nil, map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Metrics in the Prometheus text format",
+						"content":     map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}},
+					},
+					"404": errorResponse("Metrics not configured or served on the metrics port"),
+				}),
+			},
+			"/api/v1/info": map[string]interface{}{
+				"get": operation("Build information of the server", nil, map[string]interface{}{
+					"200": jsonResponse("Version, commit, build date, Go version and platform", ref("BuildInfo")),
+				}),
+			},
+			"/api/v1/deployments": map[string]interface{}{
+				"get": operation("List cached deployments. With cluster=*, deployments of every cluster are listed with their cluster.",
+					append(append(listParams(), indexParams()...),
+						queryParam("cluster", "The cluster this server serves, or * for every configured cluster"),
+					),
+					map[string]interface{}{
+						"200": jsonResponse("Deployments, or masked deployments when a field mask applies", map[string]interface{}{
+							"oneOf": []interface{}{
+								ref("DeploymentListResponse"), ref("MaskedDeploymentListResponse"),
+								ref("ClusterDeploymentListResponse"), ref("MaskedClusterDeploymentListResponse"),
+							},
+						}),
+						"400": errorResponse("Invalid query parameter, continue token or field mask"),
+						"404": errorResponse("Cluster not served by this server"),
+						"503": errorResponse("Informer not configured or not synced, or no clusters configured"),
+					}),
+				"post": map[string]interface{}{
+					"summary": "Create a deployment running one container. Requires API authentication to be configured.",
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": ref("CreateDeploymentRequest")},
+						},
+					},
+					"responses": map[string]interface{}{
+						"201": jsonResponse("The deployment was created", ref("DeploymentResponse")),
+						"400": errorResponse("Invalid request body, name, namespace, image, replicas or labels"),
+						"401": errorResponse("Missing or invalid credentials"),
+						"403": errorResponse("API authentication is not configured, or the caller's role doesn't allow the request"),
+						"404": errorResponse("Namespace not found"),
+						"409": errorResponse("The deployment already exists"),
+						"422": errorResponse("The deployment was rejected by the Kubernetes API"),
+						"503": errorResponse("Kubernetes client not configured"),
+					},
+				},
+			},
+			"/api/v1/deployments/aggregate": map[string]interface{}{
+				"get": operation("Sum cached deployments grouped by a label, including labels propagated from namespaces",
+					[]interface{}{
+						map[string]interface{}{
+							"name":        "groupBy",
+							"in":          "query",
+							"required":    true,
+							"description": "Label key to group by, e.g. team",
+							"schema":      map[string]interface{}{"type": "string"},
+						},
+						queryParam("namespace", "Only include deployments in this namespace"),
+						queryParam("labelSelector", "Kubernetes label selector, e.g. app=web"),
+					},
+					map[string]interface{}{
+						"200": jsonResponse("Deployment groups", ref("DeploymentAggregateResponse")),
+						"400": errorResponse("Missing or invalid groupBy, or invalid label selector"),
+						"503": errorResponse("Informer not configured or not synced"),
+					}),
+			},
+			"/api/v1/deployments/index": map[string]interface{}{
+				"get": operation("Look up cached deployments in an index of the cache, or list the values of the index when no value is given",
+					[]interface{}{
+						map[string]interface{}{
+							"name":        "name",
+							"in":          "query",
+							"required":    true,
+							"description": "Index: " + strings.Join(kubernetes.DeploymentIndexes(), ", ") + " or one configured in controller.informer.indexes",
+							"schema":      map[string]interface{}{"type": "string"},
+						},
+						queryParam("value", "Index value, e.g. nginx:1.25 for image, namespace/app for app and namespace/kind/name for owner"),
+					},
+					map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "The deployments with the value, or the values of the index",
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{"schema": map[string]interface{}{
+									"oneOf": []interface{}{ref("DeploymentListResponse"), ref("DeploymentIndexResponse")},
+								}},
+							},
+						},
+						"400": errorResponse("Missing or unknown index"),
+						"503": errorResponse("Informer not configured or not synced"),
+					}),
+			},
+			"/api/v1/deployments/{name}": map[string]interface{}{
+				"get": operation("Get a deployment in the default namespace",
+					[]interface{}{pathParam("name"), fieldsParam()},
+					deploymentResponses()),
+			},
+			"/api/v1/deployments/{namespace}/{name}": map[string]interface{}{
+				"get": operation("Get a deployment",
+					[]interface{}{pathParam("namespace"), pathParam("name"), fieldsParam()},
+					deploymentResponses()),
+				"delete": operation("Delete a deployment, optionally only if it is still at resourceVersion, after snapshotting it to the history store. Requires API authentication to be configured.",
+					[]interface{}{
+						pathParam("namespace"), pathParam("name"),
+						queryParam("resourceVersion", "Only delete the deployment if it is still at this version"),
+					},
+					map[string]interface{}{
+						"204": map[string]interface{}{"description": "The deployment was deleted"},
+						"400": errorResponse("Invalid path"),
+						"401": errorResponse("Missing or invalid credentials"),
+						"403": errorResponse("API authentication is not configured, or the caller's role doesn't allow the request"),
+						"404": errorResponse("Deployment not found"),
+						"409": errorResponse("The deployment changed since the given resourceVersion"),
+						"503": errorResponse("Kubernetes client not configured"),
+					}),
+			},
+			"/api/v1/deployments/watch": map[string]interface{}{
+				"get": operation("Stream deployment events as Server-Sent Events",
+					eventFilterParams(),
+					map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "text/event-stream of DeploymentEvent messages",
+							"content": map[string]interface{}{
+								"text/event-stream": map[string]interface{}{"schema": ref("DeploymentEvent")},
+							},
+						},
+						"400": errorResponse("Invalid label selector"),
+						"503": errorResponse("Informer not configured"),
+					}),
+			},
+			"/api/v1/deployments/ws": map[string]interface{}{
+				"get": operation("Stream deployment events over a WebSocket. Clients send WSClientMessage and receive WSServerMessage.",
+					eventFilterParams(),
+					map[string]interface{}{
+						"101": map[string]interface{}{"description": "Switching to the WebSocket protocol"},
+						"400": errorResponse("Invalid label selector"),
+						"503": errorResponse("Informer not configured"),
+					}),
+			},
+			"/api/v1/deployments/{namespace}/{name}/scale": map[string]interface{}{
+				"put": map[string]interface{}{
+					"summary":    "Scale a deployment, optionally only if it is still at resourceVersion. Requires API authentication to be configured.",
+					"parameters": []interface{}{pathParam("namespace"), pathParam("name")},
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": ref("ScaleRequest")},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": jsonResponse("The deployment was scaled", ref("ScaleResponse")),
+						"400": errorResponse("Invalid path or request body"),
+						"401": errorResponse("Missing or invalid credentials"),
+						"403": errorResponse("API authentication is not configured, or the caller's role doesn't allow the request"),
+						"404": errorResponse("Deployment not found"),
+						"409": errorResponse("The deployment changed since the given resourceVersion"),
+						"503": errorResponse("Kubernetes client not configured"),
+					},
+				},
+			},
+			"/api/v1/deployments/{namespace}/{name}/revisions": map[string]interface{}{
+				"get": operation("List the rollout revisions of a cached deployment from the replica sets it controls",
+					[]interface{}{pathParam("namespace"), pathParam("name")},
+					map[string]interface{}{
+						"200": jsonResponse("Revisions in ascending order", ref("DeploymentRevisionsResponse")),
+						"400": errorResponse("Invalid path"),
+						"404": errorResponse("Deployment not found"),
+						"503": errorResponse("Informers not configured or not synced"),
+					}),
+			},
+			"/api/v1/deployments/{namespace}/{name}/events": map[string]interface{}{
+				"get": operation("List the cached events of a deployment, its replica sets and their pods, most recent first",
+					[]interface{}{
+						pathParam("namespace"), pathParam("name"),
+						queryParam("type", "Only return events of this type, Normal or Warning"),
+						map[string]interface{}{
+							"name":        "limit",
+							"in":          "query",
+							"description": "Maximum number of events to return",
+							"schema":      map[string]interface{}{"type": "integer", "minimum": 1},
+						},
+					},
+					map[string]interface{}{
+						"200": jsonResponse("Events", ref("DeploymentEventsResponse")),
+						"400": errorResponse("Invalid path, type or limit"),
+						"404": errorResponse("Deployment not found"),
+						"503": errorResponse("Informers not configured or not synced"),
+					}),
+			},
+			"/api/v1/deployments/{namespace}/{name}/config": map[string]interface{}{
+				"get": operation("List the ConfigMaps and Secrets a deployment references, with their keys and last data change; Secret values are never returned",
+					[]interface{}{pathParam("namespace"), pathParam("name")},
+					map[string]interface{}{
+						"200": jsonResponse("Config sources", ref("DeploymentConfigResponse")),
+						"400": errorResponse("Invalid path"),
+						"404": errorResponse("Deployment not found"),
+						"503": errorResponse("Informers not configured or not synced"),
+					}),
+			},
+			"/api/v1/batch/scale/preview": map[string]interface{}{
+				"post": map[string]interface{}{
+					"summary": "Preview scaling the selected cached deployments without applying it, e.g. for approval in a UI",
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": ref("ScalePreviewRequest")},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": jsonResponse("The changes scaling would make", ref("BatchPlan")),
+						"400": errorResponse("Invalid request body or label selector"),
+						"503": errorResponse("Informer not configured or not synced"),
+					},
+				},
+			},
+			"/api/v1/drift": map[string]interface{}{
+				"get": operation("Compare deployment spec hashes across the clusters of each drift group, as of the last check", []interface{}{
+					queryParam("drifted", "Set to true to only return drifted deployments"),
+				}, map[string]interface{}{
+					"200": jsonResponse("The last drift report", ref("DriftReport")),
+					"503": errorResponse("Drift detection not configured or not checked yet"),
+				}),
+			},
+			"/api/v1/drift/manifests": map[string]interface{}{
+				"get": operation("Compare deployments with their declared manifests, recorded by k6s apply or read from the manifest directory, as of the last check", []interface{}{
+					queryParam("namespace", "Only return deployments of this namespace"),
+					queryParam("drifted", "Set to true to only return drifted and missing deployments"),
+				}, map[string]interface{}{
+					"200": jsonResponse("The last manifest drift report", ref("ManifestDriftReport")),
+					"503": errorResponse("Manifest drift detection not enabled or not checked yet"),
+				}),
+			},
+			"/api/v1/compliance": map[string]interface{}{
+				"get": operation("Check the images of the cached deployments against the registry policy rules, as of the last scan", []interface{}{
+					queryParam("namespace", "Only return findings of deployments in this namespace"),
+					queryParam("severity", "Only return findings of at least this severity: critical, high, medium or low"),
+				}, map[string]interface{}{
+					"200": jsonResponse("The last image compliance report", ref("ComplianceReport")),
+					"400": errorResponse("Invalid severity"),
+					"503": errorResponse("Image compliance scanning not enabled or not scanned yet"),
+				}),
+			},
+			"/api/v1/rollouts": map[string]interface{}{
+				"get": operation("Score the rollout health of the cached deployments and flag stuck rollouts, as of the last check", []interface{}{
+					queryParam("namespace", "Only return deployments of this namespace"),
+					queryParam("status", "Only return deployments with this status: Healthy, Progressing, Degraded, Stuck or Paused"),
+				}, map[string]interface{}{
+					"200": jsonResponse("The last rollout health report", ref("RolloutReport")),
+					"400": errorResponse("Invalid status"),
+					"503": errorResponse("Rollout health tracking not enabled or not checked yet"),
+				}),
+			},
+			"/api/v1/rollouts/{namespace}/{name}": map[string]interface{}{
+				"get": operation("Get the rollout health of a deployment as of the last check", []interface{}{pathParam("namespace"), pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("The rollout health", ref("RolloutHealth")),
+					"404": errorResponse("Deployment not found"),
+					"503": errorResponse("Rollout health tracking not enabled or not checked yet"),
+				}),
+			},
+			"/api/v1/propagation": map[string]interface{}{
+				"get": operation("Outcome of the last propagation of labeled deployments from the primary cluster to each member cluster", []interface{}{
+					queryParam("failed", "Set to true to only return deployments that failed or conflict in a cluster"),
+				}, map[string]interface{}{
+					"200": jsonResponse("The last propagation report", ref("PropagationReport")),
+					"503": errorResponse("Propagation not enabled or not synced yet"),
+				}),
+			},
+			"/api/v1/history": map[string]interface{}{
+				"get": operation("Query the recorded deployment events, most recent first", []interface{}{
+					queryParam("namespace", "Only return events of deployments in this namespace"),
+					queryParam("name", "Only return events of deployments with this name"),
+					queryParam("since", "Only return events since this RFC 3339 time, or this long ago, such as 2h"),
+					map[string]interface{}{
+						"name":        "limit",
+						"in":          "query",
+						"description": "Maximum number of events to return",
+						"schema":      map[string]interface{}{"type": "integer", "minimum": 1},
+					},
+				}, map[string]interface{}{
+					"200": jsonResponse("Recorded events", ref("HistoryResponse")),
+					"400": errorResponse("Invalid since or limit"),
+					"503": errorResponse("Event history not enabled"),
+				}),
+			},
+			"/api/v1/history/samples": map[string]interface{}{
+				"get": operation("Query the replica and restart samples of deployments recorded by anomaly detection, oldest first", []interface{}{
+					queryParam("namespace", "Only return samples of deployments in this namespace"),
+					queryParam("name", "Only return samples of deployments with this name"),
+					queryParam("since", "Only return samples since this RFC 3339 time, or this long ago, such as 2h"),
+				}, map[string]interface{}{
+					"200": jsonResponse("Recorded samples", ref("HistorySamplesResponse")),
+					"400": errorResponse("Invalid since"),
+					"503": errorResponse("Event history not enabled"),
+				}),
+			},
+			"/api/v1/anomalies": map[string]interface{}{
+				"get": operation("Restart spikes and unexpected scale-to-zero flagged from the replica and restart trends of the cached deployments, as of the last check", []interface{}{
+					queryParam("namespace", "Only return anomalies of deployments in this namespace"),
+					queryParam("type", "Only return anomalies of this type: restart-spike or scale-to-zero"),
+				}, map[string]interface{}{
+					"200": jsonResponse("The last anomaly report", ref("AnomalyReport")),
+					"400": errorResponse("Invalid type"),
+					"503": errorResponse("Anomaly detection not enabled or not checked yet"),
+				}),
+			},
+			"/api/v1/loglevel": map[string]interface{}{
+				"get": operation("The log level of the server and the overrides of components", nil, map[string]interface{}{
+					"200": jsonResponse("The log levels in effect", ref("LogLevelResponse")),
+				}),
+				"put": map[string]interface{}{
+					"summary": "Change the log level of the server or of components until restart or SIGHUP; an empty component level removes its override. Requires API authentication to be configured.",
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": ref("LogLevelRequest")},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": jsonResponse("The log levels in effect", ref("LogLevelResponse")),
+						"400": errorResponse("Invalid request body or unknown level"),
+						"401": errorResponse("Missing or invalid credentials"),
+						"403": errorResponse("API authentication is not configured, or the caller's role doesn't allow the request"),
+					},
+				},
+			},
+			"/api/v1/namespaces": map[string]interface{}{
+				"get": operation("List cached namespaces with the number of cached deployments in each", nil, map[string]interface{}{
+					"200": jsonResponse("Namespaces", ref("NamespaceListResponse")),
+					"503": errorResponse("Namespace informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/namespaces/{name}": map[string]interface{}{
+				"get": operation("Get a cached namespace", []interface{}{pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("The namespace", ref("NamespaceResponse")),
+					"404": errorResponse("Namespace not found"),
+					"503": errorResponse("Namespace informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/namespaces/{name}/deployments": map[string]interface{}{
+				"get": operation("List the cached deployments of a namespace",
+					append(append([]interface{}{pathParam("name")}, listParams()[1:]...), indexParams()...),
+					map[string]interface{}{
+						"200": jsonResponse("Deployments, or masked deployments when a field mask applies", map[string]interface{}{
+							"oneOf": []interface{}{ref("DeploymentListResponse"), ref("MaskedDeploymentListResponse")},
+						}),
+						"400": errorResponse("Invalid query parameter, continue token or field mask"),
+						"404": errorResponse("Namespace not found"),
+						"503": errorResponse("Informer not configured or not synced"),
+					}),
+			},
+			"/api/v1/namespaces/{name}/pods": map[string]interface{}{
+				"get": operation("List the cached pods of a namespace", []interface{}{pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("Pods", ref("PodListResponse")),
+					"404": errorResponse("Namespace not found"),
+					"503": errorResponse("Informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/nodes": map[string]interface{}{
+				"get": operation("List cached nodes with their status, allocatable resources, taints and conditions, and the number of cached pods on each", nil, map[string]interface{}{
+					"200": jsonResponse("Nodes", ref("NodeListResponse")),
+					"503": errorResponse("Node informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/nodes/{name}": map[string]interface{}{
+				"get": operation("Get a cached node", []interface{}{pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("The node", ref("NodeResponse")),
+					"404": errorResponse("Node not found"),
+					"503": errorResponse("Node informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/nodes/{name}/pods": map[string]interface{}{
+				"get": operation("List the cached pods scheduled on a node", []interface{}{pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("Pods", ref("PodListResponse")),
+					"404": errorResponse("Node not found"),
+					"503": errorResponse("Informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/jobs": map[string]interface{}{
+				"get": operation("List cached jobs with their status, completions, active, succeeded and failed pod counts and duration", []interface{}{
+					queryParam("namespace", "Only list the jobs of this namespace"),
+					queryParam("status", "Only list jobs with this status: Complete, Failed, Suspended, Running or Pending"),
+				}, map[string]interface{}{
+					"200": jsonResponse("Jobs", ref("JobListResponse")),
+					"503": errorResponse("Job informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/jobs/{namespace}/{name}": map[string]interface{}{
+				"get": operation("Get a cached job", []interface{}{pathParam("namespace"), pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("The job", ref("JobSummary")),
+					"404": errorResponse("Job not found"),
+					"503": errorResponse("Job informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/cronjobs": map[string]interface{}{
+				"get": operation("List cached CronJobs with their schedule, last schedule and success times, and the outcomes of their cached jobs", []interface{}{
+					queryParam("namespace", "Only list the CronJobs of this namespace"),
+				}, map[string]interface{}{
+					"200": jsonResponse("CronJobs", ref("CronJobListResponse")),
+					"503": errorResponse("Job informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/cronjobs/{namespace}/{name}": map[string]interface{}{
+				"get": operation("Get a cached CronJob with the cached jobs it created, most recent first", []interface{}{pathParam("namespace"), pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("The CronJob", ref("CronJobResponse")),
+					"404": errorResponse("CronJob not found"),
+					"503": errorResponse("Job informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/routes": map[string]interface{}{
+				"get": operation("List the hosts and paths cached Ingresses route to Services, with the deployments the Services select", []interface{}{
+					queryParam("host", "Only list the routes serving this host, including wildcard and catch-all routes"),
+				}, map[string]interface{}{
+					"200": jsonResponse("Routes", ref("RouteListResponse")),
+					"503": errorResponse("Ingress informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/pvcs": map[string]interface{}{
+				"get": operation("List cached PersistentVolumeClaims with their phase, volume, capacity, access modes, storage class and the pods mounting them", []interface{}{
+					queryParam("namespace", "Only list the claims of this namespace"),
+					queryParam("phase", "Only list claims in this phase: Pending, Bound or Lost"),
+				}, map[string]interface{}{
+					"200": jsonResponse("PersistentVolumeClaims", ref("PVCListResponse")),
+					"503": errorResponse("PersistentVolumeClaim informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/pvcs/{namespace}/{name}": map[string]interface{}{
+				"get": operation("Get a cached PersistentVolumeClaim", []interface{}{pathParam("namespace"), pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("The PersistentVolumeClaim", ref("PVCResponse")),
+					"404": errorResponse("PersistentVolumeClaim not found"),
+					"503": errorResponse("PersistentVolumeClaim informer not configured or not synced"),
+				}),
+			},
+			"/api/v1/clusters": map[string]interface{}{
+				"get": operation("List the clusters in the configuration file", nil, map[string]interface{}{
+					"200": jsonResponse("Configured clusters", ref("ClusterListResponse")),
+					"503": errorResponse("Cluster store not configured"),
+				}),
+				"post": map[string]interface{}{
+					"summary": "Add a cluster. It is enabled unless enabled is false, and an empty kubeconfig uses the server's default. Requires API authentication to be configured.",
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": ref("ClusterConfig")},
+						},
+					},
+					"responses": clusterWriteResponses("201", "The cluster was added", map[string]interface{}{
+						"409": errorResponse("A cluster with this name already exists"),
+					}),
+				},
+			},
+			"/api/v1/clusters/{name}": map[string]interface{}{
+				"get": operation("Get a cluster", []interface{}{pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("The cluster, with proxy credentials redacted", ref("ClusterResponse")),
+					"404": errorResponse("Cluster not found"),
+					"503": errorResponse("Cluster store not configured"),
+				}),
+				"delete": operation("Delete a cluster. Requires API authentication to be configured.", []interface{}{pathParam("name")},
+					clusterWriteResponses("204", "The cluster was deleted", nil)),
+			},
+			"/api/v1/clusters/{name}/enable": map[string]interface{}{
+				"post": operation("Enable a cluster. Requires API authentication to be configured.", []interface{}{pathParam("name")},
+					clusterWriteResponses("200", "The updated cluster", nil)),
+			},
+			"/api/v1/clusters/{name}/disable": map[string]interface{}{
+				"post": operation("Disable a cluster. Requires API authentication to be configured.", []interface{}{pathParam("name")},
+					clusterWriteResponses("200", "The updated cluster", nil)),
+			},
+			"/api/v1/clusters/{name}/primary": map[string]interface{}{
+				"post": operation("Make a cluster the primary cluster. Requires API authentication to be configured.", []interface{}{pathParam("name")},
+					clusterWriteResponses("200", "The updated cluster", nil)),
+			},
+			"/api/v1/clusters/{name}/deployments": map[string]interface{}{
+				"get": operation("List the cached deployments of a cluster",
+					append([]interface{}{pathParam("name")}, listParams()...),
+					map[string]interface{}{
+						"200": jsonResponse("Deployments, or masked deployments when a field mask applies", map[string]interface{}{
+							"oneOf": []interface{}{ref("ClusterDeploymentListResponse"), ref("MaskedClusterDeploymentListResponse")},
+						}),
+						"400": errorResponse("Invalid query parameter, continue token or field mask"),
+						"404": errorResponse("Cluster not configured"),
+						"502": errorResponse("The cluster's deployments could not be listed"),
+						"503": errorResponse("The cluster's deployment cache is not synced"),
+					}),
+			},
+			"/api/v1/clusters/{name}/connectivity": map[string]interface{}{
+				"get": operation("Check that a cluster's API server is reachable", []interface{}{pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("Connectivity check result", ref("ClusterConnectivityResponse")),
+					"404": errorResponse("Cluster not found"),
+					"503": errorResponse("Cluster store not configured"),
+				}),
+			},
+			"/api/v1/clusters/{name}/status": map[string]interface{}{
+				"get": operation("Get the tracked health of a cluster: connected, syncing, degraded, unreachable or disabled", []interface{}{pathParam("name")}, map[string]interface{}{
+					"200": jsonResponse("Cluster health", ref("ClusterStatusResponse")),
+					"404": errorResponse("Cluster not found"),
+					"503": errorResponse("Cluster store or health tracking not configured"),
+				}),
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+		// Credentials are only required when the server configures authentication
+		"security": []interface{}{
+			map[string]interface{}{"bearerAuth": []interface{}{}},
+			map[string]interface{}{},
+		},
+	}
+}
+
+// listParams returns the filtering, sorting and paging parameters of deployment lists
+func listParams() []interface{} {
+	return []interface{}{
+		queryParam("namespace", "Only list deployments in this namespace"),
+		queryParam("image", "Only list deployments with a container running this image"),
+		queryParam("labelSelector", "Kubernetes label selector, e.g. app=web"),
+		queryParam("sortBy", "Sort by "+strings.Join(listSortKeys, ", ")+" (default name); prefix with - to reverse"),
+		map[string]interface{}{
+			"name":        "limit",
+			"in":          "query",
+			"description": "Maximum number of deployments to return",
+			"schema":      map[string]interface{}{"type": "integer", "minimum": 1},
+		},
+		queryParam("continue", "Token from a previous response to fetch the next page"),
+		fieldsParam(),
+	}
+}
+
+// indexParams returns the parameters selecting deployments of the local
+// cache by an index
+func indexParams() []interface{} {
+	return []interface{}{
+		queryParam("index", "Only list the deployments the index maps value to: "+strings.Join(kubernetes.DeploymentIndexes(), ", ")+" or one configured in controller.informer.indexes. Not supported with cluster."),
+		queryParam("value", "Index value, required with index"),
+	}
+}
+
+// operation builds an OpenAPI operation object
+func operation(summary string, params []interface{}, responses map[string]interface{}) map[string]interface{} {
+	op := map[string]interface{}{
+		"summary":   summary,
+		"responses": responses,
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	return op
+}
+
+// deploymentResponses returns the responses of single deployment endpoints
+func deploymentResponses() map[string]interface{} {
+	return map[string]interface{}{
+		"200": jsonResponse("The deployment, masked when a field mask applies", ref("DeploymentResponse")),
+		"400": errorResponse("Invalid path or unknown field in field mask"),
+		"404": errorResponse("Deployment not found"),
+		"503": errorResponse("Informer not configured or not synced"),
+	}
+}
+
+// clusterWriteResponses returns the responses of cluster management endpoints
+func clusterWriteResponses(status, description string, extra map[string]interface{}) map[string]interface{} {
+	responses := map[string]interface{}{
+		"400": errorResponse("Invalid path or cluster configuration"),
+		"401": errorResponse("Missing or invalid credentials"),
+		"403": errorResponse("API authentication is not configured, or the caller's role doesn't allow the request"),
+		"404": errorResponse("Cluster not found"),
+		"503": errorResponse("Cluster store not configured"),
+	}
+	if status == "204" {
+		responses[status] = map[string]interface{}{"description": description}
+	} else {
+		responses[status] = jsonResponse(description, ref("ClusterResponse"))
+	}
+	for code, response := range extra {
+		responses[code] = response
+	}
+	return responses
+}
+
+// eventFilterParams returns the query parameters accepted by event streams
+func eventFilterParams() []interface{} {
+	return []interface{}{
+		queryParam("namespace", "Only stream events for this namespace"),
+		queryParam("labelSelector", "Kubernetes label selector, e.g. app=web"),
+	}
+}
+
+// fieldsParam describes the ?fields= field mask parameter
+func fieldsParam() map[string]interface{} {
+	return map[string]interface{}{
+		"name":        "fields",
+		"in":          "query",
+		"description": "Comma-separated deployment fields to return: " + strings.Join(config.DeploymentFields, ", "),
+		"schema":      map[string]interface{}{"type": "string"},
+	}
+}
+
+// queryParam describes an optional string query parameter
+func queryParam(name, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "query",
+		"description": description,
+		"schema":      map[string]interface{}{"type": "string"},
+	}
+}
+
+// pathParam describes a required string path parameter
+func pathParam(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     name,
+		"in":       "path",
+		"required": true,
+		"schema":   map[string]interface{}{"type": "string"},
+	}
+}
+
+// jsonResponse describes a JSON response with the given schema
+func jsonResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// errorResponse describes an ErrorResponse
+func errorResponse(description string) map[string]interface{} {
+	return jsonResponse(description, ref("ErrorResponse"))
+}
+
+// ref returns a reference to a component schema
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+// schemaFor returns the schema for a Go type, referencing named components where possible
+func schemaFor(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		return schemaFor(t.Elem())
+	}
+	for name, component := range openAPIComponents {
+		if component == t {
+			return ref(name)
+		}
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		return schemaForStruct(t)
+	default:
+		// interface{} and other dynamic values accept anything
+		return map[string]interface{}{}
+	}
+}
+
+// schemaForStruct returns an object schema built from a struct's JSON fields
+func schemaForStruct(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			// Fields of embedded structs are encoded inline
+			embedded := schemaForStruct(field.Type)
+			for key, value := range embedded["properties"].(map[string]interface{}) {
+				properties[key] = value
+			}
+			if fields, ok := embedded["required"].([]string); ok {
+				required = append(required, fields...)
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestOpenAPIEndpoint(t *testing.T) {
+	srv := New(0)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/openapi.json")
+	srv.Handler()(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(ctx.Response.Body(), &doc); err != nil {
+		t.Fatalf("Invalid OpenAPI JSON: %v", err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("Expected OpenAPI 3.0.3, got %v", doc["openapi"])
+	}
+
+	// Every routed API path must be documented
+	paths := doc["paths"].(map[string]interface{})
+	for _, path := range []string{"/health", "/livez", "/readyz", "/version", "/metrics", "/api/v1/info", "/api/v1/deployments", "/api/v1/deployments/prod/web", "/api/v1/deployments/web", "/api/v1/deployments/watch", "/api/v1/deployments/ws", "/api/v1/deployments/prod/web/scale", "/api/v1/deployments/prod/web/revisions", "/api/v1/deployments/prod/web/events", "/api/v1/deployments/prod/web/config",
+		"/api/v1/clusters", "/api/v1/clusters/prod", "/api/v1/clusters/prod/enable", "/api/v1/clusters/prod/disable", "/api/v1/clusters/prod/primary", "/api/v1/clusters/prod/connectivity", "/api/v1/clusters/prod/status", "/api/v1/clusters/prod/deployments",
+		"/api/v1/batch/scale/preview", "/api/v1/drift", "/api/v1/drift/manifests", "/api/v1/compliance", "/api/v1/propagation", "/api/v1/history", "/api/v1/loglevel",
+		"/api/v1/namespaces", "/api/v1/namespaces/prod", "/api/v1/namespaces/prod/deployments", "/api/v1/namespaces/prod/pods",
+		"/api/v1/nodes", "/api/v1/nodes/node-1", "/api/v1/nodes/node-1/pods",
+		"/api/v1/jobs", "/api/v1/jobs/prod/backup", "/api/v1/cronjobs", "/api/v1/cronjobs/prod/backup", "/api/v1/routes",
+		"/api/v1/pvcs", "/api/v1/pvcs/prod/data", "/api/v1/rollouts", "/api/v1/rollouts/prod/web", "/api/v1/history/samples", "/api/v1/anomalies"} {
+		if _, ok := paths[routeFor(path)]; !ok {
+			t.Errorf("Route %s is missing from the OpenAPI document", routeFor(path))
+		}
+	}
+
+	// Schemas follow the JSON field names of the response types
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	deployment := schemas["DeploymentResponse"].(map[string]interface{})["properties"].(map[string]interface{})
+	for _, field := range []string{"name", "namespace", "replicas", "ready", "updated", "available", "age", "image", "labels", "specHash"} {
+		if _, ok := deployment[field]; !ok {
+			t.Errorf("DeploymentResponse schema is missing %s", field)
+		}
+	}
+	clusterDeployment := schemas["ClusterDeploymentResponse"].(map[string]interface{})["properties"].(map[string]interface{})
+	for _, field := range []string{"cluster", "name", "namespace", "replicas"} {
+		if _, ok := clusterDeployment[field]; !ok {
+			t.Errorf("ClusterDeploymentResponse schema is missing %s", field)
+		}
+	}
+
+	// All references resolve
+	body := string(ctx.Response.Body())
+	for _, part := range strings.Split(body, `"#/components/schemas/`)[1:] {
+		name := part[:strings.Index(part, `"`)]
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("Unresolved schema reference %s", name)
+		}
+	}
+}
+
+func TestDocsEndpoint(t *testing.T) {
+	srv := New(0)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/docs")
+	srv.Handler()(ctx)
+
+	if !strings.HasPrefix(string(ctx.Response.Header.ContentType()), "text/html") {
+		t.Errorf("Expected HTML, got %s", ctx.Response.Header.ContentType())
+	}
+	if !strings.Contains(string(ctx.Response.Body()), "/openapi.json") {
+		t.Error("Expected Swagger UI to load /openapi.json")
+	}
+	if strings.Contains(string(ctx.Response.Body()), "swagger-ui-dist@5/") {
+		t.Error("Expected Swagger UI to be pinned to an exact version")
+	}
+	policy := string(ctx.Response.Header.Peek("Content-Security-Policy"))
+	if !strings.Contains(policy, "script-src "+swaggerUIAssets+"/swagger-ui-bundle.js 'sha256-") {
+		t.Errorf("Expected the policy to allow only the pinned bundle and the page script, got %q", policy)
+	}
+}
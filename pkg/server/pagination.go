@@ -0,0 +1,258 @@
+package server
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Sort keys accepted by the sortBy parameter; prefix with "-" to reverse
+var listSortKeys = []string{"name", "age", "replicas"}
+
+// listOptions are the filtering, sorting and paging parameters of a list request
+type listOptions struct {
+	namespace string
+	selector  labels.Selector
+	image     string
+
+	// index and indexValue select the deployments an index of the cache
+	// maps the value to
+	index      string
+	indexValue string
+
+	sortBy  string
+	reverse bool
+	limit   int
+	offset  int
+
+	// query identifies the filtered, sorted result a continue token belongs to
+	query string
+}
+
+// parseListOptions reads list parameters from the query string
+func parseListOptions(args *fasthttp.Args) (listOptions, error) {
+	opts := listOptions{
+		namespace: string(args.Peek("namespace")),
+		selector:  labels.Everything(),
+		image:     string(args.Peek("image")),
+		index:     string(args.Peek("index")),
+		sortBy:    "name",
+	}
+
+	labelSelector := string(args.Peek("labelSelector"))
+	if labelSelector != "" {
+		selector, err := labels.Parse(labelSelector)
+		if err != nil {
+			return opts, fmt.Errorf("invalid label selector: %v", err)
+		}
+		opts.selector = selector
+	}
+
+	if sortBy := string(args.Peek("sortBy")); sortBy != "" {
+		opts.sortBy = strings.TrimPrefix(sortBy, "-")
+		opts.reverse = strings.HasPrefix(sortBy, "-")
+		if !isListSortKey(opts.sortBy) {
+			return opts, fmt.Errorf("invalid sortBy %q (valid keys: %s)", sortBy, strings.Join(listSortKeys, ", "))
+		}
+	}
+
+	if limit := string(args.Peek("limit")); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			return opts, fmt.Errorf("invalid limit %q: must be a positive integer", limit)
+		}
+		opts.limit = value
+	}
+
+	if opts.index != "" {
+		if !args.Has("value") {
+			return opts, fmt.Errorf("value is required with index")
+		}
+		opts.indexValue = string(args.Peek("value"))
+	}
+
+	opts.query = fmt.Sprintf("%s|%s|%s|%t", opts.namespace, opts.selector.String(), opts.sortBy, opts.reverse)
+	if opts.image != "" {
+		opts.query += "|image=" + opts.image
+	}
+	if opts.index != "" {
+		opts.query += "|index=" + opts.index + "=" + opts.indexValue
+	}
+	if cluster := string(args.Peek("cluster")); cluster != "" {
+		// Tokens of one cluster's list don't apply to another's
+		opts.query += "|" + cluster
+	}
+
+	if token := string(args.Peek("continue")); token != "" {
+		offset, err := decodeContinueToken(token, opts.query)
+		if err != nil {
+			return opts, err
+		}
+		opts.offset = offset
+	}
+
+	return opts, nil
+}
+
+// isListSortKey reports whether key is a supported sort key
+func isListSortKey(key string) bool {
+	for _, k := range listSortKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates returns the cached deployments the filter can match, looked up
+// in the requested index, or the image or namespace index of the cache,
+// rather than listing it all. Only candidates match the requested index.
+func (o listOptions) candidates(informer *kubernetes.DeploymentInformer) ([]*appsv1.Deployment, error) {
+	switch {
+	case o.index != "":
+		return informer.DeploymentsByIndex(o.index, o.indexValue)
+	case o.image != "":
+		return informer.DeploymentsByIndex(kubernetes.ImageIndex, o.image)
+	case o.namespace != "":
+		return informer.DeploymentsByIndex(kubernetes.NamespaceIndex, o.namespace)
+	}
+	return informer.ListDeployments()
+}
+
+// filter returns the deployments matching the namespace, image and label
+// selector, including labels propagated to them
+func (o listOptions) filter(deployments []*appsv1.Deployment, propagator kubernetes.LabelPropagator) []*appsv1.Deployment {
+	filtered := make([]*appsv1.Deployment, 0, len(deployments))
+	for _, dep := range deployments {
+		if o.matches(dep, propagator) {
+			filtered = append(filtered, dep)
+		}
+	}
+	return filtered
+}
+
+// matches reports whether a deployment matches the namespace, image and label
+// selector
+func (o listOptions) matches(dep *appsv1.Deployment, propagator kubernetes.LabelPropagator) bool {
+	if o.namespace != "" && dep.Namespace != o.namespace {
+		return false
+	}
+	if o.image != "" && !usesImage(dep, o.image) {
+		return false
+	}
+	return o.selector.Matches(labels.Set(kubernetes.EffectiveLabels(dep, propagator)))
+}
+
+// usesImage reports whether a container or init container of a deployment
+// runs image
+func usesImage(dep *appsv1.Deployment, image string) bool {
+	spec := dep.Spec.Template.Spec
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			if container.Image == image {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sort orders deployments by the sort key, breaking ties by namespace and name
+// so that pages are stable between requests
+func (o listOptions) sort(deployments []*appsv1.Deployment) {
+	sort.SliceStable(deployments, func(i, j int) bool {
+		if o.reverse {
+			return o.less(deployments[j], deployments[i])
+		}
+		return o.less(deployments[i], deployments[j])
+	})
+}
+
+// less orders two deployments by the sort key, then by namespace and name
+func (o listOptions) less(a, b *appsv1.Deployment) bool {
+	byName := func(a, b *appsv1.Deployment) bool {
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	}
+
+	switch o.sortBy {
+	case "age":
+		// Youngest first, like ascending values in the AGE column
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.After(b.CreationTimestamp.Time)
+		}
+	case "replicas":
+		if ra, rb := specReplicas(a), specReplicas(b); ra != rb {
+			return ra < rb
+		}
+	}
+	return byName(a, b)
+}
+
+// page returns the requested page and the continue token for the next one
+func (o listOptions) page(deployments []*appsv1.Deployment) ([]*appsv1.Deployment, string) {
+	return pageItems(o, deployments)
+}
+
+// pageItems returns the page of items requested by o and the continue token
+// for the next one
+func pageItems[T any](o listOptions, items []T) ([]T, string) {
+	if o.offset >= len(items) {
+		return []T{}, ""
+	}
+	items = items[o.offset:]
+	if o.limit == 0 || len(items) <= o.limit {
+		return items, ""
+	}
+	return items[:o.limit], encodeContinueToken(o.offset+o.limit, o.query)
+}
+
+// specReplicas returns the desired replicas of a deployment (default 1)
+func specReplicas(dep *appsv1.Deployment) int32 {
+	if dep.Spec.Replicas == nil {
+		return 1
+	}
+	return *dep.Spec.Replicas
+}
+
+// encodeContinueToken returns an opaque token for the next page of a query
+func encodeContinueToken(offset int, query string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%x", offset, queryHash(query))))
+}
+
+// decodeContinueToken returns the offset in a continue token, rejecting
+// tokens issued for a different query
+func decodeContinueToken(token, query string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid continue token")
+	}
+
+	offsetPart, hashPart, found := strings.Cut(string(data), ":")
+	offset, err := strconv.Atoi(offsetPart)
+	if !found || err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid continue token")
+	}
+	if hashPart != fmt.Sprintf("%x", queryHash(query)) {
+		return 0, fmt.Errorf("continue token does not match the namespace, image, labelSelector and sortBy of this request")
+	}
+	return offset, nil
+}
+
+// queryHash identifies a list query in continue tokens
+func queryHash(query string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(query))
+	return h.Sum32()
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestListDeploymentsPagination(t *testing.T) {
+	now := time.Now()
+	var objects []runtime.Object
+	for i, spec := range []struct {
+		namespace, name string
+		replicas        int32
+		app             string
+	}{
+		{"prod", "web", 3, "web"},
+		{"prod", "api", 5, "api"},
+		{"prod", "worker", 1, "worker"},
+		{"dev", "web", 1, "web"},
+	} {
+		dep := newTestDeployment(spec.namespace, spec.name, spec.replicas, map[string]string{"app": spec.app})
+		dep.CreationTimestamp = metav1.NewTime(now.Add(-time.Duration(i+1) * time.Hour))
+		objects = append(objects, dep)
+	}
+
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(objects...), "", time.Minute)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("informer.Start() error = %v", err)
+	}
+	defer informer.Stop()
+	handler := NewDeploymentHandler(informer)
+
+	list := func(query string) (int, DeploymentListResponse) {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v1/deployments?" + query)
+		ctx.Request.Header.SetMethod("GET")
+		handler.HandleDeployments(ctx)
+
+		var response DeploymentListResponse
+		_ = json.Unmarshal(ctx.Response.Body(), &response)
+		return ctx.Response.StatusCode(), response
+	}
+	names := func(response DeploymentListResponse) []string {
+		var result []string
+		for _, item := range response.Items {
+			result = append(result, item.Namespace+"/"+item.Name)
+		}
+		return result
+	}
+
+	// Pages follow each other until there is no continue token
+	var pages [][]string
+	status, response := list("namespace=prod&limit=2")
+	for {
+		if status != fasthttp.StatusOK {
+			t.Fatalf("Expected status 200, got %d", status)
+		}
+		if response.Total != 3 {
+			t.Errorf("Expected total 3, got %d", response.Total)
+		}
+		pages = append(pages, names(response))
+		if response.Continue == "" {
+			break
+		}
+		status, response = list("namespace=prod&limit=2&continue=" + response.Continue)
+	}
+	if len(pages) != 2 || len(pages[0]) != 2 || pages[0][0] != "prod/api" || pages[1][0] != "prod/worker" {
+		t.Errorf("Unexpected pages: %v", pages)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"sortBy=-replicas&limit=2", []string{"prod/api", "prod/web"}},
+		{"sortBy=age&labelSelector=app%3Dweb", []string{"prod/web", "dev/web"}},
+		{"sortBy=-age&labelSelector=app%3Dweb", []string{"dev/web", "prod/web"}},
+	}
+	for _, tt := range tests {
+		status, response := list(tt.query)
+		got := names(response)
+		if status != fasthttp.StatusOK || len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+			t.Errorf("%s: expected %v, got %v (status %d)", tt.query, tt.want, got, status)
+		}
+	}
+
+	// A continue token only applies to the query it was issued for
+	_, response = list("namespace=prod&limit=1")
+	if status, _ := list("namespace=dev&limit=1&continue=" + response.Continue); status != fasthttp.StatusBadRequest {
+		t.Errorf("Expected 400 for a continue token from another query, got %d", status)
+	}
+
+	for _, query := range []string{"limit=0", "sortBy=size", "labelSelector=app%3D%3D%3D", "continue=garbage"} {
+		if status, _ := list(query); status != fasthttp.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, status)
+		}
+	}
+}
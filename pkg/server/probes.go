@@ -0,0 +1,204 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/valyala/fasthttp"
+)
+
+// HealthCheck reports why a probe should fail, or nil when it passes
+type HealthCheck func() error
+
+// namedCheck is a health check with the name probes report it under
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// CheckResult is the outcome of one check of a probe
+type CheckResult struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ProbeResponse is returned by /livez and /readyz, with status 200 when every
+// check passes and 503 otherwise
+type ProbeResponse struct {
+	Status   string        `json:"status"`
+	Checks   []CheckResult `json:"checks"`
+	Excluded []string      `json:"excluded,omitempty"`
+}
+
+// AddLivenessCheck adds a check to /livez. A failing liveness check gets the
+// server restarted, so it should only fail when the server can't recover.
+func (s *Server) AddLivenessCheck(name string, check HealthCheck) {
+	s.probesMu.Lock()
+	defer s.probesMu.Unlock()
+	s.livenessChecks = append(s.livenessChecks, namedCheck{name, check})
+}
+
+// AddReadinessCheck adds a check to /readyz, in addition to the cache sync
+// of the informers the server reads from
+func (s *Server) AddReadinessCheck(name string, check HealthCheck) {
+	s.probesMu.Lock()
+	defer s.probesMu.Unlock()
+	s.readinessChecks = append(s.readinessChecks, namedCheck{name, check})
+}
+
+// livenessProbe returns the checks of /livez: the server answers, and with a
+// sync timeout, it became ready in time
+func (s *Server) livenessProbe() []namedCheck {
+	checks := []namedCheck{{"ping", func() error { return nil }}}
+	if timeout := s.config.Probes.SyncTimeout; timeout > 0 {
+		checks = append(checks, namedCheck{"sync-timeout", func() error {
+			if s.everReady.Load() || time.Since(s.startTime) < timeout {
+				return nil
+			}
+			if s.checkReady() {
+				return nil
+			}
+			return fmt.Errorf("not ready %s after startup", timeout)
+		}})
+	}
+
+	s.probesMu.Lock()
+	defer s.probesMu.Unlock()
+	return append(checks, s.livenessChecks...)
+}
+
+// readinessProbe returns the checks of /readyz: the server is not shutting
+// down, and the cache of every informer it reads from has synced, on the
+// local and on remote clusters
+func (s *Server) readinessProbe() []namedCheck {
+	checks := []namedCheck{{"shutdown", func() error {
+		if s.shuttingDown.Load() {
+			return errors.New("server is shutting down")
+		}
+		return nil
+	}}}
+	synced := func(name string, hasSynced func() bool) {
+		checks = append(checks, namedCheck{name, func() error {
+			if !hasSynced() {
+				return errors.New("cache not synced")
+			}
+			return nil
+		}})
+	}
+	if s.deploymentHandler != nil {
+		synced("informer/deployments", s.deploymentHandler.informer.HasSynced)
+	}
+	if s.namespaces != nil {
+		synced("informer/namespaces", s.namespaces.HasSynced)
+	}
+	if s.pods != nil {
+		synced("informer/pods", s.pods.HasSynced)
+	}
+	if s.nodes != nil {
+		synced("informer/nodes", s.nodes.HasSynced)
+	}
+	if s.jobs != nil {
+		synced("informer/jobs", s.jobs.HasSynced)
+	}
+	if s.ingresses != nil {
+		synced("informer/ingresses", s.ingresses.HasSynced)
+	}
+	if s.pvcs != nil {
+		synced("informer/pvcs", s.pvcs.HasSynced)
+	}
+	if s.replicaSets != nil {
+		synced("informer/replicasets", s.replicaSets.HasSynced)
+	}
+	if s.kubeEvents != nil {
+		synced("informer/events", s.kubeEvents.HasSynced)
+	}
+	if s.configs != nil {
+		synced("informer/config", s.configs.HasSynced)
+	}
+	if s.clusterInformers != nil {
+		for _, name := range s.clusterInformers.Clusters() {
+			checks = append(checks, namedCheck{"cluster/" + name, func() error {
+				state, err := s.clusterInformers.CacheSyncState(name)
+				switch {
+				case state == cluster.CacheSyncFailed:
+					return fmt.Errorf("cache sync failed: %v", err)
+				case state != cluster.CacheSyncSynced:
+					return errors.New("cache not synced")
+				}
+				return nil
+			}})
+		}
+	}
+
+	s.probesMu.Lock()
+	defer s.probesMu.Unlock()
+	return append(checks, s.readinessChecks...)
+}
+
+// checkReady reports whether every readiness check not excluded by the
+// configuration passes, and remembers the first time it does
+func (s *Server) checkReady() bool {
+	response := runChecks(s.readinessProbe(), s.config.Probes.Exclude)
+	if response.Status != "ok" {
+		return false
+	}
+	s.everReady.Store(true)
+	return true
+}
+
+// runChecks runs checks, skipping the excluded ones
+func runChecks(checks []namedCheck, exclude []string) ProbeResponse {
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[name] = true
+	}
+
+	response := ProbeResponse{Status: "ok", Checks: []CheckResult{}}
+	for _, c := range checks {
+		if skip[c.name] {
+			response.Excluded = append(response.Excluded, c.name)
+			continue
+		}
+		result := CheckResult{Name: c.name, Healthy: true}
+		if err := c.check(); err != nil {
+			result.Healthy = false
+			result.Error = err.Error()
+			response.Status = "failed"
+		}
+		response.Checks = append(response.Checks, result)
+	}
+	return response
+}
+
+// handleLivez handles GET /livez
+func (s *Server) handleLivez(ctx *fasthttp.RequestCtx) {
+	s.serveProbe(ctx, s.livenessProbe())
+}
+
+// handleReadyz handles GET /readyz
+func (s *Server) handleReadyz(ctx *fasthttp.RequestCtx) {
+	response := s.serveProbe(ctx, s.readinessProbe())
+	if response.Status == "ok" && !ctx.QueryArgs().Has("exclude") {
+		s.everReady.Store(true)
+	}
+}
+
+// serveProbe runs the checks of a probe, skipping those excluded by the
+// configuration or by exclude query parameters, as in /readyz?exclude=cluster/staging
+func (s *Server) serveProbe(ctx *fasthttp.RequestCtx, checks []namedCheck) ProbeResponse {
+	exclude := append([]string{}, s.config.Probes.Exclude...)
+	for _, name := range ctx.QueryArgs().PeekMulti("exclude") {
+		exclude = append(exclude, string(name))
+	}
+
+	response := runChecks(checks, exclude)
+	status := fasthttp.StatusOK
+	if response.Status != "ok" {
+		status = fasthttp.StatusServiceUnavailable
+	}
+	sendJSON(ctx, status, response)
+	return response
+}
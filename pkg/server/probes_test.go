@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// probe requests a probe endpoint and decodes its response
+func probe(t *testing.T, srv *Server, uri string) (int, ProbeResponse) {
+	t.Helper()
+	ctx := serve(srv, fasthttp.MethodGet, uri, "")
+	var response ProbeResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &response); err != nil {
+		t.Fatalf("Failed to decode %s response: %v", uri, err)
+	}
+	return ctx.Response.StatusCode(), response
+}
+
+// result returns the result of a check in a probe response
+func result(response ProbeResponse, name string) CheckResult {
+	for _, check := range response.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return CheckResult{Name: name}
+}
+
+func TestReadyz(t *testing.T) {
+	srv := New(0)
+	if status, response := probe(t, srv, "/readyz"); status != fasthttp.StatusOK || response.Status != "ok" {
+		t.Fatalf("Expected a server without informers to be ready, got %d %+v", status, response)
+	}
+
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(), "", time.Minute)
+	srv.SetDeploymentInformer(informer)
+	status, response := probe(t, srv, "/readyz")
+	if status != fasthttp.StatusServiceUnavailable || result(response, "informer/deployments").Error != "cache not synced" {
+		t.Errorf("Expected 503 until the deployment cache syncs, got %d %+v", status, response)
+	}
+
+	// Checks can be excluded per request
+	status, response = probe(t, srv, "/readyz?exclude=informer/deployments")
+	if status != fasthttp.StatusOK || len(response.Excluded) != 1 {
+		t.Errorf("Expected the excluded check to be skipped, got %d %+v", status, response)
+	}
+
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+	if status, response := probe(t, srv, "/readyz"); status != fasthttp.StatusOK {
+		t.Errorf("Expected 200 once the cache synced, got %d %+v", status, response)
+	}
+
+	srv.AddReadinessCheck("database", func() error { return errors.New("connection refused") })
+	status, response = probe(t, srv, "/readyz")
+	if status != fasthttp.StatusServiceUnavailable || result(response, "database").Error != "connection refused" {
+		t.Errorf("Expected an added check to fail readiness, got %d %+v", status, response)
+	}
+}
+
+func TestLivez(t *testing.T) {
+	cfg := config.DefaultConfig().Server
+	cfg.Probes.SyncTimeout = time.Minute
+	srv := NewWithConfig(cfg)
+	srv.SetDeploymentInformer(kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(), "", time.Minute))
+
+	if status, response := probe(t, srv, "/livez"); status != fasthttp.StatusOK {
+		t.Errorf("Expected a syncing server to be live within the sync timeout, got %d %+v", status, response)
+	}
+
+	srv.startTime = time.Now().Add(-2 * time.Minute)
+	status, response := probe(t, srv, "/livez")
+	if status != fasthttp.StatusServiceUnavailable || result(response, "sync-timeout").Healthy {
+		t.Errorf("Expected a server never ready within the sync timeout to fail liveness, got %d %+v", status, response)
+	}
+
+	// A server that was ready once stays live
+	srv.everReady.Store(true)
+	if status, response := probe(t, srv, "/livez"); status != fasthttp.StatusOK {
+		t.Errorf("Expected a server that was ready to stay live, got %d %+v", status, response)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/propagation"
+	"github.com/valyala/fasthttp"
+)
+
+// SetPropagator sets the propagator whose latest report is served at /api/v1/propagation
+func (s *Server) SetPropagator(propagator *propagation.Propagator) {
+	s.propagator = propagator
+}
+
+// handlePropagation handles GET /api/v1/propagation. It returns the outcome
+// of the last sync for each propagated deployment and member cluster;
+// "failed=true" keeps only deployments that are not in sync somewhere.
+func (s *Server) handlePropagation(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.propagator == nil || !s.propagator.Enabled() {
+		s.handleServiceUnavailable(ctx, "Propagation not enabled (multi_cluster.propagation.enabled)")
+		return
+	}
+
+	report, synced := s.propagator.Report()
+	if !synced {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Propagation has not completed a sync yet")
+		return
+	}
+
+	if string(ctx.QueryArgs().Peek("failed")) == "true" {
+		report = failedOnly(report)
+	}
+	sendJSON(ctx, fasthttp.StatusOK, report)
+}
+
+// failedOnly returns a copy of report keeping only deployments that failed
+// or conflict in at least one cluster
+func failedOnly(report propagation.Report) propagation.Report {
+	deployments := []propagation.DeploymentStatus{}
+	for _, dep := range report.Deployments {
+		for _, status := range dep.Clusters {
+			if status.State == propagation.StateFailed || status.State == propagation.StateConflict {
+				deployments = append(deployments, dep)
+				break
+			}
+		}
+	}
+	report.Deployments = deployments
+	return report
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/propagation"
+	k6stesting "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/testing"
+	"github.com/valyala/fasthttp"
+)
+
+func TestHandlePropagation(t *testing.T) {
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/propagation", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a propagator, got %d", ctx.Response.StatusCode())
+	}
+
+	registry := k6stesting.FakeRegistry{
+		"eu": k6stesting.NewFakeCluster("eu", newTestDeployment("shop", "web", 3, map[string]string{propagation.PropagateLabel: "true"})),
+	}
+	propagator := propagation.NewPropagator(registry, config.PropagationConfig{Enabled: true})
+	srv.SetPropagator(propagator)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/propagation", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first sync, got %d", ctx.Response.StatusCode())
+	}
+
+	propagator.Sync(context.Background())
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/propagation", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var report propagation.Report
+	if err := json.Unmarshal(ctx.Response.Body(), &report); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if report.Error == "" {
+		t.Errorf("Expected an error without a primary cluster, got %+v", report)
+	}
+
+	if ctx := serve(srv, fasthttp.MethodPost, "/api/v1/propagation", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", ctx.Response.StatusCode())
+	}
+
+	propagator.SetConfig(config.PropagationConfig{})
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/propagation", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 once disabled, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestFailedOnly(t *testing.T) {
+	report := propagation.Report{Deployments: []propagation.DeploymentStatus{
+		{Name: "web", Clusters: map[string]propagation.ClusterStatus{"eu": {State: propagation.StateInSync}}},
+		{Name: "api", Clusters: map[string]propagation.ClusterStatus{"eu": {State: propagation.StateCreated}, "us": {State: propagation.StateConflict}}},
+	}}
+	filtered := failedOnly(report)
+	if len(filtered.Deployments) != 1 || filtered.Deployments[0].Name != "api" {
+		t.Errorf("Expected only api, got %+v", filtered.Deployments)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PVCResponse represents a PersistentVolumeClaim in API responses. MountedBy
+// lists the cached pods mounting it, when pods are cached.
+type PVCResponse struct {
+	kubernetes.PVCSummary
+	MountedBy []string `json:"mountedBy"`
+}
+
+// PVCListResponse represents the response for PersistentVolumeClaim list
+type PVCListResponse struct {
+	Items []PVCResponse `json:"items"`
+	Count int           `json:"count"`
+}
+
+// SetPVCInformer sets the PersistentVolumeClaim cache served at /api/v1/pvcs
+func (s *Server) SetPVCInformer(informer *kubernetes.PVCInformer) {
+	s.pvcs = informer
+}
+
+// handlePVCs handles GET /api/v1/pvcs, filtered by the namespace and phase
+// query parameters, and GET /api/v1/pvcs/{namespace}/{name}
+func (s *Server) handlePVCs(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.pvcs == nil {
+		s.handleServiceUnavailable(ctx, "PersistentVolumeClaim informer not configured")
+		return
+	}
+	if !s.pvcs.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "PersistentVolumeClaim informer cache is not synced")
+		return
+	}
+
+	path := strings.TrimPrefix(string(ctx.Path()), "/api/v1/pvcs")
+	if path != "" {
+		parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Expected a path of the form /api/v1/pvcs/{namespace}/{name}")
+			return
+		}
+		pvc, exists := s.pvcs.GetPVC(parts[0], parts[1])
+		if !exists {
+			sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("PersistentVolumeClaim %s/%s not found", parts[0], parts[1]))
+			return
+		}
+		sendJSON(ctx, fasthttp.StatusOK, s.pvcResponse(pvc))
+		return
+	}
+
+	pvcs, err := s.pvcs.ListPVCs(string(ctx.QueryArgs().Peek("namespace")))
+	if err != nil {
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve PersistentVolumeClaims")
+		return
+	}
+	phase := string(ctx.QueryArgs().Peek("phase"))
+	response := PVCListResponse{Items: make([]PVCResponse, 0, len(pvcs))}
+	for _, pvc := range pvcs {
+		if phase != "" && !strings.EqualFold(string(pvc.Status.Phase), phase) {
+			continue
+		}
+		response.Items = append(response.Items, s.pvcResponse(pvc))
+	}
+	response.Count = len(response.Items)
+	sendJSON(ctx, fasthttp.StatusOK, response)
+}
+
+// pvcResponse converts a claim, listing the cached pods mounting it when pods
+// are cached and synced
+func (s *Server) pvcResponse(pvc *corev1.PersistentVolumeClaim) PVCResponse {
+	response := PVCResponse{PVCSummary: kubernetes.SummarizePVC(pvc)}
+	if s.pods == nil || !s.pods.HasSynced() {
+		return response
+	}
+	pods, err := s.pods.ListClaimPods(pvc.Namespace, pvc.Name)
+	if err != nil {
+		return response
+	}
+	response.MountedBy = make([]string, 0, len(pods))
+	for _, pod := range pods {
+		response.MountedBy = append(response.MountedBy, pod.Name)
+	}
+	return response
+}
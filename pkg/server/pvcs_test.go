@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandlePVCs(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "shop"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "shop"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "shop"}, Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         "data",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}},
+		}}}},
+	)
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/pvcs", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a PVC informer, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pvcs := kubernetes.NewPVCInformer(clientset, "", time.Minute)
+	if err := pvcs.Start(ctx); err != nil {
+		t.Fatalf("pvcs.Start() error = %v", err)
+	}
+	defer pvcs.Stop()
+	srv.SetPVCInformer(pvcs)
+
+	resp := serve(srv, fasthttp.MethodGet, "/api/v1/pvcs/shop/data", "")
+	var pvc PVCResponse
+	if err := json.Unmarshal(resp.Response.Body(), &pvc); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if pvc.Phase != "Bound" || pvc.MountedBy != nil {
+		t.Errorf("Expected the claim without mounting pods before pods are cached, got %+v", pvc)
+	}
+
+	pods := kubernetes.NewPodInformer(clientset, "", time.Minute)
+	if err := pods.Start(ctx); err != nil {
+		t.Fatalf("pods.Start() error = %v", err)
+	}
+	defer pods.Stop()
+	srv.SetPodInformer(pods)
+
+	resp = serve(srv, fasthttp.MethodGet, "/api/v1/pvcs?namespace=shop&phase=bound", "")
+	if resp.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Response.StatusCode(), resp.Response.Body())
+	}
+	var list PVCListResponse
+	if err := json.Unmarshal(resp.Response.Body(), &list); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if list.Count != 1 || list.Items[0].Name != "data" || !reflect.DeepEqual(list.Items[0].MountedBy, []string{"db-0"}) {
+		t.Errorf("Expected the bound claim mounted by db-0, got %+v", list)
+	}
+
+	if resp := serve(srv, fasthttp.MethodGet, "/api/v1/pvcs/shop/unknown", ""); resp.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown claim, got %d", resp.Response.StatusCode())
+	}
+}
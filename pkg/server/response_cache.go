@@ -0,0 +1,197 @@
+package server
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cache"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const (
+	// defaultResponseCacheTTL is how long responses are cached by default
+	defaultResponseCacheTTL = 10 * time.Second
+
+	// defaultResponseCacheEntries is how many responses are cached by default
+	defaultResponseCacheEntries = 1000
+)
+
+// ResponseCache caches the responses of the deployment endpoints. A response
+// is keyed by the resourceVersion of what it shows: the deployment for the
+// get endpoint, and the last changed deployment for lists. Deployment events
+// move the versions on and drop the responses they make stale, so cached
+// responses are never older than the informer cache, apart from labels
+// propagated from namespaces, which the TTL bounds.
+type ResponseCache struct {
+	ttl     time.Duration
+	entries *cache.ShardedCache[*cachedResponse]
+
+	mu sync.Mutex
+
+	// resourceVersion of the last deployment event, and of the last event
+	// of each deployment by namespace/name
+	version     string
+	deployments map[string]string
+}
+
+// cachedResponse is a cached response and the deployment it shows, "" for
+// responses of several deployments
+type cachedResponse struct {
+	deployment  string
+	statusCode  int
+	contentType string
+	body        []byte
+}
+
+// NewResponseCache creates a cache serving responses for ttl, keeping at
+// most maxEntries of them
+func NewResponseCache(ttl time.Duration, maxEntries int) *ResponseCache {
+	if ttl <= 0 {
+		ttl = defaultResponseCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultResponseCacheEntries
+	}
+	return &ResponseCache{
+		ttl:         ttl,
+		entries:     cache.NewShardedCache[*cachedResponse](0, maxEntries),
+		deployments: make(map[string]string),
+	}
+}
+
+// newResponseCache creates the response cache of the configuration, nil
+// when responses are not cached
+func newResponseCache(cfg config.ResponseCacheConfig) *ResponseCache {
+	if !cfg.Enabled {
+		return nil
+	}
+	return NewResponseCache(cfg.TTL, cfg.MaxEntries)
+}
+
+// OnAdd moves the versions on past an added deployment
+func (c *ResponseCache) OnAdd(obj *appsv1.Deployment) {
+	c.changed(obj)
+}
+
+// OnUpdate moves the versions on past an updated deployment. Resyncs, which
+// leave the resourceVersion unchanged, keep the cached responses.
+func (c *ResponseCache) OnUpdate(oldObj, newObj *appsv1.Deployment) {
+	if oldObj.ResourceVersion == newObj.ResourceVersion {
+		return
+	}
+	c.changed(newObj)
+}
+
+// OnDelete forgets a deleted deployment and drops the responses showing it
+func (c *ResponseCache) OnDelete(obj *appsv1.Deployment) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := obj.Namespace + "/" + obj.Name
+	delete(c.deployments, key)
+	c.version = obj.ResourceVersion
+	c.invalidate(key)
+}
+
+// changed records the resourceVersion of a deployment and drops the
+// responses it made stale
+func (c *ResponseCache) changed(obj *appsv1.Deployment) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := obj.Namespace + "/" + obj.Name
+	c.deployments[key] = obj.ResourceVersion
+	c.version = obj.ResourceVersion
+	c.invalidate(key)
+}
+
+// invalidate drops the responses of several deployments and of deployment;
+// the caller holds the lock
+func (c *ResponseCache) invalidate(deployment string) {
+	c.entries.DeleteFunc(func(_ string, entry *cachedResponse) bool {
+		return entry.deployment == "" || entry.deployment == deployment
+	})
+}
+
+// Len returns the number of cached responses
+func (c *ResponseCache) Len() int {
+	return c.entries.Size()
+}
+
+// key returns the cache key of a request for deployment, "" for requests of
+// several deployments
+func (c *ResponseCache) key(deployment, uri string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	version := c.version
+	if deployment != "" {
+		version = c.deployments[deployment]
+	}
+	return version + " " + uri
+}
+
+// cachedDeployment returns whether a request can be answered from the
+// response cache, and the namespace/name of the deployment it shows, "" for
+// requests of several deployments. Only GET requests of endpoints served from
+// the deployment informer alone are cached.
+func cachedDeployment(ctx *fasthttp.RequestCtx) (string, bool) {
+	if !ctx.IsGet() || ctx.QueryArgs().Has("cluster") {
+		return "", false
+	}
+	path := string(ctx.Path())
+	switch routeFor(path) {
+	case "/api/v1/deployments", "/api/v1/deployments/aggregate", "/api/v1/deployments/index":
+		return "", true
+	case "/api/v1/deployments/{name}":
+		return "default/" + strings.TrimPrefix(path, "/api/v1/deployments/"), true
+	case "/api/v1/deployments/{namespace}/{name}":
+		return strings.TrimPrefix(path, "/api/v1/deployments/"), true
+	}
+	return "", false
+}
+
+// responseCacheMiddleware answers cacheable requests from the response cache,
+// and caches the successful responses of those it passes on
+func (s *Server) responseCacheMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if s.responseCache == nil {
+		return next
+	}
+	return func(ctx *fasthttp.RequestCtx) {
+		// Responses from a snapshot aren't cached, since the keys of lists
+		// only change with deployment events
+		deployment, ok := cachedDeployment(ctx)
+		if !ok || s.deploymentsStale() {
+			next(ctx)
+			return
+		}
+
+		route := routeFor(string(ctx.Path()))
+		key := s.responseCache.key(deployment, string(ctx.RequestURI()))
+		if entry, hit := s.responseCache.entries.Get(key); hit {
+			metrics.APIResponseCache.WithLabelValues(route, "hit").Inc()
+			ctx.Response.Header.Set("X-Cache", "HIT")
+			ctx.SetStatusCode(entry.statusCode)
+			ctx.SetContentType(entry.contentType)
+			ctx.SetBody(entry.body)
+			return
+		}
+
+		metrics.APIResponseCache.WithLabelValues(route, "miss").Inc()
+		next(ctx)
+		ctx.Response.Header.Set("X-Cache", "MISS")
+		if ctx.Response.StatusCode() != fasthttp.StatusOK {
+			return
+		}
+		s.responseCache.entries.Set(key, &cachedResponse{
+			deployment:  deployment,
+			statusCode:  ctx.Response.StatusCode(),
+			contentType: string(ctx.Response.Header.ContentType()),
+			body:        append([]byte(nil), ctx.Response.Body()...),
+		}, s.responseCache.ttl)
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResponseCacheMiddleware(t *testing.T) {
+	web := newTestDeployment("shop", "web", 2, map[string]string{"app": "web"})
+	web.ResourceVersion = "1"
+	api := newTestDeployment("shop", "api", 1, map[string]string{"app": "api"})
+	api.ResourceVersion = "2"
+	clientset := fake.NewSimpleClientset(web, api)
+	informer := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+
+	cfg := config.DefaultConfig().Server
+	cfg.ResponseCache = config.ResponseCacheConfig{Enabled: true, TTL: time.Minute}
+	srv := NewWithConfig(cfg)
+	srv.SetDeploymentInformer(informer)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("informer.Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	cacheResult := func(uri string) string {
+		return string(serve(srv, fasthttp.MethodGet, uri, "").Response.Header.Peek("X-Cache"))
+	}
+	for _, uri := range []string{"/api/v1/deployments", "/api/v1/deployments/shop/web"} {
+		if got := cacheResult(uri); got != "MISS" {
+			t.Errorf("%s: expected a miss on the first request, got %q", uri, got)
+		}
+		if got := cacheResult(uri); got != "HIT" {
+			t.Errorf("%s: expected a hit on the second request, got %q", uri, got)
+		}
+	}
+	if got := cacheResult("/api/v1/deployments/shop/web/revisions"); got != "" {
+		t.Errorf("Expected revisions not to be cached, got %q", got)
+	}
+
+	// Scaling api drops the lists but keeps the response of web
+	scaled := api.DeepCopy()
+	scaled.ResourceVersion = "3"
+	scaled.Spec.Replicas = int32Ptr(5)
+	if _, err := clientset.AppsV1().Deployments("shop").Update(context.TODO(), scaled, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for cacheResult("/api/v1/deployments") == "HIT" {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the update to invalidate the cached list")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	var list DeploymentListResponse
+	_ = json.Unmarshal(serve(srv, fasthttp.MethodGet, "/api/v1/deployments?sortBy=-replicas", "").Response.Body(), &list)
+	if list.Total != 2 || list.Items[0].Replicas != 5 {
+		t.Errorf("Expected the scaled api first, got %+v", list)
+	}
+	if got := cacheResult("/api/v1/deployments/shop/web"); got != "HIT" {
+		t.Errorf("Expected web to stay cached, got %q", got)
+	}
+
+	if srv.responseCache.Len() == 0 {
+		t.Fatal("Expected cached responses")
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	responses := NewResponseCache(time.Millisecond, 0)
+	responses.OnAdd(newTestDeployment("shop", "web", 1, nil))
+	key := responses.key("shop/web", "/api/v1/deployments/shop/web")
+	responses.entries.Set(key, &cachedResponse{deployment: "shop/web"}, responses.ttl)
+	time.Sleep(5 * time.Millisecond)
+	if _, hit := responses.entries.Get(key); hit {
+		t.Error("Expected the response to expire")
+	}
+}
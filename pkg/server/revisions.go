@@ -0,0 +1,75 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+)
+
+// RevisionResponse is a rollout revision of a deployment
+type RevisionResponse = kubernetes.Revision
+
+// DeploymentRevisionsResponse lists the rollout revisions of a deployment in
+// ascending order
+type DeploymentRevisionsResponse struct {
+	Name      string             `json:"name"`
+	Namespace string             `json:"namespace"`
+	Revisions []RevisionResponse `json:"revisions"`
+}
+
+// SetReplicaSetInformer sets the replica set cache deployment revisions are read from
+func (s *Server) SetReplicaSetInformer(informer *kubernetes.ReplicaSetInformer) {
+	s.replicaSets = informer
+}
+
+// handleDeploymentRevisions handles GET /api/v1/deployments/{namespace}/{name}/revisions
+func (s *Server) handleDeploymentRevisions(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.deploymentHandler == nil {
+		s.handleServiceUnavailable(ctx, "Deployment informer not configured")
+		return
+	}
+	if s.replicaSets == nil {
+		s.handleServiceUnavailable(ctx, "ReplicaSet informer not configured")
+		return
+	}
+	informer := s.deploymentHandler.informer
+	if !informer.IsStarted() || !informer.HasSynced() || !s.replicaSets.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Deployment or ReplicaSet informer cache is not synced")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(string(ctx.Path()), "/api/v1/deployments/"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid revisions path format")
+		return
+	}
+	namespace, name := parts[0], parts[1]
+
+	dep, err := informer.GetDeployment(namespace, name)
+	if err != nil {
+		sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Deployment %s/%s not found", namespace, name))
+		return
+	}
+
+	revisions, err := s.replicaSets.Revisions(dep)
+	if err != nil {
+		requestLog(ctx).Error("Failed to list replica sets from cache", err, map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		})
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve revisions")
+		return
+	}
+
+	sendJSON(ctx, fasthttp.StatusOK, DeploymentRevisionsResponse{
+		Name:      name,
+		Namespace: namespace,
+		Revisions: revisions,
+	})
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeploymentRevisions(t *testing.T) {
+	dep := newTestDeployment("shop", "web", 3, nil)
+	dep.UID = "web-uid"
+	dep.Annotations = map[string]string{"deployment.kubernetes.io/revision": "2"}
+	controller := true
+	replicaSet := func(name, revision string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "shop",
+			Annotations:     map[string]string{"deployment.kubernetes.io/revision": revision},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: dep.UID, Controller: &controller}},
+		}}
+	}
+	clientset := fake.NewSimpleClientset(dep, replicaSet("web-b", "2"), replicaSet("web-a", "1"))
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	deployments := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+	srv.SetDeploymentInformer(deployments)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/web/revisions", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a replica set informer, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	replicaSets := kubernetes.NewReplicaSetInformer(clientset, "", time.Minute)
+	if err := replicaSets.Start(ctx); err != nil {
+		t.Fatalf("replicaSets.Start() error = %v", err)
+	}
+	defer replicaSets.Stop()
+	srv.SetReplicaSetInformer(replicaSets)
+
+	resp := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/web/revisions", "")
+	if resp.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Response.StatusCode(), resp.Response.Body())
+	}
+	var response DeploymentRevisionsResponse
+	if err := json.Unmarshal(resp.Response.Body(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(response.Revisions) != 2 || response.Revisions[0].ReplicaSet != "web-a" || !response.Revisions[1].Current {
+		t.Errorf("Unexpected revisions: %+v", response)
+	}
+
+	if resp := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/api/revisions", ""); resp.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown deployment, got %d", resp.Response.StatusCode())
+	}
+}
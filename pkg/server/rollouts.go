@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/rollout"
+	"github.com/valyala/fasthttp"
+)
+
+// SetRolloutTracker sets the tracker whose latest report is served at
+// /api/v1/rollouts
+func (s *Server) SetRolloutTracker(tracker *rollout.Tracker) {
+	s.rollouts = tracker
+}
+
+// handleRollouts handles GET /api/v1/rollouts, returning the rollout health
+// of the last check filtered by the namespace and status query parameters,
+// and GET /api/v1/rollouts/{namespace}/{name}
+func (s *Server) handleRollouts(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.rollouts == nil || !s.rollouts.Enabled() {
+		s.handleServiceUnavailable(ctx, "Rollout health tracking not configured (controller.rollouts.enabled)")
+		return
+	}
+	report, checked := s.rollouts.Report()
+	if !checked {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Rollout health tracking has not completed a check yet")
+		return
+	}
+
+	path := strings.TrimPrefix(string(ctx.Path()), "/api/v1/rollouts")
+	if path != "" {
+		parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Expected a path of the form /api/v1/rollouts/{namespace}/{name}")
+			return
+		}
+		for _, health := range report.Deployments {
+			if health.Namespace == parts[0] && health.Name == parts[1] {
+				sendJSON(ctx, fasthttp.StatusOK, health)
+				return
+			}
+		}
+		sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Deployment %s/%s not found", parts[0], parts[1]))
+		return
+	}
+
+	status, err := rollout.ParseStatus(string(ctx.QueryArgs().Peek("status")))
+	if err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+	sendJSON(ctx, fasthttp.StatusOK, rollout.Filter(report, string(ctx.QueryArgs().Peek("namespace")), status))
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/rollout"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleRollouts(t *testing.T) {
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/rollouts", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a tracker, got %d", ctx.Response.StatusCode())
+	}
+
+	web := newTestDeployment("shop", "web", 2, nil)
+	web.Status = appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 2, Conditions: []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"},
+	}}
+	api := newTestDeployment("ops", "api", 1, nil)
+	api.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	deployments := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(web, api), "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+
+	cfg := config.DefaultConfig().Controller.Rollouts
+	tracker := rollout.NewTracker(deployments, cfg)
+	srv.SetRolloutTracker(tracker)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/rollouts", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 while tracking is disabled, got %d", ctx.Response.StatusCode())
+	}
+
+	cfg.Enabled = true
+	tracker.SetConfig(cfg)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/rollouts", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first check, got %d", ctx.Response.StatusCode())
+	}
+
+	tracker.Check()
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/rollouts?status=stuck", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var report rollout.Report
+	if err := json.Unmarshal(ctx.Response.Body(), &report); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if len(report.Deployments) != 1 || report.Deployments[0].Name != "web" || report.Stuck != 1 {
+		t.Errorf("Expected only the stuck web deployment, got %+v", report)
+	}
+
+	ctx = serve(srv, fasthttp.MethodGet, "/api/v1/rollouts/ops/api", "")
+	var health rollout.Health
+	if err := json.Unmarshal(ctx.Response.Body(), &health); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if health.Status != rollout.StatusHealthy || health.Score != 100 {
+		t.Errorf("Expected api to be healthy, got %+v", health)
+	}
+
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/rollouts/ops/missing", ""); ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown deployment, got %d", ctx.Response.StatusCode())
+	}
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/rollouts?status=broken", ""); ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown status, got %d", ctx.Response.StatusCode())
+	}
+	if ctx := serve(srv, fasthttp.MethodPost, "/api/v1/rollouts", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", ctx.Response.StatusCode())
+	}
+}
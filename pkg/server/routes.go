@@ -0,0 +1,68 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// RouteListResponse represents the response for route list, for all hosts
+// or the host of the host query parameter
+type RouteListResponse struct {
+	Host  string             `json:"host,omitempty"`
+	Items []kubernetes.Route `json:"items"`
+	Count int                `json:"count"`
+}
+
+// SetIngressInformer sets the Ingress and Service cache served at /api/v1/routes
+func (s *Server) SetIngressInformer(informer *kubernetes.IngressInformer) {
+	s.ingresses = informer
+}
+
+// handleRoutes handles GET /api/v1/routes, listing the Ingress routes to
+// Services and the deployments behind them, only those serving a host with
+// the host query parameter
+func (s *Server) handleRoutes(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if s.ingresses == nil {
+		s.handleServiceUnavailable(ctx, "Ingress informer not configured")
+		return
+	}
+	if !s.ingresses.HasSynced() {
+		sendError(ctx, fasthttp.StatusServiceUnavailable, "Service unavailable", "Ingress informer cache is not synced")
+		return
+	}
+
+	host := string(ctx.QueryArgs().Peek("host"))
+	routes, err := s.ingresses.Routes(host, s.cachedDeployments())
+	if err != nil {
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to retrieve routes")
+		return
+	}
+	if routes == nil {
+		routes = []kubernetes.Route{}
+	}
+	sendJSON(ctx, fasthttp.StatusOK, RouteListResponse{Host: host, Items: routes, Count: len(routes)})
+}
+
+// cachedDeployments returns the cached deployments, or nil when deployments
+// are not cached or not synced
+func (s *Server) cachedDeployments() []*appsv1.Deployment {
+	if s.deploymentHandler == nil {
+		return nil
+	}
+	informer := s.deploymentHandler.informer
+	if !informer.IsStarted() || !informer.HasSynced() {
+		return nil
+	}
+	deployments, err := informer.ListDeployments()
+	if err != nil {
+		return nil
+	}
+	return deployments
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandleRoutes(t *testing.T) {
+	dep := newTestDeployment("shop", "web", 2, nil)
+	dep.Spec.Template.Labels = map[string]string{"app": "web"}
+	clientset := fake.NewSimpleClientset(dep,
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}, Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "web"}}},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "shop"},
+			Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{
+				Host: "shop.example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{
+					Path:    "/",
+					Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}}},
+				}}}},
+			}}},
+		},
+	)
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/routes", ""); ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without an ingress informer, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ingresses := kubernetes.NewIngressInformer(clientset, "", time.Minute)
+	if err := ingresses.Start(ctx); err != nil {
+		t.Fatalf("ingresses.Start() error = %v", err)
+	}
+	defer ingresses.Stop()
+	deployments := kubernetes.NewDeploymentInformer(clientset, "", time.Minute)
+	if err := deployments.Start(); err != nil {
+		t.Fatalf("deployments.Start() error = %v", err)
+	}
+	defer deployments.Stop()
+	srv.SetIngressInformer(ingresses)
+	srv.SetDeploymentInformer(deployments)
+
+	resp := serve(srv, fasthttp.MethodGet, "/api/v1/routes?host=shop.example.com", "")
+	if resp.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Response.StatusCode(), resp.Response.Body())
+	}
+	var list RouteListResponse
+	if err := json.Unmarshal(resp.Response.Body(), &list); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if list.Count != 1 || list.Host != "shop.example.com" {
+		t.Fatalf("Expected the route of shop.example.com, got %+v", list)
+	}
+	if route := list.Items[0]; route.Service != "web" || len(route.Deployments) != 1 || route.Deployments[0] != "web" {
+		t.Errorf("Expected shop.example.com to be served by deployment web, got %+v", route)
+	}
+
+	resp = serve(srv, fasthttp.MethodGet, "/api/v1/routes?host=unknown.test", "")
+	if err := json.Unmarshal(resp.Response.Body(), &list); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if list.Count != 0 || list.Items == nil {
+		t.Errorf("Expected an empty route list, got %+v", list)
+	}
+}
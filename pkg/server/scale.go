@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ScaleRequest is the body of a scale request. With a resourceVersion, e.g.
+// from a scale preview, the deployment is only scaled when it has not changed
+// since that version.
+type ScaleRequest struct {
+	Replicas        *int32 `json:"replicas"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// ScaleResponse reports the result of a scale request
+type ScaleResponse struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	Replicas        int32  `json:"replicas"`
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// handleScaleDeployment handles PUT /api/v1/deployments/{namespace}/{name}/scale.
+// Scaling writes to the cluster, so it is only available when an API
+// authentication method (tokens, token review or client certificates) is
+// configured.
+func (s *Server) handleScaleDeployment(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsPut() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	if !s.config.Auth.Enabled() {
+		sendError(ctx, fasthttp.StatusForbidden, "Forbidden", "Scaling requires API authentication to be configured (server.auth)")
+		return
+	}
+	if s.client == nil {
+		s.handleServiceUnavailable(ctx, "Kubernetes client not configured")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(string(ctx.Path()), "/api/v1/deployments/"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid scale path format")
+		return
+	}
+	namespace, name := parts[0], parts[1]
+
+	var req ScaleRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Replicas == nil || *req.Replicas < 0 {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "replicas must be a non-negative number")
+		return
+	}
+
+	deployment, err := s.client.DeploymentScale(ctx, namespace, name, *req.Replicas, req.ResourceVersion)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			sendError(ctx, fasthttp.StatusNotFound, "Not found", fmt.Sprintf("Deployment %s/%s not found", namespace, name))
+			return
+		}
+		if apierrors.IsConflict(err) {
+			sendError(ctx, fasthttp.StatusConflict, "Conflict", fmt.Sprintf("Deployment %s/%s has changed since resource version %s", namespace, name, req.ResourceVersion))
+			return
+		}
+		requestLog(ctx).Error("Failed to scale deployment", err, map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		})
+		sendError(ctx, fasthttp.StatusInternalServerError, "Internal server error", "Failed to scale deployment")
+		return
+	}
+
+	principal, _ := ctx.UserValue(principalKey).(string)
+	requestLog(ctx).Info("Scaled deployment", map[string]interface{}{
+		"namespace": namespace,
+		"name":      name,
+		"replicas":  *req.Replicas,
+		"principal": principal,
+	})
+
+	sendJSON(ctx, fasthttp.StatusOK, ScaleResponse{
+		Name:            deployment.Name,
+		Namespace:       deployment.Namespace,
+		Replicas:        *deployment.Spec.Replicas,
+		ResourceVersion: deployment.ResourceVersion,
+	})
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestScaleDeployment(t *testing.T) {
+	dep := newTestDeployment("shop", "web", 1, nil)
+	dep.ResourceVersion = "7"
+	clientset := fake.NewSimpleClientset(dep)
+	// The fake clientset ignores resource versions in patches, the API server
+	// rejects stale ones
+	clientset.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		var patch struct {
+			Metadata struct {
+				ResourceVersion string `json:"resourceVersion"`
+			} `json:"metadata"`
+		}
+		_ = json.Unmarshal(action.(k8stesting.PatchAction).GetPatch(), &patch)
+		if rv := patch.Metadata.ResourceVersion; rv != "" && rv != "7" {
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "web", nil)
+		}
+		return false, nil, nil
+	})
+
+	cfg := config.DefaultConfig().Server
+	cfg.Auth.Tokens = []config.APIToken{{Name: "ci", Token: "0123456789abcdef", Role: "admin"}}
+	srv := NewWithConfig(cfg)
+	srv.SetDeploymentClient(kubernetes.NewClientWithClientset(clientset))
+
+	scale := func(uri, body string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(fasthttp.MethodPut)
+		ctx.Request.SetRequestURI(uri)
+		ctx.Request.Header.Set("Authorization", "Bearer 0123456789abcdef")
+		ctx.Request.SetBodyString(body)
+		srv.Handler()(ctx)
+		return ctx
+	}
+
+	tests := []struct {
+		name   string
+		uri    string
+		body   string
+		status int
+	}{
+		{"stale resource version", "/api/v1/deployments/shop/web/scale", `{"replicas":3,"resourceVersion":"6"}`, fasthttp.StatusConflict},
+		{"current resource version", "/api/v1/deployments/shop/web/scale", `{"replicas":3,"resourceVersion":"7"}`, fasthttp.StatusOK},
+		{"any resource version", "/api/v1/deployments/shop/web/scale", `{"replicas":4}`, fasthttp.StatusOK},
+		{"negative replicas", "/api/v1/deployments/shop/web/scale", `{"replicas":-1}`, fasthttp.StatusBadRequest},
+		{"unknown deployment", "/api/v1/deployments/shop/api/scale", `{"replicas":1}`, fasthttp.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := scale(tt.uri, tt.body)
+			if ctx.Response.StatusCode() != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+
+	ctx := scale("/api/v1/deployments/shop/web/scale", `{"replicas":5}`)
+	var response ScaleResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &response); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if response.Replicas != 5 || response.Name != "web" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
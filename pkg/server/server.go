@@ -1,67 +1,463 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/anomaly"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/compliance"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/drift"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/history"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
-	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/propagation"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/rollout"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/security"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
 	"github.com/valyala/fasthttp"
 )
 
 // Server represents the HTTP server
 type Server struct {
 	port              int
+	config            config.ServerConfig
 	deploymentHandler *DeploymentHandler
+	client            *kubernetes.Client
+	events            *EventHub
+	accessLog         *AccessLogger
+	labels            kubernetes.LabelPropagator
+	clusters          *cluster.ConfigStore
+	drift             *drift.Detector
+	manifestDrift     *drift.ManifestDetector
+	compliance        *compliance.Scanner
+	rollouts          *rollout.Tracker
+	anomalies         *anomaly.Detector
+	propagator        *propagation.Propagator
+	eventHistory      *history.EventStore
+	deletedHistory    *history.Store
+	deletedCluster    string
+	clusterInformers  *ClusterInformers
+	health            *cluster.HealthTracker
+	namespaces        *kubernetes.NamespaceInformer
+	pods              *kubernetes.PodInformer
+	nodes             *kubernetes.NodeInformer
+	jobs              *kubernetes.JobInformer
+	ingresses         *kubernetes.IngressInformer
+	pvcs              *kubernetes.PVCInformer
+	replicaSets       *kubernetes.ReplicaSetInformer
+	kubeEvents        *kubernetes.EventInformer
+	configs           *kubernetes.ConfigInformer
+	tokenReview       *TokenReviewer
+	clientCAs         *x509.CertPool
+	clientCAsOnce     sync.Once
+	clientCAsErr      error
+	authorizer        *security.Authorizer
+	rateLimiter       *RateLimiter
+	responseCache     *ResponseCache
+	clusterLists      *sharedQuery[clusterListing]
+	metrics           fasthttp.RequestHandler
+	startTime         time.Time
+
+	// Checks added to the /livez and /readyz probes
+	probesMu        sync.Mutex
+	livenessChecks  []namedCheck
+	readinessChecks []namedCheck
+	everReady       atomic.Bool
+
+	// Listeners stopped by Shutdown
+	serveMu      sync.Mutex
+	serving      []*fasthttp.Server
+	shuttingDown atomic.Bool
+	
+	// OpenAPI document, generated on first request
+	openAPIOnce sync.Once
+	openAPIDoc  []byte
+	openAPIErr  error
 }
 
 // New creates a new server instance
 func New(port int) *Server {
+	cfg := config.DefaultConfig().Server
+	cfg.Port = port
+	return NewWithConfig(cfg)
+}
+
+// NewWithConfig creates a new server instance using server configuration
+func NewWithConfig(cfg config.ServerConfig) *Server {
 	return &Server{
-		port: port,
+		port:          cfg.Port,
+		config:        cfg,
+		authorizer:    newAuthorizer(cfg.Auth),
+		rateLimiter:   newRateLimiter(cfg.Limits),
+		responseCache: newResponseCache(cfg.ResponseCache),
+		clusterLists:  newSharedQuery[clusterListing]("cluster_deployments", cfg.Limits.ClusterListTTL),
+		startTime:     time.Now(),
 	}
 }
 
-// SetDeploymentInformer sets the deployment informer for API endpoints
+// SetDeploymentInformer sets the deployment informer for API endpoints and
+// registers the server's event handlers with it. The informer may already be
+// running; watch subscribers and the response cache then see the events
+// delivered from here on.
 func (s *Server) SetDeploymentInformer(informer *kubernetes.DeploymentInformer) {
 	s.deploymentHandler = NewDeploymentHandler(informer)
+	s.deploymentHandler.SetFieldMask(s.config.FieldMask)
+	s.deploymentHandler.SetLabelPropagator(s.labels)
+
+	// Stream informer events to watch subscribers
+	s.events = NewEventHub(informer, !s.config.DisableEnrichment)
+	s.events.SetLabelPropagator(s.labels)
+	informer.AddEventHandler(s.events)
+
+	if s.responseCache != nil {
+		informer.AddEventHandler(s.responseCache)
+	}
+}
+
+// SetLabelPropagator sets the source of labels deployments inherit in API
+// views, such as cost allocation labels from their namespace. It must be
+// called before SetDeploymentInformer.
+func (s *Server) SetLabelPropagator(propagator kubernetes.LabelPropagator) {
+	s.labels = propagator
+}
+
+// SetDeploymentClient sets the Kubernetes client used by write endpoints
+func (s *Server) SetDeploymentClient(client *kubernetes.Client) {
+	s.client = client
 }
 
 // Start starts the HTTP server
 func (s *Server) Start() error {
-	logger.Info("Starting HTTP server", map[string]interface{}{
+	serverLog().Info("Starting HTTP server", map[string]interface{}{
 		"port": s.port,
 	})
 
-	// Create request handler with logging middleware
-	requestHandler := s.loggingMiddleware(func(ctx *fasthttp.RequestCtx) {
-		path := string(ctx.Path())
-		
-		switch {
-		case path == "/health":
-			s.handleHealth(ctx)
-		case path == "/version":
-			s.handleVersion(ctx)
-		case strings.HasPrefix(path, "/api/v1/deployments"):
-			if s.deploymentHandler != nil {
-				s.deploymentHandler.HandleDeployments(ctx)
-			} else {
-				s.handleServiceUnavailable(ctx, "Deployment informer not configured")
-			}
-		default:
-			s.handleNotFound(ctx)
+	if err := s.loadClientCAs(); err != nil {
+		return err
+	}
+
+	if s.config.AccessLog.Enabled {
+		accessLog, err := NewAccessLogger(s.config.AccessLog)
+		if err != nil {
+			return err
 		}
-	})
+		s.accessLog = accessLog
+		defer func() {
+			_ = accessLog.Close()
+		}()
+	}
+
+	if s.events != nil {
+		defer s.events.Close()
+	}
+
+	if s.metrics != nil && s.config.MetricsPort != 0 {
+		go s.serveMetrics()
+	}
 
 	// Start server
 	addr := ":" + strconv.Itoa(s.port)
-	logger.Info("Server listening", map[string]interface{}{
+	if !s.config.TLS.Enabled() {
+		serverLog().Info("Server listening", map[string]interface{}{
+			"address": addr,
+		})
+		return s.serve(s.httpServer()).ListenAndServe(addr)
+	}
+
+	// Stops reloading the certificate once the server stops
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tlsConfig, err := s.tlsConfig(ctx)
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	serverLog().Info("Server listening", map[string]interface{}{
 		"address": addr,
+		"tls":     true,
 	})
+	return s.serve(s.httpServer()).Serve(tls.NewListener(ln, tlsConfig))
+}
+
+// serve records a listening server, for Shutdown to stop it
+func (s *Server) serve(srv *fasthttp.Server) *fasthttp.Server {
+	s.serveMu.Lock()
+	defer s.serveMu.Unlock()
+	s.serving = append(s.serving, srv)
+	return srv
+}
+
+// Shutdown stops the server gracefully: /readyz fails, watch streams end, the
+// listeners stop accepting connections, and in-flight requests are drained
+// until ctx is done. Start returns once its listener stopped.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+	if s.events != nil {
+		s.events.Close()
+	}
+
+	s.serveMu.Lock()
+	serving := s.serving
+	s.serveMu.Unlock()
+
+	var errs []error
+	for _, srv := range serving {
+		if err := srv.ShutdownWithContext(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// httpServer returns the fasthttp server of the handler, limiting request
+// bodies to the configured size
+func (s *Server) httpServer() *fasthttp.Server {
+	return &fasthttp.Server{
+		Handler:            s.Handler(),
+		MaxRequestBodySize: s.config.Limits.MaxBodySize,
+		ErrorHandler:       handleRequestError,
+	}
+}
+
+// handleRequestError answers requests that can't be read, such as requests
+// with a body over the size limit
+func handleRequestError(ctx *fasthttp.RequestCtx, err error) {
+	if errors.Is(err, fasthttp.ErrBodyTooLarge) {
+		sendError(ctx, fasthttp.StatusRequestEntityTooLarge, "Request entity too large", "Request body exceeds the size limit")
+		return
+	}
+	sendError(ctx, fasthttp.StatusBadRequest, "Bad request", "Malformed request")
+}
+
+// TLSConfig returns the TLS configuration of the server for another API, such
+// as the gRPC API, serving the same certificate and client CAs. The
+// certificate is reloaded until ctx is cancelled when a reload interval is set.
+func (s *Server) TLSConfig(ctx context.Context) (*tls.Config, error) {
+	if err := s.loadClientCAs(); err != nil {
+		return nil, err
+	}
+	return s.tlsConfig(ctx)
+}
+
+// tlsConfig returns the TLS configuration of the server: its certificate,
+// reloaded until ctx is cancelled when a reload interval is set, and the client
+// CAs that verify the certificates clients may present
+func (s *Server) tlsConfig(ctx context.Context) (*tls.Config, error) {
+	certs, err := NewCertificateReloader(s.config.TLS.CertFile, s.config.TLS.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	if interval := s.config.TLS.ReloadInterval; interval > 0 {
+		go certs.Watch(ctx, interval)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+	if s.clientCAs != nil {
+		// Clients without a certificate can still authenticate with a token
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		tlsConfig.ClientCAs = s.clientCAs
+	}
+	return tlsConfig, nil
+}
+
+// Handler returns the request handler with all middleware applied
+func (s *Server) Handler() fasthttp.RequestHandler {
+	handler := s.loggingMiddleware(s.corsMiddleware(s.rateLimitMiddleware(s.authMiddleware(s.staleMiddleware(s.responseCacheMiddleware(s.route))))))
+	if s.accessLog != nil {
+		handler = s.accessLog.Middleware(handler)
+	}
+	// Timeouts wrap the other middleware, so that none reads a response a
+	// timed out handler still writes
+	return s.requestIDMiddleware(s.timeoutMiddleware(s.proxyMiddleware(s.metricsMiddleware(s.tracingMiddleware(handler)))))
+}
+
+// route dispatches a request to the matching endpoint handler
+func (s *Server) route(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	if strings.HasPrefix(path, "/api/") && !s.checkCluster(ctx) {
+		return
+	}
+	if s.config.DisableEnrichment && enrichmentRoutes[routeFor(path)] {
+		sendError(ctx, fasthttp.StatusNotFound, "Not found", "Enrichment endpoints are disabled by server.disable_enrichment")
+		return
+	}
 	
-	return fasthttp.ListenAndServe(addr, requestHandler)
+	switch {
+	case path == "/health":
+		s.handleHealth(ctx)
+	case path == "/livez":
+		s.handleLivez(ctx)
+	case path == "/readyz":
+		s.handleReadyz(ctx)
+	case path == "/version":
+		s.handleVersion(ctx)
+	case path == "/metrics":
+		s.handleMetrics(ctx)
+	case path == "/api/v1/info":
+		s.handleInfo(ctx)
+	case path == "/openapi.json":
+		s.handleOpenAPI(ctx)
+	case path == "/docs":
+		s.handleDocs(ctx)
+	case path == "/api/v1/deployments/watch":
+		if s.events != nil {
+			s.handleWatchDeployments(ctx)
+		} else {
+			s.handleServiceUnavailable(ctx, "Deployment informer not configured")
+		}
+	case path == "/api/v1/deployments/ws":
+		if s.events != nil {
+			s.handleDeploymentsWebSocket(ctx)
+		} else {
+			s.handleServiceUnavailable(ctx, "Deployment informer not configured")
+		}
+	case path == "/api/v1/batch/scale/preview":
+		s.handleScalePreview(ctx)
+	case path == "/api/v1/drift":
+		s.handleDrift(ctx)
+	case path == "/api/v1/drift/manifests":
+		s.handleManifestDrift(ctx)
+	case path == "/api/v1/compliance":
+		s.handleCompliance(ctx)
+	case path == "/api/v1/rollouts" || strings.HasPrefix(path, "/api/v1/rollouts/"):
+		s.handleRollouts(ctx)
+	case path == "/api/v1/propagation":
+		s.handlePropagation(ctx)
+	case path == "/api/v1/history":
+		s.handleHistory(ctx)
+	case path == "/api/v1/history/samples":
+		s.handleHistorySamples(ctx)
+	case path == "/api/v1/anomalies":
+		s.handleAnomalies(ctx)
+	case path == "/api/v1/loglevel":
+		s.handleLogLevel(ctx)
+	case path == "/api/v1/namespaces" || strings.HasPrefix(path, "/api/v1/namespaces/"):
+		s.handleNamespaces(ctx)
+	case path == "/api/v1/nodes" || strings.HasPrefix(path, "/api/v1/nodes/"):
+		s.handleNodes(ctx)
+	case path == "/api/v1/jobs" || strings.HasPrefix(path, "/api/v1/jobs/"):
+		s.handleJobs(ctx)
+	case path == "/api/v1/cronjobs" || strings.HasPrefix(path, "/api/v1/cronjobs/"):
+		s.handleCronJobs(ctx)
+	case path == "/api/v1/routes":
+		s.handleRoutes(ctx)
+	case path == "/api/v1/pvcs" || strings.HasPrefix(path, "/api/v1/pvcs/"):
+		s.handlePVCs(ctx)
+	case strings.HasPrefix(path, "/api/v1/clusters/") && strings.HasSuffix(path, "/deployments"):
+		s.handleClusterDeployments(ctx)
+	case path == "/api/v1/clusters" || strings.HasPrefix(path, "/api/v1/clusters/"):
+		s.handleClusters(ctx)
+	case strings.HasPrefix(path, "/api/v1/deployments/") && strings.HasSuffix(path, "/revisions"):
+		s.handleDeploymentRevisions(ctx)
+	case strings.HasPrefix(path, "/api/v1/deployments/") && strings.HasSuffix(path, "/events"):
+		s.handleDeploymentEvents(ctx)
+	case strings.HasPrefix(path, "/api/v1/deployments/") && strings.HasSuffix(path, "/config"):
+		s.handleDeploymentConfig(ctx)
+	case strings.HasPrefix(path, "/api/v1/deployments/") && strings.HasSuffix(path, "/scale"):
+		s.handleScaleDeployment(ctx)
+	case path == "/api/v1/deployments" && ctx.IsPost():
+		s.handleCreateDeployment(ctx)
+	case strings.HasPrefix(path, "/api/v1/deployments/") && ctx.IsDelete():
+		s.handleDeleteDeployment(ctx)
+	case path == "/api/v1/deployments" && string(ctx.QueryArgs().Peek("cluster")) == allClusters:
+		s.handleAggregatedDeployments(ctx)
+	case strings.HasPrefix(path, "/api/v1/deployments"):
+		if s.deploymentHandler != nil {
+			s.deploymentHandler.HandleDeployments(ctx)
+		} else {
+			s.handleServiceUnavailable(ctx, "Deployment informer not configured")
+		}
+	default:
+		s.handleNotFound(ctx)
+	}
+}
+
+// enrichmentRoutes are the endpoints adding related resources to cached
+// deployments, and those served from the namespace and pod caches, which
+// server.disable_enrichment turns off
+var enrichmentRoutes = map[string]bool{
+	"/api/v1/deployments/{namespace}/{name}/revisions": true,
+	"/api/v1/deployments/{namespace}/{name}/events":    true,
+	"/api/v1/deployments/{namespace}/{name}/config":    true,
+	"/api/v1/routes": true,
+	"/api/v1/namespaces":                   true,
+	"/api/v1/namespaces/{name}":            true,
+	"/api/v1/namespaces/{name}/deployments": true,
+	"/api/v1/namespaces/{name}/pods":       true,
+	"/api/v1/nodes/{name}/pods":            true,
+}
+
+// routeFor returns the route template matching a request path,
+// so that logs and metrics don't leak resource names
+func routeFor(path string) string {
+	switch {
+	case path == "/health", path == "/livez", path == "/readyz", path == "/version", path == "/metrics", path == "/openapi.json", path == "/docs":
+		return path
+	case path == "/api/v1/deployments", path == "/api/v1/deployments/watch", path == "/api/v1/deployments/ws",
+		path == "/api/v1/deployments/aggregate", path == "/api/v1/deployments/index":
+		return path
+	case strings.HasPrefix(path, "/api/v1/deployments/"):
+		parts := strings.Split(strings.TrimPrefix(path, "/api/v1/deployments/"), "/")
+		if len(parts) == 1 {
+			return "/api/v1/deployments/{name}"
+		}
+		if len(parts) == 3 && (parts[2] == "scale" || parts[2] == "revisions" || parts[2] == "events" || parts[2] == "config") {
+			return "/api/v1/deployments/{namespace}/{name}/" + parts[2]
+		}
+		return "/api/v1/deployments/{namespace}/{name}"
+	case path == "/api/v1/clusters", path == "/api/v1/batch/scale/preview", path == "/api/v1/drift", path == "/api/v1/drift/manifests", path == "/api/v1/compliance", path == "/api/v1/info",
+		path == "/api/v1/propagation", path == "/api/v1/history", path == "/api/v1/loglevel", path == "/api/v1/namespaces", path == "/api/v1/nodes",
+		path == "/api/v1/jobs", path == "/api/v1/cronjobs", path == "/api/v1/routes",
+		path == "/api/v1/pvcs", path == "/api/v1/rollouts", path == "/api/v1/history/samples", path == "/api/v1/anomalies":
+		return path
+	case strings.HasPrefix(path, "/api/v1/namespaces/"):
+		parts := strings.Split(strings.TrimPrefix(path, "/api/v1/namespaces/"), "/")
+		if len(parts) == 2 && (parts[1] == "deployments" || parts[1] == "pods") {
+			return "/api/v1/namespaces/{name}/" + parts[1]
+		}
+		return "/api/v1/namespaces/{name}"
+	case strings.HasPrefix(path, "/api/v1/nodes/"):
+		parts := strings.Split(strings.TrimPrefix(path, "/api/v1/nodes/"), "/")
+		if len(parts) == 2 && parts[1] == "pods" {
+			return "/api/v1/nodes/{name}/pods"
+		}
+		return "/api/v1/nodes/{name}"
+	case strings.HasPrefix(path, "/api/v1/jobs/"):
+		return "/api/v1/jobs/{namespace}/{name}"
+	case strings.HasPrefix(path, "/api/v1/cronjobs/"):
+		return "/api/v1/cronjobs/{namespace}/{name}"
+	case strings.HasPrefix(path, "/api/v1/pvcs/"):
+		return "/api/v1/pvcs/{namespace}/{name}"
+	case strings.HasPrefix(path, "/api/v1/rollouts/"):
+		return "/api/v1/rollouts/{namespace}/{name}"
+	case strings.HasPrefix(path, "/api/v1/clusters/"):
+		parts := strings.Split(strings.TrimPrefix(path, "/api/v1/clusters/"), "/")
+		if len(parts) == 2 {
+			switch parts[1] {
+			case "enable", "disable", "primary", "connectivity", "status", "deployments":
+				return "/api/v1/clusters/{name}/" + parts[1]
+			}
+		}
+		return "/api/v1/clusters/{name}"
+	default:
+		return "unmatched"
+	}
 }
 
 // handleHealth handles health check endpoint
@@ -75,7 +471,16 @@ func (s *Server) handleHealth(ctx *fasthttp.RequestCtx) {
 func (s *Server) handleVersion(ctx *fasthttp.RequestCtx) {
 	ctx.SetContentType("application/json")
 	ctx.SetStatusCode(fasthttp.StatusOK)
-	fmt.Fprintf(ctx, `{"version":"v0.12.0"}`)
+	sendJSON(ctx, fasthttp.StatusOK, map[string]string{"version": version.Version})
+}
+
+// handleInfo handles GET /api/v1/info with the build information
+func (s *Server) handleInfo(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+	sendJSON(ctx, fasthttp.StatusOK, version.Get())
 }
 
 // handleNotFound handles 404 responses
@@ -102,13 +507,20 @@ func (s *Server) loggingMiddleware(next fasthttp.RequestHandler) fasthttp.Reques
 		
 		// Log the request
 		duration := time.Since(start)
-		logger.Info("HTTP request", map[string]interface{}{
+		fields := map[string]interface{}{
 			"method":     string(ctx.Method()),
 			"path":       string(ctx.Path()),
+			"route":      routeFor(string(ctx.Path())),
 			"status":     ctx.Response.StatusCode(),
 			"duration":   duration.String(),
+			"latency_ms": float64(duration.Microseconds()) / 1000,
 			"user_agent": string(ctx.UserAgent()),
-			"remote_ip":  ctx.RemoteIP().String(),
-		})
+			"remote_ip":  clientIP(ctx),
+		}
+		if ctx.Response.StatusCode() >= fasthttp.StatusInternalServerError {
+			requestLog(ctx).Warn("HTTP request", fields)
+		} else {
+			requestLog(ctx).Info("HTTP request", fields)
+		}
 	}
 }
@@ -1,11 +1,14 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"testing"
 	"time"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
 	"github.com/valyala/fasthttp"
 )
 
@@ -71,7 +74,7 @@ func TestServerEndpoints(t *testing.T) {
 		t.Errorf("Version endpoint: expected status %d, got %d", fasthttp.StatusOK, statusCode)
 	}
 	
-	expectedVersion := `{"version":"v0.12.0"}`
+	expectedVersion := `{"version":"` + version.Version + `"}`
 	if string(body) != expectedVersion {
 		t.Errorf("Version endpoint: expected body %s, got %s", expectedVersion, string(body))
 	}
@@ -91,3 +94,45 @@ func TestServerEndpoints(t *testing.T) {
 		t.Errorf("Nonexistent endpoint: expected body %s, got %s", expectedNotFound, string(body))
 	}
 }
+
+func TestInfo(t *testing.T) {
+	srv := New(0)
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/info", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	var info version.Info
+	if err := json.Unmarshal(ctx.Response.Body(), &info); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if info != version.Get() {
+		t.Errorf("Expected %+v, got %+v", version.Get(), info)
+	}
+
+	if ctx := serve(srv, fasthttp.MethodPost, "/api/v1/info", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestDisableEnrichment(t *testing.T) {
+	cfg := config.DefaultConfig().Server
+	cfg.DisableEnrichment = true
+	srv := NewWithConfig(cfg)
+
+	for _, uri := range []string{
+		"/api/v1/deployments/shop/web/revisions",
+		"/api/v1/deployments/shop/web/events",
+		"/api/v1/deployments/shop/web/config",
+		"/api/v1/routes",
+		"/api/v1/namespaces",
+		"/api/v1/namespaces/shop/pods",
+		"/api/v1/nodes/node-1/pods",
+	} {
+		if ctx := serve(srv, fasthttp.MethodGet, uri, ""); ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+			t.Errorf("%s: expected 404 with enrichment disabled, got %d", uri, ctx.Response.StatusCode())
+		}
+	}
+	if ctx := serve(srv, fasthttp.MethodGet, "/api/v1/info", ""); ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Errorf("Expected other endpoints to be served, got %d", ctx.Response.StatusCode())
+	}
+}
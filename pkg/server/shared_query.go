@@ -0,0 +1,65 @@
+package server
+
+import (
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cache"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/metrics"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultSharedQueryTTL is how long the result of a shared query is reused
+// by default
+const defaultSharedQueryTTL = time.Second
+
+// sharedQuery protects an expensive query from stampedes: concurrent
+// identical queries wait for one computation, and its result answers later
+// ones for a short TTL. Results are shared between callers, which must not
+// modify them.
+type sharedQuery[T any] struct {
+	name    string
+	ttl     time.Duration
+	flights singleflight.Group
+	results *cache.ShardedCache[T]
+}
+
+// newSharedQuery creates a shared query reusing results for ttl (0 = 1s),
+// counted in metrics under name
+func newSharedQuery[T any](name string, ttl time.Duration) *sharedQuery[T] {
+	if ttl <= 0 {
+		ttl = defaultSharedQueryTTL
+	}
+	return &sharedQuery[T]{
+		name:    name,
+		ttl:     ttl,
+		results: cache.NewShardedCache[T](1, 0),
+	}
+}
+
+// do returns the result of the query identified by key, computed by compute
+// unless a recent or in-flight computation provides it
+func (q *sharedQuery[T]) do(key string, compute func() T) T {
+	if result, ok := q.results.Get(key); ok {
+		metrics.APISharedQueries.WithLabelValues(q.name, "memoized").Inc()
+		return result
+	}
+
+	computed := false
+	value, _, _ := q.flights.Do(key, func() (interface{}, error) {
+		// A flight that landed since the lookup stored its result
+		if result, ok := q.results.Get(key); ok {
+			return result, nil
+		}
+		computed = true
+		result := compute()
+		q.results.Set(key, result, q.ttl)
+		return result, nil
+	})
+
+	if computed {
+		metrics.APISharedQueries.WithLabelValues(q.name, "computed").Inc()
+	} else {
+		metrics.APISharedQueries.WithLabelValues(q.name, "shared").Inc()
+	}
+	return value.(T)
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSharedQuery(t *testing.T) {
+	query := newSharedQuery[int]("test", time.Hour)
+	var computations atomic.Int32
+	release := make(chan struct{})
+	compute := func() int {
+		computations.Add(1)
+		<-release
+		return 42
+	}
+
+	// Concurrent identical queries wait for one computation
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = query.do("all", compute)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, result := range results {
+		if result != 42 {
+			t.Fatalf("Expected 42 for every query, got %v", results)
+		}
+	}
+	if n := computations.Load(); n != 1 {
+		t.Errorf("Expected one computation, got %d", n)
+	}
+
+	// Later queries are answered from the result until it expires
+	if result := query.do("all", compute); result != 42 || computations.Load() != 1 {
+		t.Errorf("Expected the memoized result, got %d after %d computations", result, computations.Load())
+	}
+	query.do("other", compute)
+	if n := computations.Load(); n != 2 {
+		t.Errorf("Expected another key to be computed, got %d computations", n)
+	}
+
+	expiring := newSharedQuery[int]("test", time.Millisecond)
+	expiring.do("all", compute)
+	time.Sleep(5 * time.Millisecond)
+	expiring.do("all", compute)
+	if n := computations.Load(); n != 4 {
+		t.Errorf("Expected an expired result to be computed again, got %d computations", n)
+	}
+}
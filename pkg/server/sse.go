@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// sseKeepaliveInterval is how often a comment is sent to keep idle streams open
+const sseKeepaliveInterval = 15 * time.Second
+
+// handleWatchDeployments handles GET /api/v1/deployments/watch, streaming
+// deployment events as Server-Sent Events
+func (s *Server) handleWatchDeployments(ctx *fasthttp.RequestCtx) {
+	if !ctx.IsGet() {
+		sendError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed", fmt.Sprintf("Method %s is not supported", ctx.Method()))
+		return
+	}
+
+	filter, err := parseEventFilter(ctx.QueryArgs())
+	if err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+
+	sub := s.events.Subscribe(filter)
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetContentType("text/event-stream")
+	ctx.Response.Header.Set("Cache-Control", "no-cache")
+	ctx.Response.Header.Set("Connection", "keep-alive")
+	ctx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer s.events.Unsubscribe(sub)
+
+		fmt.Fprintf(w, "retry: %d\n: connected\n\n", (3 * time.Second).Milliseconds())
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		keepalive := time.NewTicker(sseKeepaliveInterval)
+		defer keepalive.Stop()
+
+		for {
+			select {
+			case event, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+				if err := writeSSEEvent(w, &event); err != nil {
+					serverLog().Debug("Event stream closed", map[string]interface{}{
+						"error": err.Error(),
+					})
+					return
+				}
+			case <-keepalive.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	})
+}
+
+// writeSSEEvent writes a single event in text/event-stream format and flushes it
+func writeSSEEvent(w *bufio.Writer, event *DeploymentEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return w.Flush()
+}
+
+// parseEventFilter builds an event filter from "namespace" and "labelSelector" query parameters
+func parseEventFilter(args *fasthttp.Args) (EventFilter, error) {
+	filter := EventFilter{
+		Namespace: string(args.Peek("namespace")),
+	}
+
+	if selector := string(args.Peek("labelSelector")); selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return filter, fmt.Errorf("invalid label selector: %w", err)
+		}
+		filter.Selector = parsed
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// staleMiddleware marks the responses of deployment endpoints with
+// X-Cache-Stale while the deployment informer serves a snapshot loaded on
+// start, until its cache has synced
+func (s *Server) staleMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if s.deploymentsStale() && showsDeployments(string(ctx.Path())) {
+			ctx.Response.Header.Set("X-Cache-Stale", "true")
+		}
+		next(ctx)
+	}
+}
+
+// deploymentsStale reports whether deployments are served from a snapshot
+func (s *Server) deploymentsStale() bool {
+	return s.deploymentHandler != nil && s.deploymentHandler.informer.Stale()
+}
+
+// showsDeployments reports whether a path serves deployments of the
+// deployment informer
+func showsDeployments(path string) bool {
+	return strings.HasPrefix(path, "/api/v1/deployments") ||
+		strings.HasPrefix(path, "/api/v1/namespaces/") && strings.HasSuffix(path, "/deployments")
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStaleDeploymentsFromSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployments.json")
+	web := newTestDeployment("shop", "web", 2, map[string]string{"app": "web"})
+	web.ResourceVersion = "1"
+	saved := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(web), "", time.Minute)
+	if err := saved.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := saved.SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	saved.Stop()
+
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(web), "", time.Minute)
+	if _, err := informer.LoadSnapshot(path, 0); err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	cfg := config.DefaultConfig().Server
+	cfg.ResponseCache = config.ResponseCacheConfig{Enabled: true}
+	srv := NewWithConfig(cfg)
+	srv.SetDeploymentInformer(informer)
+
+	// The snapshot is served, marked stale and not cached
+	ctx := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/web", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200 from the snapshot, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if got := string(ctx.Response.Header.Peek("X-Cache-Stale")); got != "true" {
+		t.Errorf("Expected X-Cache-Stale: true, got %q", got)
+	}
+	if srv.responseCache.Len() != 0 {
+		t.Error("Expected stale responses not to be cached")
+	}
+	if got := serve(srv, fasthttp.MethodGet, "/health", "").Response.Header.Peek("X-Cache-Stale"); len(got) != 0 {
+		t.Errorf("Expected only deployment responses to be marked, got %q", got)
+	}
+
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+	if got := serve(srv, fasthttp.MethodGet, "/api/v1/deployments/shop/web", "").Response.Header.Peek("X-Cache-Stale"); len(got) != 0 {
+		t.Errorf("Expected no mark once synced, got %q", got)
+	}
+}
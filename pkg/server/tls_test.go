@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/valyala/fasthttp"
+)
+
+// writeServerCertificate writes a self-signed certificate for 127.0.0.1 to
+// tls.crt and tls.key in dir, modified at modTime, and returns it
+func writeServerCertificate(t *testing.T, dir, cn string, modTime time.Time) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		"tls.key": pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	start := time.Now().Add(-time.Hour)
+	writeServerCertificate(t, dir, "first", start)
+
+	reloader, err := NewCertificateReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertificateReloader() error = %v", err)
+	}
+	servedCN := func() string {
+		cert, _ := reloader.GetCertificate(nil)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+
+	if reloaded, err := reloader.Reload(); reloaded || err != nil {
+		t.Errorf("Expected unchanged files not to be reloaded, got %v, %v", reloaded, err)
+	}
+
+	writeServerCertificate(t, dir, "rotated", start.Add(time.Minute))
+	if reloaded, err := reloader.Reload(); !reloaded || err != nil {
+		t.Fatalf("Expected the rotated certificate to be reloaded, got %v, %v", reloaded, err)
+	}
+	if cn := servedCN(); cn != "rotated" {
+		t.Errorf("Expected the rotated certificate to be served, got %s", cn)
+	}
+
+	// A certificate that doesn't match its key keeps the current one
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloader.Reload(); err == nil {
+		t.Error("Expected an error for an invalid key")
+	}
+	if cn := servedCN(); cn != "rotated" {
+		t.Errorf("Expected the previous certificate to be kept, got %s", cn)
+	}
+
+	if _, err := NewCertificateReloader(filepath.Join(dir, "missing.crt"), keyFile); err == nil {
+		t.Error("Expected an error for a missing certificate")
+	}
+}
+
+func TestServeTLSWithClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	serverCert := writeServerCertificate(t, dir, "k6s", time.Now())
+	ca, caKey := newCertificate(t, "k6s clients", true, nil, nil)
+	client, clientKey := newCertificate(t, "portal", false, ca, caKey)
+
+	cfg := config.DefaultConfig().Server
+	cfg.TLS = config.ServerTLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}
+	cfg.Auth.ClientCAFile = filepath.Join(dir, "ca.crt")
+	srv := NewWithConfig(cfg)
+	srv.clientCAs = x509.NewCertPool()
+	srv.clientCAs.AddCert(ca)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tlsConfig, err := srv.tlsConfig(ctx)
+	if err != nil {
+		t.Fatalf("tlsConfig() error = %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() { _ = fasthttp.Serve(tls.NewListener(ln, tlsConfig), srv.Handler()) }()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCert)
+	get := func(certs []tls.Certificate) int {
+		t.Helper()
+		httpClient := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs, MinVersion: tls.VersionTLS12},
+		}}
+		resp, err := httpClient.Get("https://" + ln.Addr().String() + "/api/v1/info")
+		if err != nil {
+			t.Fatalf("GET over TLS failed: %v", err)
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	withCert := []tls.Certificate{{Certificate: [][]byte{client.Raw}, PrivateKey: clientKey}}
+	if status := get(withCert); status != http.StatusOK {
+		t.Errorf("Expected a client certificate to authenticate, got %d", status)
+	}
+	if status := get(nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected a request without credentials to be unauthorized, got %d", status)
+	}
+}
+
+func TestTLSConfigForOtherAPIs(t *testing.T) {
+	dir := t.TempDir()
+	writeServerCertificate(t, dir, "k6s", time.Now())
+	ca, caKey := newCertificate(t, "k6s clients", true, nil, nil)
+	client, _ := newCertificate(t, "portal", false, ca, caKey)
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig().Server
+	cfg.TLS = config.ServerTLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}
+	cfg.Auth.ClientCAFile = filepath.Join(dir, "ca.crt")
+	srv := NewWithConfig(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tlsConfig, err := srv.TLSConfig(ctx)
+	if err != nil {
+		t.Fatalf("TLSConfig() error = %v", err)
+	}
+	if cert, err := tlsConfig.GetCertificate(nil); err != nil || cert == nil {
+		t.Fatalf("Expected the server certificate, got %v, %v", cert, err)
+	}
+	if tlsConfig.ClientCAs == nil || tlsConfig.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Error("Expected the configured client CAs to verify client certificates")
+	}
+
+	if identity, ok, err := srv.Authenticate("", []*x509.Certificate{client}); err != nil || !ok || identity.Name != "portal" {
+		t.Errorf("Expected the client certificate to authenticate as portal, got %v, %v, %v", identity, ok, err)
+	}
+}
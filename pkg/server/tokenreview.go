@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/security"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authenticationclient "k8s.io/client-go/kubernetes/typed/authentication/v1"
+)
+
+// defaultTokenReviewTTL is how long review results are cached when no TTL is configured
+const defaultTokenReviewTTL = time.Minute
+
+// defaultTokenReviewCacheSize is how many accepted tokens are cached when no
+// size is configured
+const defaultTokenReviewCacheSize = 1000
+
+// rejectedTokenCacheSize is how many rejected tokens are cached. Clients can
+// present any number of distinct invalid tokens, so only a few are kept, to
+// spare reviews of a client retrying the same one.
+const rejectedTokenCacheSize = 100
+
+// TokenReviewer validates bearer tokens with the Kubernetes TokenReview API,
+// caching the results so that a client's requests don't each cost a review
+type TokenReviewer struct {
+	reviews   authenticationclient.TokenReviewInterface
+	audiences []string
+	ttl       time.Duration
+
+	mu       sync.Mutex
+	cache    map[[sha256.Size]byte]tokenReview
+	rejected map[[sha256.Size]byte]tokenReview
+	size     int
+}
+
+// tokenReview is a cached review result
+type tokenReview struct {
+	user          security.Identity
+	authenticated bool
+	expires       time.Time
+}
+
+// NewTokenReviewer creates a reviewer of tokens issued for audiences (empty
+// = the API server's), caching results for ttl and at most size accepted
+// tokens
+func NewTokenReviewer(reviews authenticationclient.TokenReviewInterface, audiences []string, ttl time.Duration, size int) *TokenReviewer {
+	if ttl <= 0 {
+		ttl = defaultTokenReviewTTL
+	}
+	if size <= 0 {
+		size = defaultTokenReviewCacheSize
+	}
+	return &TokenReviewer{
+		reviews:   reviews,
+		audiences: audiences,
+		ttl:       ttl,
+		cache:     make(map[[sha256.Size]byte]tokenReview),
+		rejected:  make(map[[sha256.Size]byte]tokenReview),
+		size:      size,
+	}
+}
+
+// Review returns the user a token authenticates, with its groups, and false
+// when the API server rejects it. Errors reaching the API server are not cached.
+func (r *TokenReviewer) Review(ctx context.Context, token string) (security.Identity, bool, error) {
+	// Tokens are only kept as hashes
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+
+	r.mu.Lock()
+	cached, found := r.cache[key]
+	if !found {
+		cached, found = r.rejected[key]
+	}
+	r.mu.Unlock()
+	if found && now.Before(cached.expires) {
+		return cached.user, cached.authenticated, nil
+	}
+
+	review, err := r.reviews.Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: r.audiences},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return security.Identity{}, false, err
+	}
+	result := tokenReview{
+		user: security.Identity{
+			Name:   review.Status.User.Username,
+			Groups: review.Status.User.Groups,
+		},
+		authenticated: review.Status.Authenticated,
+		expires:       now.Add(r.ttl),
+	}
+
+	r.mu.Lock()
+	if result.authenticated {
+		cacheReview(r.cache, r.size, key, result, now)
+	} else {
+		cacheReview(r.rejected, rejectedTokenCacheSize, key, result, now)
+	}
+	r.mu.Unlock()
+
+	return result.user, result.authenticated, nil
+}
+
+// cacheReview adds a review result to cache, holding at most size results.
+// A full cache first drops expired results, and then an arbitrary one.
+func cacheReview(cache map[[sha256.Size]byte]tokenReview, size int, key [sha256.Size]byte, result tokenReview, now time.Time) {
+	if _, found := cache[key]; !found && len(cache) >= size {
+		for k, entry := range cache {
+			if now.After(entry.expires) {
+				delete(cache, k)
+			}
+		}
+		for k := range cache {
+			if len(cache) < size {
+				break
+			}
+			delete(cache, k)
+		}
+	}
+	cache[key] = result
+}
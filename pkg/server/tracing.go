@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/logger"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/telemetry"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// requestIDHeader carries the ID of a request, from the client or
+	// generated, and is echoed in the response
+	requestIDHeader = "X-Request-ID"
+
+	// requestIDKey is the request user value holding the request ID
+	requestIDKey = "request_id"
+
+	// traceIDKey is the request user value holding the ID of the trace of
+	// a request's span
+	traceIDKey = "trace_id"
+
+	// maxRequestIDLength bounds request IDs accepted from clients
+	maxRequestIDLength = 128
+
+	// tracerName is the instrumentation scope of API request spans
+	tracerName = "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/server"
+)
+
+// requestIDMiddleware assigns every request an ID: the client's X-Request-ID
+// when it is a reasonable token, or else a generated one. The ID is returned
+// in the response header and added to the request's logs.
+func (s *Server) requestIDMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		id := string(ctx.Request.Header.Peek(requestIDHeader))
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		ctx.SetUserValue(requestIDKey, id)
+		ctx.Response.Header.Set(requestIDHeader, id)
+		next(ctx)
+	}
+}
+
+// validRequestID reports whether a client request ID can be logged as is
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random request ID
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// requestID returns the ID of a request, or "" before it is assigned
+func requestID(ctx *fasthttp.RequestCtx) string {
+	id, _ := ctx.UserValue(requestIDKey).(string)
+	return id
+}
+
+// requestLog returns a logger adding the ID of a request, and of its trace
+// when it is traced, to its entries
+func requestLog(ctx *fasthttp.RequestCtx) *logger.Logger {
+	fields := make(map[string]interface{}, 2)
+	if id := requestID(ctx); id != "" {
+		fields[requestIDKey] = id
+	}
+	if id, _ := ctx.UserValue(traceIDKey).(string); id != "" {
+		fields[traceIDKey] = id
+	}
+	if len(fields) == 0 {
+		return serverLog()
+	}
+	return serverLog().WithFields(fields)
+}
+
+// serverLog returns the logger of the HTTP API
+func serverLog() *logger.Logger {
+	return logger.ForComponent(logger.ComponentServer)
+}
+
+// tracingMiddleware records a server span for every request when telemetry
+// is enabled, continuing the caller's trace when it sends a traceparent
+// header. The trace ID is added to the request's logs.
+func (s *Server) tracingMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if !telemetry.Enabled() {
+		return next
+	}
+	tracer := otel.Tracer(tracerName)
+	return func(ctx *fasthttp.RequestCtx) {
+		method := string(ctx.Method())
+		route := routeFor(string(ctx.Path()))
+		parent := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier{&ctx.Request.Header})
+		_, span := tracer.Start(parent, method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", string(ctx.Path())),
+				attribute.String("client.address", clientIP(ctx)),
+				attribute.String("k6s.request_id", requestID(ctx)),
+			),
+		)
+		defer span.End()
+		if id := telemetry.TraceID(span); id != "" {
+			ctx.SetUserValue(traceIDKey, id)
+		}
+
+		next(ctx)
+
+		status := ctx.Response.StatusCode()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= fasthttp.StatusInternalServerError {
+			span.SetStatus(codes.Error, fasthttp.StatusMessage(status))
+		}
+	}
+}
+
+// headerCarrier adapts fasthttp request headers to the OpenTelemetry
+// propagation carrier interface
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (c headerCarrier) Get(key string) string { return string(c.header.Peek(key)) }
+func (c headerCarrier) Set(key, value string) { c.header.Set(key, value) }
+func (c headerCarrier) Keys() []string {
+	var keys []string
+	c.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
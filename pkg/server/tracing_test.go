@@ -0,0 +1,86 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/telemetry"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestID(t *testing.T) {
+	srv := New(0)
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"client ID", "checkout-42", "checkout-42"},
+		{"generated", "", ""},
+		{"invalid client ID", "two words", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/health")
+			if tt.header != "" {
+				ctx.Request.Header.Set(requestIDHeader, tt.header)
+			}
+			srv.Handler()(ctx)
+
+			id := string(ctx.Response.Header.Peek(requestIDHeader))
+			if tt.want != "" && id != tt.want {
+				t.Errorf("Expected request ID %q, got %q", tt.want, id)
+			}
+			if tt.want == "" && (len(id) != 32 || id == tt.header) {
+				t.Errorf("Expected a generated request ID, got %q", id)
+			}
+			if requestID(ctx) != id {
+				t.Errorf("Expected the request ID %q to be recorded, got %q", id, requestID(ctx))
+			}
+		})
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	restore := telemetry.Install(provider)
+	defer restore()
+
+	srv := NewWithConfig(config.DefaultConfig().Server)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/v1/deployments/prod/web")
+	ctx.Request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	srv.Handler()(ctx)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /api/v1/deployments/{namespace}/{name}" {
+		t.Errorf("Expected the span to be named by route, got %q", span.Name())
+	}
+	if span.Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the caller's trace to continue, got parent %v", span.Parent())
+	}
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	if attributes["http.response.status_code"].AsInt64() != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected the response status, got %v", attributes["http.response.status_code"])
+	}
+	if attributes["k6s.request_id"].AsString() != requestID(ctx) {
+		t.Errorf("Expected the request ID %q, got %v", requestID(ctx), attributes["k6s.request_id"])
+	}
+	if id := ctx.UserValue(traceIDKey); id != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the trace ID to be added to the request's logs, got %v", id)
+	}
+}
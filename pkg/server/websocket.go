@@ -0,0 +1,209 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/valyala/fasthttp"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// wsWriteWait is the time allowed to write a message to the client
+	wsWriteWait = 10 * time.Second
+
+	// wsPongWait is the time allowed to read the next pong from the client
+	wsPongWait = 60 * time.Second
+
+	// wsPingInterval must be less than wsPongWait
+	wsPingInterval = (wsPongWait * 9) / 10
+
+	// wsMaxMessageSize limits the size of client messages
+	wsMaxMessageSize = 4096
+)
+
+// WebSocket message types sent to clients
+const (
+	WSMessageEvent      = "event"
+	WSMessageSubscribed = "subscribed"
+	WSMessageError      = "error"
+)
+
+// WSClientMessage is a message sent by a WebSocket client.
+// The only supported action is "subscribe", which replaces the client's filter.
+type WSClientMessage struct {
+	Action        string `json:"action"`
+	Namespace     string `json:"namespace,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// WSServerMessage is a message sent to a WebSocket client
+type WSServerMessage struct {
+	Type   string           `json:"type"`
+	Event  *DeploymentEvent `json:"event,omitempty"`
+	Filter *WSFilter        `json:"filter,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// WSFilter describes the filter active for a WebSocket client
+type WSFilter struct {
+	Namespace     string `json:"namespace"`
+	LabelSelector string `json:"labelSelector"`
+}
+
+var wsUpgrader = websocket.FastHTTPUpgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// wsClient is a single WebSocket connection subscribed to the event hub
+type wsClient struct {
+	conn    *websocket.Conn
+	hub     *EventHub
+	sub     *Subscriber
+	replies chan WSServerMessage
+	done    chan struct{}
+}
+
+// handleDeploymentsWebSocket handles GET /api/v1/deployments/ws, streaming
+// deployment events over a WebSocket with per-client filters
+func (s *Server) handleDeploymentsWebSocket(ctx *fasthttp.RequestCtx) {
+	filter, err := parseEventFilter(ctx.QueryArgs())
+	if err != nil {
+		sendError(ctx, fasthttp.StatusBadRequest, "Bad request", err.Error())
+		return
+	}
+	initial := WSFilter{
+		Namespace:     string(ctx.QueryArgs().Peek("namespace")),
+		LabelSelector: string(ctx.QueryArgs().Peek("labelSelector")),
+	}
+
+	// Browser applications on allowed CORS origins may connect as well
+	upgrader := wsUpgrader
+	upgrader.CheckOrigin = s.checkWebSocketOrigin
+	err = upgrader.Upgrade(ctx, func(conn *websocket.Conn) {
+		client := &wsClient{
+			conn:    conn,
+			hub:     s.events,
+			sub:     s.events.Subscribe(filter),
+			replies: make(chan WSServerMessage, 8),
+			done:    make(chan struct{}),
+		}
+		client.replies <- WSServerMessage{Type: WSMessageSubscribed, Filter: &initial}
+		client.run()
+	})
+	if err != nil {
+		serverLog().Debug("WebSocket upgrade failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// run serves the client until either side closes the connection
+func (c *wsClient) run() {
+	serverLog().Debug("WebSocket client connected", map[string]interface{}{
+		"remote": c.conn.RemoteAddr().String(),
+	})
+
+	go c.readLoop()
+	c.writeLoop()
+
+	c.hub.Unsubscribe(c.sub)
+	_ = c.conn.Close()
+	<-c.done
+
+	serverLog().Debug("WebSocket client disconnected", map[string]interface{}{
+		"remote":  c.conn.RemoteAddr().String(),
+		"dropped": c.sub.Dropped(),
+	})
+}
+
+// readLoop processes client messages and pongs; it ends the subscription
+// when the connection is closed by the client
+func (c *wsClient) readLoop() {
+	defer close(c.done)
+	defer c.hub.Unsubscribe(c.sub)
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		reply := c.handleMessage(data)
+		select {
+		case c.replies <- reply:
+		default:
+			// The client isn't reading replies; drop rather than block reads
+		}
+	}
+}
+
+// handleMessage applies a client message and returns the reply to send
+func (c *wsClient) handleMessage(data []byte) WSServerMessage {
+	var msg WSClientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return WSServerMessage{Type: WSMessageError, Error: "invalid message: " + err.Error()}
+	}
+
+	switch msg.Action {
+	case "subscribe":
+		filter := EventFilter{Namespace: msg.Namespace}
+		if msg.LabelSelector != "" {
+			selector, err := labels.Parse(msg.LabelSelector)
+			if err != nil {
+				return WSServerMessage{Type: WSMessageError, Error: fmt.Sprintf("invalid label selector: %v", err)}
+			}
+			filter.Selector = selector
+		}
+		c.hub.SetFilter(c.sub, filter)
+		return WSServerMessage{
+			Type:   WSMessageSubscribed,
+			Filter: &WSFilter{Namespace: msg.Namespace, LabelSelector: msg.LabelSelector},
+		}
+	default:
+		return WSServerMessage{Type: WSMessageError, Error: fmt.Sprintf("unknown action '%s'", msg.Action)}
+	}
+}
+
+// writeLoop is the only writer on the connection; it sends events, replies and pings
+func (c *wsClient) writeLoop() {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case event, ok := <-c.sub.Events():
+			if !ok {
+				_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+				_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := c.write(WSServerMessage{Type: WSMessageEvent, Event: &event}); err != nil {
+				return
+			}
+		case reply := <-c.replies:
+			if err := c.write(reply); err != nil {
+				return
+			}
+		case <-ping.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write sends a JSON message with a write deadline
+func (c *wsClient) write(msg WSServerMessage) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return c.conn.WriteJSON(msg)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeploymentsWebSocket(t *testing.T) {
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(), "", time.Minute)
+	srv := New(0)
+	srv.events = NewEventHub(informer, false)
+	defer srv.events.Close()
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go func() {
+		_ = fasthttp.Serve(ln, srv.Handler())
+	}()
+
+	dialer := websocket.Dialer{
+		NetDial: func(network, addr string) (net.Conn, error) {
+			return ln.Dial()
+		},
+	}
+	conn, _, err := dialer.Dial("ws://localhost/api/v1/deployments/ws?namespace=dev", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var msg WSServerMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if msg.Type != WSMessageSubscribed || msg.Filter == nil || msg.Filter.Namespace != "dev" {
+		t.Fatalf("Expected initial subscription to dev, got %+v", msg)
+	}
+
+	if err := conn.WriteJSON(WSClientMessage{Action: "subscribe", Namespace: "prod", LabelSelector: "app=web"}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if msg.Type != WSMessageSubscribed || msg.Filter.Namespace != "prod" {
+		t.Fatalf("Expected subscription to prod, got %+v", msg)
+	}
+
+	srv.events.OnAdd(newTestDeployment("dev", "api", 1, map[string]string{"app": "api"}))
+	srv.events.OnAdd(newTestDeployment("prod", "web", 2, map[string]string{"app": "web"}))
+
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if msg.Type != WSMessageEvent || msg.Event == nil || msg.Event.Deployment.Name != "web" {
+		t.Fatalf("Expected event for prod/web, got %+v", msg)
+	}
+
+	if err := conn.WriteJSON(WSClientMessage{Action: "unknown"}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if msg.Type != WSMessageError {
+		t.Errorf("Expected error reply for unknown action, got %+v", msg)
+	}
+}
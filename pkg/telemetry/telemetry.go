@@ -0,0 +1,143 @@
+// Package telemetry sets up OpenTelemetry tracing exported with OTLP over
+// HTTP, and starts the spans of reconciliations and informer event handlers
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is the service spans of k6s are reported under by default
+const ServiceName = "k6s"
+
+// tracerName is the instrumentation scope of reconciliation and informer
+// spans
+const tracerName = "github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/telemetry"
+
+// enabled is set while a tracer provider is installed
+var enabled atomic.Bool
+
+// Setup installs the global tracer provider exporting to the configured
+// endpoint and the W3C trace context propagator, and returns the function
+// flushing and stopping the export
+func Setup(ctx context.Context, cfg config.TelemetryConfig) (func(context.Context) error, error) {
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio == 0 {
+		ratio = 1
+	}
+	service := cfg.ServiceName
+	if service == "" {
+		service = ServiceName
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", service),
+			attribute.String("service.version", version.Version),
+		)),
+	)
+	restore := Install(provider)
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		restore()
+		return err
+	}, nil
+}
+
+// Install makes provider the global tracer provider, with the W3C trace
+// context propagator, and enables the spans of k6s. It returns the function
+// restoring the previous provider.
+func Install(provider trace.TracerProvider) func() {
+	previous, previousPropagator, wasEnabled := otel.GetTracerProvider(), otel.GetTextMapPropagator(), enabled.Load()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	enabled.Store(true)
+	return func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+		enabled.Store(wasEnabled)
+	}
+}
+
+// Enabled reports whether spans are recorded
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// noopSpan is returned while spans aren't recorded
+var noopSpan = trace.SpanFromContext(context.Background())
+
+// StartReconcile starts the span of a reconciliation of the object
+// namespace/name by a reconciler
+func StartReconcile(ctx context.Context, reconciler, cluster, namespace, name string) (context.Context, trace.Span) {
+	if !Enabled() {
+		return ctx, noopSpan
+	}
+	return otel.Tracer(tracerName).Start(ctx, "reconcile "+reconciler,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("k6s.controller", reconciler),
+			attribute.String("k6s.cluster", cluster),
+			attribute.String("k8s.namespace.name", namespace),
+			attribute.String("k6s.object.name", name),
+		),
+	)
+}
+
+// StartInformerEvent starts the span of the handlers of an informer event,
+// such as an added deployment
+func StartInformerEvent(resource, event, cluster, namespace, name string) trace.Span {
+	if !Enabled() {
+		return noopSpan
+	}
+	_, span := otel.Tracer(tracerName).Start(context.Background(), "informer "+resource+" "+event,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("k6s.informer.event", event),
+			attribute.String("k6s.cluster", cluster),
+			attribute.String("k8s.namespace.name", namespace),
+			attribute.String("k6s.object.name", name),
+		),
+	)
+	return span
+}
+
+// End ends a span, marking it failed with err
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID returns the ID of the trace of a recorded span, "" otherwise, to
+// correlate logs with traces
+func TraceID(span trace.Span) string {
+	if sc := span.SpanContext(); sc.IsValid() && span.IsRecording() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
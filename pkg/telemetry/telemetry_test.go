@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpans(t *testing.T) {
+	// Without a provider installed, spans are not recorded
+	if _, span := StartReconcile(context.Background(), "deployment", "local", "shop", "web"); span.IsRecording() || TraceID(span) != "" {
+		t.Error("Expected no span to be recorded while telemetry is disabled")
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	restore := Install(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	if !Enabled() {
+		t.Fatal("Expected telemetry to be enabled once installed")
+	}
+
+	ctx, reconcile := StartReconcile(context.Background(), "deployment", "local", "shop", "web")
+	if TraceID(reconcile) == "" {
+		t.Error("Expected the reconcile span to have a trace ID")
+	}
+	End(reconcile, errors.New("conflict"))
+	End(StartInformerEvent("deployment", "update", "local", "shop", "web"), nil)
+	if ctx == context.Background() {
+		t.Error("Expected the reconcile span to be added to the context")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected two spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "reconcile deployment" || spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected a failed reconcile span, got %q with status %v", spans[0].Name(), spans[0].Status())
+	}
+	if spans[1].Name() != "informer deployment update" || spans[1].Status().Code == codes.Error {
+		t.Errorf("Expected an informer span, got %q with status %v", spans[1].Name(), spans[1].Status())
+	}
+
+	restore()
+	if Enabled() {
+		t.Error("Expected telemetry to be disabled once restored")
+	}
+}
@@ -6,13 +6,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/cluster"
 	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/config"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -176,3 +179,34 @@ func AssertNever(t *testing.T, condition func() bool, timeout time.Duration, mes
 		time.Sleep(10 * time.Millisecond)
 	}
 }
+
+// FakeCluster is a cluster client backed by a fake clientset
+type FakeCluster struct {
+	Name      string
+	Primary   bool
+	Clientset kubernetes.Interface
+}
+
+func (c FakeCluster) GetName() string                                    { return c.Name }
+func (c FakeCluster) GetRestConfig() (*rest.Config, error)               { return &rest.Config{}, nil }
+func (c FakeCluster) GetKubernetesClient() (kubernetes.Interface, error) { return c.Clientset, nil }
+func (c FakeCluster) IsEnabled() bool                                    { return true }
+func (c FakeCluster) IsPrimary() bool                                    { return c.Primary }
+func (c FakeCluster) TestConnection(ctx context.Context) error           { return nil }
+
+// NewFakeCluster returns a cluster client serving objects from a fake clientset
+func NewFakeCluster(name string, objects ...runtime.Object) FakeCluster {
+	return FakeCluster{Name: name, Clientset: fake.NewSimpleClientset(objects...)}
+}
+
+// FakeRegistry serves fake clusters by name
+type FakeRegistry map[string]cluster.ClusterClient
+
+func (r FakeRegistry) GetEnabledClusters() map[string]cluster.ClusterClient { return r }
+func (r FakeRegistry) GetCluster(name string) (cluster.ClusterClient, bool) {
+	c, ok := r[name]
+	return c, ok
+}
+func (r FakeRegistry) AddCluster(name string, c *cluster.ClusterConfig) error { return nil }
+func (r FakeRegistry) RemoveCluster(name string) error                        { return nil }
+func (r FakeRegistry) ListClusters() []string                                 { return nil }
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxChanges is how many changes of each deployment are kept for the details
+// pane
+const maxChanges = 20
+
+// Change is a change of a deployment found by the change analyzer
+type Change struct {
+	Time time.Time
+	kubernetes.DeploymentChange
+}
+
+// Source provides the objects shown by the UI
+type Source interface {
+	// Deployments returns the deployments of every namespace
+	Deployments() []*appsv1.Deployment
+
+	// Pods returns the pods of every namespace, and false until they have
+	// been listed
+	Pods() ([]*corev1.Pod, bool)
+
+	// Changes returns the changes of a deployment seen since the UI started,
+	// oldest first
+	Changes(namespace, name string) []Change
+}
+
+// InformerSource serves deployments and pods from informer caches, and
+// records the changes the change analyzer finds in deployment updates
+type InformerSource struct {
+	deployments *kubernetes.DeploymentInformer
+	pods        *kubernetes.PodInformer
+	analyzer    *kubernetes.DeploymentChangeAnalyzer
+
+	mu      sync.Mutex
+	changes map[string][]Change
+}
+
+// NewInformerSource creates a source reading the caches of deployments and
+// pods, which may still be syncing or nil. It replaces the event handlers of
+// deployments, which must not be started yet, so that no events are logged
+// over the UI.
+func NewInformerSource(deployments *kubernetes.DeploymentInformer, pods *kubernetes.PodInformer) *InformerSource {
+	s := &InformerSource{
+		deployments: deployments,
+		pods:        pods,
+		analyzer:    kubernetes.NewDeploymentChangeAnalyzer(deployments),
+		changes:     make(map[string][]Change),
+	}
+	deployments.ReplaceEventHandlers(s)
+	return s
+}
+
+// Deployments returns the cached deployments of every namespace
+func (s *InformerSource) Deployments() []*appsv1.Deployment {
+	deployments, err := s.deployments.ListDeployments()
+	if err != nil {
+		return nil
+	}
+	return deployments
+}
+
+// Pods returns the cached pods of every namespace once the cache has synced
+func (s *InformerSource) Pods() ([]*corev1.Pod, bool) {
+	if s.pods == nil || !s.pods.HasSynced() {
+		return nil, false
+	}
+	return s.pods.ListAllPods(), true
+}
+
+// Changes returns the recorded changes of a deployment
+func (s *InformerSource) Changes(namespace, name string) []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Change(nil), s.changes[namespace+"/"+name]...)
+}
+
+func (s *InformerSource) OnAdd(obj *appsv1.Deployment) {}
+
+func (s *InformerSource) OnUpdate(oldObj, newObj *appsv1.Deployment) {
+	changes := s.analyzer.AnalyzeUpdate(oldObj, newObj)
+	if len(changes) == 0 {
+		return
+	}
+
+	now := time.Now()
+	key := newObj.Namespace + "/" + newObj.Name
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, change := range changes {
+		s.changes[key] = append(s.changes[key], Change{Time: now, DeploymentChange: change})
+	}
+	if n := len(s.changes[key]); n > maxChanges {
+		s.changes[key] = s.changes[key][n-maxChanges:]
+	}
+}
+
+func (s *InformerSource) OnDelete(obj *appsv1.Deployment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.changes, obj.Namespace+"/"+obj.Name)
+}
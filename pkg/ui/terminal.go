@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// refreshInterval is how often the screen is redrawn from the caches without
+// a key press
+const refreshInterval = time.Second
+
+// Terminal control sequences
+const (
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// escapeKeys are the escape sequences of the keys read
+var escapeKeys = map[string]string{
+	"\x1b[A":  keyUp,
+	"\x1bOA":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1bOB":  keyDown,
+	"\x1b[H":  keyHome,
+	"\x1b[1~": keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1b[4~": keyEnd,
+}
+
+// Run shows the UI on the terminal of in and out, starting in namespace ("" =
+// all namespaces), until the user quits or ctx is done
+func Run(ctx context.Context, src Source, namespace string, in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("k6s ui needs an interactive terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to switch the terminal to raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	fmt.Fprint(out, enterAltScreen+hideCursor)
+	defer fmt.Fprint(out, showCursor+exitAltScreen)
+
+	keys := make(chan string)
+	go readKeys(in, keys)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	m := newModel(src, namespace)
+	for {
+		if width, height, err := term.GetSize(fd); err == nil {
+			m.width, m.height = width, height
+		}
+		// Raw mode doesn't return the carriage on line feeds
+		fmt.Fprint(out, clearScreen+strings.ReplaceAll(m.render(), "\n", "\r\n"))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			m.handleKey(key)
+			if m.quit {
+				return nil
+			}
+		}
+	}
+}
+
+// readKeys sends the keys read from in to keys, and closes keys once in is
+// closed
+func readKeys(in io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		for _, key := range parseKeys(buf[:n]) {
+			keys <- key
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseKeys splits input read from a raw terminal into keys: escape
+// sequences of known keys, named control keys and printable characters.
+// Unknown escape sequences are dropped.
+func parseKeys(input []byte) []string {
+	var keys []string
+	s := string(input)
+	for len(s) > 0 {
+		if s[0] == '\x1b' {
+			if len(s) == 1 {
+				keys = append(keys, keyEsc)
+				break
+			}
+			matched := false
+			for sequence, key := range escapeKeys {
+				if strings.HasPrefix(s, sequence) {
+					keys = append(keys, key)
+					s = s[len(sequence):]
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				// Drop the rest of the input, which is part of the sequence
+				break
+			}
+			continue
+		}
+
+		switch s[0] {
+		case '\r', '\n':
+			keys = append(keys, keyEnter)
+		case '\t':
+			keys = append(keys, keyTab)
+		case 0x03:
+			keys = append(keys, keyCtrlC)
+		default:
+			r := []rune(s)[0]
+			keys = append(keys, string(r))
+			s = s[len(string(r)):]
+			continue
+		}
+		s = s[1:]
+	}
+	return keys
+}
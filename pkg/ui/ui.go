@@ -0,0 +1,377 @@
+// Package ui implements "k6s ui", a terminal UI browsing the deployments and
+// pods of informer caches
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// view is the kind of objects listed
+type view int
+
+const (
+	viewDeployments view = iota
+	viewPods
+)
+
+func (v view) String() string {
+	if v == viewPods {
+		return "Pods"
+	}
+	return "Deployments"
+}
+
+// Keys, as read by parseKeys
+const (
+	keyUp    = "up"
+	keyDown  = "down"
+	keyHome  = "home"
+	keyEnd   = "end"
+	keyTab   = "tab"
+	keyEnter = "enter"
+	keyEsc   = "esc"
+	keyCtrlC = "ctrl-c"
+)
+
+// helpLine lists the key bindings
+const helpLine = "↑/↓ select  tab deployments/pods  n/N namespace  a all namespaces  enter details  q quit"
+
+// selectedStyle and resetStyle highlight the selected row
+const (
+	selectedStyle = "\x1b[7m"
+	resetStyle    = "\x1b[0m"
+)
+
+// model is the state of the UI
+type model struct {
+	src Source
+
+	view      view
+	namespace string // "" for all namespaces
+	selected  int
+	details   bool
+	quit      bool
+
+	width, height int
+}
+
+func newModel(src Source, namespace string) *model {
+	return &model{src: src, namespace: namespace, width: 80, height: 24}
+}
+
+// namespaces returns the namespaces of the deployments and pods, sorted
+func (m *model) namespaces() []string {
+	seen := make(map[string]bool)
+	for _, dep := range m.src.Deployments() {
+		seen[dep.Namespace] = true
+	}
+	pods, _ := m.src.Pods()
+	for _, pod := range pods {
+		seen[pod.Namespace] = true
+	}
+	if m.namespace != "" {
+		seen[m.namespace] = true
+	}
+
+	namespaces := make([]string, 0, len(seen))
+	for namespace := range seen {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// deployments returns the deployments of the namespace shown
+func (m *model) deployments() []*appsv1.Deployment {
+	var deployments []*appsv1.Deployment
+	for _, dep := range m.src.Deployments() {
+		if m.namespace == "" || dep.Namespace == m.namespace {
+			deployments = append(deployments, dep)
+		}
+	}
+	sort.Slice(deployments, func(i, j int) bool {
+		if deployments[i].Namespace != deployments[j].Namespace {
+			return deployments[i].Namespace < deployments[j].Namespace
+		}
+		return deployments[i].Name < deployments[j].Name
+	})
+	return deployments
+}
+
+// pods returns the pods of the namespace shown, and false until pods have
+// been listed
+func (m *model) pods() ([]*corev1.Pod, bool) {
+	all, ok := m.src.Pods()
+	var pods []*corev1.Pod
+	for _, pod := range all {
+		if m.namespace == "" || pod.Namespace == m.namespace {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, ok
+}
+
+// handleKey updates the model for a key press
+func (m *model) handleKey(key string) {
+	switch key {
+	case "q", keyCtrlC:
+		m.quit = true
+	case keyUp, "k":
+		m.selected--
+	case keyDown, "j":
+		m.selected++
+	case keyHome, "g":
+		m.selected = 0
+	case keyEnd, "G":
+		m.selected = 1<<31 - 1
+	case keyTab:
+		if m.view == viewDeployments {
+			m.view = viewPods
+		} else {
+			m.view = viewDeployments
+		}
+		m.selected = 0
+	case "d":
+		m.view, m.selected = viewDeployments, 0
+	case "p":
+		m.view, m.selected = viewPods, 0
+	case "n", "N":
+		m.switchNamespace(key == "n")
+	case "a":
+		m.namespace, m.selected = "", 0
+	case keyEnter:
+		m.details = !m.details
+	case keyEsc:
+		m.details = false
+	}
+}
+
+// switchNamespace shows the next or previous namespace, cycling through all
+// namespaces
+func (m *model) switchNamespace(next bool) {
+	choices := append([]string{""}, m.namespaces()...)
+	current := 0
+	for i, namespace := range choices {
+		if namespace == m.namespace {
+			current = i
+		}
+	}
+	if next {
+		current = (current + 1) % len(choices)
+	} else {
+		current = (current + len(choices) - 1) % len(choices)
+	}
+	m.namespace, m.selected = choices[current], 0
+}
+
+// render returns the screen for the model, lines separated by "\n"
+func (m *model) render() string {
+	namespace := m.namespace
+	if namespace == "" {
+		namespace = "all"
+	}
+
+	var header []string
+	var rows [][]string
+	var details []string
+	podsListed := true
+	switch m.view {
+	case viewDeployments:
+		header = []string{"NAME", "READY", "UP-TO-DATE", "AVAILABLE", "AGE"}
+		deployments := m.deployments()
+		m.clampSelection(len(deployments))
+		for _, dep := range deployments {
+			rows = append(rows, []string{
+				dep.Name,
+				fmt.Sprintf("%d/%d", dep.Status.ReadyReplicas, dep.Status.Replicas),
+				fmt.Sprintf("%d", dep.Status.UpdatedReplicas),
+				fmt.Sprintf("%d", dep.Status.AvailableReplicas),
+				kubernetes.FormatAge(dep.CreationTimestamp.Time),
+			})
+		}
+		if m.details && len(deployments) > 0 {
+			details = m.deploymentDetails(deployments[m.selected])
+		}
+		if m.namespace == "" {
+			header = append([]string{"NAMESPACE"}, header...)
+			for i, dep := range deployments {
+				rows[i] = append([]string{dep.Namespace}, rows[i]...)
+			}
+		}
+	case viewPods:
+		header = []string{"NAME", "READY", "STATUS", "RESTARTS", "NODE", "AGE"}
+		var pods []*corev1.Pod
+		pods, podsListed = m.pods()
+		m.clampSelection(len(pods))
+		for _, pod := range pods {
+			description := kubernetes.DescribePod(pod)
+			rows = append(rows, []string{
+				description.Name,
+				description.Ready,
+				description.Phase,
+				fmt.Sprintf("%d", description.Restarts),
+				description.Node,
+				kubernetes.FormatAge(pod.CreationTimestamp.Time),
+			})
+		}
+		if m.details && len(pods) > 0 {
+			details = podDetails(pods[m.selected])
+		}
+		if m.namespace == "" {
+			header = append([]string{"NAMESPACE"}, header...)
+			for i, pod := range pods {
+				rows[i] = append([]string{pod.Namespace}, rows[i]...)
+			}
+		}
+	}
+
+	lines := []string{
+		fmt.Sprintf("k6s ui  %s (%d)  namespace: %s", m.view, len(rows), namespace),
+		helpLine,
+		"",
+	}
+
+	// The details pane takes up to half of the screen below the table
+	if limit := m.height / 2; len(details) > limit {
+		details = details[:limit]
+	}
+	tableHeight := m.height - len(lines) - 1
+	if len(details) > 0 {
+		tableHeight -= len(details) + 1
+	}
+
+	table := formatTable(header, rows)
+	lines = append(lines, table[0])
+	switch {
+	case !podsListed:
+		lines = append(lines, "Waiting for the pod cache to sync...")
+	case len(rows) == 0:
+		lines = append(lines, "No resources found.")
+	}
+
+	// Scroll the rows to keep the selection visible
+	first := 0
+	if tableHeight > 0 && m.selected >= tableHeight {
+		first = m.selected - tableHeight + 1
+	}
+	for i := first; i < len(rows) && i-first < tableHeight; i++ {
+		line := truncate(table[i+1], m.width)
+		if i == m.selected {
+			line = selectedStyle + line + resetStyle
+		}
+		lines = append(lines, line)
+	}
+
+	if len(details) > 0 {
+		lines = append(lines, strings.Repeat("─", m.width))
+		lines = append(lines, details...)
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, selectedStyle) {
+			lines[i] = truncate(line, m.width)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// clampSelection keeps the selection within n rows
+func (m *model) clampSelection(n int) {
+	if m.selected >= n {
+		m.selected = n - 1
+	}
+	if m.selected < 0 {
+		m.selected = 0
+	}
+}
+
+// deploymentDetails describes a deployment and the changes the change
+// analyzer found since the UI started
+func (m *model) deploymentDetails(dep *appsv1.Deployment) []string {
+	description := kubernetes.DescribeDeployment(dep, nil, nil, nil)
+	lines := []string{
+		fmt.Sprintf("Name:       %s/%s", description.Namespace, description.Name),
+		fmt.Sprintf("Replicas:   %d desired | %d updated | %d total | %d available | %d unavailable",
+			description.Replicas.Desired, description.Replicas.Updated, description.Replicas.Total,
+			description.Replicas.Available, description.Replicas.Unavailable),
+		fmt.Sprintf("Strategy:   %s", description.Strategy),
+	}
+	for _, container := range description.Containers {
+		lines = append(lines, fmt.Sprintf("Container:  %s (%s)", container.Name, container.Image))
+	}
+	for _, condition := range description.Conditions {
+		line := fmt.Sprintf("Condition:  %s=%s", condition.Type, condition.Status)
+		if condition.Reason != "" {
+			line += " (" + condition.Reason + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "Changes:")
+	changes := m.src.Changes(dep.Namespace, dep.Name)
+	if len(changes) == 0 {
+		lines = append(lines, "  none seen yet")
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		lines = append(lines, fmt.Sprintf("  %s  %s", changes[i].Time.Format("15:04:05"), changes[i].Description))
+	}
+	return lines
+}
+
+// podDetails describes a pod and its containers
+func podDetails(pod *corev1.Pod) []string {
+	description := kubernetes.DescribePod(pod)
+	lines := []string{
+		fmt.Sprintf("Name:       %s/%s", pod.Namespace, description.Name),
+		fmt.Sprintf("Status:     %s, %s ready, %d restarts", description.Phase, description.Ready, description.Restarts),
+		fmt.Sprintf("Node:       %s", description.Node),
+		fmt.Sprintf("IP:         %s", pod.Status.PodIP),
+	}
+	statuses := make(map[string]corev1.ContainerStatus)
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+	for _, container := range pod.Spec.Containers {
+		line := fmt.Sprintf("Container:  %s (%s)", container.Name, container.Image)
+		status := statuses[container.Name]
+		switch {
+		case status.State.Waiting != nil:
+			line += " waiting: " + status.State.Waiting.Reason
+		case status.State.Terminated != nil:
+			line += " terminated: " + status.State.Terminated.Reason
+		case status.State.Running != nil:
+			line += " running"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatTable aligns the header and rows in columns, returning the header
+// line first
+func formatTable(header []string, rows [][]string) []string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// truncate cuts line to width runes
+func truncate(line string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(line) <= width {
+		return line
+	}
+	return string([]rune(line)[:width])
+}
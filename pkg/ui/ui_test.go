@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeSource serves fixed objects
+type fakeSource struct {
+	deployments []*appsv1.Deployment
+	pods        []*corev1.Pod
+	podsListed  bool
+	changes     map[string][]Change
+}
+
+func (s *fakeSource) Deployments() []*appsv1.Deployment { return s.deployments }
+func (s *fakeSource) Pods() ([]*corev1.Pod, bool)       { return s.pods, s.podsListed }
+func (s *fakeSource) Changes(namespace, name string) []Change {
+	return s.changes[namespace+"/"+name]
+}
+
+func newTestDeployment(namespace, name string, replicas int32) *appsv1.Deployment {
+	dep := kubernetes.NewDeployment(namespace, name, "registry.example/"+name+":1.0", replicas)
+	dep.Status.Replicas = replicas
+	dep.Status.ReadyReplicas = replicas
+	return dep
+}
+
+func newTestPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "app", Image: "app:1"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestModel(t *testing.T) {
+	src := &fakeSource{
+		deployments: []*appsv1.Deployment{
+			newTestDeployment("shop", "web", 3),
+			newTestDeployment("payments", "api", 2),
+			newTestDeployment("shop", "cart", 1),
+		},
+		pods: []*corev1.Pod{newTestPod("shop", "web-1")},
+		changes: map[string][]Change{
+			"shop/web": {{
+				Time:             time.Date(2026, 1, 2, 12, 4, 5, 0, time.UTC),
+				DeploymentChange: kubernetes.DeploymentChange{Description: "Replicas changed from 1 to 3"},
+			}},
+		},
+	}
+	m := newModel(src, "")
+	m.width, m.height = 120, 30
+
+	screen := m.render()
+	if !strings.Contains(screen, "Deployments (3)  namespace: all") || !strings.Contains(screen, "NAMESPACE") {
+		t.Errorf("Expected the deployments of all namespaces, got:\n%s", screen)
+	}
+	if !strings.Contains(screen, selectedStyle+"payments") {
+		t.Errorf("Expected the first deployment to be selected, got:\n%s", screen)
+	}
+
+	// Namespaces cycle in order, back to all namespaces
+	var namespaces []string
+	for i := 0; i < 3; i++ {
+		m.handleKey("n")
+		namespaces = append(namespaces, m.namespace)
+	}
+	if want := []string{"payments", "shop", ""}; !reflect.DeepEqual(namespaces, want) {
+		t.Errorf("Expected namespaces %v, got %v", want, namespaces)
+	}
+	m.handleKey("N")
+	if m.namespace != "shop" {
+		t.Fatalf("Expected the previous namespace to be shop, got %q", m.namespace)
+	}
+
+	m.handleKey(keyDown)
+	m.handleKey(keyDown)
+	m.handleKey(keyEnter)
+	screen = m.render()
+	if strings.Contains(screen, "NAMESPACE") || strings.Contains(screen, "api") {
+		t.Errorf("Expected the deployments of shop only, got:\n%s", screen)
+	}
+	if m.selected != 1 {
+		t.Errorf("Expected the selection to stay on the last row, got %d", m.selected)
+	}
+	for _, want := range []string{"Name:       shop/web", "Container:  web (registry.example/web:1.0)", "12:04:05  Replicas changed from 1 to 3"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("Expected the details pane to show %q, got:\n%s", want, screen)
+		}
+	}
+
+	m.handleKey(keyTab)
+	screen = m.render()
+	if !strings.Contains(screen, "Waiting for the pod cache to sync") {
+		t.Errorf("Expected pods to wait for their cache, got:\n%s", screen)
+	}
+	src.podsListed = true
+	if screen = m.render(); !strings.Contains(screen, "Pods (1)") || !strings.Contains(screen, "Node:       node-1") {
+		t.Errorf("Expected the pods of shop with details, got:\n%s", screen)
+	}
+
+	m.handleKey("q")
+	if !m.quit {
+		t.Error("Expected q to quit")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("j\x1b[A\x1b[B\t\rnq\x03\x1b"))
+	want := []string{"j", keyUp, keyDown, keyTab, keyEnter, "n", "q", keyCtrlC, keyEsc}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseKeys() = %v, want %v", got, want)
+	}
+	if got := parseKeys([]byte("\x1b[5~")); len(got) != 0 {
+		t.Errorf("Expected unknown sequences to be dropped, got %v", got)
+	}
+}
+
+func TestInformerSource(t *testing.T) {
+	web := newTestDeployment("shop", "web", 1)
+	informer := kubernetes.NewDeploymentInformer(fake.NewSimpleClientset(web), "", time.Minute)
+	src := NewInformerSource(informer, nil)
+	if err := informer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer informer.Stop()
+
+	scaled := web.DeepCopy()
+	scaled.Spec.Replicas = func(n int32) *int32 { return &n }(3)
+	src.OnUpdate(web, scaled)
+	changes := src.Changes("shop", "web")
+	if len(changes) != 1 || changes[0].Field != "replicas" || changes[0].Time.IsZero() {
+		t.Errorf("Expected the replica change, got %+v", changes)
+	}
+	if deployments := src.Deployments(); len(deployments) != 1 {
+		t.Errorf("Expected the cached deployment, got %d", len(deployments))
+	}
+	if _, listed := src.Pods(); listed {
+		t.Error("Expected no pods without a pod informer")
+	}
+
+	src.OnDelete(scaled)
+	if changes := src.Changes("shop", "web"); len(changes) != 0 {
+		t.Errorf("Expected the changes of a deleted deployment to be dropped, got %+v", changes)
+	}
+}
@@ -0,0 +1,60 @@
+// Package version holds the build information of k6s, injected at build time:
+//
+//	go build -ldflags "-X github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version.Version=v1.2.3 \
+//	  -X github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/roman-povoroznyk/kubernetes-controller/k6s/pkg/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information (set by ldflags)
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = "unknown"
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the build information. Without an injected commit, the VCS
+// revision recorded by the Go toolchain is used, e.g. for go install builds.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    commit(),
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
+
+// String returns a one-line summary of the build
+func (i Info) String() string {
+	return fmt.Sprintf("k6s %s (commit %s, built %s, %s %s)", i.Version, i.Commit, i.BuildDate, i.GoVersion, i.Platform)
+}
+
+// commit returns the injected commit or the VCS revision of the build
+func commit() string {
+	if Commit != "" {
+		return Commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
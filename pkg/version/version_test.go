@@ -0,0 +1,33 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	previous := Version
+	previousCommit := Commit
+	t.Cleanup(func() {
+		Version = previous
+		Commit = previousCommit
+	})
+	Version = "v1.2.3"
+	Commit = "abc123"
+
+	info := Get()
+	if info.Version != "v1.2.3" || info.Commit != "abc123" {
+		t.Errorf("Expected injected version and commit, got %+v", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Expected Go version %s, got %s", runtime.Version(), info.GoVersion)
+	}
+	if info.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("Unexpected platform %s", info.Platform)
+	}
+
+	Commit = ""
+	if Get().Commit == "" {
+		t.Error("Expected a fallback commit")
+	}
+}